@@ -21,6 +21,7 @@
 package client
 
 import (
+	"context"
 	"time"
 
 	adminv1 "github.com/uber/cadence-idl/go/proto/admin/v1"
@@ -49,6 +50,7 @@ import (
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/rpc"
 	"github.com/uber/cadence/common/service"
+	"github.com/uber/cadence/common/types"
 )
 
 type (
@@ -152,10 +154,15 @@ func (cf *rpcClientFactory) NewMatchingClientWithTimeout(
 
 	peerResolver := matching.NewPeerResolver(cf.resolver, namedPort)
 
-	client := matching.NewClient(
+	// the load balancer lists the partitions of the scaled task lists with the client it's part of
+	var client matching.Client
+	listPartitions := func(ctx context.Context, request *types.MatchingListTaskListPartitionsRequest) (*types.ListTaskListPartitionsResponse, error) {
+		return client.ListTaskListPartitions(ctx, request)
+	}
+	client = matching.NewClient(
 		rawClient,
 		peerResolver,
-		matching.NewLoadBalancer(domainIDToName, cf.dynConfig),
+		matching.NewLoadBalancer(domainIDToName, cf.dynConfig, listPartitions),
 	)
 	client = timeoutwrapper.NewMatchingClient(client, longPollTimeout, timeout)
 	if errorRate := cf.dynConfig.GetFloat64Property(dynamicconfig.MatchingErrorInjectionRate)(); errorRate != 0 {
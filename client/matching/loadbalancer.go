@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/types"
 )
@@ -63,19 +64,26 @@ type (
 		nReadPartitions  dynamicconfig.IntPropertyFnWithTaskListInfoFilters
 		nWritePartitions dynamicconfig.IntPropertyFnWithTaskListInfoFilters
 		domainIDToName   func(string) (string, error)
+		// the partitions of the task lists scaled by the adaptive scaler are taken from matching instead
+		enableAdaptiveScaler dynamicconfig.BoolPropertyFnWithTaskListInfoFilters
+		partitionCache       *partitionCache
 	}
 )
 
 // NewLoadBalancer returns an instance of matching load balancer that
-// can help distribute api calls across task list partitions
+// can help distribute api calls across task list partitions, listPartitions
+// is used to get the partitions of the task lists scaled by matching
 func NewLoadBalancer(
 	domainIDToName func(string) (string, error),
 	dc *dynamicconfig.Collection,
+	listPartitions ListPartitionsFn,
 ) LoadBalancer {
 	return &defaultLoadBalancer{
-		domainIDToName:   domainIDToName,
-		nReadPartitions:  dc.GetIntPropertyFilteredByTaskListInfo(dynamicconfig.MatchingNumTasklistReadPartitions),
-		nWritePartitions: dc.GetIntPropertyFilteredByTaskListInfo(dynamicconfig.MatchingNumTasklistWritePartitions),
+		domainIDToName:       domainIDToName,
+		nReadPartitions:      dc.GetIntPropertyFilteredByTaskListInfo(dynamicconfig.MatchingNumTasklistReadPartitions),
+		nWritePartitions:     dc.GetIntPropertyFilteredByTaskListInfo(dynamicconfig.MatchingNumTasklistWritePartitions),
+		enableAdaptiveScaler: dc.GetBoolPropertyFilteredByTaskListInfo(dynamicconfig.MatchingEnableAdaptiveScaler),
+		partitionCache:       newPartitionCache(listPartitions, clock.NewRealTimeSource()),
 	}
 }
 
@@ -95,6 +103,9 @@ func (lb *defaultLoadBalancer) PickWritePartition(
 	if nRead := lb.nReadPartitions(domainName, taskList.GetName(), taskListType); nPartitions > nRead {
 		nPartitions = nRead
 	}
+	if n, ok := lb.scaledPartitions(domainName, taskList, taskListType, forwardedFrom); ok {
+		nPartitions = n
+	}
 	return lb.pickPartition(taskList, forwardedFrom, nPartitions)
 
 }
@@ -110,10 +121,34 @@ func (lb *defaultLoadBalancer) PickReadPartition(
 		return taskList.GetName()
 	}
 	n := lb.nReadPartitions(domainName, taskList.GetName(), taskListType)
+	if scaled, ok := lb.scaledPartitions(domainName, taskList, taskListType, forwardedFrom); ok {
+		// the partitions being drained are not listed, their backlog is forwarded to the root partition
+		n = scaled
+	}
 	return lb.pickPartition(taskList, forwardedFrom, n)
 
 }
 
+// scaledPartitions returns the number of partitions of a task list scaled by matching, it returns false
+// when the task list isn't scaled or its partitions are not known yet
+func (lb *defaultLoadBalancer) scaledPartitions(
+	domainName string,
+	taskList types.TaskList,
+	taskListType int,
+	forwardedFrom string,
+) (int, bool) {
+	if lb.partitionCache == nil || lb.enableAdaptiveScaler == nil {
+		return 0, false
+	}
+	if forwardedFrom != "" || taskList.GetKind() == types.TaskListKindSticky || strings.HasPrefix(taskList.GetName(), common.ReservedTaskListPrefix) {
+		return 0, false
+	}
+	if !lb.enableAdaptiveScaler(domainName, taskList.GetName(), taskListType) {
+		return 0, false
+	}
+	return lb.partitionCache.get(domainName, taskList.GetName(), taskListType)
+}
+
 func (lb *defaultLoadBalancer) pickPartition(
 	taskList types.TaskList,
 	forwardedFrom string,
@@ -23,12 +23,17 @@
 package matching
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
 )
 
@@ -251,3 +256,66 @@ func Test_defaultLoadBalancer_pickPartition(t *testing.T) {
 		})
 	}
 }
+
+func Test_defaultLoadBalancer_ScaledPartitions(t *testing.T) {
+	listed := make(chan *types.MatchingListTaskListPartitionsRequest, 1)
+	var listErr error
+	listPartitions := func(_ context.Context, request *types.MatchingListTaskListPartitionsRequest) (*types.ListTaskListPartitionsResponse, error) {
+		defer func() { listed <- request }()
+		if listErr != nil {
+			return nil, listErr
+		}
+		return &types.ListTaskListPartitionsResponse{
+			ActivityTaskListPartitions: []*types.TaskListPartitionMetadata{{Key: "a"}, {Key: "b"}, {Key: "c"}},
+			DecisionTaskListPartitions: []*types.TaskListPartitionMetadata{{Key: "a"}},
+		}, nil
+	}
+	timeSource := clock.NewMockedTimeSource()
+	lb := &defaultLoadBalancer{
+		nReadPartitions:      func(string, string, int) int { return 1 },
+		nWritePartitions:     func(string, string, int) int { return 1 },
+		domainIDToName:       func(string) (string, error) { return "domainName", nil },
+		enableAdaptiveScaler: func(domain string, taskList string, taskType int) bool { return taskList != "disabled" },
+		partitionCache:       newPartitionCache(listPartitions, timeSource),
+	}
+	taskList := types.TaskList{Name: "tl"}
+
+	// the configured partitions are used until the partitions are listed
+	_, ok := lb.scaledPartitions("domainName", taskList, persistence.TaskListTypeActivity, "")
+	assert.False(t, ok)
+	request := <-listed
+	assert.Equal(t, "domainName", request.Domain)
+	assert.Equal(t, "tl", request.TaskList.GetName())
+
+	n, ok := lb.scaledPartitions("domainName", taskList, persistence.TaskListTypeActivity, "")
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+	n, ok = lb.scaledPartitions("domainName", taskList, persistence.TaskListTypeDecision, "")
+	assert.True(t, ok)
+	assert.Equal(t, 1, n)
+	for i := 0; i < 100; i++ {
+		assert.Contains(t, []string{"tl", "/__cadence_sys/tl/1", "/__cadence_sys/tl/2"},
+			lb.PickWritePartition("domainID", taskList, persistence.TaskListTypeActivity, ""))
+	}
+
+	// the last known partitions are kept when a refresh fails
+	listErr = errors.New("list failed")
+	timeSource.Advance(partitionCacheRefreshInterval)
+	n, ok = lb.scaledPartitions("domainName", taskList, persistence.TaskListTypeActivity, "")
+	<-listed
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+
+	// forwarded requests, sticky task lists and task lists without the scaler are not scaled
+	_, ok = lb.scaledPartitions("domainName", taskList, persistence.TaskListTypeActivity, "/__cadence_sys/tl/1")
+	assert.False(t, ok)
+	_, ok = lb.scaledPartitions("domainName", types.TaskList{Name: "tl", Kind: types.TaskListKindSticky.Ptr()}, persistence.TaskListTypeActivity, "")
+	assert.False(t, ok)
+	_, ok = lb.scaledPartitions("domainName", types.TaskList{Name: "disabled"}, persistence.TaskListTypeActivity, "")
+	assert.False(t, ok)
+	select {
+	case <-listed:
+		assert.Fail(t, "partitions of task lists without the scaler should not be listed")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
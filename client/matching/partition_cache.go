@@ -0,0 +1,121 @@
+// Copyright (c) 2024 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package matching
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	partitionCacheRefreshInterval = 10 * time.Second
+	partitionCacheRefreshTimeout  = 5 * time.Second
+)
+
+type (
+	// ListPartitionsFn lists the partitions of a task list
+	ListPartitionsFn func(context.Context, *types.MatchingListTaskListPartitionsRequest) (*types.ListTaskListPartitionsResponse, error)
+
+	// partitionCache caches the number of partitions of the task lists scaled by the adaptive scaler
+	// of matching. The entries are refreshed in the background, so that picking a partition never
+	// waits on a call to matching.
+	partitionCache struct {
+		sync.Mutex
+		listPartitions ListPartitionsFn
+		timeSource     clock.TimeSource
+		entries        map[partitionCacheKey]*partitionCacheEntry
+	}
+
+	partitionCacheKey struct {
+		domainName   string
+		taskListName string
+	}
+
+	partitionCacheEntry struct {
+		activityPartitions int
+		decisionPartitions int
+		// refreshTime is the time of the last successful refresh, and attemptTime is the time of the last refresh
+		refreshTime time.Time
+		attemptTime time.Time
+		refreshing  bool
+	}
+)
+
+func newPartitionCache(listPartitions ListPartitionsFn, timeSource clock.TimeSource) *partitionCache {
+	return &partitionCache{
+		listPartitions: listPartitions,
+		timeSource:     timeSource,
+		entries:        make(map[partitionCacheKey]*partitionCacheEntry),
+	}
+}
+
+// get returns the number of partitions of a task list, it returns false if the task list is not cached yet
+func (c *partitionCache) get(domainName string, taskListName string, taskListType int) (int, bool) {
+	key := partitionCacheKey{domainName: domainName, taskListName: taskListName}
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &partitionCacheEntry{}
+		c.entries[key] = entry
+	}
+	if !entry.refreshing && c.timeSource.Since(entry.attemptTime) >= partitionCacheRefreshInterval {
+		entry.refreshing = true
+		entry.attemptTime = c.timeSource.Now()
+		go c.refresh(key)
+	}
+	if entry.refreshTime.IsZero() {
+		return 0, false
+	}
+	if taskListType == persistence.TaskListTypeActivity {
+		return entry.activityPartitions, true
+	}
+	return entry.decisionPartitions, true
+}
+
+func (c *partitionCache) refresh(key partitionCacheKey) {
+	ctx, cancel := context.WithTimeout(context.Background(), partitionCacheRefreshTimeout)
+	defer cancel()
+	resp, err := c.listPartitions(ctx, &types.MatchingListTaskListPartitionsRequest{
+		Domain:   key.domainName,
+		TaskList: &types.TaskList{Name: key.taskListName, Kind: types.TaskListKindNormal.Ptr()},
+	})
+
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	entry.refreshing = false
+	if err != nil || resp == nil {
+		// keep the last known partitions until the next refresh
+		return
+	}
+	entry.activityPartitions = len(resp.ActivityTaskListPartitions)
+	entry.decisionPartitions = len(resp.DecisionTaskListPartitions)
+	entry.refreshTime = c.timeSource.Now()
+}
@@ -69,6 +69,11 @@ func startHandler(c *cli.Context) {
 	if err := cfg.ValidateAndFillDefaults(); err != nil {
 		log.Fatalf("config validation failed: %v", err)
 	}
+	if c.Bool("setup-sqlite-schema") {
+		if err := sql.SetupSQLiteSchemas(cfg.Persistence); err != nil {
+			log.Fatal("sqlite schema setup failed: ", err)
+		}
+	}
 	// cassandra schema version validation
 	if err := cassandra.VerifyCompatibleVersion(cfg.Persistence, gocql.Quorum); err != nil {
 		log.Fatal("cassandra schema version compatibility check failed: ", err)
@@ -211,6 +216,10 @@ func BuildCLI(releaseVersion string, gitRevision string) *cli.App {
 					Value: strings.Join(validServices, ","),
 					Usage: "list of services to start",
 				},
+				cli.BoolFlag{
+					Name:  "setup-sqlite-schema",
+					Usage: "set up the schemas of empty SQLite databases before starting, e.g. for in-memory databases",
+				},
 			},
 			Action: func(c *cli.Context) {
 				startHandler(c)
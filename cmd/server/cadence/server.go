@@ -34,11 +34,13 @@ import (
 	"github.com/uber/cadence/common/archiver/provider"
 	"github.com/uber/cadence/common/asyncworkflow/queue"
 	"github.com/uber/cadence/common/blobstore/filestore"
+	"github.com/uber/cadence/common/blobstore/objectstore"
 	"github.com/uber/cadence/common/cluster"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/dynamicconfig/configstore"
 	"github.com/uber/cadence/common/elasticsearch"
+	"github.com/uber/cadence/common/encryption/filekeyring"
 	"github.com/uber/cadence/common/log/loggerimpl"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/membership"
@@ -116,6 +118,12 @@ func (s *server) startService() common.Daemon {
 	params.Logger = loggerimpl.NewLogger(zapLogger).WithTags(tag.Service(params.Name))
 
 	params.PersistenceConfig = s.cfg.Persistence
+	if s.cfg.Encryption.Keyring != nil {
+		params.PersistenceConfig.KeyProvider, err = filekeyring.NewKeyProvider(s.cfg.Encryption.Keyring)
+		if err != nil {
+			log.Fatalf("error creating encryption key provider: %v", err)
+		}
+	}
 
 	err = nil
 	if s.cfg.DynamicConfig.Client == "" {
@@ -298,11 +306,23 @@ func (s *server) startService() common.Daemon {
 	params.PersistenceConfig.TransactionSizeLimit = dc.GetIntProperty(dynamicconfig.TransactionSizeLimit)
 	params.PersistenceConfig.ErrorInjectionRate = dc.GetFloat64Property(dynamicconfig.PersistenceErrorInjectionRate)
 	params.AuthorizationConfig = s.cfg.Authorization
-	params.BlobstoreClient, err = filestore.NewFilestoreClient(s.cfg.Blobstore.Filestore)
-	if err != nil {
-		log.Printf("failed to create file blobstore client, will continue startup without it: %v", err)
-		params.BlobstoreClient = nil
+	params.AuditConfig = s.cfg.Audit
+	if s.cfg.Blobstore.Objectstore != nil {
+		params.BlobstoreClient, err = objectstore.NewObjectstoreClient(s.cfg.Blobstore.Objectstore)
+		if err != nil {
+			log.Fatalf("error creating object store blobstore client: %v", err)
+		}
+		// the object store is shared by all hosts, so history payloads can be offloaded to it
+		params.PersistenceConfig.BlobstoreClient = params.BlobstoreClient
+	} else {
+		params.BlobstoreClient, err = filestore.NewFilestoreClient(s.cfg.Blobstore.Filestore)
+		if err != nil {
+			log.Printf("failed to create file blobstore client, will continue startup without it: %v", err)
+			params.BlobstoreClient = nil
+		}
+		// the file blobstore is local to the host, so history payloads are not offloaded to it
 	}
+	params.PersistenceConfig.PayloadOffloadThreshold = dc.GetIntPropertyFilteredByDomain(dynamicconfig.PayloadOffloadThreshold)
 
 	params.AsyncWorkflowQueueProvider, err = queue.NewAsyncQueueProvider(s.cfg.AsyncWorkflowQueues)
 	if err != nil {
@@ -40,6 +40,8 @@ const (
 	ErrReasonReadHistory = "failed to read history batches"
 	// ErrReasonHistoryMutated is the error reason for mutated history
 	ErrReasonHistoryMutated = "history was mutated"
+	// ErrReasonEncryptArchive is the error reason for failing to encrypt the archive
+	ErrReasonEncryptArchive = "failed to encrypt archive"
 )
 
 var (
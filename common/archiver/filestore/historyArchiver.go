@@ -177,6 +177,11 @@ func (h *historyArchiver) Archive(
 		logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(errEncodeHistory), tag.Error(err))
 		return err
 	}
+	encodedHistoryBatches, err = archiver.EncryptArchive(h.container.KeyProvider, encodedHistoryBatches, request.DomainName)
+	if err != nil {
+		logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(archiver.ErrReasonEncryptArchive), tag.Error(err))
+		return err
+	}
 
 	dirPath := URI.Path()
 	if err = util.MkdirAll(dirPath, h.dirMode); err != nil {
@@ -251,6 +256,10 @@ func (h *historyArchiver) Get(
 	if err != nil {
 		return nil, &types.InternalServiceError{Message: err.Error()}
 	}
+	encodedHistoryBatches, err = archiver.DecryptArchive(h.container.KeyProvider, encodedHistoryBatches)
+	if err != nil {
+		return nil, &types.InternalServiceError{Message: err.Error()}
+	}
 
 	historyBatches, err := decodeHistoryBatches(encodedHistoryBatches)
 	if err != nil {
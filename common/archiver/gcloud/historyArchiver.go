@@ -177,6 +177,11 @@ func (h *historyArchiver) Archive(ctx context.Context, URI archiver.URI, request
 			logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(errEncodeHistory), tag.Error(err))
 			return errUploadNonRetriable
 		}
+		encodedHistoryPart, err = archiver.EncryptArchive(h.container.KeyProvider, encodedHistoryPart, request.DomainName)
+		if err != nil {
+			logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(archiver.ErrReasonEncryptArchive), tag.Error(err))
+			return errUploadNonRetriable
+		}
 
 		filename := constructHistoryFilenameMultipart(request.DomainID, request.WorkflowID, request.RunID, request.CloseFailoverVersion, part)
 		if exist, _ := h.gcloudStorage.Exist(ctx, URI, filename); !exist {
@@ -249,6 +254,10 @@ outer:
 			return nil, &types.InternalServiceError{Message: "Fail retrieving history file: " + URI.String() + "/" + filename}
 		}
 
+		encodedHistoryBatches, err = archiver.DecryptArchive(h.container.KeyProvider, encodedHistoryBatches)
+		if err != nil {
+			return nil, &types.InternalServiceError{Message: err.Error()}
+		}
 		batches, err := decodeHistoryBatches(encodedHistoryBatches)
 		if err != nil {
 			return nil, &types.InternalServiceError{Message: err.Error()}
@@ -124,6 +124,11 @@ func (v *visibilityArchiver) Archive(ctx context.Context, URI archiver.URI, requ
 		logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(errEncodeVisibilityRecord), tag.Error(err))
 		return err
 	}
+	encodedVisibilityRecord, err = archiver.EncryptArchive(v.container.KeyProvider, encodedVisibilityRecord, request.DomainName)
+	if err != nil {
+		logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(archiver.ErrReasonEncryptArchive), tag.Error(err))
+		return err
+	}
 
 	// The filename has the format: closeTimestamp_hash(runID).visibility
 	// This format allows the archiver to sort all records without reading the file contents
@@ -217,6 +222,10 @@ func (v *visibilityArchiver) query(ctx context.Context, URI archiver.URI, reques
 			return nil, &types.InternalServiceError{Message: err.Error()}
 		}
 
+		encodedRecord, err = archiver.DecryptArchive(v.container.KeyProvider, encodedRecord)
+		if err != nil {
+			return nil, &types.InternalServiceError{Message: err.Error()}
+		}
 		record, err := decodeVisibilityRecord(encodedRecord)
 		if err != nil {
 			return nil, &types.InternalServiceError{Message: err.Error()}
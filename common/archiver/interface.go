@@ -25,6 +25,7 @@ import (
 
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/cluster"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
@@ -67,6 +68,7 @@ type (
 		MetricsClient    metrics.Client
 		ClusterMetadata  cluster.Metadata
 		DomainCache      cache.DomainCache
+		KeyProvider      encryption.KeyProvider
 	}
 
 	// HistoryArchiver is used to archive history and read archived history
@@ -82,6 +84,7 @@ type (
 		MetricsClient   metrics.Client
 		ClusterMetadata cluster.Metadata
 		DomainCache     cache.DomainCache
+		KeyProvider     encryption.KeyProvider
 	}
 
 	// ArchiveVisibilityRequest is request to Archive single workflow visibility record
@@ -0,0 +1,118 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// The object store history archiver archives workflow histories to S3-compatible object stores.
+// The histories are stored with the same layout as the s3store archiver, which it uses with the endpoint of the object store.
+
+package objectstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/archiver/s3store"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/types"
+)
+
+var (
+	errEmptyEndpoint     = errors.New("empty object store endpoint")
+	errNoBucketSpecified = errors.New("no bucket specified")
+	errBucketNotExists   = errors.New("requested bucket does not exist")
+)
+
+type (
+	historyArchiver struct {
+		container  *archiver.HistoryBootstrapContainer
+		s3Archiver archiver.HistoryArchiver
+	}
+)
+
+// NewHistoryArchiver creates a new archiver.HistoryArchiver based on an S3-compatible object store
+func NewHistoryArchiver(
+	container *archiver.HistoryBootstrapContainer,
+	cfg *config.ObjectstoreArchiver,
+) (archiver.HistoryArchiver, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	s3Config := newS3Config(cfg)
+	s3Archiver, err := s3store.NewHistoryArchiver(container, &config.S3Archiver{
+		Region:           aws.StringValue(s3Config.Region),
+		Endpoint:         s3Config.Endpoint,
+		S3ForcePathStyle: aws.BoolValue(s3Config.S3ForcePathStyle),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newHistoryArchiver(container, s3Archiver), nil
+}
+
+func newHistoryArchiver(
+	container *archiver.HistoryBootstrapContainer,
+	s3Archiver archiver.HistoryArchiver,
+) *historyArchiver {
+	return &historyArchiver{
+		container:  container,
+		s3Archiver: s3Archiver,
+	}
+}
+
+func (h *historyArchiver) Archive(
+	ctx context.Context,
+	URI archiver.URI,
+	request *archiver.ArchiveHistoryRequest,
+	opts ...archiver.ArchiveOption,
+) error {
+	s3URI, err := toS3URI(URI)
+	if err != nil {
+		logger := archiver.TagLoggerWithArchiveHistoryRequestAndURI(h.container.Logger, request, URI.String())
+		logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(archiver.ErrReasonInvalidURI), tag.Error(err))
+		if featureCatalog := archiver.GetFeatureCatalog(opts...); featureCatalog.NonRetriableError != nil {
+			return featureCatalog.NonRetriableError()
+		}
+		return err
+	}
+	return h.s3Archiver.Archive(ctx, s3URI, request, opts...)
+}
+
+func (h *historyArchiver) Get(
+	ctx context.Context,
+	URI archiver.URI,
+	request *archiver.GetHistoryRequest,
+) (*archiver.GetHistoryResponse, error) {
+	s3URI, err := toS3URI(URI)
+	if err != nil {
+		return nil, &types.BadRequestError{Message: archiver.ErrInvalidURI.Error()}
+	}
+	return h.s3Archiver.Get(ctx, s3URI, request)
+}
+
+func (h *historyArchiver) ValidateURI(URI archiver.URI) error {
+	s3URI, err := toS3URI(URI)
+	if err != nil {
+		return err
+	}
+	return h.s3Archiver.ValidateURI(s3URI)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package objectstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/types"
+)
+
+type historyArchiverSuite struct {
+	*require.Assertions
+	suite.Suite
+
+	s3Archiver *archiver.HistoryArchiverMock
+	archiver   *historyArchiver
+	URI        archiver.URI
+	s3URI      archiver.URI
+}
+
+func TestHistoryArchiverSuite(t *testing.T) {
+	suite.Run(t, new(historyArchiverSuite))
+}
+
+func (s *historyArchiverSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	var err error
+	s.URI, err = archiver.NewURI(testBucketURI)
+	s.NoError(err)
+	s.s3URI, err = archiver.NewURI("s3://test-bucket/archive")
+	s.NoError(err)
+	s.s3Archiver = &archiver.HistoryArchiverMock{}
+	s.archiver = newHistoryArchiver(&archiver.HistoryBootstrapContainer{Logger: testlogger.New(s.T())}, s.s3Archiver)
+}
+
+func (s *historyArchiverSuite) TearDownTest() {
+	s.s3Archiver.AssertExpectations(s.T())
+}
+
+func (s *historyArchiverSuite) TestNewHistoryArchiver() {
+	_, err := NewHistoryArchiver(&archiver.HistoryBootstrapContainer{}, &config.ObjectstoreArchiver{})
+	s.Equal(errEmptyEndpoint, err)
+
+	_, err = NewHistoryArchiver(&archiver.HistoryBootstrapContainer{}, &config.ObjectstoreArchiver{Endpoint: "http://127.0.0.1:9000"})
+	s.NoError(err)
+}
+
+func (s *historyArchiverSuite) TestArchive() {
+	request := &archiver.ArchiveHistoryRequest{DomainID: testDomainID, WorkflowID: "wid", RunID: "rid"}
+	s.s3Archiver.On("Archive", mock.Anything, s.s3URI, request).Return(nil).Once()
+	s.NoError(s.archiver.Archive(context.Background(), s.URI, request))
+}
+
+func (s *historyArchiverSuite) TestArchive_InvalidURI() {
+	URI, err := archiver.NewURI("s3://test-bucket/archive")
+	s.NoError(err)
+	err = s.archiver.Archive(context.Background(), URI, &archiver.ArchiveHistoryRequest{DomainID: testDomainID})
+	s.Equal(archiver.ErrURISchemeMismatch, err)
+}
+
+func (s *historyArchiverSuite) TestGet() {
+	request := &archiver.GetHistoryRequest{DomainID: testDomainID, WorkflowID: "wid", RunID: "rid", PageSize: 10}
+	response := &archiver.GetHistoryResponse{HistoryBatches: []*types.History{{}}}
+	s.s3Archiver.On("Get", mock.Anything, s.s3URI, request).Return(response, nil).Once()
+	actual, err := s.archiver.Get(context.Background(), s.URI, request)
+	s.NoError(err)
+	s.Equal(response, actual)
+
+	URI, err := archiver.NewURI("objectstore:///archive")
+	s.NoError(err)
+	_, err = s.archiver.Get(context.Background(), URI, request)
+	s.IsType(&types.BadRequestError{}, err)
+}
+
+func (s *historyArchiverSuite) TestValidateURI() {
+	s.s3Archiver.On("ValidateURI", s.s3URI).Return(nil).Once()
+	s.NoError(s.archiver.ValidateURI(s.URI))
+
+	URI, err := archiver.NewURI("objectstore:///archive")
+	s.NoError(err)
+	s.Equal(errNoBucketSpecified, s.archiver.ValidateURI(URI))
+}
@@ -0,0 +1,604 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package objectstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xwb1989/sqlparser"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	// QueryParser parses the SQL-like query of advanced visibility into a filter of the archived visibility records
+	QueryParser interface {
+		Parse(query string) (*parsedQuery, error)
+	}
+
+	queryParser struct{}
+
+	parsedQuery struct {
+		// filter matches the records of the query
+		filter filter
+		// index and indexValue select the records listed for the query, see visibilityIndexes
+		index      string
+		indexValue string
+		// earliestCloseTime and latestCloseTime bound the close time of the listed records
+		earliestCloseTime int64
+		latestCloseTime   int64
+		emptyResult       bool
+	}
+
+	filter interface {
+		match(record *visibilityRecord) bool
+	}
+
+	andFilter struct {
+		left  filter
+		right filter
+	}
+
+	orFilter struct {
+		left  filter
+		right filter
+	}
+
+	notFilter struct {
+		filter filter
+	}
+
+	// comparisonFilter compares a field of the record with the values of the query,
+	// there are multiple values only for the in and not in operators
+	comparisonFilter struct {
+		field    string
+		operator string
+		values   []interface{}
+	}
+
+	// rangeFilter matches the records whose field is between from and to inclusively
+	rangeFilter struct {
+		field string
+		from  interface{}
+		to    interface{}
+		not   bool
+	}
+)
+
+const (
+	queryTemplate = "select * from dummy where %s"
+
+	defaultDateTimeFormat = time.RFC3339
+)
+
+// indexPriority is the preference of the indexes when a query filters on more than one of them
+var indexPriority = map[string]int{
+	indexAll:                0,
+	definition.CloseStatus:  1,
+	definition.WorkflowType: 2,
+	definition.WorkflowID:   3,
+}
+
+// NewQueryParser creates a new query parser for the object store
+func NewQueryParser() QueryParser {
+	return &queryParser{}
+}
+
+func (p *queryParser) Parse(query string) (*parsedQuery, error) {
+	stmt, err := sqlparser.Parse(fmt.Sprintf(queryTemplate, query))
+	if err != nil {
+		return nil, err
+	}
+	sel := stmt.(*sqlparser.Select)
+	if len(sel.OrderBy) > 0 || len(sel.GroupBy) > 0 || sel.Limit != nil {
+		return nil, errors.New("order by, group by and limit are not supported, archived workflows are sorted by close time in descending order")
+	}
+	parsedQuery := &parsedQuery{
+		index:             indexAll,
+		earliestCloseTime: 0,
+		latestCloseTime:   math.MaxInt64,
+	}
+	if sel.Where == nil {
+		return parsedQuery, nil
+	}
+	parsedQuery.filter, err = convertExpr(sel.Where.Expr)
+	if err != nil {
+		return nil, err
+	}
+	parsedQuery.narrow(parsedQuery.filter)
+	if parsedQuery.earliestCloseTime > parsedQuery.latestCloseTime {
+		parsedQuery.emptyResult = true
+	}
+	return parsedQuery, nil
+}
+
+// narrow selects the index and the close time range of the listed records from the conditions
+// which all the records of the query must satisfy, i.e. the conditions joined by and at the top level
+func (q *parsedQuery) narrow(f filter) {
+	switch f := f.(type) {
+	case *andFilter:
+		q.narrow(f.left)
+		q.narrow(f.right)
+	case *comparisonFilter:
+		if f.field == definition.CloseTime {
+			q.narrowCloseTime(f.operator, f.values[0].(int64))
+			return
+		}
+		if f.operator != sqlparser.EqualStr {
+			return
+		}
+		if priority, ok := indexPriority[f.field]; ok && priority > indexPriority[q.index] {
+			q.index = f.field
+			q.indexValue = indexValueOf(f.field, f.values[0])
+		}
+	case *rangeFilter:
+		if f.field == definition.CloseTime && !f.not {
+			q.narrowCloseTime(sqlparser.GreaterEqualStr, f.from.(int64))
+			q.narrowCloseTime(sqlparser.LessEqualStr, f.to.(int64))
+		}
+	}
+}
+
+func (q *parsedQuery) narrowCloseTime(operator string, timestamp int64) {
+	switch operator {
+	case sqlparser.EqualStr:
+		q.narrowCloseTime(sqlparser.GreaterEqualStr, timestamp)
+		q.narrowCloseTime(sqlparser.LessEqualStr, timestamp)
+	case sqlparser.LessThanStr:
+		q.latestCloseTime = common.MinInt64(q.latestCloseTime, timestamp-1)
+	case sqlparser.LessEqualStr:
+		q.latestCloseTime = common.MinInt64(q.latestCloseTime, timestamp)
+	case sqlparser.GreaterThanStr:
+		q.earliestCloseTime = common.MaxInt64(q.earliestCloseTime, timestamp+1)
+	case sqlparser.GreaterEqualStr:
+		q.earliestCloseTime = common.MaxInt64(q.earliestCloseTime, timestamp)
+	}
+}
+
+func indexValueOf(field string, value interface{}) string {
+	if field == definition.CloseStatus {
+		return types.WorkflowExecutionCloseStatus(value.(int64)).String()
+	}
+	return value.(string)
+}
+
+func convertExpr(expr sqlparser.Expr) (filter, error) {
+	switch expr := expr.(type) {
+	case *sqlparser.AndExpr:
+		left, right, err := convertBinaryExpr(expr.Left, expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &andFilter{left: left, right: right}, nil
+	case *sqlparser.OrExpr:
+		left, right, err := convertBinaryExpr(expr.Left, expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &orFilter{left: left, right: right}, nil
+	case *sqlparser.NotExpr:
+		f, err := convertExpr(expr.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &notFilter{filter: f}, nil
+	case *sqlparser.ParenExpr:
+		return convertExpr(expr.Expr)
+	case *sqlparser.ComparisonExpr:
+		return convertComparisonExpr(expr)
+	case *sqlparser.RangeCond:
+		return convertRangeCond(expr)
+	default:
+		return nil, fmt.Errorf("unsupported expression: %s", sqlparser.String(expr))
+	}
+}
+
+func convertBinaryExpr(leftExpr, rightExpr sqlparser.Expr) (filter, filter, error) {
+	left, err := convertExpr(leftExpr)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := convertExpr(rightExpr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+func convertComparisonExpr(expr *sqlparser.ComparisonExpr) (filter, error) {
+	field, err := convertColName(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	f := &comparisonFilter{field: field, operator: expr.Operator}
+	switch expr.Operator {
+	case sqlparser.EqualStr, sqlparser.NotEqualStr:
+	case sqlparser.LessThanStr, sqlparser.LessEqualStr, sqlparser.GreaterThanStr, sqlparser.GreaterEqualStr:
+		if field == definition.CloseStatus {
+			return nil, fmt.Errorf("operator %s is not supported for %s", expr.Operator, field)
+		}
+	case sqlparser.InStr, sqlparser.NotInStr:
+		tuple, ok := expr.Right.(sqlparser.ValTuple)
+		if !ok {
+			return nil, fmt.Errorf("invalid values: %s", sqlparser.String(expr.Right))
+		}
+		for _, valExpr := range tuple {
+			value, err := convertValue(field, valExpr)
+			if err != nil {
+				return nil, err
+			}
+			f.values = append(f.values, value)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", expr.Operator)
+	}
+	value, err := convertValue(field, expr.Right)
+	if err != nil {
+		return nil, err
+	}
+	f.values = []interface{}{value}
+	return f, nil
+}
+
+func convertRangeCond(expr *sqlparser.RangeCond) (filter, error) {
+	field, err := convertColName(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+	if field == definition.CloseStatus {
+		return nil, fmt.Errorf("operator %s is not supported for %s", expr.Operator, field)
+	}
+	from, err := convertValue(field, expr.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := convertValue(field, expr.To)
+	if err != nil {
+		return nil, err
+	}
+	return &rangeFilter{field: field, from: from, to: to, not: expr.Operator == sqlparser.NotBetweenStr}, nil
+}
+
+func convertColName(expr sqlparser.Expr) (string, error) {
+	colName, ok := expr.(*sqlparser.ColName)
+	if !ok {
+		return "", fmt.Errorf("invalid filter name: %s", sqlparser.String(expr))
+	}
+	name := colName.Name.String()
+	if !colName.Qualifier.IsEmpty() && colName.Qualifier.Name.String() != definition.Attr {
+		return "", fmt.Errorf("invalid filter name: %s", sqlparser.String(expr))
+	}
+	switch name {
+	case definition.DomainID, definition.Memo, "":
+		return "", fmt.Errorf("invalid filter name: %s", sqlparser.String(expr))
+	}
+	return name, nil
+}
+
+// convertValue converts the value of the query to the type of the field:
+// string for the IDs and the workflow type, int64 for the timestamps, the close status and the history length,
+// and string, int64, float64 or bool for the search attributes
+func convertValue(field string, expr sqlparser.Expr) (interface{}, error) {
+	if boolVal, ok := expr.(sqlparser.BoolVal); ok {
+		if isSystemField(field) {
+			return nil, fmt.Errorf("invalid value for %s: %s", field, sqlparser.String(expr))
+		}
+		return bool(boolVal), nil
+	}
+	val, ok := expr.(*sqlparser.SQLVal)
+	if !ok {
+		return nil, fmt.Errorf("invalid value: %s", sqlparser.String(expr))
+	}
+	invalidValueErr := fmt.Errorf("invalid value for %s: %s", field, sqlparser.String(expr))
+	switch field {
+	case definition.WorkflowID, definition.RunID, definition.WorkflowType:
+		if val.Type != sqlparser.StrVal {
+			return nil, invalidValueErr
+		}
+		return string(val.Val), nil
+	case definition.StartTime, definition.ExecutionTime, definition.CloseTime:
+		timestamp, err := convertToTimestamp(val)
+		if err != nil {
+			return nil, invalidValueErr
+		}
+		return timestamp, nil
+	case definition.CloseStatus:
+		if val.Type != sqlparser.StrVal && val.Type != sqlparser.IntVal {
+			return nil, invalidValueErr
+		}
+		status, err := convertStatusStr(string(val.Val))
+		if err != nil {
+			return nil, err
+		}
+		return int64(status), nil
+	case definition.HistoryLength:
+		if val.Type != sqlparser.IntVal {
+			return nil, invalidValueErr
+		}
+		return strconv.ParseInt(string(val.Val), 10, 64)
+	default:
+		switch val.Type {
+		case sqlparser.StrVal:
+			return string(val.Val), nil
+		case sqlparser.IntVal:
+			return strconv.ParseInt(string(val.Val), 10, 64)
+		case sqlparser.FloatVal:
+			return strconv.ParseFloat(string(val.Val), 64)
+		default:
+			return nil, invalidValueErr
+		}
+	}
+}
+
+func isSystemField(field string) bool {
+	switch field {
+	case definition.WorkflowID, definition.RunID, definition.WorkflowType, definition.StartTime,
+		definition.ExecutionTime, definition.CloseTime, definition.CloseStatus, definition.HistoryLength:
+		return true
+	default:
+		return false
+	}
+}
+
+func convertToTimestamp(val *sqlparser.SQLVal) (int64, error) {
+	switch val.Type {
+	case sqlparser.IntVal:
+		return strconv.ParseInt(string(val.Val), 10, 64)
+	case sqlparser.StrVal:
+		parsedTime, err := time.Parse(defaultDateTimeFormat, string(val.Val))
+		if err != nil {
+			return 0, err
+		}
+		return parsedTime.UnixNano(), nil
+	default:
+		return 0, fmt.Errorf("invalid timestamp: %s", sqlparser.String(val))
+	}
+}
+
+func convertStatusStr(statusStr string) (types.WorkflowExecutionCloseStatus, error) {
+	statusStr = strings.ToLower(strings.TrimSpace(statusStr))
+	switch statusStr {
+	case "completed", strconv.Itoa(int(types.WorkflowExecutionCloseStatusCompleted)):
+		return types.WorkflowExecutionCloseStatusCompleted, nil
+	case "failed", strconv.Itoa(int(types.WorkflowExecutionCloseStatusFailed)):
+		return types.WorkflowExecutionCloseStatusFailed, nil
+	case "canceled", strconv.Itoa(int(types.WorkflowExecutionCloseStatusCanceled)):
+		return types.WorkflowExecutionCloseStatusCanceled, nil
+	case "terminated", strconv.Itoa(int(types.WorkflowExecutionCloseStatusTerminated)):
+		return types.WorkflowExecutionCloseStatusTerminated, nil
+	case "continuedasnew", "continued_as_new", strconv.Itoa(int(types.WorkflowExecutionCloseStatusContinuedAsNew)):
+		return types.WorkflowExecutionCloseStatusContinuedAsNew, nil
+	case "timedout", "timed_out", strconv.Itoa(int(types.WorkflowExecutionCloseStatusTimedOut)):
+		return types.WorkflowExecutionCloseStatusTimedOut, nil
+	default:
+		return 0, fmt.Errorf("unknown workflow close status: %s", statusStr)
+	}
+}
+
+func (f *andFilter) match(record *visibilityRecord) bool {
+	return f.left.match(record) && f.right.match(record)
+}
+
+func (f *orFilter) match(record *visibilityRecord) bool {
+	return f.left.match(record) || f.right.match(record)
+}
+
+func (f *notFilter) match(record *visibilityRecord) bool {
+	return !f.filter.match(record)
+}
+
+func (f *comparisonFilter) match(record *visibilityRecord) bool {
+	fieldValues, ok := fieldValuesOf(record, f.field)
+	switch f.operator {
+	case sqlparser.EqualStr, sqlparser.InStr:
+		return ok && containsAny(fieldValues, f.values)
+	case sqlparser.NotEqualStr, sqlparser.NotInStr:
+		// same as advanced visibility, a record without the search attribute matches
+		return !ok || !containsAny(fieldValues, f.values)
+	}
+	for _, fieldValue := range fieldValues {
+		result, comparable := compare(fieldValue, f.values[0])
+		if !comparable {
+			continue
+		}
+		switch f.operator {
+		case sqlparser.LessThanStr:
+			ok = result < 0
+		case sqlparser.LessEqualStr:
+			ok = result <= 0
+		case sqlparser.GreaterThanStr:
+			ok = result > 0
+		case sqlparser.GreaterEqualStr:
+			ok = result >= 0
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *rangeFilter) match(record *visibilityRecord) bool {
+	fieldValues, _ := fieldValuesOf(record, f.field)
+	for _, fieldValue := range fieldValues {
+		fromResult, fromComparable := compare(fieldValue, f.from)
+		toResult, toComparable := compare(fieldValue, f.to)
+		if fromComparable && toComparable && fromResult >= 0 && toResult <= 0 {
+			return !f.not
+		}
+	}
+	return f.not
+}
+
+// fieldValuesOf returns the values of the field of the record, a search attribute of a list type has multiple values
+func fieldValuesOf(record *visibilityRecord, field string) ([]interface{}, bool) {
+	switch field {
+	case definition.WorkflowID:
+		return []interface{}{record.WorkflowID}, true
+	case definition.RunID:
+		return []interface{}{record.RunID}, true
+	case definition.WorkflowType:
+		return []interface{}{record.WorkflowTypeName}, true
+	case definition.StartTime:
+		return []interface{}{record.StartTimestamp}, true
+	case definition.ExecutionTime:
+		return []interface{}{record.ExecutionTimestamp}, true
+	case definition.CloseTime:
+		return []interface{}{record.CloseTimestamp}, true
+	case definition.CloseStatus:
+		return []interface{}{int64(record.CloseStatus)}, true
+	case definition.HistoryLength:
+		return []interface{}{record.HistoryLength}, true
+	}
+	encoded, ok := record.SearchAttributes[field]
+	if !ok {
+		return nil, false
+	}
+	// the search attributes are archived as their JSON encoding
+	decoder := json.NewDecoder(bytes.NewReader([]byte(encoded)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []interface{}{encoded}, true
+	}
+	if list, ok := value.([]interface{}); ok {
+		for i := range list {
+			list[i] = normalizeNumber(list[i])
+		}
+		return list, true
+	}
+	return []interface{}{normalizeNumber(value)}, true
+}
+
+func normalizeNumber(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
+
+func containsAny(fieldValues []interface{}, values []interface{}) bool {
+	for _, fieldValue := range fieldValues {
+		for _, value := range values {
+			if result, comparable := compare(fieldValue, value); comparable && result == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compare compares a value of a record with a value of the query, strings in RFC3339 format are compared as
+// timestamps with each other and with integers, which are unix nanoseconds, to support the datetime search attributes
+func compare(fieldValue, value interface{}) (int, bool) {
+	switch fieldValue := fieldValue.(type) {
+	case int64:
+		switch value := value.(type) {
+		case int64:
+			return compareInt64(fieldValue, value), true
+		case float64:
+			return compareFloat64(float64(fieldValue), value), true
+		case string:
+			if timestamp, ok := parseTimestamp(value); ok {
+				return compareInt64(fieldValue, timestamp), true
+			}
+		}
+	case float64:
+		switch value := value.(type) {
+		case int64:
+			return compareFloat64(fieldValue, float64(value)), true
+		case float64:
+			return compareFloat64(fieldValue, value), true
+		}
+	case string:
+		switch value := value.(type) {
+		case string:
+			fieldTimestamp, fieldIsTime := parseTimestamp(fieldValue)
+			timestamp, isTime := parseTimestamp(value)
+			if fieldIsTime && isTime {
+				return compareInt64(fieldTimestamp, timestamp), true
+			}
+			return strings.Compare(fieldValue, value), true
+		case int64:
+			if fieldTimestamp, ok := parseTimestamp(fieldValue); ok {
+				return compareInt64(fieldTimestamp, value), true
+			}
+		}
+	case bool:
+		if value, ok := value.(bool); ok {
+			if fieldValue == value {
+				return 0, true
+			}
+			if value {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func parseTimestamp(value string) (int64, bool) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, false
+	}
+	return t.UnixNano(), true
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package objectstore
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/types"
+)
+
+type queryParserSuite struct {
+	*require.Assertions
+	suite.Suite
+
+	parser QueryParser
+	record *visibilityRecord
+}
+
+func TestQueryParserSuite(t *testing.T) {
+	suite.Run(t, new(queryParserSuite))
+}
+
+func (s *queryParserSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.parser = NewQueryParser()
+	s.record = &visibilityRecord{
+		DomainID:           testDomainID,
+		WorkflowID:         "wid",
+		RunID:              "rid",
+		WorkflowTypeName:   "wf-type",
+		StartTimestamp:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+		ExecutionTimestamp: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+		CloseTimestamp:     time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano(),
+		CloseStatus:        types.WorkflowExecutionCloseStatusFailed,
+		HistoryLength:      10,
+		SearchAttributes: map[string]string{
+			"CustomKeywordField":  `["a","b"]`,
+			"CustomStringField":   `"some text"`,
+			"CustomIntField":      `5`,
+			"CustomDoubleField":   `1.5`,
+			"CustomBoolField":     `true`,
+			"CustomDatetimeField": `"2020-01-01T12:00:00Z"`,
+		},
+	}
+}
+
+func (s *queryParserSuite) TestParse_Index() {
+	testCases := map[string]struct {
+		query              string
+		expectedIndex      string
+		expectedIndexValue string
+	}{
+		"no index": {
+			query:         "HistoryLength > 5",
+			expectedIndex: indexAll,
+		},
+		"workflow type": {
+			query:              "WorkflowType = 'wf-type' and CloseStatus = 'failed'",
+			expectedIndex:      definition.WorkflowType,
+			expectedIndexValue: "wf-type",
+		},
+		"workflow ID is preferred": {
+			query:              "CloseStatus = 1 and (WorkflowType = 'wf-type' and WorkflowID = 'wid')",
+			expectedIndex:      definition.WorkflowID,
+			expectedIndexValue: "wid",
+		},
+		"close status": {
+			query:              "CloseStatus = 'failed'",
+			expectedIndex:      definition.CloseStatus,
+			expectedIndexValue: "FAILED",
+		},
+		"or is not indexed": {
+			query:         "WorkflowType = 'wf-type' or WorkflowID = 'wid'",
+			expectedIndex: indexAll,
+		},
+		"not equal is not indexed": {
+			query:         "WorkflowType != 'wf-type'",
+			expectedIndex: indexAll,
+		},
+	}
+	for name, tc := range testCases {
+		s.Run(name, func() {
+			parsedQuery, err := s.parser.Parse(tc.query)
+			s.NoError(err)
+			s.Equal(tc.expectedIndex, parsedQuery.index)
+			s.Equal(tc.expectedIndexValue, parsedQuery.indexValue)
+		})
+	}
+}
+
+func (s *queryParserSuite) TestParse_CloseTimeRange() {
+	testCases := map[string]struct {
+		query            string
+		expectedEarliest int64
+		expectedLatest   int64
+		emptyResult      bool
+	}{
+		"no range": {
+			query:            "WorkflowID = 'wid'",
+			expectedEarliest: 0,
+			expectedLatest:   math.MaxInt64,
+		},
+		"greater and less than": {
+			query:            "CloseTime > 100 and CloseTime < 200",
+			expectedEarliest: 101,
+			expectedLatest:   199,
+		},
+		"between": {
+			query:            "CloseTime between '2020-01-01T00:00:00Z' and 2000000000000000000",
+			expectedEarliest: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+			expectedLatest:   2000000000000000000,
+		},
+		"equal": {
+			query:            "CloseTime = 100",
+			expectedEarliest: 100,
+			expectedLatest:   100,
+		},
+		"empty range": {
+			query:            "CloseTime >= 200 and CloseTime <= 100",
+			expectedEarliest: 200,
+			expectedLatest:   100,
+			emptyResult:      true,
+		},
+		"range under or is not used": {
+			query:            "CloseTime >= 200 or WorkflowID = 'wid'",
+			expectedEarliest: 0,
+			expectedLatest:   math.MaxInt64,
+		},
+	}
+	for name, tc := range testCases {
+		s.Run(name, func() {
+			parsedQuery, err := s.parser.Parse(tc.query)
+			s.NoError(err)
+			s.Equal(tc.expectedEarliest, parsedQuery.earliestCloseTime)
+			s.Equal(tc.expectedLatest, parsedQuery.latestCloseTime)
+			s.Equal(tc.emptyResult, parsedQuery.emptyResult)
+		})
+	}
+}
+
+func (s *queryParserSuite) TestParse_Invalid() {
+	queries := []string{
+		"WorkflowID",
+		"WorkflowID = 5",
+		"CloseTime > 'yesterday'",
+		"CloseStatus > 'failed'",
+		"CloseStatus = 'unknown'",
+		"CloseStatus between 1 and 2",
+		"HistoryLength = 'ten'",
+		"DomainID = 'domain-id'",
+		"Other.CustomIntField = 5",
+		"WorkflowID like 'wid%'",
+		"WorkflowID = 'wid' order by CloseTime desc",
+		"CustomIntField = CustomDoubleField",
+	}
+	for _, query := range queries {
+		_, err := s.parser.Parse(query)
+		s.Error(err, query)
+	}
+}
+
+func (s *queryParserSuite) TestMatch() {
+	testCases := map[string]bool{
+		"WorkflowID = 'wid'":                                                      true,
+		"WorkflowID = 'other'":                                                    false,
+		"RunID in ('other', 'rid')":                                               true,
+		"WorkflowType not in ('wf-type')":                                         false,
+		"WorkflowType != 'other' and CloseStatus = 'failed'":                      true,
+		"CloseStatus = 0 or CloseStatus = 'timed_out'":                            false,
+		"not (CloseStatus = 'completed')":                                         true,
+		"StartTime >= '2020-01-01T00:00:00Z'":                                     true,
+		"StartTime > '2020-01-01T00:00:00Z'":                                      false,
+		"ExecutionTime < 1577836800000000001":                                     true,
+		"CloseTime between '2020-01-01T00:00:00Z' and '2020-01-03T00:00:00Z'":     true,
+		"CloseTime not between '2020-01-01T00:00:00Z' and '2020-01-03T00:00:00Z'": false,
+		"HistoryLength >= 10 and HistoryLength < 11":                              true,
+		"CustomKeywordField = 'b'":                                                true,
+		"CustomKeywordField = 'c'":                                                false,
+		"CustomKeywordField != 'a'":                                               false,
+		"CustomKeywordField in ('c', 'a')":                                        true,
+		"Attr.CustomStringField = 'some text'":                                    true,
+		"CustomIntField > 4 and CustomIntField <= 5":                              true,
+		"CustomIntField = 5.0":                                                    true,
+		"CustomDoubleField between 1 and 2":                                       true,
+		"CustomDoubleField < 1.5":                                                 false,
+		"CustomBoolField = true":                                                  true,
+		"CustomBoolField = false":                                                 false,
+		"CustomDatetimeField > '2020-01-01T00:00:00Z'":                            true,
+		"CustomDatetimeField < 1577836800000000000":                               false,
+		"MissingField = 'value'":                                                  false,
+		"MissingField != 'value'":                                                 true,
+		"MissingField > 0":                                                        false,
+		"CustomIntField = 'five'":                                                 false,
+		"(WorkflowID = 'other' or CustomIntField = 5) and RunID = 'rid'":          true,
+	}
+	for query, expected := range testCases {
+		parsedQuery, err := s.parser.Parse(query)
+		s.NoError(err, query)
+		s.Equal(expected, parsedQuery.filter.match(s.record), query)
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/archiver/s3store"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	// URIScheme is the scheme for the S3-compatible object store implementation
+	URIScheme = "objectstore"
+
+	defaultRegion         = "us-east-1"
+	defaultRequestTimeout = 60 * time.Second
+
+	visibilityKeyword = "visibility"
+	// indexAll is the index of all the visibility records of a domain
+	indexAll = "all"
+	// the index of a record is followed by the inverted close time so that the latest records are listed first
+	invertedTimestampFormat = "%019d"
+)
+
+// the visibility records are archived under each of the indexes
+var visibilityIndexes = []string{indexAll, definition.WorkflowID, definition.WorkflowType, definition.CloseStatus}
+
+func validateConfig(cfg *config.ObjectstoreArchiver) error {
+	if cfg == nil || cfg.Endpoint == "" {
+		return errEmptyEndpoint
+	}
+	return nil
+}
+
+// NewS3Client creates a client of the S3-compatible object store of the config
+func NewS3Client(cfg *config.ObjectstoreArchiver) (s3iface.S3API, error) {
+	sess, err := session.NewSession(newS3Config(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
+func newS3Config(cfg *config.ObjectstoreArchiver) *aws.Config {
+	return &aws.Config{
+		Endpoint:         aws.String(cfg.Endpoint),
+		Region:           aws.String(regionOrDefault(cfg.Region)),
+		S3ForcePathStyle: aws.Bool(!cfg.VirtualHostedStyle),
+	}
+}
+
+func regionOrDefault(region string) string {
+	if region == "" {
+		return defaultRegion
+	}
+	return region
+}
+
+// Only validates the scheme and buckets are passed
+func softValidateURI(URI archiver.URI) error {
+	if URI.Scheme() != URIScheme {
+		return archiver.ErrURISchemeMismatch
+	}
+	if len(URI.Hostname()) == 0 {
+		return errNoBucketSpecified
+	}
+	return nil
+}
+
+// toS3URI converts the URI to the URI of the s3store archiver which stores the histories
+func toS3URI(URI archiver.URI) (archiver.URI, error) {
+	if err := softValidateURI(URI); err != nil {
+		return nil, err
+	}
+	return archiver.NewURI(s3store.URIScheme + "://" + URI.Hostname() + URI.Path())
+}
+
+// Key construction
+
+// constructVisibilityIndexPrefix returns the prefix of the records of an index value, e.g.
+// <path>/<domain-id>/visibility/WorkflowType/<escaped workflow type>/
+func constructVisibilityIndexPrefix(path, domainID, index, value string) string {
+	parts := []string{path, domainID, visibilityKeyword, index}
+	if index != indexAll {
+		parts = append(parts, url.PathEscape(value))
+	}
+	return strings.TrimLeft(strings.Join(parts, "/"), "/") + "/"
+}
+
+func constructVisibilityKey(indexPrefix string, closeTimestamp int64, runID string) string {
+	return indexPrefix + invertTimestamp(closeTimestamp) + "_" + runID
+}
+
+// constructVisibilityStartAfter returns the key after which the records closed no later than latestCloseTime are listed
+func constructVisibilityStartAfter(indexPrefix string, latestCloseTime int64) string {
+	return indexPrefix + invertTimestamp(latestCloseTime)
+}
+
+func invertTimestamp(timestamp int64) string {
+	if timestamp < 0 {
+		timestamp = 0
+	}
+	return fmt.Sprintf(invertedTimestampFormat, math.MaxInt64-timestamp)
+}
+
+// closeTimestampFromKey returns the close time of the record of the key
+func closeTimestampFromKey(indexPrefix, key string) (int64, error) {
+	name := strings.TrimPrefix(key, indexPrefix)
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		name = name[:i]
+	}
+	inverted, err := strconv.ParseInt(name, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid visibility record key %v: %v", key, err)
+	}
+	return math.MaxInt64 - inverted, nil
+}
+
+func visibilityIndexValue(index string, request *archiver.ArchiveVisibilityRequest) string {
+	switch index {
+	case definition.WorkflowID:
+		return request.WorkflowID
+	case definition.WorkflowType:
+		return request.WorkflowTypeName
+	case definition.CloseStatus:
+		return request.CloseStatus.String()
+	default:
+		return ""
+	}
+}
+
+// encoding & decoding util
+
+func encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func decodeVisibilityRecord(data []byte) (*visibilityRecord, error) {
+	record := &visibilityRecord{}
+	err := json.Unmarshal(data, record)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func serializeQueryVisibilityToken(token *queryVisibilityToken) ([]byte, error) {
+	if token == nil {
+		return nil, nil
+	}
+	return json.Marshal(token)
+}
+
+func deserializeQueryVisibilityToken(data []byte) (*queryVisibilityToken, error) {
+	token := &queryVisibilityToken{}
+	err := json.Unmarshal(data, token)
+	return token, err
+}
+
+// object store operations
+
+func ensureContextTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, defaultRequestTimeout)
+}
+
+func bucketExists(ctx context.Context, s3cli s3iface.S3API, URI archiver.URI) error {
+	ctx, cancel := ensureContextTimeout(ctx)
+	defer cancel()
+	_, err := s3cli.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(URI.Hostname()),
+	})
+	if err == nil {
+		return nil
+	}
+	if isNotFoundError(err) {
+		return errBucketNotExists
+	}
+	return err
+}
+
+func upload(ctx context.Context, s3cli s3iface.S3API, URI archiver.URI, key string, data []byte) error {
+	ctx, cancel := ensureContextTimeout(ctx)
+	defer cancel()
+	_, err := s3cli.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(URI.Hostname()),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchBucket {
+			return &types.BadRequestError{Message: errBucketNotExists.Error()}
+		}
+		return err
+	}
+	return nil
+}
+
+func download(ctx context.Context, s3cli s3iface.S3API, URI archiver.URI, key string) ([]byte, error) {
+	ctx, cancel := ensureContextTimeout(ctx)
+	defer cancel()
+	result, err := s3cli.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(URI.Hostname()),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3.ErrCodeNoSuchBucket:
+				return nil, &types.BadRequestError{Message: errBucketNotExists.Error()}
+			case s3.ErrCodeNoSuchKey:
+				return nil, &types.EntityNotExistsError{Message: err.Error()}
+			}
+		}
+		return nil, err
+	}
+	defer result.Body.Close()
+	return ioutil.ReadAll(result.Body)
+}
+
+func isNotFoundError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && (aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchBucket)
+}
+
+// IsRetryableError returns whether a request to the object store which failed with the error can be retried
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		return isStatusCodeRetryable(aerr) || request.IsErrorRetryable(aerr) || request.IsErrorThrottle(aerr)
+	}
+	return false
+}
+
+func isStatusCodeRetryable(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		if rerr, ok := err.(awserr.RequestFailure); ok {
+			if rerr.StatusCode() == 429 || (rerr.StatusCode() >= 500 && rerr.StatusCode() != 501) {
+				return true
+			}
+		}
+		return isStatusCodeRetryable(aerr.OrigErr())
+	}
+	return false
+}
+
+func convertToExecutionInfo(record *visibilityRecord) *types.WorkflowExecutionInfo {
+	return &types.WorkflowExecutionInfo{
+		Execution: &types.WorkflowExecution{
+			WorkflowID: record.WorkflowID,
+			RunID:      record.RunID,
+		},
+		Type: &types.WorkflowType{
+			Name: record.WorkflowTypeName,
+		},
+		StartTime:     common.Int64Ptr(record.StartTimestamp),
+		ExecutionTime: common.Int64Ptr(record.ExecutionTimestamp),
+		CloseTime:     common.Int64Ptr(record.CloseTimestamp),
+		CloseStatus:   record.CloseStatus.Ptr(),
+		HistoryLength: record.HistoryLength,
+		Memo:          record.Memo,
+		SearchAttributes: &types.SearchAttributes{
+			IndexedFields: archiver.ConvertSearchAttrToBytes(record.SearchAttributes),
+		},
+	}
+}
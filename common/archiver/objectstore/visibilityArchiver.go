@@ -0,0 +1,265 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package objectstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	visibilityArchiver struct {
+		container   *archiver.VisibilityBootstrapContainer
+		s3cli       s3iface.S3API
+		queryParser QueryParser
+	}
+
+	visibilityRecord archiver.ArchiveVisibilityRequest
+
+	queryVisibilityToken struct {
+		// LastKey is the key of the last record read by the previous page
+		LastKey string
+	}
+)
+
+const (
+	errEncodeVisibilityRecord = "failed to encode visibility record"
+	errWriteKey               = "failed to write visibility record to object store"
+
+	// a query page reads at most pageSize * maxReadRecordsPerPageFactor records, if the filter of the query
+	// doesn't match enough of them, the page is returned with fewer records and a next page token
+	maxReadRecordsPerPageFactor = 10
+)
+
+// NewVisibilityArchiver creates a new archiver.VisibilityArchiver based on an S3-compatible object store
+func NewVisibilityArchiver(
+	container *archiver.VisibilityBootstrapContainer,
+	config *config.ObjectstoreArchiver,
+) (archiver.VisibilityArchiver, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	s3cli, err := NewS3Client(config)
+	if err != nil {
+		return nil, err
+	}
+	return newVisibilityArchiver(container, s3cli), nil
+}
+
+func newVisibilityArchiver(
+	container *archiver.VisibilityBootstrapContainer,
+	s3cli s3iface.S3API,
+) *visibilityArchiver {
+	return &visibilityArchiver{
+		container:   container,
+		s3cli:       s3cli,
+		queryParser: NewQueryParser(),
+	}
+}
+
+// Archive writes the visibility record under each of the visibility indexes
+func (v *visibilityArchiver) Archive(
+	ctx context.Context,
+	URI archiver.URI,
+	request *archiver.ArchiveVisibilityRequest,
+	opts ...archiver.ArchiveOption,
+) (err error) {
+	scope := v.container.MetricsClient.Scope(metrics.VisibilityArchiverScope, metrics.DomainTag(request.DomainName))
+	featureCatalog := archiver.GetFeatureCatalog(opts...)
+	sw := scope.StartTimer(metrics.CadenceLatency)
+	logger := archiver.TagLoggerWithArchiveVisibilityRequestAndURI(v.container.Logger, request, URI.String())
+	archiveFailReason := ""
+	defer func() {
+		sw.Stop()
+		if err != nil {
+			if IsRetryableError(err) {
+				scope.IncCounter(metrics.VisibilityArchiverArchiveTransientErrorCount)
+				logger.Error(archiver.ArchiveTransientErrorMsg, tag.ArchivalArchiveFailReason(archiveFailReason), tag.Error(err))
+			} else {
+				scope.IncCounter(metrics.VisibilityArchiverArchiveNonRetryableErrorCount)
+				logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(archiveFailReason), tag.Error(err))
+				if featureCatalog.NonRetriableError != nil {
+					err = featureCatalog.NonRetriableError()
+				}
+			}
+		}
+	}()
+
+	if err := softValidateURI(URI); err != nil {
+		archiveFailReason = archiver.ErrReasonInvalidURI
+		return err
+	}
+
+	if err := archiver.ValidateVisibilityArchivalRequest(request); err != nil {
+		archiveFailReason = archiver.ErrReasonInvalidArchiveRequest
+		return err
+	}
+
+	encodedVisibilityRecord, err := encode(request)
+	if err != nil {
+		archiveFailReason = errEncodeVisibilityRecord
+		return err
+	}
+	encodedVisibilityRecord, err = archiver.EncryptArchive(v.container.KeyProvider, encodedVisibilityRecord, request.DomainName)
+	if err != nil {
+		archiveFailReason = archiver.ErrReasonEncryptArchive
+		return err
+	}
+	for _, index := range visibilityIndexes {
+		prefix := constructVisibilityIndexPrefix(URI.Path(), request.DomainID, index, visibilityIndexValue(index, request))
+		key := constructVisibilityKey(prefix, request.CloseTimestamp, request.RunID)
+		if err := upload(ctx, v.s3cli, URI, key, encodedVisibilityRecord); err != nil {
+			archiveFailReason = errWriteKey
+			return err
+		}
+	}
+	scope.IncCounter(metrics.VisibilityArchiveSuccessCount)
+	return nil
+}
+
+// Query lists the records of the index selected by the query in the close time range of the query,
+// and returns the ones matching the query, the latest closed first
+func (v *visibilityArchiver) Query(
+	ctx context.Context,
+	URI archiver.URI,
+	request *archiver.QueryVisibilityRequest,
+) (*archiver.QueryVisibilityResponse, error) {
+	if err := softValidateURI(URI); err != nil {
+		return nil, &types.BadRequestError{Message: archiver.ErrInvalidURI.Error()}
+	}
+
+	if err := archiver.ValidateQueryRequest(request); err != nil {
+		return nil, &types.BadRequestError{Message: archiver.ErrInvalidQueryVisibilityRequest.Error()}
+	}
+
+	parsedQuery, err := v.queryParser.Parse(request.Query)
+	if err != nil {
+		return nil, &types.BadRequestError{Message: err.Error()}
+	}
+	if parsedQuery.emptyResult {
+		return &archiver.QueryVisibilityResponse{}, nil
+	}
+
+	prefix := constructVisibilityIndexPrefix(URI.Path(), request.DomainID, parsedQuery.index, parsedQuery.indexValue)
+	startAfter := constructVisibilityStartAfter(prefix, parsedQuery.latestCloseTime)
+	if request.NextPageToken != nil {
+		token, err := deserializeQueryVisibilityToken(request.NextPageToken)
+		if err != nil {
+			return nil, &types.BadRequestError{Message: archiver.ErrNextPageTokenCorrupted.Error()}
+		}
+		startAfter = token.LastKey
+	}
+	return v.query(ctx, URI, prefix, startAfter, parsedQuery, request.PageSize)
+}
+
+func (v *visibilityArchiver) query(
+	ctx context.Context,
+	URI archiver.URI,
+	prefix string,
+	startAfter string,
+	parsedQuery *parsedQuery,
+	pageSize int,
+) (*archiver.QueryVisibilityResponse, error) {
+	ctx, cancel := ensureContextTimeout(ctx)
+	defer cancel()
+
+	response := &archiver.QueryVisibilityResponse{}
+	maxReadRecords := pageSize * maxReadRecordsPerPageFactor
+	readRecords := 0
+	var continuationToken *string
+	for {
+		results, err := v.s3cli.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(URI.Hostname()),
+			Prefix:            aws.String(prefix),
+			StartAfter:        aws.String(startAfter),
+			MaxKeys:           aws.Int64(int64(pageSize)),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			if IsRetryableError(err) {
+				return nil, &types.InternalServiceError{Message: err.Error()}
+			}
+			return nil, &types.BadRequestError{Message: err.Error()}
+		}
+		for _, item := range results.Contents {
+			key := aws.StringValue(item.Key)
+			closeTimestamp, err := closeTimestampFromKey(prefix, key)
+			if err != nil {
+				return nil, &types.InternalServiceError{Message: err.Error()}
+			}
+			if closeTimestamp < parsedQuery.earliestCloseTime {
+				// the remaining records are closed even earlier
+				return response, nil
+			}
+
+			encodedRecord, err := download(ctx, v.s3cli, URI, key)
+			if err != nil {
+				var notExistsErr *types.EntityNotExistsError
+				if !errors.As(err, &notExistsErr) {
+					return nil, &types.InternalServiceError{Message: err.Error()}
+				}
+				// the record is deleted after it's listed, e.g. by the lifecycle rule of the bucket
+			} else {
+				encodedRecord, err = archiver.DecryptArchive(v.container.KeyProvider, encodedRecord)
+				if err != nil {
+					return nil, &types.InternalServiceError{Message: err.Error()}
+				}
+				record, err := decodeVisibilityRecord(encodedRecord)
+				if err != nil {
+					return nil, &types.InternalServiceError{Message: err.Error()}
+				}
+				if parsedQuery.filter == nil || parsedQuery.filter.match(record) {
+					response.Executions = append(response.Executions, convertToExecutionInfo(record))
+				}
+			}
+
+			readRecords++
+			if len(response.Executions) == pageSize || readRecords == maxReadRecords {
+				response.NextPageToken, err = serializeQueryVisibilityToken(&queryVisibilityToken{LastKey: key})
+				if err != nil {
+					return nil, &types.InternalServiceError{Message: err.Error()}
+				}
+				return response, nil
+			}
+		}
+		if !aws.BoolValue(results.IsTruncated) {
+			return response, nil
+		}
+		continuationToken = results.NextContinuationToken
+	}
+}
+
+func (v *visibilityArchiver) ValidateURI(URI archiver.URI) error {
+	if err := softValidateURI(URI); err != nil {
+		return err
+	}
+	return bucketExists(context.TODO(), v.s3cli, URI)
+}
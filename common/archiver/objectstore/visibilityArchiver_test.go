@@ -0,0 +1,394 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	testDomainID  = "test-domain-id"
+	testBucketURI = "objectstore://test-bucket/archive"
+)
+
+type (
+	visibilityArchiverSuite struct {
+		*require.Assertions
+		suite.Suite
+
+		s3cli     *fakeS3
+		container *archiver.VisibilityBootstrapContainer
+		URI       archiver.URI
+		archiver  *visibilityArchiver
+	}
+
+	// fakeS3 is an in-memory object store with a single bucket
+	fakeS3 struct {
+		s3iface.S3API
+
+		sync.Mutex
+		bucket    string
+		objects   map[string][]byte
+		listCalls int
+		getCalls  int
+	}
+)
+
+func TestVisibilityArchiverSuite(t *testing.T) {
+	suite.Run(t, new(visibilityArchiverSuite))
+}
+
+func (s *visibilityArchiverSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	var err error
+	s.URI, err = archiver.NewURI(testBucketURI)
+	s.NoError(err)
+	s.s3cli = newFakeS3(s.URI.Hostname())
+	s.container = &archiver.VisibilityBootstrapContainer{
+		Logger:        testlogger.New(s.T()),
+		MetricsClient: metrics.NewClient(tally.NewTestScope("test", nil), metrics.VisibilityArchiverScope),
+	}
+	s.archiver = newVisibilityArchiver(s.container, s.s3cli)
+}
+
+func (s *visibilityArchiverSuite) TestValidateURI() {
+	testCases := []struct {
+		URI         string
+		expectedErr error
+	}{
+		{URI: "s3://test-bucket", expectedErr: archiver.ErrURISchemeMismatch},
+		{URI: "objectstore:///archive", expectedErr: errNoBucketSpecified},
+		{URI: "objectstore://other-bucket/archive", expectedErr: errBucketNotExists},
+		{URI: testBucketURI, expectedErr: nil},
+	}
+	for _, tc := range testCases {
+		URI, err := archiver.NewURI(tc.URI)
+		s.NoError(err)
+		s.Equal(tc.expectedErr, s.archiver.ValidateURI(URI))
+	}
+}
+
+func (s *visibilityArchiverSuite) TestArchive() {
+	request := s.newArchiveRequest("wid/1", "rid", "wf-type", 100, types.WorkflowExecutionCloseStatusCompleted)
+	s.NoError(s.archiver.Archive(context.Background(), s.URI, request))
+
+	inverted := invertTimestamp(100)
+	s.ElementsMatch([]string{
+		"archive/test-domain-id/visibility/all/" + inverted + "_rid",
+		"archive/test-domain-id/visibility/WorkflowID/wid%2F1/" + inverted + "_rid",
+		"archive/test-domain-id/visibility/WorkflowType/wf-type/" + inverted + "_rid",
+		"archive/test-domain-id/visibility/CloseStatus/COMPLETED/" + inverted + "_rid",
+	}, s.s3cli.keys())
+}
+
+func (s *visibilityArchiverSuite) TestArchive_InvalidURI() {
+	URI, err := archiver.NewURI("objectstore:///archive")
+	s.NoError(err)
+	request := s.newArchiveRequest("wid", "rid", "wf-type", 100, types.WorkflowExecutionCloseStatusCompleted)
+	s.Error(s.archiver.Archive(context.Background(), URI, request))
+	s.Empty(s.s3cli.keys())
+}
+
+func (s *visibilityArchiverSuite) TestQuery() {
+	s.archiveRecords()
+
+	testCases := map[string]struct {
+		query       string
+		expectedIDs []string
+	}{
+		"all records latest closed first": {
+			query:       "CloseTime >= 0",
+			expectedIDs: []string{"wid-4", "wid-3", "wid-2", "wid-1", "wid-0"},
+		},
+		"workflow type and close status": {
+			query:       "WorkflowType = 'type-a' and CloseStatus = 'failed'",
+			expectedIDs: []string{"wid-3", "wid-1"},
+		},
+		"close time range": {
+			query:       "CloseTime between 200 and 400 and WorkflowType != 'type-b'",
+			expectedIDs: []string{"wid-3", "wid-1"},
+		},
+		"workflow ID": {
+			query:       "WorkflowID = 'wid-2'",
+			expectedIDs: []string{"wid-2"},
+		},
+		"search attribute": {
+			query:       "CustomIntField >= 3 or RunID = 'rid-0'",
+			expectedIDs: []string{"wid-4", "wid-3", "wid-0"},
+		},
+		"empty range": {
+			query: "CloseTime > 500",
+		},
+	}
+	for name, tc := range testCases {
+		s.Run(name, func() {
+			response, err := s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+				DomainID: testDomainID,
+				PageSize: 10,
+				Query:    tc.query,
+			})
+			s.NoError(err)
+			s.Nil(response.NextPageToken)
+			s.Equal(tc.expectedIDs, workflowIDs(response.Executions))
+		})
+	}
+}
+
+func (s *visibilityArchiverSuite) TestQuery_ListsOnlyIndexAndTimeRange() {
+	s.archiveRecords()
+
+	// the records of other workflow types and the records closed after the range are not read
+	response, err := s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+		DomainID: testDomainID,
+		PageSize: 1,
+		Query:    "WorkflowType = 'type-a' and CloseTime <= 400",
+	})
+	s.NoError(err)
+	s.Equal([]string{"wid-3"}, workflowIDs(response.Executions))
+	s.NotNil(response.NextPageToken)
+	s.Equal(1, s.s3cli.listCalls)
+	s.Equal(1, s.s3cli.getCalls)
+
+	response, err = s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+		DomainID:      testDomainID,
+		PageSize:      1,
+		Query:         "WorkflowType = 'type-a' and CloseTime <= 400",
+		NextPageToken: response.NextPageToken,
+	})
+	s.NoError(err)
+	s.Equal([]string{"wid-1"}, workflowIDs(response.Executions))
+}
+
+func (s *visibilityArchiverSuite) TestQuery_Paging() {
+	s.archiveRecords()
+
+	var ids []string
+	var token []byte
+	for page := 0; page == 0 || token != nil; page++ {
+		s.Less(page, 10)
+		response, err := s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+			DomainID:      testDomainID,
+			PageSize:      2,
+			Query:         "CloseStatus != 'completed'",
+			NextPageToken: token,
+		})
+		s.NoError(err)
+		ids = append(ids, workflowIDs(response.Executions)...)
+		token = response.NextPageToken
+	}
+	s.Equal([]string{"wid-4", "wid-3", "wid-1"}, ids)
+}
+
+func (s *visibilityArchiverSuite) TestQuery_MaxReadRecords() {
+	for i := 0; i < 2*maxReadRecordsPerPageFactor; i++ {
+		s.NoError(s.archiver.Archive(context.Background(), s.URI,
+			s.newArchiveRequest(fmt.Sprintf("wid-%v", i), "rid", "wf-type", int64(i+1), types.WorkflowExecutionCloseStatusCompleted)))
+	}
+
+	response, err := s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+		DomainID: testDomainID,
+		PageSize: 1,
+		Query:    "WorkflowID = 'wid-0' or WorkflowType = 'other'",
+	})
+	s.NoError(err)
+	s.Empty(response.Executions)
+	s.NotNil(response.NextPageToken)
+
+	response, err = s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+		DomainID:      testDomainID,
+		PageSize:      1,
+		Query:         "WorkflowID = 'wid-0' or WorkflowType = 'other'",
+		NextPageToken: response.NextPageToken,
+	})
+	s.NoError(err)
+	s.Equal([]string{"wid-0"}, workflowIDs(response.Executions))
+}
+
+func (s *visibilityArchiverSuite) TestQuery_InvalidRequest() {
+	_, err := s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+		DomainID: testDomainID,
+		PageSize: 10,
+		Query:    "WorkflowID = 'wid' order by StartTime",
+	})
+	s.IsType(&types.BadRequestError{}, err)
+
+	_, err = s.archiver.Query(context.Background(), s.URI, &archiver.QueryVisibilityRequest{
+		DomainID:      testDomainID,
+		PageSize:      10,
+		Query:         "WorkflowID = 'wid'",
+		NextPageToken: []byte("invalid"),
+	})
+	s.IsType(&types.BadRequestError{}, err)
+}
+
+// archiveRecords archives the records of 5 workflows closed at 100, 200, 300, 400 and 500
+func (s *visibilityArchiverSuite) archiveRecords() {
+	for i := 0; i < 5; i++ {
+		workflowType := "type-a"
+		if i%2 == 0 {
+			workflowType = "type-b"
+		}
+		status := types.WorkflowExecutionCloseStatusFailed
+		if i == 0 || i == 2 {
+			status = types.WorkflowExecutionCloseStatusCompleted
+		}
+		request := s.newArchiveRequest(fmt.Sprintf("wid-%v", i), fmt.Sprintf("rid-%v", i), workflowType, int64((i+1)*100), status)
+		request.SearchAttributes = map[string]string{"CustomIntField": fmt.Sprintf("%v", i)}
+		s.NoError(s.archiver.Archive(context.Background(), s.URI, request))
+	}
+	s.s3cli.listCalls = 0
+	s.s3cli.getCalls = 0
+}
+
+func (s *visibilityArchiverSuite) newArchiveRequest(
+	workflowID string,
+	runID string,
+	workflowType string,
+	closeTimestamp int64,
+	status types.WorkflowExecutionCloseStatus,
+) *archiver.ArchiveVisibilityRequest {
+	return &archiver.ArchiveVisibilityRequest{
+		DomainID:         testDomainID,
+		DomainName:       "test-domain",
+		WorkflowID:       workflowID,
+		RunID:            runID,
+		WorkflowTypeName: workflowType,
+		StartTimestamp:   closeTimestamp - int64(time.Millisecond),
+		CloseTimestamp:   closeTimestamp,
+		CloseStatus:      status,
+		HistoryLength:    10,
+	}
+}
+
+func workflowIDs(executions []*types.WorkflowExecutionInfo) []string {
+	var ids []string
+	for _, execution := range executions {
+		ids = append(ids, execution.GetExecution().GetWorkflowID())
+	}
+	return ids
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{
+		bucket:  bucket,
+		objects: make(map[string][]byte),
+	}
+}
+
+func (f *fakeS3) keys() []string {
+	f.Lock()
+	defer f.Unlock()
+	var keys []string
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeS3) checkBucket(bucket *string) error {
+	if aws.StringValue(bucket) != f.bucket {
+		return awserr.New(s3.ErrCodeNoSuchBucket, "no such bucket", nil)
+	}
+	return nil
+}
+
+func (f *fakeS3) HeadBucketWithContext(_ aws.Context, input *s3.HeadBucketInput, _ ...request.Option) (*s3.HeadBucketOutput, error) {
+	if err := f.checkBucket(input.Bucket); err != nil {
+		return nil, awserr.New("NotFound", "not found", nil)
+	}
+	return &s3.HeadBucketOutput{}, nil
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, input *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	if err := f.checkBucket(input.Bucket); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.objects[aws.StringValue(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	if err := f.checkBucket(input.Bucket); err != nil {
+		return nil, err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.getCalls++
+	data, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// ListObjectsV2WithContext lists the keys in lexicographical order, the continuation token is the last listed key
+func (f *fakeS3) ListObjectsV2WithContext(_ aws.Context, input *s3.ListObjectsV2Input, _ ...request.Option) (*s3.ListObjectsV2Output, error) {
+	if err := f.checkBucket(input.Bucket); err != nil {
+		return nil, err
+	}
+	f.listCalls++
+	startAfter := aws.StringValue(input.StartAfter)
+	if input.ContinuationToken != nil {
+		startAfter = aws.StringValue(input.ContinuationToken)
+	}
+	output := &s3.ListObjectsV2Output{IsTruncated: aws.Bool(false)}
+	for _, key := range f.keys() {
+		if !strings.HasPrefix(key, aws.StringValue(input.Prefix)) || key <= startAfter {
+			continue
+		}
+		if int64(len(output.Contents)) == aws.Int64Value(input.MaxKeys) {
+			output.IsTruncated = aws.Bool(true)
+			output.NextContinuationToken = output.Contents[len(output.Contents)-1].Key
+			break
+		}
+		output.Contents = append(output.Contents, &s3.Object{Key: aws.String(key)})
+	}
+	return output, nil
+}
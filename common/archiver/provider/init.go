@@ -27,6 +27,7 @@ import (
 
 	"github.com/uber/cadence/common/archiver"
 	"github.com/uber/cadence/common/archiver/filestore"
+	"github.com/uber/cadence/common/archiver/objectstore"
 	"github.com/uber/cadence/common/archiver/s3store"
 	"github.com/uber/cadence/common/config"
 )
@@ -58,6 +59,13 @@ func init() {
 		}
 		return s3store.NewHistoryArchiver(container, out)
 	}))
+	must(RegisterHistoryArchiver(objectstore.URIScheme, config.ObjectstoreConfig, func(cfg *config.YamlNode, container *archiver.HistoryBootstrapContainer) (archiver.HistoryArchiver, error) {
+		var out *config.ObjectstoreArchiver
+		if err := cfg.Decode(&out); err != nil {
+			return nil, fmt.Errorf("bad config: %w", err)
+		}
+		return objectstore.NewHistoryArchiver(container, out)
+	}))
 
 	must(RegisterVisibilityArchiver(filestore.URIScheme, config.FilestoreConfig, func(cfg *config.YamlNode, container *archiver.VisibilityBootstrapContainer) (archiver.VisibilityArchiver, error) {
 		var out *config.FilestoreArchiver
@@ -73,4 +81,11 @@ func init() {
 		}
 		return s3store.NewVisibilityArchiver(container, out)
 	}))
+	must(RegisterVisibilityArchiver(objectstore.URIScheme, config.ObjectstoreConfig, func(cfg *config.YamlNode, container *archiver.VisibilityBootstrapContainer) (archiver.VisibilityArchiver, error) {
+		var out *config.ObjectstoreArchiver
+		if err := cfg.Decode(&out); err != nil {
+			return nil, fmt.Errorf("bad config: %w", err)
+		}
+		return objectstore.NewVisibilityArchiver(container, out)
+	}))
 }
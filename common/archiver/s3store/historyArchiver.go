@@ -187,6 +187,11 @@ func (h *historyArchiver) Archive(
 			logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(errEncodeHistory), tag.Error(err))
 			return err
 		}
+		encodedHistoryBlob, err = archiver.EncryptArchive(h.container.KeyProvider, encodedHistoryBlob, request.DomainName)
+		if err != nil {
+			logger.Error(archiver.ArchiveNonRetriableErrorMsg, tag.ArchivalArchiveFailReason(archiver.ErrReasonEncryptArchive), tag.Error(err))
+			return err
+		}
 
 		key := constructHistoryKey(URI.Path(), request.DomainID, request.WorkflowID, request.RunID, request.CloseFailoverVersion, progress.BatchIdx)
 
@@ -319,6 +324,10 @@ func (h *historyArchiver) Get(
 			}
 		}
 
+		encodedRecord, err = archiver.DecryptArchive(h.container.KeyProvider, encodedRecord)
+		if err != nil {
+			return nil, &types.InternalServiceError{Message: err.Error()}
+		}
 		historyBlob, err := decodeHistoryBlob(encodedRecord)
 		if err != nil {
 			return nil, &types.InternalServiceError{Message: err.Error()}
@@ -136,6 +136,11 @@ func (v *visibilityArchiver) Archive(
 		archiveFailReason = errEncodeVisibilityRecord
 		return err
 	}
+	encodedVisibilityRecord, err = archiver.EncryptArchive(v.container.KeyProvider, encodedVisibilityRecord, request.DomainName)
+	if err != nil {
+		archiveFailReason = archiver.ErrReasonEncryptArchive
+		return err
+	}
 	indexes := createIndexesToArchive(request)
 	// Upload archive to all indexes
 	for _, element := range indexes {
@@ -234,6 +239,10 @@ func (v *visibilityArchiver) query(
 			return nil, &types.InternalServiceError{Message: err.Error()}
 		}
 
+		encodedRecord, err = archiver.DecryptArchive(v.container.KeyProvider, encodedRecord)
+		if err != nil {
+			return nil, &types.InternalServiceError{Message: err.Error()}
+		}
 		record, err := decodeVisibilityRecord(encodedRecord)
 		if err != nil {
 			return nil, &types.InternalServiceError{Message: err.Error()}
@@ -22,7 +22,9 @@ package archiver
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/types"
@@ -143,6 +145,36 @@ func ValidateQueryRequest(request *QueryVisibilityRequest) error {
 	return nil
 }
 
+// EncryptArchive encrypts an encoded archive with the active key of the domain,
+// the archive is returned as it is if there is no key provider or payloads of the domain are not encrypted
+func EncryptArchive(keyProvider encryption.KeyProvider, data []byte, domainName string) ([]byte, error) {
+	if keyProvider == nil || len(data) == 0 {
+		return data, nil
+	}
+	keyID := keyProvider.ActiveKeyID(domainName)
+	if len(keyID) == 0 {
+		return data, nil
+	}
+	encrypted, err := encryption.Encrypt(keyProvider, keyID, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+	return encrypted, nil
+}
+
+// DecryptArchive decrypts an archive encrypted by EncryptArchive,
+// archives which are not encrypted are returned as they are
+func DecryptArchive(keyProvider encryption.KeyProvider, data []byte) ([]byte, error) {
+	if !encryption.IsEncrypted(data) {
+		return data, nil
+	}
+	decrypted, err := encryption.Decrypt(keyProvider, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+	return decrypted, nil
+}
+
 // ConvertSearchAttrToBytes converts search attribute value from string back to byte array
 func ConvertSearchAttrToBytes(searchAttrStr map[string]string) map[string][]byte {
 	searchAttr := make(map[string][]byte)
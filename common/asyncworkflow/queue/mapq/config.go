@@ -0,0 +1,168 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/uber/cadence/common/mapq/types"
+)
+
+const (
+	defaultConcurrency = 10
+	defaultMaxAttempts = 3
+)
+
+type (
+	queueConfig struct {
+		// Name identifies the queue in the persistence. Producers and consumers with the same name share the requests
+		// so queues with the same name are expected to have the same config.
+		Name string `yaml:"name"`
+		// DispatchRPS is the rate limit of each partition of the queue. Requests are not rate limited if it's 0.
+		DispatchRPS int64 `yaml:"dispatchRPS"`
+		// Concurrency is the number of requests processed concurrently by each partition of the queue
+		Concurrency int `yaml:"concurrency"`
+		// MaxAttempts is the number of times a request is processed before it's dropped
+		MaxAttempts int `yaml:"maxAttempts"`
+		// Domains get dedicated partitions so that bursts of requests of a domain don't delay the requests of others.
+		// Requests of all other domains share a single partition.
+		Domains []domainConfig `yaml:"domains"`
+	}
+
+	domainConfig struct {
+		Name string `yaml:"name"`
+		// DispatchRPS and Concurrency override the ones of the queue for the partition of the domain if set
+		DispatchRPS int64 `yaml:"dispatchRPS"`
+		Concurrency int   `yaml:"concurrency"`
+	}
+)
+
+func (c *queueConfig) ID() string {
+	return fmt.Sprintf("mapq::%s", c.Name)
+}
+
+func (c *queueConfig) validate() error {
+	if err := validatePartitionValue(c.Name); err != nil {
+		return fmt.Errorf("invalid queue name: %w", err)
+	}
+
+	domains := make(map[string]struct{}, len(c.Domains))
+	for _, domain := range c.Domains {
+		if err := validatePartitionValue(domain.Name); err != nil {
+			return fmt.Errorf("invalid domain name: %w", err)
+		}
+		if _, ok := domains[domain.Name]; ok {
+			return fmt.Errorf("domain %s is defined more than once", domain.Name)
+		}
+		domains[domain.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+func (c *queueConfig) setDefaults() {
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultConcurrency
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultMaxAttempts
+	}
+}
+
+// policies returns the MAPQ policies for a two level tree partitioned by queue name and domain.
+// The queue name is a predefined split of the root so that queues sharing the persistence don't collide,
+// and the configured domains are predefined splits of the queue node.
+func (c *queueConfig) policies() []types.NodePolicy {
+	queuePath := fmt.Sprintf("*/%s", c.Name)
+	domainSplits := make([]any, 0, len(c.Domains))
+	for _, domain := range c.Domains {
+		domainSplits = append(domainSplits, domain.Name)
+	}
+
+	policies := []types.NodePolicy{
+		{
+			Path:           "*",
+			SplitPolicy:    &types.SplitPolicy{PredefinedSplits: []any{c.Name}},
+			DispatchPolicy: &types.DispatchPolicy{DispatchRPS: c.DispatchRPS, Concurrency: c.Concurrency},
+		},
+		{
+			// requests are never routed to the catch-all node of the root
+			Path:        "*/*",
+			SplitPolicy: &types.SplitPolicy{Disabled: true},
+		},
+		{
+			Path:        queuePath,
+			SplitPolicy: &types.SplitPolicy{PredefinedSplits: domainSplits},
+		},
+		{
+			Path:        "*/./.",
+			SplitPolicy: &types.SplitPolicy{Disabled: true},
+		},
+	}
+
+	for _, domain := range c.Domains {
+		dispatchPolicy := types.DispatchPolicy{DispatchRPS: c.DispatchRPS, Concurrency: c.Concurrency}
+		if domain.DispatchRPS > 0 {
+			dispatchPolicy.DispatchRPS = domain.DispatchRPS
+		}
+		if domain.Concurrency > 0 {
+			dispatchPolicy.Concurrency = domain.Concurrency
+		}
+		policies = append(policies, types.NodePolicy{
+			Path:           fmt.Sprintf("%s/%s", queuePath, domain.Name),
+			DispatchPolicy: &dispatchPolicy,
+		})
+	}
+
+	return policies
+}
+
+// partitions returns the partitions of the leaf nodes which receive the requests of the queue
+func (c *queueConfig) partitions() []types.ItemPartitions {
+	domains := []string{"*"}
+	for _, domain := range c.Domains {
+		domains = append(domains, domain.Name)
+	}
+
+	partitions := make([]types.ItemPartitions, 0, len(domains))
+	for _, domain := range domains {
+		partitions = append(partitions, types.NewItemPartitions(
+			[]string{partitionKeyQueue, partitionKeyDomain},
+			map[string]any{partitionKeyQueue: c.Name, partitionKeyDomain: domain},
+		))
+	}
+	return partitions
+}
+
+// partition values are used in the paths of the queue tree nodes
+func validatePartitionValue(value string) error {
+	if value == "" {
+		return errors.New("empty value")
+	}
+	if value == "*" || value == "." || strings.Contains(value, "/") {
+		return fmt.Errorf("%q is not allowed", value)
+	}
+	return nil
+}
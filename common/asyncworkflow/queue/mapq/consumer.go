@@ -0,0 +1,196 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/messaging"
+)
+
+const defaultStopTimeout = 10 * time.Second
+
+var errMessageNacked = errors.New("message is nacked")
+
+type (
+	// messageConsumer adapts the leaf node consumers of a MAPQ client to a messaging.Consumer
+	// so that requests are processed by the default async workflow consumer.
+	// Items dispatched by MAPQ are pushed to the messages channel, and Process returns once they are acked or nacked.
+	messageConsumer struct {
+		client      types.Client
+		maxAttempts int
+		logger      log.Logger
+		messages    chan messaging.Message
+
+		sync.Mutex
+		// attempts counts the nacks of items by their offsets
+		attempts map[int64]int
+	}
+
+	// message is a request dispatched by MAPQ
+	message struct {
+		item   types.Item
+		result chan error
+	}
+
+	// consumerFactory returns the same consumer for all leaf nodes
+	consumerFactory struct {
+		consumer types.Consumer
+	}
+
+	leafConsumer struct {
+		*messageConsumer
+	}
+)
+
+var _ messaging.Consumer = (*messageConsumer)(nil)
+
+func newMessageConsumer(maxAttempts int, logger log.Logger) *messageConsumer {
+	return &messageConsumer{
+		maxAttempts: maxAttempts,
+		logger:      logger,
+		messages:    make(chan messaging.Message),
+		attempts:    make(map[int64]int),
+	}
+}
+
+// Start starts dispatching the requests of the MAPQ client
+func (c *messageConsumer) Start() error {
+	return c.client.Start(context.Background())
+}
+
+// Stop stops the MAPQ client and commits the offsets of processed requests
+func (c *messageConsumer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultStopTimeout)
+	defer cancel()
+	if err := c.client.Stop(ctx); err != nil {
+		c.logger.Error("Failed to stop MAPQ client", tag.Error(err))
+	}
+}
+
+func (c *messageConsumer) Messages() <-chan messaging.Message {
+	return c.messages
+}
+
+// process delivers the item to the messages channel and waits until it's acked or nacked.
+// Items nacked maxAttempts times are dropped since there's no DLQ.
+func (c *messageConsumer) process(ctx context.Context, item types.Item) error {
+	msg := &message{
+		item:   item,
+		result: make(chan error, 1),
+	}
+
+	select {
+	case c.messages <- msg:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var err error
+	select {
+	case err = <-msg.result:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if err == nil {
+		delete(c.attempts, item.Offset())
+		return nil
+	}
+
+	c.attempts[item.Offset()]++
+	if c.attempts[item.Offset()] < c.maxAttempts {
+		return err
+	}
+
+	delete(c.attempts, item.Offset())
+	c.logger.Error("Dropping request after max attempts",
+		tag.Dynamic("offset", item.Offset()),
+		tag.Dynamic("attempts", c.maxAttempts),
+	)
+	return nil
+}
+
+func (m *message) Value() []byte {
+	payload, _ := m.item.GetAttribute(attributePayload).([]byte)
+	return payload
+}
+
+// Partition is not used by the default consumer other than logging and MAPQ partitions are not numbered
+func (m *message) Partition() int32 {
+	return 0
+}
+
+func (m *message) Offset() int64 {
+	return m.item.Offset()
+}
+
+func (m *message) Ack() error {
+	m.result <- nil
+	return nil
+}
+
+func (m *message) Nack() error {
+	m.result <- errMessageNacked
+	return nil
+}
+
+func (f *consumerFactory) New(types.ItemPartitions) (types.Consumer, error) {
+	return f.consumer, nil
+}
+
+func (f *consumerFactory) Stop(context.Context) error {
+	return nil
+}
+
+func (c leafConsumer) Start(context.Context) error {
+	return nil
+}
+
+func (c leafConsumer) Stop(context.Context) error {
+	return nil
+}
+
+func (c leafConsumer) Process(ctx context.Context, item types.Item) error {
+	return c.process(ctx, item)
+}
+
+// producerOnlyConsumerFactory is used by the MAPQ clients of producers which are never started
+type producerOnlyConsumerFactory struct{}
+
+func (producerOnlyConsumerFactory) New(partitions types.ItemPartitions) (types.Consumer, error) {
+	return nil, fmt.Errorf("consumers are not supported by producers: %v", partitions)
+}
+
+func (producerOnlyConsumerFactory) Stop(context.Context) error {
+	return nil
+}
@@ -0,0 +1,97 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/messaging"
+)
+
+type testItem struct {
+	requestItem
+	offset int64
+}
+
+func (i *testItem) Offset() int64 {
+	return i.offset
+}
+
+func TestMessageConsumerProcess(t *testing.T) {
+	c := newMessageConsumer(2, testlogger.New(t))
+	factory := &consumerFactory{consumer: leafConsumer{c}}
+	leaf, err := factory.New(nil)
+	assert.NoError(t, err)
+
+	// acks and nacks are sent by the default consumer which reads the messages channel
+	results := make(chan func(messaging.Message) error, 10)
+	go func() {
+		for msg := range c.Messages() {
+			assert.Equal(t, []byte("payload"), msg.Value())
+			assert.NoError(t, (<-results)(msg))
+		}
+	}()
+	ack := func(msg messaging.Message) error { return msg.Ack() }
+	nack := func(msg messaging.Message) error { return msg.Nack() }
+
+	item := &testItem{requestItem: requestItem{payload: []byte("payload")}, offset: 5}
+	ctx := context.Background()
+
+	results <- ack
+	assert.NoError(t, leaf.Process(ctx, item))
+
+	// nacked items are retried by MAPQ until max attempts
+	results <- nack
+	assert.ErrorIs(t, leaf.Process(ctx, item), errMessageNacked)
+	results <- nack
+	assert.NoError(t, leaf.Process(ctx, item))
+	assert.Empty(t, c.attempts)
+
+	// attempts are reset when the item is acked
+	results <- nack
+	assert.Error(t, leaf.Process(ctx, item))
+	results <- ack
+	assert.NoError(t, leaf.Process(ctx, item))
+	assert.Empty(t, c.attempts)
+	close(c.messages)
+}
+
+func TestMessageConsumerProcessCanceled(t *testing.T) {
+	c := newMessageConsumer(2, testlogger.New(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := c.process(ctx, &testItem{offset: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProducerOnlyConsumerFactory(t *testing.T) {
+	_, err := producerOnlyConsumerFactory{}.New(types.NewItemPartitions(nil, nil))
+	assert.Error(t, err)
+	assert.NoError(t, producerOnlyConsumerFactory{}.Stop(context.Background()))
+}
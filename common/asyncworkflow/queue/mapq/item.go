@@ -0,0 +1,118 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"fmt"
+
+	"github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/.gen/go/sqlblobs"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/asyncworkflow/queue/consumer"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/mapq/types"
+)
+
+const (
+	partitionKeyQueue  = "queue"
+	partitionKeyDomain = "domain"
+
+	// attributePayload is not a partition key. It's used by consumers to get the encoded request of fetched items.
+	attributePayload = "payload"
+)
+
+type (
+	// requestItem is an async workflow request stored in MAPQ.
+	// Payload is the thriftrw encoded sqlblobs.AsyncRequestMessage.
+	requestItem struct {
+		queue   string
+		domain  string
+		payload []byte
+	}
+
+	// itemCodec stores the payload of items as is. Fetched items are only used to get their payload
+	// so the domain is not persisted.
+	itemCodec struct {
+		queue string
+	}
+)
+
+var _ types.Item = (*requestItem)(nil)
+
+func (i *requestItem) GetAttribute(key string) any {
+	switch key {
+	case partitionKeyQueue:
+		return i.queue
+	case partitionKeyDomain:
+		return i.domain
+	case attributePayload:
+		return i.payload
+	default:
+		return nil
+	}
+}
+
+// Offset is assigned by the persister
+func (i *requestItem) Offset() int64 {
+	return 0
+}
+
+func (i *requestItem) String() string {
+	return fmt.Sprintf("requestItem{queue:%s, domain:%s, size:%d}", i.queue, i.domain, len(i.payload))
+}
+
+func (c itemCodec) Encode(item types.Item) ([]byte, error) {
+	payload, ok := item.GetAttribute(attributePayload).([]byte)
+	if !ok {
+		return nil, fmt.Errorf("item %v has no payload", item)
+	}
+	return payload, nil
+}
+
+func (c itemCodec) Decode(data []byte) (types.Item, error) {
+	return &requestItem{queue: c.queue, payload: data}, nil
+}
+
+// decodeRequest returns the domain and workflow ID of an async workflow request
+func decodeRequest(decoder codec.BinaryEncoder, request *sqlblobs.AsyncRequestMessage) (string, string, error) {
+	if request.GetEncoding() != string(common.EncodingTypeThriftRW) {
+		return "", "", &consumer.UnsupportedEncoding{EncodingType: request.GetEncoding()}
+	}
+
+	switch request.GetType() {
+	case sqlblobs.AsyncRequestTypeStartWorkflowExecutionAsyncRequest:
+		var startRequest shared.StartWorkflowExecutionAsyncRequest
+		if err := decoder.Decode(request.GetPayload(), &startRequest); err != nil {
+			return "", "", err
+		}
+		return startRequest.GetRequest().GetDomain(), startRequest.GetRequest().GetWorkflowId(), nil
+	case sqlblobs.AsyncRequestTypeSignalWithStartWorkflowExecutionAsyncRequest:
+		var signalWithStartRequest shared.SignalWithStartWorkflowExecutionAsyncRequest
+		if err := decoder.Decode(request.GetPayload(), &signalWithStartRequest); err != nil {
+			return "", "", err
+		}
+		return signalWithStartRequest.GetRequest().GetDomain(), signalWithStartRequest.GetRequest().GetWorkflowId(), nil
+	default:
+		return "", "", &consumer.UnsupportedRequestType{Type: request.GetType()}
+	}
+}
@@ -0,0 +1,84 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uber/cadence/.gen/go/sqlblobs"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/messaging"
+)
+
+type (
+	producerImpl struct {
+		queue   string
+		client  types.Client
+		encoder codec.BinaryEncoder
+		logger  log.Logger
+	}
+)
+
+var _ messaging.Producer = (*producerImpl)(nil)
+
+// newProducer returns a producer which enqueues requests through the given MAPQ client.
+// The client is not started since producers only need it to route requests to their partitions.
+func newProducer(queue string, client types.Client, logger log.Logger) messaging.Producer {
+	return &producerImpl{
+		queue:   queue,
+		client:  client,
+		encoder: codec.NewThriftRWEncoder(),
+		logger:  logger,
+	}
+}
+
+func (p *producerImpl) Publish(ctx context.Context, message interface{}) error {
+	request, ok := message.(*sqlblobs.AsyncRequestMessage)
+	if !ok {
+		return errors.New("unknown producer message type")
+	}
+
+	domain, _, err := decodeRequest(p.encoder, request)
+	if err != nil {
+		return fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	payload, err := p.encoder.Encode(request)
+	if err != nil {
+		p.logger.Error("Failed to serialize thrift object", tag.Error(err))
+		return err
+	}
+
+	_, err = p.client.Enqueue(ctx, []types.Item{&requestItem{queue: p.queue, domain: domain, payload: payload}})
+	if err != nil {
+		p.logger.Warn("Failed to enqueue request", tag.WorkflowDomainName(domain), tag.Error(err))
+		return err
+	}
+
+	return nil
+}
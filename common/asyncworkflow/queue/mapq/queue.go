@@ -0,0 +1,274 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/uber/cadence/.gen/go/sqlblobs"
+	"github.com/uber/cadence/common/asyncworkflow/queue/consumer"
+	"github.com/uber/cadence/common/asyncworkflow/queue/provider"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/mapq"
+	"github.com/uber/cadence/common/mapq/persister"
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/messaging"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+)
+
+const (
+	describePageSize        = 1000
+	maxDescribedRequests    = 100
+	maxDescribedPageFetches = 100
+
+	removeStalePartitionsTimeout = 10 * time.Second
+)
+
+type (
+	// queueImpl is an async workflow queue stored in the queue table of Cadence persistence via MAPQ
+	queueImpl struct {
+		config *queueConfig
+	}
+)
+
+var _ provider.Describer = (*queueImpl)(nil)
+
+func newQueue(decoder provider.Decoder) (provider.Queue, error) {
+	var out queueConfig
+	if err := decoder.Decode(&out); err != nil {
+		return nil, fmt.Errorf("bad config: %w", err)
+	}
+	if err := out.validate(); err != nil {
+		return nil, fmt.Errorf("bad config: %w", err)
+	}
+	out.setDefaults()
+	return &queueImpl{
+		config: &out,
+	}, nil
+}
+
+func (q *queueImpl) ID() string {
+	return q.config.ID()
+}
+
+func (q *queueImpl) CreateConsumer(p *provider.Params) (provider.Consumer, error) {
+	logger := p.Logger.WithTags(tag.AsyncWFQueueID(q.ID()))
+	queuePersister, err := q.newPersister(p)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), removeStalePartitionsTimeout)
+	defer cancel()
+	if err := q.removeStalePartitions(ctx, queuePersister); err != nil {
+		// they are removed by the next consumer, in the meantime they only delay the deletion of requests
+		logger.Warn("Failed to remove the offsets of stale partitions", tag.Error(err))
+	}
+
+	var opts []mapq.Options
+	if p.OwnsPartition != nil {
+		// every consumer of the queue loads all partitions, so each of them must be dispatched by exactly one of them.
+		// partition paths contain the queue name, so they are unique across queues.
+		opts = append(opts, mapq.WithLeafOwnership(p.OwnsPartition))
+	}
+
+	messageConsumer := newMessageConsumer(q.config.MaxAttempts, logger)
+	client, err := q.newClient(p, queuePersister, &consumerFactory{consumer: leafConsumer{messageConsumer}}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	messageConsumer.client = client
+	return consumer.New(q.ID(), messageConsumer, p.Logger, p.MetricsClient, p.FrontendClient), nil
+}
+
+func (q *queueImpl) CreateProducer(p *provider.Params) (messaging.Producer, error) {
+	queuePersister, err := q.newPersister(p)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := q.newClient(p, queuePersister, producerOnlyConsumerFactory{})
+	if err != nil {
+		return nil, err
+	}
+	logger := p.Logger.WithTags(tag.AsyncWFQueueID(q.ID()))
+	return messaging.NewMetricProducer(newProducer(q.config.Name, client, logger), p.MetricsClient), nil
+}
+
+// Describe reads the requests of all partitions after their committed offsets.
+// The queue is scanned once from the lowest committed offset, up to maxDescribedPageFetches pages.
+func (q *queueImpl) Describe(ctx context.Context, p *provider.Params) (*provider.QueueDescription, error) {
+	queueManager, err := q.queueManager(p)
+	if err != nil {
+		return nil, err
+	}
+
+	requestCodec := itemCodec{queue: q.config.Name}
+	offsets, err := persister.New(queueManager, requestCodec).GetOffsets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get offsets: %w", err)
+	}
+
+	description := &provider.QueueDescription{}
+	allPartitions := q.config.partitions()
+	byPath := make(map[string]int, len(allPartitions))
+	readLevel := int64(math.MaxInt64)
+	for _, partitions := range allPartitions {
+		path := types.PartitionsPath(partitions)
+		partition := provider.PartitionDescription{
+			Partition:       fmt.Sprintf("%v", partitions.GetPartitionValue(partitionKeyDomain)),
+			CommittedOffset: offsets.GetOffset(path),
+		}
+		if partition.CommittedOffset < readLevel {
+			readLevel = partition.CommittedOffset
+		}
+		byPath[path] = len(description.Partitions)
+		description.Partitions = append(description.Partitions, partition)
+	}
+	if len(description.Partitions) == 0 {
+		return description, nil
+	}
+
+	decoder := codec.NewThriftRWEncoder()
+	pageInfo := types.PageInfo{ReadLevel: readLevel, PageSize: describePageSize}
+	for fetches := 0; ; fetches++ {
+		if fetches == maxDescribedPageFetches {
+			description.Truncated = true
+			break
+		}
+
+		items, nextPageInfo, err := persister.Scan(ctx, queueManager, requestCodec, pageInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan requests: %w", err)
+		}
+
+		for _, item := range items {
+			index, ok := byPath[item.Path]
+			if !ok {
+				continue
+			}
+			partition := &description.Partitions[index]
+			if item.Offset() <= partition.CommittedOffset {
+				continue
+			}
+			partition.PendingRequestCount++
+			if len(partition.PendingRequests) < maxDescribedRequests {
+				partition.PendingRequests = append(partition.PendingRequests, describeRequest(decoder, item))
+			}
+		}
+
+		if nextPageInfo.ReadLevel == pageInfo.ReadLevel {
+			break
+		}
+		pageInfo = nextPageInfo
+	}
+
+	return description, nil
+}
+
+// removeStalePartitions removes the offsets of the partitions of the queue which aren't configured anymore,
+// e.g. of a removed domain, otherwise their requests would never be deleted
+func (q *queueImpl) removeStalePartitions(ctx context.Context, queuePersister types.Persister) error {
+	offsets, err := queuePersister.GetOffsets(ctx)
+	if err != nil {
+		return err
+	}
+
+	configured := make(map[string]struct{})
+	for _, partitions := range q.config.partitions() {
+		configured[types.PartitionsPath(partitions)] = struct{}{}
+	}
+
+	// queues sharing the queue type have other names, so the paths of their partitions have another prefix
+	queuePrefix := fmt.Sprintf("*/%s/", q.config.Name)
+	var stale []string
+	for path := range offsets.Partitions {
+		if _, ok := configured[path]; !ok && strings.HasPrefix(path, queuePrefix) {
+			stale = append(stale, path)
+		}
+	}
+	return queuePersister.RemoveOffsets(ctx, stale)
+}
+
+func (q *queueImpl) newPersister(p *provider.Params) (types.Persister, error) {
+	queueManager, err := q.queueManager(p)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := p.Logger.WithTags(tag.AsyncWFQueueID(q.ID()))
+	return persister.New(queueManager, itemCodec{queue: q.config.Name}, persister.WithLogger(logger)), nil
+}
+
+func (q *queueImpl) newClient(
+	p *provider.Params,
+	queuePersister types.Persister,
+	consumerFactory types.ConsumerFactory,
+	opts ...mapq.Options,
+) (types.Client, error) {
+	opts = append([]mapq.Options{
+		mapq.WithPersister(queuePersister),
+		mapq.WithConsumerFactory(consumerFactory),
+		mapq.WithPartitions([]string{partitionKeyQueue, partitionKeyDomain}),
+		mapq.WithPolicies(q.config.policies()),
+	}, opts...)
+	return mapq.New(
+		p.Logger.WithTags(tag.AsyncWFQueueID(q.ID())),
+		p.MetricsClient.Scope(metrics.AsyncWorkflowConsumerScope),
+		opts...,
+	)
+}
+
+// queueManager returns the queue of Cadence persistence storing the requests of the queue
+func (q *queueImpl) queueManager(p *provider.Params) (persistence.QueueManager, error) {
+	if p.MapQQueueManager == nil {
+		return nil, errors.New("mapq queue requires the persistence queue manager")
+	}
+
+	queueManager, err := p.MapQQueueManager(q.config.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the persistence queue manager: %w", err)
+	}
+	return queueManager, nil
+}
+
+// describeRequest decodes the request of an item on a best effort basis
+func describeRequest(decoder codec.BinaryEncoder, item types.Item) provider.PendingRequest {
+	pendingRequest := provider.PendingRequest{Offset: item.Offset()}
+	payload, _ := item.GetAttribute(attributePayload).([]byte)
+
+	var request sqlblobs.AsyncRequestMessage
+	if err := decoder.Decode(payload, &request); err != nil {
+		return pendingRequest
+	}
+	pendingRequest.Type = request.GetType().String()
+	pendingRequest.Domain, pendingRequest.WorkflowID, _ = decodeRequest(decoder, &request)
+	return pendingRequest
+}
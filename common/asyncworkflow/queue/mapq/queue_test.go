@@ -0,0 +1,255 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/.gen/go/sqlblobs"
+	"github.com/uber/cadence/client/frontend"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/asyncworkflow/queue/provider"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/log/testlogger"
+	mapqtypes "github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/metrics"
+	pt "github.com/uber/cadence/common/persistence/persistence-tests"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin/sqlite"
+	"github.com/uber/cadence/common/types"
+)
+
+type MockDecoder struct {
+	DecodeFunc func(v any) error
+}
+
+func (m *MockDecoder) Decode(v any) error {
+	return m.DecodeFunc(v)
+}
+
+func TestNewQueue(t *testing.T) {
+	tests := []struct {
+		name      string
+		decoder   *MockDecoder
+		want      *queueImpl
+		errString string
+	}{
+		{
+			name: "successful decoding with defaults",
+			decoder: &MockDecoder{
+				DecodeFunc: func(v any) error {
+					out := v.(*queueConfig)
+					out.Name = "queue1"
+					out.Domains = []domainConfig{{Name: "d1", Concurrency: 5}}
+					return nil
+				},
+			},
+			want: &queueImpl{
+				config: &queueConfig{
+					Name:        "queue1",
+					Concurrency: defaultConcurrency,
+					MaxAttempts: defaultMaxAttempts,
+					Domains:     []domainConfig{{Name: "d1", Concurrency: 5}},
+				},
+			},
+		},
+		{
+			name: "decoding failure",
+			decoder: &MockDecoder{
+				DecodeFunc: func(v any) error {
+					return errors.New("decoding error")
+				},
+			},
+			errString: "bad config: decoding error",
+		},
+		{
+			name: "missing name",
+			decoder: &MockDecoder{
+				DecodeFunc: func(v any) error {
+					return nil
+				},
+			},
+			errString: "bad config: invalid queue name: empty value",
+		},
+		{
+			name: "invalid domain",
+			decoder: &MockDecoder{
+				DecodeFunc: func(v any) error {
+					out := v.(*queueConfig)
+					out.Name = "queue1"
+					out.Domains = []domainConfig{{Name: "*"}}
+					return nil
+				},
+			},
+			errString: `bad config: invalid domain name: "*" is not allowed`,
+		},
+		{
+			name: "duplicate domain",
+			decoder: &MockDecoder{
+				DecodeFunc: func(v any) error {
+					out := v.(*queueConfig)
+					out.Name = "queue1"
+					out.Domains = []domainConfig{{Name: "d1"}, {Name: "d1"}}
+					return nil
+				},
+			},
+			errString: "bad config: domain d1 is defined more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newQueue(tt.decoder)
+			if tt.errString != "" {
+				assert.EqualError(t, err, tt.errString)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, "mapq::queue1", got.ID())
+		})
+	}
+}
+
+func TestQueueWithoutQueueManager(t *testing.T) {
+	q := &queueImpl{config: &queueConfig{Name: "queue1"}}
+	params := &provider.Params{Logger: testlogger.New(t), MetricsClient: metrics.NewNoopMetricsClient()}
+
+	_, err := q.CreateConsumer(params)
+	assert.Error(t, err)
+	_, err = q.CreateProducer(params)
+	assert.Error(t, err)
+	_, err = q.Describe(context.Background(), params)
+	assert.Error(t, err)
+}
+
+func TestRemoveStalePartitions(t *testing.T) {
+	q := &queueImpl{config: &queueConfig{Name: "queue1", Domains: []domainConfig{{Name: "d1"}}}}
+	queuePersister := mapqtypes.NewMockPersister(gomock.NewController(t))
+	queuePersister.EXPECT().GetOffsets(gomock.Any()).Return(&mapqtypes.Offsets{Partitions: map[string]int64{
+		"*/queue1/*":  5,
+		"*/queue1/d1": 5,
+		"*/queue1/d2": 3,
+		// partitions of another queue sharing the queue type
+		"*/queue2/d2": 1,
+	}}, nil)
+	queuePersister.EXPECT().RemoveOffsets(gomock.Any(), []string{"*/queue1/d2"}).Return(nil)
+
+	assert.NoError(t, q.removeStalePartitions(context.Background(), queuePersister))
+}
+
+// TestQueueWithSQLite publishes requests through the queue table of SQLite, inspects them and starts their workflows
+func TestQueueWithSQLite(t *testing.T) {
+	option, err := sqlite.GetTestClusterOption()
+	require.NoError(t, err)
+	testBase := pt.NewTestBaseWithSQL(t, option)
+	testBase.Setup()
+	defer testBase.TearDownWorkflowStore()
+
+	blob := &types.DataBlob{
+		EncodingType: types.EncodingTypeJSON.Ptr(),
+		Data:         []byte(`{"name":"queue1","domains":[{"name":"d1","concurrency":2}]}`),
+	}
+	q, err := newQueue(newDecoder(blob))
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	frontendClient := frontend.NewMockClient(ctrl)
+	params := &provider.Params{
+		Logger:           testlogger.New(t),
+		MetricsClient:    metrics.NewNoopMetricsClient(),
+		FrontendClient:   frontendClient,
+		MapQQueueManager: testBase.ExecutionMgrFactory.NewMapQQueueManager,
+	}
+	ctx := context.Background()
+
+	producer, err := q.CreateProducer(params)
+	require.NoError(t, err)
+	requests := map[string]string{"wid1": "d1", "wid2": "d2", "wid3": "d1"}
+	for _, workflowID := range []string{"wid1", "wid2", "wid3"} {
+		require.NoError(t, producer.Publish(ctx, newStartRequestMessage(t, requests[workflowID], workflowID)))
+	}
+	assert.Error(t, producer.Publish(ctx, "invalid"))
+
+	// requests of d1 are in its dedicated partition and requests of other domains are in the shared partition
+	description, err := q.(provider.Describer).Describe(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, description.Partitions, 2)
+	assert.Equal(t, "*", description.Partitions[0].Partition)
+	assert.Equal(t, 1, description.Partitions[0].PendingRequestCount)
+	assert.Equal(t, "d2", description.Partitions[0].PendingRequests[0].Domain)
+	assert.Equal(t, "wid2", description.Partitions[0].PendingRequests[0].WorkflowID)
+	assert.Equal(t, "StartWorkflowExecutionAsyncRequest", description.Partitions[0].PendingRequests[0].Type)
+	assert.Equal(t, "d1", description.Partitions[1].Partition)
+	assert.Equal(t, 2, description.Partitions[1].PendingRequestCount)
+
+	var wg sync.WaitGroup
+	wg.Add(len(requests))
+	frontendClient.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			assert.Equal(t, requests[request.GetWorkflowID()], request.GetDomain())
+			wg.Done()
+			return &types.StartWorkflowExecutionResponse{RunID: "rid"}, nil
+		}).Times(len(requests))
+
+	consumer, err := q.CreateConsumer(params)
+	require.NoError(t, err)
+	require.NoError(t, consumer.Start())
+	require.True(t, common.AwaitWaitGroup(&wg, 10*time.Second), "requests are not processed")
+	consumer.Stop()
+
+	// offsets are committed on stop
+	description, err = q.(provider.Describer).Describe(ctx, params)
+	require.NoError(t, err)
+	for _, partition := range description.Partitions {
+		assert.Zero(t, partition.PendingRequestCount, "partition %s", partition.Partition)
+		assert.NotEqual(t, int64(-1), partition.CommittedOffset, "partition %s", partition.Partition)
+	}
+}
+
+func newStartRequestMessage(t *testing.T, domain, workflowID string) *sqlblobs.AsyncRequestMessage {
+	encoder := codec.NewThriftRWEncoder()
+	payload, err := encoder.Encode(&shared.StartWorkflowExecutionAsyncRequest{
+		Request: &shared.StartWorkflowExecutionRequest{
+			Domain:     common.StringPtr(domain),
+			WorkflowId: common.StringPtr(workflowID),
+		},
+	})
+	require.NoError(t, err)
+
+	requestType := sqlblobs.AsyncRequestTypeStartWorkflowExecutionAsyncRequest
+	return &sqlblobs.AsyncRequestMessage{
+		PartitionKey: common.StringPtr(workflowID),
+		Type:         &requestType,
+		Encoding:     common.StringPtr(string(common.EncodingTypeThriftRW)),
+		Payload:      payload,
+	}
+}
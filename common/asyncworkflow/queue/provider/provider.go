@@ -25,12 +25,14 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/uber/cadence/client/frontend"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/messaging"
 	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/syncmap"
 	"github.com/uber/cadence/common/types"
 )
@@ -40,6 +42,13 @@ type (
 		Logger         log.Logger
 		MetricsClient  metrics.Client
 		FrontendClient frontend.Client
+		// MapQQueueManager returns the queue of Cadence persistence storing the items of a queue by its name.
+		// It is used by the queues which don't depend on external messaging systems.
+		MapQQueueManager func(queueName string) (persistence.QueueManager, error)
+		// OwnsPartition tells whether this instance consumes the partition of a queue with the given key.
+		// It is used by the queues which split their partitions among consumers themselves.
+		// All partitions are consumed by every instance if it's nil.
+		OwnsPartition func(key string) (bool, error)
 	}
 
 	Decoder interface {
@@ -58,6 +67,36 @@ type (
 		CreateProducer(*Params) (messaging.Producer, error)
 	}
 
+	// Describer is implemented by the queues which can report the requests waiting to be processed
+	Describer interface {
+		Describe(context.Context, *Params) (*QueueDescription, error)
+	}
+
+	// QueueDescription contains the pending requests of each partition of a queue
+	QueueDescription struct {
+		Partitions []PartitionDescription
+		// Truncated is set if the queue is too long to be fully read, the pending request counts are lower bounds then
+		Truncated bool
+	}
+
+	PartitionDescription struct {
+		// Partition identifies the partition within the queue
+		Partition string
+		// CommittedOffset is the offset up to which requests are processed
+		CommittedOffset int64
+		// PendingRequestCount is the number of requests after the committed offset
+		PendingRequestCount int
+		// PendingRequests are the first pending requests of the partition
+		PendingRequests []PendingRequest
+	}
+
+	PendingRequest struct {
+		Offset     int64
+		Type       string
+		Domain     string
+		WorkflowID string
+	}
+
 	QueueConstructor func(Decoder) (Queue, error)
 
 	DecoderConstructor func(*types.DataBlob) Decoder
@@ -0,0 +1,84 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audit
+
+//go:generate mockgen -package $GOPACKAGE -source $GOFILE -destination interface_mock.go -self_package github.com/uber/cadence/common/audit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// OutcomeSuccess is the outcome of the calls which succeeded
+	OutcomeSuccess = "success"
+	// OutcomeAccessDenied is the outcome of the calls which were rejected by the authorizer
+	OutcomeAccessDenied = "access_denied"
+	// OutcomeError is the outcome of the calls which failed for any other reason
+	OutcomeError = "error"
+)
+
+type (
+	// Entry records who called an API of the frontend, on which workflow and how the call ended
+	Entry struct {
+		Timestamp time.Time `json:"timestamp"`
+		// Actor is the caller authenticated by its mTLS client certificate or its JWT,
+		// it's empty if the caller could not be authenticated
+		Actor  string   `json:"actor,omitempty"`
+		Groups []string `json:"groups,omitempty"`
+		// PrincipalSource is how the actor was authenticated, e.g. "mtls" or "jwt"
+		PrincipalSource string `json:"principalSource,omitempty"`
+		// Identity is the identity reported by the client in the request, it's not authenticated
+		Identity   string `json:"identity,omitempty"`
+		API        string `json:"api"`
+		Domain     string `json:"domain,omitempty"`
+		WorkflowID string `json:"workflowID,omitempty"`
+		RunID      string `json:"runID,omitempty"`
+		Outcome    string `json:"outcome"`
+		Error      string `json:"error,omitempty"`
+	}
+
+	// Sink stores the audit entries
+	Sink interface {
+		Write(ctx context.Context, entry *Entry) error
+		Close() error
+	}
+)
+
+// DefaultAPIs are the audited APIs when none is configured, they are the APIs which change a workflow or a domain
+var DefaultAPIs = []string{
+	"DeprecateDomain",
+	"RefreshWorkflowTasks",
+	"RegisterDomain",
+	"RequestCancelWorkflowExecution",
+	"ResetStickyTaskList",
+	"ResetWorkflowExecution",
+	"RestartWorkflowExecution",
+	"SignalWithStartWorkflowExecution",
+	"SignalWithStartWorkflowExecutionAsync",
+	"SignalWorkflowExecution",
+	"StartWorkflowExecution",
+	"StartWorkflowExecutionAsync",
+	"TerminateWorkflowExecution",
+	"UpdateDomain",
+}
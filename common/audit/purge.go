@@ -0,0 +1,87 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/persistence"
+)
+
+const purgePageSize = 1000
+
+// Retention returns how long the entries of the persistence sink are kept
+func Retention(cfg config.Audit) time.Duration {
+	if cfg.Retention <= 0 {
+		return DefaultRetention
+	}
+	return cfg.Retention
+}
+
+// PurgeExpiredEntries deletes the entries of the persistence sink which were enqueued before the expiration.
+// Entries are enqueued in the order of their message IDs, so they're read from the oldest one until one which
+// isn't expired. A clock skew between the frontend hosts only delays the deletion of some entries to the next purge.
+func PurgeExpiredEntries(ctx context.Context, queue persistence.QueueManager, expiration time.Time) error {
+	lastMessageID := int64(-1)
+	lastExpiredID := int64(-1)
+	for {
+		messages, err := queue.ReadMessages(ctx, lastMessageID, purgePageSize)
+		if err != nil {
+			return err
+		}
+		expired := true
+		for _, message := range messages {
+			enqueuedAt, err := decodeEnqueueTime(message.Payload)
+			if err == nil && !enqueuedAt.Before(expiration) {
+				expired = false
+				break
+			}
+			// entries which can't be decoded are deleted with the expired ones
+			lastExpiredID = message.ID
+		}
+		if !expired || len(messages) < purgePageSize {
+			break
+		}
+		lastMessageID = messages[len(messages)-1].ID
+	}
+	if lastExpiredID < 0 {
+		return nil
+	}
+	return queue.DeleteMessagesBefore(ctx, lastExpiredID+1)
+}
+
+// decodeEnqueueTime returns when an entry of the persistence sink was enqueued,
+// it's the timestamp of the entry for the entries enqueued before the enqueue time was recorded
+func decodeEnqueueTime(payload []byte) (time.Time, error) {
+	entry := persistedEntry{Entry: &Entry{}}
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return time.Time{}, err
+	}
+	if entry.EnqueuedAt.IsZero() {
+		return entry.Timestamp, nil
+	}
+	return entry.EnqueuedAt, nil
+}
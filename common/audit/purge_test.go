@@ -0,0 +1,111 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/persistence"
+)
+
+func TestPurgeExpiredEntries(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	newMessage := func(id int64, age time.Duration) *persistence.QueueMessage {
+		// the entries of the calls which completed earlier can be enqueued later
+		entry := newTestEntry("wid")
+		entry.Timestamp = now.Add(-3 * time.Hour)
+		payload, err := json.Marshal(persistedEntry{Entry: entry, EnqueuedAt: now.Add(-age)})
+		require.NoError(t, err)
+		return &persistence.QueueMessage{ID: id, Payload: payload}
+	}
+	newEntryMessage := func(id int64, age time.Duration) *persistence.QueueMessage {
+		entry := newTestEntry("wid")
+		entry.Timestamp = now.Add(-age)
+		payload, err := json.Marshal(entry)
+		require.NoError(t, err)
+		return &persistence.QueueMessage{ID: id, Payload: payload}
+	}
+	expiredPage := make(persistence.QueueMessageList, purgePageSize)
+	for i := range expiredPage {
+		expiredPage[i] = newMessage(int64(i), 2*time.Hour)
+	}
+
+	tests := map[string]struct {
+		pages           []persistence.QueueMessageList
+		deletedBeforeID int64
+	}{
+		"empty queue": {
+			pages: []persistence.QueueMessageList{nil},
+		},
+		"no expired entry": {
+			pages: []persistence.QueueMessageList{{newMessage(1, 0), newMessage(2, 0)}},
+		},
+		"expired entries": {
+			pages:           []persistence.QueueMessageList{{newMessage(1, 2*time.Hour), newMessage(2, 2*time.Hour), newMessage(3, 0)}},
+			deletedBeforeID: 3,
+		},
+		"all entries expired": {
+			pages:           []persistence.QueueMessageList{{newMessage(1, 2*time.Hour), {ID: 2, Payload: []byte("invalid")}}},
+			deletedBeforeID: 3,
+		},
+		"expired entries in several pages": {
+			pages:           []persistence.QueueMessageList{expiredPage, {newMessage(purgePageSize, 2*time.Hour), newMessage(purgePageSize+1, 0)}},
+			deletedBeforeID: purgePageSize + 1,
+		},
+		"entries without enqueue time": {
+			pages:           []persistence.QueueMessageList{{newEntryMessage(1, 2*time.Hour), newEntryMessage(2, 0), newMessage(3, 2*time.Hour)}},
+			deletedBeforeID: 2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			queue := persistence.NewMockQueueManager(ctrl)
+
+			lastMessageID := int64(-1)
+			for _, page := range test.pages {
+				queue.EXPECT().ReadMessages(gomock.Any(), lastMessageID, purgePageSize).Return(page, nil)
+				if len(page) > 0 {
+					lastMessageID = page[len(page)-1].ID
+				}
+			}
+			if test.deletedBeforeID > 0 {
+				queue.EXPECT().DeleteMessagesBefore(gomock.Any(), test.deletedBeforeID).Return(nil)
+			}
+			assert.NoError(t, PurgeExpiredEntries(context.Background(), queue, now.Add(-time.Hour)))
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	assert.Equal(t, DefaultRetention, Retention(config.Audit{}))
+	assert.Equal(t, time.Hour, Retention(config.Audit{Retention: time.Hour}))
+}
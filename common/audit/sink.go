@@ -0,0 +1,303 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/messaging"
+	"github.com/uber/cadence/common/persistence"
+)
+
+const (
+	// DefaultRetention is how long the entries of the persistence sink are kept if the config doesn't set it
+	DefaultRetention = 7 * 24 * time.Hour
+	// DefaultBufferSize is the number of entries buffered by the async sink if the config doesn't set it
+	DefaultBufferSize = 1000
+	// DefaultFullBufferTimeout is how long the async sink waits for space in its full buffer if the config doesn't set it
+	DefaultFullBufferTimeout = time.Second
+
+	maxEnqueueAttempts = 5
+	// writeTimeout bounds the write of an entry by the async sink
+	writeTimeout = 5 * time.Second
+)
+
+var errBufferFull = errors.New("audit buffer is full")
+
+type (
+	fileSink struct {
+		sync.Mutex
+		file *os.File
+	}
+
+	kafkaSink struct {
+		producer messaging.Producer
+	}
+
+	persistenceSink struct {
+		queue      persistence.QueueManager
+		timeSource clock.TimeSource
+	}
+
+	// persistedEntry is an entry enqueued by the persistence sink
+	persistedEntry struct {
+		*Entry
+		// EnqueuedAt is when the entry was enqueued, the entries expire in the order of their message IDs
+		// since it's set right before each enqueue, unlike the timestamp which is set when the call completes
+		EnqueuedAt time.Time `json:"enqueuedAt"`
+	}
+
+	asyncSink struct {
+		sink    Sink
+		logger  log.Logger
+		entries chan *Entry
+		// dropOnFullBuffer drops the entries when the buffer is full instead of waiting for fullBufferTimeout
+		dropOnFullBuffer  bool
+		fullBufferTimeout time.Duration
+		// dropped is the number of entries dropped since the last time they were logged
+		dropped int64
+		// closed guards entries, which is closed by Close
+		sync.RWMutex
+		closed bool
+		doneCh chan struct{}
+	}
+)
+
+// NewSink creates the sink of the audit config, it returns nil if auditing is disabled.
+// The messaging client is only used by the kafka sink and the queue by the persistence sink.
+func NewSink(
+	cfg config.Audit,
+	messagingClient messaging.Client,
+	queue persistence.QueueManager,
+) (Sink, error) {
+	switch cfg.Sink {
+	case "":
+		return nil, nil
+	case config.AuditSinkFile:
+		return NewFileSink(cfg.FilePath)
+	case config.AuditSinkKafka:
+		if messagingClient == nil {
+			return nil, fmt.Errorf("kafka audit sink requires kafka to be configured")
+		}
+		producer, err := messagingClient.NewProducer(cfg.KafkaApplication)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaSink(producer), nil
+	case config.AuditSinkPersistence:
+		return NewPersistenceSink(queue, clock.NewRealTimeSource()), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", cfg.Sink)
+	}
+}
+
+// NewFileSink creates a Sink which appends the entries as JSON lines to a file
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file %v: %v", path, err)
+	}
+	return &fileSink{file: file}, nil
+}
+
+// NewKafkaSink creates a Sink which publishes the entries to kafka, keyed by their workflow ID
+func NewKafkaSink(producer messaging.Producer) Sink {
+	return &kafkaSink{producer: producer}
+}
+
+// NewPersistenceSink creates a Sink which enqueues the entries in a queue of the persistence.
+// The expired entries are deleted by PurgeExpiredEntries.
+func NewPersistenceSink(queue persistence.QueueManager, timeSource clock.TimeSource) Sink {
+	return &persistenceSink{
+		queue:      queue,
+		timeSource: timeSource,
+	}
+}
+
+// NewAsyncSink creates a Sink which buffers the entries and writes them to the sink in the background,
+// so that calls don't wait for the sink. The full buffer policy of the config applies when the buffer is full.
+func NewAsyncSink(sink Sink, cfg config.Audit, logger log.Logger) Sink {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	fullBufferTimeout := cfg.FullBufferTimeout
+	if fullBufferTimeout <= 0 {
+		fullBufferTimeout = DefaultFullBufferTimeout
+	}
+	s := &asyncSink{
+		sink:              sink,
+		logger:            logger,
+		entries:           make(chan *Entry, bufferSize),
+		dropOnFullBuffer:  cfg.FullBufferPolicy == config.AuditFullBufferPolicyDrop,
+		fullBufferTimeout: fullBufferTimeout,
+		doneCh:            make(chan struct{}),
+	}
+	go s.writeLoop()
+	return s
+}
+
+// DecodeEntry decodes an entry written by a sink
+func DecodeEntry(payload []byte) (*Entry, error) {
+	var entry Entry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *fileSink) Write(_ context.Context, entry *Entry) error {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.file.Write(append(payload, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+func (s *kafkaSink) Write(ctx context.Context, entry *Entry) error {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.producer.Publish(ctx, &messaging.KeyedMessage{Key: entry.WorkflowID, Value: payload})
+}
+
+func (s *kafkaSink) Close() error {
+	if closeableProducer, ok := s.producer.(messaging.CloseableProducer); ok {
+		return closeableProducer.Close()
+	}
+	return nil
+}
+
+// Write retries on conflicts since message IDs are assigned by reading the last message ID of the queue
+func (s *persistenceSink) Write(ctx context.Context, entry *Entry) error {
+	var err error
+	for attempt := 0; attempt < maxEnqueueAttempts; attempt++ {
+		var payload []byte
+		payload, err = json.Marshal(persistedEntry{Entry: entry, EnqueuedAt: s.timeSource.Now()})
+		if err != nil {
+			return err
+		}
+		err = s.queue.EnqueueMessage(ctx, payload)
+		var conditionFailedErr *persistence.ConditionFailedError
+		if !errors.As(err, &conditionFailedErr) {
+			return err
+		}
+	}
+	return err
+}
+
+// Close doesn't close the queue as it's owned by the persistence bean
+func (s *persistenceSink) Close() error {
+	return nil
+}
+
+// Write doesn't wait for the entry to be written. When the buffer is full, it waits for space in the buffer
+// for at most the full buffer timeout, unless the entry is dropped, in which case it returns nil.
+func (s *asyncSink) Write(ctx context.Context, entry *Entry) error {
+	s.RLock()
+	defer s.RUnlock()
+	if s.closed {
+		return errors.New("audit sink is closed")
+	}
+	select {
+	case s.entries <- entry:
+		return nil
+	default:
+	}
+	if s.dropOnFullBuffer {
+		atomic.AddInt64(&s.dropped, 1)
+		return nil
+	}
+
+	timer := time.NewTimer(s.fullBufferTimeout)
+	defer timer.Stop()
+	select {
+	case s.entries <- entry:
+		return nil
+	case <-timer.C:
+		return errBufferFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes the buffered entries and closes the sink
+func (s *asyncSink) Close() error {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.entries)
+	s.Unlock()
+	<-s.doneCh
+	return s.sink.Close()
+}
+
+func (s *asyncSink) writeLoop() {
+	defer close(s.doneCh)
+	for entry := range s.entries {
+		s.logDropped()
+		s.write(entry)
+	}
+	s.logDropped()
+}
+
+func (s *asyncSink) write(entry *Entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := s.sink.Write(ctx, entry); err != nil {
+		s.logger.Error("Failed to write audit entry",
+			tag.WorkflowHandlerName(entry.API),
+			tag.WorkflowDomainName(entry.Domain),
+			tag.WorkflowID(entry.WorkflowID),
+			tag.Error(err))
+	}
+}
+
+// logDropped logs the number of entries dropped since the last time they were logged, if any
+func (s *asyncSink) logDropped() {
+	if dropped := atomic.SwapInt64(&s.dropped, 0); dropped > 0 {
+		s.logger.Warn("Audit entries are dropped because the audit buffer is full", tag.Counter(int(dropped)))
+	}
+}
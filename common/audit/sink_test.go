@@ -0,0 +1,229 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/messaging"
+	"github.com/uber/cadence/common/persistence"
+)
+
+func newTestEntry(workflowID string) *Entry {
+	return &Entry{
+		Timestamp:       time.Unix(1700000000, 0).UTC(),
+		Actor:           "worker",
+		Groups:          []string{"ops"},
+		PrincipalSource: "mtls",
+		API:             "TerminateWorkflowExecution",
+		Domain:          "test-domain",
+		WorkflowID:      workflowID,
+		RunID:           "test-run-id",
+		Outcome:         OutcomeSuccess,
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), newTestEntry("wid1")))
+	require.NoError(t, sink.Write(context.Background(), newTestEntry("wid2")))
+	require.NoError(t, sink.Close())
+
+	// entries are appended to the existing file
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), newTestEntry("wid3")))
+	require.NoError(t, sink.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	for i, workflowID := range []string{"wid1", "wid2", "wid3"} {
+		entry, err := DecodeEntry([]byte(lines[i]))
+		require.NoError(t, err)
+		assert.Equal(t, newTestEntry(workflowID), entry)
+	}
+}
+
+func TestPersistenceSink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	queue := persistence.NewMockQueueManager(ctrl)
+	now := time.Unix(1700000060, 0).UTC()
+	sink := NewPersistenceSink(queue, clock.NewMockedTimeSourceAt(now))
+
+	entry := newTestEntry("wid")
+	payload, err := json.Marshal(entry)
+	require.NoError(t, err)
+	// the enqueue time is recorded with the entry, which can still be decoded as an entry
+	payload = append(payload[:len(payload)-1], []byte(`,"enqueuedAt":"2023-11-14T22:14:20Z"}`)...)
+	decoded, err := DecodeEntry(payload)
+	require.NoError(t, err)
+	assert.Equal(t, entry, decoded)
+	enqueuedAt, err := decodeEnqueueTime(payload)
+	require.NoError(t, err)
+	assert.Equal(t, now, enqueuedAt)
+	queue.EXPECT().EnqueueMessage(gomock.Any(), payload).Return(nil)
+	assert.NoError(t, sink.Write(context.Background(), entry))
+
+	// enqueues which conflict with other enqueues are retried
+	conflict := &persistence.ConditionFailedError{Msg: "message ID already exists"}
+	queue.EXPECT().EnqueueMessage(gomock.Any(), payload).Return(conflict).Times(2)
+	queue.EXPECT().EnqueueMessage(gomock.Any(), payload).Return(nil)
+	assert.NoError(t, sink.Write(context.Background(), entry))
+	queue.EXPECT().EnqueueMessage(gomock.Any(), payload).Return(conflict).Times(maxEnqueueAttempts)
+	assert.Equal(t, conflict, sink.Write(context.Background(), entry))
+	queue.EXPECT().EnqueueMessage(gomock.Any(), payload).Return(errors.New("unavailable"))
+	assert.Error(t, sink.Write(context.Background(), entry))
+
+	assert.NoError(t, sink.Close())
+}
+
+func TestAsyncSink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockSink(ctrl)
+	sink := NewAsyncSink(inner, config.Audit{BufferSize: 1, FullBufferPolicy: config.AuditFullBufferPolicyDrop}, testlogger.New(t)).(*asyncSink)
+
+	// the first entry blocks the writes until it's released, the second one is buffered and the third one is dropped
+	written := make(chan struct{})
+	release := make(chan struct{})
+	inner.EXPECT().Write(gomock.Any(), newTestEntry("wid1")).DoAndReturn(func(context.Context, *Entry) error {
+		close(written)
+		<-release
+		return nil
+	})
+	inner.EXPECT().Write(gomock.Any(), newTestEntry("wid2")).Return(errors.New("unavailable"))
+	inner.EXPECT().Close().Return(nil)
+
+	assert.NoError(t, sink.Write(context.Background(), newTestEntry("wid1")))
+	<-written
+	assert.NoError(t, sink.Write(context.Background(), newTestEntry("wid2")))
+	assert.NoError(t, sink.Write(context.Background(), newTestEntry("wid3")), "calls don't fail when entries are dropped")
+	assert.Equal(t, int64(1), atomic.LoadInt64(&sink.dropped))
+	close(release)
+
+	// buffered entries are written when the sink is closed
+	assert.NoError(t, sink.Close())
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, int64(0), atomic.LoadInt64(&sink.dropped), "dropped entries are logged")
+	assert.Error(t, sink.Write(context.Background(), newTestEntry("wid4")))
+}
+
+func TestAsyncSink_FullBufferFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockSink(ctrl)
+	sink := NewAsyncSink(inner, config.Audit{BufferSize: 1, FullBufferTimeout: 10 * time.Millisecond}, testlogger.New(t)).(*asyncSink)
+	assert.False(t, sink.dropOnFullBuffer, "the writes fail by default")
+
+	// the first entry blocks the writes until it's released and the second one is buffered
+	written := make(chan struct{})
+	release := make(chan struct{})
+	inner.EXPECT().Write(gomock.Any(), newTestEntry("wid1")).DoAndReturn(func(context.Context, *Entry) error {
+		close(written)
+		<-release
+		return nil
+	})
+	inner.EXPECT().Write(gomock.Any(), newTestEntry("wid2")).Return(nil)
+	inner.EXPECT().Write(gomock.Any(), newTestEntry("wid4")).Return(nil)
+	inner.EXPECT().Close().Return(nil)
+
+	assert.NoError(t, sink.Write(context.Background(), newTestEntry("wid1")))
+	<-written
+	assert.NoError(t, sink.Write(context.Background(), newTestEntry("wid2")))
+	assert.Equal(t, errBufferFull, sink.Write(context.Background(), newTestEntry("wid3")), "the buffer is still full after the timeout")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, sink.Write(ctx, newTestEntry("wid3")))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&sink.dropped))
+
+	// the write waits for space in the buffer
+	sink.fullBufferTimeout = time.Minute
+	writeErrCh := make(chan error)
+	go func() {
+		writeErrCh <- sink.Write(context.Background(), newTestEntry("wid4"))
+	}()
+	close(release)
+	assert.NoError(t, <-writeErrCh)
+	assert.NoError(t, sink.Close())
+}
+
+func TestKafkaSink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	producer := messaging.NewMockCloseableProducer(ctrl)
+	sink := NewKafkaSink(producer)
+
+	entry := newTestEntry("wid")
+	payload, err := json.Marshal(entry)
+	require.NoError(t, err)
+	producer.EXPECT().Publish(gomock.Any(), &messaging.KeyedMessage{Key: "wid", Value: payload}).Return(nil)
+	producer.EXPECT().Close().Return(nil)
+	assert.NoError(t, sink.Write(context.Background(), entry))
+	assert.NoError(t, sink.Close())
+}
+
+func TestNewSink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	sink, err := NewSink(config.Audit{}, nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, sink, "auditing is disabled without sink")
+
+	sink, err = NewSink(config.Audit{Sink: config.AuditSinkFile, FilePath: filepath.Join(t.TempDir(), "audit.log")}, nil, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &fileSink{}, sink)
+	require.NoError(t, sink.Close())
+
+	_, err = NewSink(config.Audit{Sink: config.AuditSinkKafka, KafkaApplication: "audit"}, nil, nil)
+	assert.Error(t, err, "kafka sink requires a messaging client")
+
+	messagingClient := messaging.NewMockClient(ctrl)
+	messagingClient.EXPECT().NewProducer("audit").Return(messaging.NewMockProducer(ctrl), nil)
+	sink, err = NewSink(config.Audit{Sink: config.AuditSinkKafka, KafkaApplication: "audit"}, messagingClient, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &kafkaSink{}, sink)
+
+	sink, err = NewSink(config.Audit{Sink: config.AuditSinkPersistence}, nil, persistence.NewMockQueueManager(ctrl))
+	require.NoError(t, err)
+	assert.IsType(t, &persistenceSink{}, sink)
+	require.NoError(t, sink.Close())
+
+	_, err = NewSink(config.Audit{Sink: "syslog"}, nil, nil)
+	assert.Error(t, err)
+}
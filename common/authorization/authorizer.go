@@ -102,6 +102,13 @@ type Authorizer interface {
 	Authorize(ctx context.Context, attributes *Attributes) (Result, error)
 }
 
+// StopAuthorizer stops the background work of the authorizer, if it has any
+func StopAuthorizer(authorizer Authorizer) {
+	if stoppable, ok := authorizer.(interface{ Stop() }); ok {
+		stoppable.Stop()
+	}
+}
+
 func GetAuthProviderClient(privateKey string) (clientworker.AuthorizationProvider, error) {
 	pk, err := os.ReadFile(privateKey)
 	if err != nil {
@@ -69,12 +69,33 @@ func (j JWTClaims) GetGroups() []string {
 	return strings.Split(j.Groups, groupSeparator)
 }
 
+// principal returns the caller identified by the claims, the name falls back to the subject
+func (j JWTClaims) principal() Principal {
+	actor := j.Name
+	if actor == "" {
+		actor = j.Subject
+	}
+	return Principal{Actor: actor, Groups: j.GetGroups(), Source: PrincipalSourceJWT}
+}
+
 // NewOAuthAuthorizer creates an oauth Authorizer
 func NewOAuthAuthorizer(
 	oauthConfig config.OAuthAuthorizer,
 	log log.Logger,
 	domainCache cache.DomainCache,
 ) (Authorizer, error) {
+	authority, err := newOAuthAuthority(oauthConfig, log, domainCache)
+	if err != nil {
+		return nil, err
+	}
+	return authority, nil
+}
+
+func newOAuthAuthority(
+	oauthConfig config.OAuthAuthorizer,
+	log log.Logger,
+	domainCache cache.DomainCache,
+) (*oauthAuthority, error) {
 	var jwks *keyfunc.JWKS
 	var key interface{}
 	var err error
@@ -114,54 +135,60 @@ func NewOAuthAuthorizer(
 
 // Authorize defines the logic to verify get claims from token
 func (a *oauthAuthority) Authorize(ctx context.Context, attributes *Attributes) (Result, error) {
+	claims, err := a.getVerifiedClaims(ctx)
+	if err != nil {
+		a.log.Debug("request is not authorized", tag.Error(err))
+		return Result{Decision: DecisionDeny}, nil
+	}
+
+	if claims.Admin {
+		return Result{Decision: DecisionAllow}, nil
+	}
+
+	domain, err := a.domainCache.GetDomain(attributes.DomainName)
+	if err != nil {
+		return Result{Decision: DecisionDeny}, err
+	}
+
+	if err := validatePermission(claims, attributes, domain.GetInfo().Data); err != nil {
+		a.log.Debug("request is not authorized", tag.Error(err))
+		return Result{Decision: DecisionDeny}, nil
+	}
+
+	return Result{Decision: DecisionAllow}, nil
+}
+
+// getVerifiedClaims returns the claims of the token in the header of the call once the token is verified
+func (a *oauthAuthority) getVerifiedClaims(ctx context.Context) (*JWTClaims, error) {
 	call := yarpc.CallFromContext(ctx)
 
 	token := call.Header(common.AuthorizationTokenHeaderName)
 	if token == "" {
-		a.log.Debug("request is not authorized", tag.Error(errors.New("token is not set in header")))
-		return Result{Decision: DecisionDeny}, nil
+		return nil, errors.New("token is not set in header")
 	}
 
 	var claims JWTClaims
 	parsedToken, err := a.parser.ParseWithClaims(token, &claims, a.keyFunc)
 	if err != nil {
-		a.log.Debug("request is not authorized", tag.Error(err))
-		return Result{Decision: DecisionDeny}, nil
+		return nil, err
 	}
 
 	if !isTokenInternal(parsedToken) {
 		parsed, _, err := a.parser.ParseUnverified(token, jwt.MapClaims{})
 		if err != nil {
-			a.log.Debug("request is not authorized", tag.Error(err))
-			return Result{Decision: DecisionDeny}, nil
+			return nil, err
 		}
 
 		if err := a.parseExternal(parsed.Claims.(jwt.MapClaims), &claims); err != nil {
-			a.log.Debug("request is not authorized", tag.Error(err))
-			return Result{Decision: DecisionDeny}, nil
+			return nil, err
 		}
 	}
 
 	if err := a.validateTTL(&claims); err != nil {
-		a.log.Debug("request is not authorized", tag.Error(err))
-		return Result{Decision: DecisionDeny}, nil
+		return nil, err
 	}
 
-	if claims.Admin {
-		return Result{Decision: DecisionAllow}, nil
-	}
-
-	domain, err := a.domainCache.GetDomain(attributes.DomainName)
-	if err != nil {
-		return Result{Decision: DecisionDeny}, err
-	}
-
-	if err := validatePermission(&claims, attributes, domain.GetInfo().Data); err != nil {
-		a.log.Debug("request is not authorized", tag.Error(err))
-		return Result{Decision: DecisionDeny}, nil
-	}
-
-	return Result{Decision: DecisionAllow}, nil
+	return &claims, nil
 }
 
 // keyFunc returns correct key to check signature
@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+)
+
+const (
+	// PrincipalSourceMTLS is the source of the principals identified by their mTLS client certificate
+	PrincipalSourceMTLS = "mtls"
+	// PrincipalSourceJWT is the source of the principals identified by their JWT
+	PrincipalSourceJWT = "jwt"
+)
+
+type (
+	// PrincipalResolver identifies the authenticated caller of a request
+	PrincipalResolver interface {
+		GetPrincipal(ctx context.Context) (Principal, bool)
+	}
+
+	principalResolver struct {
+		jwt *oauthAuthority
+		// mtlsFirst is whether the client certificate takes precedence over the JWT
+		mtlsFirst bool
+	}
+)
+
+// NewPrincipalResolver creates a PrincipalResolver which identifies the callers the same way as the enabled
+// authorizer: by their mTLS client certificate if the RBAC authorizer identifies them by it, otherwise
+// by their JWT verified with the credentials of the enabled OAuth or RBAC authorizer
+func NewPrincipalResolver(authorization config.Authorization, log log.Logger) (PrincipalResolver, error) {
+	var jwtConfig *config.OAuthAuthorizer
+	switch {
+	case authorization.OAuthAuthorizer.Enable:
+		jwtConfig = &authorization.OAuthAuthorizer
+	case authorization.RBACAuthorizer.Enable:
+		rbacJWT := authorization.RBACAuthorizer.JWTVerification()
+		jwtConfig = &rbacJWT
+	}
+
+	resolver := &principalResolver{
+		mtlsFirst: authorization.RBACAuthorizer.Enable && authorization.RBACAuthorizer.MTLSIdentity,
+	}
+	if jwtConfig != nil {
+		jwt, err := newOAuthAuthority(*jwtConfig, log, nil)
+		if err != nil {
+			return nil, err
+		}
+		resolver.jwt = jwt
+	}
+	return resolver, nil
+}
+
+// GetPrincipal returns the principal which the authorizer uses, the principal of the JWT
+// or of the mTLS client certificate, and falls back to the other one if the call has none
+func (r *principalResolver) GetPrincipal(ctx context.Context) (Principal, bool) {
+	if r.mtlsFirst {
+		if principal, ok := GetPeerCertificatePrincipal(ctx); ok {
+			return principal, true
+		}
+	}
+	if principal, ok := r.getJWTPrincipal(ctx); ok {
+		return principal, true
+	}
+	if !r.mtlsFirst {
+		return GetPeerCertificatePrincipal(ctx)
+	}
+	return Principal{}, false
+}
+
+func (r *principalResolver) getJWTPrincipal(ctx context.Context) (Principal, bool) {
+	if r.jwt == nil {
+		return Principal{}, false
+	}
+	claims, err := r.jwt.getVerifiedClaims(ctx)
+	if err != nil {
+		return Principal{}, false
+	}
+	return claims.principal(), true
+}
+
+// GetPeerCertificatePrincipal returns the principal of the client certificate verified by the mTLS handshake of the call.
+// The actor is the common name of the certificate and the groups are its organizational units.
+func GetPeerCertificatePrincipal(ctx context.Context) (Principal, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Principal{}, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return Principal{}, false
+	}
+	certificate := tlsInfo.State.VerifiedChains[0][0]
+	if certificate.Subject.CommonName == "" {
+		return Principal{}, false
+	}
+	return Principal{
+		Actor:  certificate.Subject.CommonName,
+		Groups: certificate.Subject.OrganizationalUnit,
+		Source: PrincipalSourceMTLS,
+	}, true
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log/testlogger"
+)
+
+func newTestPeerContext(ctx context.Context, state tls.ConnectionState) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func newTestVerifiedPeerContext(ctx context.Context, commonName string, units ...string) context.Context {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: commonName, OrganizationalUnit: units}}
+	return newTestPeerContext(ctx, tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{certificate},
+		VerifiedChains:   [][]*x509.Certificate{{certificate}},
+	})
+}
+
+func TestGetPeerCertificatePrincipal(t *testing.T) {
+	_, ok := GetPeerCertificatePrincipal(context.Background())
+	assert.False(t, ok, "calls without peer have no principal")
+
+	unverified := &x509.Certificate{Subject: pkix.Name{CommonName: "worker"}}
+	_, ok = GetPeerCertificatePrincipal(newTestPeerContext(context.Background(), tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{unverified},
+	}))
+	assert.False(t, ok, "certificates which were not verified don't identify the caller")
+
+	_, ok = GetPeerCertificatePrincipal(newTestVerifiedPeerContext(context.Background(), ""))
+	assert.False(t, ok, "certificates without common name don't identify the caller")
+
+	principal, ok := GetPeerCertificatePrincipal(newTestVerifiedPeerContext(context.Background(), "worker", "ops", "oncall"))
+	require.True(t, ok)
+	assert.Equal(t, Principal{Actor: "worker", Groups: []string{"ops", "oncall"}, Source: PrincipalSourceMTLS}, principal)
+}
+
+func TestPrincipalResolver(t *testing.T) {
+	resolver, err := NewPrincipalResolver(config.Authorization{}, testlogger.New(t))
+	require.NoError(t, err)
+	_, ok := resolver.GetPrincipal(newTestRBACContext(t, testRBACToken))
+	assert.False(t, ok, "tokens are not verified without OAuth or RBAC authorizer")
+	principal, ok := resolver.GetPrincipal(newTestVerifiedPeerContext(context.Background(), "worker"))
+	require.True(t, ok)
+	assert.Equal(t, "worker", principal.Actor)
+
+	resolver, err = NewPrincipalResolver(config.Authorization{RBACAuthorizer: newTestRBACConfig("unused")}, testlogger.New(t))
+	require.NoError(t, err)
+	principal, ok = resolver.GetPrincipal(newTestRBACContext(t, testRBACToken))
+	require.True(t, ok)
+	assert.Equal(t, Principal{Actor: "John Doe", Groups: []string{"a", "b", "c"}, Source: PrincipalSourceJWT}, principal)
+	principal, ok = resolver.GetPrincipal(newTestVerifiedPeerContext(newTestRBACContext(t, testRBACToken), "worker"))
+	require.True(t, ok)
+	assert.Equal(t, "John Doe", principal.Actor, "the token takes precedence unless the RBAC authorizer uses certificates")
+	principal, ok = resolver.GetPrincipal(newTestVerifiedPeerContext(context.Background(), "worker"))
+	require.True(t, ok)
+	assert.Equal(t, "worker", principal.Actor, "the certificate identifies callers without token")
+	_, ok = resolver.GetPrincipal(newTestRBACContext(t, "invalid"))
+	assert.False(t, ok)
+
+	rbacConfig := newTestRBACConfig("unused")
+	rbacConfig.MTLSIdentity = true
+	resolver, err = NewPrincipalResolver(config.Authorization{RBACAuthorizer: rbacConfig}, testlogger.New(t))
+	require.NoError(t, err)
+	principal, ok = resolver.GetPrincipal(newTestVerifiedPeerContext(newTestRBACContext(t, testRBACToken), "worker"))
+	require.True(t, ok)
+	assert.Equal(t, "worker", principal.Actor, "the certificate takes precedence when the RBAC authorizer uses it")
+	principal, ok = resolver.GetPrincipal(newTestRBACContext(t, testRBACToken))
+	require.True(t, ok)
+	assert.Equal(t, "John Doe", principal.Actor)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+)
+
+const defaultRBACPollInterval = 10 * time.Second
+
+type rbacAuthority struct {
+	config      config.RBACAuthorizer
+	log         log.Logger
+	jwt         *oauthAuthority
+	policy      atomic.Value // *RBACPolicy
+	contentHash []byte
+	doneCh      chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewRBACAuthorizer creates an Authorizer which identifies the callers by their JWT,
+// or their mTLS client certificate if enabled, and authorizes them with the rules of the policy file.
+// The policy file is polled for changes until the authorizer is stopped with StopAuthorizer.
+func NewRBACAuthorizer(
+	rbacConfig config.RBACAuthorizer,
+	log log.Logger,
+) (Authorizer, error) {
+	authority, err := newRBACAuthority(rbacConfig, log)
+	if err != nil {
+		return nil, err
+	}
+	return authority, nil
+}
+
+// newRBACAuthority creates the authorizer, the policy file is polled for changes until it's stopped
+func newRBACAuthority(
+	rbacConfig config.RBACAuthorizer,
+	log log.Logger,
+) (*rbacAuthority, error) {
+	jwt, err := newOAuthAuthority(rbacConfig.JWTVerification(), log, nil)
+	if err != nil {
+		return nil, err
+	}
+	if rbacConfig.PollInterval <= 0 {
+		rbacConfig.PollInterval = defaultRBACPollInterval
+	}
+
+	a := &rbacAuthority{
+		config: rbacConfig,
+		log:    log,
+		jwt:    jwt,
+		doneCh: make(chan struct{}),
+	}
+	if err := a.update(); err != nil {
+		return nil, err
+	}
+	go func() {
+		ticker := time.NewTicker(a.config.PollInterval)
+		for {
+			select {
+			case <-ticker.C:
+				if err := a.update(); err != nil {
+					a.log.Error("Failed to update RBAC policy, the previous policy is still used", tag.Error(err))
+				}
+			case <-a.doneCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return a, nil
+}
+
+// Authorize allows the request if the policy allows the caller identified by the JWT,
+// or by the mTLS client certificate, to make it
+func (a *rbacAuthority) Authorize(ctx context.Context, attributes *Attributes) (Result, error) {
+	principal, ok := Principal{}, false
+	if a.config.MTLSIdentity {
+		principal, ok = GetPeerCertificatePrincipal(ctx)
+	}
+	if !ok {
+		claims, err := a.jwt.getVerifiedClaims(ctx)
+		if err != nil {
+			a.log.Debug("request is not authorized", tag.Error(err))
+			return Result{Decision: DecisionDeny}, nil
+		}
+
+		if claims.Admin {
+			return Result{Decision: DecisionAllow}, nil
+		}
+		principal = claims.principal()
+	}
+
+	explanation := a.getPolicy().Explain(principal, attributes)
+	if explanation.Decision != DecisionAllow {
+		a.log.Debug("request is not authorized", tag.Value(explanation.Reason))
+	}
+	return Result{Decision: explanation.Decision}, nil
+}
+
+// Stop stops polling the policy file
+func (a *rbacAuthority) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.doneCh)
+	})
+}
+
+func (a *rbacAuthority) getPolicy() *RBACPolicy {
+	return a.policy.Load().(*RBACPolicy)
+}
+
+// update reloads the policy file if its content changed since the last update,
+// the modification time isn't used as it may not change, e.g. when the file is replaced by a copy
+func (a *rbacAuthority) update() error {
+	content, err := os.ReadFile(a.config.PolicyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file %v: %v", a.config.PolicyFile, err)
+	}
+	hash := sha256.Sum256(content)
+	if bytes.Equal(hash[:], a.contentHash) {
+		return nil
+	}
+	policy, err := parseRBACPolicy(a.config.PolicyFile, content)
+	if err != nil {
+		return err
+	}
+	a.policy.Store(policy)
+	a.contentHash = hash[:]
+	a.log.Info("RBAC policy is updated", tag.Value(a.config.PolicyFile))
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// RBACEffectAllow grants the APIs of a rule
+	RBACEffectAllow = "allow"
+	// RBACEffectDeny denies the APIs of a rule, deny rules take precedence over allow rules
+	RBACEffectDeny = "deny"
+)
+
+type (
+	// RBACPolicy is the content of the policy file of the RBAC authorizer.
+	// Requests which are not allowed by any rule are denied.
+	RBACPolicy struct {
+		Rules []RBACRule `yaml:"rules"`
+	}
+
+	// RBACRule allows or denies the actors and groups to call the APIs on the resources of the rule.
+	// All the fields are glob patterns, e.g. "Describe*". An empty list of domains, workflow types
+	// or task lists matches any of them, a non-empty list only matches the requests that carry it.
+	// Most APIs don't carry the workflow type or task list, so deny rules with workflow types or
+	// task lists fail closed and also match the requests without them.
+	RBACRule struct {
+		Name          string   `yaml:"name"`
+		Effect        string   `yaml:"effect"`
+		Actors        []string `yaml:"actors"`
+		Groups        []string `yaml:"groups"`
+		APIs          []string `yaml:"apis"`
+		Domains       []string `yaml:"domains"`
+		WorkflowTypes []string `yaml:"workflowTypes"`
+		TaskLists     []string `yaml:"taskLists"`
+	}
+
+	// Principal is the caller which is authorized
+	Principal struct {
+		Actor  string
+		Groups []string
+		// Source is how the caller was identified, e.g. PrincipalSourceMTLS
+		Source string
+	}
+
+	// RBACExplanation is the decision of a policy and why it was made
+	RBACExplanation struct {
+		Decision Decision
+		// Rule is the name of the rule which made the decision, it's empty if no rule matches
+		Rule   string
+		Reason string
+	}
+)
+
+// LoadRBACPolicy reads and validates a policy file
+func LoadRBACPolicy(policyFile string) (*RBACPolicy, error) {
+	content, err := os.ReadFile(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %v: %v", policyFile, err)
+	}
+	return parseRBACPolicy(policyFile, content)
+}
+
+// parseRBACPolicy decodes and validates the content of a policy file
+func parseRBACPolicy(policyFile string, content []byte) (*RBACPolicy, error) {
+	var policy RBACPolicy
+	if err := yaml.UnmarshalStrict(content, &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy file %v: %v", policyFile, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate validates the rules of the policy
+func (p *RBACPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %v has no name", i)
+		}
+		if rule.Effect != "" && rule.Effect != RBACEffectAllow && rule.Effect != RBACEffectDeny {
+			return fmt.Errorf("rule %v has unknown effect %q", rule.Name, rule.Effect)
+		}
+		if len(rule.Actors) == 0 && len(rule.Groups) == 0 {
+			return fmt.Errorf("rule %v applies to no actor or group", rule.Name)
+		}
+		if len(rule.APIs) == 0 {
+			return fmt.Errorf("rule %v applies to no API", rule.Name)
+		}
+		for _, patterns := range [][]string{rule.Actors, rule.Groups, rule.APIs, rule.Domains, rule.WorkflowTypes, rule.TaskLists} {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("rule %v has invalid pattern %q: %v", rule.Name, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Explain returns whether the principal is allowed to make the request and why
+func (p *RBACPolicy) Explain(principal Principal, attributes *Attributes) RBACExplanation {
+	var allowedBy *RBACRule
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if !rule.matches(principal, attributes) {
+			continue
+		}
+		if rule.Effect == RBACEffectDeny {
+			return RBACExplanation{
+				Decision: DecisionDeny,
+				Rule:     rule.Name,
+				Reason:   fmt.Sprintf("%v is denied by rule %v", describeRequest(principal, attributes), rule.Name),
+			}
+		}
+		if allowedBy == nil {
+			allowedBy = rule
+		}
+	}
+	if allowedBy != nil {
+		return RBACExplanation{
+			Decision: DecisionAllow,
+			Rule:     allowedBy.Name,
+			Reason:   fmt.Sprintf("%v is allowed by rule %v", describeRequest(principal, attributes), allowedBy.Name),
+		}
+	}
+	return RBACExplanation{
+		Decision: DecisionDeny,
+		Reason:   fmt.Sprintf("%v is not allowed by any rule", describeRequest(principal, attributes)),
+	}
+}
+
+func (r *RBACRule) matches(principal Principal, attributes *Attributes) bool {
+	if !matchAnyOf(r.Actors, []string{principal.Actor}) && !matchAnyOf(r.Groups, principal.Groups) {
+		return false
+	}
+	if !matchAny(r.APIs, attributes.APIName) {
+		return false
+	}
+	if len(r.Domains) > 0 && (attributes.DomainName == "" || !matchAny(r.Domains, attributes.DomainName)) {
+		return false
+	}
+	if len(r.WorkflowTypes) > 0 && !r.matchOptional(r.WorkflowTypes, attributes.WorkflowType != nil, attributes.WorkflowType.GetName()) {
+		return false
+	}
+	if len(r.TaskLists) > 0 && !r.matchOptional(r.TaskLists, attributes.TaskList != nil, attributes.TaskList.GetName()) {
+		return false
+	}
+	return true
+}
+
+// matchOptional matches an attribute which only some requests carry,
+// deny rules match the requests without it so that they can't be bypassed
+func (r *RBACRule) matchOptional(patterns []string, present bool, value string) bool {
+	if !present {
+		return r.Effect == RBACEffectDeny
+	}
+	return matchAny(patterns, value)
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		// patterns are validated when the policy is loaded
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyOf(patterns []string, values []string) bool {
+	for _, value := range values {
+		if value != "" && matchAny(patterns, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeRequest(principal Principal, attributes *Attributes) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "actor %q", principal.Actor)
+	if len(principal.Groups) > 0 {
+		fmt.Fprintf(&sb, " (groups %v)", strings.Join(principal.Groups, ", "))
+	}
+	fmt.Fprintf(&sb, " calling %v", attributes.APIName)
+	if attributes.DomainName != "" {
+		fmt.Fprintf(&sb, " on domain %v", attributes.DomainName)
+	}
+	if attributes.WorkflowType != nil {
+		fmt.Fprintf(&sb, " with workflow type %v", attributes.WorkflowType.GetName())
+	}
+	if attributes.TaskList != nil {
+		fmt.Fprintf(&sb, " with task list %v", attributes.TaskList.GetName())
+	}
+	return sb.String()
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2021 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package authorization
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/types"
+)
+
+const testRBACPolicy = `
+rules:
+  - name: payments-operators
+    groups: [payments-oncall]
+    apis: ["Describe*", SignalWorkflowExecution]
+    domains: [payments]
+  - name: charge-starters
+    actors: [charge-service]
+    apis: [StartWorkflowExecution]
+    domains: ["payments*"]
+    workflowTypes: [ChargeWorkflow]
+  - name: charge-pollers
+    actors: [charge-service]
+    groups: [charge-workers]
+    apis: ["Poll*"]
+    taskLists: [charge-tl]
+  - name: no-signals-during-freeze
+    effect: deny
+    actors: ["*"]
+    apis: [SignalWorkflowExecution]
+    domains: [payments]
+  - name: no-refunds
+    effect: deny
+    actors: [charge-service]
+    apis: ["*WorkflowExecution"]
+    workflowTypes: [RefundWorkflow]
+  - name: no-legacy-polls
+    effect: deny
+    actors: [charge-service]
+    apis: ["Poll*"]
+    taskLists: ["legacy-*"]
+`
+
+func writeTestRBACPolicy(t *testing.T, content string) string {
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte(content), 0644))
+	return policyFile
+}
+
+func TestRBACPolicy_Explain(t *testing.T) {
+	policy, err := LoadRBACPolicy(writeTestRBACPolicy(t, testRBACPolicy))
+	require.NoError(t, err)
+
+	oncall := Principal{Actor: "alice", Groups: []string{"payments-oncall"}}
+	service := Principal{Actor: "charge-service"}
+	tests := map[string]struct {
+		principal  Principal
+		attributes Attributes
+		decision   Decision
+		rule       string
+	}{
+		"allowed by group": {
+			principal:  oncall,
+			attributes: Attributes{APIName: "DescribeWorkflowExecution", DomainName: "payments"},
+			decision:   DecisionAllow,
+			rule:       "payments-operators",
+		},
+		"other domain": {
+			principal:  oncall,
+			attributes: Attributes{APIName: "DescribeWorkflowExecution", DomainName: "orders"},
+			decision:   DecisionDeny,
+		},
+		"domain level API without domain": {
+			principal:  oncall,
+			attributes: Attributes{APIName: "DescribeCluster"},
+			decision:   DecisionDeny,
+		},
+		"deny takes precedence": {
+			principal:  oncall,
+			attributes: Attributes{APIName: "SignalWorkflowExecution", DomainName: "payments"},
+			decision:   DecisionDeny,
+			rule:       "no-signals-during-freeze",
+		},
+		"allowed workflow type": {
+			principal: service,
+			attributes: Attributes{
+				APIName:      "StartWorkflowExecution",
+				DomainName:   "payments-staging",
+				WorkflowType: &types.WorkflowType{Name: "ChargeWorkflow"},
+			},
+			decision: DecisionAllow,
+			rule:     "charge-starters",
+		},
+		"other workflow type": {
+			principal: service,
+			attributes: Attributes{
+				APIName:      "StartWorkflowExecution",
+				DomainName:   "payments",
+				WorkflowType: &types.WorkflowType{Name: "OrderWorkflow"},
+			},
+			decision: DecisionDeny,
+		},
+		"denied workflow type": {
+			principal: service,
+			attributes: Attributes{
+				APIName:      "StartWorkflowExecution",
+				DomainName:   "payments",
+				WorkflowType: &types.WorkflowType{Name: "RefundWorkflow"},
+			},
+			decision: DecisionDeny,
+			rule:     "no-refunds",
+		},
+		"deny rule matches requests without workflow type": {
+			principal:  service,
+			attributes: Attributes{APIName: "TerminateWorkflowExecution", DomainName: "payments"},
+			decision:   DecisionDeny,
+			rule:       "no-refunds",
+		},
+		"allowed task list": {
+			principal: service,
+			attributes: Attributes{
+				APIName:    "PollForActivityTask",
+				DomainName: "payments",
+				TaskList:   &types.TaskList{Name: "charge-tl"},
+			},
+			decision: DecisionAllow,
+			rule:     "charge-pollers",
+		},
+		"denied task list": {
+			principal: service,
+			attributes: Attributes{
+				APIName:    "PollForActivityTask",
+				DomainName: "payments",
+				TaskList:   &types.TaskList{Name: "legacy-tl"},
+			},
+			decision: DecisionDeny,
+			rule:     "no-legacy-polls",
+		},
+		"deny rule matches requests without task list": {
+			principal:  service,
+			attributes: Attributes{APIName: "PollForActivityTask", DomainName: "payments"},
+			decision:   DecisionDeny,
+			rule:       "no-legacy-polls",
+		},
+		"allow rule doesn't match requests without task list": {
+			principal:  Principal{Actor: "charge-worker", Groups: []string{"charge-workers"}},
+			attributes: Attributes{APIName: "PollForActivityTask", DomainName: "payments"},
+			decision:   DecisionDeny,
+		},
+		"other actor": {
+			principal: Principal{Actor: "refund-service"},
+			attributes: Attributes{
+				APIName:    "PollForActivityTask",
+				DomainName: "payments",
+				TaskList:   &types.TaskList{Name: "charge-tl"},
+			},
+			decision: DecisionDeny,
+		},
+		"anonymous actor doesn't match wildcard": {
+			principal:  Principal{Groups: []string{"payments-oncall"}},
+			attributes: Attributes{APIName: "SignalWorkflowExecution", DomainName: "payments"},
+			decision:   DecisionAllow,
+			rule:       "payments-operators",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			explanation := policy.Explain(test.principal, &test.attributes)
+			assert.Equal(t, test.decision, explanation.Decision)
+			assert.Equal(t, test.rule, explanation.Rule)
+			assert.Contains(t, explanation.Reason, test.attributes.APIName)
+		})
+	}
+}
+
+func TestLoadRBACPolicy_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		err     string
+	}{
+		"unknown field": {
+			content: "rules:\n  - name: r\n    actor: [a]\n    apis: [\"*\"]\n",
+			err:     "failed to decode policy file",
+		},
+		"no name": {
+			content: "rules:\n  - actors: [a]\n    apis: [\"*\"]\n",
+			err:     "rule 0 has no name",
+		},
+		"unknown effect": {
+			content: "rules:\n  - name: r\n    effect: maybe\n    actors: [a]\n    apis: [\"*\"]\n",
+			err:     "rule r has unknown effect \"maybe\"",
+		},
+		"no subject": {
+			content: "rules:\n  - name: r\n    apis: [\"*\"]\n",
+			err:     "rule r applies to no actor or group",
+		},
+		"no API": {
+			content: "rules:\n  - name: r\n    actors: [a]\n",
+			err:     "rule r applies to no API",
+		},
+		"invalid pattern": {
+			content: "rules:\n  - name: r\n    actors: [a]\n    apis: [\"[\"]\n",
+			err:     "rule r has invalid pattern",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadRBACPolicy(writeTestRBACPolicy(t, test.content))
+			assert.ErrorContains(t, err, test.err)
+		})
+	}
+
+	_, err := LoadRBACPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read policy file")
+}
@@ -0,0 +1,178 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/uber/cadence/common/archiver/objectstore"
+	"github.com/uber/cadence/common/blobstore"
+	"github.com/uber/cadence/common/config"
+)
+
+const (
+	// the tags are stored base64 encoded in a single metadata value, since
+	// object stores only keep ASCII metadata and may change the case of its keys
+	tagsMetadataKey = "Cadence-Tags"
+)
+
+type (
+	client struct {
+		s3cli  s3iface.S3API
+		bucket string
+		prefix string
+	}
+)
+
+// NewObjectstoreClient constructs a blobstore backed by an S3-compatible object store
+func NewObjectstoreClient(cfg *config.ObjectstoreBlobstore) (blobstore.Client, error) {
+	if cfg == nil {
+		return nil, errors.New("object store blobstore config is nil")
+	}
+	if len(cfg.Endpoint) == 0 {
+		return nil, errors.New("endpoint not given for object store blobstore")
+	}
+	if len(cfg.Bucket) == 0 {
+		return nil, errors.New("bucket not given for object store blobstore")
+	}
+	s3cli, err := objectstore.NewS3Client(&cfg.ObjectstoreArchiver)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(s3cli, cfg.Bucket, cfg.Prefix), nil
+}
+
+func newClient(s3cli s3iface.S3API, bucket string, prefix string) *client {
+	return &client{
+		s3cli:  s3cli,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+// Put stores a blob
+func (c *client) Put(ctx context.Context, request *blobstore.PutRequest) (*blobstore.PutResponse, error) {
+	tagsData, err := json.Marshal(request.Blob.Tags)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.s3cli.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:   aws.String(c.bucket),
+		Key:      aws.String(c.objectKey(request.Key)),
+		Body:     bytes.NewReader(request.Blob.Body),
+		Metadata: map[string]*string{tagsMetadataKey: aws.String(base64.StdEncoding.EncodeToString(tagsData))},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &blobstore.PutResponse{}, nil
+}
+
+// Get fetches a blob
+func (c *client) Get(ctx context.Context, request *blobstore.GetRequest) (*blobstore.GetResponse, error) {
+	result, err := c.s3cli.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(c.objectKey(request.Key)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+	data, err := ioutil.ReadAll(result.Body)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string)
+	if encodedTags := aws.StringValue(result.Metadata[tagsMetadataKey]); encodedTags != "" {
+		tagsData, err := base64.StdEncoding.DecodeString(encodedTags)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tagsData, &tags); err != nil {
+			return nil, err
+		}
+	}
+	return &blobstore.GetResponse{
+		Blob: blobstore.Blob{
+			Body: data,
+			Tags: tags,
+		},
+	}, nil
+}
+
+// Exists determines if a blob exists
+func (c *client) Exists(ctx context.Context, request *blobstore.ExistsRequest) (*blobstore.ExistsResponse, error) {
+	_, err := c.s3cli.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(c.objectKey(request.Key)),
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return &blobstore.ExistsResponse{Exists: false}, nil
+		}
+		return nil, err
+	}
+	return &blobstore.ExistsResponse{
+		Exists: true,
+	}, nil
+}
+
+// Delete deletes a blob
+func (c *client) Delete(ctx context.Context, request *blobstore.DeleteRequest) (*blobstore.DeleteResponse, error) {
+	_, err := c.s3cli.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(c.objectKey(request.Key)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &blobstore.DeleteResponse{}, nil
+}
+
+// IsRetryableError returns true if the error is retryable false otherwise
+func (c *client) IsRetryableError(err error) bool {
+	return objectstore.IsRetryableError(err)
+}
+
+func (c *client) objectKey(key string) string {
+	if c.prefix == "" {
+		return key
+	}
+	return path.Join(c.prefix, key)
+}
+
+func isNotFoundError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && (aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey)
+}
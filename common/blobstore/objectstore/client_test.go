@@ -0,0 +1,169 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/uber/cadence/common/blobstore"
+	"github.com/uber/cadence/common/config"
+)
+
+type (
+	ClientSuite struct {
+		*require.Assertions
+		suite.Suite
+
+		s3cli  *fakeS3
+		client *client
+	}
+
+	// fakeS3 is an in-memory object store with a single bucket
+	fakeS3 struct {
+		s3iface.S3API
+
+		sync.Mutex
+		bucket   string
+		objects  map[string][]byte
+		metadata map[string]map[string]*string
+	}
+)
+
+func TestClientSuite(t *testing.T) {
+	suite.Run(t, new(ClientSuite))
+}
+
+func (s *ClientSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.s3cli = &fakeS3{
+		bucket:   "test-bucket",
+		objects:  make(map[string][]byte),
+		metadata: make(map[string]map[string]*string),
+	}
+	s.client = newClient(s.s3cli, "test-bucket", "payloads")
+}
+
+func (s *ClientSuite) TestNewObjectstoreClient_InvalidConfig() {
+	_, err := NewObjectstoreClient(nil)
+	s.Error(err)
+	_, err = NewObjectstoreClient(&config.ObjectstoreBlobstore{Bucket: "test-bucket"})
+	s.Error(err)
+	_, err = NewObjectstoreClient(&config.ObjectstoreBlobstore{ObjectstoreArchiver: config.ObjectstoreArchiver{Endpoint: "http://127.0.0.1:9000"}})
+	s.Error(err)
+}
+
+func (s *ClientSuite) TestCrudOperations() {
+	ctx := context.Background()
+	key := "tree_id/branch_id/1"
+
+	existsResp, err := s.client.Exists(ctx, &blobstore.ExistsRequest{Key: key})
+	s.NoError(err)
+	s.False(existsResp.Exists)
+
+	blob := blobstore.Blob{
+		Tags: map[string]string{"tree_id": "tree", "encoding": "thriftrw+zstd"},
+		Body: []byte{1, 2, 3},
+	}
+	_, err = s.client.Put(ctx, &blobstore.PutRequest{Key: key, Blob: blob})
+	s.NoError(err)
+	s.Contains(s.s3cli.objects, "payloads/"+key)
+
+	existsResp, err = s.client.Exists(ctx, &blobstore.ExistsRequest{Key: key})
+	s.NoError(err)
+	s.True(existsResp.Exists)
+
+	getResp, err := s.client.Get(ctx, &blobstore.GetRequest{Key: key})
+	s.NoError(err)
+	s.Equal(blob, getResp.Blob)
+
+	_, err = s.client.Delete(ctx, &blobstore.DeleteRequest{Key: key})
+	s.NoError(err)
+	existsResp, err = s.client.Exists(ctx, &blobstore.ExistsRequest{Key: key})
+	s.NoError(err)
+	s.False(existsResp.Exists)
+
+	_, err = s.client.Get(ctx, &blobstore.GetRequest{Key: key})
+	s.Error(err)
+}
+
+func (s *ClientSuite) TestIsRetryableError() {
+	s.False(s.client.IsRetryableError(awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)))
+	s.True(s.client.IsRetryableError(awserr.NewRequestFailure(awserr.New("InternalError", "internal error", nil), 503, "")))
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, input *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	if aws.StringValue(input.Bucket) != f.bucket {
+		return nil, awserr.New(s3.ErrCodeNoSuchBucket, "no such bucket", nil)
+	}
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[aws.StringValue(input.Key)] = data
+	f.metadata[aws.StringValue(input.Key)] = input.Metadata
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	data, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok || aws.StringValue(input.Bucket) != f.bucket {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such key", nil)
+	}
+	return &s3.GetObjectOutput{
+		Body:     ioutil.NopCloser(bytes.NewReader(data)),
+		Metadata: f.metadata[aws.StringValue(input.Key)],
+	}, nil
+}
+
+func (f *fakeS3) HeadObjectWithContext(_ aws.Context, input *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	if _, ok := f.objects[aws.StringValue(input.Key)]; !ok || aws.StringValue(input.Bucket) != f.bucket {
+		return nil, awserr.New("NotFound", "not found", nil)
+	}
+	return &s3.HeadObjectOutput{}, nil
+}
+
+func (f *fakeS3) DeleteObjectWithContext(_ aws.Context, input *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.objects, aws.StringValue(input.Key))
+	delete(f.metadata, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
@@ -23,6 +23,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -31,6 +32,7 @@ type (
 	Authorization struct {
 		OAuthAuthorizer OAuthAuthorizer `yaml:"oauthAuthorizer"`
 		NoopAuthorizer  NoopAuthorizer  `yaml:"noopAuthorizer"`
+		RBACAuthorizer  RBACAuthorizer  `yaml:"rbacAuthorizer"`
 	}
 
 	NoopAuthorizer struct {
@@ -54,6 +56,25 @@ type (
 		PublicKey string `yaml:"publicKey"`
 	}
 
+	// RBACAuthorizer authorizes the callers identified by their JWT with the rules of a policy file
+	RBACAuthorizer struct {
+		Enable bool `yaml:"enable"`
+		// Path of the policy file, the file is reloaded when it changes
+		PolicyFile string `yaml:"policyFile"`
+		// How often the policy file is checked for changes, defaults to 10s
+		PollInterval time.Duration `yaml:"pollInterval"`
+		// Identify the callers presenting a client certificate verified by mTLS by the common name
+		// and the organizational units of the certificate, instead of by their JWT.
+		// The JWT credentials are optional when it's enabled.
+		MTLSIdentity bool `yaml:"mtlsIdentity"`
+		// Max of TTL in the claim
+		MaxJwtTTL int64 `yaml:"maxJwtTTL"`
+		// Credentials to verify/create the JWT using public/private keys
+		JwtCredentials *JwtCredentials `yaml:"jwtCredentials"`
+		// Provider
+		Provider *OAuthProvider `yaml:"provider"`
+	}
+
 	// OAuthProvider is used to validate tokens provided by 3rd party Identity Provider service
 	OAuthProvider struct {
 		JWKSURL             string `yaml:"jwksURL"`
@@ -64,21 +85,48 @@ type (
 
 // Validate validates the persistence config
 func (a *Authorization) Validate() error {
-	if a.OAuthAuthorizer.Enable && a.NoopAuthorizer.Enable {
+	enabled := 0
+	for _, enable := range []bool{a.OAuthAuthorizer.Enable, a.NoopAuthorizer.Enable, a.RBACAuthorizer.Enable} {
+		if enable {
+			enabled++
+		}
+	}
+	if enabled > 1 {
 		return fmt.Errorf("[AuthorizationConfig] More than one authorizer is enabled")
 	}
 
 	if a.OAuthAuthorizer.Enable {
-		if err := a.validateOAuth(); err != nil {
+		if err := validateOAuth(a.OAuthAuthorizer); err != nil {
 			return err
 		}
 	}
 
+	if a.RBACAuthorizer.Enable {
+		if a.RBACAuthorizer.PolicyFile == "" {
+			return fmt.Errorf("[RBACConfig] PolicyFile can't be empty")
+		}
+		rbacJWT := a.RBACAuthorizer.JWTVerification()
+		if !a.RBACAuthorizer.MTLSIdentity || rbacJWT.JwtCredentials != nil || rbacJWT.Provider != nil {
+			if err := validateOAuth(rbacJWT); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
-func (a *Authorization) validateOAuth() error {
-	oauthConfig := a.OAuthAuthorizer
+// JWTVerification returns the config verifying the JWT which identifies the callers
+func (r RBACAuthorizer) JWTVerification() OAuthAuthorizer {
+	return OAuthAuthorizer{
+		Enable:         r.Enable,
+		MaxJwtTTL:      r.MaxJwtTTL,
+		JwtCredentials: r.JwtCredentials,
+		Provider:       r.Provider,
+	}
+}
+
+func validateOAuth(oauthConfig OAuthAuthorizer) error {
 
 	if oauthConfig.MaxJwtTTL <= 0 {
 		return fmt.Errorf("[OAuthConfig] MaxTTL must be greater than 0")
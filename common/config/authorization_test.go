@@ -112,3 +112,57 @@ func TestCorrectValidation(t *testing.T) {
 	err := cfg.Validate()
 	assert.NoError(t, err)
 }
+
+func TestRBACPolicyFileIsEmpty(t *testing.T) {
+	cfg := Authorization{
+		RBACAuthorizer: RBACAuthorizer{
+			Enable: true,
+		},
+	}
+
+	err := cfg.Validate()
+	assert.EqualError(t, err, "[RBACConfig] PolicyFile can't be empty")
+}
+
+func TestRBACAndOAuthEnabled(t *testing.T) {
+	cfg := Authorization{
+		OAuthAuthorizer: OAuthAuthorizer{
+			Enable: true,
+		},
+		RBACAuthorizer: RBACAuthorizer{
+			Enable: true,
+		},
+	}
+
+	err := cfg.Validate()
+	assert.EqualError(t, err, "[AuthorizationConfig] More than one authorizer is enabled")
+}
+
+func TestRBACValidatesJWT(t *testing.T) {
+	cfg := Authorization{
+		RBACAuthorizer: RBACAuthorizer{
+			Enable:     true,
+			PolicyFile: "policy.yaml",
+			MaxJwtTTL:  0,
+		},
+	}
+
+	err := cfg.Validate()
+	assert.EqualError(t, err, "[OAuthConfig] MaxTTL must be greater than 0")
+}
+
+func TestRBACWithMTLSIdentityDoesNotRequireJWT(t *testing.T) {
+	cfg := Authorization{
+		RBACAuthorizer: RBACAuthorizer{
+			Enable:       true,
+			PolicyFile:   "policy.yaml",
+			MTLSIdentity: true,
+		},
+	}
+
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RBACAuthorizer.JwtCredentials = &JwtCredentials{}
+	err := cfg.Validate()
+	assert.EqualError(t, err, "[OAuthConfig] MaxTTL must be greater than 0", "configured JWT credentials are still validated")
+}
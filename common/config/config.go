@@ -32,8 +32,10 @@ import (
 	yarpctls "go.uber.org/yarpc/api/transport/tls"
 	"gopkg.in/yaml.v2" // CAUTION: go.uber.org/config does not support yaml.v3
 
+	"github.com/uber/cadence/common/blobstore"
 	"github.com/uber/cadence/common/dynamicconfig"
 	c "github.com/uber/cadence/common/dynamicconfig/configstore/config"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/peerprovider/ringpopprovider"
 	"github.com/uber/cadence/common/service"
 )
@@ -77,9 +79,12 @@ type (
 		Blobstore Blobstore `yaml:"blobstore"`
 		// Authorization is the config for setting up authorization
 		Authorization Authorization `yaml:"authorization"`
+		// Encryption is the config for encrypting payloads at rest
+		Encryption Encryption `yaml:"encryption"`
+		// Audit is the config for auditing the API calls of the frontend
+		Audit Audit `yaml:"audit"`
 		// HeaderForwardingRules defines which inbound headers to include or exclude on outbound calls
 		HeaderForwardingRules []HeaderRule `yaml:"headerForwardingRules"`
-		// Note: This is not implemented yet. It's coming in the next release.
 		// AsyncWorkflowQueues is the config for predefining async workflow queue(s)
 		// To use Async APIs for a domain first specify the queue using Admin API.
 		// Either refer to one of the predefined queues in this config or alternatively specify the queue details inline in the API call.
@@ -163,6 +168,8 @@ type (
 	// Blobstore contains the config for blobstore
 	Blobstore struct {
 		Filestore *FileBlobstore `yaml:"filestore"`
+		// Objectstore is shared by all hosts, so the history event payloads are offloaded to it when it's set
+		Objectstore *ObjectstoreBlobstore `yaml:"objectstore"`
 	}
 
 	// FileBlobstore contains the config for a file backed blobstore
@@ -170,6 +177,52 @@ type (
 		OutputDirectory string `yaml:"outputDirectory"`
 	}
 
+	// ObjectstoreBlobstore contains the config for a blobstore backed by an S3-compatible object store, e.g. MinIO
+	ObjectstoreBlobstore struct {
+		// Bucket is the existing bucket the blobs are stored in
+		Bucket string `yaml:"bucket"`
+		// Prefix is prepended to the keys of the blobs
+		Prefix string `yaml:"prefix"`
+		// ObjectstoreArchiver is the connection to the object store, it's set the same way as for the objectstore archiver
+		ObjectstoreArchiver `yaml:",inline"`
+	}
+
+	// Encryption contains the config for encrypting payloads at rest
+	Encryption struct {
+		Keyring *FileKeyring `yaml:"keyring"`
+	}
+
+	// Audit contains the config for recording who called the APIs of the frontend
+	Audit struct {
+		// Sink is where the audit entries are written to, one of "file", "kafka" or "persistence".
+		// Auditing is disabled when it's empty.
+		Sink string `yaml:"sink"`
+		// FilePath is the file the entries are appended to by the file sink
+		FilePath string `yaml:"filePath"`
+		// KafkaApplication is the kafka application whose topic the entries are published to by the kafka sink
+		KafkaApplication string `yaml:"kafkaApplication"`
+		// APIs are the names of the audited APIs, e.g. "TerminateWorkflowExecution".
+		// All the APIs which change a workflow or a domain are audited when it's empty.
+		APIs []string `yaml:"apis"`
+		// Retention is how long the entries of the persistence sink are kept, 7 days if it's not set.
+		// The expired entries are deleted every hour by the audit purger of the worker scanner.
+		Retention time.Duration `yaml:"retention"`
+		// BufferSize is the number of entries which wait to be written to the sink, 1000 if it's not set
+		BufferSize int `yaml:"bufferSize"`
+		// FullBufferPolicy is how entries are written when the buffer is full, "fail" if it's not set.
+		// "fail" waits for space in the buffer for at most FullBufferTimeout, then fails the write of the entry,
+		// which is logged. "drop" drops the entry without waiting, dropped entries are counted and logged.
+		FullBufferPolicy string `yaml:"fullBufferPolicy"`
+		// FullBufferTimeout is how long the fail policy waits for space in the buffer, 1 second if it's not set
+		FullBufferTimeout time.Duration `yaml:"fullBufferTimeout"`
+	}
+
+	// FileKeyring contains the config for a file backed keyring
+	FileKeyring struct {
+		// Path is the path of the keyring file
+		Path string `yaml:"path"`
+	}
+
 	// Persistence contains the configuration for data store / persistence layer
 	Persistence struct {
 		// DefaultStore is the name of the default data store to use
@@ -197,6 +250,15 @@ type (
 		// TODO: move dynamic config out of static config
 		// ErrorInjectionRate is the the rate for injecting random error
 		ErrorInjectionRate dynamicconfig.FloatPropertyFn `yaml:"-" json:"-"`
+		// KeyProvider provides the keys which encrypt payloads at rest, payloads are not encrypted if it's nil
+		KeyProvider encryption.KeyProvider `yaml:"-" json:"-"`
+		// BlobstoreClient stores the history event payloads which are larger than PayloadOffloadThreshold,
+		// payloads are not offloaded if it's nil. It must be shared by all hosts, so it can't be the file blobstore.
+		// The server sets it to the object store blobstore when it's configured
+		BlobstoreClient blobstore.Client `yaml:"-" json:"-"`
+		// TODO: move dynamic config out of static config
+		// PayloadOffloadThreshold is the size above which history event payloads are offloaded to the blobstore
+		PayloadOffloadThreshold dynamicconfig.IntPropertyFnWithDomainFilter `yaml:"-" json:"-"`
 	}
 
 	// DataStore is the configuration for a single datastore
@@ -222,7 +284,7 @@ type (
 
 	// NoSQL contains configuration to connect to NoSQL Database cluster
 	NoSQL struct {
-		// PluginName is the name of NoSQL plugin, default is "cassandra". Supported values: cassandra
+		// PluginName is the name of NoSQL plugin, default is "cassandra". Supported values: cassandra, dynamodb, mongodb
 		PluginName string `yaml:"pluginName"`
 		// Hosts is a csv of cassandra endpoints
 		Hosts string `yaml:"hosts" validate:"nonzero"`
@@ -236,7 +298,7 @@ type (
 		AllowedAuthenticators []string `yaml:"allowedAuthenticators"`
 		// Keyspace is the cassandra keyspace
 		Keyspace string `yaml:"keyspace"`
-		// Region is the region filter arg for cassandra
+		// Region is the region filter arg for cassandra, or the AWS region for dynamodb
 		Region string `yaml:"region"`
 		// Datacenter is the data center filter arg for cassandra
 		Datacenter string `yaml:"datacenter"`
@@ -313,6 +375,7 @@ type (
 		// PluginName is the name of SQL plugin
 		PluginName string `yaml:"pluginName" validate:"nonzero"`
 		// DatabaseName is the name of SQL database to connect to
+		// For sqlite, it is the path of the database file, or starts with ":memory:" for an in-memory database
 		// If useMultipleDatabases, must be empty and provide it via multipleDatabasesConfig instead
 		// Required if not useMultipleDatabases
 		DatabaseName string `yaml:"databaseName"`
@@ -337,7 +400,8 @@ type (
 		NumShards int `yaml:"nShards"`
 		// TLS is the configuration for TLS connections
 		TLS *TLS `yaml:"tls"`
-		// EncodingType is the configuration for the type of encoding used for sql blobs
+		// EncodingType is the configuration for the type of encoding used for sql blobs,
+		// thriftrw+zstd and thriftrw+snappy compress the thriftrw encoding
 		EncodingType string `yaml:"encodingType"`
 		// DecodingTypes is the configuration for all the sql blob decoding types which need to be supported
 		// DecodingTypes should not be removed unless there are no blobs in database with the encoding type
@@ -502,6 +566,7 @@ type (
 	// Config keys and structures expected in the main default binary include:
 	//  - FilestoreConfig: [*FilestoreArchiver], used with provider scheme [github.com/uber/cadence/common/archiver/filestore.URIScheme]
 	//  - S3storeConfig: [*S3Archiver], used with provider scheme [github.com/uber/cadence/common/archiver/s3store.URIScheme]
+	//  - ObjectstoreConfig: [*ObjectstoreArchiver], used with provider scheme [github.com/uber/cadence/common/archiver/objectstore.URIScheme]
 	//  - "gstorage" via [github.com/uber/cadence/common/archiver/gcloud.ConfigKey]: [github.com/uber/cadence/common/archiver/gcloud.Config], used with provider scheme "gs" [github.com/uber/cadence/common/archiver/gcloud.URIScheme]
 	//
 	// For handling hardcoded config, see ToYamlNode.
@@ -526,6 +591,7 @@ type (
 	// Config keys and structures expected in the main default binary include:
 	//  - FilestoreConfig: [*FilestoreArchiver], used with provider scheme [github.com/uber/cadence/common/archiver/filestore.URIScheme]
 	//  - S3storeConfig: [*S3Archiver], used with provider scheme [github.com/uber/cadence/common/archiver/s3store.URIScheme]
+	//  - ObjectstoreConfig: [*ObjectstoreArchiver], used with provider scheme [github.com/uber/cadence/common/archiver/objectstore.URIScheme]
 	//  - "gstorage" via [github.com/uber/cadence/common/archiver/gcloud.ConfigKey]: [github.com/uber/cadence/common/archiver/gcloud.Config], used with provider scheme "gs" [github.com/uber/cadence/common/archiver/gcloud.URIScheme]
 	//
 	// For handling hardcoded config, see ToYamlNode.
@@ -544,6 +610,17 @@ type (
 		S3ForcePathStyle bool    `yaml:"s3ForcePathStyle"`
 	}
 
+	// ObjectstoreArchiver contains the config for the archiver of S3-compatible object stores, e.g. MinIO
+	ObjectstoreArchiver struct {
+		// Endpoint is the URL of the object store, e.g. http://127.0.0.1:9000
+		Endpoint string `yaml:"endpoint"`
+		// Region is the region of the object store, us-east-1 is used if it's not set
+		Region string `yaml:"region"`
+		// VirtualHostedStyle addresses the buckets as <bucket>.<endpoint> instead of <endpoint>/<bucket>,
+		// the path style is used by default since most S3-compatible stores only support it
+		VirtualHostedStyle bool `yaml:"virtualHostedStyle"`
+	}
+
 	// PublicClient is config for connecting to cadence frontend
 	PublicClient struct {
 		// HostPort is the host port to connect on. Host can be DNS name
@@ -599,6 +676,14 @@ type (
 	// Config is the configuration for the queue provider.
 	// Config types and structures expected in the main default binary include:
 	// - type: "kafka", config: [*github.com/uber/cadence/common/asyncworkflow/queue/kafka.QueueConfig]]]
+	// - type: "mapq", the queue is stored in the persistence of Cadence so it doesn't depend on an external messaging system.
+	//   Its config has the following fields:
+	//   - name: identifies the queue in the persistence. Queues with the same name share their requests.
+	//   - dispatchRPS: rate limit of each partition of the queue. Requests are not rate limited if it's 0.
+	//   - concurrency: number of requests processed concurrently by each partition of the queue. Defaults to 10.
+	//   - maxAttempts: number of times a request is processed before it's dropped. Defaults to 3.
+	//   - domains: list of domains with dedicated partitions, each with a name and optional dispatchRPS and concurrency overrides.
+	//     Requests of all other domains share a single partition.
 	AsyncWorkflowQueueProvider struct {
 		Type   string    `yaml:"type"`
 		Config *YamlNode `yaml:"config"`
@@ -609,8 +694,21 @@ const (
 	// NonShardedStoreName is the shard name used for singular (non-sharded) stores
 	NonShardedStoreName = "NonShardedStore"
 
-	FilestoreConfig = "filestore"
-	S3storeConfig   = "s3store"
+	FilestoreConfig   = "filestore"
+	S3storeConfig     = "s3store"
+	ObjectstoreConfig = "objectstore"
+
+	// AuditSinkFile appends the audit entries to a file
+	AuditSinkFile = "file"
+	// AuditSinkKafka publishes the audit entries to a kafka topic
+	AuditSinkKafka = "kafka"
+	// AuditSinkPersistence stores the audit entries in the queue table of the default store
+	AuditSinkPersistence = "persistence"
+
+	// AuditFullBufferPolicyFail fails the write of an audit entry if the buffer is still full after a timeout
+	AuditFullBufferPolicyFail = "fail"
+	// AuditFullBufferPolicyDrop drops an audit entry if the buffer is full
+	AuditFullBufferPolicyDrop = "drop"
 )
 
 var _ yaml.Unmarshaler = (*YamlNode)(nil)
@@ -670,6 +768,10 @@ func (c *Config) validate() error {
 		return err
 	}
 
+	if err := c.Audit.Validate(); err != nil {
+		return err
+	}
+
 	return c.Authorization.Validate()
 }
 
@@ -716,3 +818,34 @@ func (c *Config) GetServiceConfig(serviceName string) (Service, error) {
 	}
 	return serviceConfig, nil
 }
+
+// Validate validates the audit config
+func (a *Audit) Validate() error {
+	switch a.Sink {
+	case "", AuditSinkKafka, AuditSinkPersistence:
+	case AuditSinkFile:
+		if a.FilePath == "" {
+			return fmt.Errorf("[AuditConfig] FilePath can't be empty for the file sink")
+		}
+	default:
+		return fmt.Errorf("[AuditConfig] unknown sink %q", a.Sink)
+	}
+	if a.Sink == AuditSinkKafka && a.KafkaApplication == "" {
+		return fmt.Errorf("[AuditConfig] KafkaApplication can't be empty for the kafka sink")
+	}
+	if a.Retention < 0 {
+		return fmt.Errorf("[AuditConfig] Retention can't be negative")
+	}
+	if a.BufferSize < 0 {
+		return fmt.Errorf("[AuditConfig] BufferSize can't be negative")
+	}
+	switch a.FullBufferPolicy {
+	case "", AuditFullBufferPolicyFail, AuditFullBufferPolicyDrop:
+	default:
+		return fmt.Errorf("[AuditConfig] unknown full buffer policy %q", a.FullBufferPolicy)
+	}
+	if a.FullBufferTimeout < 0 {
+		return fmt.Errorf("[AuditConfig] FullBufferTimeout can't be negative")
+	}
+	return nil
+}
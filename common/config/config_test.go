@@ -23,9 +23,11 @@ package config
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/service"
@@ -387,3 +389,54 @@ func TestInvalidShardedNoSQLConfig_TasklistShardingRefersToUnknownConnection(t *
 	err := cfg.ValidateAndFillDefaults()
 	require.ErrorContains(t, err, "Unknown tasklist shard name")
 }
+
+func TestAuditValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg Audit
+		err string
+	}{
+		"disabled":           {cfg: Audit{}},
+		"file":               {cfg: Audit{Sink: AuditSinkFile, FilePath: "audit.log"}},
+		"file without path":  {cfg: Audit{Sink: AuditSinkFile}, err: "[AuditConfig] FilePath can't be empty for the file sink"},
+		"kafka":              {cfg: Audit{Sink: AuditSinkKafka, KafkaApplication: "audit"}},
+		"kafka without app":  {cfg: Audit{Sink: AuditSinkKafka}, err: "[AuditConfig] KafkaApplication can't be empty for the kafka sink"},
+		"persistence":        {cfg: Audit{Sink: AuditSinkPersistence}},
+		"unknown sink":       {cfg: Audit{Sink: "syslog"}, err: `[AuditConfig] unknown sink "syslog"`},
+		"negative retention": {cfg: Audit{Sink: AuditSinkPersistence, Retention: -time.Hour}, err: "[AuditConfig] Retention can't be negative"},
+		"negative buffer":    {cfg: Audit{Sink: AuditSinkPersistence, BufferSize: -1}, err: "[AuditConfig] BufferSize can't be negative"},
+		"drop policy":        {cfg: Audit{Sink: AuditSinkPersistence, FullBufferPolicy: AuditFullBufferPolicyDrop}},
+		"fail policy":        {cfg: Audit{Sink: AuditSinkPersistence, FullBufferPolicy: AuditFullBufferPolicyFail, FullBufferTimeout: time.Second}},
+		"unknown policy":     {cfg: Audit{Sink: AuditSinkPersistence, FullBufferPolicy: "block"}, err: `[AuditConfig] unknown full buffer policy "block"`},
+		"negative timeout":   {cfg: Audit{Sink: AuditSinkPersistence, FullBufferTimeout: -time.Second}, err: "[AuditConfig] FullBufferTimeout can't be negative"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.cfg.Validate()
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestObjectstoreBlobstoreYaml(t *testing.T) {
+	var cfg Blobstore
+	err := yaml.Unmarshal([]byte(`
+objectstore:
+  endpoint: "http://127.0.0.1:9000"
+  region: "eu-west-1"
+  bucket: "cadence"
+  prefix: "payloads"
+`), &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, &ObjectstoreBlobstore{
+		Bucket: "cadence",
+		Prefix: "payloads",
+		ObjectstoreArchiver: ObjectstoreArchiver{
+			Endpoint: "http://127.0.0.1:9000",
+			Region:   "eu-west-1",
+		},
+	}, cfg.Objectstore)
+}
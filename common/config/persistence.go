@@ -138,7 +138,8 @@ func (c *Persistence) Validate() error {
 				if ds.SQL.DatabaseName == "" {
 					return fmt.Errorf("sql persistence config: databaseName can not be empty")
 				}
-				if ds.SQL.ConnectAddr == "" {
+				// sqlite databases are local files, which don't have an address
+				if ds.SQL.ConnectAddr == "" && ds.SQL.PluginName != "sqlite" {
 					return fmt.Errorf("sql persistence config: connectAddr can not be empty")
 				}
 			}
@@ -60,12 +60,14 @@ const (
 
 // Data encoding types
 const (
-	EncodingTypeJSON     EncodingType = "json"
-	EncodingTypeThriftRW EncodingType = "thriftrw"
-	EncodingTypeGob      EncodingType = "gob"
-	EncodingTypeUnknown  EncodingType = "unknow"
-	EncodingTypeEmpty    EncodingType = ""
-	EncodingTypeProto    EncodingType = "proto3"
+	EncodingTypeJSON           EncodingType = "json"
+	EncodingTypeThriftRW       EncodingType = "thriftrw"
+	EncodingTypeThriftRWZstd   EncodingType = "thriftrw+zstd"
+	EncodingTypeThriftRWSnappy EncodingType = "thriftrw+snappy"
+	EncodingTypeGob            EncodingType = "gob"
+	EncodingTypeUnknown        EncodingType = "unknow"
+	EncodingTypeEmpty          EncodingType = ""
+	EncodingTypeProto          EncodingType = "proto3"
 )
 
 type (
@@ -125,6 +125,9 @@ type MapPropertyFn func(opts ...FilterOption) map[string]interface{}
 // StringPropertyFnWithDomainFilter is a wrapper to get string property from dynamic config
 type StringPropertyFnWithDomainFilter func(domain string) string
 
+// StringPropertyFnWithTaskListInfoFilters is a wrapper to get string property from dynamic config with three filters: domain, taskList, taskType
+type StringPropertyFnWithTaskListInfoFilters func(domain string, taskList string, taskType int) string
+
 // BoolPropertyFnWithDomainFilter is a wrapper to get bool property from dynamic config with domain as filter
 type BoolPropertyFnWithDomainFilter func(domain string) bool
 
@@ -447,6 +450,26 @@ func (c *Collection) GetStringPropertyFilteredByDomain(key StringKey) StringProp
 	}
 }
 
+// GetStringPropertyFilteredByTaskListInfo gets property with taskListInfo as filters and asserts that it's a string
+func (c *Collection) GetStringPropertyFilteredByTaskListInfo(key StringKey) StringPropertyFnWithTaskListInfoFilters {
+	return func(domain string, taskList string, taskType int) string {
+		filters := c.toFilterMap(
+			DomainFilter(domain),
+			TaskListFilter(taskList),
+			TaskTypeFilter(taskType),
+		)
+		val, err := c.client.GetStringValue(
+			key,
+			filters,
+		)
+		if err != nil {
+			c.logError(key, filters, err)
+			return key.DefaultString()
+		}
+		return val
+	}
+}
+
 // GetBoolPropertyFilteredByDomain gets property with domain filter and asserts that it's a bool
 func (c *Collection) GetBoolPropertyFilteredByDomain(key BoolKey) BoolPropertyFnWithDomainFilter {
 	return func(domain string) bool {
@@ -64,6 +64,17 @@ func (s *configSuite) TestGetStringProperty() {
 	s.Equal("b", value())
 }
 
+func (s *configSuite) TestGetStringPropertyFilteredByTaskListInfo() {
+	key := TestGetStringPropertyKey
+	domain := "testDomain"
+	taskList := "testTaskList"
+	taskType := 0
+	value := s.cln.GetStringPropertyFilteredByTaskListInfo(key)
+	s.Equal(key.DefaultString(), value(domain, taskList, taskType))
+	s.client.SetValue(key, "b")
+	s.Equal("b", value(domain, taskList, taskType))
+}
+
 func (s *configSuite) TestGetIntProperty() {
 	key := TestGetIntPropertyKey
 	value := s.cln.GetIntProperty(key)
@@ -357,6 +357,18 @@ const (
 	// Default value: 262144 (256*1024)
 	// Allowed filters: DomainName
 	BlobSizeLimitWarn
+	// OffloadedBlobSizeLimitError is the per event blob size limit when large payloads are offloaded to the blobstore
+	// KeyName: limit.offloadedBlobSize.error
+	// Value type: Int
+	// Default value: 3145728 (3*1024*1024), it must stay below the RPC message size limit and TransactionSizeLimit
+	// Allowed filters: DomainName
+	OffloadedBlobSizeLimitError
+	// PayloadOffloadThreshold is the size above which history event payloads are stored in the blobstore instead of the history event
+	// KeyName: history.payloadOffloadThreshold
+	// Value type: Int
+	// Default value: 0 (payloads are not offloaded)
+	// Allowed filters: DomainName
+	PayloadOffloadThreshold
 	// HistorySizeLimitError is the per workflow execution history size limit
 	// KeyName: limit.historySize.error
 	// Value type: Int
@@ -670,6 +682,20 @@ const (
 	// Default value: 10 (see domain.MaxBadBinaries)
 	// Allowed filters: DomainName
 	FrontendMaxBadBinaries
+	// FrontendMinClientTaskPriority is the lowest task priority clients can set with the task priority header,
+	// lower priorities are raised to it
+	// KeyName: frontend.minClientTaskPriority
+	// Value type: Int
+	// Default value: -10
+	// Allowed filters: DomainName
+	FrontendMinClientTaskPriority
+	// FrontendMaxClientTaskPriority is the highest task priority clients can set with the task priority header,
+	// higher priorities are lowered to it
+	// KeyName: frontend.maxClientTaskPriority
+	// Value type: Int
+	// Default value: 10
+	// Allowed filters: DomainName
+	FrontendMaxClientTaskPriority
 	// SearchAttributesNumberOfKeysLimit is the limit of number of keys
 	// KeyName: frontend.searchAttributesNumberOfKeysLimit
 	// Value type: Int
@@ -811,6 +837,33 @@ const (
 	// Default value: 20
 	// Allowed filters: DomainName,TasklistName,TasklistType
 	MatchingForwarderMaxChildrenPerNode
+	// MatchingPartitionUpscaleRPS is the add task rate per write partition above which the adaptive scaler adds partitions
+	// KeyName: matching.partitionUpscaleRPS
+	// Value type: Int
+	// Default value: 200
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingPartitionUpscaleRPS
+	// MatchingPartitionDownscaleRPS is the add task rate per write partition below which the adaptive scaler removes partitions,
+	// it should be smaller than MatchingPartitionUpscaleRPS to avoid scaling up and down repeatedly
+	// KeyName: matching.partitionDownscaleRPS
+	// Value type: Int
+	// Default value: 150
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingPartitionDownscaleRPS
+	// MatchingAdaptiveScalerMaxPartitions is the max number of partitions the adaptive scaler can scale a task list to
+	// KeyName: matching.adaptiveScalerMaxPartitions
+	// Value type: Int
+	// Default value: 10
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingAdaptiveScalerMaxPartitions
+	// MatchingTaskListPurgeCreatedBefore is the unix timestamp in seconds before which the backlog tasks of a tasklist are purged,
+	// it is combined with MatchingTaskListPurgeWorkflowType when both are set, 0 means no purge by age.
+	// The admin tasklist purge command sets it to the time of the request and clears it once the purge is completed
+	// KeyName: matching.taskListPurgeCreatedBefore
+	// Value type: Int
+	// Default value: 0
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingTaskListPurgeCreatedBefore
 
 	// key for history
 
@@ -898,6 +951,20 @@ const (
 	// Default value: 1000
 	// Allowed filters: DomainName
 	TaskProcessRPS
+	// QueueProcessorIsolatedDomainRPS is the task processing rate per second on each history host for a domain
+	// which is isolated by history.queueProcessorIsolatedDomains, 0 means no limit
+	// KeyName: history.queueProcessorIsolatedDomainRPS
+	// Value type: Int
+	// Default value: 0
+	// Allowed filters: DomainName
+	QueueProcessorIsolatedDomainRPS
+	// TaskSchedulerIsolatedDomainWeight is the weight of the dedicated channel of an isolated domain
+	// in weighted round robin task scheduler
+	// KeyName: history.taskSchedulerIsolatedDomainWeight
+	// Value type: Int
+	// Default value: 1
+	// Allowed filters: DomainName
+	TaskSchedulerIsolatedDomainWeight
 	// TaskSchedulerType is the task scheduler type for priority task processor
 	// KeyName: history.taskSchedulerType
 	// Value type: Int enum(1 for SchedulerTypeFIFO, 2 for SchedulerTypeWRR(weighted round robin scheduler implementation))
@@ -1651,6 +1718,13 @@ const (
 	// Default value: false
 	// Allowed filters: DomainName
 	FrontendEmitSignalNameMetricsTag
+	// FrontendEnableClientTaskPriority is to accept the task priority and fairness headers of clients for the workflows of a domain,
+	// the headers are ignored for domains where it's disabled
+	// KeyName: frontend.enableClientTaskPriority
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: DomainName
+	FrontendEnableClientTaskPriority
 	// EnableQueryAttributeValidation enables validation of queries' search attributes against the dynamic config whitelist
 	// Keyname: frontend.enableQueryAttributeValidation
 	// Value type: Bool
@@ -1672,6 +1746,25 @@ const (
 	// Default value: false
 	// Allowed filters: DomainID
 	MatchingEnableTaskInfoLogByDomainID
+	// MatchingEnableTaskPriority is to enable dispatching the buffered tasks of a tasklist by priority and fairness key
+	// KeyName: matching.enableTaskPriority
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingEnableTaskPriority
+	// MatchingEnableAdaptiveScaler is to enable scaling the number of partitions of a tasklist by its traffic,
+	// it replaces the static values of matching.numTasklistWritePartitions and matching.numTasklistReadPartitions
+	// KeyName: matching.enableAdaptiveScaler
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingEnableAdaptiveScaler
+	// MatchingTaskListPaused is to pause dispatching tasks of a tasklist, new tasks are still accepted and written to the backlog
+	// KeyName: matching.taskListPaused
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingTaskListPaused
 
 	// key for history
 
@@ -1870,6 +1963,12 @@ const (
 	// Default value: false
 	// Allowed filters: N/A
 	HistoryScannerEnabled
+	// HistoryReencryptionEnabled indicates if history re-encryption scanner should be started as part of worker.Scanner
+	// KeyName: worker.historyReencryptionEnabled
+	// Value type: Bool
+	// Default value: false
+	// Allowed filters: N/A
+	HistoryReencryptionEnabled
 	// ConcreteExecutionsScannerEnabled indicates if executions scanner should be started as part of worker.Scanner
 	// KeyName: worker.executionsScannerEnabled
 	// Value type: Bool
@@ -1967,6 +2066,24 @@ const (
 	// Default value: true
 	// Allowed filters: N/A
 	EnableFailoverManager
+	// EnableScheduler indicates if the worker of schedule workflows is enabled
+	// KeyName: system.enableScheduler
+	// Value type: Bool
+	// Default value: true
+	// Allowed filters: N/A
+	EnableScheduler
+	// EnableDomainDeleter indicates if the worker of domain deletion workflows is enabled
+	// KeyName: system.enableDomainDeleter
+	// Value type: Bool
+	// Default value: true
+	// Allowed filters: N/A
+	EnableDomainDeleter
+	// EnableHistoryExporter indicates if the worker of history export workflows is enabled
+	// KeyName: system.enableHistoryExporter
+	// Value type: Bool
+	// Default value: true
+	// Allowed filters: N/A
+	EnableHistoryExporter
 	// ConcreteExecutionFixerDomainAllow is which domains are allowed to be fixed by concrete fixer workflow
 	// KeyName: worker.concreteExecutionFixerDomainAllow
 	// Value type: Bool
@@ -2330,9 +2447,10 @@ const (
 	// Default value: "enabled"
 	// Allowed filters: N/A
 	VisibilityArchivalStatus
-	// DefaultEventEncoding is the encoding type for history events
+	// DefaultEventEncoding is the encoding type for history events and the blobs of mutable states.
+	// "thriftrw+zstd" and "thriftrw+snappy" compress the thriftrw encoded blobs
 	// KeyName: history.defaultEventEncoding
-	// Value type: String
+	// Value type: String enum: "thriftrw", "thriftrw+zstd", "thriftrw+snappy" or "json"
 	// Default value: string(common.EncodingTypeThriftRW)
 	// Allowed filters: DomainName
 	DefaultEventEncoding
@@ -2368,6 +2486,14 @@ const (
 	// Default value: ""
 	ESAnalyzerWorkflowTypeMetricDomains
 
+	// MatchingTaskListPurgeWorkflowType is the workflow type whose backlog tasks of a tasklist are purged,
+	// it is combined with MatchingTaskListPurgeCreatedBefore when both are set, empty means no purge by workflow type
+	// KeyName: matching.taskListPurgeWorkflowType
+	// Value type: String
+	// Default value: ""
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingTaskListPurgeWorkflowType
+
 	// FrontendGlobalRatelimiterMode controls what keys use global vs fallback behavior,
 	// and whether shadowing is enabled.  This is only available for frontend usage for now.
 	//
@@ -2461,6 +2587,24 @@ const (
 	// Default value: 100ms
 	// Allowed filters: DomainName
 	MatchingActivityTaskSyncMatchWaitTime
+	// MatchingAdaptiveScalerUpdateInterval is the interval at which the adaptive scaler evaluates the traffic of a tasklist
+	// KeyName: matching.adaptiveScalerUpdateInterval
+	// Value type: Duration
+	// Default value: 15s (15*time.Second)
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingAdaptiveScalerUpdateInterval
+	// MatchingPartitionUpscaleSustainedDuration is how long the traffic has to stay above the upscale threshold before partitions are added
+	// KeyName: matching.partitionUpscaleSustainedDuration
+	// Value type: Duration
+	// Default value: 1m (1*time.Minute)
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingPartitionUpscaleSustainedDuration
+	// MatchingPartitionDownscaleSustainedDuration is how long the traffic has to stay below the downscale threshold before partitions are removed
+	// KeyName: matching.partitionDownscaleSustainedDuration
+	// Value type: Duration
+	// Default value: 2m (2*time.Minute)
+	// Allowed filters: DomainName,TasklistName,TasklistType
+	MatchingPartitionDownscaleSustainedDuration
 
 	// HistoryLongPollExpirationInterval is the long poll expiration interval in the history service
 	// KeyName: history.longPollExpirationInterval
@@ -2900,6 +3044,13 @@ const (
 	// Default value: see common.ConvertIntMapToDynamicConfigMapProperty(DefaultStuckTaskSplitThreshold) in code base
 	// Allowed filters: N/A
 	QueueProcessorStuckTaskSplitThreshold
+	// QueueProcessorIsolatedDomains assigns domains to dedicated virtual queues in history timer and transfer queue processors.
+	// The map is from domain name to a positive virtual queue ID, domains with the same ID share the same virtual queue
+	// KeyName: history.queueProcessorIsolatedDomains
+	// Value type: Map
+	// Default value: empty map
+	// Allowed filters: N/A
+	QueueProcessorIsolatedDomains
 
 	// LastMapKey must be the last one in this const group
 	LastMapKey
@@ -2992,6 +3143,18 @@ var IntKeys = map[IntKey]DynamicInt{
 		Description:  "BlobSizeLimitWarn is the per event blob size limit for warning",
 		DefaultValue: 256 * 1024,
 	},
+	OffloadedBlobSizeLimitError: {
+		KeyName:      "limit.offloadedBlobSize.error",
+		Filters:      []Filter{DomainName},
+		Description:  "OffloadedBlobSizeLimitError is the per event blob size limit when large payloads are offloaded to the blobstore",
+		DefaultValue: 3 * 1024 * 1024,
+	},
+	PayloadOffloadThreshold: {
+		KeyName:      "history.payloadOffloadThreshold",
+		Filters:      []Filter{DomainName},
+		Description:  "PayloadOffloadThreshold is the size above which history event payloads are stored in the blobstore instead of the history event, 0 disables offloading",
+		DefaultValue: 0,
+	},
 	HistorySizeLimitError: {
 		KeyName:      "limit.historySize.error",
 		Filters:      []Filter{DomainName},
@@ -3226,6 +3389,18 @@ var IntKeys = map[IntKey]DynamicInt{
 		Description:  "FrontendMaxBadBinaries is the max number of bad binaries in domain config",
 		DefaultValue: 10,
 	},
+	FrontendMinClientTaskPriority: {
+		KeyName:      "frontend.minClientTaskPriority",
+		Filters:      []Filter{DomainName},
+		Description:  "FrontendMinClientTaskPriority is the lowest task priority clients can set with the task priority header, lower priorities are raised to it",
+		DefaultValue: -10,
+	},
+	FrontendMaxClientTaskPriority: {
+		KeyName:      "frontend.maxClientTaskPriority",
+		Filters:      []Filter{DomainName},
+		Description:  "FrontendMaxClientTaskPriority is the highest task priority clients can set with the task priority header, higher priorities are lowered to it",
+		DefaultValue: 10,
+	},
 	SearchAttributesNumberOfKeysLimit: {
 		KeyName:      "frontend.searchAttributesNumberOfKeysLimit",
 		Filters:      []Filter{DomainName},
@@ -3356,6 +3531,30 @@ var IntKeys = map[IntKey]DynamicInt{
 		Description:  "MatchingForwarderMaxChildrenPerNode is the max number of children per node in the task list partition tree",
 		DefaultValue: 20,
 	},
+	MatchingPartitionUpscaleRPS: {
+		KeyName:      "matching.partitionUpscaleRPS",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingPartitionUpscaleRPS is the add task rate per write partition above which the adaptive scaler adds partitions",
+		DefaultValue: 200,
+	},
+	MatchingPartitionDownscaleRPS: {
+		KeyName:      "matching.partitionDownscaleRPS",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingPartitionDownscaleRPS is the add task rate per write partition below which the adaptive scaler removes partitions",
+		DefaultValue: 150,
+	},
+	MatchingAdaptiveScalerMaxPartitions: {
+		KeyName:      "matching.adaptiveScalerMaxPartitions",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingAdaptiveScalerMaxPartitions is the max number of partitions the adaptive scaler can scale a task list to",
+		DefaultValue: 10,
+	},
+	MatchingTaskListPurgeCreatedBefore: {
+		KeyName:      "matching.taskListPurgeCreatedBefore",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingTaskListPurgeCreatedBefore is the unix timestamp in seconds before which the backlog tasks of a tasklist are purged",
+		DefaultValue: 0,
+	},
 	HistoryRPS: {
 		KeyName:      "history.rps",
 		Description:  "HistoryRPS is request rate per second for each history host",
@@ -3429,6 +3628,18 @@ var IntKeys = map[IntKey]DynamicInt{
 		Description:  "TaskProcessRPS is the task processing rate per second for each domain",
 		DefaultValue: 1000,
 	},
+	QueueProcessorIsolatedDomainRPS: {
+		KeyName:      "history.queueProcessorIsolatedDomainRPS",
+		Filters:      []Filter{DomainName},
+		Description:  "QueueProcessorIsolatedDomainRPS is the task processing rate per second on each history host for a domain which is isolated by history.queueProcessorIsolatedDomains, 0 means no limit",
+		DefaultValue: 0,
+	},
+	TaskSchedulerIsolatedDomainWeight: {
+		KeyName:      "history.taskSchedulerIsolatedDomainWeight",
+		Filters:      []Filter{DomainName},
+		Description:  "TaskSchedulerIsolatedDomainWeight is the weight of the dedicated channel of an isolated domain in weighted round robin task scheduler",
+		DefaultValue: 1,
+	},
 	TaskSchedulerType: {
 		KeyName:      "history.taskSchedulerType",
 		Description:  "TaskSchedulerType is the task scheduler type for priority task processor",
@@ -4085,6 +4296,12 @@ var BoolKeys = map[BoolKey]DynamicBool{
 		Description:  "FrontendEmitSignalNameMetricsTag enables emitting signal name tag in metrics in frontend client",
 		DefaultValue: false,
 	},
+	FrontendEnableClientTaskPriority: {
+		KeyName:      "frontend.enableClientTaskPriority",
+		Filters:      []Filter{DomainName},
+		Description:  "FrontendEnableClientTaskPriority is to accept the task priority and fairness headers of clients for the workflows of a domain",
+		DefaultValue: false,
+	},
 	EnableQueryAttributeValidation: {
 		KeyName:      "frontend.enableQueryAttributeValidation",
 		Description:  "EnableQueryAttributeValidation enables validation of queries' search attributes against the dynamic config whitelist",
@@ -4102,6 +4319,24 @@ var BoolKeys = map[BoolKey]DynamicBool{
 		Description:  "MatchingEnableTaskInfoLogByDomainID is enables info level logs for decision/activity task based on the request domainID",
 		DefaultValue: false,
 	},
+	MatchingEnableTaskPriority: {
+		KeyName:      "matching.enableTaskPriority",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingEnableTaskPriority is to enable dispatching the buffered tasks of a tasklist by priority and fairness key",
+		DefaultValue: false,
+	},
+	MatchingEnableAdaptiveScaler: {
+		KeyName:      "matching.enableAdaptiveScaler",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingEnableAdaptiveScaler is to enable scaling the number of partitions of a tasklist by its traffic",
+		DefaultValue: false,
+	},
+	MatchingTaskListPaused: {
+		KeyName:      "matching.taskListPaused",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingTaskListPaused is to pause dispatching tasks of a tasklist, new tasks are still accepted and written to the backlog",
+		DefaultValue: false,
+	},
 	EventsCacheGlobalEnable: {
 		KeyName:      "history.eventsCacheGlobalEnable",
 		Description:  "EventsCacheGlobalEnable is enables global cache over all history shards",
@@ -4249,6 +4484,11 @@ var BoolKeys = map[BoolKey]DynamicBool{
 		Description:  "HistoryScannerEnabled indicates if history scanner should be started as part of worker.Scanner",
 		DefaultValue: false,
 	},
+	HistoryReencryptionEnabled: {
+		KeyName:      "worker.historyReencryptionEnabled",
+		Description:  "HistoryReencryptionEnabled indicates if history re-encryption scanner should be started as part of worker.Scanner",
+		DefaultValue: false,
+	},
 	ConcreteExecutionsScannerEnabled: {
 		KeyName:      "worker.executionsScannerEnabled",
 		Description:  "ConcreteExecutionsScannerEnabled indicates if executions scanner should be started as part of worker.Scanner",
@@ -4330,6 +4570,21 @@ var BoolKeys = map[BoolKey]DynamicBool{
 		Description:  "EnableFailoverManager indicates if failover manager is enabled",
 		DefaultValue: true,
 	},
+	EnableScheduler: {
+		KeyName:      "system.enableScheduler",
+		Description:  "EnableScheduler indicates if the worker of schedule workflows is enabled",
+		DefaultValue: true,
+	},
+	EnableDomainDeleter: {
+		KeyName:      "system.enableDomainDeleter",
+		Description:  "EnableDomainDeleter indicates if the worker of domain deletion workflows is enabled",
+		DefaultValue: true,
+	},
+	EnableHistoryExporter: {
+		KeyName:      "system.enableHistoryExporter",
+		Description:  "EnableHistoryExporter indicates if the worker of history export workflows is enabled",
+		DefaultValue: true,
+	},
 	ConcreteExecutionFixerDomainAllow: {
 		KeyName:      "worker.concreteExecutionFixerDomainAllow",
 		Filters:      []Filter{DomainName},
@@ -4668,7 +4923,7 @@ var StringKeys = map[StringKey]DynamicString{
 	DefaultEventEncoding: {
 		KeyName:      "history.defaultEventEncoding",
 		Filters:      []Filter{DomainName},
-		Description:  "DefaultEventEncoding is the encoding type for history events",
+		Description:  "DefaultEventEncoding is the encoding type for history events and the blobs of mutable states. thriftrw+zstd and thriftrw+snappy compress the thriftrw encoded blobs",
 		DefaultValue: string(common.EncodingTypeThriftRW),
 	},
 	AdminOperationToken: {
@@ -4701,6 +4956,12 @@ var StringKeys = map[StringKey]DynamicString{
 		Description:  "ESAnalyzerWorkflowDurationWarnThresholds defines the domains we want to emit wf version metrics on",
 		DefaultValue: "",
 	},
+	MatchingTaskListPurgeWorkflowType: {
+		KeyName:      "matching.taskListPurgeWorkflowType",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingTaskListPurgeWorkflowType is the workflow type whose backlog tasks of a tasklist are purged",
+		DefaultValue: "",
+	},
 	FrontendGlobalRatelimiterMode: {
 		KeyName:      "frontend.globalRatelimiterMode",
 		Description:  "FrontendGlobalRatelimiterMode defines which mode a global key should be in, per key, to make gradual changes to ratelimiter algorithms",
@@ -4799,6 +5060,24 @@ var DurationKeys = map[DurationKey]DynamicDuration{
 		Description:  "MatchingActivityTaskSyncMatchWaitTime is the amount of time activity task will wait to be sync matched",
 		DefaultValue: time.Millisecond * 50,
 	},
+	MatchingAdaptiveScalerUpdateInterval: {
+		KeyName:      "matching.adaptiveScalerUpdateInterval",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingAdaptiveScalerUpdateInterval is the interval at which the adaptive scaler evaluates the traffic of a tasklist",
+		DefaultValue: time.Second * 15,
+	},
+	MatchingPartitionUpscaleSustainedDuration: {
+		KeyName:      "matching.partitionUpscaleSustainedDuration",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingPartitionUpscaleSustainedDuration is how long the traffic has to stay above the upscale threshold before partitions are added",
+		DefaultValue: time.Minute,
+	},
+	MatchingPartitionDownscaleSustainedDuration: {
+		KeyName:      "matching.partitionDownscaleSustainedDuration",
+		Filters:      []Filter{DomainName, TaskListName, TaskType},
+		Description:  "MatchingPartitionDownscaleSustainedDuration is how long the traffic has to stay below the downscale threshold before partitions are removed",
+		DefaultValue: time.Minute * 2,
+	},
 	HistoryLongPollExpirationInterval: {
 		KeyName:      "history.longPollExpirationInterval",
 		Filters:      []Filter{DomainName},
@@ -5172,6 +5451,11 @@ var MapKeys = map[MapKey]DynamicMap{
 		Description:  "QueueProcessorStuckTaskSplitThreshold is the threshold for the number of attempts of a task",
 		DefaultValue: common.ConvertIntMapToDynamicConfigMapProperty(map[int]int{0: 100, 1: 10000}),
 	},
+	QueueProcessorIsolatedDomains: {
+		KeyName:      "history.queueProcessorIsolatedDomains",
+		Description:  "QueueProcessorIsolatedDomains assigns domains to dedicated virtual queues in history timer and transfer queue processors. The map is from domain name to a positive virtual queue ID, domains with the same ID share the same virtual queue",
+		DefaultValue: map[string]interface{}{},
+	},
 }
 
 var ListKeys = map[ListKey]DynamicList{
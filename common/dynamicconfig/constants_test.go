@@ -243,7 +243,7 @@ func (s *constantSuite) TestStringKey() {
 			Key:          DefaultEventEncoding,
 			KeyName:      "history.defaultEventEncoding",
 			Filters:      []Filter{DomainName},
-			Description:  "DefaultEventEncoding is the encoding type for history events",
+			Description:  "DefaultEventEncoding is the encoding type for history events and the blobs of mutable states. thriftrw+zstd and thriftrw+snappy compress the thriftrw encoded blobs",
 			DefaultValue: string(common.EncodingTypeThriftRW),
 		},
 	}
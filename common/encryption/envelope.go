@@ -0,0 +1,190 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// An encrypted payload is an envelope of
+//
+//	magic | version | key ID length | key ID | wrapped data key | nonce | ciphertext
+//
+// the data key is a random AES-256 key encrypting the payload, wrapped with AES-256-GCM by the key of the key ID.
+// The header before the nonce is authenticated as additional data of the ciphertext.
+const (
+	envelopeVersion = byte(1)
+	dataKeySize     = 32
+	nonceSize       = 12
+	tagSize         = 16
+	wrappedKeySize  = nonceSize + dataKeySize + tagSize
+	maxKeyIDLength  = 255
+)
+
+// the magic starts with a zero byte, so it can't be confused with JSON or text payloads
+var envelopeMagic = []byte("\x00CENC")
+
+var errMalformedEnvelope = errors.New("malformed encrypted payload")
+
+// IsEncrypted returns true if the data is a payload encrypted by Encrypt
+func IsEncrypted(data []byte) bool {
+	return len(data) > len(envelopeMagic) && bytes.HasPrefix(data, envelopeMagic)
+}
+
+// KeyID returns the ID of the key which encrypted the payload
+func KeyID(data []byte) (string, error) {
+	keyID, _, err := parseHeader(data)
+	return keyID, err
+}
+
+// Encrypt encrypts the plaintext with a new data key wrapped by the key of the key ID
+func Encrypt(provider KeyProvider, keyID string, plaintext []byte) ([]byte, error) {
+	if provider == nil {
+		return nil, ErrNoKeyProvider
+	}
+	if len(keyID) == 0 || len(keyID) > maxKeyIDLength {
+		return nil, fmt.Errorf("invalid encryption key ID %q", keyID)
+	}
+	key, err := provider.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	keyAEAD, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(envelopeMagic)+2+len(keyID)+wrappedKeySize)
+	header = append(header, envelopeMagic...)
+	header = append(header, envelopeVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	header, err = seal(keyAEAD, header, dataKey, append([]byte(nil), header...))
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := make([]byte, 0, len(header)+nonceSize+len(plaintext)+tagSize)
+	envelope = append(envelope, header...)
+	return seal(dataAEAD, envelope, plaintext, header)
+}
+
+// Decrypt decrypts a payload encrypted by Encrypt
+func Decrypt(provider KeyProvider, data []byte) ([]byte, error) {
+	if provider == nil {
+		return nil, ErrNoKeyProvider
+	}
+	keyID, keyIDEnd, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < keyIDEnd+wrappedKeySize+nonceSize+tagSize {
+		return nil, errMalformedEnvelope
+	}
+	key, err := provider.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	keyAEAD, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerEnd := keyIDEnd + wrappedKeySize
+	dataKey, err := open(keyAEAD, data[keyIDEnd:headerEnd], data[:keyIDEnd])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with encryption key %q: %v", keyID, err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataAEAD, data[headerEnd:], data[:headerEnd])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload encrypted with key %q: %v", keyID, err)
+	}
+	return plaintext, nil
+}
+
+// parseHeader returns the key ID and the offset of the end of the key ID
+func parseHeader(data []byte) (string, int, error) {
+	if !IsEncrypted(data) {
+		return "", 0, errMalformedEnvelope
+	}
+	offset := len(envelopeMagic)
+	if len(data) < offset+2 {
+		return "", 0, errMalformedEnvelope
+	}
+	if data[offset] != envelopeVersion {
+		return "", 0, fmt.Errorf("unsupported encrypted payload version %v", data[offset])
+	}
+	keyIDLength := int(data[offset+1])
+	offset += 2
+	if keyIDLength == 0 || len(data) < offset+keyIDLength {
+		return "", 0, errMalformedEnvelope
+	}
+	return string(data[offset : offset+keyIDLength]), offset + keyIDLength, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("encryption key must be %v bytes, got %v bytes", dataKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal appends a random nonce and the sealed plaintext to dst
+func seal(aead cipher.AEAD, dst []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+// open opens data sealed by seal
+func open(aead cipher.AEAD, sealed []byte, additionalData []byte) ([]byte, error) {
+	if len(sealed) < nonceSize+tagSize {
+		return nil, errMalformedEnvelope
+	}
+	return aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+}
@@ -0,0 +1,125 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyProvider(t *testing.T) *MockKeyProvider {
+	keys := map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+		"key-2": bytes.Repeat([]byte{2}, 32),
+		"short": bytes.Repeat([]byte{3}, 16),
+	}
+	provider := NewMockKeyProvider(gomock.NewController(t))
+	provider.EXPECT().Key(gomock.Any()).DoAndReturn(func(keyID string) ([]byte, error) {
+		if key, ok := keys[keyID]; ok {
+			return key, nil
+		}
+		return nil, ErrKeyNotFound
+	}).AnyTimes()
+	return provider
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	provider := newTestKeyProvider(t)
+	plaintext := []byte(`{"input":"secret"}`)
+
+	encrypted, err := Encrypt(provider, "key-1", plaintext)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "secret")
+	keyID, err := KeyID(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", keyID)
+
+	decrypted, err := Decrypt(provider, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// every payload has its own data key and nonce
+	encryptedAgain, err := Encrypt(provider, "key-1", plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, encryptedAgain)
+
+	empty, err := Encrypt(provider, "key-2", nil)
+	require.NoError(t, err)
+	decrypted, err = Decrypt(provider, empty)
+	require.NoError(t, err)
+	assert.Empty(t, decrypted)
+}
+
+func TestEncrypt_Errors(t *testing.T) {
+	provider := newTestKeyProvider(t)
+
+	_, err := Encrypt(nil, "key-1", []byte("data"))
+	assert.Equal(t, ErrNoKeyProvider, err)
+	_, err = Encrypt(provider, "", []byte("data"))
+	assert.Error(t, err)
+	_, err = Encrypt(provider, "unknown", []byte("data"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = Encrypt(provider, "short", []byte("data"))
+	assert.Error(t, err)
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	provider := newTestKeyProvider(t)
+	encrypted, err := Encrypt(provider, "key-1", []byte("data"))
+	require.NoError(t, err)
+
+	_, err = Decrypt(nil, encrypted)
+	assert.Equal(t, ErrNoKeyProvider, err)
+	_, err = Decrypt(provider, []byte("data"))
+	assert.Error(t, err)
+	_, err = Decrypt(provider, encrypted[:len(encrypted)-1])
+	assert.Error(t, err)
+	_, err = Decrypt(provider, encrypted[:len(envelopeMagic)+4])
+	assert.Error(t, err)
+
+	// the key ID is authenticated
+	tampered := append([]byte(nil), encrypted...)
+	copy(tampered[len(envelopeMagic)+2:], "key-2")
+	_, err = Decrypt(provider, tampered)
+	assert.Error(t, err)
+
+	tampered = append([]byte(nil), encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Decrypt(provider, tampered)
+	assert.Error(t, err)
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.False(t, IsEncrypted(nil))
+	assert.False(t, IsEncrypted([]byte(`{"key":"value"}`)))
+	assert.False(t, IsEncrypted(envelopeMagic))
+	assert.True(t, IsEncrypted(append(envelopeMagic, envelopeVersion)))
+
+	_, err := KeyID([]byte(`{"key":"value"}`))
+	assert.Error(t, err)
+}
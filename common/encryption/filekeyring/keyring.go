@@ -0,0 +1,124 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package filekeyring
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/encryption"
+)
+
+type (
+	// keyring is the content of a keyring file, e.g.
+	//
+	//	keys:
+	//	  key-2021: <base64 encoded 32 bytes key>
+	//	  key-2022: <base64 encoded 32 bytes key>
+	//	domains:
+	//	  samples-domain: key-2022
+	//	defaultKey: key-2021
+	//
+	// payloads of the domains which are not listed are encrypted with the default key,
+	// or not encrypted if there is no default key
+	keyring struct {
+		Keys       map[string]string `yaml:"keys"`
+		Domains    map[string]string `yaml:"domains"`
+		DefaultKey string            `yaml:"defaultKey"`
+	}
+
+	keyProvider struct {
+		keys         map[string][]byte
+		domains      map[string]string
+		defaultKeyID string
+	}
+)
+
+// NewKeyProvider constructs a key provider backed by a keyring file
+func NewKeyProvider(cfg *config.FileKeyring) (encryption.KeyProvider, error) {
+	if cfg == nil {
+		return nil, errors.New("file keyring config is nil")
+	}
+	if len(cfg.Path) == 0 {
+		return nil, errors.New("path not given for file keyring")
+	}
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyProvider(data)
+}
+
+func newKeyProvider(data []byte) (*keyProvider, error) {
+	var ring keyring
+	if err := yaml.UnmarshalStrict(data, &ring); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring: %v", err)
+	}
+
+	provider := &keyProvider{
+		keys:         make(map[string][]byte, len(ring.Keys)),
+		domains:      ring.Domains,
+		defaultKeyID: ring.DefaultKey,
+	}
+	for keyID, encodedKey := range ring.Keys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %q: %v", keyID, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, got %v bytes", keyID, len(key))
+		}
+		provider.keys[keyID] = key
+	}
+	if _, ok := provider.keys[ring.DefaultKey]; len(ring.DefaultKey) > 0 && !ok {
+		return nil, fmt.Errorf("default key %q is not in the keyring", ring.DefaultKey)
+	}
+	for domainName, keyID := range ring.Domains {
+		if _, ok := provider.keys[keyID]; len(keyID) > 0 && !ok {
+			return nil, fmt.Errorf("key %q of domain %q is not in the keyring", keyID, domainName)
+		}
+	}
+	return provider, nil
+}
+
+// ActiveKeyID returns the ID of the key which encrypts new payloads of the domain
+func (p *keyProvider) ActiveKeyID(domainName string) string {
+	if keyID, ok := p.domains[domainName]; ok {
+		return keyID
+	}
+	return p.defaultKeyID
+}
+
+// Key returns the key of the ID
+func (p *keyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", encryption.ErrKeyNotFound, keyID)
+	}
+	return key, nil
+}
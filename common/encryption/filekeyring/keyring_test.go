@@ -0,0 +1,98 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package filekeyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/encryption"
+)
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestNewKeyProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+keys:
+  key-1: `+base64.StdEncoding.EncodeToString(key1)+`
+  key-2: `+base64.StdEncoding.EncodeToString(key2)+`
+domains:
+  rotated-domain: key-2
+  plaintext-domain: ""
+defaultKey: key-1
+`), 0600))
+
+	provider, err := NewKeyProvider(&config.FileKeyring{Path: path})
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", provider.ActiveKeyID("rotated-domain"))
+	assert.Equal(t, "", provider.ActiveKeyID("plaintext-domain"))
+	assert.Equal(t, "key-1", provider.ActiveKeyID("other-domain"))
+
+	key, err := provider.Key("key-1")
+	require.NoError(t, err)
+	assert.Equal(t, key1, key)
+	_, err = provider.Key("key-3")
+	assert.ErrorIs(t, err, encryption.ErrKeyNotFound)
+
+	encrypted, err := encryption.Encrypt(provider, provider.ActiveKeyID("rotated-domain"), []byte("payload"))
+	require.NoError(t, err)
+	decrypted, err := encryption.Decrypt(provider, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), decrypted)
+}
+
+func TestNewKeyProvider_InvalidConfig(t *testing.T) {
+	_, err := NewKeyProvider(nil)
+	assert.Error(t, err)
+	_, err = NewKeyProvider(&config.FileKeyring{})
+	assert.Error(t, err)
+	_, err = NewKeyProvider(&config.FileKeyring{Path: filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err)
+}
+
+func TestNewKeyProvider_InvalidKeyring(t *testing.T) {
+	encodedKey := base64.StdEncoding.EncodeToString(key1)
+	for name, keyring := range map[string]string{
+		"unknown field":      "key: " + encodedKey,
+		"not base64":         "keys: {key-1: '!'}",
+		"wrong key size":     "keys: {key-1: " + base64.StdEncoding.EncodeToString(key1[:16]) + "}",
+		"unknown default":    "keys: {key-1: " + encodedKey + "}\ndefaultKey: key-2",
+		"unknown domain key": "keys: {key-1: " + encodedKey + "}\ndomains: {samples-domain: key-2}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newKeyProvider([]byte(keyring))
+			assert.Error(t, err)
+		})
+	}
+}
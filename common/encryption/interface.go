@@ -0,0 +1,47 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package encryption
+
+import "errors"
+
+//go:generate mockgen -package $GOPACKAGE -source $GOFILE -destination interface_mock.go -self_package github.com/uber/cadence/common/encryption
+
+type (
+	// KeyProvider provides the keys which encrypt payloads at rest.
+	// Payloads are encrypted with a random data key, which is wrapped by a key of the provider,
+	// keys must be kept by the provider as long as payloads encrypted with them are retained.
+	KeyProvider interface {
+		// ActiveKeyID returns the ID of the key which encrypts new payloads of the domain,
+		// payloads of the domain are not encrypted if the ID is empty
+		ActiveKeyID(domainName string) string
+		// Key returns the key of the ID, the key must be 32 bytes for AES-256
+		Key(keyID string) ([]byte, error)
+	}
+)
+
+var (
+	// ErrKeyNotFound is returned by a KeyProvider if it doesn't have the key
+	ErrKeyNotFound = errors.New("encryption key not found")
+	// ErrNoKeyProvider is returned when an encrypted payload is read without a key provider
+	ErrNoKeyProvider = errors.New("payload is encrypted but no key provider is configured")
+)
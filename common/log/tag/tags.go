@@ -951,6 +951,11 @@ func ArchivalBlobstoreContextTimeout(blobstoreContextTimeout time.Duration) Tag
 	return newDurationTag("archival-blobstore-context-timeout", blobstoreContextTimeout)
 }
 
+// BlobstoreKey returns tag for the key of a blob in the blobstore
+func BlobstoreKey(key string) Tag {
+	return newStringTag("blobstore-key", key)
+}
+
 // VisibilityQuery returns tag for the query for getting visibility records
 func VisibilityQuery(query string) Tag {
 	return newStringTag("visibility-query", query)
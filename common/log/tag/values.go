@@ -123,6 +123,9 @@ var (
 	ComponentESVisibilityManager        = component("es-visibility-manager")
 	ComponentArchiver                   = component("archiver")
 	ComponentBatcher                    = component("batcher")
+	ComponentScheduler                  = component("scheduler")
+	ComponentDomainDeleter              = component("domain-deleter")
+	ComponentHistoryExporter            = component("history-exporter")
 	ComponentWorker                     = component("worker")
 	ComponentServiceResolver            = component("service-resolver")
 	ComponentFailoverCoordinator        = component("failover-coordinator")
@@ -265,10 +268,13 @@ var (
 	StoreOperationDeleteHistoryBranch       = storeOperation("delete-history-branch")
 	StoreOperationGetHistoryTree            = storeOperation("get-history-tree")
 	StoreOperationGetAllHistoryTreeBranches = storeOperation("get-all-history-tree-branches")
+	StoreOperationReencryptHistoryBranch    = storeOperation("reencrypt-history-branch")
 
 	StoreOperationEnqueueMessage             = storeOperation("enqueue-message")
+	StoreOperationEnqueueMessages            = storeOperation("enqueue-messages")
 	StoreOperationReadMessages               = storeOperation("read-messages")
 	StoreOperationUpdateAckLevel             = storeOperation("update-ack-level")
+	StoreOperationDeleteAckLevel             = storeOperation("delete-ack-level")
 	StoreOperationGetAckLevels               = storeOperation("get-ack-levels")
 	StoreOperationDeleteMessagesBefore       = storeOperation("delete-messages-before")
 	StoreOperationEnqueueMessageToDLQ        = storeOperation("enqueue-message-to-dlq")
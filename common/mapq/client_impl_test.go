@@ -94,7 +94,7 @@ func TestStartStop(t *testing.T) {
 	consumerFactory.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
 	consumerFactory.EXPECT().New(gomock.Any()).Return(consumer, nil).Times(1)
 	opts := []Options{
-		WithPersister(types.NewMockPersister(ctrl)),
+		WithPersister(newIdlePersister(ctrl)),
 		WithConsumerFactory(consumerFactory),
 	}
 	logger := testlogger.New(t)
@@ -115,7 +115,7 @@ func TestAck(t *testing.T) {
 	consumerFactory.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
 	consumerFactory.EXPECT().New(gomock.Any()).Return(consumer, nil).Times(1)
 	opts := []Options{
-		WithPersister(types.NewMockPersister(ctrl)),
+		WithPersister(newIdlePersister(ctrl)),
 		WithConsumerFactory(consumerFactory),
 	}
 	logger := testlogger.New(t)
@@ -141,7 +141,7 @@ func TestNack(t *testing.T) {
 	consumerFactory.EXPECT().Stop(gomock.Any()).Return(nil).Times(1)
 	consumerFactory.EXPECT().New(gomock.Any()).Return(consumer, nil).Times(1)
 	opts := []Options{
-		WithPersister(types.NewMockPersister(ctrl)),
+		WithPersister(newIdlePersister(ctrl)),
 		WithConsumerFactory(consumerFactory),
 	}
 	logger := testlogger.New(t)
@@ -159,3 +159,15 @@ func TestNack(t *testing.T) {
 		t.Errorf("Ack() error: %q, want %q", err, "not implemented")
 	}
 }
+
+// newIdlePersister returns a persister mock that has no items to fetch
+func newIdlePersister(ctrl *gomock.Controller) *types.MockPersister {
+	persister := types.NewMockPersister(ctrl)
+	persister.EXPECT().GetOffsets(gomock.Any()).Return(&types.Offsets{}, nil).AnyTimes()
+	persister.EXPECT().CommitOffsets(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	persister.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, partitions types.ItemPartitions, pageInfo types.PageInfo) ([]types.Item, types.PageInfo, error) {
+			return nil, pageInfo, nil
+		}).AnyTimes()
+	return persister
+}
@@ -0,0 +1,300 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mapq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/mapq/persister"
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/metrics"
+	pt "github.com/uber/cadence/common/persistence/persistence-tests"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin/sqlite"
+)
+
+// TestClientWithQueuePersister runs MAPQ end to end on top of the queue table of SQLite
+// and verifies that items are delivered at least once across restarts.
+func TestClientWithQueuePersister(t *testing.T) {
+	option, err := sqlite.GetTestClusterOption()
+	require.NoError(t, err)
+	testBase := pt.NewTestBaseWithSQL(t, option)
+	testBase.Setup()
+	defer testBase.TearDownWorkflowStore()
+
+	queue, err := testBase.ExecutionMgrFactory.NewMapQQueueManager("test-queue")
+	require.NoError(t, err)
+	p := persister.New(queue, queueTestItemCodec{})
+	consumers := newRecordingConsumerFactory()
+
+	newClient := func() types.Client {
+		cl, err := New(
+			testlogger.New(t),
+			metrics.NoopScope(0),
+			WithPersister(p),
+			WithConsumerFactory(consumers),
+			WithPartitions([]string{"type", "domain"}),
+			WithPolicies([]types.NodePolicy{
+				{
+					Path:        "*",
+					SplitPolicy: &types.SplitPolicy{PredefinedSplits: []any{"timer"}},
+				},
+				{
+					Path:        "*/.",
+					SplitPolicy: &types.SplitPolicy{PredefinedSplits: []any{"d1"}},
+				},
+				{
+					Path:           "*/./.",
+					SplitPolicy:    &types.SplitPolicy{Disabled: true},
+					DispatchPolicy: &types.DispatchPolicy{Concurrency: 2},
+				},
+			}),
+			WithFetchPageSize(3),
+			WithPollInterval(10*time.Millisecond),
+			WithRetryInterval(time.Millisecond),
+			WithOffsetCommitInterval(time.Hour),
+		)
+		require.NoError(t, err)
+		require.NoError(t, cl.Start(context.Background()))
+		return cl
+	}
+	ctx := context.Background()
+
+	// items are dispatched to the consumers of their leaf nodes and failed items are redelivered
+	consumers.failOnce(2)
+	cl := newClient()
+	_, err = cl.Enqueue(ctx, queueTestItems(1, 10))
+	require.NoError(t, err)
+	consumers.waitForProcessed(t, 1, 10)
+	require.NoError(t, cl.Stop(ctx))
+	assert.Equal(t, 2, consumers.processCount(2))
+
+	offsets, err := p.GetOffsets(ctx)
+	require.NoError(t, err)
+	assert.Len(t, offsets.Partitions, 4)
+
+	// committed items are not redelivered after restart
+	cl = newClient()
+	_, err = cl.Enqueue(ctx, queueTestItems(11, 12))
+	require.NoError(t, err)
+	consumers.waitForProcessed(t, 11, 12)
+	require.NoError(t, cl.Stop(ctx))
+	for id := int64(1); id <= 12; id++ {
+		if id != 2 {
+			assert.Equal(t, 1, consumers.processCount(id), "item %d", id)
+		}
+	}
+
+	// items which are not committed before stop are redelivered after restart
+	consumers.block(13)
+	cl = newClient()
+	_, err = cl.Enqueue(ctx, queueTestItems(13, 13))
+	require.NoError(t, err)
+	consumers.waitForProcessing(t, 13)
+	require.NoError(t, cl.Stop(ctx))
+	assert.Equal(t, 0, consumers.processCount(13))
+
+	consumers.unblock(13)
+	cl = newClient()
+	consumers.waitForProcessed(t, 13, 13)
+	require.NoError(t, cl.Stop(ctx))
+	assert.Equal(t, 1, consumers.processCount(13))
+}
+
+// queueTestItems returns items with IDs in [from, to] spread across timer/transfer types and d1/d2 domains
+func queueTestItems(from, to int64) []types.Item {
+	var items []types.Item
+	for id := from; id <= to; id++ {
+		item := &queueTestItem{ID: id, Type: "timer", Domain: "d1"}
+		if id%2 == 0 {
+			item.Type = "transfer"
+		}
+		if id%3 == 0 {
+			item.Domain = "d2"
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+type queueTestItem struct {
+	ID     int64  `json:"id"`
+	Type   string `json:"type"`
+	Domain string `json:"domain"`
+}
+
+func (i *queueTestItem) GetAttribute(key string) any {
+	switch key {
+	case "type":
+		return i.Type
+	case "domain":
+		return i.Domain
+	case "id":
+		return i.ID
+	default:
+		return nil
+	}
+}
+
+func (i *queueTestItem) Offset() int64 {
+	return i.ID
+}
+
+func (i *queueTestItem) String() string {
+	return fmt.Sprintf("queueTestItem{ID:%d, Type:%s, Domain:%s}", i.ID, i.Type, i.Domain)
+}
+
+type queueTestItemCodec struct{}
+
+func (queueTestItemCodec) Encode(item types.Item) ([]byte, error) {
+	return json.Marshal(&queueTestItem{
+		ID:     item.Offset(),
+		Type:   item.GetAttribute("type").(string),
+		Domain: item.GetAttribute("domain").(string),
+	})
+}
+
+func (queueTestItemCodec) Decode(data []byte) (types.Item, error) {
+	var item queueTestItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// recordingConsumerFactory creates consumers which record successfully processed items by ID
+type recordingConsumerFactory struct {
+	sync.Mutex
+	processed  map[int64]int
+	processing map[int64]bool
+	failing    map[int64]bool
+	blocked    map[int64]bool
+}
+
+func newRecordingConsumerFactory() *recordingConsumerFactory {
+	return &recordingConsumerFactory{
+		processed:  map[int64]int{},
+		processing: map[int64]bool{},
+		failing:    map[int64]bool{},
+		blocked:    map[int64]bool{},
+	}
+}
+
+func (f *recordingConsumerFactory) New(types.ItemPartitions) (types.Consumer, error) {
+	return &recordingConsumer{factory: f}, nil
+}
+
+func (f *recordingConsumerFactory) Stop(context.Context) error {
+	return nil
+}
+
+func (f *recordingConsumerFactory) failOnce(id int64) {
+	f.Lock()
+	defer f.Unlock()
+	f.failing[id] = true
+}
+
+func (f *recordingConsumerFactory) block(id int64) {
+	f.Lock()
+	defer f.Unlock()
+	f.blocked[id] = true
+}
+
+func (f *recordingConsumerFactory) unblock(id int64) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.blocked, id)
+}
+
+func (f *recordingConsumerFactory) processCount(id int64) int {
+	f.Lock()
+	defer f.Unlock()
+	return f.processed[id]
+}
+
+func (f *recordingConsumerFactory) waitForProcessed(t *testing.T, from, to int64) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		f.Lock()
+		defer f.Unlock()
+		for id := from; id <= to; id++ {
+			if f.processed[id] == 0 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func (f *recordingConsumerFactory) waitForProcessing(t *testing.T, id int64) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		f.Lock()
+		defer f.Unlock()
+		return f.processing[id]
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+type recordingConsumer struct {
+	factory *recordingConsumerFactory
+}
+
+func (c *recordingConsumer) Start(context.Context) error {
+	return nil
+}
+
+func (c *recordingConsumer) Stop(context.Context) error {
+	return nil
+}
+
+func (c *recordingConsumer) Process(ctx context.Context, item types.Item) error {
+	f := c.factory
+	f.Lock()
+	defer f.Unlock()
+
+	id := item.GetAttribute("id").(int64)
+	if f.failing[id] {
+		delete(f.failing, id)
+		f.processed[id]++
+		return errors.New("failed to process")
+	}
+	if f.blocked[id] {
+		f.processing[id] = true
+		f.Unlock()
+		<-ctx.Done()
+		f.Lock()
+		return ctx.Err()
+	}
+
+	f.processed[id]++
+	return nil
+}
@@ -28,23 +28,105 @@ import (
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/backoff"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/quotas"
+)
+
+const (
+	defaultPageSize      = 100
+	defaultPollInterval  = time.Second
+	defaultRetryInterval = time.Second
+
+	defaultMaxRetryInterval = time.Minute
 )
 
+// Options are the dispatcher settings which are not part of node policies
+type Options struct {
+	// PageSize is the maximum number of items scanned per fetch from the persister
+	PageSize int
+
+	// PollInterval is how long the dispatcher waits before fetching again once it has caught up with the queue.
+	// Enqueued items wake up the dispatcher earlier.
+	PollInterval time.Duration
+
+	// RetryInterval is how long the dispatcher waits before redelivering an item that failed to be processed
+	// for the first time. The interval grows exponentially with each failure, up to MaxRetryInterval.
+	RetryInterval time.Duration
+
+	// MaxRetryInterval is the maximum time the dispatcher waits before redelivering a failed item
+	MaxRetryInterval time.Duration
+}
+
+// Dispatcher fetches the items of a leaf node from the persister and pushes them to the consumer.
+// Items are delivered at least once: committed offset only moves past an item after it's processed successfully
+// and failed items are redelivered until they succeed or the dispatcher is stopped.
 type Dispatcher struct {
-	consumer  types.Consumer
-	ctx       context.Context
-	cancelCtx context.CancelFunc
-	wg        sync.WaitGroup
+	logger          log.Logger
+	consumer        types.Consumer
+	persister       types.Persister
+	partitions      types.ItemPartitions
+	policy          types.DispatchPolicy
+	opts            Options
+	limiter         quotas.Limiter
+	retryPolicy     backoff.RetryPolicy
+	committedOffset *atomic.Int64
+	notifyCh        chan struct{}
+	ctx             context.Context
+	cancelCtx       context.CancelFunc
+	wg              sync.WaitGroup
 }
 
-func New(c types.Consumer) *Dispatcher {
+func New(
+	logger log.Logger,
+	c types.Consumer,
+	persister types.Persister,
+	partitions types.ItemPartitions,
+	committedOffset int64,
+	policy types.DispatchPolicy,
+	opts Options,
+) *Dispatcher {
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+	if opts.MaxRetryInterval < opts.RetryInterval {
+		opts.MaxRetryInterval = common.MaxDuration(defaultMaxRetryInterval, opts.RetryInterval)
+	}
+
+	retryPolicy := backoff.NewExponentialRetryPolicy(opts.RetryInterval)
+	retryPolicy.SetMaximumInterval(opts.MaxRetryInterval)
+	retryPolicy.SetExpirationInterval(backoff.NoInterval)
+
+	var limiter quotas.Limiter
+	if policy.DispatchRPS > 0 {
+		limiter = quotas.NewSimpleRateLimiter(int(policy.DispatchRPS))
+	}
+
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	return &Dispatcher{
-		consumer:  c,
-		ctx:       ctx,
-		cancelCtx: cancelCtx,
+		logger:          logger.WithTags(tag.Dynamic("partitions", partitions.String())),
+		consumer:        c,
+		persister:       persister,
+		partitions:      partitions,
+		policy:          policy,
+		opts:            opts,
+		limiter:         limiter,
+		retryPolicy:     retryPolicy,
+		committedOffset: atomic.NewInt64(committedOffset),
+		notifyCh:        make(chan struct{}, 1),
+		ctx:             ctx,
+		cancelCtx:       cancelCtx,
 	}
 }
 
@@ -66,7 +148,115 @@ func (d *Dispatcher) Stop(ctx context.Context) error {
 	return nil
 }
 
+// Notify wakes up the dispatcher if it's waiting for new items
+func (d *Dispatcher) Notify() {
+	select {
+	case d.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// CommittedOffset returns the offset up to which all items of the node are processed
+func (d *Dispatcher) CommittedOffset() int64 {
+	return d.committedOffset.Load()
+}
+
 func (d *Dispatcher) run() {
 	defer d.wg.Done()
-	// TODO: implement
+
+	pageInfo := types.PageInfo{
+		ReadLevel: d.CommittedOffset(),
+		PageSize:  d.opts.PageSize,
+	}
+	for d.ctx.Err() == nil {
+		items, nextPageInfo, err := d.persister.Fetch(d.ctx, d.partitions, pageInfo)
+		if err != nil {
+			if d.ctx.Err() == nil {
+				d.logger.Warn("Failed to fetch items", tag.Error(err))
+			}
+			d.waitForItems()
+			continue
+		}
+
+		if !d.dispatch(items) {
+			// stopped before all items of the page are processed, they will be redelivered after restart
+			return
+		}
+
+		d.committedOffset.Store(nextPageInfo.ReadLevel)
+		if nextPageInfo.ReadLevel == pageInfo.ReadLevel {
+			// caught up with the queue
+			d.waitForItems()
+		}
+		pageInfo = nextPageInfo
+	}
+}
+
+// dispatch processes the given items with the concurrency and rate limit of the node policy.
+// It returns false if the dispatcher is stopped before all items are processed.
+func (d *Dispatcher) dispatch(items []types.Item) bool {
+	concurrency := d.policy.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for _, item := range items {
+		if d.limiter != nil {
+			if err := d.limiter.Wait(d.ctx); err != nil {
+				return false
+			}
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-d.ctx.Done():
+			return false
+		}
+
+		wg.Add(1)
+		go func(item types.Item) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.process(item)
+		}(item)
+	}
+
+	wg.Wait()
+	return d.ctx.Err() == nil
+}
+
+// process delivers the item to the consumer until it succeeds or the dispatcher is stopped.
+// Failed deliveries are retried with exponential backoff.
+func (d *Dispatcher) process(item types.Item) {
+	for attempt := 0; ; attempt++ {
+		err := d.consumer.Process(d.ctx, item)
+		if err == nil || d.ctx.Err() != nil {
+			return
+		}
+
+		retryInterval := d.retryPolicy.ComputeNextDelay(0, attempt)
+		d.logger.Warn("Failed to process item, will retry",
+			tag.Error(err),
+			tag.Dynamic("item", item.String()),
+			tag.Attempt(int32(attempt+1)),
+			tag.Dynamic("retry-interval", retryInterval))
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func (d *Dispatcher) waitForItems() {
+	timer := time.NewTimer(d.opts.PollInterval)
+	defer timer.Stop()
+	select {
+	case <-d.ctx.Done():
+	case <-d.notifyCh:
+	case <-timer.C:
+	}
 }
@@ -24,16 +24,31 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"go.uber.org/goleak"
+
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/mapq/types"
 )
 
+var testPartitions = types.NewItemPartitions([]string{"type"}, map[string]any{"type": "timer"})
+
 func TestStartStop(t *testing.T) {
 	defer goleak.VerifyNone(t)
 
-	d := New(nil)
+	ctrl := gomock.NewController(t)
+	persister := types.NewMockPersister(ctrl)
+	persister.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, partitions types.ItemPartitions, pageInfo types.PageInfo) ([]types.Item, types.PageInfo, error) {
+			return nil, pageInfo, nil
+		}).AnyTimes()
+
+	d := New(testlogger.New(t), types.NewMockConsumer(ctrl), persister, testPartitions, types.InitialOffset, types.DispatchPolicy{}, Options{})
 	err := d.Start(context.Background())
 	if err != nil {
 		t.Fatalf("Start() failed: %v", err)
@@ -46,3 +61,183 @@ func TestStartStop(t *testing.T) {
 		t.Fatalf("Stop() failed: %v", err)
 	}
 }
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  types.DispatchPolicy
+		failing map[int64]int
+	}{
+		{
+			name: "sequential",
+		},
+		{
+			name:   "concurrent with rate limit",
+			policy: types.DispatchPolicy{DispatchRPS: 1000, Concurrency: 3},
+		},
+		{
+			name:    "failed items are redelivered",
+			policy:  types.DispatchPolicy{Concurrency: 2},
+			failing: map[int64]int{2: 1, 4: 3},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer goleak.VerifyNone(t)
+			ctrl := gomock.NewController(t)
+
+			persister := newFakePersister(t, ctrl, 5)
+			consumer := types.NewMockConsumer(ctrl)
+			var mu sync.Mutex
+			attempts := map[int64]int{}
+			consumer.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, item types.Item) error {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts[item.Offset()]++
+				if attempts[item.Offset()] <= tc.failing[item.Offset()] {
+					return errors.New("processing failed")
+				}
+				return nil
+			}).AnyTimes()
+
+			d := New(testlogger.New(t), consumer, persister, testPartitions, types.InitialOffset, tc.policy, Options{
+				PageSize:      2,
+				PollInterval:  time.Millisecond,
+				RetryInterval: time.Millisecond,
+			})
+			if err := d.Start(context.Background()); err != nil {
+				t.Fatalf("Start() failed: %v", err)
+			}
+			defer d.Stop(context.Background())
+
+			waitFor(t, func() bool { return d.CommittedOffset() == 4 })
+
+			mu.Lock()
+			defer mu.Unlock()
+			for offset := int64(0); offset < 5; offset++ {
+				if got, want := attempts[offset], tc.failing[offset]+1; got != want {
+					t.Errorf("item %d is processed %d times, want %d", offset, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	d := New(testlogger.New(t), nil, nil, testPartitions, types.InitialOffset, types.DispatchPolicy{}, Options{
+		RetryInterval:    10 * time.Millisecond,
+		MaxRetryInterval: 40 * time.Millisecond,
+	})
+
+	for attempt, want := range []time.Duration{10, 20, 40, 40, 40} {
+		want *= time.Millisecond
+		got := d.retryPolicy.ComputeNextDelay(0, attempt)
+		// the delay has up to 20% jitter
+		if got < want*8/10 || got > want {
+			t.Errorf("retry interval of attempt %d is %v, want %v with jitter", attempt, got, want)
+		}
+	}
+
+	// the maximum retry interval can't be below the first one
+	d = New(testlogger.New(t), nil, nil, testPartitions, types.InitialOffset, types.DispatchPolicy{}, Options{
+		RetryInterval: 2 * time.Minute,
+	})
+	if got := d.opts.MaxRetryInterval; got != 2*time.Minute {
+		t.Errorf("max retry interval is %v, want %v", got, 2*time.Minute)
+	}
+}
+
+func TestStopBeforeProcessed(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctrl := gomock.NewController(t)
+
+	persister := newFakePersister(t, ctrl, 3)
+	consumer := types.NewMockConsumer(ctrl)
+	processing := make(chan struct{})
+	consumer.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, item types.Item) error {
+		if item.Offset() < 2 {
+			return nil
+		}
+		close(processing)
+		<-ctx.Done()
+		return ctx.Err()
+	}).Times(3)
+
+	d := New(testlogger.New(t), consumer, persister, testPartitions, types.InitialOffset, types.DispatchPolicy{}, Options{PageSize: 2})
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	<-processing
+	if err := d.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+
+	// only the first page is committed. the last item will be redelivered after restart
+	if got := d.CommittedOffset(); got != 1 {
+		t.Errorf("CommittedOffset() = %d, want 1", got)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctrl := gomock.NewController(t)
+
+	persister := newFakePersister(t, ctrl, 0)
+	consumer := types.NewMockConsumer(ctrl)
+	consumer.EXPECT().Process(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	d := New(testlogger.New(t), consumer, persister, testPartitions, types.InitialOffset, types.DispatchPolicy{}, Options{PollInterval: time.Hour})
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop(context.Background())
+
+	persister.add(1)
+	d.Notify()
+	waitFor(t, func() bool { return d.CommittedOffset() == 0 })
+}
+
+type fakePersister struct {
+	*types.MockPersister
+	sync.Mutex
+	count int64
+}
+
+// newFakePersister returns a persister whose items have offsets 0, 1, ..., count-1
+func newFakePersister(t *testing.T, ctrl *gomock.Controller, count int64) *fakePersister {
+	p := &fakePersister{MockPersister: types.NewMockPersister(ctrl), count: count}
+	p.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, partitions types.ItemPartitions, pageInfo types.PageInfo) ([]types.Item, types.PageInfo, error) {
+			p.Lock()
+			defer p.Unlock()
+			var items []types.Item
+			for offset := pageInfo.ReadLevel + 1; offset < p.count && len(items) < pageInfo.PageSize; offset++ {
+				item := types.NewMockItem(ctrl)
+				item.EXPECT().Offset().Return(offset).AnyTimes()
+				item.EXPECT().String().Return("item").AnyTimes()
+				items = append(items, item)
+				pageInfo.ReadLevel = offset
+			}
+			return items, pageInfo, nil
+		}).AnyTimes()
+	return p
+}
+
+func (p *fakePersister) add(count int64) {
+	p.Lock()
+	defer p.Unlock()
+	p.count += count
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
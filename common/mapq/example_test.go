@@ -221,9 +221,13 @@ func (p *InMemoryPersister) CommitOffsets(ctx context.Context, offsets *types.Of
 	return nil
 }
 
-// Fetch(ctx context.Context, partitions ItemPartitions, pageInfo PageInfo) ([]Item, error)
-func (p *InMemoryPersister) Fetch(ctx context.Context, partitions types.ItemPartitions, pageInfo types.PageInfo) ([]types.Item, error) {
-	return nil, nil
+func (p *InMemoryPersister) RemoveOffsets(ctx context.Context, paths []string) error {
+	fmt.Printf("removing offsets: %v\n", paths)
+	return nil
+}
+
+func (p *InMemoryPersister) Fetch(ctx context.Context, partitions types.ItemPartitions, pageInfo types.PageInfo) ([]types.Item, types.PageInfo, error) {
+	return nil, pageInfo, nil
 }
 
 func newTimerItem(domain string, t time.Time, timerType int) types.Item {
@@ -24,6 +24,7 @@ package mapq
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
@@ -72,6 +73,51 @@ func WithPolicies(policies []types.NodePolicy) Options {
 	}
 }
 
+// WithOffsetCommitInterval sets how often the committed offsets of leaf nodes are persisted.
+// Items processed after the last commit are redelivered when the client restarts.
+func WithOffsetCommitInterval(interval time.Duration) Options {
+	return func(c *clientImpl) {
+		c.treeOpts.OffsetCommitInterval = interval
+	}
+}
+
+// WithFetchPageSize sets the maximum number of items dispatchers scan per fetch from the persister
+func WithFetchPageSize(pageSize int) Options {
+	return func(c *clientImpl) {
+		c.treeOpts.Dispatcher.PageSize = pageSize
+	}
+}
+
+// WithPollInterval sets how long dispatchers wait before fetching again once they catch up with the queue
+func WithPollInterval(interval time.Duration) Options {
+	return func(c *clientImpl) {
+		c.treeOpts.Dispatcher.PollInterval = interval
+	}
+}
+
+// WithRetryInterval sets how long dispatchers wait before redelivering an item that failed to be processed for the first time
+func WithRetryInterval(interval time.Duration) Options {
+	return func(c *clientImpl) {
+		c.treeOpts.Dispatcher.RetryInterval = interval
+	}
+}
+
+// WithMaxRetryInterval sets the maximum time dispatchers wait before redelivering an item that failed to be processed
+func WithMaxRetryInterval(interval time.Duration) Options {
+	return func(c *clientImpl) {
+		c.treeOpts.Dispatcher.MaxRetryInterval = interval
+	}
+}
+
+// WithLeafOwnership sets the function which tells whether this instance dispatches the items of a leaf node.
+// It's used to split the leaf nodes of a queue among the instances consuming it, e.g. via a membership ring.
+// Ownership is re-evaluated every offset commit interval. All leaf nodes are dispatched by default.
+func WithLeafOwnership(ownsLeaf func(path string) (bool, error)) Options {
+	return func(c *clientImpl) {
+		c.treeOpts.OwnsLeaf = ownsLeaf
+	}
+}
+
 func New(logger log.Logger, scope metrics.Scope, opts ...Options) (types.Client, error) {
 	c := &clientImpl{
 		logger: logger.WithTags(tag.ComponentMapQ),
@@ -90,7 +136,7 @@ func New(logger log.Logger, scope metrics.Scope, opts ...Options) (types.Client,
 		return nil, fmt.Errorf("consumer factory is required. Use WithConsumerFactory option to set it")
 	}
 
-	tree, err := tree.New(logger, scope, c.partitions, c.policies, c.persister, c.consumerFactory)
+	tree, err := tree.New(logger, scope, c.partitions, c.policies, c.persister, c.consumerFactory, c.treeOpts)
 	if err != nil {
 		return nil, err
 	}
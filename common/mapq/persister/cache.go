@@ -0,0 +1,96 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package persister
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/persistence"
+)
+
+// maxCachedMessages is the max number of messages kept by messageCache
+const maxCachedMessages = 10000
+
+// messageCache keeps a window of consecutive messages of the queue. All leaf nodes scan the whole queue and skip
+// the items of other leaf nodes, so without the cache every message would be read once per leaf node.
+// Leaf nodes that are caught up read the same part of the queue, the first one reads it from the database
+// and the others get it from the cache.
+type messageCache struct {
+	sync.Mutex
+	// start is the read level the window was read after
+	start int64
+	// messages are the messages of the queue after start, in the order of their IDs.
+	// No message can be enqueued with a lower ID than the last one, so the window has no gaps.
+	messages []*persistence.QueueMessage
+}
+
+// read returns the messages after pageInfo.ReadLevel, up to pageInfo.PageSize of them
+func (c *messageCache) read(ctx context.Context, queue persistence.QueueManager, pageInfo types.PageInfo) ([]*persistence.QueueMessage, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	end := c.start
+	if len(c.messages) > 0 {
+		end = c.messages[len(c.messages)-1].ID
+	}
+
+	if len(c.messages) == 0 || pageInfo.ReadLevel < c.start || pageInfo.ReadLevel > end {
+		messages, err := queue.ReadMessages(ctx, pageInfo.ReadLevel, pageInfo.PageSize)
+		if err != nil {
+			return nil, err
+		}
+		// leaf nodes behind the window don't replace it, as the leaf nodes that are caught up would have to read it again
+		if len(messages) > 0 && (len(c.messages) == 0 || pageInfo.ReadLevel > end) {
+			c.start = pageInfo.ReadLevel
+			c.messages = messages
+		}
+		return messages, nil
+	}
+
+	first := sort.Search(len(c.messages), func(i int) bool {
+		return c.messages[i].ID > pageInfo.ReadLevel
+	})
+	cached := c.messages[first:]
+	if len(cached) >= pageInfo.PageSize {
+		return cached[:pageInfo.PageSize], nil
+	}
+
+	messages, err := queue.ReadMessages(ctx, end, pageInfo.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	c.messages = append(c.messages, messages...)
+	if overflow := len(c.messages) - maxCachedMessages; overflow > 0 {
+		c.start = c.messages[overflow-1].ID
+		c.messages = c.messages[overflow:]
+	}
+
+	page := append(cached[:len(cached):len(cached)], messages...)
+	if len(page) > pageInfo.PageSize {
+		page = page[:pageInfo.PageSize]
+	}
+	return page, nil
+}
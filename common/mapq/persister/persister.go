@@ -0,0 +1,338 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package persister
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/persistence"
+)
+
+const (
+	maxEnqueueAttempts = 5
+)
+
+type (
+	// ItemCodec converts items to and from the payloads stored in the queue
+	ItemCodec interface {
+		Encode(types.Item) ([]byte, error)
+		Decode([]byte) (types.Item, error)
+	}
+
+	// queuePersister stores the items of the leaf nodes of a MAPQ queue in a queue of the queue table.
+	// Committed offsets of leaf nodes are stored as ack levels of the queue keyed by leaf node path.
+	// The queue can be shared by multiple MAPQ clients as long as their leaf node paths don't collide.
+	queuePersister struct {
+		queue  persistence.QueueManager
+		codec  ItemCodec
+		logger log.Logger
+
+		// registeredPaths caches the leaf node paths known to have an ack level in the queue
+		registeredPaths sync.Map
+		// cache keeps the last scanned messages, so that leaf nodes reading the same part of the queue share the reads
+		cache messageCache
+	}
+
+	// Option configures the persister
+	Option func(*queuePersister)
+
+	// itemPayload is the message payload stored in the queue
+	itemPayload struct {
+		// Partition is the path of the leaf node that owns the item
+		Partition string `json:"partition"`
+		Item      []byte `json:"item"`
+	}
+
+	// persistedItem is an item fetched from the queue. Its offset is the message ID assigned by the queue.
+	persistedItem struct {
+		types.Item
+		offset int64
+	}
+
+	// ScannedItem is an item of any leaf node returned by Scan
+	ScannedItem struct {
+		types.Item
+		// Path is the path of the leaf node that owns the item
+		Path string
+	}
+)
+
+var _ types.Persister = (*queuePersister)(nil)
+
+// WithLogger sets the logger used to report the messages which can't be decoded
+func WithLogger(logger log.Logger) Option {
+	return func(p *queuePersister) {
+		p.logger = logger
+	}
+}
+
+// New returns a persister on top of the queue table of Cassandra or SQL persistence.
+// Use client.Factory.NewMapQQueueManager to create the queue manager of a MAPQ queue.
+func New(queue persistence.QueueManager, codec ItemCodec, opts ...Option) types.Persister {
+	p := &queuePersister{
+		queue:  queue,
+		codec:  codec,
+		logger: log.NewNoop(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Persist registers the leaf nodes of the items, then enqueues all items in a single batch
+func (p *queuePersister) Persist(ctx context.Context, items []types.ItemToPersist) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	payloads := make([][]byte, 0, len(items))
+	for _, item := range items {
+		path := types.PartitionsPath(item)
+		if err := p.registerPath(ctx, path); err != nil {
+			return fmt.Errorf("failed to register partition %s: %w", path, err)
+		}
+
+		encoded, err := p.codec.Encode(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode item %v: %w", item, err)
+		}
+
+		payload, err := json.Marshal(itemPayload{
+			Partition: path,
+			Item:      encoded,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode payload of item %v: %w", item, err)
+		}
+		payloads = append(payloads, payload)
+	}
+
+	// message IDs are assigned by reading the last message ID of the queue, so concurrent enqueues can conflict
+	err := retryOnConflict(func() error {
+		return p.queue.EnqueueMessages(ctx, payloads)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist %d items: %w", len(items), err)
+	}
+	return nil
+}
+
+// registerPath stores the initial offset as the ack level of a leaf node before its first item is enqueued.
+// Otherwise the items could be deleted by the commit of another leaf node before they are dispatched.
+func (p *queuePersister) registerPath(ctx context.Context, path string) error {
+	if _, ok := p.registeredPaths.Load(path); ok {
+		return nil
+	}
+
+	// ack levels are never moved backwards so this is a no-op for leaf nodes that are already registered
+	err := retryOnConflict(func() error {
+		return p.queue.UpdateAckLevel(ctx, types.InitialOffset, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.registeredPaths.Store(path, struct{}{})
+	return nil
+}
+
+func retryOnConflict(op func() error) error {
+	var err error
+	for attempt := 0; attempt < maxEnqueueAttempts; attempt++ {
+		err = op()
+		var conditionFailedErr *persistence.ConditionFailedError
+		if !errors.As(err, &conditionFailedErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (p *queuePersister) GetOffsets(ctx context.Context) (*types.Offsets, error) {
+	ackLevels, err := p.queue.GetAckLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := &types.Offsets{Partitions: map[string]int64{}}
+	for path, ackLevel := range ackLevels {
+		offsets.Partitions[path] = ackLevel
+	}
+
+	return offsets, nil
+}
+
+func (p *queuePersister) CommitOffsets(ctx context.Context, offsets *types.Offsets) error {
+	if offsets == nil || len(offsets.Partitions) == 0 {
+		return nil
+	}
+
+	for path, offset := range offsets.Partitions {
+		if offset == types.InitialOffset {
+			continue
+		}
+
+		if err := p.queue.UpdateAckLevel(ctx, offset, path); err != nil {
+			return fmt.Errorf("failed to commit offset of %s: %w", path, err)
+		}
+	}
+
+	return p.deleteCommittedItems(ctx)
+}
+
+// RemoveOffsets deletes the ack levels of the leaf nodes, so that their items no longer hold back the deletion
+// of the items committed by the other leaf nodes of the queue.
+func (p *queuePersister) RemoveOffsets(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	for _, path := range paths {
+		err := retryOnConflict(func() error {
+			return p.queue.DeleteAckLevel(ctx, path)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove offset of %s: %w", path, err)
+		}
+		p.registeredPaths.Delete(path)
+	}
+
+	return p.deleteCommittedItems(ctx)
+}
+
+// deleteCommittedItems deletes the items at or below the ack levels of all leaf nodes registered in the queue.
+// Leaf nodes of other clients sharing the queue are taken into account by reading all ack levels back.
+func (p *queuePersister) deleteCommittedItems(ctx context.Context) error {
+	ackLevels, err := p.queue.GetAckLevels(ctx)
+	if err != nil {
+		return err
+	}
+
+	minOffset := int64(math.MaxInt64)
+	for _, ackLevel := range ackLevels {
+		if ackLevel < minOffset {
+			minOffset = ackLevel
+		}
+	}
+
+	if minOffset == math.MaxInt64 || minOffset <= types.InitialOffset {
+		return nil
+	}
+
+	return p.queue.DeleteMessagesBefore(ctx, minOffset+1)
+}
+
+func (p *queuePersister) Fetch(
+	ctx context.Context,
+	partitions types.ItemPartitions,
+	pageInfo types.PageInfo,
+) ([]types.Item, types.PageInfo, error) {
+	messages, err := p.cache.read(ctx, p.queue, pageInfo)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	path := types.PartitionsPath(partitions)
+	scanned, nextPageInfo := decodeMessages(p.codec, p.logger, messages, pageInfo, func(partition string) bool {
+		return partition == path
+	})
+
+	items := make([]types.Item, 0, len(scanned))
+	for _, item := range scanned {
+		items = append(items, item.Item)
+	}
+	return items, nextPageInfo, nil
+}
+
+// Scan returns the items of all leaf nodes after pageInfo.ReadLevel, e.g. to inspect the queue with a single read
+// instead of fetching the items of each leaf node. The returned PageInfo should be used to scan the next page.
+func Scan(
+	ctx context.Context,
+	queue persistence.QueueManager,
+	codec ItemCodec,
+	pageInfo types.PageInfo,
+) ([]ScannedItem, types.PageInfo, error) {
+	messages, err := queue.ReadMessages(ctx, pageInfo.ReadLevel, pageInfo.PageSize)
+	if err != nil {
+		return nil, pageInfo, err
+	}
+
+	items, nextPageInfo := decodeMessages(codec, log.NewNoop(), messages, pageInfo, func(string) bool { return true })
+	return items, nextPageInfo, nil
+}
+
+// decodeMessages decodes the items of the messages owned by the leaf nodes accepted by the filter.
+// Messages which can't be decoded are skipped, otherwise they would block the leaf nodes reading them forever.
+func decodeMessages(
+	codec ItemCodec,
+	logger log.Logger,
+	messages []*persistence.QueueMessage,
+	pageInfo types.PageInfo,
+	filter func(partition string) bool,
+) ([]ScannedItem, types.PageInfo) {
+	nextPageInfo := pageInfo
+	var items []ScannedItem
+	for _, message := range messages {
+		nextPageInfo.ReadLevel = message.ID
+
+		var payload itemPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			logger.Error("Skipping MAPQ message with invalid payload", tag.TaskID(message.ID), tag.Error(err))
+			continue
+		}
+
+		if !filter(payload.Partition) {
+			continue
+		}
+
+		item, err := codec.Decode(payload.Item)
+		if err != nil {
+			logger.Error("Skipping MAPQ message with invalid item", tag.TaskID(message.ID), tag.Dynamic("partition", payload.Partition), tag.Error(err))
+			continue
+		}
+		items = append(items, ScannedItem{
+			Item: &persistedItem{Item: item, offset: message.ID},
+			Path: payload.Partition,
+		})
+	}
+
+	return items, nextPageInfo
+}
+
+func (i *persistedItem) Offset() int64 {
+	return i.offset
+}
+
+func (i *persistedItem) String() string {
+	return fmt.Sprintf("persistedItem{offset:%d, item:%v}", i.offset, i.Item)
+}
@@ -0,0 +1,451 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package persister
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common/mapq/types"
+	"github.com/uber/cadence/common/persistence"
+)
+
+var testPartitionKeys = []string{"type", "domain"}
+
+func TestPersist(t *testing.T) {
+	tests := []struct {
+		name        string
+		enqueueErrs []error
+		wantErr     bool
+	}{
+		{
+			name:        "success",
+			enqueueErrs: []error{nil},
+		},
+		{
+			name:        "retried on conflict",
+			enqueueErrs: []error{&persistence.ConditionFailedError{}, &persistence.ConditionFailedError{}, nil},
+		},
+		{
+			name: "too many conflicts",
+			enqueueErrs: []error{
+				&persistence.ConditionFailedError{},
+				&persistence.ConditionFailedError{},
+				&persistence.ConditionFailedError{},
+				&persistence.ConditionFailedError{},
+				&persistence.ConditionFailedError{},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "enqueue failure",
+			enqueueErrs: []error{errors.New("failed")},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := persistence.NewMockQueueManager(gomock.NewController(t))
+			wantPayload, err := json.Marshal(itemPayload{Partition: "*/timer/*", Item: []byte(`{"id":1,"type":"timer","domain":"d1"}`)})
+			assert.NoError(t, err)
+			queue.EXPECT().UpdateAckLevel(gomock.Any(), types.InitialOffset, "*/timer/*").Return(nil).Times(1)
+			for _, enqueueErr := range tc.enqueueErrs {
+				queue.EXPECT().EnqueueMessages(gomock.Any(), [][]byte{wantPayload}).Return(enqueueErr).Times(1)
+			}
+
+			p := New(queue, testItemCodec{})
+			err = p.Persist(context.Background(), []types.ItemToPersist{
+				types.NewItemToPersist(
+					&testItem{ID: 1, Type: "timer", Domain: "d1"},
+					types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"}),
+				),
+			})
+			assert.Equal(t, tc.wantErr, err != nil, "Persist() error: %v", err)
+		})
+	}
+}
+
+func TestPersistRegistersPartitionOnce(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	gomock.InOrder(
+		queue.EXPECT().UpdateAckLevel(gomock.Any(), types.InitialOffset, "*/timer/*").Return(&persistence.ConditionFailedError{}).Times(1),
+		queue.EXPECT().UpdateAckLevel(gomock.Any(), types.InitialOffset, "*/timer/*").Return(nil).Times(1),
+		// items of a single call are enqueued in a single batch
+		queue.EXPECT().EnqueueMessages(gomock.Any(), gomock.Len(2)).Return(nil).Times(1),
+	)
+
+	p := New(queue, testItemCodec{})
+	partitions := types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"})
+	err := p.Persist(context.Background(), []types.ItemToPersist{
+		types.NewItemToPersist(&testItem{ID: 1, Type: "timer", Domain: "d1"}, partitions),
+		types.NewItemToPersist(&testItem{ID: 2, Type: "timer", Domain: "d2"}, partitions),
+	})
+	assert.NoError(t, err)
+}
+
+func TestPersistRegisterFailure(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	queue.EXPECT().UpdateAckLevel(gomock.Any(), types.InitialOffset, "*/timer/*").Return(errors.New("failed")).Times(1)
+
+	p := New(queue, testItemCodec{})
+	err := p.Persist(context.Background(), []types.ItemToPersist{
+		types.NewItemToPersist(
+			&testItem{ID: 1, Type: "timer", Domain: "d1"},
+			types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"}),
+		),
+	})
+	assert.Error(t, err)
+}
+
+func TestFetch(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(4), 3).Return(persistence.QueueMessageList{
+		testMessage(t, 5, "*/timer/*", &testItem{ID: 1, Type: "timer", Domain: "d1"}),
+		testMessage(t, 6, "*/transfer/*", &testItem{ID: 2, Type: "transfer", Domain: "d1"}),
+		testMessage(t, 7, "*/timer/*", &testItem{ID: 3, Type: "timer", Domain: "d2"}),
+	}, nil).Times(1)
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(7), 3).Return(persistence.QueueMessageList{
+		testMessage(t, 8, "*/transfer/*", &testItem{ID: 4, Type: "transfer", Domain: "d1"}),
+	}, nil).Times(1)
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(8), 3).Return(nil, nil).Times(1)
+
+	p := New(queue, testItemCodec{})
+	partitions := types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"})
+
+	items, pageInfo, err := p.Fetch(context.Background(), partitions, types.PageInfo{ReadLevel: 4, PageSize: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, types.PageInfo{ReadLevel: 7, PageSize: 3}, pageInfo)
+	assert.Equal(t, []types.Item{
+		&persistedItem{Item: &testItem{ID: 1, Type: "timer", Domain: "d1"}, offset: 5},
+		&persistedItem{Item: &testItem{ID: 3, Type: "timer", Domain: "d2"}, offset: 7},
+	}, items)
+	assert.Equal(t, int64(7), items[1].Offset())
+
+	// read level advances over the items of other partitions
+	items, pageInfo, err = p.Fetch(context.Background(), partitions, pageInfo)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, types.PageInfo{ReadLevel: 8, PageSize: 3}, pageInfo)
+
+	items, pageInfo, err = p.Fetch(context.Background(), partitions, pageInfo)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, types.PageInfo{ReadLevel: 8, PageSize: 3}, pageInfo)
+}
+
+func TestFetchSharesReadsOfPartitions(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	gomock.InOrder(
+		queue.EXPECT().ReadMessages(gomock.Any(), int64(4), 2).Return(persistence.QueueMessageList{
+			testMessage(t, 5, "*/timer/*", &testItem{ID: 1, Type: "timer", Domain: "d1"}),
+			testMessage(t, 6, "*/transfer/*", &testItem{ID: 2, Type: "transfer", Domain: "d1"}),
+		}, nil).Times(1),
+		// the transfer partition is ahead of the cached messages
+		queue.EXPECT().ReadMessages(gomock.Any(), int64(6), 2).Return(persistence.QueueMessageList{
+			testMessage(t, 7, "*/timer/*", &testItem{ID: 3, Type: "timer", Domain: "d2"}),
+		}, nil).Times(1),
+		// only one message after the read level is cached, so the timer partition reads the rest of the page
+		queue.EXPECT().ReadMessages(gomock.Any(), int64(7), 2).Return(nil, nil).Times(1),
+		// the partition behind the cached messages reads the queue without replacing them
+		queue.EXPECT().ReadMessages(gomock.Any(), int64(1), 2).Return(persistence.QueueMessageList{
+			testMessage(t, 2, "*/other/*", &testItem{ID: 4, Type: "other", Domain: "d1"}),
+		}, nil).Times(1),
+	)
+
+	p := New(queue, testItemCodec{})
+	timer := types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"})
+	transfer := types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "transfer", "domain": "*"})
+	other := types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "other", "domain": "*"})
+
+	items, pageInfo, err := p.Fetch(context.Background(), timer, types.PageInfo{ReadLevel: 4, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, types.PageInfo{ReadLevel: 6, PageSize: 2}, pageInfo)
+
+	items, pageInfo, err = p.Fetch(context.Background(), transfer, types.PageInfo{ReadLevel: 4, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Item{&persistedItem{Item: &testItem{ID: 2, Type: "transfer", Domain: "d1"}, offset: 6}}, items)
+	assert.Equal(t, types.PageInfo{ReadLevel: 6, PageSize: 2}, pageInfo)
+
+	items, pageInfo, err = p.Fetch(context.Background(), transfer, types.PageInfo{ReadLevel: 5, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, types.PageInfo{ReadLevel: 7, PageSize: 2}, pageInfo)
+
+	items, pageInfo, err = p.Fetch(context.Background(), timer, types.PageInfo{ReadLevel: 6, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Item{&persistedItem{Item: &testItem{ID: 3, Type: "timer", Domain: "d2"}, offset: 7}}, items)
+	assert.Equal(t, types.PageInfo{ReadLevel: 7, PageSize: 2}, pageInfo)
+
+	items, pageInfo, err = p.Fetch(context.Background(), other, types.PageInfo{ReadLevel: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, types.PageInfo{ReadLevel: 2, PageSize: 2}, pageInfo)
+
+	items, _, err = p.Fetch(context.Background(), timer, types.PageInfo{ReadLevel: 4, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestScan(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(4), 10).Return(persistence.QueueMessageList{
+		testMessage(t, 5, "*/timer/*", &testItem{ID: 1, Type: "timer", Domain: "d1"}),
+		testMessage(t, 6, "*/transfer/*", &testItem{ID: 2, Type: "transfer", Domain: "d1"}),
+	}, nil).Times(1)
+
+	items, pageInfo, err := Scan(context.Background(), queue, testItemCodec{}, types.PageInfo{ReadLevel: 4, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, types.PageInfo{ReadLevel: 6, PageSize: 10}, pageInfo)
+	assert.Equal(t, []ScannedItem{
+		{Item: &persistedItem{Item: &testItem{ID: 1, Type: "timer", Domain: "d1"}, offset: 5}, Path: "*/timer/*"},
+		{Item: &persistedItem{Item: &testItem{ID: 2, Type: "transfer", Domain: "d1"}, offset: 6}, Path: "*/transfer/*"},
+	}, items)
+}
+
+func TestFetchSkipsInvalidMessages(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	invalidItem, err := json.Marshal(itemPayload{Partition: "*/timer/*", Item: []byte("invalid")})
+	assert.NoError(t, err)
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(0), 10).Return(persistence.QueueMessageList{
+		{ID: 1, Payload: []byte("invalid")},
+		{ID: 2, Payload: invalidItem},
+		testMessage(t, 3, "*/timer/*", &testItem{ID: 1, Type: "timer", Domain: "d1"}),
+		{ID: 4, Payload: []byte("invalid")},
+	}, nil).Times(1)
+
+	p := New(queue, testItemCodec{})
+	partitions := types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"})
+	items, pageInfo, err := p.Fetch(context.Background(), partitions, types.PageInfo{ReadLevel: 0, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Item{&persistedItem{Item: &testItem{ID: 1, Type: "timer", Domain: "d1"}, offset: 3}}, items)
+	assert.Equal(t, types.PageInfo{ReadLevel: 4, PageSize: 10}, pageInfo)
+}
+
+func TestGetOffsets(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	queue.EXPECT().GetAckLevels(gomock.Any()).Return(map[string]int64{"*/timer/*": 5, "*/*/*": 3}, nil).Times(1)
+
+	offsets, err := New(queue, testItemCodec{}).GetOffsets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Offsets{Partitions: map[string]int64{"*/timer/*": 5, "*/*/*": 3}}, offsets)
+	assert.Equal(t, types.InitialOffset, offsets.GetOffset("*/transfer/*"))
+}
+
+func TestCommitOffsets(t *testing.T) {
+	tests := []struct {
+		name            string
+		offsets         *types.Offsets
+		wantAckLevels   map[string]int64
+		storedAckLevels map[string]int64
+		wantDelete      int64
+		getAckLevelsErr error
+		wantErr         bool
+	}{
+		{
+			name: "nil offsets",
+		},
+		{
+			name: "all partitions committed",
+			offsets: &types.Offsets{Partitions: map[string]int64{
+				"*/timer/*": 5,
+				"*/*/*":     3,
+			}},
+			wantAckLevels:   map[string]int64{"*/timer/*": 5, "*/*/*": 3},
+			storedAckLevels: map[string]int64{"*/timer/*": 5, "*/*/*": 3},
+			wantDelete:      4,
+		},
+		{
+			name: "a partition without committed offset",
+			offsets: &types.Offsets{Partitions: map[string]int64{
+				"*/timer/*": 5,
+				"*/*/*":     types.InitialOffset,
+			}},
+			wantAckLevels:   map[string]int64{"*/timer/*": 5},
+			storedAckLevels: map[string]int64{"*/timer/*": 5, "*/*/*": types.InitialOffset},
+		},
+		{
+			name: "partitions of another client sharing the queue",
+			offsets: &types.Offsets{Partitions: map[string]int64{
+				"*/timer/*": 5,
+			}},
+			wantAckLevels:   map[string]int64{"*/timer/*": 5},
+			storedAckLevels: map[string]int64{"*/timer/*": 5, "*/other/*": 2},
+			wantDelete:      3,
+		},
+		{
+			name: "get ack levels failure",
+			offsets: &types.Offsets{Partitions: map[string]int64{
+				"*/timer/*": 5,
+			}},
+			wantAckLevels:   map[string]int64{"*/timer/*": 5},
+			getAckLevelsErr: errors.New("failed"),
+			wantErr:         true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := persistence.NewMockQueueManager(gomock.NewController(t))
+			for path, ackLevel := range tc.wantAckLevels {
+				queue.EXPECT().UpdateAckLevel(gomock.Any(), ackLevel, path).Return(nil).Times(1)
+			}
+			if tc.offsets != nil {
+				queue.EXPECT().GetAckLevels(gomock.Any()).Return(tc.storedAckLevels, tc.getAckLevelsErr).Times(1)
+			}
+			if tc.wantDelete != 0 {
+				queue.EXPECT().DeleteMessagesBefore(gomock.Any(), tc.wantDelete).Return(nil).Times(1)
+			}
+
+			err := New(queue, testItemCodec{}).CommitOffsets(context.Background(), tc.offsets)
+			assert.Equal(t, tc.wantErr, err != nil, "CommitOffsets() error: %v", err)
+		})
+	}
+}
+
+func TestRemoveOffsets(t *testing.T) {
+	tests := []struct {
+		name            string
+		paths           []string
+		deleteErr       error
+		storedAckLevels map[string]int64
+		wantDelete      int64
+		wantErr         bool
+	}{
+		{
+			name: "no paths",
+		},
+		{
+			name:            "removed partition no longer holds back the deletion",
+			paths:           []string{"*/removed/*"},
+			storedAckLevels: map[string]int64{"*/timer/*": 8},
+			wantDelete:      9,
+		},
+		{
+			name:            "other partitions are behind",
+			paths:           []string{"*/removed/*"},
+			storedAckLevels: map[string]int64{"*/timer/*": 8, "*/other/*": types.InitialOffset},
+		},
+		{
+			name:      "delete ack level failure",
+			paths:     []string{"*/removed/*"},
+			deleteErr: errors.New("failed"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			queue := persistence.NewMockQueueManager(gomock.NewController(t))
+			for _, path := range tc.paths {
+				queue.EXPECT().DeleteAckLevel(gomock.Any(), path).Return(tc.deleteErr).Times(1)
+			}
+			if len(tc.paths) > 0 && tc.deleteErr == nil {
+				queue.EXPECT().GetAckLevels(gomock.Any()).Return(tc.storedAckLevels, nil).Times(1)
+			}
+			if tc.wantDelete != 0 {
+				queue.EXPECT().DeleteMessagesBefore(gomock.Any(), tc.wantDelete).Return(nil).Times(1)
+			}
+
+			err := New(queue, testItemCodec{}).RemoveOffsets(context.Background(), tc.paths)
+			assert.Equal(t, tc.wantErr, err != nil, "RemoveOffsets() error: %v", err)
+		})
+	}
+}
+
+func TestRemoveOffsetsRegistersPartitionAgain(t *testing.T) {
+	queue := persistence.NewMockQueueManager(gomock.NewController(t))
+	gomock.InOrder(
+		queue.EXPECT().UpdateAckLevel(gomock.Any(), types.InitialOffset, "*/timer/*").Return(nil).Times(1),
+		queue.EXPECT().EnqueueMessages(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+		queue.EXPECT().DeleteAckLevel(gomock.Any(), "*/timer/*").Return(nil).Times(1),
+		queue.EXPECT().GetAckLevels(gomock.Any()).Return(map[string]int64{}, nil).Times(1),
+		queue.EXPECT().UpdateAckLevel(gomock.Any(), types.InitialOffset, "*/timer/*").Return(nil).Times(1),
+		queue.EXPECT().EnqueueMessages(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+	)
+
+	p := New(queue, testItemCodec{})
+	items := []types.ItemToPersist{
+		types.NewItemToPersist(
+			&testItem{ID: 1, Type: "timer", Domain: "d1"},
+			types.NewItemPartitions(testPartitionKeys, map[string]any{"type": "timer", "domain": "*"}),
+		),
+	}
+	assert.NoError(t, p.Persist(context.Background(), items))
+	assert.NoError(t, p.RemoveOffsets(context.Background(), []string{"*/timer/*"}))
+	assert.NoError(t, p.Persist(context.Background(), items))
+}
+
+func testMessage(t *testing.T, id int64, partition string, item *testItem) *persistence.QueueMessage {
+	encoded, err := testItemCodec{}.Encode(item)
+	assert.NoError(t, err)
+	payload, err := json.Marshal(itemPayload{Partition: partition, Item: encoded})
+	assert.NoError(t, err)
+	return &persistence.QueueMessage{ID: id, QueueType: persistence.MapQQueueTypeOf("test-queue"), Payload: payload}
+}
+
+type testItem struct {
+	ID     int64  `json:"id"`
+	Type   string `json:"type"`
+	Domain string `json:"domain"`
+}
+
+func (i *testItem) GetAttribute(key string) any {
+	switch key {
+	case "type":
+		return i.Type
+	case "domain":
+		return i.Domain
+	default:
+		return nil
+	}
+}
+
+func (i *testItem) Offset() int64 {
+	return i.ID
+}
+
+func (i *testItem) String() string {
+	return fmt.Sprintf("testItem{ID:%d, Type:%s, Domain:%s}", i.ID, i.Type, i.Domain)
+}
+
+type testItemCodec struct{}
+
+func (testItemCodec) Encode(item types.Item) ([]byte, error) {
+	return json.Marshal(&testItem{ID: item.Offset(), Type: item.GetAttribute("type").(string), Domain: item.GetAttribute("domain").(string)})
+}
+
+func (testItemCodec) Decode(data []byte) (types.Item, error) {
+	var item testItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
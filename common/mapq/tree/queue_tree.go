@@ -26,13 +26,34 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/mapq/dispatcher"
 	"github.com/uber/cadence/common/mapq/types"
 	"github.com/uber/cadence/common/metrics"
 )
 
+const defaultOffsetCommitInterval = 10 * time.Second
+
+// Options are the settings of the queue tree which are not part of node policies
+type Options struct {
+	// OffsetCommitInterval is how often committed offsets of leaf nodes are persisted.
+	// Offsets are also persisted when the tree is stopped.
+	OffsetCommitInterval time.Duration
+
+	// Dispatcher contains the settings of leaf node dispatchers
+	Dispatcher dispatcher.Options
+
+	// OwnsLeaf tells whether this instance dispatches the items of the leaf node with the given path.
+	// It allows multiple instances consuming the same queue to split its leaf nodes among themselves.
+	// Ownership is re-evaluated every OffsetCommitInterval. Leaf nodes are owned by all instances if it's nil.
+	OwnsLeaf func(path string) (bool, error)
+}
+
 // QueueTree is a tree structure that represents the queue structure for MAPQ
 type QueueTree struct {
 	originalLogger  log.Logger
@@ -42,7 +63,14 @@ type QueueTree struct {
 	policyCol       types.NodePolicyCollection
 	persister       types.Persister
 	consumerFactory types.ConsumerFactory
+	opts            Options
 	root            *QueueTreeNode
+	ctx             context.Context
+	cancelCtx       context.CancelFunc
+	wg              sync.WaitGroup
+
+	// mu guards the dispatchers of leaf nodes which are started and stopped as their ownership changes
+	mu sync.Mutex
 }
 
 func New(
@@ -52,7 +80,13 @@ func New(
 	policies []types.NodePolicy,
 	persister types.Persister,
 	consumerFactory types.ConsumerFactory,
+	opts Options,
 ) (*QueueTree, error) {
+	if opts.OffsetCommitInterval <= 0 {
+		opts.OffsetCommitInterval = defaultOffsetCommitInterval
+	}
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
 	t := &QueueTree{
 		originalLogger:  logger,
 		logger:          logger.WithTags(tag.ComponentMapQTree),
@@ -61,32 +95,61 @@ func New(
 		policyCol:       types.NewNodePolicyCollection(policies),
 		persister:       persister,
 		consumerFactory: consumerFactory,
+		opts:            opts,
+		ctx:             ctx,
+		cancelCtx:       cancelCtx,
 	}
 
 	return t, t.init()
 }
 
-// Start the dispatchers for all leaf nodes
+// Start the dispatchers for all owned leaf nodes from their last committed offsets
 func (t *QueueTree) Start(ctx context.Context) error {
 	t.logger.Info("Starting MAPQ tree", tag.Dynamic("tree", t.String()))
-	err := t.root.Start(ctx, t.consumerFactory, nil, map[string]any{})
+	offsets, err := t.persister.GetOffsets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get offsets: %w", err)
+	}
+
+	t.logger.Info("Fetched committed offsets", tag.Dynamic("offsets", offsets.String()))
+	t.mu.Lock()
+	err = t.root.Start(ctx, t.consumerFactory, t.persister, offsets, t.opts.Dispatcher, t.ownsLeaf, nil, map[string]any{})
+	t.mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("failed to start root node: %w", err)
 	}
 
+	t.wg.Add(1)
+	go t.commitOffsetsLoop()
+
 	t.logger.Info("Started MAPQ tree")
 	return nil
 }
 
-// Stop the dispatchers for all leaf nodes
+// Stop the dispatchers for all leaf nodes and commit their offsets
 func (t *QueueTree) Stop(ctx context.Context) error {
 	t.logger.Info("Stopping MAPQ tree", tag.Dynamic("tree", t.String()))
 
+	t.cancelCtx()
+	timeout := 10 * time.Second
+	if dl, ok := ctx.Deadline(); ok {
+		timeout = time.Until(dl)
+	}
+	if !common.AwaitWaitGroup(&t.wg, timeout) {
+		return fmt.Errorf("failed to stop offset committer in %v", timeout)
+	}
+
+	t.mu.Lock()
 	err := t.root.Stop(ctx)
+	t.mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("failed to stop nodes: %w", err)
 	}
 
+	if err := t.commitOffsets(ctx); err != nil {
+		return fmt.Errorf("failed to commit offsets: %w", err)
+	}
+
 	t.logger.Info("Stopped MAPQ tree")
 	return nil
 }
@@ -122,7 +185,118 @@ func (t *QueueTree) Enqueue(ctx context.Context, items []types.Item) ([]types.It
 		itemsToPersist = append(itemsToPersist, itemToPersist)
 	}
 
-	return itemsToPersist, t.persister.Persist(ctx, itemsToPersist)
+	if err := t.persister.Persist(ctx, itemsToPersist); err != nil {
+		return nil, err
+	}
+
+	// wake up the dispatchers of the leaf nodes which received new items
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, item := range itemsToPersist {
+		if leaf := t.root.leaf(item); leaf != nil && leaf.Dispatcher != nil {
+			leaf.Dispatcher.Notify()
+		}
+	}
+
+	return itemsToPersist, nil
+}
+
+// Offsets returns the committed offsets of all leaf nodes
+func (t *QueueTree) Offsets() *types.Offsets {
+	offsets := &types.Offsets{Partitions: map[string]int64{}}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.root.collectOffsets(offsets)
+	return offsets
+}
+
+func (t *QueueTree) commitOffsetsLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.opts.OffsetCommitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.rebalance(t.ctx); err != nil && t.ctx.Err() == nil {
+				t.logger.Error("Failed to rebalance leaf nodes", tag.Error(err))
+			}
+			if err := t.commitOffsets(t.ctx); err != nil && t.ctx.Err() == nil {
+				t.logger.Error("Failed to commit offsets", tag.Error(err))
+			}
+		}
+	}
+}
+
+func (t *QueueTree) commitOffsets(ctx context.Context) error {
+	return t.persister.CommitOffsets(ctx, t.Offsets())
+}
+
+// rebalance starts the dispatchers of the leaf nodes this instance acquired and stops the ones of the leaf nodes it lost.
+// Offsets of the lost leaf nodes are committed right away so their new owners continue from there.
+func (t *QueueTree) rebalance(ctx context.Context) error {
+	if t.opts.OwnsLeaf == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var acquired []*QueueTreeNode
+	released := &types.Offsets{Partitions: map[string]int64{}}
+	for _, leaf := range t.root.leaves() {
+		owned := t.ownsLeaf(leaf.Path)
+		switch {
+		case owned && leaf.Dispatcher == nil:
+			acquired = append(acquired, leaf)
+		case !owned && leaf.Dispatcher != nil:
+			t.logger.Info("Releasing leaf node", tag.Dynamic("path", leaf.Path))
+			offset, err := leaf.stopDispatcher(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to stop dispatcher of %s: %w", leaf.Path, err)
+			}
+			released.Partitions[leaf.Path] = offset
+		}
+	}
+
+	if len(released.Partitions) > 0 {
+		if err := t.persister.CommitOffsets(ctx, released); err != nil {
+			return fmt.Errorf("failed to commit offsets of released leaf nodes: %w", err)
+		}
+	}
+
+	if len(acquired) == 0 {
+		return nil
+	}
+
+	offsets, err := t.persister.GetOffsets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get offsets: %w", err)
+	}
+	for _, leaf := range acquired {
+		t.logger.Info("Acquiring leaf node", tag.Dynamic("path", leaf.Path), tag.Dynamic("offset", offsets.GetOffset(leaf.Path)))
+		if err := leaf.startDispatcher(ctx, t.consumerFactory, t.persister, offsets.GetOffset(leaf.Path), t.opts.Dispatcher); err != nil {
+			return fmt.Errorf("failed to start dispatcher of %s: %w", leaf.Path, err)
+		}
+	}
+	return nil
+}
+
+// ownsLeaf tells whether this instance should dispatch the items of the given leaf node.
+// Leaf nodes whose ownership can't be determined are not owned until the next rebalance.
+func (t *QueueTree) ownsLeaf(path string) bool {
+	if t.opts.OwnsLeaf == nil {
+		return true
+	}
+
+	owned, err := t.opts.OwnsLeaf(path)
+	if err != nil {
+		t.logger.Warn("Failed to determine the ownership of leaf node", tag.Dynamic("path", path), tag.Error(err))
+		return false
+	}
+	return owned
 }
 
 func (t *QueueTree) init() error {
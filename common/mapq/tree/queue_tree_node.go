@@ -63,13 +63,20 @@ type QueueTreeNode struct {
 	// If there's no children then the node is considered leaf node
 	Children map[any]*QueueTreeNode
 
-	// The dispatcher for this node. Only leaf nodes have dispatcher
+	// The dispatcher for this node. Only leaf nodes owned by this instance have dispatcher
 	Dispatcher *dispatcher.Dispatcher
+
+	// The partitions of the items of this node. Only set for leaf nodes once started
+	itemPartitions types.ItemPartitions
 }
 
 func (n *QueueTreeNode) Start(
 	ctx context.Context,
 	consumerFactory types.ConsumerFactory,
+	persister types.Persister,
+	offsets *types.Offsets,
+	dispatcherOpts dispatcher.Options,
+	owns func(path string) bool,
 	partitions []string,
 	partitionMap map[string]any,
 ) error {
@@ -77,22 +84,24 @@ func (n *QueueTreeNode) Start(
 
 	// If there are no children then this is a leaf node
 	if len(n.Children) == 0 {
-		n.logger.Info("Creating consumer and starting a new dispatcher for leaf node")
-		c, err := consumerFactory.New(types.NewItemPartitions(partitions, partitionMap))
-		if err != nil {
-			return err
+		n.itemPartitions = types.NewItemPartitions(partitions, partitionMap)
+		if !owns(n.Path) {
+			n.logger.Info("Leaf node is owned by another instance, not starting a dispatcher")
+			return nil
 		}
-		d := dispatcher.New(c)
-		if err := d.Start(ctx); err != nil {
-			return err
-		}
-		n.Dispatcher = d
-		return nil
+		return n.startDispatcher(ctx, consumerFactory, persister, offsets.GetOffset(n.Path), dispatcherOpts)
 	}
 
 	for _, child := range n.Children {
-		partitionMap[n.PartitionKey] = child.AttributeVal
-		err := child.Start(ctx, consumerFactory, partitions, partitionMap)
+		// each child gets its own copy of partitions since leaf nodes keep them for their lifetime
+		childPartitions := append(append([]string{}, partitions...), n.PartitionKey)
+		childPartitionMap := make(map[string]any, len(partitionMap)+1)
+		for k, v := range partitionMap {
+			childPartitionMap[k] = v
+		}
+		childPartitionMap[n.PartitionKey] = child.AttributeVal
+
+		err := child.Start(ctx, consumerFactory, persister, offsets, dispatcherOpts, owns, childPartitions, childPartitionMap)
 		if err != nil {
 			return fmt.Errorf("failed to start child %s: %w", child.Path, err)
 		}
@@ -102,6 +111,43 @@ func (n *QueueTreeNode) Start(
 	return nil
 }
 
+// startDispatcher creates a consumer and starts a new dispatcher for the leaf node from the given offset
+func (n *QueueTreeNode) startDispatcher(
+	ctx context.Context,
+	consumerFactory types.ConsumerFactory,
+	persister types.Persister,
+	offset int64,
+	dispatcherOpts dispatcher.Options,
+) error {
+	n.logger.Info("Creating consumer and starting a new dispatcher for leaf node")
+	c, err := consumerFactory.New(n.itemPartitions)
+	if err != nil {
+		return err
+	}
+
+	var dispatchPolicy types.DispatchPolicy
+	if n.NodePolicy.DispatchPolicy != nil {
+		dispatchPolicy = *n.NodePolicy.DispatchPolicy
+	}
+	d := dispatcher.New(n.logger, c, persister, n.itemPartitions, offset, dispatchPolicy, dispatcherOpts)
+	if err := d.Start(ctx); err != nil {
+		return err
+	}
+	n.Dispatcher = d
+	return nil
+}
+
+// stopDispatcher stops the dispatcher of the leaf node and returns its committed offset
+func (n *QueueTreeNode) stopDispatcher(ctx context.Context) (int64, error) {
+	if err := n.Dispatcher.Stop(ctx); err != nil {
+		return 0, err
+	}
+
+	offset := n.Dispatcher.CommittedOffset()
+	n.Dispatcher = nil
+	return offset, nil
+}
+
 func (n *QueueTreeNode) Stop(ctx context.Context) error {
 	n.logger.Info("Stopping node")
 
@@ -149,6 +195,48 @@ func (n *QueueTreeNode) Enqueue(
 	return child.Enqueue(ctx, item, partitions, partitionMap)
 }
 
+// leaf returns the leaf node which owns the given partitions or nil if there's no such node
+func (n *QueueTreeNode) leaf(partitions types.ItemPartitions) *QueueTreeNode {
+	node := n
+	for _, key := range partitions.GetPartitionKeys() {
+		child, ok := node.Children[partitions.GetPartitionValue(key)]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	if len(node.Children) != 0 {
+		return nil
+	}
+
+	return node
+}
+
+// leaves returns the leaf nodes under this node
+func (n *QueueTreeNode) leaves() []*QueueTreeNode {
+	if len(n.Children) == 0 {
+		return []*QueueTreeNode{n}
+	}
+
+	var leaves []*QueueTreeNode
+	for _, child := range n.Children {
+		leaves = append(leaves, child.leaves()...)
+	}
+	return leaves
+}
+
+func (n *QueueTreeNode) collectOffsets(offsets *types.Offsets) {
+	if n.Dispatcher != nil { // leaf node
+		offsets.Partitions[n.Path] = n.Dispatcher.CommittedOffset()
+		return
+	}
+
+	for _, child := range n.Children {
+		child.collectOffsets(offsets)
+	}
+}
+
 func (n *QueueTreeNode) String() string {
 	return fmt.Sprintf("QueueTreeNode{Path: %q, AttributeKey: %v, AttributeVal: %v, NodePolicy: %s, Num Children: %d}", n.Path, n.AttributeKey, n.AttributeVal, n.NodePolicy, len(n.Children))
 }
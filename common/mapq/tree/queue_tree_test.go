@@ -24,7 +24,10 @@ package tree
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
@@ -50,13 +53,34 @@ func TestStartStop(t *testing.T) {
 	// - */*/*/*
 	consumerFactory.EXPECT().New(gomock.Any()).Return(consumer, nil).Times(7)
 
+	// committed offsets are loaded on start and committed again on stop
+	persister := newIdlePersister(ctrl)
+	persister.EXPECT().GetOffsets(gomock.Any()).Return(&types.Offsets{
+		Partitions: map[string]int64{
+			"*/timer/deletehistory/*": 10,
+			"*/transfer/*/*":          20,
+		},
+	}, nil).Times(1)
+	persister.EXPECT().CommitOffsets(gomock.Any(), &types.Offsets{
+		Partitions: map[string]int64{
+			"*/timer/deletehistory/*": 10,
+			"*/timer/*/domain1":       types.InitialOffset,
+			"*/timer/*/*":             types.InitialOffset,
+			"*/transfer/*/domain1":    types.InitialOffset,
+			"*/transfer/*/*":          20,
+			"*/*/*/domain1":           types.InitialOffset,
+			"*/*/*/*":                 types.InitialOffset,
+		},
+	}).Return(nil).Times(1)
+
 	tree, err := New(
 		testlogger.New(t),
 		metrics.NoopScope(0),
 		[]string{"type", "sub-type", "domain"},
 		getTestPolicies(),
-		types.NewMockPersister(ctrl),
+		persister,
 		consumerFactory,
+		Options{},
 	)
 	if err != nil {
 		t.Fatalf("failed to create queue tree: %v", err)
@@ -71,6 +95,81 @@ func TestStartStop(t *testing.T) {
 	}
 }
 
+func TestLeafOwnership(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	ctrl := gomock.NewController(t)
+	consumerFactory := types.NewMockConsumerFactory(ctrl)
+	consumer := types.NewMockConsumer(ctrl)
+	// one consumer for the leaf node owned on start and one for the leaf node acquired later
+	consumerFactory.EXPECT().New(gomock.Any()).Return(consumer, nil).Times(2)
+
+	persister := newIdlePersister(ctrl)
+	gomock.InOrder(
+		persister.EXPECT().GetOffsets(gomock.Any()).Return(&types.Offsets{
+			Partitions: map[string]int64{"*/transfer/*/*": 20},
+		}, nil),
+		// offset of the released leaf node is committed right away for its new owner
+		persister.EXPECT().CommitOffsets(gomock.Any(), &types.Offsets{
+			Partitions: map[string]int64{"*/transfer/*/*": 20},
+		}).Return(nil),
+		persister.EXPECT().GetOffsets(gomock.Any()).Return(&types.Offsets{
+			Partitions: map[string]int64{"*/transfer/*/*": 20, "*/*/*/*": 30},
+		}, nil),
+		// only the offsets of owned leaf nodes are committed on stop
+		persister.EXPECT().CommitOffsets(gomock.Any(), &types.Offsets{
+			Partitions: map[string]int64{"*/*/*/*": 30},
+		}).Return(nil),
+	)
+
+	var mu sync.Mutex
+	owned := map[string]bool{"*/transfer/*/*": true}
+	tree, err := New(
+		testlogger.New(t),
+		metrics.NoopScope(0),
+		[]string{"type", "sub-type", "domain"},
+		getTestPolicies(),
+		persister,
+		consumerFactory,
+		Options{
+			OffsetCommitInterval: time.Hour,
+			OwnsLeaf: func(path string) (bool, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if path == "*/timer/*/*" {
+					return false, errors.New("membership not ready")
+				}
+				return owned[path], nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create queue tree: %v", err)
+	}
+
+	if err := tree.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start queue tree: %v", err)
+	}
+
+	if diff := cmp.Diff(map[string]int64{"*/transfer/*/*": 20}, tree.Offsets().Partitions); diff != "" {
+		t.Errorf("offsets mismatch after start (-want +got):\n%s", diff)
+	}
+
+	mu.Lock()
+	owned = map[string]bool{"*/*/*/*": true}
+	mu.Unlock()
+	if err := tree.rebalance(context.Background()); err != nil {
+		t.Fatalf("failed to rebalance queue tree: %v", err)
+	}
+
+	if diff := cmp.Diff(map[string]int64{"*/*/*/*": 30}, tree.Offsets().Partitions); diff != "" {
+		t.Errorf("offsets mismatch after rebalance (-want +got):\n%s", diff)
+	}
+
+	if err := tree.Stop(context.Background()); err != nil {
+		t.Fatalf("failed to stop queue tree: %v", err)
+	}
+}
+
 func TestEnqueue(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -158,7 +257,9 @@ func TestEnqueue(t *testing.T) {
 			consumerFactory.EXPECT().New(gomock.Any()).Return(consumer, nil).Times(tc.leafNodeCount)
 
 			var gotItemsToPersistByPersister []types.ItemToPersist
-			persister := types.NewMockPersister(ctrl)
+			persister := newIdlePersister(ctrl)
+			persister.EXPECT().GetOffsets(gomock.Any()).Return(&types.Offsets{}, nil).Times(1)
+			persister.EXPECT().CommitOffsets(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			persister.EXPECT().Persist(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, itemsToPersist []types.ItemToPersist) error {
 				gotItemsToPersistByPersister = itemsToPersist
 				return tc.persistErr
@@ -171,6 +272,7 @@ func TestEnqueue(t *testing.T) {
 				getTestPolicies(),
 				persister,
 				consumerFactory,
+				Options{},
 			)
 			if err != nil {
 				t.Fatalf("failed to create queue tree: %v", err)
@@ -211,6 +313,16 @@ func TestEnqueue(t *testing.T) {
 	}
 }
 
+// newIdlePersister returns a persister mock that has no items to fetch
+func newIdlePersister(ctrl *gomock.Controller) *types.MockPersister {
+	persister := types.NewMockPersister(ctrl)
+	persister.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, partitions types.ItemPartitions, pageInfo types.PageInfo) ([]types.Item, types.PageInfo, error) {
+			return nil, pageInfo, nil
+		}).AnyTimes()
+	return persister
+}
+
 func mockItem(t *testing.T, attributes map[string]any) types.Item {
 	item := types.NewMockItem(gomock.NewController(t))
 	item.EXPECT().GetAttribute(gomock.Any()).DoAndReturn(func(key string) any {
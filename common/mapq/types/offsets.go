@@ -22,7 +22,48 @@
 
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
+// InitialOffset is the committed offset of a leaf node that hasn't processed any items yet
+const InitialOffset int64 = -1
+
 // Offsets encapsulates the whole queue tree state including the offsets of each leaf node
 type Offsets struct {
-	// TODO: complete
+	// Partitions contains the committed offsets of leaf nodes by their path. e.g. "*/timer/*"
+	// All items of a leaf node up to and including its committed offset are processed.
+	Partitions map[string]int64
+}
+
+// GetOffset returns the committed offset of the leaf node with given path
+func (o *Offsets) GetOffset(path string) int64 {
+	if o == nil {
+		return InitialOffset
+	}
+
+	offset, ok := o.Partitions[path]
+	if !ok {
+		return InitialOffset
+	}
+
+	return offset
+}
+
+func (o *Offsets) String() string {
+	if o == nil {
+		return "Offsets{}"
+	}
+	return fmt.Sprintf("Offsets{Partitions:%v}", o.Partitions)
+}
+
+// PartitionsPath returns the path of the leaf node that owns the given partitions. e.g. "*/timer/*/domain1"
+func PartitionsPath(partitions ItemPartitions) string {
+	var sb strings.Builder
+	sb.WriteString("*")
+	for _, key := range partitions.GetPartitionKeys() {
+		sb.WriteString(fmt.Sprintf("/%v", partitions.GetPartitionValue(key)))
+	}
+	return sb.String()
 }
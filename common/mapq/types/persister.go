@@ -27,12 +27,31 @@ import "context"
 //go:generate mockgen -package $GOPACKAGE -source $GOFILE -destination persister_mock.go -package types github.com/uber/cadence/common/mapq/types Persister
 
 type Persister interface {
+	// Persist stores the items in the queue. Offsets of the persisted items are assigned by the persister.
 	Persist(ctx context.Context, items []ItemToPersist) error
+
+	// GetOffsets returns the last committed offsets of the queue tree
 	GetOffsets(ctx context.Context) (*Offsets, error)
+
+	// CommitOffsets stores the committed offsets of the queue tree.
+	// Items at or below the committed offsets of all leaf nodes can be deleted by the persister.
 	CommitOffsets(ctx context.Context, offsets *Offsets) error
-	Fetch(ctx context.Context, partitions ItemPartitions, pageInfo PageInfo) ([]Item, error)
+
+	// RemoveOffsets removes the committed offsets of leaf nodes which aren't dispatched anymore,
+	// e.g. after a policy change. Their items no longer prevent the deletion of the committed items.
+	RemoveOffsets(ctx context.Context, paths []string) error
+
+	// Fetch returns the items of the given leaf node partitions after pageInfo.ReadLevel.
+	// Offset() of the returned items is the offset assigned by the persister.
+	// The returned PageInfo should be used to fetch the next page. Its ReadLevel is the offset of the last
+	// item scanned, which might belong to another partition, so it advances even if no items are returned.
+	Fetch(ctx context.Context, partitions ItemPartitions, pageInfo PageInfo) ([]Item, PageInfo, error)
 }
 
 type PageInfo struct {
-	// TODO: define ack levels, page size, etc.
+	// ReadLevel is the offset after which items are fetched
+	ReadLevel int64
+
+	// PageSize is the maximum number of items to scan
+	PageSize int
 }
@@ -71,12 +71,13 @@ func (mr *MockPersisterMockRecorder) CommitOffsets(ctx, offsets interface{}) *go
 }
 
 // Fetch mocks base method.
-func (m *MockPersister) Fetch(ctx context.Context, partitions ItemPartitions, pageInfo PageInfo) ([]Item, error) {
+func (m *MockPersister) Fetch(ctx context.Context, partitions ItemPartitions, pageInfo PageInfo) ([]Item, PageInfo, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Fetch", ctx, partitions, pageInfo)
 	ret0, _ := ret[0].([]Item)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(PageInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Fetch indicates an expected call of Fetch.
@@ -113,3 +114,17 @@ func (mr *MockPersisterMockRecorder) Persist(ctx, items interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Persist", reflect.TypeOf((*MockPersister)(nil).Persist), ctx, items)
 }
+
+// RemoveOffsets mocks base method.
+func (m *MockPersister) RemoveOffsets(ctx context.Context, paths []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOffsets", ctx, paths)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOffsets indicates an expected call of RemoveOffsets.
+func (mr *MockPersisterMockRecorder) RemoveOffsets(ctx, paths interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOffsets", reflect.TypeOf((*MockPersister)(nil).RemoveOffsets), ctx, paths)
+}
@@ -60,6 +60,12 @@ type (
 		Publish(ctx context.Context, message interface{}) error
 	}
 
+	// KeyedMessage is a message which is already serialized by its producer, it's published as is under its key
+	KeyedMessage struct {
+		Key   string
+		Value []byte
+	}
+
 	// CloseableProducer is a Producer that can be closed
 	CloseableProducer interface {
 		Producer
@@ -32,6 +32,7 @@ import (
 	"github.com/uber/cadence/.gen/go/indexer"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/messaging"
 )
 
 func TestNewKafkaProducer(t *testing.T) {
@@ -70,6 +71,14 @@ func TestPublish(t *testing.T) {
 			},
 			hasErr: false,
 		},
+		{
+			name: "Publish keyed message succeeded",
+			message: &messaging.KeyedMessage{
+				Key:   "test-workflow-id",
+				Value: []byte(`{"api":"TerminateWorkflowExecution"}`),
+			},
+			hasErr: false,
+		},
 		{
 			name:    "Unrecognized message type",
 			message: "This is not a recognized message type",
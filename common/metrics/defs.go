@@ -276,6 +276,8 @@ const (
 	PersistenceCountWorkflowExecutionsScope
 	// PersistenceEnqueueMessageScope tracks Enqueue calls made by service to persistence layer
 	PersistenceEnqueueMessageScope
+	// PersistenceEnqueueMessagesScope tracks EnqueueMessages calls made by service to persistence layer
+	PersistenceEnqueueMessagesScope
 	// PersistenceEnqueueMessageToDLQScope tracks Enqueue DLQ calls made by service to persistence layer
 	PersistenceEnqueueMessageToDLQScope
 	// PersistenceReadMessagesScope tracks ReadMessages calls made by service to persistence layer
@@ -290,6 +292,8 @@ const (
 	PersistenceRangeDeleteMessagesFromDLQScope
 	// PersistenceUpdateAckLevelScope tracks UpdateAckLevel calls made by service to persistence layer
 	PersistenceUpdateAckLevelScope
+	// PersistenceDeleteAckLevelScope tracks DeleteAckLevel calls made by service to persistence layer
+	PersistenceDeleteAckLevelScope
 	// PersistenceGetAckLevelsScope tracks GetAckLevel calls made by service to persistence layer
 	PersistenceGetAckLevelsScope
 	// PersistenceUpdateDLQAckLevelScope tracks UpdateDLQAckLevel calls made by service to persistence layer
@@ -721,6 +725,8 @@ const (
 	PersistenceGetHistoryTreeScope
 	// PersistenceGetAllHistoryTreeBranchesScope tracks GetHistoryTree calls made by service to persistence layer
 	PersistenceGetAllHistoryTreeBranchesScope
+	// PersistenceReencryptHistoryBranchScope tracks ReencryptHistoryBranch calls made by service to persistence layer
+	PersistenceReencryptHistoryBranchScope
 
 	// ClusterMetadataArchivalConfigScope tracks ArchivalConfig calls to ClusterMetadata
 	ClusterMetadataArchivalConfigScope
@@ -1350,6 +1356,8 @@ const (
 	BatcherScope
 	// HistoryScavengerScope is scope used by all metrics emitted by worker.history.Scavenger module
 	HistoryScavengerScope
+	// HistoryReencryptorScope is scope used by all metrics emitted by worker.history.Reencryptor module
+	HistoryReencryptorScope
 	// ParentClosePolicyProcessorScope is scope used by all metrics emitted by worker.ParentClosePolicyProcessor
 	ParentClosePolicyProcessorScope
 	// ShardScannerScope is scope used by all metrics emitted by worker.shardscanner module
@@ -1446,7 +1454,9 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		PersistenceCompleteForkBranchScope:                       {operation: "CompleteForkBranch"},
 		PersistenceGetHistoryTreeScope:                           {operation: "GetHistoryTree"},
 		PersistenceGetAllHistoryTreeBranchesScope:                {operation: "GetAllHistoryTreeBranches"},
+		PersistenceReencryptHistoryBranchScope:                   {operation: "ReencryptHistoryBranch"},
 		PersistenceEnqueueMessageScope:                           {operation: "EnqueueMessage"},
+		PersistenceEnqueueMessagesScope:                          {operation: "EnqueueMessages"},
 		PersistenceEnqueueMessageToDLQScope:                      {operation: "EnqueueMessageToDLQ"},
 		PersistenceReadMessagesScope:                             {operation: "ReadQueueMessages"},
 		PersistenceReadMessagesFromDLQScope:                      {operation: "ReadQueueMessagesFromDLQ"},
@@ -1454,6 +1464,7 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		PersistenceDeleteMessageFromDLQScope:                     {operation: "DeleteQueueMessageFromDLQ"},
 		PersistenceRangeDeleteMessagesFromDLQScope:               {operation: "RangeDeleteMessagesFromDLQ"},
 		PersistenceUpdateAckLevelScope:                           {operation: "UpdateAckLevel"},
+		PersistenceDeleteAckLevelScope:                           {operation: "DeleteAckLevel"},
 		PersistenceGetAckLevelsScope:                             {operation: "GetAckLevel"},
 		PersistenceUpdateDLQAckLevelScope:                        {operation: "UpdateDLQAckLevel"},
 		PersistenceGetDLQAckLevelsScope:                          {operation: "GetDLQAckLevel"},
@@ -1980,6 +1991,7 @@ var ScopeDefs = map[ServiceIdx]map[int]scopeDefinition{
 		CheckDataCorruptionWorkflowScope:       {operation: "CheckDataCorruptionWorkflow"},
 		ExecutionsFixerScope:                   {operation: "ExecutionsFixer"},
 		HistoryScavengerScope:                  {operation: "historyscavenger"},
+		HistoryReencryptorScope:                {operation: "historyreencryptor"},
 		BatcherScope:                           {operation: "batcher"},
 		ParentClosePolicyProcessorScope:        {operation: "ParentClosePolicyProcessor"},
 		ESAnalyzerScope:                        {operation: "ESAnalyzer"},
@@ -2078,6 +2090,8 @@ const (
 	DomainCacheCallbacksCount
 
 	HistorySize
+	HistoryPersistedSize
+	HistoryCompressionRatio
 	HistoryCount
 	EventBlobSize
 
@@ -2259,6 +2273,7 @@ const (
 	TransferTaskThrottledCounter
 	TimerTaskThrottledCounter
 	CrossClusterTaskThrottledCounter
+	IsolatedDomainTaskThrottledCounter
 
 	TransferTaskMissingEventCounter
 
@@ -2268,6 +2283,7 @@ const (
 	ProcessingQueueStuckTaskSplitCounter
 	ProcessingQueueSelectedDomainSplitCounter
 	ProcessingQueueRandomSplitCounter
+	ProcessingQueueDomainIsolationSplitCounter
 	ProcessingQueueThrottledCounter
 
 	QueueValidatorLostTaskCounter
@@ -2521,6 +2537,7 @@ const (
 	AsyncMatchDispatchLatencyPerTaskList
 	AsyncMatchDispatchTimeoutCounterPerTaskList
 	ExpiredTasksPerTaskListCounter
+	PurgedTasksPerTaskListCounter
 	ForwardedPerTaskListCounter
 	ForwardTaskCallsPerTaskList
 	ForwardTaskErrorsPerTaskList
@@ -2558,6 +2575,11 @@ const (
 	PollLocalMatchLatencyPerTaskList
 	PollForwardMatchLatencyPerTaskList
 	PollLocalMatchAfterForwardFailedLatencyPerTaskList
+	EstimatedAddTaskQPSPerTaskListGauge
+	WritePartitionsPerTaskListGauge
+	ReadPartitionsPerTaskListGauge
+	PartitionUpscaleCounterPerTaskList
+	PartitionDownscaleCounterPerTaskList
 
 	NumMatchingMetrics
 )
@@ -2617,6 +2639,9 @@ const (
 	HistoryScavengerSuccessCount
 	HistoryScavengerErrorCount
 	HistoryScavengerSkipCount
+	HistoryReencryptorSuccessCount
+	HistoryReencryptorErrorCount
+	HistoryReencryptorNodeCount
 	DomainReplicationEnqueueDLQCount
 	ScannerExecutionsGauge
 	ScannerCorruptedGauge
@@ -2734,6 +2759,8 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		DomainCacheCallbacksLatency:                                  {metricName: "domain_cache_callbacks_latency", metricType: Timer},
 		DomainCacheCallbacksCount:                                    {metricName: "domain_cache_callbacks_count", metricType: Counter},
 		HistorySize:                                                  {metricName: "history_size", metricType: Timer},
+		HistoryPersistedSize:                                         {metricName: "history_persisted_size", metricType: Timer},
+		HistoryCompressionRatio:                                      {metricName: "history_compression_ratio", metricType: Histogram, buckets: CompressionRatioBuckets},
 		HistoryCount:                                                 {metricName: "history_count", metricType: Timer},
 		EventBlobSize:                                                {metricName: "event_blob_size", metricType: Timer},
 		DecisionResultCount:                                          {metricName: "decision_result_count", metricType: Timer},
@@ -2937,6 +2964,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		TransferTaskThrottledCounter:                                 {metricName: "transfer_task_throttled_counter", metricType: Counter},
 		TimerTaskThrottledCounter:                                    {metricName: "timer_task_throttled_counter", metricType: Counter},
 		CrossClusterTaskThrottledCounter:                             {metricName: "cross_cluster_task_throttled_counter", metricType: Counter},
+		IsolatedDomainTaskThrottledCounter:                           {metricName: "isolated_domain_task_throttled_counter", metricType: Counter},
 		TransferTaskMissingEventCounter:                              {metricName: "transfer_task_missing_event_counter", metricType: Counter},
 		ProcessingQueueNumTimer:                                      {metricName: "processing_queue_num", metricType: Timer},
 		ProcessingQueueMaxLevelTimer:                                 {metricName: "processing_queue_max_level", metricType: Timer},
@@ -2944,6 +2972,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		ProcessingQueueStuckTaskSplitCounter:                         {metricName: "processing_queue_stuck_task_split_counter", metricType: Counter},
 		ProcessingQueueSelectedDomainSplitCounter:                    {metricName: "processing_queue_selected_domain_split_counter", metricType: Counter},
 		ProcessingQueueRandomSplitCounter:                            {metricName: "processing_queue_random_split_counter", metricType: Counter},
+		ProcessingQueueDomainIsolationSplitCounter:                   {metricName: "processing_queue_domain_isolation_split_counter", metricType: Counter},
 		ProcessingQueueThrottledCounter:                              {metricName: "processing_queue_throttled_counter", metricType: Counter},
 		QueueValidatorLostTaskCounter:                                {metricName: "queue_validator_lost_task_counter", metricType: Counter},
 		QueueValidatorDropTaskCounter:                                {metricName: "queue_validator_drop_task_counter", metricType: Counter},
@@ -3186,6 +3215,7 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		BufferIsolationGroupRedirectFailureCounter:              {metricName: "buffer_isolation_group_redirect_failure_per_tl", metricRollupName: "buffer_isolation_group_redirect_failure"},
 		BufferIsolationGroupMisconfiguredCounter:                {metricName: "buffer_isolation_group_misconfigured_failure_per_tl", metricRollupName: "buffer_isolation_group_misconfigured_failure"},
 		ExpiredTasksPerTaskListCounter:                          {metricName: "tasks_expired_per_tl", metricRollupName: "tasks_expired"},
+		PurgedTasksPerTaskListCounter:                           {metricName: "tasks_purged_per_tl", metricRollupName: "tasks_purged"},
 		ForwardedPerTaskListCounter:                             {metricName: "forwarded_per_tl", metricRollupName: "forwarded"},
 		ForwardTaskCallsPerTaskList:                             {metricName: "forward_task_calls_per_tl", metricRollupName: "forward_task_calls"},
 		ForwardTaskErrorsPerTaskList:                            {metricName: "forward_task_errors_per_tl", metricRollupName: "forward_task_errors"},
@@ -3227,6 +3257,11 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		PollLocalMatchLatencyPerTaskList:                        {metricName: "poll_local_match_latency_per_tl", metricRollupName: "poll_local_match_latency", metricType: Timer},
 		PollForwardMatchLatencyPerTaskList:                      {metricName: "poll_forward_match_latency_per_tl", metricRollupName: "poll_forward_match_latency", metricType: Timer},
 		PollLocalMatchAfterForwardFailedLatencyPerTaskList:      {metricName: "poll_local_match_after_forward_failed_latency_per_tl", metricRollupName: "poll_local_match_after_forward_failed_latency", metricType: Timer},
+		EstimatedAddTaskQPSPerTaskListGauge:                     {metricName: "estimated_add_task_qps_per_tl", metricType: Gauge},
+		WritePartitionsPerTaskListGauge:                         {metricName: "write_partitions_per_tl", metricType: Gauge},
+		ReadPartitionsPerTaskListGauge:                          {metricName: "read_partitions_per_tl", metricType: Gauge},
+		PartitionUpscaleCounterPerTaskList:                      {metricName: "partition_upscale_per_tl", metricRollupName: "partition_upscale"},
+		PartitionDownscaleCounterPerTaskList:                    {metricName: "partition_downscale_per_tl", metricRollupName: "partition_downscale"},
 	},
 	Worker: {
 		ReplicatorMessages:                            {metricName: "replicator_messages"},
@@ -3282,6 +3317,9 @@ var MetricDefs = map[ServiceIdx]map[int]metricDefinition{
 		HistoryScavengerSuccessCount:                  {metricName: "scavenger_success", metricType: Counter},
 		HistoryScavengerErrorCount:                    {metricName: "scavenger_errors", metricType: Counter},
 		HistoryScavengerSkipCount:                     {metricName: "scavenger_skips", metricType: Counter},
+		HistoryReencryptorSuccessCount:                {metricName: "reencryptor_success", metricType: Counter},
+		HistoryReencryptorErrorCount:                  {metricName: "reencryptor_errors", metricType: Counter},
+		HistoryReencryptorNodeCount:                   {metricName: "reencryptor_reencrypted_nodes", metricType: Counter},
 		DomainReplicationEnqueueDLQCount:              {metricName: "domain_replication_dlq_enqueue_requests", metricType: Counter},
 		ScannerExecutionsGauge:                        {metricName: "scanner_executions", metricType: Gauge},
 		ScannerCorruptedGauge:                         {metricName: "scanner_corrupted", metricType: Gauge},
@@ -3382,6 +3420,9 @@ var GlobalRatelimiterUsageHistogram = append(
 	tally.MustMakeExponentialValueBuckets(1, 2, 17)..., // 1..65536
 )
 
+// CompressionRatioBuckets contains buckets for the ratio of compressed to uncompressed sizes
+var CompressionRatioBuckets = tally.MustMakeLinearValueBuckets(0.05, 0.05, 20) // 0.05..1
+
 // ErrorClass is an enum to help with classifying SLA vs. non-SLA errors (SLA = "service level agreement")
 type ErrorClass uint8
 
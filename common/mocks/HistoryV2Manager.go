@@ -229,3 +229,26 @@ func (_m *HistoryV2Manager) ReadRawHistoryBranch(ctx context.Context, request *p
 
 	return r0, r1
 }
+
+// ReencryptHistoryBranch provides a mock function with given fields: ctx, request
+func (_m *HistoryV2Manager) ReencryptHistoryBranch(ctx context.Context, request *persistence.ReencryptHistoryBranchRequest) (*persistence.ReencryptHistoryBranchResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *persistence.ReencryptHistoryBranchResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *persistence.ReencryptHistoryBranchRequest) *persistence.ReencryptHistoryBranchResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*persistence.ReencryptHistoryBranchResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *persistence.ReencryptHistoryBranchRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,112 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package partition
+
+import (
+	"strconv"
+)
+
+const (
+	// TaskPriorityKey is the key of the priority of the tasks of a workflow in the partition config,
+	// tasks with higher priority are dispatched first by matching
+	TaskPriorityKey = "task-priority"
+	// TaskFairnessKeyKey is the key of the fairness key of the tasks of a workflow in the partition config,
+	// tasks with the same priority are dispatched fairly across fairness keys
+	TaskFairnessKeyKey = "task-fairness-key"
+	// TaskFairnessWeightKey is the key of the weight of the fairness key in the partition config,
+	// a fairness key with weight 2 is dispatched twice as often as a fairness key with weight 1
+	TaskFairnessWeightKey = "task-fairness-weight"
+
+	// DefaultTaskPriority is the priority of tasks without priority
+	DefaultTaskPriority = 0
+	// DefaultTaskFairnessWeight is the weight of fairness keys without weight
+	DefaultTaskFairnessWeight = 1.0
+	// MaxTaskFairnessWeight is the max weight of a fairness key
+	MaxTaskFairnessWeight = 1000.0
+)
+
+// TaskPriority returns the task priority in the partition config
+func TaskPriority(config map[string]string) int {
+	priority, err := strconv.Atoi(config[TaskPriorityKey])
+	if err != nil {
+		return DefaultTaskPriority
+	}
+	return priority
+}
+
+// TaskFairnessKey returns the task fairness key in the partition config
+func TaskFairnessKey(config map[string]string) string {
+	return config[TaskFairnessKeyKey]
+}
+
+// TaskFairnessWeight returns the weight of the task fairness key in the partition config
+func TaskFairnessWeight(config map[string]string) float64 {
+	weight, err := strconv.ParseFloat(config[TaskFairnessWeightKey], 64)
+	if err != nil || weight <= 0 {
+		return DefaultTaskFairnessWeight
+	}
+	if weight > MaxTaskFairnessWeight {
+		return MaxTaskFairnessWeight
+	}
+	return weight
+}
+
+// WithTaskPriorityInRange returns the partition config with the task priority clamped to [min, max],
+// the config is returned as is if it has no task priority
+func WithTaskPriorityInRange(config map[string]string, min, max int) map[string]string {
+	if _, ok := config[TaskPriorityKey]; !ok {
+		return config
+	}
+
+	priority := TaskPriority(config)
+	if priority < min {
+		priority = min
+	}
+	if priority > max {
+		priority = max
+	}
+
+	result := make(map[string]string, len(config))
+	for k, v := range config {
+		result[k] = v
+	}
+	result[TaskPriorityKey] = strconv.Itoa(priority)
+	return result
+}
+
+// WithoutTaskPriority returns the partition config without the task priority and fairness keys,
+// it returns nil if there are no other keys in the config
+func WithoutTaskPriority(config map[string]string) map[string]string {
+	var result map[string]string
+	for k, v := range config {
+		switch k {
+		case TaskPriorityKey, TaskFairnessKeyKey, TaskFairnessWeightKey:
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(config))
+		}
+		result[k] = v
+	}
+	return result
+}
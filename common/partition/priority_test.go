@@ -0,0 +1,64 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package partition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskPriority(t *testing.T) {
+	assert.Equal(t, DefaultTaskPriority, TaskPriority(nil))
+	assert.Equal(t, DefaultTaskPriority, TaskPriority(map[string]string{TaskPriorityKey: "high"}))
+	assert.Equal(t, -5, TaskPriority(map[string]string{TaskPriorityKey: "-5"}))
+	assert.Equal(t, 10, TaskPriority(map[string]string{TaskPriorityKey: "10"}))
+}
+
+func TestTaskFairnessWeight(t *testing.T) {
+	assert.Equal(t, DefaultTaskFairnessWeight, TaskFairnessWeight(nil))
+	assert.Equal(t, DefaultTaskFairnessWeight, TaskFairnessWeight(map[string]string{TaskFairnessWeightKey: "0"}))
+	assert.Equal(t, DefaultTaskFairnessWeight, TaskFairnessWeight(map[string]string{TaskFairnessWeightKey: "abc"}))
+	assert.Equal(t, 2.5, TaskFairnessWeight(map[string]string{TaskFairnessWeightKey: "2.5"}))
+	assert.Equal(t, MaxTaskFairnessWeight, TaskFairnessWeight(map[string]string{TaskFairnessWeightKey: "1e9"}))
+}
+
+func TestWithTaskPriorityInRange(t *testing.T) {
+	assert.Nil(t, WithTaskPriorityInRange(nil, -10, 10))
+	assert.Equal(t, map[string]string{IsolationGroupKey: "zone"}, WithTaskPriorityInRange(map[string]string{IsolationGroupKey: "zone"}, -10, 10))
+	assert.Equal(t, map[string]string{TaskPriorityKey: "5"}, WithTaskPriorityInRange(map[string]string{TaskPriorityKey: "5"}, -10, 10))
+	assert.Equal(t, map[string]string{TaskPriorityKey: "-10"}, WithTaskPriorityInRange(map[string]string{TaskPriorityKey: "-50"}, -10, 10))
+	config := map[string]string{IsolationGroupKey: "zone", TaskPriorityKey: "50"}
+	assert.Equal(t, map[string]string{IsolationGroupKey: "zone", TaskPriorityKey: "10"}, WithTaskPriorityInRange(config, -10, 10))
+	// the input is not modified
+	assert.Equal(t, "50", config[TaskPriorityKey])
+}
+
+func TestWithoutTaskPriority(t *testing.T) {
+	assert.Nil(t, WithoutTaskPriority(nil))
+	assert.Nil(t, WithoutTaskPriority(map[string]string{TaskPriorityKey: "1", TaskFairnessKeyKey: "tenant"}))
+	config := map[string]string{IsolationGroupKey: "zone", TaskPriorityKey: "1", TaskFairnessWeightKey: "2"}
+	assert.Equal(t, map[string]string{IsolationGroupKey: "zone"}, WithoutTaskPriority(config))
+	// the input is not modified
+	assert.Len(t, config, 3)
+}
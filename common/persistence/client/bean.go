@@ -51,6 +51,12 @@ type (
 		GetDomainReplicationQueueManager() persistence.QueueManager
 		SetDomainReplicationQueueManager(persistence.QueueManager)
 
+		GetMapQQueueManager(string) (persistence.QueueManager, error)
+		SetMapQQueueManager(string, persistence.QueueManager)
+
+		GetAuditQueueManager() persistence.QueueManager
+		SetAuditQueueManager(persistence.QueueManager)
+
 		GetShardManager() persistence.ShardManager
 		SetShardManager(persistence.ShardManager)
 
@@ -70,13 +76,21 @@ type (
 		taskManager                   persistence.TaskManager
 		visibilityManager             persistence.VisibilityManager
 		domainReplicationQueueManager persistence.QueueManager
+		mapQQueueManagerFactory       MapQQueueManagerFactory
+		auditQueueManager             persistence.QueueManager
 		shardManager                  persistence.ShardManager
 		historyManager                persistence.HistoryManager
 		configStoreManager            persistence.ConfigStoreManager
 		executionManagerFactory       persistence.ExecutionManagerFactory
 
 		sync.RWMutex
-		shardIDToExecutionManager map[int]persistence.ExecutionManager
+		shardIDToExecutionManager   map[int]persistence.ExecutionManager
+		queueNameToMapQQueueManager map[string]persistence.QueueManager
+	}
+
+	// MapQQueueManagerFactory creates the queues storing the items of MAPQ queues
+	MapQQueueManagerFactory interface {
+		NewMapQQueueManager(queueName string) (persistence.QueueManager, error)
 	}
 
 	// Params contains dependencies for persistence
@@ -118,6 +132,11 @@ func NewBeanFromFactory(
 		return nil, err
 	}
 
+	auditQueue, err := factory.NewAuditQueueManager()
+	if err != nil {
+		return nil, err
+	}
+
 	shardMgr, err := factory.NewShardManager()
 	if err != nil {
 		return nil, err
@@ -138,10 +157,12 @@ func NewBeanFromFactory(
 		taskMgr,
 		visibilityMgr,
 		domainReplicationQueue,
+		auditQueue,
 		shardMgr,
 		historyMgr,
 		configStoreMgr,
 		factory,
+		factory,
 	), nil
 }
 
@@ -151,22 +172,27 @@ func NewBean(
 	taskManager persistence.TaskManager,
 	visibilityManager persistence.VisibilityManager,
 	domainReplicationQueueManager persistence.QueueManager,
+	auditQueueManager persistence.QueueManager,
 	shardManager persistence.ShardManager,
 	historyManager persistence.HistoryManager,
 	configStoreManager persistence.ConfigStoreManager,
 	executionManagerFactory persistence.ExecutionManagerFactory,
+	mapQQueueManagerFactory MapQQueueManagerFactory,
 ) *BeanImpl {
 	return &BeanImpl{
 		domainManager:                 domainManager,
 		taskManager:                   taskManager,
 		visibilityManager:             visibilityManager,
 		domainReplicationQueueManager: domainReplicationQueueManager,
+		auditQueueManager:             auditQueueManager,
 		shardManager:                  shardManager,
 		historyManager:                historyManager,
 		configStoreManager:            configStoreManager,
 		executionManagerFactory:       executionManagerFactory,
+		mapQQueueManagerFactory:       mapQQueueManagerFactory,
 
-		shardIDToExecutionManager: make(map[int]persistence.ExecutionManager),
+		shardIDToExecutionManager:   make(map[int]persistence.ExecutionManager),
+		queueNameToMapQQueueManager: make(map[string]persistence.QueueManager),
 	}
 }
 
@@ -250,6 +276,68 @@ func (s *BeanImpl) SetDomainReplicationQueueManager(
 	s.domainReplicationQueueManager = domainReplicationQueueManager
 }
 
+// GetMapQQueueManager gets the QueueManager storing the items of a MAPQ queue
+func (s *BeanImpl) GetMapQQueueManager(
+	queueName string,
+) (persistence.QueueManager, error) {
+
+	s.RLock()
+	mapQQueueManager, ok := s.queueNameToMapQQueueManager[queueName]
+	if ok {
+		s.RUnlock()
+		return mapQQueueManager, nil
+	}
+	s.RUnlock()
+
+	s.Lock()
+	defer s.Unlock()
+
+	mapQQueueManager, ok = s.queueNameToMapQQueueManager[queueName]
+	if ok {
+		return mapQQueueManager, nil
+	}
+
+	mapQQueueManager, err := s.mapQQueueManagerFactory.NewMapQQueueManager(queueName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.queueNameToMapQQueueManager[queueName] = mapQQueueManager
+	return mapQQueueManager, nil
+}
+
+// SetMapQQueueManager sets the QueueManager storing the items of a MAPQ queue
+func (s *BeanImpl) SetMapQQueueManager(
+	queueName string,
+	mapQQueueManager persistence.QueueManager,
+) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.queueNameToMapQQueueManager[queueName] = mapQQueueManager
+}
+
+// GetAuditQueueManager gets the QueueManager of the audit entries
+func (s *BeanImpl) GetAuditQueueManager() persistence.QueueManager {
+
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.auditQueueManager
+}
+
+// SetAuditQueueManager sets the QueueManager of the audit entries
+func (s *BeanImpl) SetAuditQueueManager(
+	auditQueueManager persistence.QueueManager,
+) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.auditQueueManager = auditQueueManager
+}
+
 // GetShardManager get ShardManager
 func (s *BeanImpl) GetShardManager() persistence.ShardManager {
 
@@ -365,10 +453,14 @@ func (s *BeanImpl) Close() {
 		s.visibilityManager.Close()
 	}
 	s.domainReplicationQueueManager.Close()
+	s.auditQueueManager.Close()
 	s.shardManager.Close()
 	s.historyManager.Close()
 	s.executionManagerFactory.Close()
 	for _, executionMgr := range s.shardIDToExecutionManager {
 		executionMgr.Close()
 	}
+	for _, mapQQueueMgr := range s.queueNameToMapQQueueManager {
+		mapQQueueMgr.Close()
+	}
 }
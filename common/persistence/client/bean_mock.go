@@ -140,6 +140,35 @@ func (mr *MockBeanMockRecorder) GetHistoryManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryManager", reflect.TypeOf((*MockBean)(nil).GetHistoryManager))
 }
 
+// GetAuditQueueManager mocks base method.
+func (m *MockBean) GetAuditQueueManager() persistence.QueueManager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditQueueManager")
+	ret0, _ := ret[0].(persistence.QueueManager)
+	return ret0
+}
+
+// GetAuditQueueManager indicates an expected call of GetAuditQueueManager.
+func (mr *MockBeanMockRecorder) GetAuditQueueManager() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditQueueManager", reflect.TypeOf((*MockBean)(nil).GetAuditQueueManager))
+}
+
+// GetMapQQueueManager mocks base method.
+func (m *MockBean) GetMapQQueueManager(arg0 string) (persistence.QueueManager, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMapQQueueManager", arg0)
+	ret0, _ := ret[0].(persistence.QueueManager)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMapQQueueManager indicates an expected call of GetMapQQueueManager.
+func (mr *MockBeanMockRecorder) GetMapQQueueManager(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMapQQueueManager", reflect.TypeOf((*MockBean)(nil).GetMapQQueueManager), arg0)
+}
+
 // GetShardManager mocks base method.
 func (m *MockBean) GetShardManager() persistence.ShardManager {
 	m.ctrl.T.Helper()
@@ -242,6 +271,30 @@ func (mr *MockBeanMockRecorder) SetHistoryManager(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHistoryManager", reflect.TypeOf((*MockBean)(nil).SetHistoryManager), arg0)
 }
 
+// SetAuditQueueManager mocks base method.
+func (m *MockBean) SetAuditQueueManager(arg0 persistence.QueueManager) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetAuditQueueManager", arg0)
+}
+
+// SetAuditQueueManager indicates an expected call of SetAuditQueueManager.
+func (mr *MockBeanMockRecorder) SetAuditQueueManager(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAuditQueueManager", reflect.TypeOf((*MockBean)(nil).SetAuditQueueManager), arg0)
+}
+
+// SetMapQQueueManager mocks base method.
+func (m *MockBean) SetMapQQueueManager(arg0 string, arg1 persistence.QueueManager) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMapQQueueManager", arg0, arg1)
+}
+
+// SetMapQQueueManager indicates an expected call of SetMapQQueueManager.
+func (mr *MockBeanMockRecorder) SetMapQQueueManager(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMapQQueueManager", reflect.TypeOf((*MockBean)(nil).SetMapQQueueManager), arg0, arg1)
+}
+
 // SetShardManager mocks base method.
 func (m *MockBean) SetShardManager(arg0 persistence.ShardManager) {
 	m.ctrl.T.Helper()
@@ -29,6 +29,7 @@ import (
 	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/config"
 	es "github.com/uber/cadence/common/elasticsearch"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/messaging"
@@ -69,6 +70,10 @@ type (
 		NewVisibilityManager(params *Params, serviceConfig *service.Config) (p.VisibilityManager, error)
 		// NewDomainReplicationQueueManager returns a new queue for domain replication
 		NewDomainReplicationQueueManager() (p.QueueManager, error)
+		// NewMapQQueueManager returns a new queue for the items of the MAPQ queue with the given name
+		NewMapQQueueManager(queueName string) (p.QueueManager, error)
+		// NewAuditQueueManager returns a new queue for audit entries
+		NewAuditQueueManager() (p.QueueManager, error)
 		// NewConfigStoreManager returns a new config store manager
 		NewConfigStoreManager() (p.ConfigStoreManager, error)
 	}
@@ -210,7 +215,7 @@ func (f *factoryImpl) NewHistoryManager() (p.HistoryManager, error) {
 	if err != nil {
 		return nil, err
 	}
-	result := p.NewHistoryV2ManagerImpl(store, f.logger, f.config.TransactionSizeLimit)
+	result := p.NewHistoryV2ManagerImpl(store, f.logger, f.config.TransactionSizeLimit, f.config.KeyProvider, f.config.BlobstoreClient, f.config.PayloadOffloadThreshold)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = errorinjectors.NewHistoryManager(result, errorRate, f.logger)
 	}
@@ -252,7 +257,7 @@ func (f *factoryImpl) NewExecutionManager(shardID int) (p.ExecutionManager, erro
 	if err != nil {
 		return nil, err
 	}
-	result := p.NewExecutionManagerImpl(store, f.logger, p.NewPayloadSerializer())
+	result := p.NewExecutionManagerImpl(store, f.logger, p.NewPayloadSerializerWithKeyProvider(f.config.KeyProvider), f.config.KeyProvider)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = errorinjectors.NewExecutionManager(result, errorRate, f.logger)
 	}
@@ -289,7 +294,7 @@ func (f *factoryImpl) NewVisibilityManager(
 		}
 
 		visibilityFromPinot = newPinotVisibilityManager(
-			params.PinotClient, resourceConfig, visibilityProducer, params.MetricsClient, f.logger)
+			params.PinotClient, resourceConfig, visibilityProducer, params.MetricsClient, f.logger, f.config.KeyProvider)
 
 		// need to use triple manager in migration mode
 		if params.PinotConfig.Migration.Enabled {
@@ -299,7 +304,7 @@ func (f *factoryImpl) NewVisibilityManager(
 			}
 			visibilityIndexName := params.ESConfig.Indices[common.VisibilityAppName]
 			visibilityFromES = newESVisibilityManager(
-				visibilityIndexName, params.ESClient, resourceConfig, esVisibilityProducer, params.MetricsClient, f.logger,
+				visibilityIndexName, params.ESClient, resourceConfig, esVisibilityProducer, params.MetricsClient, f.logger, f.config.KeyProvider,
 			)
 
 			return p.NewVisibilityTripleManager(
@@ -328,7 +333,7 @@ func (f *factoryImpl) NewVisibilityManager(
 			f.logger.Fatal("Creating visibility producer failed", tag.Error(err))
 		}
 		visibilityFromES = newESVisibilityManager(
-			visibilityIndexName, params.ESClient, resourceConfig, visibilityProducer, params.MetricsClient, f.logger,
+			visibilityIndexName, params.ESClient, resourceConfig, visibilityProducer, params.MetricsClient, f.logger, f.config.KeyProvider,
 		)
 	}
 	return p.NewVisibilityDualManager(
@@ -349,9 +354,10 @@ func newPinotVisibilityManager(
 	producer messaging.Producer,
 	metricsClient metrics.Client,
 	log log.Logger,
+	keyProvider encryption.KeyProvider,
 ) p.VisibilityManager {
 	visibilityFromPinotStore := pinotVisibility.NewPinotVisibilityStore(pinotClient, visibilityConfig, producer, log)
-	visibilityFromPinot := p.NewVisibilityManagerImpl(visibilityFromPinotStore, log)
+	visibilityFromPinot := p.NewVisibilityManagerImpl(visibilityFromPinotStore, log, keyProvider)
 
 	// wrap with rate limiter
 	if visibilityConfig.PersistenceMaxQPS != nil && visibilityConfig.PersistenceMaxQPS() != 0 {
@@ -377,10 +383,11 @@ func newESVisibilityManager(
 	producer messaging.Producer,
 	metricsClient metrics.Client,
 	log log.Logger,
+	keyProvider encryption.KeyProvider,
 ) p.VisibilityManager {
 
 	visibilityFromESStore := elasticsearch.NewElasticSearchVisibilityStore(esClient, indexName, producer, visibilityConfig, log)
-	visibilityFromES := p.NewVisibilityManagerImpl(visibilityFromESStore, log)
+	visibilityFromES := p.NewVisibilityManagerImpl(visibilityFromESStore, log, keyProvider)
 
 	// wrap with rate limiter
 	if visibilityConfig.PersistenceMaxQPS != nil && visibilityConfig.PersistenceMaxQPS() != 0 {
@@ -410,7 +417,7 @@ func (f *factoryImpl) newDBVisibilityManager(
 	if err != nil {
 		return nil, err
 	}
-	result := p.NewVisibilityManagerImpl(store, f.logger)
+	result := p.NewVisibilityManagerImpl(store, f.logger, f.config.KeyProvider)
 	if errorRate := f.config.ErrorInjectionRate(); errorRate != 0 {
 		result = errorinjectors.NewVisibilityManager(result, errorRate, f.logger)
 	}
@@ -438,8 +445,20 @@ func (f *factoryImpl) newDBVisibilityManager(
 }
 
 func (f *factoryImpl) NewDomainReplicationQueueManager() (p.QueueManager, error) {
+	return f.newQueueManager(p.DomainReplicationQueueType)
+}
+
+func (f *factoryImpl) NewMapQQueueManager(queueName string) (p.QueueManager, error) {
+	return f.newQueueManager(p.MapQQueueTypeOf(queueName))
+}
+
+func (f *factoryImpl) NewAuditQueueManager() (p.QueueManager, error) {
+	return f.newQueueManager(p.AuditQueueType)
+}
+
+func (f *factoryImpl) newQueueManager(queueType p.QueueType) (p.QueueManager, error) {
 	ds := f.datastores[storeTypeQueue]
-	store, err := ds.factory.NewQueue(p.DomainReplicationQueueType)
+	store, err := ds.factory.NewQueue(queueType)
 	if err != nil {
 		return nil, err
 	}
@@ -145,6 +145,36 @@ func (mr *MockFactoryMockRecorder) NewHistoryManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewHistoryManager", reflect.TypeOf((*MockFactory)(nil).NewHistoryManager))
 }
 
+// NewAuditQueueManager mocks base method.
+func (m *MockFactory) NewAuditQueueManager() (persistence.QueueManager, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewAuditQueueManager")
+	ret0, _ := ret[0].(persistence.QueueManager)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewAuditQueueManager indicates an expected call of NewAuditQueueManager.
+func (mr *MockFactoryMockRecorder) NewAuditQueueManager() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewAuditQueueManager", reflect.TypeOf((*MockFactory)(nil).NewAuditQueueManager))
+}
+
+// NewMapQQueueManager mocks base method.
+func (m *MockFactory) NewMapQQueueManager(arg0 string) (persistence.QueueManager, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewMapQQueueManager", arg0)
+	ret0, _ := ret[0].(persistence.QueueManager)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewMapQQueueManager indicates an expected call of NewMapQQueueManager.
+func (mr *MockFactoryMockRecorder) NewMapQQueueManager(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewMapQQueueManager", reflect.TypeOf((*MockFactory)(nil).NewMapQQueueManager), arg0)
+}
+
 // NewShardManager mocks base method.
 func (m *MockFactory) NewShardManager() (persistence.ShardManager, error) {
 	m.ctrl.T.Helper()
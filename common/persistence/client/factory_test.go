@@ -176,6 +176,22 @@ func TestFactoryMethods(t *testing.T) {
 		ds.EXPECT().NewQueue(persistence.DomainReplicationQueueType).Return(nil, nil).MinTimes(1)
 		check(t, fact.NewDomainReplicationQueueManager)
 	})
+	t.Run("NewMapQQueueManager", func(t *testing.T) {
+		fact := makeFactory(t)
+		ds := mockDatastore(t, fact, storeTypeQueue)
+
+		ds.EXPECT().NewQueue(persistence.MapQQueueTypeOf("test-queue")).Return(nil, nil).MinTimes(1)
+		check(t, func() (persistence.QueueManager, error) {
+			return fact.NewMapQQueueManager("test-queue")
+		})
+	})
+	t.Run("NewAuditQueueManager", func(t *testing.T) {
+		fact := makeFactory(t)
+		ds := mockDatastore(t, fact, storeTypeQueue)
+
+		ds.EXPECT().NewQueue(persistence.AuditQueueType).Return(nil, nil).MinTimes(1)
+		check(t, fact.NewAuditQueueManager)
+	})
 	t.Run("NewConfigStoreManager", func(t *testing.T) {
 		fact := makeFactory(t)
 		ds := mockDatastore(t, fact, storeTypeConfigStore)
@@ -0,0 +1,98 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/uber/cadence/common"
+)
+
+var (
+	// the zstd encoder and decoder are safe for concurrent use of EncodeAll and DecodeAll
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// IsCompressedEncoding returns true if blobs of the encoding type are compressed
+func IsCompressedEncoding(encodingType common.EncodingType) bool {
+	switch encodingType {
+	case common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy:
+		return true
+	default:
+		return false
+	}
+}
+
+// UncompressedEncoding returns the encoding type of the compressed payload
+// of the encoding type, or the encoding type itself if it's not compressed
+func UncompressedEncoding(encodingType common.EncodingType) common.EncodingType {
+	switch encodingType {
+	case common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy:
+		return common.EncodingTypeThriftRW
+	default:
+		return encodingType
+	}
+}
+
+// CompressDataBlob compresses a blob encoded with UncompressedEncoding(encodingType)
+// into a blob of the given compressed encoding type
+func CompressDataBlob(blob *DataBlob, encodingType common.EncodingType) (*DataBlob, error) {
+	if blob == nil || len(blob.Data) == 0 || !IsCompressedEncoding(encodingType) {
+		return blob, nil
+	}
+	if blob.Encoding != UncompressedEncoding(encodingType) {
+		return nil, NewCadenceSerializationError(fmt.Sprintf("cannot compress %v blob with encoding %v", blob.Encoding, encodingType))
+	}
+
+	var data []byte
+	switch encodingType {
+	case common.EncodingTypeThriftRWZstd:
+		data = zstdEncoder.EncodeAll(blob.Data, make([]byte, 0, len(blob.Data)/2))
+	case common.EncodingTypeThriftRWSnappy:
+		data = snappy.Encode(nil, blob.Data)
+	}
+	return NewDataBlob(data, encodingType), nil
+}
+
+// DecompressDataBlob returns the uncompressed blob of a compressed blob,
+// blobs which are not compressed are returned as they are
+func DecompressDataBlob(blob *DataBlob) (*DataBlob, error) {
+	if blob == nil || len(blob.Data) == 0 || !IsCompressedEncoding(blob.Encoding) {
+		return blob, nil
+	}
+
+	var data []byte
+	var err error
+	switch blob.Encoding {
+	case common.EncodingTypeThriftRWZstd:
+		data, err = zstdDecoder.DecodeAll(blob.Data, nil)
+	case common.EncodingTypeThriftRWSnappy:
+		data, err = snappy.Decode(nil, blob.Data)
+	}
+	if err != nil {
+		return nil, NewCadenceDeserializationError(fmt.Sprintf("failed to decompress blob with encoding %v: %v", blob.Encoding, err))
+	}
+	return NewDataBlob(data, UncompressedEncoding(blob.Encoding)), nil
+}
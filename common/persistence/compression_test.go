@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common"
+)
+
+func TestCompressDataBlob(t *testing.T) {
+	data := bytes.Repeat([]byte("highly repetitive activity input "), 100)
+
+	for _, encoding := range []common.EncodingType{common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy} {
+		t.Run(string(encoding), func(t *testing.T) {
+			compressed, err := CompressDataBlob(NewDataBlob(data, common.EncodingTypeThriftRW), encoding)
+			require.NoError(t, err)
+			assert.Equal(t, encoding, compressed.Encoding)
+			assert.Less(t, len(compressed.Data), len(data)/10)
+
+			decompressed, err := DecompressDataBlob(compressed)
+			require.NoError(t, err)
+			assert.Equal(t, NewDataBlob(data, common.EncodingTypeThriftRW), decompressed)
+
+			_, err = CompressDataBlob(NewDataBlob(data, common.EncodingTypeJSON), encoding)
+			assert.Error(t, err, "only thriftrw blobs can be compressed")
+
+			_, err = DecompressDataBlob(NewDataBlob([]byte("corrupted"), encoding))
+			assert.IsType(t, &CadenceDeserializationError{}, err)
+		})
+	}
+
+	t.Run("not compressed", func(t *testing.T) {
+		blob := NewDataBlob(data, common.EncodingTypeThriftRW)
+		compressed, err := CompressDataBlob(blob, common.EncodingTypeThriftRW)
+		require.NoError(t, err)
+		assert.Equal(t, blob, compressed)
+
+		decompressed, err := DecompressDataBlob(blob)
+		require.NoError(t, err)
+		assert.Equal(t, blob, decompressed)
+
+		decompressed, err = DecompressDataBlob(nil)
+		require.NoError(t, err)
+		assert.Nil(t, decompressed)
+	})
+}
+
+func TestDeserializeMixedEncodings(t *testing.T) {
+	serializer := NewPayloadSerializer()
+	events := generateTestHistoryEventBatch()
+
+	var blobs []*DataBlob
+	for _, encoding := range []common.EncodingType{
+		common.EncodingTypeJSON,
+		common.EncodingTypeThriftRW,
+		common.EncodingTypeThriftRWZstd,
+		common.EncodingTypeThriftRWSnappy,
+	} {
+		blob, err := serializer.SerializeBatchEvents(events, encoding)
+		require.NoError(t, err)
+		assert.Equal(t, encoding, blob.Encoding)
+		blobs = append(blobs, blob)
+	}
+
+	for _, blob := range blobs {
+		deserialized, err := serializer.DeserializeBatchEvents(blob)
+		require.NoError(t, err)
+		assert.Equal(t, events, deserialized)
+	}
+}
+
+func TestUncompressedEncoding(t *testing.T) {
+	assert.Equal(t, common.EncodingTypeThriftRW, UncompressedEncoding(common.EncodingTypeThriftRWZstd))
+	assert.Equal(t, common.EncodingTypeThriftRW, UncompressedEncoding(common.EncodingTypeThriftRWSnappy))
+	assert.Equal(t, common.EncodingTypeThriftRW, UncompressedEncoding(common.EncodingTypeThriftRW))
+	assert.Equal(t, common.EncodingTypeJSON, UncompressedEncoding(common.EncodingTypeJSON))
+	assert.False(t, IsCompressedEncoding(common.EncodingTypeThriftRW))
+	assert.True(t, IsCompressedEncoding(common.EncodingTypeThriftRWZstd))
+}
@@ -1086,6 +1086,21 @@ func (mr *MockHistoryManagerMockRecorder) ReadRawHistoryBranch(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRawHistoryBranch", reflect.TypeOf((*MockHistoryManager)(nil).ReadRawHistoryBranch), arg0, arg1)
 }
 
+// ReencryptHistoryBranch mocks base method.
+func (m *MockHistoryManager) ReencryptHistoryBranch(arg0 context.Context, arg1 *ReencryptHistoryBranchRequest) (*ReencryptHistoryBranchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptHistoryBranch", arg0, arg1)
+	ret0, _ := ret[0].(*ReencryptHistoryBranchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReencryptHistoryBranch indicates an expected call of ReencryptHistoryBranch.
+func (mr *MockHistoryManagerMockRecorder) ReencryptHistoryBranch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptHistoryBranch", reflect.TypeOf((*MockHistoryManager)(nil).ReencryptHistoryBranch), arg0, arg1)
+}
+
 // MockDomainManager is a mock of DomainManager interface.
 type MockDomainManager struct {
 	ctrl     *gomock.Controller
@@ -1272,6 +1287,20 @@ func (mr *MockQueueManagerMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockQueueManager)(nil).Close))
 }
 
+// DeleteAckLevel mocks base method.
+func (m *MockQueueManager) DeleteAckLevel(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAckLevel", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAckLevel indicates an expected call of DeleteAckLevel.
+func (mr *MockQueueManagerMockRecorder) DeleteAckLevel(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAckLevel", reflect.TypeOf((*MockQueueManager)(nil).DeleteAckLevel), arg0, arg1)
+}
+
 // DeleteMessageFromDLQ mocks base method.
 func (m *MockQueueManager) DeleteMessageFromDLQ(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -1328,6 +1357,20 @@ func (mr *MockQueueManagerMockRecorder) EnqueueMessageToDLQ(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueMessageToDLQ", reflect.TypeOf((*MockQueueManager)(nil).EnqueueMessageToDLQ), arg0, arg1)
 }
 
+// EnqueueMessages mocks base method.
+func (m *MockQueueManager) EnqueueMessages(arg0 context.Context, arg1 [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueMessages", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueMessages indicates an expected call of EnqueueMessages.
+func (mr *MockQueueManagerMockRecorder) EnqueueMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueMessages", reflect.TypeOf((*MockQueueManager)(nil).EnqueueMessages), arg0, arg1)
+}
+
 // GetAckLevels mocks base method.
 func (m *MockQueueManager) GetAckLevels(arg0 context.Context) (map[string]int64, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +54,7 @@ package persistence
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"strings"
 	"time"
 
@@ -92,6 +93,13 @@ type QueueType int
 // Negative numbers are reserved for DLQ
 const (
 	DomainReplicationQueueType QueueType = iota + 1
+	AuditQueueType
+)
+
+// Queue types from mapQQueueTypeMin to mapQQueueTypeMin+mapQQueueTypeCount-1 store the items of MAPQ queues
+const (
+	mapQQueueTypeMin   = 1 << 20
+	mapQQueueTypeCount = 1 << 20
 )
 
 // Create Workflow Execution Mode
@@ -448,14 +456,22 @@ type (
 
 	// TaskListInfo describes a state of a task list implementation.
 	TaskListInfo struct {
-		DomainID    string
-		Name        string
-		TaskType    int
-		RangeID     int64
-		AckLevel    int64
-		Kind        int
-		Expiry      time.Time
-		LastUpdated time.Time
+		DomainID                string
+		Name                    string
+		TaskType                int
+		RangeID                 int64
+		AckLevel                int64
+		Kind                    int
+		Expiry                  time.Time
+		LastUpdated             time.Time
+		AdaptivePartitionConfig *TaskListPartitionConfig
+	}
+
+	// TaskListPartitionConfig describes the partition counts last chosen by the
+	// adaptive scaler of a task list. It is nil when the scaler never ran.
+	TaskListPartitionConfig struct {
+		NumReadPartitions  int
+		NumWritePartitions int
 	}
 
 	// TaskInfo describes either activity or decision task
@@ -637,7 +653,10 @@ type (
 		NewWorkflowSnapshot WorkflowSnapshot
 
 		WorkflowRequestMode CreateWorkflowRequestMode
-		DomainName          string
+
+		Encoding common.EncodingType // optional binary encoding type
+
+		DomainName string
 	}
 
 	// CreateWorkflowExecutionResponse is the response to CreateWorkflowExecutionRequest
@@ -1342,8 +1361,10 @@ type (
 
 	// AppendHistoryNodesResponse is a response to AppendHistoryNodesRequest
 	AppendHistoryNodesResponse struct {
-		// The data blob that was persisted to database
+		// The data blob that was persisted to database, before compression and with the offloaded payloads
 		DataBlob DataBlob
+		// The size of the data persisted to database, after offloading payloads and compression
+		PersistedSize int
 	}
 
 	// ReadHistoryBranchRequest is used to read a history branch
@@ -1489,6 +1510,28 @@ type (
 		Branches []HistoryBranchDetail
 	}
 
+	// ReencryptHistoryBranchRequest is used to re-encrypt the history nodes of a branch
+	ReencryptHistoryBranchRequest struct {
+		// The branch to be re-encrypted
+		BranchToken []byte
+		// Maximum number of history nodes re-encrypted per page
+		PageSize int
+		// Token to continue re-encrypting the next page of history nodes. Pass in empty slice for first page
+		NextPageToken []byte
+		// The shard of the history branch data
+		ShardID *int
+		// The domain whose active key encrypts the history nodes
+		DomainName string
+	}
+
+	// ReencryptHistoryBranchResponse is the response to ReencryptHistoryBranchRequest
+	ReencryptHistoryBranchResponse struct {
+		// The number of history nodes rewritten in the page
+		ReencryptedCount int
+		// Token to re-encrypt the next page, empty if all the history nodes of the branch are re-encrypted
+		NextPageToken []byte
+	}
+
 	// CreateFailoverMarkersRequest is request to create failover markers
 	CreateFailoverMarkersRequest struct {
 		RangeID int64
@@ -1614,6 +1657,8 @@ type (
 		GetHistoryTree(ctx context.Context, request *GetHistoryTreeRequest) (*GetHistoryTreeResponse, error)
 		// GetAllHistoryTreeBranches returns all branches of all trees
 		GetAllHistoryTreeBranches(ctx context.Context, request *GetAllHistoryTreeBranchesRequest) (*GetAllHistoryTreeBranchesResponse, error)
+		// ReencryptHistoryBranch rewrites the history nodes of a branch which are not encrypted with the active key of the domain
+		ReencryptHistoryBranch(ctx context.Context, request *ReencryptHistoryBranchRequest) (*ReencryptHistoryBranchResponse, error)
 	}
 
 	// DomainManager is used to manage metadata CRUD for domain entities
@@ -1633,9 +1678,11 @@ type (
 	QueueManager interface {
 		Closeable
 		EnqueueMessage(ctx context.Context, messagePayload []byte) error
+		EnqueueMessages(ctx context.Context, messagePayloads [][]byte) error
 		ReadMessages(ctx context.Context, lastMessageID int64, maxCount int) (QueueMessageList, error)
 		DeleteMessagesBefore(ctx context.Context, messageID int64) error
 		UpdateAckLevel(ctx context.Context, messageID int64, clusterName string) error
+		DeleteAckLevel(ctx context.Context, clusterName string) error
 		GetAckLevels(ctx context.Context) (map[string]int64, error)
 		EnqueueMessageToDLQ(ctx context.Context, messagePayload []byte) error
 		ReadMessagesFromDLQ(ctx context.Context, firstMessageID int64, lastMessageID int64, pageSize int, pageToken []byte) ([]*QueueMessage, []byte, error)
@@ -1663,6 +1710,14 @@ type (
 	}
 )
 
+// MapQQueueTypeOf returns the queue type storing the items of the MAPQ queue with the given name.
+// MAPQ queues whose names collide share a queue type, which is safe as items are stored with the path of their leaf node.
+func MapQQueueTypeOf(queueName string) QueueType {
+	h := fnv.New32a()
+	h.Write([]byte(queueName))
+	return QueueType(mapQQueueTypeMin + int(h.Sum32()%mapQQueueTypeCount))
+}
+
 // IsTimeoutError check whether error is TimeoutError
 func IsTimeoutError(err error) bool {
 	_, ok := err.(*TimeoutError)
@@ -155,6 +155,8 @@ type (
 		GetHistoryTree(ctx context.Context, request *InternalGetHistoryTreeRequest) (*InternalGetHistoryTreeResponse, error)
 		// GetAllHistoryTreeBranches returns all branches of all trees
 		GetAllHistoryTreeBranches(ctx context.Context, request *GetAllHistoryTreeBranchesRequest) (*GetAllHistoryTreeBranchesResponse, error)
+		// UpdateHistoryNode overrides the events of an existing history node
+		UpdateHistoryNode(ctx context.Context, request *InternalUpdateHistoryNodeRequest) error
 	}
 
 	// VisibilityStore is the store interface for visibility
@@ -197,9 +199,11 @@ type (
 	Queue interface {
 		Closeable
 		EnqueueMessage(ctx context.Context, messagePayload []byte) error
+		EnqueueMessages(ctx context.Context, messagePayloads [][]byte) error
 		ReadMessages(ctx context.Context, lastMessageID int64, maxCount int) ([]*InternalQueueMessage, error)
 		DeleteMessagesBefore(ctx context.Context, messageID int64) error
 		UpdateAckLevel(ctx context.Context, messageID int64, clusterName string) error
+		DeleteAckLevel(ctx context.Context, clusterName string) error
 		GetAckLevels(ctx context.Context) (map[string]int64, error)
 		EnqueueMessageToDLQ(ctx context.Context, messagePayload []byte) error
 		ReadMessagesFromDLQ(ctx context.Context, firstMessageID int64, lastMessageID int64, pageSize int, pageToken []byte) ([]*InternalQueueMessage, []byte, error)
@@ -535,6 +539,22 @@ type (
 		ShardID int
 	}
 
+	// InternalUpdateHistoryNodeRequest is used to override the events of a history node
+	InternalUpdateHistoryNodeRequest struct {
+		// The tree of the node
+		TreeID string
+		// The branch of the node
+		BranchID string
+		// The ID of the node
+		NodeID int64
+		// The transaction ID of the node
+		TransactionID int64
+		// The events which override the events of the node
+		Events *DataBlob
+		// Used in sharded data stores to identify which shard to use
+		ShardID int
+	}
+
 	// InternalGetWorkflowExecutionRequest is used to retrieve the info of a workflow execution
 	InternalGetWorkflowExecutionRequest struct {
 		DomainID  string
@@ -623,6 +643,8 @@ type (
 	InternalReadHistoryBranchResponse struct {
 		// History events
 		History []*DataBlob
+		// The nodes of the history events, in the same order as History
+		Nodes []InternalHistoryNode
 		// Pagination token
 		NextPageToken []byte
 		// LastNodeID is the last known node ID attached to a history node
@@ -631,6 +653,12 @@ type (
 		LastTransactionID int64
 	}
 
+	// InternalHistoryNode identifies a node of a history branch
+	InternalHistoryNode struct {
+		NodeID        int64
+		TransactionID int64
+	}
+
 	// InternalGetHistoryTreeRequest is used to get history tree
 	InternalGetHistoryTreeRequest struct {
 		// A UUID of a tree
@@ -929,7 +957,7 @@ func NewDataBlob(data []byte, encodingType common.EncodingType) *DataBlob {
 	if len(data) == 0 {
 		return nil
 	}
-	if encodingType != common.EncodingTypeThriftRW && data[0] == 'Y' {
+	if encodingType != common.EncodingTypeThriftRW && !IsCompressedEncoding(encodingType) && data[0] == 'Y' {
 		// original reason for this is not written down, but maybe for handling data prior to an encoding type?
 		panic(fmt.Sprintf("Invalid data blob encoding: \"%v\"", encodingType))
 	}
@@ -981,9 +1009,16 @@ func (d *DataBlob) GetEncoding() common.EncodingType {
 		return common.EncodingTypeJSON
 	case common.EncodingTypeThriftRW:
 		return common.EncodingTypeThriftRW
+	case common.EncodingTypeThriftRWZstd:
+		return common.EncodingTypeThriftRWZstd
+	case common.EncodingTypeThriftRWSnappy:
+		return common.EncodingTypeThriftRWSnappy
 	case common.EncodingTypeEmpty:
 		return common.EncodingTypeEmpty
 	default:
+		if IsEncryptedEncoding(common.EncodingType(encodingStr)) {
+			return common.EncodingType(encodingStr)
+		}
 		return common.EncodingTypeUnknown
 	}
 }
@@ -44,6 +44,8 @@ func TestDataBlob(t *testing.T) {
 		allEncodings := []common.EncodingType{
 			common.EncodingTypeJSON,
 			common.EncodingTypeThriftRW,
+			common.EncodingTypeThriftRWZstd,
+			common.EncodingTypeThriftRWSnappy,
 			common.EncodingTypeGob,
 			common.EncodingTypeUnknown,
 			common.EncodingTypeEmpty,
@@ -55,10 +57,16 @@ func TestDataBlob(t *testing.T) {
 		assert.NotPanics(t, func() {
 			NewDataBlob([]byte(problematic), common.EncodingTypeThriftRW)
 		}, "only thriftrw data can start with Y without panicking")
+		// compressed data can start with any byte, e.g. a snappy block of 89 bytes
+		for _, encoding := range []common.EncodingType{common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy} {
+			assert.NotPanicsf(t, func() {
+				NewDataBlob([]byte(problematic), encoding)
+			}, "compressed %v data can start with Y without panicking", encoding)
+		}
 
 		// all others panic
 		for _, encoding := range allEncodings {
-			if encoding == common.EncodingTypeThriftRW {
+			if encoding == common.EncodingTypeThriftRW || IsCompressedEncoding(encoding) {
 				continue // handled above
 			}
 			assert.Panicsf(t, func() {
@@ -119,6 +127,8 @@ func TestDataBlob(t *testing.T) {
 		same(common.EncodingTypeGob)
 		same(common.EncodingTypeJSON)
 		same(common.EncodingTypeThriftRW)
+		same(common.EncodingTypeThriftRWZstd)
+		same(common.EncodingTypeThriftRWSnappy)
 		same(common.EncodingTypeEmpty)
 
 		// highly suspicious
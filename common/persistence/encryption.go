@@ -0,0 +1,177 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/encryption"
+)
+
+// encryptedEncodingSuffix is appended to the encoding type of encrypted blobs,
+// the encoding type without the suffix is the encoding type of the decrypted blob
+const encryptedEncodingSuffix = "+encrypted"
+
+// IsEncryptedEncoding returns true if blobs of the encoding type are encrypted
+func IsEncryptedEncoding(encodingType common.EncodingType) bool {
+	return strings.HasSuffix(string(encodingType), encryptedEncodingSuffix)
+}
+
+// DecryptedEncoding returns the encoding type of the decrypted payload
+// of the encoding type, or the encoding type itself if it's not encrypted
+func DecryptedEncoding(encodingType common.EncodingType) common.EncodingType {
+	return common.EncodingType(strings.TrimSuffix(string(encodingType), encryptedEncodingSuffix))
+}
+
+// GetEncryptionKeyID returns the ID of the key which encrypted the blob,
+// or an empty string if the blob is not encrypted
+func (d *DataBlob) GetEncryptionKeyID() string {
+	if d == nil || !IsEncryptedEncoding(d.Encoding) {
+		return ""
+	}
+	keyID, err := encryption.KeyID(d.Data)
+	if err != nil {
+		return ""
+	}
+	return keyID
+}
+
+// EncryptDataBlob encrypts the blob with the active key of the domain,
+// the blob is returned as it is if there is no key provider or payloads of the domain are not encrypted
+func EncryptDataBlob(keyProvider encryption.KeyProvider, blob *DataBlob, domainName string) (*DataBlob, error) {
+	if keyProvider == nil || blob == nil || len(blob.Data) == 0 || IsEncryptedEncoding(blob.Encoding) {
+		return blob, nil
+	}
+	keyID := keyProvider.ActiveKeyID(domainName)
+	if len(keyID) == 0 {
+		return blob, nil
+	}
+	data, err := encryption.Encrypt(keyProvider, keyID, blob.Data)
+	if err != nil {
+		return nil, NewCadenceSerializationError(fmt.Sprintf("failed to encrypt blob with encoding %v: %v", blob.Encoding, err))
+	}
+	return NewDataBlob(data, blob.Encoding+encryptedEncodingSuffix), nil
+}
+
+// DecryptDataBlob returns the decrypted blob of an encrypted blob,
+// blobs which are not encrypted are returned as they are
+func DecryptDataBlob(keyProvider encryption.KeyProvider, blob *DataBlob) (*DataBlob, error) {
+	if blob == nil || len(blob.Data) == 0 || !IsEncryptedEncoding(blob.Encoding) {
+		return blob, nil
+	}
+	data, err := encryption.Decrypt(keyProvider, blob.Data)
+	if err != nil {
+		return nil, NewCadenceDeserializationError(fmt.Sprintf("failed to decrypt blob with encoding %v: %v", blob.Encoding, err))
+	}
+	return &DataBlob{Data: data, Encoding: DecryptedEncoding(blob.Encoding)}, nil
+}
+
+// ReencryptDataBlob encrypts the blob with the active key of the domain if it was encrypted with another key,
+// or decrypts it if payloads of the domain are no longer encrypted. The returned bool is false if the blob is unchanged.
+func ReencryptDataBlob(keyProvider encryption.KeyProvider, blob *DataBlob, domainName string) (*DataBlob, bool, error) {
+	if keyProvider == nil || blob == nil || len(blob.Data) == 0 {
+		return blob, false, nil
+	}
+	if blob.GetEncryptionKeyID() == keyProvider.ActiveKeyID(domainName) {
+		return blob, false, nil
+	}
+	decrypted, err := DecryptDataBlob(keyProvider, blob)
+	if err != nil {
+		return nil, false, err
+	}
+	encrypted, err := EncryptDataBlob(keyProvider, decrypted, domainName)
+	if err != nil {
+		return nil, false, err
+	}
+	return encrypted, true, nil
+}
+
+// activeKeyID returns the ID of the key which encrypts the new payloads of the domain,
+// or an empty string if payloads of the domain are not encrypted
+func activeKeyID(keyProvider encryption.KeyProvider, domainName string) string {
+	if keyProvider == nil {
+		return ""
+	}
+	return keyProvider.ActiveKeyID(domainName)
+}
+
+// isEncryptedDataBlob returns true if the blob is encrypted by EncryptDataBlob
+func isEncryptedDataBlob(blob *DataBlob) bool {
+	return blob != nil && IsEncryptedEncoding(blob.Encoding)
+}
+
+// encryptPayload encrypts a payload field with the key. Unlike blobs, payload fields have no encoding
+// recording that they are encrypted, so the caller records it outside of the payload and calls decryptPayload
+// only for payloads which it knows are encrypted. The payload bytes are never inspected to tell.
+func encryptPayload(keyProvider encryption.KeyProvider, keyID string, payload []byte) ([]byte, error) {
+	if len(payload) == 0 {
+		return payload, nil
+	}
+	data, err := encryption.Encrypt(keyProvider, keyID, payload)
+	if err != nil {
+		return nil, NewCadenceSerializationError(fmt.Sprintf("failed to encrypt payload: %v", err))
+	}
+	return data, nil
+}
+
+// decryptPayload decrypts a payload field encrypted by encryptPayload
+func decryptPayload(keyProvider encryption.KeyProvider, payload []byte) ([]byte, error) {
+	if len(payload) == 0 {
+		return payload, nil
+	}
+	data, err := encryption.Decrypt(keyProvider, payload)
+	if err != nil {
+		return nil, NewCadenceDeserializationError(fmt.Sprintf("failed to decrypt payload: %v", err))
+	}
+	return data, nil
+}
+
+func encryptPayloads(keyProvider encryption.KeyProvider, keyID string, payloads map[string][]byte) (map[string][]byte, error) {
+	if len(payloads) == 0 {
+		return payloads, nil
+	}
+	encrypted := make(map[string][]byte, len(payloads))
+	for key, payload := range payloads {
+		data, err := encryptPayload(keyProvider, keyID, payload)
+		if err != nil {
+			return nil, err
+		}
+		encrypted[key] = data
+	}
+	return encrypted, nil
+}
+
+func decryptPayloads(keyProvider encryption.KeyProvider, payloads map[string][]byte) (map[string][]byte, error) {
+	if len(payloads) == 0 {
+		return payloads, nil
+	}
+	decrypted := make(map[string][]byte, len(payloads))
+	for key, payload := range payloads {
+		data, err := decryptPayload(keyProvider, payload)
+		if err != nil {
+			return nil, err
+		}
+		decrypted[key] = data
+	}
+	return decrypted, nil
+}
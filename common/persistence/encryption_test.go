@@ -0,0 +1,182 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/encryption"
+)
+
+func newTestKeyProvider(t *testing.T, activeKeys map[string]string) *encryption.MockKeyProvider {
+	keys := map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+		"key-2": bytes.Repeat([]byte{2}, 32),
+	}
+	provider := encryption.NewMockKeyProvider(gomock.NewController(t))
+	provider.EXPECT().Key(gomock.Any()).DoAndReturn(func(keyID string) ([]byte, error) {
+		if key, ok := keys[keyID]; ok {
+			return key, nil
+		}
+		return nil, encryption.ErrKeyNotFound
+	}).AnyTimes()
+	provider.EXPECT().ActiveKeyID(gomock.Any()).DoAndReturn(func(domainName string) string {
+		return activeKeys[domainName]
+	}).AnyTimes()
+	return provider
+}
+
+func TestEncryptDataBlob(t *testing.T) {
+	provider := newTestKeyProvider(t, map[string]string{"encrypted-domain": "key-1"})
+	blob := NewDataBlob([]byte("activity input"), common.EncodingTypeThriftRWZstd)
+
+	encrypted, err := EncryptDataBlob(provider, blob, "encrypted-domain")
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedEncoding(encrypted.Encoding))
+	assert.Equal(t, encrypted.Encoding, encrypted.GetEncoding())
+	assert.Equal(t, common.EncodingTypeThriftRWZstd, DecryptedEncoding(encrypted.Encoding))
+	assert.Equal(t, "key-1", encrypted.GetEncryptionKeyID())
+	assert.NotContains(t, string(encrypted.Data), "activity input")
+
+	// blobs are encrypted only once
+	encryptedAgain, err := EncryptDataBlob(provider, encrypted, "encrypted-domain")
+	require.NoError(t, err)
+	assert.Equal(t, encrypted, encryptedAgain)
+
+	decrypted, err := DecryptDataBlob(provider, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, blob, decrypted)
+
+	_, err = DecryptDataBlob(nil, encrypted)
+	assert.IsType(t, &CadenceDeserializationError{}, err)
+
+	t.Run("not encrypted", func(t *testing.T) {
+		notEncrypted, err := EncryptDataBlob(provider, blob, "plaintext-domain")
+		require.NoError(t, err)
+		assert.Equal(t, blob, notEncrypted)
+		assert.Empty(t, notEncrypted.GetEncryptionKeyID())
+
+		notEncrypted, err = EncryptDataBlob(nil, blob, "encrypted-domain")
+		require.NoError(t, err)
+		assert.Equal(t, blob, notEncrypted)
+
+		decrypted, err := DecryptDataBlob(nil, blob)
+		require.NoError(t, err)
+		assert.Equal(t, blob, decrypted)
+	})
+}
+
+func TestReencryptDataBlob(t *testing.T) {
+	oldProvider := newTestKeyProvider(t, map[string]string{"domain": "key-1"})
+	newProvider := newTestKeyProvider(t, map[string]string{"domain": "key-2"})
+	blob := NewDataBlob([]byte("activity input"), common.EncodingTypeThriftRW)
+
+	encrypted, err := EncryptDataBlob(oldProvider, blob, "domain")
+	require.NoError(t, err)
+
+	unchanged, changed, err := ReencryptDataBlob(oldProvider, encrypted, "domain")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, encrypted, unchanged)
+
+	reencrypted, changed, err := ReencryptDataBlob(newProvider, encrypted, "domain")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "key-2", reencrypted.GetEncryptionKeyID())
+	decrypted, err := DecryptDataBlob(newProvider, reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, blob, decrypted)
+
+	// plaintext blobs are encrypted, and encrypted blobs are decrypted once the domain is no longer encrypted
+	reencrypted, changed, err = ReencryptDataBlob(newProvider, blob, "domain")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "key-2", reencrypted.GetEncryptionKeyID())
+
+	decrypted, changed, err = ReencryptDataBlob(newProvider, reencrypted, "other-domain")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, blob, decrypted)
+}
+
+func TestDeserializeEncryptedEvents(t *testing.T) {
+	provider := newTestKeyProvider(t, map[string]string{"domain": "key-1"})
+	serializer := NewPayloadSerializerWithKeyProvider(provider)
+	events := generateTestHistoryEventBatch()
+
+	for _, encoding := range []common.EncodingType{
+		common.EncodingTypeThriftRW,
+		common.EncodingTypeThriftRWZstd,
+	} {
+		blob, err := serializer.SerializeBatchEvents(events, encoding)
+		require.NoError(t, err)
+		encrypted, err := EncryptDataBlob(provider, blob, "domain")
+		require.NoError(t, err)
+
+		deserialized, err := serializer.DeserializeBatchEvents(encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, events, deserialized)
+
+		_, err = NewPayloadSerializer().DeserializeBatchEvents(encrypted)
+		assert.Error(t, err)
+	}
+}
+
+func TestEncryptExecutionInfo(t *testing.T) {
+	provider := newTestKeyProvider(t, map[string]string{"encrypted-domain": "key-1"})
+	manager := &executionManagerImpl{
+		serializer:  NewPayloadSerializerWithKeyProvider(provider),
+		keyProvider: provider,
+	}
+	// plaintext payloads which look like encrypted ones are not mistaken for them
+	executionContext := []byte("\x00CENC\x01 execution context")
+	memo := map[string][]byte{"key": []byte("\x00CENC\x01 memo")}
+
+	for _, domainName := range []string{"encrypted-domain", "plaintext-domain"} {
+		t.Run(domainName, func(t *testing.T) {
+			info, err := manager.SerializeExecutionInfo(&WorkflowExecutionInfo{
+				ExecutionContext: executionContext,
+				Memo:             memo,
+			}, &ExecutionStats{}, common.EncodingTypeThriftRW)
+			require.NoError(t, err)
+			require.NoError(t, manager.encryptExecutionInfo(info, domainName))
+			if domainName == "encrypted-domain" {
+				assert.True(t, IsEncryptedEncoding(info.AutoResetPoints.Encoding))
+				assert.NotContains(t, string(info.ExecutionContext), "execution context")
+				assert.NotContains(t, string(info.Memo["key"]), "memo")
+			} else {
+				assert.False(t, IsEncryptedEncoding(info.AutoResetPoints.Encoding))
+				assert.Equal(t, executionContext, info.ExecutionContext)
+			}
+
+			decrypted, _, err := manager.DeserializeExecutionInfo(info)
+			require.NoError(t, err)
+			assert.Equal(t, executionContext, decrypted.ExecutionContext)
+			assert.Equal(t, memo, decrypted.Memo)
+		})
+	}
+}
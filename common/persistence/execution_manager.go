@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/types"
 )
@@ -37,6 +38,7 @@ type (
 		persistence   ExecutionStore
 		statsComputer statsComputer
 		logger        log.Logger
+		keyProvider   encryption.KeyProvider
 	}
 )
 
@@ -47,12 +49,14 @@ func NewExecutionManagerImpl(
 	persistence ExecutionStore,
 	logger log.Logger,
 	serializer PayloadSerializer,
+	keyProvider encryption.KeyProvider,
 ) ExecutionManager {
 	return &executionManagerImpl{
 		serializer:    serializer,
 		persistence:   persistence,
 		statsComputer: statsComputer{},
 		logger:        logger,
+		keyProvider:   keyProvider,
 	}
 }
 
@@ -138,6 +142,16 @@ func (m *executionManagerImpl) DeserializeExecutionInfo(
 		return nil, nil, err
 	}
 
+	executionContext, memo := info.ExecutionContext, info.Memo
+	if isEncryptedDataBlob(info.AutoResetPoints) {
+		if executionContext, err = decryptPayload(m.keyProvider, executionContext); err != nil {
+			return nil, nil, err
+		}
+		if memo, err = decryptPayloads(m.keyProvider, memo); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	newInfo := &WorkflowExecutionInfo{
 		CompletionEvent: completionEvent,
 
@@ -155,7 +169,7 @@ func (m *executionManagerImpl) DeserializeExecutionInfo(
 		WorkflowTypeName:                   info.WorkflowTypeName,
 		WorkflowTimeout:                    int32(info.WorkflowTimeout.Seconds()),
 		DecisionStartToCloseTimeout:        int32(info.DecisionStartToCloseTimeout.Seconds()),
-		ExecutionContext:                   info.ExecutionContext,
+		ExecutionContext:                   executionContext,
 		State:                              info.State,
 		CloseStatus:                        info.CloseStatus,
 		LastFirstEventID:                   info.LastFirstEventID,
@@ -195,7 +209,7 @@ func (m *executionManagerImpl) DeserializeExecutionInfo(
 		ExpirationSeconds:                  int32(info.ExpirationInterval.Seconds()),
 		AutoResetPoints:                    autoResetPoints,
 		SearchAttributes:                   info.SearchAttributes,
-		Memo:                               info.Memo,
+		Memo:                               memo,
 		PartitionConfig:                    info.PartitionConfig,
 	}
 	newStats := &ExecutionStats{
@@ -331,12 +345,18 @@ func (m *executionManagerImpl) UpdateWorkflowExecution(
 	if err != nil {
 		return nil, err
 	}
+	if err := m.encryptWorkflowMutation(serializedWorkflowMutation, request.DomainName); err != nil {
+		return nil, err
+	}
 	var serializedNewWorkflowSnapshot *InternalWorkflowSnapshot
 	if request.NewWorkflowSnapshot != nil {
 		serializedNewWorkflowSnapshot, err = m.SerializeWorkflowSnapshot(request.NewWorkflowSnapshot, request.Encoding)
 		if err != nil {
 			return nil, err
 		}
+		if err := m.encryptWorkflowSnapshot(serializedNewWorkflowSnapshot, request.DomainName); err != nil {
+			return nil, err
+		}
 	}
 
 	newRequest := &InternalUpdateWorkflowExecutionRequest{
@@ -540,12 +560,18 @@ func (m *executionManagerImpl) ConflictResolveWorkflowExecution(
 	if err != nil {
 		return nil, err
 	}
+	if err := m.encryptWorkflowSnapshot(serializedResetWorkflowSnapshot, request.DomainName); err != nil {
+		return nil, err
+	}
 	var serializedCurrentWorkflowMutation *InternalWorkflowMutation
 	if request.CurrentWorkflowMutation != nil {
 		serializedCurrentWorkflowMutation, err = m.SerializeWorkflowMutation(request.CurrentWorkflowMutation, request.Encoding)
 		if err != nil {
 			return nil, err
 		}
+		if err := m.encryptWorkflowMutation(serializedCurrentWorkflowMutation, request.DomainName); err != nil {
+			return nil, err
+		}
 	}
 	var serializedNewWorkflowMutation *InternalWorkflowSnapshot
 	if request.NewWorkflowSnapshot != nil {
@@ -553,6 +579,9 @@ func (m *executionManagerImpl) ConflictResolveWorkflowExecution(
 		if err != nil {
 			return nil, err
 		}
+		if err := m.encryptWorkflowSnapshot(serializedNewWorkflowMutation, request.DomainName); err != nil {
+			return nil, err
+		}
 	}
 
 	newRequest := &InternalConflictResolveWorkflowExecutionRequest{
@@ -581,12 +610,18 @@ func (m *executionManagerImpl) CreateWorkflowExecution(
 	request *CreateWorkflowExecutionRequest,
 ) (*CreateWorkflowExecutionResponse, error) {
 
-	encoding := common.EncodingTypeThriftRW
+	encoding := request.Encoding
+	if encoding == common.EncodingTypeEmpty {
+		encoding = common.EncodingTypeThriftRW
+	}
 
 	serializedNewWorkflowSnapshot, err := m.SerializeWorkflowSnapshot(&request.NewWorkflowSnapshot, encoding)
 	if err != nil {
 		return nil, err
 	}
+	if err := m.encryptWorkflowSnapshot(serializedNewWorkflowSnapshot, request.DomainName); err != nil {
+		return nil, err
+	}
 
 	newRequest := &InternalCreateWorkflowExecutionRequest{
 		RangeID: request.RangeID,
@@ -755,6 +790,130 @@ func (m *executionManagerImpl) SerializeWorkflowSnapshot(
 	}, nil
 }
 
+// encryptWorkflowMutation encrypts the payloads of a serialized mutation in place
+// with the active key of the domain
+func (m *executionManagerImpl) encryptWorkflowMutation(
+	mutation *InternalWorkflowMutation,
+	domainName string,
+) error {
+
+	if m.keyProvider == nil {
+		return nil
+	}
+	if err := m.encryptExecutionInfo(mutation.ExecutionInfo, domainName); err != nil {
+		return err
+	}
+	if err := m.encryptActivityInfos(mutation.UpsertActivityInfos, domainName); err != nil {
+		return err
+	}
+	if err := m.encryptChildExecutionInfos(mutation.UpsertChildExecutionInfos, domainName); err != nil {
+		return err
+	}
+	newBufferedEvents, err := EncryptDataBlob(m.keyProvider, mutation.NewBufferedEvents, domainName)
+	if err != nil {
+		return err
+	}
+	mutation.NewBufferedEvents = newBufferedEvents
+	return nil
+}
+
+// encryptWorkflowSnapshot encrypts the payloads of a serialized snapshot in place
+// with the active key of the domain
+func (m *executionManagerImpl) encryptWorkflowSnapshot(
+	snapshot *InternalWorkflowSnapshot,
+	domainName string,
+) error {
+
+	if m.keyProvider == nil {
+		return nil
+	}
+	if err := m.encryptExecutionInfo(snapshot.ExecutionInfo, domainName); err != nil {
+		return err
+	}
+	if err := m.encryptActivityInfos(snapshot.ActivityInfos, domainName); err != nil {
+		return err
+	}
+	return m.encryptChildExecutionInfos(snapshot.ChildExecutionInfos, domainName)
+}
+
+func (m *executionManagerImpl) encryptExecutionInfo(
+	info *InternalWorkflowExecutionInfo,
+	domainName string,
+) error {
+
+	if info == nil {
+		return nil
+	}
+	completionEvent, err := EncryptDataBlob(m.keyProvider, info.CompletionEvent, domainName)
+	if err != nil {
+		return err
+	}
+	info.CompletionEvent = completionEvent
+
+	// the reset points blob is written with every execution info, so its encoding records
+	// whether the payload fields of the execution info are encrypted
+	autoResetPoints, err := EncryptDataBlob(m.keyProvider, info.AutoResetPoints, domainName)
+	if err != nil {
+		return err
+	}
+	info.AutoResetPoints = autoResetPoints
+	if !isEncryptedDataBlob(autoResetPoints) {
+		return nil
+	}
+	keyID := autoResetPoints.GetEncryptionKeyID()
+	executionContext, err := encryptPayload(m.keyProvider, keyID, info.ExecutionContext)
+	if err != nil {
+		return err
+	}
+	memo, err := encryptPayloads(m.keyProvider, keyID, info.Memo)
+	if err != nil {
+		return err
+	}
+	info.ExecutionContext = executionContext
+	info.Memo = memo
+	return nil
+}
+
+func (m *executionManagerImpl) encryptActivityInfos(
+	infos []*InternalActivityInfo,
+	domainName string,
+) error {
+
+	for _, info := range infos {
+		scheduledEvent, err := EncryptDataBlob(m.keyProvider, info.ScheduledEvent, domainName)
+		if err != nil {
+			return err
+		}
+		startedEvent, err := EncryptDataBlob(m.keyProvider, info.StartedEvent, domainName)
+		if err != nil {
+			return err
+		}
+		info.ScheduledEvent = scheduledEvent
+		info.StartedEvent = startedEvent
+	}
+	return nil
+}
+
+func (m *executionManagerImpl) encryptChildExecutionInfos(
+	infos []*InternalChildExecutionInfo,
+	domainName string,
+) error {
+
+	for _, info := range infos {
+		initiatedEvent, err := EncryptDataBlob(m.keyProvider, info.InitiatedEvent, domainName)
+		if err != nil {
+			return err
+		}
+		startedEvent, err := EncryptDataBlob(m.keyProvider, info.StartedEvent, domainName)
+		if err != nil {
+			return err
+		}
+		info.InitiatedEvent = initiatedEvent
+		info.StartedEvent = startedEvent
+	}
+	return nil
+}
+
 func (m *executionManagerImpl) SerializeVersionHistories(
 	versionHistories *VersionHistories,
 	encoding common.EncodingType,
@@ -185,7 +185,7 @@ func TestExecutionManager_ProxyStoreMethods(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockedStore := NewMockExecutionStore(ctrl)
 			tc.prepareMocks(mockedStore)
-			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil)
+			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil, nil)
 			v := reflect.ValueOf(manager)
 			method := v.MethodByName(tc.method)
 			methodType := method.Type()
@@ -261,7 +261,7 @@ func TestGetReplicationTasks(t *testing.T) {
 
 			mockedStore := NewMockExecutionStore(ctrl)
 			tc.prepareMocks(mockedStore)
-			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil)
+			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil, nil)
 			res, err := manager.GetReplicationTasks(context.Background(), &GetReplicationTasksRequest{})
 			tc.checkRes(t, res, err)
 		})
@@ -273,7 +273,7 @@ func TestExecutionManager_GetWorkflowExecution(t *testing.T) {
 	mockedStore := NewMockExecutionStore(ctrl)
 	mockedSerializer := NewMockPayloadSerializer(ctrl)
 
-	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer)
+	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer, nil)
 
 	request := &GetWorkflowExecutionRequest{
 		DomainID: testDomainID,
@@ -360,7 +360,7 @@ func TestExecutionManager_GetWorkflowExecution_NoWorkflow(t *testing.T) {
 	mockedStore := NewMockExecutionStore(ctrl)
 	mockedSerializer := NewMockPayloadSerializer(ctrl)
 
-	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer)
+	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer, nil)
 
 	request := &GetWorkflowExecutionRequest{
 		DomainID: "testDomain",
@@ -383,7 +383,7 @@ func TestExecutionManager_UpdateWorkflowExecution(t *testing.T) {
 	mockedStore := NewMockExecutionStore(ctrl)
 	mockedSerializer := NewMockPayloadSerializer(ctrl)
 
-	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer)
+	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer, nil)
 
 	expectedInfo := sampleInternalWorkflowMutation()
 
@@ -579,7 +579,7 @@ func TestSerializeWorkflowSnapshot(t *testing.T) {
 
 			mockedSerializer := NewMockPayloadSerializer(ctrl)
 			tc.prepareMocks(mockedSerializer)
-			manager := NewExecutionManagerImpl(nil, testlogger.New(t), mockedSerializer).(*executionManagerImpl)
+			manager := NewExecutionManagerImpl(nil, testlogger.New(t), mockedSerializer, nil).(*executionManagerImpl)
 			res, err := manager.SerializeWorkflowSnapshot(tc.input, common.EncodingTypeThriftRW)
 			tc.checkRes(t, res, err)
 		})
@@ -623,7 +623,7 @@ func TestDeserializeBufferedEvents(t *testing.T) {
 
 			tc.prepareMocks(mockedSerializer)
 
-			manager := NewExecutionManagerImpl(nil, testlogger.New(t), mockedSerializer).(*executionManagerImpl)
+			manager := NewExecutionManagerImpl(nil, testlogger.New(t), mockedSerializer, nil).(*executionManagerImpl)
 
 			events := []*DataBlob{
 				sampleEventData(),
@@ -639,7 +639,7 @@ func TestDeserializeBufferedEvents(t *testing.T) {
 func TestPutReplicationTaskToDLQ(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockedStore := NewMockExecutionStore(ctrl)
-	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil)
+	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil, nil)
 
 	now := time.Now().UTC().Round(time.Second)
 
@@ -672,7 +672,7 @@ func TestPutReplicationTaskToDLQ(t *testing.T) {
 func TestGetReplicationTasksFromDLQ(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockedStore := NewMockExecutionStore(ctrl)
-	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil)
+	manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), nil, nil)
 
 	request := &GetReplicationTasksFromDLQRequest{
 		SourceClusterName: "test-cluster",
@@ -975,7 +975,7 @@ func TestListConcreteExecutions(t *testing.T) {
 
 			tc.prepareMocks(mockedStore, mockedSerializer)
 
-			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer)
+			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer, nil)
 
 			res, err := manager.ListConcreteExecutions(context.Background(), request)
 
@@ -987,6 +987,7 @@ func TestListConcreteExecutions(t *testing.T) {
 func TestCreateWorkflowExecution(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
+		encoding     common.EncodingType
 		prepareMocks func(*MockExecutionStore, *MockPayloadSerializer)
 		checkRes     func(*testing.T, *CreateWorkflowExecutionResponse, error)
 	}{
@@ -1057,6 +1058,16 @@ func TestCreateWorkflowExecution(t *testing.T) {
 				assert.ErrorIs(t, err, assert.AnError)
 			},
 		},
+		{
+			name:     "encoding of request",
+			encoding: common.EncodingTypeThriftRWZstd,
+			prepareMocks: func(mockedStore *MockExecutionStore, mockedSerializer *MockPayloadSerializer) {
+				mockedSerializer.EXPECT().SerializeEvent(completionEvent(), common.EncodingTypeThriftRWZstd).Return(nil, assert.AnError).Times(1)
+			},
+			checkRes: func(t *testing.T, response *CreateWorkflowExecutionResponse, err error) {
+				assert.ErrorIs(t, err, assert.AnError)
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
@@ -1072,9 +1083,10 @@ func TestCreateWorkflowExecution(t *testing.T) {
 				PreviousLastWriteVersion: 1,
 				NewWorkflowSnapshot:      *sampleWorkflowSnapshot(),
 				WorkflowRequestMode:      CreateWorkflowRequestModeReplicated,
+				Encoding:                 tc.encoding,
 			}
 
-			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer)
+			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer, nil)
 
 			res, err := manager.CreateWorkflowExecution(context.Background(), request)
 
@@ -1219,7 +1231,7 @@ func TestConflictResolveWorkflowExecution(t *testing.T) {
 
 			tc.prepareMocks(mockedStore, mockedSerializer)
 
-			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer)
+			manager := NewExecutionManagerImpl(mockedStore, testlogger.New(t), mockedSerializer, nil)
 
 			res, err := manager.ConflictResolveWorkflowExecution(context.Background(), tc.request)
 
@@ -28,8 +28,10 @@ import (
 
 	workflow "github.com/uber/cadence/.gen/go/shared"
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/blobstore"
 	"github.com/uber/cadence/common/codec"
 	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/types"
@@ -45,6 +47,10 @@ type (
 		thriftEncoder         codec.BinaryEncoder
 		pagingTokenSerializer *jsonHistoryTokenSerializer
 		transactionSizeLimit  dynamicconfig.IntPropertyFn
+		keyProvider           encryption.KeyProvider
+		// blobstoreClient is nil if payload offloading is not configured
+		blobstoreClient         blobstore.Client
+		payloadOffloadThreshold dynamicconfig.IntPropertyFnWithDomainFilter
 	}
 )
 
@@ -64,15 +70,21 @@ func NewHistoryV2ManagerImpl(
 	persistence HistoryStore,
 	logger log.Logger,
 	transactionSizeLimit dynamicconfig.IntPropertyFn,
+	keyProvider encryption.KeyProvider,
+	blobstoreClient blobstore.Client,
+	payloadOffloadThreshold dynamicconfig.IntPropertyFnWithDomainFilter,
 ) HistoryManager {
 
 	return &historyV2ManagerImpl{
-		historySerializer:     NewPayloadSerializer(),
-		persistence:           persistence,
-		logger:                logger,
-		thriftEncoder:         codec.NewThriftRWEncoder(),
-		pagingTokenSerializer: newJSONHistoryTokenSerializer(),
-		transactionSizeLimit:  transactionSizeLimit,
+		historySerializer:       NewPayloadSerializerWithKeyProvider(keyProvider),
+		persistence:             persistence,
+		logger:                  logger,
+		thriftEncoder:           codec.NewThriftRWEncoder(),
+		pagingTokenSerializer:   newJSONHistoryTokenSerializer(),
+		transactionSizeLimit:    transactionSizeLimit,
+		keyProvider:             keyProvider,
+		blobstoreClient:         blobstoreClient,
+		payloadOffloadThreshold: payloadOffloadThreshold,
 	}
 }
 
@@ -151,7 +163,20 @@ func (m *historyV2ManagerImpl) DeleteHistoryBranch(
 		ShardID:    shardID,
 	}
 
-	return m.persistence.DeleteHistoryBranch(ctx, req)
+	if m.blobstoreClient == nil {
+		return m.persistence.DeleteHistoryBranch(ctx, req)
+	}
+
+	// the offloaded payloads are looked up before the nodes referencing them are deleted
+	offloadedKeys, err := m.getOffloadedPayloadKeysToDelete(ctx, req.BranchInfo, shardID)
+	if err != nil {
+		return err
+	}
+	if err := m.persistence.DeleteHistoryBranch(ctx, req); err != nil {
+		return err
+	}
+	m.deleteOffloadedPayloads(ctx, offloadedKeys)
+	return nil
 }
 
 // GetHistoryTree returns all branch information of a tree
@@ -225,10 +250,53 @@ func (m *historyV2ManagerImpl) AppendHistoryNodes(
 	}
 
 	// nodeID will be the first eventID
-	blob, err := m.historySerializer.SerializeBatchEvents(request.Events, request.Encoding)
+	blob, err := m.historySerializer.SerializeBatchEvents(request.Events, UncompressedEncoding(request.Encoding))
 	if err != nil {
 		return nil, err
 	}
+	shardID, err := getShardID(request.ShardID)
+	if err != nil {
+		m.logger.Error("shardID is not set in append history nodes operation", tag.Error(err))
+		return nil, &types.InternalServiceError{
+			Message: err.Error(),
+		}
+	}
+	// large payloads are stored in the blobstore, the node only keeps the events without them
+	offloadedBlob, offloadedKey, err := m.offloadPayloads(ctx, request, branch.GetTreeID(), branch.GetBranchID(), nodeID, blob)
+	if err != nil {
+		return nil, err
+	}
+	persistedBlob := blob
+	if offloadedBlob != nil {
+		persistedBlob = offloadedBlob
+	}
+	persistedBlob, err = m.appendHistoryNode(ctx, request, &branch, nodeID, persistedBlob, offloadedBlob != nil, shardID)
+	if err != nil && len(offloadedKey) > 0 && !IsTimeoutError(err) {
+		// the offloaded payloads are only referenced by the node, unless a timeout leaves it unknown whether it's written
+		m.deleteOffloadedPayloads(ctx, []string{offloadedKey})
+	}
+	if persistedBlob == nil {
+		return nil, err
+	}
+	return &AppendHistoryNodesResponse{
+		DataBlob:      *blob,
+		PersistedSize: len(persistedBlob.Data),
+	}, err
+}
+
+// appendHistoryNode compresses, encrypts and writes the blob of a history node.
+// It returns the blob to write, which is nil if the node isn't written because the blob is invalid.
+func (m *historyV2ManagerImpl) appendHistoryNode(
+	ctx context.Context,
+	request *AppendHistoryNodesRequest,
+	branch *workflow.HistoryBranch,
+	nodeID int64,
+	blob *DataBlob,
+	offloaded bool,
+	shardID int,
+) (*DataBlob, error) {
+
+	// the size limit is checked before compression so that it doesn't depend on the encoding
 	size := len(blob.Data)
 	sizeLimit := m.transactionSizeLimit()
 	if size > sizeLimit {
@@ -236,28 +304,27 @@ func (m *historyV2ManagerImpl) AppendHistoryNodes(
 			Msg: fmt.Sprintf("transaction size of %v bytes exceeds limit of %v bytes", size, sizeLimit),
 		}
 	}
-	shardID, err := getShardID(request.ShardID)
+	persistedBlob, err := CompressDataBlob(blob, request.Encoding)
 	if err != nil {
-		m.logger.Error("shardID is not set in append history nodes operation", tag.Error(err))
-		return nil, &types.InternalServiceError{
-			Message: err.Error(),
-		}
+		return nil, err
+	}
+	if offloaded {
+		persistedBlob = &DataBlob{Data: persistedBlob.Data, Encoding: persistedBlob.Encoding + offloadedEncodingSuffix}
+	}
+	persistedBlob, err = EncryptDataBlob(m.keyProvider, persistedBlob, request.DomainName)
+	if err != nil {
+		return nil, err
 	}
 	req := &InternalAppendHistoryNodesRequest{
 		IsNewBranch:   request.IsNewBranch,
 		Info:          request.Info,
-		BranchInfo:    *thrift.ToHistoryBranch(&branch),
+		BranchInfo:    *thrift.ToHistoryBranch(branch),
 		NodeID:        nodeID,
-		Events:        blob,
+		Events:        persistedBlob,
 		TransactionID: request.TransactionID,
 		ShardID:       shardID,
 	}
-
-	err = m.persistence.AppendHistoryNodes(ctx, req)
-
-	return &AppendHistoryNodesResponse{
-		DataBlob: *blob,
-	}, err
+	return persistedBlob, m.persistence.AppendHistoryNodes(ctx, req)
 }
 
 // ReadHistoryBranchByBatch returns history node data for a branch by batch
@@ -300,11 +367,23 @@ func (m *historyV2ManagerImpl) ReadRawHistoryBranch(
 	request *ReadHistoryBranchRequest,
 ) (*ReadRawHistoryBranchResponse, error) {
 
-	dataBlobs, token, dataSize, _, err := m.readRawHistoryBranch(ctx, request)
+	dataBlobs, token, _, _, err := m.readRawHistoryBranch(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
+	// raw history is returned to other services and clusters, which don't know encrypted or compressed encodings
+	dataSize := 0
+	for i, dataBlob := range dataBlobs {
+		if dataBlob, err = DecryptDataBlob(m.keyProvider, dataBlob); err != nil {
+			return nil, err
+		}
+		if dataBlobs[i], err = DecompressDataBlob(dataBlob); err != nil {
+			return nil, err
+		}
+		dataSize += len(dataBlobs[i].Data)
+	}
+
 	nextPageToken, err := m.serializeToken(token)
 	if err != nil {
 		return nil, err
@@ -325,21 +404,121 @@ func (m *historyV2ManagerImpl) GetAllHistoryTreeBranches(
 	return m.persistence.GetAllHistoryTreeBranches(ctx, request)
 }
 
+// ReencryptHistoryBranch rewrites the history nodes of a branch which are not encrypted with the active key of the domain
+// The nodes of the ancestors of the branch are rewritten as well, they are shared with the branches forked from them
+func (m *historyV2ManagerImpl) ReencryptHistoryBranch(
+	ctx context.Context,
+	request *ReencryptHistoryBranchRequest,
+) (*ReencryptHistoryBranchResponse, error) {
+
+	if m.keyProvider == nil {
+		return nil, &InvalidPersistenceRequestError{
+			Msg: "payload encryption is not configured",
+		}
+	}
+
+	readRequest := &ReadHistoryBranchRequest{
+		BranchToken:   request.BranchToken,
+		MinEventID:    common.FirstEventID,
+		MaxEventID:    common.EndEventID,
+		PageSize:      request.PageSize,
+		NextPageToken: request.NextPageToken,
+		ShardID:       request.ShardID,
+		DomainName:    request.DomainName,
+	}
+	internalRequest, internalResponse, token, err := m.readHistoryBranchNodes(ctx, readRequest)
+	if err != nil {
+		return nil, err
+	}
+	if len(internalResponse.Nodes) != len(internalResponse.History) {
+		return nil, &types.InternalServiceError{
+			Message: "history store doesn't return the nodes of the history events",
+		}
+	}
+
+	reencryptedCount := 0
+	for i, dataBlob := range internalResponse.History {
+		if isOffloadedBlob(dataBlob) {
+			key := offloadedPayloadsKey(internalRequest.TreeID, internalRequest.BranchID, internalResponse.Nodes[i].NodeID, internalResponse.Nodes[i].TransactionID)
+			if err := m.reencryptOffloadedPayloads(ctx, key, request.DomainName); err != nil {
+				return nil, err
+			}
+		}
+		reencryptedBlob, changed, err := ReencryptDataBlob(m.keyProvider, dataBlob, request.DomainName)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			continue
+		}
+		err = m.persistence.UpdateHistoryNode(ctx, &InternalUpdateHistoryNodeRequest{
+			TreeID:        internalRequest.TreeID,
+			BranchID:      internalRequest.BranchID,
+			NodeID:        internalResponse.Nodes[i].NodeID,
+			TransactionID: internalResponse.Nodes[i].TransactionID,
+			Events:        reencryptedBlob,
+			ShardID:       internalRequest.ShardID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		reencryptedCount++
+	}
+
+	nextPageToken, err := m.serializeToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReencryptHistoryBranchResponse{
+		ReencryptedCount: reencryptedCount,
+		NextPageToken:    nextPageToken,
+	}, nil
+}
+
 func (m *historyV2ManagerImpl) readRawHistoryBranch(
 	ctx context.Context,
 	request *ReadHistoryBranchRequest,
 ) ([]*DataBlob, *historyV2PagingToken, int, log.Logger, error) {
 
+	internalRequest, resp, token, err := m.readHistoryBranchNodes(ctx, request)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+
+	dataSize := 0
+	for _, dataBlob := range resp.History {
+		dataSize += len(dataBlob.Data)
+	}
+	// the events of the nodes whose payloads are offloaded are read from the blobstore
+	dataBlobs, err := m.resolveOffloadedPayloads(ctx, internalRequest.TreeID, internalRequest.BranchID, resp.History, resp.Nodes)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+
+	// NOTE: in this method, we need to make sure eventVersion is NOT
+	// decreasing(otherwise we skip the events), eventID should be continuous(otherwise return error)
+	logger := m.logger.WithTags(tag.WorkflowBranchID(internalRequest.BranchID), tag.WorkflowTreeID(internalRequest.TreeID))
+
+	return dataBlobs, token, dataSize, logger, nil
+}
+
+// readHistoryBranchNodes reads a page of history nodes of the branch range of the paging token
+func (m *historyV2ManagerImpl) readHistoryBranchNodes(
+	ctx context.Context,
+	request *ReadHistoryBranchRequest,
+) (*InternalReadHistoryBranchRequest, *InternalReadHistoryBranchResponse, *historyV2PagingToken, error) {
+
 	var branch workflow.HistoryBranch
 	err := m.thriftEncoder.Decode(request.BranchToken, &branch)
 	if err != nil {
-		return nil, nil, 0, nil, err
+		return nil, nil, nil, err
 	}
 	treeID := *branch.TreeID
 	branchID := *branch.BranchID
 
 	if request.PageSize <= 0 || request.MinEventID >= request.MaxEventID {
-		return nil, nil, 0, nil, &InvalidPersistenceRequestError{
+		return nil, nil, nil, &InvalidPersistenceRequestError{
 			Msg: fmt.Sprintf(
 				"no events can be found for pageSize %v, minEventID %v, maxEventID: %v",
 				request.PageSize,
@@ -355,7 +534,7 @@ func (m *historyV2ManagerImpl) readRawHistoryBranch(
 		defaultLastEventID,
 	)
 	if err != nil {
-		return nil, nil, 0, nil, err
+		return nil, nil, nil, err
 	}
 
 	allBRs := branch.Ancestors
@@ -388,7 +567,7 @@ func (m *historyV2ManagerImpl) readRawHistoryBranch(
 		}
 
 		if token.CurrentRangeIndex == notStartedIndex {
-			return nil, nil, 0, nil, &types.InternalDataInconsistencyError{
+			return nil, nil, nil, &types.InternalDataInconsistencyError{
 				Message: "branchRange is corrupted",
 			}
 		}
@@ -404,7 +583,7 @@ func (m *historyV2ManagerImpl) readRawHistoryBranch(
 	shardID, err := getShardID(request.ShardID)
 	if err != nil {
 		m.logger.Error("shardID is not set in read history branch operation", tag.Error(err))
-		return nil, nil, 0, nil, &types.InternalServiceError{Message: err.Error()}
+		return nil, nil, nil, &types.InternalServiceError{Message: err.Error()}
 	}
 	req := &InternalReadHistoryBranchRequest{
 		TreeID:            treeID,
@@ -420,28 +599,18 @@ func (m *historyV2ManagerImpl) readRawHistoryBranch(
 
 	resp, err := m.persistence.ReadHistoryBranch(ctx, req)
 	if err != nil {
-		return nil, nil, 0, nil, err
+		return nil, nil, nil, err
 	}
 	// TODO: consider if it's possible to remove this branch
 	if len(resp.History) == 0 && len(request.NextPageToken) == 0 {
-		return nil, nil, 0, nil, &types.EntityNotExistsError{Message: "Workflow execution history not found."}
-	}
-
-	dataBlobs := resp.History
-	dataSize := 0
-	for _, dataBlob := range resp.History {
-		dataSize += len(dataBlob.Data)
+		return nil, nil, nil, &types.EntityNotExistsError{Message: "Workflow execution history not found."}
 	}
 
 	token.StoreToken = resp.NextPageToken
 	token.LastNodeID = resp.LastNodeID
 	token.LastTransactionID = resp.LastTransactionID
 
-	// NOTE: in this method, we need to make sure eventVersion is NOT
-	// decreasing(otherwise we skip the events), eventID should be continuous(otherwise return error)
-	logger := m.logger.WithTags(tag.WorkflowBranchID(*branch.BranchID), tag.WorkflowTreeID(*branch.TreeID))
-
-	return dataBlobs, token, dataSize, logger, nil
+	return req, resp, token, nil
 }
 
 func (m *historyV2ManagerImpl) readHistoryBranch(
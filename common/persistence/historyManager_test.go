@@ -0,0 +1,366 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/blobstore"
+	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/types"
+)
+
+// fakeHistoryStore keeps the appended nodes of a single branch in memory
+type fakeHistoryStore struct {
+	HistoryStore
+	nodes     []*DataBlob
+	nodeInfos []InternalHistoryNode
+	appendErr error
+}
+
+func (s *fakeHistoryStore) AppendHistoryNodes(_ context.Context, request *InternalAppendHistoryNodesRequest) error {
+	if s.appendErr != nil {
+		return s.appendErr
+	}
+	s.nodes = append(s.nodes, request.Events)
+	s.nodeInfos = append(s.nodeInfos, InternalHistoryNode{NodeID: request.NodeID, TransactionID: request.TransactionID})
+	return nil
+}
+
+func (s *fakeHistoryStore) ReadHistoryBranch(_ context.Context, _ *InternalReadHistoryBranchRequest) (*InternalReadHistoryBranchResponse, error) {
+	return &InternalReadHistoryBranchResponse{
+		History: append([]*DataBlob{}, s.nodes...),
+		Nodes:   append([]InternalHistoryNode{}, s.nodeInfos...),
+	}, nil
+}
+
+func (s *fakeHistoryStore) GetHistoryTree(_ context.Context, _ *InternalGetHistoryTreeRequest) (*InternalGetHistoryTreeResponse, error) {
+	return &InternalGetHistoryTreeResponse{}, nil
+}
+
+func (s *fakeHistoryStore) DeleteHistoryBranch(_ context.Context, _ *InternalDeleteHistoryBranchRequest) error {
+	s.nodes = nil
+	s.nodeInfos = nil
+	return nil
+}
+
+// fakeBlobstore keeps blobs in memory
+type fakeBlobstore struct {
+	blobstore.Client
+	blobs map[string]blobstore.Blob
+}
+
+func (c *fakeBlobstore) Put(_ context.Context, request *blobstore.PutRequest) (*blobstore.PutResponse, error) {
+	c.blobs[request.Key] = request.Blob
+	return &blobstore.PutResponse{}, nil
+}
+
+func (c *fakeBlobstore) Get(_ context.Context, request *blobstore.GetRequest) (*blobstore.GetResponse, error) {
+	blob, ok := c.blobs[request.Key]
+	if !ok {
+		return nil, fmt.Errorf("blob %v doesn't exist", request.Key)
+	}
+	return &blobstore.GetResponse{Blob: blob}, nil
+}
+
+func (c *fakeBlobstore) Delete(_ context.Context, request *blobstore.DeleteRequest) (*blobstore.DeleteResponse, error) {
+	delete(c.blobs, request.Key)
+	return &blobstore.DeleteResponse{}, nil
+}
+
+func TestHistoryManager_Compression(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeHistoryStore{}
+	manager := NewHistoryV2ManagerImpl(store, testlogger.New(t), dynamicconfig.GetIntPropertyFn(1024*1024), nil, nil, nil)
+	branchToken, err := NewHistoryBranchToken("tree")
+	require.NoError(t, err)
+
+	input := bytes.Repeat([]byte("highly repetitive activity input "), 100)
+	newEvent := func(id int64) *types.HistoryEvent {
+		return &types.HistoryEvent{
+			ID:        id,
+			Version:   1,
+			EventType: types.EventTypeWorkflowExecutionSignaled.Ptr(),
+			WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "signal",
+				Input:      input,
+			},
+		}
+	}
+
+	// an old uncompressed node followed by compressed ones
+	var events []*types.HistoryEvent
+	for i, encoding := range []common.EncodingType{
+		common.EncodingTypeThriftRW,
+		common.EncodingTypeThriftRWZstd,
+		common.EncodingTypeThriftRWSnappy,
+	} {
+		event := newEvent(int64(i + 1))
+		events = append(events, event)
+		resp, err := manager.AppendHistoryNodes(ctx, &AppendHistoryNodesRequest{
+			IsNewBranch: i == 0,
+			BranchToken: branchToken,
+			Events:      []*types.HistoryEvent{event},
+			Encoding:    encoding,
+			ShardID:     common.IntPtr(1),
+		})
+		require.NoError(t, err)
+
+		persisted := store.nodes[len(store.nodes)-1]
+		assert.Equal(t, encoding, persisted.Encoding)
+		assert.Equal(t, common.EncodingTypeThriftRW, resp.DataBlob.Encoding)
+		assert.Equal(t, len(persisted.Data), resp.PersistedSize)
+		if IsCompressedEncoding(encoding) {
+			assert.Less(t, resp.PersistedSize, len(resp.DataBlob.Data))
+		}
+	}
+
+	readRequest := &ReadHistoryBranchRequest{
+		BranchToken: branchToken,
+		MinEventID:  common.FirstEventID,
+		MaxEventID:  int64(len(events) + 1),
+		PageSize:    10,
+		ShardID:     common.IntPtr(1),
+	}
+	readResp, err := manager.ReadHistoryBranch(ctx, readRequest)
+	require.NoError(t, err)
+	assert.Equal(t, events, readResp.HistoryEvents)
+
+	rawResp, err := manager.ReadRawHistoryBranch(ctx, readRequest)
+	require.NoError(t, err)
+	require.Len(t, rawResp.HistoryEventBlobs, len(events))
+	size := 0
+	for _, blob := range rawResp.HistoryEventBlobs {
+		assert.Equal(t, common.EncodingTypeThriftRW, blob.Encoding, "raw history is returned uncompressed")
+		size += len(blob.Data)
+	}
+	assert.Equal(t, size, rawResp.Size)
+}
+
+func TestHistoryManager_PayloadOffload(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeHistoryStore{}
+	blobs := &fakeBlobstore{blobs: map[string]blobstore.Blob{}}
+	provider := newTestKeyProvider(t, map[string]string{"domain": "key-1"})
+	manager := NewHistoryV2ManagerImpl(
+		store,
+		testlogger.New(t),
+		dynamicconfig.GetIntPropertyFn(1024),
+		provider,
+		blobs,
+		dynamicconfig.GetIntPropertyFilteredByDomain(100),
+	)
+	branchToken, err := NewHistoryBranchTokenByBranchID("tree", "branch")
+	require.NoError(t, err)
+
+	largeInput := bytes.Repeat([]byte("a"), 2048)
+	events := []*types.HistoryEvent{
+		{
+			ID:        1,
+			Version:   1,
+			EventType: types.EventTypeWorkflowExecutionSignaled.Ptr(),
+			WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "large signal",
+				Input:      largeInput,
+			},
+		},
+		{
+			ID:        2,
+			Version:   1,
+			EventType: types.EventTypeWorkflowExecutionSignaled.Ptr(),
+			WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "small signal",
+				Input:      []byte("small input"),
+			},
+		},
+	}
+	resp, err := manager.AppendHistoryNodes(ctx, &AppendHistoryNodesRequest{
+		IsNewBranch: true,
+		BranchToken: branchToken,
+		Events:      events,
+		Encoding:    common.EncodingTypeThriftRW,
+		ShardID:     common.IntPtr(1),
+		DomainName:  "domain",
+	})
+	require.NoError(t, err, "the offloaded payload doesn't count towards the transaction size")
+	assert.Equal(t, largeInput, events[0].WorkflowExecutionSignaledEventAttributes.Input, "request events are not modified")
+	assert.Greater(t, len(resp.DataBlob.Data), len(largeInput), "the returned blob keeps the payloads")
+	assert.Less(t, resp.PersistedSize, len(largeInput))
+
+	require.Len(t, blobs.blobs, 1)
+	require.Len(t, store.nodeInfos, 1)
+	for key, blob := range blobs.blobs {
+		assert.Equal(t, offloadedPayloadsKey("tree", "branch", 1, 0), key)
+		assert.NotContains(t, string(blob.Body), string(largeInput), "offloaded payloads are encrypted")
+		assert.Equal(t, "key-1", blob.Tags[offloadedPayloadEncryptionKeyTag])
+		assert.Equal(t, string(common.EncodingTypeThriftRW), blob.Tags[offloadedPayloadEncodingTag])
+	}
+	assert.True(t, isOffloadedBlob(store.nodes[0]), "offloaded nodes are marked by their encoding")
+
+	readRequest := &ReadHistoryBranchRequest{
+		BranchToken: branchToken,
+		MinEventID:  common.FirstEventID,
+		MaxEventID:  3,
+		PageSize:    10,
+		ShardID:     common.IntPtr(1),
+	}
+	readResp, err := manager.ReadHistoryBranch(ctx, readRequest)
+	require.NoError(t, err)
+	assert.Equal(t, events, readResp.HistoryEvents)
+
+	rawResp, err := manager.ReadRawHistoryBranch(ctx, readRequest)
+	require.NoError(t, err)
+	require.Len(t, rawResp.HistoryEventBlobs, 1)
+	assert.Equal(t, resp.DataBlob.Data, rawResp.HistoryEventBlobs[0].Data)
+
+	err = manager.DeleteHistoryBranch(ctx, &DeleteHistoryBranchRequest{
+		BranchToken: branchToken,
+		ShardID:     common.IntPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, blobs.blobs)
+}
+
+func TestHistoryManager_PayloadOffload_PayloadLikeReference(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeHistoryStore{}
+	blobs := &fakeBlobstore{blobs: map[string]blobstore.Blob{}}
+	blobs.blobs[offloadedPayloadsKey("other-tree", "other-branch", 1, 0)] = blobstore.Blob{Body: []byte("other tenant")}
+	manager := NewHistoryV2ManagerImpl(
+		store,
+		testlogger.New(t),
+		dynamicconfig.GetIntPropertyFn(1024),
+		nil,
+		blobs,
+		dynamicconfig.GetIntPropertyFilteredByDomain(100),
+	)
+	branchToken, err := NewHistoryBranchTokenByBranchID("tree", "branch")
+	require.NoError(t, err)
+
+	// payloads which look like references to the blobstore are user data, and kept as they are
+	events := []*types.HistoryEvent{
+		{
+			ID:        1,
+			Version:   1,
+			EventType: types.EventTypeWorkflowExecutionSignaled.Ptr(),
+			WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "signal",
+				Input:      []byte("\x00CREF" + offloadedPayloadsKey("other-tree", "other-branch", 1, 0)),
+			},
+		},
+	}
+	_, err = manager.AppendHistoryNodes(ctx, &AppendHistoryNodesRequest{
+		IsNewBranch: true,
+		BranchToken: branchToken,
+		Events:      events,
+		Encoding:    common.EncodingTypeThriftRW,
+		ShardID:     common.IntPtr(1),
+		DomainName:  "domain",
+	})
+	require.NoError(t, err)
+	assert.False(t, isOffloadedBlob(store.nodes[0]))
+
+	readResp, err := manager.ReadHistoryBranch(ctx, &ReadHistoryBranchRequest{
+		BranchToken: branchToken,
+		MinEventID:  common.FirstEventID,
+		MaxEventID:  2,
+		PageSize:    10,
+		ShardID:     common.IntPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, events, readResp.HistoryEvents)
+
+	err = manager.DeleteHistoryBranch(ctx, &DeleteHistoryBranchRequest{
+		BranchToken: branchToken,
+		ShardID:     common.IntPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Len(t, blobs.blobs, 1, "blobs of other branches are not deleted")
+}
+
+func TestHistoryManager_PayloadOffload_FailedAppend(t *testing.T) {
+	ctx := context.Background()
+	largeInput := bytes.Repeat([]byte("a"), 2048)
+	events := []*types.HistoryEvent{
+		{
+			ID:        1,
+			Version:   1,
+			EventType: types.EventTypeWorkflowExecutionSignaled.Ptr(),
+			WorkflowExecutionSignaledEventAttributes: &types.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "signal",
+				Input:      largeInput,
+			},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		sizeLimit  int
+		appendErr  error
+		blobsAfter int
+	}{
+		"transaction size limit": {
+			sizeLimit: 10,
+		},
+		"store error": {
+			sizeLimit: 1024,
+			appendErr: &ConditionFailedError{Msg: "conflict"},
+		},
+		"timeout": {
+			sizeLimit:  1024,
+			appendErr:  &TimeoutError{Msg: "timeout"},
+			blobsAfter: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &fakeHistoryStore{appendErr: tc.appendErr}
+			blobs := &fakeBlobstore{blobs: map[string]blobstore.Blob{}}
+			manager := NewHistoryV2ManagerImpl(
+				store,
+				testlogger.New(t),
+				dynamicconfig.GetIntPropertyFn(tc.sizeLimit),
+				nil,
+				blobs,
+				dynamicconfig.GetIntPropertyFilteredByDomain(100),
+			)
+			branchToken, err := NewHistoryBranchTokenByBranchID("tree", "branch")
+			require.NoError(t, err)
+
+			_, err = manager.AppendHistoryNodes(ctx, &AppendHistoryNodesRequest{
+				IsNewBranch: true,
+				BranchToken: branchToken,
+				Events:      events,
+				Encoding:    common.EncodingTypeThriftRW,
+				ShardID:     common.IntPtr(1),
+				DomainName:  "domain",
+			})
+			assert.Error(t, err)
+			assert.Len(t, blobs.blobs, tc.blobsAfter, "offloaded payloads are deleted unless the node may be written")
+		})
+	}
+}
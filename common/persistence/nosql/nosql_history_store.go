@@ -130,6 +130,7 @@ func (h *nosqlHistoryStore) ReadHistoryBranch(
 	}
 
 	history := make([]*persistence.DataBlob, 0, int(request.PageSize))
+	nodes := make([]persistence.InternalHistoryNode, 0, int(request.PageSize))
 
 	eventBlob := &persistence.DataBlob{}
 	nodeID := int64(0)
@@ -170,12 +171,14 @@ func (h *nosqlHistoryStore) ReadHistoryBranch(
 			lastTxnID = txnID
 			lastNodeID = nodeID
 			history = append(history, eventBlob)
+			nodes = append(nodes, persistence.InternalHistoryNode{NodeID: nodeID, TransactionID: txnID})
 			eventBlob = &persistence.DataBlob{}
 		}
 	}
 
 	return &persistence.InternalReadHistoryBranchResponse{
 		History:           history,
+		Nodes:             nodes,
 		NextPageToken:     pagingToken,
 		LastNodeID:        lastNodeID,
 		LastTransactionID: lastTxnID,
@@ -441,3 +444,32 @@ func (h *nosqlHistoryStore) GetHistoryTree(
 		Branches: branches,
 	}, nil
 }
+
+// UpdateHistoryNode overrides the events of an existing history node
+// NOTE: nodes are upserted by the plugins, the caller must make sure that the node exists
+func (h *nosqlHistoryStore) UpdateHistoryNode(
+	ctx context.Context,
+	request *persistence.InternalUpdateHistoryNodeRequest,
+) error {
+
+	nodeRow := &nosqlplugin.HistoryNodeRow{
+		TreeID:       request.TreeID,
+		BranchID:     request.BranchID,
+		NodeID:       request.NodeID,
+		TxnID:        &request.TransactionID,
+		Data:         request.Events.Data,
+		DataEncoding: string(request.Events.Encoding),
+		ShardID:      request.ShardID,
+	}
+
+	storeShard, err := h.GetStoreShardByHistoryShard(request.ShardID)
+	if err != nil {
+		return err
+	}
+
+	err = storeShard.db.InsertIntoHistoryTreeAndNode(ctx, nil, nodeRow)
+	if err != nil {
+		return convertCommonErrors(storeShard.db, "UpdateHistoryNode", err)
+	}
+	return nil
+}
@@ -104,6 +104,29 @@ func (q *nosqlQueueStore) EnqueueMessage(
 	return err
 }
 
+// EnqueueMessages enqueues the messages in order. Since they aren't written atomically, the leading messages
+// might be enqueued even if an error is returned.
+func (q *nosqlQueueStore) EnqueueMessages(
+	ctx context.Context,
+	messagePayloads [][]byte,
+) error {
+	lastMessageID, err := q.getLastMessageID(ctx, q.queueType)
+	if err != nil {
+		return err
+	}
+	ackLevels, err := q.GetAckLevels(ctx)
+	if err != nil {
+		return err
+	}
+	nextID := getNextID(ackLevels, lastMessageID)
+	for i, messagePayload := range messagePayloads {
+		if _, err := q.tryEnqueue(ctx, q.queueType, nextID+int64(i), messagePayload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (q *nosqlQueueStore) EnqueueMessageToDLQ(
 	ctx context.Context,
 	messagePayload []byte,
@@ -264,6 +287,28 @@ func (q *nosqlQueueStore) UpdateAckLevel(
 	return q.updateAckLevel(ctx, messageID, clusterName, q.queueType)
 }
 
+func (q *nosqlQueueStore) DeleteAckLevel(
+	ctx context.Context,
+	clusterName string,
+) error {
+
+	queueMetadata, err := q.getQueueMetadata(ctx, q.queueType)
+	if err != nil {
+		return err
+	}
+
+	if queueMetadata == nil {
+		return nil
+	}
+	if _, ok := queueMetadata.ClusterAckLevels[clusterName]; !ok {
+		return nil
+	}
+
+	delete(queueMetadata.ClusterAckLevels, clusterName)
+	queueMetadata.Version++
+	return q.updateQueueMetadata(ctx, queueMetadata)
+}
+
 func (q *nosqlQueueStore) GetAckLevels(
 	ctx context.Context,
 ) (map[string]int64, error) {
@@ -253,6 +253,47 @@ func TestEnqueueMessage_FailsIfCantInsertMessageToQueue(t *testing.T) {
 	assert.ErrorContains(t, store.EnqueueMessage(ctx, testPayload), errInsert.Error())
 }
 
+func TestEnqueueMessages_Succeeds(t *testing.T) {
+	const lastMessageID = int64(123)
+	td := newQueueStoreTestData(t)
+	store := td.createValidQueueStore(t)
+	ctx := context.Background()
+
+	td.mockDB.EXPECT().SelectLastEnqueuedMessageID(ctx, testQueueType).Return(lastMessageID, nil)
+	td.mockDB.EXPECT().SelectQueueMetadata(ctx, testQueueType).
+		Return(&nosqlplugin.QueueMetadataRow{ClusterAckLevels: map[string]int64{}}, nil)
+	gomock.InOrder(
+		td.mockDB.EXPECT().InsertIntoQueue(ctx, &nosqlplugin.QueueMessageRow{
+			QueueType: testQueueType,
+			ID:        lastMessageID + 1,
+			Payload:   []byte("m1"),
+		}).Return(nil),
+		td.mockDB.EXPECT().InsertIntoQueue(ctx, &nosqlplugin.QueueMessageRow{
+			QueueType: testQueueType,
+			ID:        lastMessageID + 2,
+			Payload:   []byte("m2"),
+		}).Return(nil),
+	)
+
+	require.NoError(t, store.EnqueueMessages(ctx, [][]byte{[]byte("m1"), []byte("m2")}))
+}
+
+func TestEnqueueMessages_FailsIfCantInsertMessageToQueue(t *testing.T) {
+	errInsert := errors.New("fail to insert into queue")
+	td := newQueueStoreTestData(t)
+	store := td.createValidQueueStore(t)
+	ctx := context.Background()
+
+	td.mockDB.EXPECT().SelectLastEnqueuedMessageID(ctx, testQueueType).Return(int64(0), nil)
+	td.mockDB.EXPECT().SelectQueueMetadata(ctx, testQueueType).
+		Return(&nosqlplugin.QueueMetadataRow{}, nil)
+	// the remaining messages aren't enqueued after a failure
+	td.mockDB.EXPECT().InsertIntoQueue(ctx, gomock.Any()).Return(errInsert).Times(1)
+	td.mockErrConversion(errInsert)
+
+	assert.ErrorContains(t, store.EnqueueMessages(ctx, [][]byte{[]byte("m1"), []byte("m2")}), errInsert.Error())
+}
+
 func TestEnqueueMessageToDLQ_Succeeds(t *testing.T) {
 	const dlqMessageType = -testQueueType
 	lastMessageID := int64(123)
@@ -559,6 +600,57 @@ func TestUpdateAckLevel_FailsIfUpdateMetadataFails(t *testing.T) {
 	assert.ErrorContains(t, store.UpdateAckLevel(ctx, messageID, clusterName), errUpdate.Error())
 }
 
+func TestDeleteAckLevel_Succeeds(t *testing.T) {
+	const clusterName = "test-cluster"
+	td := newQueueStoreTestData(t)
+	store := td.createValidQueueStore(t)
+	ctx := context.Background()
+
+	metadata := nosqlplugin.QueueMetadataRow{
+		QueueType: testQueueType,
+		ClusterAckLevels: map[string]int64{
+			clusterName:         110,
+			"unrelated-cluster": 300,
+		},
+		Version: 3,
+	}
+
+	td.mockDB.EXPECT().SelectQueueMetadata(ctx, testQueueType).Return(&metadata, nil)
+	td.mockDB.EXPECT().UpdateQueueMetadataCas(ctx, gomock.Any()).
+		Do(func(_ context.Context, newMeta nosqlplugin.QueueMetadataRow) {
+			assert.Equal(t, int64(4), newMeta.Version, "version should be incremented")
+			assert.Equal(t, map[string]int64{"unrelated-cluster": 300}, newMeta.ClusterAckLevels)
+		}).Return(nil)
+
+	assert.NoError(t, store.DeleteAckLevel(ctx, clusterName))
+}
+
+func TestDeleteAckLevel_NoOpIfMissing(t *testing.T) {
+	td := newQueueStoreTestData(t)
+	store := td.createValidQueueStore(t)
+	ctx := context.Background()
+
+	td.mockDB.EXPECT().SelectQueueMetadata(ctx, testQueueType).
+		Return(&nosqlplugin.QueueMetadataRow{ClusterAckLevels: map[string]int64{"unrelated-cluster": 300}}, nil)
+
+	assert.NoError(t, store.DeleteAckLevel(ctx, "test-cluster"))
+}
+
+func TestDeleteAckLevel_FailsIfUpdateMetadataFails(t *testing.T) {
+	errUpdate := errors.New("update metadata failed")
+	const clusterName = "test-cluster"
+	td := newQueueStoreTestData(t)
+	store := td.createValidQueueStore(t)
+	ctx := context.Background()
+
+	td.mockDB.EXPECT().SelectQueueMetadata(ctx, testQueueType).
+		Return(&nosqlplugin.QueueMetadataRow{ClusterAckLevels: map[string]int64{clusterName: 1}}, nil)
+	td.mockDB.EXPECT().UpdateQueueMetadataCas(ctx, gomock.Any()).Return(errUpdate)
+	td.mockErrConversion(errUpdate)
+
+	assert.ErrorContains(t, store.DeleteAckLevel(ctx, clusterName), errUpdate.Error())
+}
+
 func TestUpdateDLQAckLevel_Succeeds(t *testing.T) {
 	const messageID = 123
 	const clusterName = "test-cluster"
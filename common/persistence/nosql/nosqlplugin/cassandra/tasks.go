@@ -64,8 +64,11 @@ func (db *cdb) SelectTaskList(ctx context.Context, filter *nosqlplugin.TaskListF
 	ackLevel := tlDB["ack_level"].(int64)
 	taskListKind := tlDB["kind"].(int)
 	lastUpdatedTime := tlDB["last_updated"].(time.Time)
+	// the partition counts are absent for rows written before they were added to the schema
+	readPartitions, _ := tlDB["adaptive_read_partitions"].(int)
+	writePartitions, _ := tlDB["adaptive_write_partitions"].(int)
 
-	return &nosqlplugin.TaskListRow{
+	row := &nosqlplugin.TaskListRow{
 		DomainID:     filter.DomainID,
 		TaskListName: filter.TaskListName,
 		TaskListType: filter.TaskListType,
@@ -74,12 +77,20 @@ func (db *cdb) SelectTaskList(ctx context.Context, filter *nosqlplugin.TaskListF
 		LastUpdatedTime: lastUpdatedTime,
 		AckLevel:        ackLevel,
 		RangeID:         rangeID,
-	}, nil
+	}
+	if writePartitions > 0 {
+		row.AdaptivePartitionConfig = &persistence.TaskListPartitionConfig{
+			NumReadPartitions:  readPartitions,
+			NumWritePartitions: writePartitions,
+		}
+	}
+	return row, nil
 }
 
 // InsertTaskList insert a single tasklist row
 // Return TaskOperationConditionFailure if the condition doesn't meet
 func (db *cdb) InsertTaskList(ctx context.Context, row *nosqlplugin.TaskListRow) error {
+	readPartitions, writePartitions := partitionCounts(row.AdaptivePartitionConfig)
 	query := db.session.Query(templateInsertTaskListQuery,
 		row.DomainID,
 		row.TaskListName,
@@ -93,6 +104,8 @@ func (db *cdb) InsertTaskList(ctx context.Context, row *nosqlplugin.TaskListRow)
 		0,
 		row.TaskListKind,
 		row.LastUpdatedTime,
+		readPartitions,
+		writePartitions,
 	).WithContext(ctx)
 
 	previous := make(map[string]interface{})
@@ -111,6 +124,7 @@ func (db *cdb) UpdateTaskList(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
+	readPartitions, writePartitions := partitionCounts(row.AdaptivePartitionConfig)
 	query := db.session.Query(templateUpdateTaskListQuery,
 		row.RangeID,
 		row.DomainID,
@@ -119,6 +133,8 @@ func (db *cdb) UpdateTaskList(
 		row.AckLevel,
 		row.TaskListKind,
 		row.LastUpdatedTime,
+		readPartitions,
+		writePartitions,
 		row.DomainID,
 		row.TaskListName,
 		row.TaskListType,
@@ -136,6 +152,13 @@ func (db *cdb) UpdateTaskList(
 	return handleTaskListAppliedError(applied, previous)
 }
 
+func partitionCounts(config *persistence.TaskListPartitionConfig) (int, int) {
+	if config == nil {
+		return 0, 0
+	}
+	return config.NumReadPartitions, config.NumWritePartitions
+}
+
 func handleTaskListAppliedError(applied bool, previous map[string]interface{}) error {
 	if !applied {
 		// NOTE: Cassandra only returns the conflicted columns in this results
@@ -162,6 +185,7 @@ func (db *cdb) UpdateTaskListWithTTL(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
+	readPartitions, writePartitions := partitionCounts(row.AdaptivePartitionConfig)
 	batch := db.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
 	// part 1 is used to set TTL on primary key as UPDATE can't set TTL for primary key
 	batch.Query(templateUpdateTaskListQueryWithTTLPart1,
@@ -182,6 +206,8 @@ func (db *cdb) UpdateTaskListWithTTL(
 		row.AckLevel,
 		row.TaskListKind,
 		db.timeSrc.Now(),
+		readPartitions,
+		writePartitions,
 		row.DomainID,
 		row.TaskListName,
 		row.TaskListType,
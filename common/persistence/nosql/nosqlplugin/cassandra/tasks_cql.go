@@ -28,7 +28,9 @@ const (
 		`type: ?, ` +
 		`ack_level: ?, ` +
 		`kind: ?, ` +
-		`last_updated: ? ` +
+		`last_updated: ?, ` +
+		`adaptive_read_partitions: ?, ` +
+		`adaptive_write_partitions: ? ` +
 		`}`
 
 	templateTaskType = `{` +
@@ -82,6 +82,45 @@ func TestSelectTaskList(t *testing.T) {
 				`SELECT range_id, task_list FROM tasks WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345`,
 			},
 		},
+		{
+			name: "success with adaptive partitions",
+			filter: &nosqlplugin.TaskListFilter{
+				DomainID:     "domain1",
+				TaskListName: "tasklist1",
+				TaskListType: 1,
+			},
+			queryMockFn: func(query *gocql.MockQuery) {
+				query.EXPECT().WithContext(gomock.Any()).Return(query).Times(1)
+				query.EXPECT().Scan(gomock.Any()).DoAndReturn(func(args ...interface{}) error {
+					rangeID := args[0].(*int64)
+					*rangeID = 25
+					tlDB := args[1].(*map[string]interface{})
+					*tlDB = make(map[string]interface{})
+					(*tlDB)["ack_level"] = int64(1000)
+					(*tlDB)["kind"] = 2
+					(*tlDB)["last_updated"] = now
+					(*tlDB)["adaptive_read_partitions"] = 3
+					(*tlDB)["adaptive_write_partitions"] = 2
+					return nil
+				}).Times(1)
+			},
+			wantRow: &nosqlplugin.TaskListRow{
+				DomainID:        "domain1",
+				TaskListName:    "tasklist1",
+				TaskListType:    1,
+				TaskListKind:    2,
+				AckLevel:        1000,
+				RangeID:         25,
+				LastUpdatedTime: now,
+				AdaptivePartitionConfig: &persistence.TaskListPartitionConfig{
+					NumReadPartitions:  3,
+					NumWritePartitions: 2,
+				},
+			},
+			wantQueries: []string{
+				`SELECT range_id, task_list FROM tasks WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345`,
+			},
+		},
 		{
 			name: "scan failure",
 			filter: &nosqlplugin.TaskListFilter{
@@ -168,7 +207,7 @@ func TestInsertTaskList(t *testing.T) {
 			wantQueries: []string{
 				`INSERT INTO tasks (domain_id, task_list_name, task_list_type, type, task_id, range_id, task_list ) ` +
 					`VALUES (domain1, tasklist1, 1, 1, -12345, 1, ` +
-					`{domain_id: domain1, name: tasklist1, type: 1, ack_level: 0, kind: 2, last_updated: 2024-04-01T22:08:41Z }` +
+					`{domain_id: domain1, name: tasklist1, type: 1, ack_level: 0, kind: 2, last_updated: 2024-04-01T22:08:41Z, adaptive_read_partitions: 0, adaptive_write_partitions: 0 }` +
 					`) IF NOT EXISTS`,
 			},
 		},
@@ -278,7 +317,33 @@ func TestUpdateTaskList(t *testing.T) {
 				}).Times(1)
 			},
 			wantQueries: []string{
-				`UPDATE tasks SET range_id = 25, task_list = {domain_id: domain1, name: tasklist1, type: 1, ack_level: 1000, kind: 2, last_updated: 2024-04-01T22:08:41Z } WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345 IF range_id = 25`,
+				`UPDATE tasks SET range_id = 25, task_list = {domain_id: domain1, name: tasklist1, type: 1, ack_level: 1000, kind: 2, last_updated: 2024-04-01T22:08:41Z, adaptive_read_partitions: 0, adaptive_write_partitions: 0 } WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345 IF range_id = 25`,
+			},
+		},
+		{
+			name:        "successfully applied with adaptive partitions",
+			prevRangeID: 25,
+			row: &nosqlplugin.TaskListRow{
+				DomainID:        "domain1",
+				TaskListName:    "tasklist1",
+				TaskListType:    1,
+				TaskListKind:    2,
+				AckLevel:        1000,
+				RangeID:         25,
+				LastUpdatedTime: ts,
+				AdaptivePartitionConfig: &persistence.TaskListPartitionConfig{
+					NumReadPartitions:  3,
+					NumWritePartitions: 2,
+				},
+			},
+			queryMockFn: func(query *gocql.MockQuery) {
+				query.EXPECT().WithContext(gomock.Any()).Return(query).Times(1)
+				query.EXPECT().MapScanCAS(gomock.Any()).DoAndReturn(func(prev map[string]interface{}) (bool, error) {
+					return true, nil
+				}).Times(1)
+			},
+			wantQueries: []string{
+				`UPDATE tasks SET range_id = 25, task_list = {domain_id: domain1, name: tasklist1, type: 1, ack_level: 1000, kind: 2, last_updated: 2024-04-01T22:08:41Z, adaptive_read_partitions: 3, adaptive_write_partitions: 2 } WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345 IF range_id = 25`,
 			},
 		},
 		{
@@ -386,7 +451,7 @@ func TestUpdateTaskListWithTTL(t *testing.T) {
 			mapExecuteBatchCASApplied: true,
 			wantQueries: []string{
 				` INSERT INTO tasks (domain_id, task_list_name, task_list_type, type, task_id ) VALUES (domain1, tasklist1, 1, 1, -12345) USING TTL 180`,
-				`UPDATE tasks USING TTL 180 SET range_id = 25, task_list = {domain_id: domain1, name: tasklist1, type: 1, ack_level: 1000, kind: 2, last_updated: 2024-04-01T22:08:41Z } WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345 IF range_id = 25`,
+				`UPDATE tasks USING TTL 180 SET range_id = 25, task_list = {domain_id: domain1, name: tasklist1, type: 1, ack_level: 1000, kind: 2, last_updated: 2024-04-01T22:08:41Z, adaptive_read_partitions: 0, adaptive_write_partitions: 0 } WHERE domain_id = domain1 and task_list_name = tasklist1 and task_list_type = 1 and type = 1 and task_id = -12345 IF range_id = 25`,
 			},
 		},
 		{
@@ -21,14 +21,104 @@
 
 package dynamodb
 
-import "github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
 
 var _ nosqlplugin.AdminDB = (*ddb)(nil)
 
+const (
+	testSchemaDir = "schema/dynamodb/"
+)
+
+// tableSchema is the format of a table in schema.json
+type tableSchema struct {
+	CreateTable *dynamodb.CreateTableInput
+	// TimeToLiveAttribute enables TTL on the attribute if it's not empty
+	TimeToLiveAttribute string
+}
+
 func (db *ddb) SetupTestDatabase(schemaBaseDir string, replicas int) error {
-	panic("TODO")
+	tables, err := readSchema(schemaBaseDir)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	for _, table := range tables {
+		input := table.CreateTable
+		input.TableName = aws.String(db.tableName(aws.StringValue(input.TableName)))
+		if _, err := db.client.CreateTableWithContext(ctx, input); err != nil {
+			return err
+		}
+		if err := db.client.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+			TableName: input.TableName,
+		}); err != nil {
+			return err
+		}
+		if table.TimeToLiveAttribute == "" {
+			continue
+		}
+		if _, err := db.client.UpdateTimeToLiveWithContext(ctx, &dynamodb.UpdateTimeToLiveInput{
+			TableName: input.TableName,
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: aws.String(table.TimeToLiveAttribute),
+				Enabled:       aws.Bool(true),
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (db *ddb) TeardownTestDatabase() error {
-	panic("TODO")
+	tables, err := readSchema("")
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	for _, table := range tables {
+		tableName := aws.String(db.tableName(aws.StringValue(table.CreateTable.TableName)))
+		if _, err := db.client.DeleteTableWithContext(ctx, &dynamodb.DeleteTableInput{
+			TableName: tableName,
+		}); err != nil {
+			if isResourceNotFoundError(err) {
+				continue
+			}
+			return err
+		}
+		if err := db.client.WaitUntilTableNotExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+			TableName: tableName,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readSchema(schemaBaseDir string) ([]*tableSchema, error) {
+	if schemaBaseDir == "" {
+		var err error
+		schemaBaseDir, err = nosqlplugin.GetDefaultTestSchemaDir(testSchemaDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byteValues, err := ioutil.ReadFile(schemaBaseDir + "cadence/schema.json")
+	if err != nil {
+		return nil, err
+	}
+	var tables []*tableSchema
+	if err := json.Unmarshal(byteValues, &tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
 }
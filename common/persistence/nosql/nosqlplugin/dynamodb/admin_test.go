@@ -0,0 +1,52 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+func TestReadSchema(t *testing.T) {
+	tables, err := readSchema("../../../../../schema/dynamodb/")
+	require.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, table := range tables {
+		assert.NoError(t, table.CreateTable.Validate())
+		names[*table.CreateTable.TableName] = true
+	}
+	for _, name := range []string{
+		cadence.ShardTableName,
+		cadence.WorkflowExecutionTableName,
+		cadence.HistoryNodeTableName,
+		cadence.VisibilityTableName,
+		cadence.ClusterConfigTableName,
+	} {
+		assert.True(t, names[name], "table %v is missing in schema", name)
+	}
+}
@@ -22,15 +22,53 @@ package dynamodb
 
 import (
 	"context"
-	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 
 	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
 )
 
+// InsertConfig insert a config entry with version, the sort key is the encoded version
+// so that the latest version can be read by a reverse query
 func (db *ddb) InsertConfig(ctx context.Context, row *persistence.InternalConfigStoreEntry) error {
-	return errors.New("TODO")
+	item, err := newItem(strconv.Itoa(row.RowType), encodeInt64(row.Version), row, 0)
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.ClusterConfigTableName, item, aws.String("attribute_not_exists(pk)"), nil)
+	if db.IsConditionFailedError(err) {
+		return nosqlplugin.NewConditionFailure("InsertConfig operation failed because of version collision")
+	}
+	return err
 }
 
 func (db *ddb) SelectLatestConfig(ctx context.Context, rowType int) (*persistence.InternalConfigStoreEntry, error) {
-	return nil, errors.New("TODO")
+	items, _, err := db.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.ClusterConfigTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(strconv.Itoa(rowType)),
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(1),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errNotFound
+	}
+	var item cadence.Item
+	if err := unmarshalItem(items[0], &item); err != nil {
+		return nil, err
+	}
+	row := &persistence.InternalConfigStoreEntry{}
+	if err := decodeData(&item, row); err != nil {
+		return nil, err
+	}
+	return row, nil
 }
@@ -21,36 +21,60 @@
 package dynamodb
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
 )
 
 const (
-	// PluginName is the name of the plugin
-	PluginName = "dynamodb"
+	// maxTransactionItems is the max number of items that a single TransactWriteItems call can contain
+	maxTransactionItems = 100
+	// maxBatchWriteItems is the max number of items that a single BatchWriteItem call can contain
+	maxBatchWriteItems = 25
+
+	conditionalCheckFailedReason = "ConditionalCheckFailed"
 )
 
 var (
 	errConditionFailed = errors.New("internal condition fail error")
+	errNotFound        = errors.New("item not found")
 )
 
 // ddb represents a logical connection to DynamoDB database
 type ddb struct {
+	client dynamodbiface.DynamoDBAPI
+	cfg    *config.NoSQL
+	logger log.Logger
 }
 
 var _ nosqlplugin.DB = (*ddb)(nil)
 
 // NewDynamoDB return a new DB
 func NewDynamoDB(cfg config.NoSQL, logger log.Logger) (nosqlplugin.DB, error) {
-	return nil, fmt.Errorf("TODO")
+	return (&plugin{}).doCreateDB(&cfg, logger)
+}
+
+func newDynamoDBWithClient(cfg *config.NoSQL, client dynamodbiface.DynamoDBAPI, logger log.Logger) *ddb {
+	return &ddb{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
 }
 
 func (db *ddb) Close() {
-	panic("TODO")
+	// DynamoDB client is stateless HTTP client, nothing to close
 }
 
 func (db *ddb) PluginName() string {
@@ -58,21 +82,316 @@ func (db *ddb) PluginName() string {
 }
 
 func (db *ddb) IsNotFoundError(err error) bool {
-	panic("TODO")
+	return errors.Is(err, errNotFound)
 }
 
 func (db *ddb) IsTimeoutError(err error) bool {
-	panic("TODO")
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		return aerr.Code() == request.CanceledErrorCode || errors.Is(aerr.OrigErr(), context.DeadlineExceeded)
+	}
+	return false
 }
 
 func (db *ddb) IsThrottlingError(err error) bool {
-	panic("TODO")
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		switch aerr.Code() {
+		case dynamodb.ErrCodeProvisionedThroughputExceededException,
+			dynamodb.ErrCodeRequestLimitExceeded,
+			"ThrottlingException":
+			return true
+		}
+		if aerr.Code() == dynamodb.ErrCodeTransactionCanceledException {
+			return hasCancellationReason(err, dynamodb.ErrCodeProvisionedThroughputExceededException, "ThrottlingError")
+		}
+	}
+	return false
 }
 
 func (db *ddb) IsDBUnavailableError(err error) bool {
-	panic("TODO")
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		return reqErr.StatusCode() >= http.StatusInternalServerError
+	}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		return aerr.Code() == dynamodb.ErrCodeInternalServerError
+	}
+	return false
 }
 
 func (db *ddb) IsConditionFailedError(err error) bool {
 	return err == errConditionFailed
 }
+
+func (db *ddb) tableName(name string) string {
+	return cadence.TableName(db.cfg.Keyspace, name)
+}
+
+// getItem reads one item with strong consistency, returns errNotFound if the item doesn't exist
+func (db *ddb) getItem(ctx context.Context, table string, key map[string]*dynamodb.AttributeValue, out interface{}) error {
+	resp, err := db.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(db.tableName(table)),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Item) == 0 {
+		return errNotFound
+	}
+	return unmarshalItem(resp.Item, out)
+}
+
+// putItem writes one item, returns errConditionFailed if the condition is not met
+func (db *ddb) putItem(ctx context.Context, table string, item interface{}, condition *string, values map[string]*dynamodb.AttributeValue) error {
+	av, err := marshalItem(item)
+	if err != nil {
+		return err
+	}
+	if err := checkItemSize(table, av); err != nil {
+		return err
+	}
+	_, err = db.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(db.tableName(table)),
+		Item:                      av,
+		ConditionExpression:       condition,
+		ExpressionAttributeValues: values,
+	})
+	return convertConditionalCheckFailed(convertItemSizeError(err))
+}
+
+// deleteItem deletes one item, returns errConditionFailed if the condition is not met
+func (db *ddb) deleteItem(ctx context.Context, table string, key map[string]*dynamodb.AttributeValue, condition *string, values map[string]*dynamodb.AttributeValue) error {
+	_, err := db.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(db.tableName(table)),
+		Key:                       key,
+		ConditionExpression:       condition,
+		ExpressionAttributeValues: values,
+	})
+	return convertConditionalCheckFailed(err)
+}
+
+// query runs a query and returns one page of items together with the next page token
+func (db *ddb) query(ctx context.Context, input *dynamodb.QueryInput, pageToken []byte) ([]map[string]*dynamodb.AttributeValue, []byte, error) {
+	startKey, err := deserializePageToken(pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.ExclusiveStartKey = startKey
+	if input.IndexName == nil {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	resp, err := db.client.QueryWithContext(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	nextPageToken, err := serializePageToken(resp.LastEvaluatedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Items, nextPageToken, nil
+}
+
+// queryAll runs a query and returns all items by following the pagination until the limit is reached.
+// limit <= 0 means no limit.
+func (db *ddb) queryAll(ctx context.Context, input *dynamodb.QueryInput, limit int) ([]map[string]*dynamodb.AttributeValue, error) {
+	var result []map[string]*dynamodb.AttributeValue
+	var pageToken []byte
+	for {
+		if limit > 0 {
+			input.Limit = aws.Int64(int64(limit - len(result)))
+		}
+		items, nextPageToken, err := db.query(ctx, input, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		if len(nextPageToken) == 0 || (limit > 0 && len(result) >= limit) {
+			return result, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// count returns the number of items that match the query
+func (db *ddb) count(ctx context.Context, input *dynamodb.QueryInput) (int64, error) {
+	input.Select = aws.String(dynamodb.SelectCount)
+	var total int64
+	var pageToken []byte
+	for {
+		startKey, err := deserializePageToken(pageToken)
+		if err != nil {
+			return 0, err
+		}
+		input.ExclusiveStartKey = startKey
+		resp, err := db.client.QueryWithContext(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		total += aws.Int64Value(resp.Count)
+		if pageToken, err = serializePageToken(resp.LastEvaluatedKey); err != nil {
+			return 0, err
+		}
+		if len(pageToken) == 0 {
+			return total, nil
+		}
+	}
+}
+
+// scan runs a scan and returns one page of items together with the next page token
+func (db *ddb) scan(ctx context.Context, input *dynamodb.ScanInput, pageToken []byte) ([]map[string]*dynamodb.AttributeValue, []byte, error) {
+	startKey, err := deserializePageToken(pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.ExclusiveStartKey = startKey
+	resp, err := db.client.ScanWithContext(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+	nextPageToken, err := serializePageToken(resp.LastEvaluatedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Items, nextPageToken, nil
+}
+
+// deleteByQuery deletes all the items returned by the query, in batches.
+// The query only needs to project the key attributes.
+func (db *ddb) deleteByQuery(ctx context.Context, table string, input *dynamodb.QueryInput) (int, error) {
+	input.ProjectionExpression = aws.String(cadence.PartitionKeyAttribute + ", " + cadence.SortKeyAttribute)
+	deleted := 0
+	var pageToken []byte
+	for {
+		items, nextPageToken, err := db.query(ctx, input, pageToken)
+		if err != nil {
+			return deleted, err
+		}
+		if err := db.batchDelete(ctx, table, items); err != nil {
+			return deleted, err
+		}
+		deleted += len(items)
+		if len(nextPageToken) == 0 {
+			return deleted, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// batchDelete deletes the items by their keys, and retries the unprocessed items
+func (db *ddb) batchDelete(ctx context.Context, table string, keys []map[string]*dynamodb.AttributeValue) error {
+	tableName := db.tableName(table)
+	for start := 0; start < len(keys); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var requests []*dynamodb.WriteRequest
+		for _, key := range keys[start:end] {
+			requests = append(requests, &dynamodb.WriteRequest{
+				DeleteRequest: &dynamodb.DeleteRequest{
+					Key: primaryKeyOf(key),
+				},
+			})
+		}
+		unprocessed := map[string][]*dynamodb.WriteRequest{tableName: requests}
+		for len(unprocessed) > 0 {
+			resp, err := db.client.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: unprocessed,
+			})
+			if err != nil {
+				return err
+			}
+			unprocessed = resp.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+// transactWrite executes the items in one transaction.
+// When the transaction is canceled because of condition checks, a *transactionConditionFailure is returned,
+// when an item is too large, a *persistence.TransactionSizeLimitError is returned
+func (db *ddb) transactWrite(ctx context.Context, items []*dynamodb.TransactWriteItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := checkTransactionSize(items); err != nil {
+		return err
+	}
+	_, err := db.client.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err == nil {
+		return nil
+	}
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		failure := &transactionConditionFailure{
+			cause: err,
+			items: make(map[int]map[string]*dynamodb.AttributeValue),
+		}
+		for i, reason := range canceled.CancellationReasons {
+			if reason != nil && aws.StringValue(reason.Code) == conditionalCheckFailedReason {
+				failure.items[i] = reason.Item
+			}
+		}
+		if len(failure.items) > 0 {
+			return failure
+		}
+	}
+	return convertItemSizeError(err)
+}
+
+// transactionConditionFailure is returned by transactWrite when some of the conditions are not met
+type transactionConditionFailure struct {
+	cause error
+	// items are indexed by the position of the failed operations in the transaction,
+	// the value is the existing item if ReturnValuesOnConditionCheckFailure is ALL_OLD.
+	items map[int]map[string]*dynamodb.AttributeValue
+}
+
+func (f *transactionConditionFailure) Error() string {
+	return f.cause.Error()
+}
+
+func (f *transactionConditionFailure) failed(index int) (map[string]*dynamodb.AttributeValue, bool) {
+	item, ok := f.items[index]
+	return item, ok
+}
+
+func convertConditionalCheckFailed(err error) error {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return errConditionFailed
+	}
+	return err
+}
+
+func isResourceNotFoundError(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException
+}
+
+func hasCancellationReason(err error, codes ...string) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	for _, reason := range canceled.CancellationReasons {
+		if reason == nil {
+			continue
+		}
+		for _, code := range codes {
+			if aws.StringValue(reason.Code) == code {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -22,17 +22,101 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+// Domain table contains three kinds of items:
+// 1. domain by name: pk = domainByNamePartition, sk = domain name, data is the DomainRow
+// 2. domain by ID: pk = domainByIDPartition, sk = domain ID, data is the domain name
+// 3. the metadata item, which has the notification version of all domains
+const (
+	domainByNamePartition = "name"
+	domainByIDPartition   = "id"
+	domainMetadataKey     = "metadata"
+	domainComponentName   = "domain"
 )
 
+// domainIDRecord is the data of the domain by ID item
+type domainIDRecord struct {
+	Name string
+}
+
 // Insert a new record to domain, return error if failed or already exists
 // Return ConditionFailure if the condition doesn't meet
 func (db *ddb) InsertDomain(
 	ctx context.Context,
 	row *nosqlplugin.DomainRow,
 ) error {
-	panic("TODO")
+	metadataNotificationVersion, err := db.SelectDomainMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	newRow := *row
+	newRow.FailoverNotificationVersion = persistence.InitialFailoverNotificationVersion
+	newRow.PreviousFailoverVersion = common.InitialPreviousFailoverVersion
+	newRow.NotificationVersion = metadataNotificationVersion
+
+	idItem, err := newItem(domainByIDPartition, row.Info.ID, &domainIDRecord{Name: row.Info.Name}, 0)
+	if err != nil {
+		return err
+	}
+	idAV, err := marshalItem(idItem)
+	if err != nil {
+		return err
+	}
+	nameItem, err := newItem(domainByNamePartition, row.Info.Name, &newRow, 0)
+	if err != nil {
+		return err
+	}
+	nameAV, err := marshalItem(nameItem)
+	if err != nil {
+		return err
+	}
+
+	// the order of the items matters for checking the failed conditions below
+	const idItemIndex, nameItemIndex = 0, 1
+	err = db.transactWrite(ctx, []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(db.tableName(cadence.DomainTableName)),
+				Item:                idAV,
+				ConditionExpression: aws.String("attribute_not_exists(pk)"),
+			},
+		},
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(db.tableName(cadence.DomainTableName)),
+				Item:                nameAV,
+				ConditionExpression: aws.String("attribute_not_exists(pk)"),
+			},
+		},
+		db.updateDomainMetadataItem(metadataNotificationVersion),
+	})
+	if failure, ok := err.(*transactionConditionFailure); ok {
+		if _, ok := failure.failed(nameItemIndex); ok {
+			db.logger.Warn("Domain already exists", tag.WorkflowDomainName(row.Info.Name))
+			return &types.DomainAlreadyExistsError{
+				Message: fmt.Sprintf("Domain %v already exists", row.Info.Name),
+			}
+		}
+		if _, ok := failure.failed(idItemIndex); ok {
+			return fmt.Errorf("CreateDomain operation failed because of uuid collision")
+		}
+		db.logger.Warn("Create domain operation failed because of condition update failure on domain metadata record")
+		return nosqlplugin.NewConditionFailure(domainComponentName)
+	}
+	return err
 }
 
 // Update domain
@@ -40,7 +124,47 @@ func (db *ddb) UpdateDomain(
 	ctx context.Context,
 	row *nosqlplugin.DomainRow,
 ) error {
-	panic("TODO")
+	nameItem, err := newItem(domainByNamePartition, row.Info.Name, row, 0)
+	if err != nil {
+		return err
+	}
+	nameAV, err := marshalItem(nameItem)
+	if err != nil {
+		return err
+	}
+
+	err = db.transactWrite(ctx, []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				TableName: aws.String(db.tableName(cadence.DomainTableName)),
+				Item:      nameAV,
+			},
+		},
+		db.updateDomainMetadataItem(row.NotificationVersion),
+	})
+	if _, ok := err.(*transactionConditionFailure); ok {
+		return nosqlplugin.NewConditionFailure(domainComponentName)
+	}
+	return err
+}
+
+// updateDomainMetadataItem increases the notification version by one, if the current version matches
+func (db *ddb) updateDomainMetadataItem(notificationVersion int64) *dynamodb.TransactWriteItem {
+	update := &dynamodb.Update{
+		TableName:        aws.String(db.tableName(cadence.DomainTableName)),
+		Key:              itemKey(domainMetadataKey, domainMetadataKey),
+		UpdateExpression: aws.String("SET notification_version = :next_version"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":next_version": numberValue(notificationVersion + 1),
+		},
+	}
+	if notificationVersion > 0 {
+		update.ConditionExpression = aws.String("notification_version = :current_version")
+		update.ExpressionAttributeValues[":current_version"] = numberValue(notificationVersion)
+	} else {
+		update.ConditionExpression = aws.String("attribute_not_exists(notification_version)")
+	}
+	return &dynamodb.TransactWriteItem{Update: update}
 }
 
 // Get one domain data, either by domainID or domainName
@@ -49,7 +173,43 @@ func (db *ddb) SelectDomain(
 	domainID *string,
 	domainName *string,
 ) (*nosqlplugin.DomainRow, error) {
-	panic("TODO")
+	if domainID != nil && domainName != nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name specified in request")
+	} else if domainID == nil && domainName == nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name are empty")
+	}
+
+	name := ""
+	if domainID != nil {
+		var err error
+		if name, err = db.selectDomainName(ctx, *domainID); err != nil {
+			return nil, err
+		}
+	} else {
+		name = *domainName
+	}
+
+	var item cadence.Item
+	if err := db.getItem(ctx, cadence.DomainTableName, itemKey(domainByNamePartition, name), &item); err != nil {
+		return nil, err
+	}
+	row := &nosqlplugin.DomainRow{}
+	if err := decodeData(&item, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (db *ddb) selectDomainName(ctx context.Context, domainID string) (string, error) {
+	var item cadence.Item
+	if err := db.getItem(ctx, cadence.DomainTableName, itemKey(domainByIDPartition, domainID), &item); err != nil {
+		return "", err
+	}
+	record := &domainIDRecord{}
+	if err := decodeData(&item, record); err != nil {
+		return "", err
+	}
+	return record.Name, nil
 }
 
 // Get all domain data
@@ -58,7 +218,34 @@ func (db *ddb) SelectAllDomains(
 	pageSize int,
 	pageToken []byte,
 ) ([]*nosqlplugin.DomainRow, []byte, error) {
-	panic("TODO")
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.DomainTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(domainByNamePartition),
+		},
+	}
+	if pageSize > 0 {
+		input.Limit = aws.Int64(int64(pageSize))
+	}
+	items, nextPageToken, err := db.query(ctx, input, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []*nosqlplugin.DomainRow
+	for _, av := range items {
+		var item cadence.Item
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, nil, err
+		}
+		row := &nosqlplugin.DomainRow{}
+		if err := decodeData(&item, row); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nextPageToken, nil
 }
 
 // Delete a domain, either by domainID or domainName
@@ -67,11 +254,59 @@ func (db *ddb) DeleteDomain(
 	domainID *string,
 	domainName *string,
 ) error {
-	panic("TODO")
+	if domainName == nil && domainID == nil {
+		return fmt.Errorf("must provide either domainID or domainName")
+	}
+
+	var name, id string
+	if domainName == nil {
+		var err error
+		id = *domainID
+		if name, err = db.selectDomainName(ctx, id); err != nil {
+			if db.IsNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+	} else {
+		name = *domainName
+		row, err := db.SelectDomain(ctx, nil, domainName)
+		if err != nil {
+			if db.IsNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		id = row.Info.ID
+	}
+
+	return db.transactWrite(ctx, []*dynamodb.TransactWriteItem{
+		{
+			Delete: &dynamodb.Delete{
+				TableName: aws.String(db.tableName(cadence.DomainTableName)),
+				Key:       itemKey(domainByNamePartition, name),
+			},
+		},
+		{
+			Delete: &dynamodb.Delete{
+				TableName: aws.String(db.tableName(cadence.DomainTableName)),
+				Key:       itemKey(domainByIDPartition, id),
+			},
+		},
+	})
 }
 
 func (db *ddb) SelectDomainMetadata(
 	ctx context.Context,
 ) (int64, error) {
-	panic("TODO")
+	var item cadence.DomainMetadataItem
+	err := db.getItem(ctx, cadence.DomainTableName, itemKey(domainMetadataKey, domainMetadataKey), &item)
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			// the metadata item doesn't exist before the first domain is created
+			return 0, nil
+		}
+		return -1, err
+	}
+	return item.NotificationVersion, nil
 }
@@ -22,31 +22,223 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
 )
 
+// readAllBranchesPageSize is the page size for reading all branches of a tree
+const readAllBranchesPageSize = 100
+
 // InsertIntoHistoryTreeAndNode inserts one or two rows: tree row and node row(at least one of them)
 func (db *ddb) InsertIntoHistoryTreeAndNode(ctx context.Context, treeRow *nosqlplugin.HistoryTreeRow, nodeRow *nosqlplugin.HistoryNodeRow) error {
-	panic("TODO")
+	if treeRow == nil && nodeRow == nil {
+		return fmt.Errorf("require at least a tree row or a node row to insert")
+	}
+
+	var items []*dynamodb.TransactWriteItem
+	if treeRow != nil {
+		item, err := newItem(treeRow.TreeID, treeRow.BranchID, treeRow, 0)
+		if err != nil {
+			return err
+		}
+		av, err := marshalItem(item)
+		if err != nil {
+			return err
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName: aws.String(db.tableName(cadence.HistoryTreeTableName)),
+				Item:      av,
+			},
+		})
+	}
+	if nodeRow != nil {
+		txnID := common.Int64Default(nodeRow.TxnID)
+		av, err := marshalItem(&cadence.HistoryNodeItem{
+			Item: cadence.Item{
+				PartitionKey: historyBranchKey(nodeRow.TreeID, nodeRow.BranchID),
+				SortKey:      historyNodeSortKey(nodeRow.NodeID, txnID),
+				Data:         nodeRow.Data,
+				DataEncoding: nodeRow.DataEncoding,
+			},
+			NodeID: nodeRow.NodeID,
+			TxnID:  txnID,
+		})
+		if err != nil {
+			return err
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName: aws.String(db.tableName(cadence.HistoryNodeTableName)),
+				Item:      av,
+			},
+		})
+	}
+
+	if len(items) == 1 {
+		if err := checkItemSize(aws.StringValue(items[0].Put.TableName), items[0].Put.Item); err != nil {
+			return err
+		}
+		_, err := db.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+			TableName: items[0].Put.TableName,
+			Item:      items[0].Put.Item,
+		})
+		return convertItemSizeError(err)
+	}
+	return db.transactWrite(ctx, items)
 }
 
 // SelectFromHistoryNode read nodes based on a filter
 func (db *ddb) SelectFromHistoryNode(ctx context.Context, filter *nosqlplugin.HistoryNodeFilter) ([]*nosqlplugin.HistoryNodeRow, []byte, error) {
-	panic("TODO")
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.HistoryNodeTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :min AND :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(historyBranchKey(filter.TreeID, filter.BranchID)),
+			// sort keys of a node have the txn ID as suffix, so nodes of MinNodeID are included and nodes of MaxNodeID are excluded
+			":min": stringValue(encodeInt64(filter.MinNodeID)),
+			":max": stringValue(encodeInt64(filter.MaxNodeID)),
+		},
+	}
+	if filter.PageSize > 0 {
+		input.Limit = aws.Int64(int64(filter.PageSize))
+	}
+	items, nextPageToken, err := db.query(ctx, input, filter.NextPageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []*nosqlplugin.HistoryNodeRow
+	for _, av := range items {
+		var item cadence.HistoryNodeItem
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, &nosqlplugin.HistoryNodeRow{
+			TreeID:       filter.TreeID,
+			BranchID:     filter.BranchID,
+			NodeID:       item.NodeID,
+			TxnID:        common.Int64Ptr(item.TxnID),
+			Data:         item.Data,
+			DataEncoding: item.DataEncoding,
+		})
+	}
+	return rows, nextPageToken, nil
 }
 
 // DeleteFromHistoryTreeAndNode delete a branch record, and a list of ranges of nodes.
 func (db *ddb) DeleteFromHistoryTreeAndNode(ctx context.Context, treeFilter *nosqlplugin.HistoryTreeFilter, nodeFilters []*nosqlplugin.HistoryNodeFilter) error {
-	panic("TODO")
+	// delete the nodes first, so that the branch can still be found to retry if the deletion fails in the middle
+	for _, nodeFilter := range nodeFilters {
+		_, err := db.deleteByQuery(ctx, cadence.HistoryNodeTableName, &dynamodb.QueryInput{
+			TableName:              aws.String(db.tableName(cadence.HistoryNodeTableName)),
+			KeyConditionExpression: aws.String("pk = :pk AND sk >= :min"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":pk":  stringValue(historyBranchKey(nodeFilter.TreeID, nodeFilter.BranchID)),
+				":min": stringValue(encodeInt64(nodeFilter.MinNodeID)),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return db.deleteItem(ctx, cadence.HistoryTreeTableName, itemKey(treeFilter.TreeID, common.StringDefault(treeFilter.BranchID)), nil, nil)
 }
 
 // SelectAllHistoryTrees will return all tree branches with pagination
 func (db *ddb) SelectAllHistoryTrees(ctx context.Context, nextPageToken []byte, pageSize int) ([]*nosqlplugin.HistoryTreeRow, []byte, error) {
-	panic("TODO")
+	input := &dynamodb.ScanInput{
+		TableName:      aws.String(db.tableName(cadence.HistoryTreeTableName)),
+		ConsistentRead: aws.Bool(true),
+	}
+	if pageSize > 0 {
+		input.Limit = aws.Int64(int64(pageSize))
+	}
+	items, nextPageToken, err := db.scan(ctx, input, nextPageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := toHistoryTreeRows(items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rows, nextPageToken, nil
 }
 
 // SelectFromHistoryTree read branch records for a tree
 func (db *ddb) SelectFromHistoryTree(ctx context.Context, filter *nosqlplugin.HistoryTreeFilter) ([]*nosqlplugin.HistoryTreeRow, error) {
-	panic("TODO")
+	var rows []*nosqlplugin.HistoryTreeRow
+	var pageToken []byte
+	for {
+		items, nextPageToken, err := db.query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(db.tableName(cadence.HistoryTreeTableName)),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":pk": stringValue(filter.TreeID),
+			},
+			Limit: aws.Int64(readAllBranchesPageSize),
+		}, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		pageRows, err := toHistoryTreeRows(items)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range pageRows {
+			sortBranchAncestors(row)
+			rows = append(rows, row)
+		}
+		if len(nextPageToken) == 0 {
+			return rows, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// historyBranchKey is the partition key of history_node table
+func historyBranchKey(treeID, branchID string) string {
+	return joinKey(treeID, branchID)
+}
+
+// historyNodeSortKey returns the sort key of history_node table.
+// Nodes are sorted by node ID in ascending order, and then by txn ID in descending order.
+func historyNodeSortKey(nodeID, txnID int64) string {
+	return joinKey(encodeInt64(nodeID), encodeInt64(-txnID))
+}
+
+func toHistoryTreeRows(items []map[string]*dynamodb.AttributeValue) ([]*nosqlplugin.HistoryTreeRow, error) {
+	var rows []*nosqlplugin.HistoryTreeRow
+	for _, av := range items {
+		var item cadence.Item
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, err
+		}
+		row := &nosqlplugin.HistoryTreeRow{}
+		if err := decodeData(&item, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// sortBranchAncestors sorts the ancestors by EndNodeID and sets the BeginNodeID
+func sortBranchAncestors(row *nosqlplugin.HistoryTreeRow) {
+	ancestors := row.Ancestors
+	if len(ancestors) == 0 {
+		return
+	}
+	sort.Slice(ancestors, func(i, j int) bool { return ancestors[i].EndNodeID < ancestors[j].EndNodeID })
+	ancestors[0].BeginNodeID = int64(1)
+	for i := 1; i < len(ancestors); i++ {
+		ancestors[i].BeginNodeID = ancestors[i-1].EndNodeID
+	}
 }
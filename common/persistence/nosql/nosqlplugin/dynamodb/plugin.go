@@ -0,0 +1,130 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+)
+
+const (
+	// PluginName is the name of the plugin
+	PluginName = "dynamodb"
+
+	defaultRegion = "us-east-1"
+)
+
+type plugin struct{}
+
+var _ nosqlplugin.Plugin = (*plugin)(nil)
+
+func init() {
+	nosql.RegisterPlugin(PluginName, &plugin{})
+}
+
+// CreateDB initialize the db object
+func (p *plugin) CreateDB(cfg *config.NoSQL, logger log.Logger, dc *persistence.DynamicConfiguration) (nosqlplugin.DB, error) {
+	return p.doCreateDB(cfg, logger)
+}
+
+// CreateAdminDB initialize the AdminDB object
+func (p *plugin) CreateAdminDB(cfg *config.NoSQL, logger log.Logger, dc *persistence.DynamicConfiguration) (nosqlplugin.AdminDB, error) {
+	return p.doCreateDB(cfg, logger)
+}
+
+func (p *plugin) doCreateDB(cfg *config.NoSQL, logger log.Logger) (*ddb, error) {
+	awsConfig, err := newAWSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return newDynamoDBWithClient(cfg, dynamodb.New(sess), logger), nil
+}
+
+// newAWSConfig builds the AWS config from the NoSQL config:
+// Region is the AWS region, and it defaults to us-east-1.
+// Hosts(with optional Port) overrides the endpoint, which is needed for DynamoDB Local or other compatible services.
+// User/Password are used as static access key/secret key, otherwise the default credential chain of AWS SDK is used.
+func newAWSConfig(cfg *config.NoSQL) (*aws.Config, error) {
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	awsConfig := &aws.Config{
+		Region: aws.String(region),
+	}
+	if cfg.Timeout > 0 {
+		awsConfig.HTTPClient = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	endpoint, err := buildEndpoint(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != "" {
+		awsConfig.Endpoint = aws.String(endpoint)
+	}
+	if cfg.User != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(cfg.User, cfg.Password, "")
+	}
+	return awsConfig, nil
+}
+
+func buildEndpoint(cfg *config.NoSQL) (string, error) {
+	hosts := strings.TrimSpace(cfg.Hosts)
+	if hosts == "" {
+		return "", nil
+	}
+	host := strings.TrimSpace(strings.Split(hosts, ",")[0])
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host, nil
+	}
+	scheme := "http"
+	if cfg.TLS != nil && cfg.TLS.Enabled {
+		scheme = "https"
+	}
+	if cfg.Port != 0 {
+		if _, _, err := net.SplitHostPort(host); err == nil {
+			return "", fmt.Errorf("dynamodb host %v already contains a port, but port %v is also configured", host, cfg.Port)
+		}
+		host = net.JoinHostPort(host, strconv.Itoa(cfg.Port))
+	}
+	return scheme + "://" + host, nil
+}
@@ -0,0 +1,93 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common/config"
+)
+
+func Test_buildEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.NoSQL
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no hosts uses the default AWS endpoint",
+			cfg:  &config.NoSQL{},
+			want: "",
+		},
+		{
+			name: "host and port",
+			cfg:  &config.NoSQL{Hosts: "localhost", Port: 8000},
+			want: "http://localhost:8000",
+		},
+		{
+			name: "first host is used",
+			cfg:  &config.NoSQL{Hosts: "host1, host2"},
+			want: "http://host1",
+		},
+		{
+			name: "tls enabled",
+			cfg:  &config.NoSQL{Hosts: "dynamodb.local", TLS: &config.TLS{Enabled: true}},
+			want: "https://dynamodb.local",
+		},
+		{
+			name: "full url",
+			cfg:  &config.NoSQL{Hosts: "https://dynamodb.us-west-2.amazonaws.com"},
+			want: "https://dynamodb.us-west-2.amazonaws.com",
+		},
+		{
+			name:    "port configured twice",
+			cfg:     &config.NoSQL{Hosts: "localhost:8000", Port: 8000},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildEndpoint(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newAWSConfig(t *testing.T) {
+	awsConfig, err := newAWSConfig(&config.NoSQL{Hosts: "localhost", Port: 8000, User: "key", Password: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRegion, *awsConfig.Region)
+	assert.Equal(t, "http://localhost:8000", *awsConfig.Endpoint)
+	credentials, err := awsConfig.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "key", credentials.AccessKeyID)
+	assert.Equal(t, "secret", credentials.SecretAccessKey)
+}
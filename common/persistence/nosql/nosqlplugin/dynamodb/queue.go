@@ -22,9 +22,19 @@ package dynamodb
 
 import (
 	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+const (
+	queueMetadataSortKey = "metadata"
+	queueComponentName   = "queue"
 )
 
 // Insert message into queue, return error if failed or already exists
@@ -33,7 +43,15 @@ func (db *ddb) InsertIntoQueue(
 	ctx context.Context,
 	row *nosqlplugin.QueueMessageRow,
 ) error {
-	panic("TODO")
+	item, err := newItem(queueKey(row.QueueType), encodeInt64(row.ID), row, 0)
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.QueueMessageTableName, item, aws.String("attribute_not_exists(pk)"), nil)
+	if db.IsConditionFailedError(err) {
+		return nosqlplugin.NewConditionFailure(queueComponentName)
+	}
+	return err
 }
 
 // Get the ID of last message inserted into the queue
@@ -41,7 +59,26 @@ func (db *ddb) SelectLastEnqueuedMessageID(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (int64, error) {
-	panic("TODO")
+	items, _, err := db.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.QueueMessageTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(queueKey(queueType)),
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(1),
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, errNotFound
+	}
+	rows, err := decodeRows[nosqlplugin.QueueMessageRow](items)
+	if err != nil {
+		return 0, err
+	}
+	return rows[0].ID, nil
 }
 
 // Read queue messages starting from the exclusiveBeginMessageID
@@ -51,7 +88,18 @@ func (db *ddb) SelectMessagesFrom(
 	exclusiveBeginMessageID int64,
 	maxRows int,
 ) ([]*nosqlplugin.QueueMessageRow, error) {
-	panic("TODO")
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.QueueMessageTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk > :begin"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":    stringValue(queueKey(queueType)),
+			":begin": stringValue(encodeInt64(exclusiveBeginMessageID)),
+		},
+	}, maxRows)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRows[nosqlplugin.QueueMessageRow](items)
 }
 
 // Read queue message starting from exclusiveBeginMessageID int64, inclusiveEndMessageID int64
@@ -59,7 +107,33 @@ func (db *ddb) SelectMessagesBetween(
 	ctx context.Context,
 	request nosqlplugin.SelectMessagesBetweenRequest,
 ) (*nosqlplugin.SelectMessagesBetweenResponse, error) {
-	panic("TODO")
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.QueueMessageTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :begin AND :end"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":    stringValue(queueKey(request.QueueType)),
+			":begin": stringValue(encodeInt64(request.ExclusiveBeginMessageID + 1)),
+			":end":   stringValue(encodeInt64(request.InclusiveEndMessageID)),
+		},
+	}
+	if request.PageSize > 0 {
+		input.Limit = aws.Int64(int64(request.PageSize))
+	}
+	items, nextPageToken, err := db.query(ctx, input, request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := decodeRows[nosqlplugin.QueueMessageRow](items)
+	if err != nil {
+		return nil, err
+	}
+	response := &nosqlplugin.SelectMessagesBetweenResponse{
+		NextPageToken: nextPageToken,
+	}
+	for _, row := range rows {
+		response.Rows = append(response.Rows, *row)
+	}
+	return response, nil
 }
 
 // Delete all messages before exclusiveBeginMessageID
@@ -68,7 +142,15 @@ func (db *ddb) DeleteMessagesBefore(
 	queueType persistence.QueueType,
 	exclusiveBeginMessageID int64,
 ) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.QueueMessageTableName, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.QueueMessageTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk < :end"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(queueKey(queueType)),
+			":end": stringValue(encodeInt64(exclusiveBeginMessageID)),
+		},
+	})
+	return err
 }
 
 // Delete all messages in a range between exclusiveBeginMessageID and inclusiveEndMessageID
@@ -78,7 +160,16 @@ func (db *ddb) DeleteMessagesInRange(
 	exclusiveBeginMessageID int64,
 	inclusiveEndMessageID int64,
 ) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.QueueMessageTableName, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.QueueMessageTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :begin AND :end"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":    stringValue(queueKey(queueType)),
+			":begin": stringValue(encodeInt64(exclusiveBeginMessageID + 1)),
+			":end":   stringValue(encodeInt64(inclusiveEndMessageID)),
+		},
+	})
+	return err
 }
 
 // Delete one message
@@ -87,7 +178,7 @@ func (db *ddb) DeleteMessage(
 	queueType persistence.QueueType,
 	messageID int64,
 ) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.QueueMessageTableName, itemKey(queueKey(queueType), encodeInt64(messageID)), nil, nil)
 }
 
 // Insert an empty metadata row, starting from a version
@@ -96,7 +187,20 @@ func (db *ddb) InsertQueueMetadata(
 	queueType persistence.QueueType,
 	version int64,
 ) error {
-	panic("TODO")
+	item, err := newQueueMetadataItem(nosqlplugin.QueueMetadataRow{
+		QueueType:        queueType,
+		ClusterAckLevels: map[string]int64{},
+		Version:          version,
+	})
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.QueueMetadataTableName, item, aws.String("attribute_not_exists(pk)"), nil)
+	if db.IsConditionFailedError(err) {
+		// it's ok if the item is not inserted, which means that the record exists already.
+		return nil
+	}
+	return err
 }
 
 // **Conditionally** update a queue metadata row, if current version is matched(meaning current == row.Version - 1),
@@ -106,7 +210,19 @@ func (db *ddb) UpdateQueueMetadataCas(
 	ctx context.Context,
 	row nosqlplugin.QueueMetadataRow,
 ) error {
-	panic("TODO")
+	item, err := newQueueMetadataItem(row)
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.QueueMetadataTableName, item,
+		aws.String("version = :previous_version"),
+		map[string]*dynamodb.AttributeValue{
+			":previous_version": numberValue(row.Version - 1),
+		})
+	if db.IsConditionFailedError(err) {
+		return nosqlplugin.NewConditionFailure(queueComponentName)
+	}
+	return err
 }
 
 // Read a QueueMetadata
@@ -114,12 +230,48 @@ func (db *ddb) SelectQueueMetadata(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (*nosqlplugin.QueueMetadataRow, error) {
-	panic("TODO")
+	var item cadence.QueueMetadataItem
+	if err := db.getItem(ctx, cadence.QueueMetadataTableName, itemKey(queueKey(queueType), queueMetadataSortKey), &item); err != nil {
+		return nil, err
+	}
+	row := &nosqlplugin.QueueMetadataRow{}
+	if err := decodeData(&item.Item, row); err != nil {
+		return nil, err
+	}
+	// if record exist but ackLevels is empty, we initialize the map
+	if row.ClusterAckLevels == nil {
+		row.ClusterAckLevels = make(map[string]int64)
+	}
+	row.QueueType = queueType
+	row.Version = item.Version
+	return row, nil
 }
 
 func (db *ddb) GetQueueSize(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (int64, error) {
-	panic("TODO")
+	return db.count(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.QueueMessageTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(queueKey(queueType)),
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+}
+
+func queueKey(queueType persistence.QueueType) string {
+	return strconv.Itoa(int(queueType))
+}
+
+func newQueueMetadataItem(row nosqlplugin.QueueMetadataRow) (*cadence.QueueMetadataItem, error) {
+	item, err := newItem(queueKey(row.QueueType), queueMetadataSortKey, &row, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.QueueMetadataItem{
+		Item:    item,
+		Version: row.Version,
+	}, nil
 }
@@ -22,29 +22,127 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+const (
+	shardSortKey = "shard"
 )
 
 // InsertShard creates a new shard, return error is there is any.
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *ddb) InsertShard(ctx context.Context, row *nosqlplugin.ShardRow) error {
-	panic("TODO")
+	item, err := newShardItem(row, row.RangeID)
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.ShardTableName, item,
+		aws.String("attribute_not_exists(pk)"), nil)
+	if db.IsConditionFailedError(err) {
+		return db.newShardConditionFailure(ctx, row.ShardID, "shard already exists")
+	}
+	return err
 }
 
 // SelectShard gets a shard
 func (db *ddb) SelectShard(ctx context.Context, shardID int, currentClusterName string) (int64, *nosqlplugin.ShardRow, error) {
-	panic("TODO")
+	var item cadence.ShardItem
+	if err := db.getItem(ctx, cadence.ShardTableName, itemKey(shardKey(shardID), shardSortKey), &item); err != nil {
+		return 0, nil, err
+	}
+	row := &nosqlplugin.ShardRow{}
+	if err := decodeData(&item.Item, row); err != nil {
+		return 0, nil, err
+	}
+
+	if row.ClusterTransferAckLevel == nil {
+		row.ClusterTransferAckLevel = map[string]int64{
+			currentClusterName: row.TransferAckLevel,
+		}
+	}
+	if row.ClusterTimerAckLevel == nil {
+		row.ClusterTimerAckLevel = map[string]time.Time{
+			currentClusterName: row.TimerAckLevel,
+		}
+	}
+	if row.ClusterReplicationLevel == nil {
+		row.ClusterReplicationLevel = make(map[string]int64)
+	}
+	if row.ReplicationDLQAckLevel == nil {
+		row.ReplicationDLQAckLevel = make(map[string]int64)
+	}
+	return item.RangeID, row, nil
 }
 
 // UpdateRangeID updates the rangeID, return error is there is any
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *ddb) UpdateRangeID(ctx context.Context, shardID int, rangeID int64, previousRangeID int64) error {
-	panic("TODO")
+	_, err := db.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(db.tableName(cadence.ShardTableName)),
+		Key:                 itemKey(shardKey(shardID), shardSortKey),
+		UpdateExpression:    aws.String("SET range_id = :range_id"),
+		ConditionExpression: aws.String("range_id = :previous_range_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":range_id":          numberValue(rangeID),
+			":previous_range_id": numberValue(previousRangeID),
+		},
+	})
+	err = convertConditionalCheckFailed(err)
+	if db.IsConditionFailedError(err) {
+		return db.newShardConditionFailure(ctx, shardID, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return err
 }
 
 // UpdateShard updates a shard, return error is there is any.
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *ddb) UpdateShard(ctx context.Context, row *nosqlplugin.ShardRow, previousRangeID int64) error {
-	panic("TODO")
+	item, err := newShardItem(row, row.RangeID)
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.ShardTableName, item,
+		aws.String("range_id = :previous_range_id"),
+		map[string]*dynamodb.AttributeValue{
+			":previous_range_id": numberValue(previousRangeID),
+		})
+	if db.IsConditionFailedError(err) {
+		return db.newShardConditionFailure(ctx, row.ShardID, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return err
+}
+
+func newShardItem(row *nosqlplugin.ShardRow, rangeID int64) (*cadence.ShardItem, error) {
+	item, err := newItem(shardKey(row.ShardID), shardSortKey, row, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.ShardItem{
+		Item:    item,
+		RangeID: rangeID,
+	}, nil
+}
+
+// newShardConditionFailure reads the current range_id of the shard to build the condition failure error
+func (db *ddb) newShardConditionFailure(ctx context.Context, shardID int, details string) error {
+	var item cadence.ShardItem
+	if err := db.getItem(ctx, cadence.ShardTableName, itemKey(shardKey(shardID), shardSortKey), &item); err != nil {
+		if db.IsNotFoundError(err) {
+			return &nosqlplugin.ShardOperationConditionFailure{
+				Details: fmt.Sprintf("shard %v not found: %v", shardID, details),
+			}
+		}
+		return err
+	}
+	return &nosqlplugin.ShardOperationConditionFailure{
+		RangeID: item.RangeID,
+		Details: fmt.Sprintf("shard_id=%v,range_id=%v: %v", shardID, item.RangeID, details),
+	}
 }
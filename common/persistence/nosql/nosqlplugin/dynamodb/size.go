@@ -0,0 +1,148 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common/persistence"
+)
+
+const (
+	// maxItemSize is the max size of an item, including the attribute names
+	maxItemSize = 400 * 1024
+	// maxTransactionSize is the max aggregate size of the items of a TransactWriteItems call
+	maxTransactionSize = 4 * 1024 * 1024
+	// itemSizeErrorMessage is the message of the ValidationException returned when an item is too large,
+	// which happens when an update expression makes an existing item grow over the limit
+	itemSizeErrorMessage = "Item size has exceeded the maximum allowed size"
+)
+
+// itemSize returns the size of an item as DynamoDB accounts it: the lengths of the attribute names plus the sizes
+// of the values. Numbers are counted with their string length, which is an upper bound of their stored size.
+func itemSize(item map[string]*dynamodb.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + attributeValueSize(value)
+	}
+	return size
+}
+
+func attributeValueSize(v *dynamodb.AttributeValue) int {
+	if v == nil {
+		return 0
+	}
+	switch {
+	case v.S != nil:
+		return len(*v.S)
+	case v.N != nil:
+		return len(*v.N)
+	case v.B != nil:
+		return len(v.B)
+	case v.BOOL != nil, v.NULL != nil:
+		return 1
+	case v.M != nil:
+		// 3 bytes of overhead for a document
+		return 3 + itemSize(v.M)
+	case v.L != nil:
+		size := 3
+		for _, e := range v.L {
+			// 1 byte of overhead for each element
+			size += 1 + attributeValueSize(e)
+		}
+		return size
+	}
+	size := 0
+	for _, s := range v.SS {
+		size += len(aws.StringValue(s))
+	}
+	for _, n := range v.NS {
+		size += len(aws.StringValue(n))
+	}
+	for _, b := range v.BS {
+		size += len(b)
+	}
+	return size
+}
+
+// checkItemSize returns a *persistence.TransactionSizeLimitError if the item can't be stored
+func checkItemSize(table string, item map[string]*dynamodb.AttributeValue) error {
+	if size := itemSize(item); size > maxItemSize {
+		return &persistence.TransactionSizeLimitError{
+			Msg: fmt.Sprintf("item of table %v is %v bytes, which exceeds the DynamoDB limit of %v bytes", table, size, maxItemSize),
+		}
+	}
+	return nil
+}
+
+// checkTransactionSize checks the size of each item written by the transaction, and their aggregate size.
+// Only the values of an update are known, so an update can still fail if it makes the existing item too large,
+// that error is converted by convertItemSizeError.
+func checkTransactionSize(items []*dynamodb.TransactWriteItem) error {
+	total := 0
+	for _, item := range items {
+		switch {
+		case item.Put != nil:
+			if err := checkItemSize(aws.StringValue(item.Put.TableName), item.Put.Item); err != nil {
+				return err
+			}
+			total += itemSize(item.Put.Item)
+		case item.Update != nil:
+			if err := checkItemSize(aws.StringValue(item.Update.TableName), item.Update.ExpressionAttributeValues); err != nil {
+				return err
+			}
+			total += itemSize(item.Update.ExpressionAttributeValues)
+		}
+	}
+	if total > maxTransactionSize {
+		return &persistence.TransactionSizeLimitError{
+			Msg: fmt.Sprintf("transaction is %v bytes, which exceeds the DynamoDB limit of %v bytes", total, maxTransactionSize),
+		}
+	}
+	return nil
+}
+
+// convertItemSizeError converts the validation error of an item exceeding the size limit
+// to a *persistence.TransactionSizeLimitError
+func convertItemSizeError(err error) error {
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for _, reason := range canceled.CancellationReasons {
+			if reason != nil && strings.Contains(aws.StringValue(reason.Message), itemSizeErrorMessage) {
+				return &persistence.TransactionSizeLimitError{Msg: aws.StringValue(reason.Message)}
+			}
+		}
+		return err
+	}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && strings.Contains(aerr.Message(), itemSizeErrorMessage) {
+		return &persistence.TransactionSizeLimitError{Msg: aerr.Message()}
+	}
+	return err
+}
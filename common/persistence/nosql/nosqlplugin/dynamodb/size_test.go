@@ -0,0 +1,102 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+func TestItemSize(t *testing.T) {
+	item := map[string]*dynamodb.AttributeValue{
+		"pk":   stringValue("abc"),
+		"n":    numberValue(12345),
+		"data": {B: make([]byte, 10)},
+		"m":    {M: map[string]*dynamodb.AttributeValue{"k": {BOOL: aws.Bool(true)}}},
+		"l":    {L: []*dynamodb.AttributeValue{stringValue("xy")}},
+	}
+	// pk: 2+3, n: 1+5, data: 4+10, m: 1+3+1+1, l: 1+3+1+2
+	assert.Equal(t, 38, itemSize(item))
+}
+
+func TestCheckTransactionSize(t *testing.T) {
+	small := map[string]*dynamodb.AttributeValue{"data": {B: make([]byte, 1024)}}
+	large := map[string]*dynamodb.AttributeValue{"data": {B: make([]byte, maxItemSize)}}
+
+	assert.NoError(t, checkTransactionSize([]*dynamodb.TransactWriteItem{{Put: &dynamodb.Put{Item: small}}}))
+
+	err := checkTransactionSize([]*dynamodb.TransactWriteItem{{Put: &dynamodb.Put{Item: small}}, {Put: &dynamodb.Put{Item: large}}})
+	assert.IsType(t, &persistence.TransactionSizeLimitError{}, err)
+
+	err = checkTransactionSize([]*dynamodb.TransactWriteItem{{Update: &dynamodb.Update{ExpressionAttributeValues: large}}})
+	assert.IsType(t, &persistence.TransactionSizeLimitError{}, err)
+
+	var items []*dynamodb.TransactWriteItem
+	for i := 0; i < maxTransactionSize/maxItemSize+1; i++ {
+		items = append(items, &dynamodb.TransactWriteItem{Put: &dynamodb.Put{Item: map[string]*dynamodb.AttributeValue{
+			"data": {B: make([]byte, maxItemSize-10)},
+		}}})
+	}
+	err = checkTransactionSize(items)
+	require.IsType(t, &persistence.TransactionSizeLimitError{}, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "transaction is"))
+}
+
+func TestConvertItemSizeError(t *testing.T) {
+	err := convertItemSizeError(&dynamodb.TransactionCanceledException{
+		Message_: aws.String("Transaction cancelled"),
+		CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ValidationError"), Message: aws.String(itemSizeErrorMessage)},
+		},
+	})
+	assert.IsType(t, &persistence.TransactionSizeLimitError{}, err)
+
+	canceled := &dynamodb.TransactionCanceledException{Message_: aws.String("Transaction cancelled")}
+	assert.Equal(t, canceled, convertItemSizeError(canceled))
+	assert.NoError(t, convertItemSizeError(nil))
+}
+
+func TestInsertIntoHistoryTreeAndNode_TooLarge(t *testing.T) {
+	client := &fakeClient{}
+	db := newTestDB(client)
+	err := db.InsertIntoHistoryTreeAndNode(context.Background(), &nosqlplugin.HistoryTreeRow{TreeID: "tree", BranchID: "branch"}, &nosqlplugin.HistoryNodeRow{
+		TreeID:   "tree",
+		BranchID: "branch",
+		NodeID:   1,
+		Data:     make([]byte, maxItemSize),
+	})
+	assert.IsType(t, &persistence.TransactionSizeLimitError{}, err)
+	assert.Empty(t, client.transactions)
+	assert.Contains(t, err.Error(), db.tableName(cadence.HistoryNodeTableName))
+}
@@ -22,20 +22,47 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+const (
+	initialRangeID = 1 // Id of the first range of a new task list
+	// taskListSortKey is the sort key of tasklist items, the partition key is from taskListKey
+	taskListSortKey = "tasklist"
 )
 
 // SelectTaskList returns a single tasklist row.
 // Return IsNotFoundError if the row doesn't exist
 func (db *ddb) SelectTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter) (*nosqlplugin.TaskListRow, error) {
-	panic("TODO")
+	var item cadence.TaskListItem
+	if err := db.getItem(ctx, cadence.TaskListTableName, itemKey(taskListKey(filter), taskListSortKey), &item); err != nil {
+		return nil, err
+	}
+	return toTaskListRow(&item)
 }
 
 // InsertTaskList insert a single tasklist row
 // Return IsConditionFailedError if the row already exists, and also the existing row
 func (db *ddb) InsertTaskList(ctx context.Context, row *nosqlplugin.TaskListRow) error {
-	panic("TODO")
+	newRow := *row
+	newRow.RangeID = initialRangeID
+	newRow.AckLevel = 0
+	item, err := newTaskListItem(&newRow, 0)
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.TaskListTableName, item, aws.String("attribute_not_exists(pk)"), nil)
+	if db.IsConditionFailedError(err) {
+		return db.newTaskListConditionFailure(ctx, taskListFilterOf(row), "tasklist already exists")
+	}
+	return err
 }
 
 // UpdateTaskList updates a single tasklist row
@@ -45,7 +72,7 @@ func (db *ddb) UpdateTaskList(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
-	panic("TODO")
+	return db.updateTaskList(ctx, 0, row, previousRangeID)
 }
 
 // UpdateTaskList updates a single tasklist row, and set an TTL on the record
@@ -58,39 +85,167 @@ func (db *ddb) UpdateTaskListWithTTL(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
-	panic("TODO")
+	return db.updateTaskList(ctx, ttlSeconds, row, previousRangeID)
+}
+
+func (db *ddb) updateTaskList(
+	ctx context.Context,
+	ttlSeconds int64,
+	row *nosqlplugin.TaskListRow,
+	previousRangeID int64,
+) error {
+	item, err := newTaskListItem(row, ttlFromNow(ttlSeconds))
+	if err != nil {
+		return err
+	}
+	err = db.putItem(ctx, cadence.TaskListTableName, item,
+		aws.String("range_id = :previous_range_id"),
+		map[string]*dynamodb.AttributeValue{
+			":previous_range_id": numberValue(previousRangeID),
+		})
+	if db.IsConditionFailedError(err) {
+		return db.newTaskListConditionFailure(ctx, taskListFilterOf(row), fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return err
 }
 
 // ListTaskList returns all tasklists.
 // Noop if TTL is already implemented in other methods
 func (db *ddb) ListTaskList(ctx context.Context, pageSize int, nextPageToken []byte) (*nosqlplugin.ListTaskListResult, error) {
-	panic("TODO")
+	input := &dynamodb.ScanInput{
+		TableName:      aws.String(db.tableName(cadence.TaskListTableName)),
+		ConsistentRead: aws.Bool(true),
+	}
+	if pageSize > 0 {
+		input.Limit = aws.Int64(int64(pageSize))
+	}
+	items, nextPageToken, err := db.scan(ctx, input, nextPageToken)
+	if err != nil {
+		return nil, err
+	}
+	result := &nosqlplugin.ListTaskListResult{
+		NextPageToken: nextPageToken,
+	}
+	for _, av := range items {
+		var item cadence.TaskListItem
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, err
+		}
+		row, err := toTaskListRow(&item)
+		if err != nil {
+			return nil, err
+		}
+		result.TaskLists = append(result.TaskLists, row)
+	}
+	return result, nil
 }
 
 // DeleteTaskList deletes a single tasklist row
 // Return TaskOperationConditionFailure if the condition doesn't meet
 func (db *ddb) DeleteTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter, previousRangeID int64) error {
-	panic("TODO")
+	err := db.deleteItem(ctx, cadence.TaskListTableName, itemKey(taskListKey(filter), taskListSortKey),
+		aws.String("range_id = :previous_range_id"),
+		map[string]*dynamodb.AttributeValue{
+			":previous_range_id": numberValue(previousRangeID),
+		})
+	if db.IsConditionFailedError(err) {
+		return db.newTaskListConditionFailure(ctx, filter, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return err
 }
 
 // InsertTasks inserts a batch of tasks
 // Return TaskOperationConditionFailure if the condition doesn't meet
+// NOTE: a transaction can contain at most 100 items, so a large batch of tasks is written in multiple transactions,
+// each of them checks the range_id of the tasklist.
 func (db *ddb) InsertTasks(
 	ctx context.Context,
 	tasksToInsert []*nosqlplugin.TaskRowForInsert,
 	tasklistCondition *nosqlplugin.TaskListRow,
 ) error {
-	panic("TODO")
+	filter := taskListFilterOf(tasklistCondition)
+	pk := taskListKey(filter)
+	rangeCondition := &dynamodb.TransactWriteItem{
+		ConditionCheck: &dynamodb.ConditionCheck{
+			TableName:           aws.String(db.tableName(cadence.TaskListTableName)),
+			Key:                 itemKey(pk, taskListSortKey),
+			ConditionExpression: aws.String("range_id = :range_id"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":range_id": numberValue(tasklistCondition.RangeID),
+			},
+		},
+	}
+
+	var items []*dynamodb.TransactWriteItem
+	for _, task := range tasksToInsert {
+		row := task.TaskRow
+		row.DomainID = filter.DomainID
+		row.TaskListName = filter.TaskListName
+		row.TaskListType = filter.TaskListType
+		item, err := newItem(pk, encodeInt64(task.TaskID), &row, ttlFromNow(int64(task.TTLSeconds)))
+		if err != nil {
+			return err
+		}
+		av, err := marshalItem(item)
+		if err != nil {
+			return err
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName: aws.String(db.tableName(cadence.TaskTableName)),
+				Item:      av,
+			},
+		})
+	}
+
+	for start := 0; start < len(items) || start == 0; start += maxTransactionItems - 1 {
+		end := start + maxTransactionItems - 1
+		if end > len(items) {
+			end = len(items)
+		}
+		// range condition is the first item of each transaction
+		transaction := append([]*dynamodb.TransactWriteItem{rangeCondition}, items[start:end]...)
+		if err := db.transactWrite(ctx, transaction); err != nil {
+			if failure, ok := err.(*transactionConditionFailure); ok {
+				if _, ok := failure.failed(0); ok {
+					return db.newTaskListConditionFailure(ctx, filter, fmt.Sprintf("range_id %v not match", tasklistCondition.RangeID))
+				}
+			}
+			return err
+		}
+	}
+	return nil
 }
 
 // SelectTasks return tasks that associated to a tasklist
 func (db *ddb) SelectTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) ([]*nosqlplugin.TaskRow, error) {
-	panic("TODO")
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.TaskTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :min AND :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(taskListKey(&filter.TaskListFilter)),
+			":min": stringValue(encodeInt64(filter.MinTaskID + 1)),
+			":max": stringValue(encodeInt64(filter.MaxTaskID)),
+		},
+	}, filter.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRows[nosqlplugin.TaskRow](items)
 }
 
 // SelectTasks return tasks that associated to a tasklist
 func (db *ddb) GetTasksCount(ctx context.Context, filter *nosqlplugin.TasksFilter) (int64, error) {
-	panic("TODO")
+	return db.count(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.TaskTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk > :min"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(taskListKey(&filter.TaskListFilter)),
+			":min": stringValue(encodeInt64(filter.MinTaskID)),
+		},
+		ConsistentRead: aws.Bool(true),
+	})
 }
 
 // DeleteTask delete a batch tasks that taskIDs less than the row
@@ -98,5 +253,64 @@ func (db *ddb) GetTasksCount(ctx context.Context, filter *nosqlplugin.TasksFilte
 // NOTE: This API ignores the `BatchSize` request parameter i.e. either all tasks leq the task_id will be deleted or an error will
 // be returned to the caller, because rowsDeleted is not supported by Cassandra
 func (db *ddb) RangeDeleteTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) (rowsDeleted int, err error) {
-	panic("TODO")
+	return db.deleteByQuery(ctx, cadence.TaskTableName, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.TaskTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :min AND :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(taskListKey(&filter.TaskListFilter)),
+			":min": stringValue(encodeInt64(filter.MinTaskID + 1)),
+			":max": stringValue(encodeInt64(filter.MaxTaskID)),
+		},
+	})
+}
+
+// taskListKey is the partition key of both tasklist and task items.
+// Domain ID has fixed length and tasklist type is a number, so the key is unique for any tasklist name.
+func taskListKey(filter *nosqlplugin.TaskListFilter) string {
+	return joinKey(filter.DomainID, filter.TaskListName, strconv.Itoa(filter.TaskListType))
+}
+
+func taskListFilterOf(row *nosqlplugin.TaskListRow) *nosqlplugin.TaskListFilter {
+	return &nosqlplugin.TaskListFilter{
+		DomainID:     row.DomainID,
+		TaskListName: row.TaskListName,
+		TaskListType: row.TaskListType,
+	}
+}
+
+func newTaskListItem(row *nosqlplugin.TaskListRow, ttl int64) (*cadence.TaskListItem, error) {
+	item, err := newItem(taskListKey(taskListFilterOf(row)), taskListSortKey, row, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.TaskListItem{
+		Item:    item,
+		RangeID: row.RangeID,
+	}, nil
+}
+
+func toTaskListRow(item *cadence.TaskListItem) (*nosqlplugin.TaskListRow, error) {
+	row := &nosqlplugin.TaskListRow{}
+	if err := decodeData(&item.Item, row); err != nil {
+		return nil, err
+	}
+	row.RangeID = item.RangeID
+	return row, nil
+}
+
+// newTaskListConditionFailure reads the current range_id of the tasklist to build the condition failure error
+func (db *ddb) newTaskListConditionFailure(ctx context.Context, filter *nosqlplugin.TaskListFilter, details string) error {
+	var item cadence.TaskListItem
+	if err := db.getItem(ctx, cadence.TaskListTableName, itemKey(taskListKey(filter), taskListSortKey), &item); err != nil {
+		if db.IsNotFoundError(err) {
+			return &nosqlplugin.TaskOperationConditionFailure{
+				Details: fmt.Sprintf("tasklist %v not found: %v", filter.TaskListName, details),
+			}
+		}
+		return err
+	}
+	return &nosqlplugin.TaskOperationConditionFailure{
+		RangeID: item.RangeID,
+		Details: fmt.Sprintf("range_id=%v: %v", item.RangeID, details),
+	}
 }
@@ -0,0 +1,250 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+const (
+	keySeparator = "#"
+	// dataEncodingJSON is the encoding of data attribute
+	dataEncodingJSON = string(common.EncodingTypeJSON)
+	// int64Width is the number of digits of max uint64
+	int64Width = 20
+)
+
+// encodeInt64 encodes an int64 into a fixed width string, so that the lexicographical order
+// of the strings is the same as the numerical order of the numbers(including negative numbers)
+func encodeInt64(v int64) string {
+	return fmt.Sprintf("%0*d", int64Width, uint64(v)^(1<<63))
+}
+
+// encodeTime encodes a time into a fixed width string with the same order, see encodeInt64
+func encodeTime(t time.Time) string {
+	return encodeInt64(t.UnixNano())
+}
+
+// joinKey concatenates the parts of a composite key
+func joinKey(parts ...string) string {
+	return strings.Join(parts, keySeparator)
+}
+
+// shardKey returns the partition key of shard scoped items
+func shardKey(shardID int) string {
+	return strconv.Itoa(shardID)
+}
+
+// ttlFromNow returns the expiration time for the ttl attribute, zero means no expiration
+func ttlFromNow(ttlSeconds int64) int64 {
+	if ttlSeconds <= 0 {
+		return 0
+	}
+	return time.Now().Unix() + ttlSeconds
+}
+
+// itemKey returns the primary key of an item
+func itemKey(pk, sk string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		cadence.PartitionKeyAttribute: stringValue(pk),
+		cadence.SortKeyAttribute:      stringValue(sk),
+	}
+}
+
+// primaryKeyOf returns the primary key part of an item
+func primaryKeyOf(item map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		cadence.PartitionKeyAttribute: item[cadence.PartitionKeyAttribute],
+		cadence.SortKeyAttribute:      item[cadence.SortKeyAttribute],
+	}
+}
+
+func stringValue(s string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(s)}
+}
+
+func numberValue(n int64) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(n, 10))}
+}
+
+// itemEncoder keeps empty maps and lists as they are, instead of NULL, so that they can be updated by expressions
+var itemEncoder = dynamodbattribute.NewEncoder(func(e *dynamodbattribute.Encoder) {
+	e.EnableEmptyCollections = true
+})
+
+func marshalItem(item interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	av, err := itemEncoder.Encode(item)
+	if err != nil {
+		return nil, err
+	}
+	return av.M, nil
+}
+
+func unmarshalItem(av map[string]*dynamodb.AttributeValue, out interface{}) error {
+	return dynamodbattribute.UnmarshalMap(av, out)
+}
+
+// newItem creates the common part of an item, with the row encoded into the data attribute
+func newItem(pk, sk string, row interface{}, ttl int64) (cadence.Item, error) {
+	item := cadence.Item{
+		PartitionKey: pk,
+		SortKey:      sk,
+		TTL:          ttl,
+	}
+	if row != nil {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return item, err
+		}
+		item.Data = data
+		item.DataEncoding = dataEncodingJSON
+	}
+	return item, nil
+}
+
+// decodeData decodes the data attribute of an item into the row
+func decodeData(item *cadence.Item, row interface{}) error {
+	if item.DataEncoding != "" && item.DataEncoding != dataEncodingJSON {
+		return fmt.Errorf("unsupported data encoding %v of item %v/%v", item.DataEncoding, item.PartitionKey, item.SortKey)
+	}
+	return json.Unmarshal(item.Data, row)
+}
+
+// serializePageToken encodes the LastEvaluatedKey of a query or scan as page token.
+// All key attributes of the tables and indexes are strings.
+func serializePageToken(lastEvaluatedKey map[string]*dynamodb.AttributeValue) ([]byte, error) {
+	if len(lastEvaluatedKey) == 0 {
+		return nil, nil
+	}
+	token := make(map[string]string, len(lastEvaluatedKey))
+	for k, v := range lastEvaluatedKey {
+		if v == nil || v.S == nil {
+			return nil, fmt.Errorf("unsupported key attribute %v in page token", k)
+		}
+		token[k] = *v.S
+	}
+	return json.Marshal(token)
+}
+
+// deserializePageToken is the reverse of serializePageToken
+func deserializePageToken(pageToken []byte) (map[string]*dynamodb.AttributeValue, error) {
+	if len(pageToken) == 0 {
+		return nil, nil
+	}
+	var token map[string]string
+	if err := json.Unmarshal(pageToken, &token); err != nil {
+		return nil, fmt.Errorf("invalid page token: %v", err)
+	}
+	startKey := make(map[string]*dynamodb.AttributeValue, len(token))
+	for k, v := range token {
+		startKey[k] = stringValue(v)
+	}
+	return startKey, nil
+}
+
+// updateBuilder builds the update expression of UpdateItem, all the attribute names and values are
+// referred by placeholders so that any map key can be used in the document paths
+type updateBuilder struct {
+	sets    []string
+	removes []string
+	names   map[string]*string
+	values  map[string]*dynamodb.AttributeValue
+}
+
+func newUpdateBuilder() *updateBuilder {
+	return &updateBuilder{
+		names:  make(map[string]*string),
+		values: make(map[string]*dynamodb.AttributeValue),
+	}
+}
+
+// name returns the placeholder of an attribute name or a map key
+func (b *updateBuilder) name(n string) string {
+	placeholder := "#n" + strconv.Itoa(len(b.names))
+	b.names[placeholder] = aws.String(n)
+	return placeholder
+}
+
+// value returns the placeholder of a value
+func (b *updateBuilder) value(v *dynamodb.AttributeValue) string {
+	placeholder := ":v" + strconv.Itoa(len(b.values))
+	b.values[placeholder] = v
+	return placeholder
+}
+
+func (b *updateBuilder) set(attribute string, v *dynamodb.AttributeValue) {
+	b.sets = append(b.sets, b.name(attribute)+" = "+b.value(v))
+}
+
+func (b *updateBuilder) setMapEntry(attribute, key string, v *dynamodb.AttributeValue) {
+	b.sets = append(b.sets, b.name(attribute)+"."+b.name(key)+" = "+b.value(v))
+}
+
+func (b *updateBuilder) removeMapEntry(attribute, key string) {
+	b.removes = append(b.removes, b.name(attribute)+"."+b.name(key))
+}
+
+// appendList appends the elements of the list value to a list attribute
+func (b *updateBuilder) appendList(attribute string, v *dynamodb.AttributeValue) {
+	n := b.name(attribute)
+	b.sets = append(b.sets, n+" = list_append("+n+", "+b.value(v)+")")
+}
+
+func (b *updateBuilder) expression() *string {
+	var clauses []string
+	if len(b.sets) > 0 {
+		clauses = append(clauses, "SET "+strings.Join(b.sets, ", "))
+	}
+	if len(b.removes) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(b.removes, ", "))
+	}
+	return aws.String(strings.Join(clauses, " "))
+}
+
+// decodeRows decodes the data attribute of the items into rows
+func decodeRows[T any](items []map[string]*dynamodb.AttributeValue) ([]*T, error) {
+	rows := make([]*T, 0, len(items))
+	for _, av := range items {
+		var item cadence.Item
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, err
+		}
+		row := new(T)
+		if err := decodeData(&item, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
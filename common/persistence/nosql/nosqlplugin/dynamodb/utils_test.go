@@ -0,0 +1,84 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeInt64_Order(t *testing.T) {
+	values := []int64{math.MinInt64, -1 << 40, -2, -1, 0, 1, 2, 1 << 40, math.MaxInt64}
+	encoded := make([]string, 0, len(values))
+	for _, v := range values {
+		s := encodeInt64(v)
+		assert.Len(t, s, int64Width)
+		encoded = append(encoded, s)
+	}
+	assert.True(t, sort.StringsAreSorted(encoded))
+}
+
+func TestPageToken(t *testing.T) {
+	token, err := serializePageToken(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, token)
+
+	key := itemKey("1", encodeInt64(10))
+	token, err = serializePageToken(key)
+	assert.NoError(t, err)
+	startKey, err := deserializePageToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, key, startKey)
+
+	_, err = serializePageToken(map[string]*dynamodb.AttributeValue{"pk": numberValue(1)})
+	assert.Error(t, err)
+	_, err = deserializePageToken([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestUpdateBuilder(t *testing.T) {
+	b := newUpdateBuilder()
+	b.set("data", stringValue("d"))
+	b.setMapEntry("activity_infos", "5", stringValue("a"))
+	b.removeMapEntry("timer_infos", "t1")
+	b.appendList("buffered_events", &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}})
+
+	assert.Equal(t,
+		"SET #n0 = :v0, #n1.#n2 = :v1, #n5 = list_append(#n5, :v2) REMOVE #n3.#n4",
+		aws.StringValue(b.expression()))
+	assert.Equal(t, map[string]*string{
+		"#n0": aws.String("data"),
+		"#n1": aws.String("activity_infos"),
+		"#n2": aws.String("5"),
+		"#n3": aws.String("timer_infos"),
+		"#n4": aws.String("t1"),
+		"#n5": aws.String("buffered_events"),
+	}, b.names)
+	assert.Len(t, b.values, 3)
+	assert.Equal(t, ":v3", b.value(numberValue(1)))
+}
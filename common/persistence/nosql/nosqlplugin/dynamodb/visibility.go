@@ -22,8 +22,27 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+// Visibility records are partitioned by domain ID and sorted by run ID.
+// Listing is done by querying the global secondary indexes, see VisibilityItem.
+// The start time key has a prefix of the workflow state, so open and closed workflows can be queried separately.
+const (
+	openStartTimeKeyPrefix   = "O"
+	closedStartTimeKeyPrefix = "C"
+	// timeKeyUpperBoundSuffix is greater than the separator, so it can be appended to a time to get
+	// an upper bound which is greater than all the keys of the time
+	timeKeyUpperBoundSuffix = "$"
 )
 
 func (db *ddb) InsertVisibility(
@@ -31,7 +50,16 @@ func (db *ddb) InsertVisibility(
 	ttlSeconds int64,
 	row *nosqlplugin.VisibilityRowForInsert,
 ) error {
-	panic("TODO")
+	item, err := newVisibilityItem(row.DomainID, &row.VisibilityRow, false, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	// the started record must not override the closed record if the close event is recorded first
+	err = db.putItem(ctx, cadence.VisibilityTableName, item, aws.String("attribute_not_exists(close_time_key)"), nil)
+	if db.IsConditionFailedError(err) {
+		return nil
+	}
+	return err
 }
 
 func (db *ddb) UpdateVisibility(
@@ -39,26 +67,171 @@ func (db *ddb) UpdateVisibility(
 	ttlSeconds int64,
 	row *nosqlplugin.VisibilityRowForUpdate,
 ) error {
-	panic("TODO")
+	if row.UpdateCloseToOpen {
+		return fmt.Errorf("updating visibility record from closed to open is not supported")
+	}
+	item, err := newVisibilityItem(row.DomainID, &row.VisibilityRow, true, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	return db.putItem(ctx, cadence.VisibilityTableName, item, nil, nil)
 }
 
 func (db *ddb) SelectVisibility(
 	ctx context.Context,
 	filter *nosqlplugin.VisibilityFilter,
 ) (*nosqlplugin.SelectVisibilityResponse, error) {
-	panic("TODO")
+	request := &filter.ListRequest
+	domainID := request.DomainUUID
+
+	var closed bool
+	var partitionAttribute, partitionValue string
+	switch filter.FilterType {
+	case nosqlplugin.AllOpen, nosqlplugin.AllClosed:
+		partitionAttribute, partitionValue = cadence.DomainIDAttribute, domainID
+	case nosqlplugin.OpenByWorkflowType, nosqlplugin.ClosedByWorkflowType:
+		partitionAttribute, partitionValue = cadence.DomainWorkflowTypeAttribute, joinKey(domainID, filter.WorkflowType)
+	case nosqlplugin.OpenByWorkflowID, nosqlplugin.ClosedByWorkflowID:
+		partitionAttribute, partitionValue = cadence.DomainWorkflowIDAttribute, joinKey(domainID, filter.WorkflowID)
+	case nosqlplugin.ClosedByClosedStatus:
+		partitionAttribute, partitionValue = cadence.DomainCloseStatusAttribute, closeStatusKey(domainID, filter.CloseStatus)
+	default:
+		return nil, fmt.Errorf("not supported filter type %v", filter.FilterType)
+	}
+	switch filter.FilterType {
+	case nosqlplugin.AllClosed, nosqlplugin.ClosedByWorkflowType, nosqlplugin.ClosedByWorkflowID, nosqlplugin.ClosedByClosedStatus:
+		closed = true
+	}
+
+	sortAttribute := cadence.StartTimeKeyAttribute
+	var lowerBound, upperBound string
+	if closed && filter.SortType == nosqlplugin.SortByClosedTime {
+		sortAttribute = cadence.CloseTimeKeyAttribute
+		lowerBound = encodeTime(request.EarliestTime)
+		upperBound = encodeTime(request.LatestTime) + timeKeyUpperBoundSuffix
+	} else if filter.SortType == nosqlplugin.SortByStartTime || !closed {
+		prefix := openStartTimeKeyPrefix
+		if closed {
+			prefix = closedStartTimeKeyPrefix
+		}
+		lowerBound = joinKey(prefix, encodeTime(request.EarliestTime))
+		upperBound = joinKey(prefix, encodeTime(request.LatestTime)) + timeKeyUpperBoundSuffix
+	} else {
+		return nil, fmt.Errorf("not supported sorting type %v", filter.SortType)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.VisibilityTableName)),
+		IndexName:              aws.String(partitionAttribute + "-" + sortAttribute),
+		KeyConditionExpression: aws.String("#pk = :pk AND #sk BETWEEN :lower AND :upper"),
+		ExpressionAttributeNames: map[string]*string{
+			"#pk": aws.String(partitionAttribute),
+			"#sk": aws.String(sortAttribute),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":    stringValue(partitionValue),
+			":lower": stringValue(lowerBound),
+			":upper": stringValue(upperBound),
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	if request.PageSize > 0 {
+		input.Limit = aws.Int64(int64(request.PageSize))
+	}
+	items, nextPageToken, err := db.query(ctx, input, request.NextPageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &nosqlplugin.SelectVisibilityResponse{
+		NextPageToken: nextPageToken,
+	}
+	for _, av := range items {
+		row, err := toVisibilityRow(av)
+		if err != nil {
+			return nil, err
+		}
+		response.Executions = append(response.Executions, row)
+	}
+	return response, nil
 }
 
 func (db *ddb) DeleteVisibility(
 	ctx context.Context,
 	domainID, workflowID, runID string,
 ) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.VisibilityTableName, itemKey(domainID, runID), nil, nil)
 }
 
 func (db *ddb) SelectOneClosedWorkflow(
 	ctx context.Context,
 	domainID, workflowID, runID string,
 ) (*nosqlplugin.VisibilityRow, error) {
-	panic("TODO")
+	resp, err := db.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(db.tableName(cadence.VisibilityTableName)),
+		Key:            itemKey(domainID, runID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Item) == 0 {
+		// Special case: return nil,nil if not found(since we will deprecate it, it's not worth refactor to be consistent)
+		return nil, nil
+	}
+	row, err := toVisibilityRow(resp.Item)
+	if err != nil {
+		return nil, err
+	}
+	if row.WorkflowID != workflowID || row.Status == nil {
+		return nil, nil
+	}
+	return row, nil
+}
+
+func newVisibilityItem(domainID string, row *nosqlplugin.VisibilityRow, closed bool, ttlSeconds int64) (*cadence.VisibilityItem, error) {
+	data := *row
+	data.DomainID = domainID
+	// search attributes are not supported by database visibility
+	data.SearchAttributes = nil
+	item, err := newItem(domainID, row.RunID, &data, ttlFromNow(ttlSeconds))
+	if err != nil {
+		return nil, err
+	}
+
+	visibilityItem := &cadence.VisibilityItem{
+		Item:               item,
+		DomainID:           domainID,
+		DomainWorkflowType: joinKey(domainID, row.TypeName),
+		DomainWorkflowID:   joinKey(domainID, row.WorkflowID),
+		StartTimeKey:       timeKey(openStartTimeKeyPrefix, row.StartTime, row.RunID),
+	}
+	if closed {
+		visibilityItem.StartTimeKey = timeKey(closedStartTimeKeyPrefix, row.StartTime, row.RunID)
+		visibilityItem.CloseTimeKey = joinKey(encodeTime(row.CloseTime), row.RunID)
+		if row.Status != nil {
+			visibilityItem.DomainCloseStatus = closeStatusKey(domainID, int32(*row.Status))
+		}
+	}
+	return visibilityItem, nil
+}
+
+func timeKey(prefix string, t time.Time, runID string) string {
+	return joinKey(prefix, encodeTime(t), runID)
+}
+
+func closeStatusKey(domainID string, status int32) string {
+	return joinKey(domainID, strconv.Itoa(int(status)))
+}
+
+func toVisibilityRow(av map[string]*dynamodb.AttributeValue) (*nosqlplugin.VisibilityRow, error) {
+	var item cadence.VisibilityItem
+	if err := unmarshalItem(av, &item); err != nil {
+		return nil, err
+	}
+	row := &persistence.InternalVisibilityWorkflowExecutionInfo{}
+	if err := decodeData(&item.Item, row); err != nil {
+		return nil, err
+	}
+	return row, nil
 }
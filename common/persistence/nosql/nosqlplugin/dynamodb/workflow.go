@@ -23,10 +23,15 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
 )
 
 var _ nosqlplugin.WorkflowCRUD = (*ddb)(nil)
@@ -42,7 +47,26 @@ func (db *ddb) InsertWorkflowExecutionWithTasks(
 	timerTasks []*nosqlplugin.TimerTask,
 	shardCondition *nosqlplugin.ShardCondition,
 ) error {
-	panic("TODO")
+	shardID := shardCondition.ShardID
+	t := newWorkflowTransaction(db, shardCondition)
+	if err := db.addWorkflowRequests(t, requests); err != nil {
+		return err
+	}
+	if err := db.addCurrentWorkflow(t, shardID, currentWorkflowRequest); err != nil {
+		return err
+	}
+	if err := db.addCreatedExecution(t, shardID, writeItemKindInsertedExecution, execution); err != nil {
+		return err
+	}
+	if err := db.addTasks(t, shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks); err != nil {
+		return err
+	}
+
+	failure, err := t.execute(ctx, db)
+	if err != nil || failure == nil {
+		return err
+	}
+	return db.convertCreateWorkflowConditionFailure(ctx, t, failure, currentWorkflowRequest, execution, shardCondition)
 }
 
 func (db *ddb) UpdateWorkflowExecutionWithTasks(
@@ -58,105 +82,347 @@ func (db *ddb) UpdateWorkflowExecutionWithTasks(
 	timerTasks []*nosqlplugin.TimerTask,
 	shardCondition *nosqlplugin.ShardCondition,
 ) error {
-	panic("TODO")
+	shardID := shardCondition.ShardID
+	var previousNextEventIDCondition int64
+	if mutatedExecution != nil {
+		previousNextEventIDCondition = *mutatedExecution.PreviousNextEventIDCondition
+	} else if resetExecution != nil {
+		previousNextEventIDCondition = *resetExecution.PreviousNextEventIDCondition
+	} else {
+		return fmt.Errorf("at least one of mutatedExecution and resetExecution should be provided")
+	}
+
+	t := newWorkflowTransaction(db, shardCondition)
+	if err := db.addWorkflowRequests(t, requests); err != nil {
+		return err
+	}
+	if err := db.addCurrentWorkflow(t, shardID, currentWorkflowRequest); err != nil {
+		return err
+	}
+	if mutatedExecution != nil {
+		if err := db.addMutatedExecution(t, shardID, mutatedExecution); err != nil {
+			return err
+		}
+	}
+	if insertedExecution != nil {
+		if err := db.addCreatedExecution(t, shardID, writeItemKindInsertedExecution, insertedExecution); err != nil {
+			return err
+		}
+	}
+	if resetExecution != nil {
+		if err := db.addCreatedExecution(t, shardID, writeItemKindResetExecution, resetExecution); err != nil {
+			return err
+		}
+	}
+	if err := db.addTasks(t, shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks); err != nil {
+		return err
+	}
+
+	failure, err := t.execute(ctx, db)
+	if err != nil || failure == nil {
+		return err
+	}
+	return db.convertUpdateWorkflowConditionFailure(ctx, t, failure, currentWorkflowRequest, previousNextEventIDCondition, shardCondition)
 }
 
 func (db *ddb) SelectCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID string) (*nosqlplugin.CurrentWorkflowRow, error) {
-	panic("TODO")
+	var item cadence.CurrentWorkflowItem
+	if err := db.getItem(ctx, cadence.CurrentWorkflowTableName, itemKey(shardKey(shardID), currentWorkflowSortKey(domainID, workflowID)), &item); err != nil {
+		return nil, err
+	}
+	row := &nosqlplugin.CurrentWorkflowRow{}
+	if err := decodeData(&item.Item, row); err != nil {
+		return nil, err
+	}
+	row.ShardID = shardID
+	row.RunID = item.CurrentRunID
+	row.LastWriteVersion = item.LastWriteVersion
+	return row, nil
 }
 
 func (db *ddb) SelectWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) (*nosqlplugin.WorkflowExecution, error) {
-	panic("TODO")
+	var item cadence.WorkflowExecutionItem
+	if err := db.getItem(ctx, cadence.WorkflowExecutionTableName, itemKey(shardKey(shardID), workflowExecutionSortKey(domainID, workflowID, runID)), &item); err != nil {
+		return nil, err
+	}
+	return toWorkflowExecution(&item)
 }
 
+// DeleteCurrentWorkflow deletes the current workflow record if the current run ID matches,
+// the deletion is skipped otherwise
 func (db *ddb) DeleteCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID, currentRunIDCondition string) error {
-	panic("TODO")
+	err := db.deleteItem(ctx, cadence.CurrentWorkflowTableName,
+		itemKey(shardKey(shardID), currentWorkflowSortKey(domainID, workflowID)),
+		aws.String("current_run_id = :current_run_id"),
+		map[string]*dynamodb.AttributeValue{
+			":current_run_id": stringValue(currentRunIDCondition),
+		})
+	if db.IsConditionFailedError(err) {
+		return nil
+	}
+	return err
 }
 
 func (db *ddb) DeleteWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.WorkflowExecutionTableName,
+		itemKey(shardKey(shardID), workflowExecutionSortKey(domainID, workflowID, runID)), nil, nil)
 }
 
 func (db *ddb) SelectAllCurrentWorkflows(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.CurrentWorkflowExecution, []byte, error) {
-	panic("TODO")
+	items, nextPageToken, err := db.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.CurrentWorkflowTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(shardKey(shardID)),
+		},
+		Limit: aws.Int64(int64(pageSize)),
+	}, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	executions := make([]*persistence.CurrentWorkflowExecution, 0, len(items))
+	for _, av := range items {
+		var item cadence.CurrentWorkflowItem
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, nil, err
+		}
+		row := &nosqlplugin.CurrentWorkflowRow{}
+		if err := decodeData(&item.Item, row); err != nil {
+			return nil, nil, err
+		}
+		executions = append(executions, &persistence.CurrentWorkflowExecution{
+			DomainID:     row.DomainID,
+			WorkflowID:   row.WorkflowID,
+			RunID:        item.CurrentRunID,
+			State:        item.WorkflowState,
+			CurrentRunID: item.CurrentRunID,
+		})
+	}
+	return executions, nextPageToken, nil
 }
 
 func (db *ddb) SelectAllWorkflowExecutions(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.InternalListConcreteExecutionsEntity, []byte, error) {
-	panic("TODO")
+	items, nextPageToken, err := db.query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.WorkflowExecutionTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(shardKey(shardID)),
+		},
+		// the maps and buffered events are not needed for listing
+		ProjectionExpression: aws.String("pk, sk, #data, data_encoding"),
+		ExpressionAttributeNames: map[string]*string{
+			"#data": aws.String(cadence.DataAttribute),
+		},
+		Limit: aws.Int64(int64(pageSize)),
+	}, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	executions := make([]*persistence.InternalListConcreteExecutionsEntity, 0, len(items))
+	for _, av := range items {
+		var item cadence.Item
+		if err := unmarshalItem(av, &item); err != nil {
+			return nil, nil, err
+		}
+		data := &workflowExecutionData{}
+		if err := decodeData(&item, data); err != nil {
+			return nil, nil, err
+		}
+		executions = append(executions, &persistence.InternalListConcreteExecutionsEntity{
+			ExecutionInfo:    data.ExecutionInfo,
+			VersionHistories: data.VersionHistories,
+		})
+	}
+	return executions, nextPageToken, nil
 }
 
 func (db *ddb) IsWorkflowExecutionExists(ctx context.Context, shardID int, domainID, workflowID, runID string) (bool, error) {
-	panic("TODO")
+	resp, err := db.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(db.tableName(cadence.WorkflowExecutionTableName)),
+		Key:                  itemKey(shardKey(shardID), workflowExecutionSortKey(domainID, workflowID, runID)),
+		ProjectionExpression: aws.String("pk"),
+		ConsistentRead:       aws.Bool(true),
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(resp.Item) > 0, nil
 }
 
 func (db *ddb) SelectTransferTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.TransferTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.TransferTask](ctx, db, cadence.TransferTaskTableName, shardKey(shardID), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *ddb) DeleteTransferTask(ctx context.Context, shardID int, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.TransferTaskTableName, itemKey(shardKey(shardID), encodeInt64(taskID)), nil, nil)
 }
 
 func (db *ddb) RangeDeleteTransferTasks(ctx context.Context, shardID int, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.TransferTaskTableName, taskIDRangeQuery(db.tableName(cadence.TransferTaskTableName), shardKey(shardID), exclusiveBeginTaskID, inclusiveEndTaskID))
+	return err
 }
 
 func (db *ddb) SelectTimerTasksOrderByVisibilityTime(ctx context.Context, shardID, pageSize int, pageToken []byte, inclusiveMinTime, exclusiveMaxTime time.Time) ([]*nosqlplugin.TimerTask, []byte, error) {
-	panic("TODO")
+	query := timerRangeQuery(db.tableName(cadence.TimerTaskTableName), shardID, inclusiveMinTime, exclusiveMaxTime)
+	query.Limit = aws.Int64(int64(pageSize))
+	items, nextPageToken, err := db.query(ctx, query, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := decodeRows[nosqlplugin.TimerTask](items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, nextPageToken, nil
 }
 
 func (db *ddb) DeleteTimerTask(ctx context.Context, shardID int, taskID int64, visibilityTimestamp time.Time) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.TimerTaskTableName, itemKey(shardKey(shardID), timerTaskSortKey(visibilityTimestamp.UnixNano(), taskID)), nil, nil)
 }
 
 func (db *ddb) RangeDeleteTimerTasks(ctx context.Context, shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.TimerTaskTableName, timerRangeQuery(db.tableName(cadence.TimerTaskTableName), shardID, inclusiveMinTime, exclusiveMaxTime))
+	return err
 }
 
 func (db *ddb) SelectReplicationTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.ReplicationTask](ctx, db, cadence.ReplicationTaskTableName, shardKey(shardID), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *ddb) DeleteReplicationTask(ctx context.Context, shardID int, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.ReplicationTaskTableName, itemKey(shardKey(shardID), encodeInt64(taskID)), nil, nil)
 }
 
 func (db *ddb) RangeDeleteReplicationTasks(ctx context.Context, shardID int, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.ReplicationTaskTableName, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.ReplicationTaskTableName)),
+		KeyConditionExpression: aws.String("pk = :pk AND sk <= :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(shardKey(shardID)),
+			":max": stringValue(encodeInt64(inclusiveEndTaskID)),
+		},
+	})
+	return err
 }
 
-func (db *ddb) InsertReplicationTask(ctx context.Context, tasks []*nosqlplugin.ReplicationTask, condition nosqlplugin.ShardCondition) error {
-	panic("TODO")
+func (db *ddb) InsertReplicationTask(ctx context.Context, tasks []*nosqlplugin.ReplicationTask, shardCondition nosqlplugin.ShardCondition) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	t := newWorkflowTransaction(db, &shardCondition)
+	if err := db.addTasks(t, shardCondition.ShardID, nil, nil, tasks, nil); err != nil {
+		return err
+	}
+	failure, err := t.execute(ctx, db)
+	if err != nil || failure == nil {
+		return err
+	}
+	if previous, failed := failure.failed(0); failed {
+		var shard cadence.ShardItem
+		if err := unmarshalItem(previous, &shard); err != nil {
+			return err
+		}
+		return &nosqlplugin.ShardOperationConditionFailure{
+			RangeID: shard.RangeID,
+		}
+	}
+	return &nosqlplugin.ShardOperationConditionFailure{
+		RangeID: -1,
+		Details: fmt.Sprintf("Failed to create replication tasks. Request RangeID: %v, failed items: (%v)", shardCondition.RangeID, failure.Error()),
+	}
 }
 
 func (db *ddb) SelectCrossClusterTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, targetCluster string, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.CrossClusterTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.CrossClusterTask](ctx, db, cadence.CrossClusterTaskTableName, crossClusterTaskKey(shardID, targetCluster), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *ddb) DeleteCrossClusterTask(ctx context.Context, shardID int, targetCluster string, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.CrossClusterTaskTableName, itemKey(crossClusterTaskKey(shardID, targetCluster), encodeInt64(taskID)), nil, nil)
 }
 
 func (db *ddb) RangeDeleteCrossClusterTasks(ctx context.Context, shardID int, targetCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.CrossClusterTaskTableName, taskIDRangeQuery(db.tableName(cadence.CrossClusterTaskTableName), crossClusterTaskKey(shardID, targetCluster), exclusiveBeginTaskID, inclusiveEndTaskID))
+	return err
 }
 
 func (db *ddb) InsertReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, task nosqlplugin.ReplicationTask) error {
-	panic("TODO")
+	item, err := newItem(replicationDLQTaskKey(shardID, sourceCluster), encodeInt64(task.TaskID), &task, 0)
+	if err != nil {
+		return err
+	}
+	return db.putItem(ctx, cadence.ReplicationDLQTaskTableName, &item, nil, nil)
 }
 
 func (db *ddb) SelectReplicationDLQTasksOrderByTaskID(ctx context.Context, shardID int, sourceCluster string, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.ReplicationTask](ctx, db, cadence.ReplicationDLQTaskTableName, replicationDLQTaskKey(shardID, sourceCluster), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *ddb) SelectReplicationDLQTasksCount(ctx context.Context, shardID int, sourceCluster string) (int64, error) {
-	panic("TODO")
+	return db.count(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName(cadence.ReplicationDLQTaskTableName)),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": stringValue(replicationDLQTaskKey(shardID, sourceCluster)),
+		},
+		ConsistentRead: aws.Bool(true),
+	})
 }
 
 func (db *ddb) DeleteReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, taskID int64) error {
-	panic("TODO")
+	return db.deleteItem(ctx, cadence.ReplicationDLQTaskTableName, itemKey(replicationDLQTaskKey(shardID, sourceCluster), encodeInt64(taskID)), nil, nil)
 }
 
 func (db *ddb) RangeDeleteReplicationDLQTasks(ctx context.Context, shardID int, sourceCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	_, err := db.deleteByQuery(ctx, cadence.ReplicationDLQTaskTableName, taskIDRangeQuery(db.tableName(cadence.ReplicationDLQTaskTableName), replicationDLQTaskKey(shardID, sourceCluster), exclusiveBeginTaskID, inclusiveEndTaskID))
+	return err
+}
+
+// selectTasksByTaskID reads a page of tasks in (exclusiveMinTaskID, inclusiveMaxTaskID]
+func selectTasksByTaskID[T any](
+	ctx context.Context,
+	db *ddb,
+	table, pk string,
+	pageSize int,
+	pageToken []byte,
+	exclusiveMinTaskID, inclusiveMaxTaskID int64,
+) ([]*T, []byte, error) {
+	query := taskIDRangeQuery(db.tableName(table), pk, exclusiveMinTaskID, inclusiveMaxTaskID)
+	query.Limit = aws.Int64(int64(pageSize))
+	items, nextPageToken, err := db.query(ctx, query, pageToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := decodeRows[T](items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, nextPageToken, nil
+}
+
+// taskIDRangeQuery returns the query of tasks in (exclusiveMinTaskID, inclusiveMaxTaskID]
+func taskIDRangeQuery(table, pk string, exclusiveMinTaskID, inclusiveMaxTaskID int64) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :min AND :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(pk),
+			":min": stringValue(encodeInt64(exclusiveMinTaskID + 1)),
+			":max": stringValue(encodeInt64(inclusiveMaxTaskID)),
+		},
+	}
+}
+
+// timerRangeQuery returns the query of timer tasks in [inclusiveMinTime, exclusiveMaxTime).
+// The sort keys of the tasks at exclusiveMaxTime start with the encoded time, and they are greater than
+// the upper bound of BETWEEN, so they are excluded.
+func timerRangeQuery(table string, shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :min AND :max"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":  stringValue(shardKey(shardID)),
+			":min": stringValue(encodeTime(inclusiveMinTime)),
+			":max": stringValue(encodeTime(exclusiveMaxTime)),
+		},
+	}
 }
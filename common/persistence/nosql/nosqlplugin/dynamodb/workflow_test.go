@@ -0,0 +1,315 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+// fakeClient records the transactions and fails them with the cancellation reasons
+type fakeClient struct {
+	dynamodbiface.DynamoDBAPI
+
+	transactions [][]*dynamodb.TransactWriteItem
+	reasons      []*dynamodb.CancellationReason
+}
+
+func (c *fakeClient) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.transactions = append(c.transactions, input.TransactItems)
+	if c.reasons != nil {
+		return nil, &dynamodb.TransactionCanceledException{
+			Message_:            aws.String("Transaction cancelled"),
+			CancellationReasons: c.reasons,
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func newTestDB(client *fakeClient) *ddb {
+	return newDynamoDBWithClient(&config.NoSQL{Keyspace: "test"}, client, nil)
+}
+
+func newTestExecution() *nosqlplugin.WorkflowExecutionRequest {
+	return &nosqlplugin.WorkflowExecutionRequest{
+		InternalWorkflowExecutionInfo: persistence.InternalWorkflowExecutionInfo{
+			DomainID:        "domain",
+			WorkflowID:      "workflow",
+			RunID:           "run",
+			CreateRequestID: "request",
+			NextEventID:     3,
+			State:           persistence.WorkflowStateCreated,
+		},
+		VersionHistories: persistence.NewDataBlob([]byte("histories"), common.EncodingTypeThriftRW),
+		LastWriteVersion: 10,
+		MapsWriteMode:    nosqlplugin.WorkflowExecutionMapsWriteModeCreate,
+		ActivityInfos: map[int64]*persistence.InternalActivityInfo{
+			5: {ScheduleID: 5, ActivityID: "activity"},
+		},
+		TimerInfos: map[string]*persistence.TimerInfo{
+			"timer": {TimerID: "timer", StartedID: 4},
+		},
+		SignalRequestedIDs: []string{"signal"},
+	}
+}
+
+func newTestCurrentWorkflowRequest() *nosqlplugin.CurrentWorkflowWriteRequest {
+	return &nosqlplugin.CurrentWorkflowWriteRequest{
+		WriteMode: nosqlplugin.CurrentWorkflowWriteModeInsert,
+		Row: nosqlplugin.CurrentWorkflowRow{
+			DomainID:         "domain",
+			WorkflowID:       "workflow",
+			RunID:            "run",
+			CreateRequestID:  "request",
+			LastWriteVersion: 10,
+		},
+	}
+}
+
+func conditionFailed(item map[string]*dynamodb.AttributeValue) *dynamodb.CancellationReason {
+	return &dynamodb.CancellationReason{Code: aws.String(conditionalCheckFailedReason), Item: item}
+}
+
+func noneFailed() *dynamodb.CancellationReason {
+	return &dynamodb.CancellationReason{Code: aws.String("None")}
+}
+
+func TestWorkflowExecutionItem_RoundTrip(t *testing.T) {
+	execution := newTestExecution()
+	item, err := newWorkflowExecutionItem(1, execution)
+	require.NoError(t, err)
+	av, err := marshalItem(item)
+	require.NoError(t, err)
+
+	var decoded cadence.WorkflowExecutionItem
+	require.NoError(t, unmarshalItem(av, &decoded))
+	state, err := toWorkflowExecution(&decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, execution.RunID, state.ExecutionInfo.RunID)
+	assert.Equal(t, execution.NextEventID, state.ExecutionInfo.NextEventID)
+	assert.Equal(t, execution.VersionHistories, state.VersionHistories)
+	assert.Equal(t, "activity", state.ActivityInfos[5].ActivityID)
+	assert.Equal(t, int64(4), state.TimerInfos["timer"].StartedID)
+	assert.Empty(t, state.ChildExecutionInfos)
+	assert.Equal(t, map[string]struct{}{"signal": {}}, state.SignalRequestedIDs)
+	assert.Empty(t, state.BufferedEvents)
+}
+
+func TestInsertWorkflowExecutionWithTasks(t *testing.T) {
+	shardCondition := &nosqlplugin.ShardCondition{ShardID: 1, RangeID: 5}
+	timerTasks := []*nosqlplugin.TimerTask{{TaskID: 100, VisibilityTimestamp: time.Unix(10, 0)}}
+
+	t.Run("success", func(t *testing.T) {
+		client := &fakeClient{}
+		err := newTestDB(client).InsertWorkflowExecutionWithTasks(
+			context.Background(), nil, newTestCurrentWorkflowRequest(), newTestExecution(),
+			nil, nil, nil, timerTasks, shardCondition)
+		require.NoError(t, err)
+		require.Len(t, client.transactions, 1)
+		items := client.transactions[0]
+		require.Len(t, items, 4)
+		assert.Equal(t, "test_shard", aws.StringValue(items[0].ConditionCheck.TableName))
+		assert.Equal(t, "test_current_workflow", aws.StringValue(items[1].Put.TableName))
+		assert.Equal(t, "test_workflow_execution", aws.StringValue(items[2].Put.TableName))
+		assert.Equal(t, "test_timer_task", aws.StringValue(items[3].Put.TableName))
+	})
+
+	t.Run("shard range ID changed", func(t *testing.T) {
+		client := &fakeClient{reasons: []*dynamodb.CancellationReason{
+			conditionFailed(map[string]*dynamodb.AttributeValue{cadence.RangeIDAttribute: numberValue(6)}),
+			noneFailed(), noneFailed(), noneFailed(),
+		}}
+		err := newTestDB(client).InsertWorkflowExecutionWithTasks(
+			context.Background(), nil, newTestCurrentWorkflowRequest(), newTestExecution(),
+			nil, nil, nil, timerTasks, shardCondition)
+		failure, ok := err.(*nosqlplugin.WorkflowOperationConditionFailure)
+		require.True(t, ok, "unexpected error %v", err)
+		assert.Equal(t, int64(6), *failure.ShardRangeIDNotMatch)
+	})
+
+	t.Run("current workflow exists", func(t *testing.T) {
+		current, err := newItem("1", currentWorkflowSortKey("domain", "workflow"), &nosqlplugin.CurrentWorkflowRow{
+			RunID:           "other-run",
+			CreateRequestID: "other-request",
+			State:           persistence.WorkflowStateRunning,
+		}, 0)
+		require.NoError(t, err)
+		currentItem, err := marshalItem(&cadence.CurrentWorkflowItem{
+			Item:             current,
+			CurrentRunID:     "other-run",
+			LastWriteVersion: 8,
+			WorkflowState:    persistence.WorkflowStateRunning,
+		})
+		require.NoError(t, err)
+
+		client := &fakeClient{reasons: []*dynamodb.CancellationReason{
+			noneFailed(), conditionFailed(currentItem), noneFailed(), noneFailed(),
+		}}
+		err = newTestDB(client).InsertWorkflowExecutionWithTasks(
+			context.Background(), nil, newTestCurrentWorkflowRequest(), newTestExecution(),
+			nil, nil, nil, timerTasks, shardCondition)
+		failure, ok := err.(*nosqlplugin.WorkflowOperationConditionFailure)
+		require.True(t, ok, "unexpected error %v", err)
+		require.NotNil(t, failure.WorkflowExecutionAlreadyExists)
+		assert.Equal(t, "other-run", failure.WorkflowExecutionAlreadyExists.RunID)
+		assert.Equal(t, "other-request", failure.WorkflowExecutionAlreadyExists.CreateRequestID)
+		assert.Equal(t, int64(8), failure.WorkflowExecutionAlreadyExists.LastWriteVersion)
+	})
+
+	t.Run("duplicate request", func(t *testing.T) {
+		requestItem, err := marshalItem(&cadence.WorkflowRequestItem{RunID: "other-run"})
+		require.NoError(t, err)
+		client := &fakeClient{reasons: []*dynamodb.CancellationReason{
+			noneFailed(), conditionFailed(requestItem), noneFailed(), noneFailed(), noneFailed(),
+		}}
+		requests := &nosqlplugin.WorkflowRequestsWriteRequest{
+			WriteMode: nosqlplugin.WorkflowRequestWriteModeInsert,
+			Rows: []*nosqlplugin.WorkflowRequestRow{{
+				ShardID:     1,
+				DomainID:    "domain",
+				WorkflowID:  "workflow",
+				RequestType: persistence.WorkflowRequestTypeStart,
+				RequestID:   "request",
+				RunID:       "run",
+			}},
+		}
+		err = newTestDB(client).InsertWorkflowExecutionWithTasks(
+			context.Background(), requests, newTestCurrentWorkflowRequest(), newTestExecution(),
+			nil, nil, nil, timerTasks, shardCondition)
+		failure, ok := err.(*nosqlplugin.WorkflowOperationConditionFailure)
+		require.True(t, ok, "unexpected error %v", err)
+		require.NotNil(t, failure.DuplicateRequest)
+		assert.Equal(t, "other-run", failure.DuplicateRequest.RunID)
+	})
+}
+
+func TestUpdateWorkflowExecutionWithTasks(t *testing.T) {
+	shardCondition := &nosqlplugin.ShardCondition{ShardID: 1, RangeID: 5}
+	newMutatedExecution := func() *nosqlplugin.WorkflowExecutionRequest {
+		execution := newTestExecution()
+		execution.MapsWriteMode = nosqlplugin.WorkflowExecutionMapsWriteModeUpdate
+		execution.PreviousNextEventIDCondition = common.Int64Ptr(2)
+		execution.ActivityInfoKeysToDelete = []int64{4}
+		return execution
+	}
+	newCurrentWorkflowRequest := func() *nosqlplugin.CurrentWorkflowWriteRequest {
+		request := newTestCurrentWorkflowRequest()
+		request.WriteMode = nosqlplugin.CurrentWorkflowWriteModeUpdate
+		request.Condition = &nosqlplugin.CurrentWorkflowWriteCondition{CurrentRunID: common.StringPtr("run")}
+		return request
+	}
+
+	t.Run("too many tasks", func(t *testing.T) {
+		var transferTasks []*nosqlplugin.TransferTask
+		for i := 0; i < 150; i++ {
+			transferTasks = append(transferTasks, &nosqlplugin.TransferTask{TaskID: int64(i)})
+		}
+		client := &fakeClient{}
+		err := newTestDB(client).UpdateWorkflowExecutionWithTasks(
+			context.Background(), nil, newCurrentWorkflowRequest(), newMutatedExecution(), nil, nil,
+			transferTasks, nil, nil, nil, shardCondition)
+		require.NoError(t, err)
+		require.Len(t, client.transactions, 2)
+		assert.Len(t, client.transactions[0], maxTransactionItems)
+		assert.NotNil(t, client.transactions[0][0].ConditionCheck)
+		update := client.transactions[0][2].Update
+		require.NotNil(t, update)
+		assert.Contains(t, aws.StringValue(update.UpdateExpression), "REMOVE")
+		// the tasks which don't fit into the transaction of the mutation are written after it
+		assert.Len(t, client.transactions[1], 53)
+		for _, item := range client.transactions[1] {
+			assert.NotNil(t, item.Put)
+		}
+	})
+
+	t.Run("too many tasks of a failed mutation", func(t *testing.T) {
+		var transferTasks []*nosqlplugin.TransferTask
+		for i := 0; i < 150; i++ {
+			transferTasks = append(transferTasks, &nosqlplugin.TransferTask{TaskID: int64(i)})
+		}
+		executionItem, err := marshalItem(&cadence.WorkflowExecutionItem{NextEventID: 7})
+		require.NoError(t, err)
+		client := &fakeClient{reasons: []*dynamodb.CancellationReason{
+			noneFailed(), noneFailed(), conditionFailed(executionItem),
+		}}
+		err = newTestDB(client).UpdateWorkflowExecutionWithTasks(
+			context.Background(), nil, newCurrentWorkflowRequest(), newMutatedExecution(), nil, nil,
+			transferTasks, nil, nil, nil, shardCondition)
+		assert.IsType(t, &nosqlplugin.WorkflowOperationConditionFailure{}, err)
+		assert.Len(t, client.transactions, 1, "no task is written when the mutation fails")
+	})
+
+	t.Run("next event ID changed", func(t *testing.T) {
+		executionItem, err := marshalItem(&cadence.WorkflowExecutionItem{NextEventID: 7})
+		require.NoError(t, err)
+		client := &fakeClient{reasons: []*dynamodb.CancellationReason{
+			noneFailed(), noneFailed(), conditionFailed(executionItem),
+		}}
+		err = newTestDB(client).UpdateWorkflowExecutionWithTasks(
+			context.Background(), nil, newCurrentWorkflowRequest(), newMutatedExecution(), nil, nil,
+			nil, nil, nil, nil, shardCondition)
+		failure, ok := err.(*nosqlplugin.WorkflowOperationConditionFailure)
+		require.True(t, ok, "unexpected error %v", err)
+		require.NotNil(t, failure.UnknownConditionFailureDetails)
+		assert.Contains(t, *failure.UnknownConditionFailureDetails, "actualNextEventID: 7")
+	})
+
+	t.Run("current run ID changed", func(t *testing.T) {
+		currentItem, err := marshalItem(&cadence.CurrentWorkflowItem{CurrentRunID: "other-run"})
+		require.NoError(t, err)
+		client := &fakeClient{reasons: []*dynamodb.CancellationReason{
+			noneFailed(), conditionFailed(currentItem), noneFailed(),
+		}}
+		err = newTestDB(client).UpdateWorkflowExecutionWithTasks(
+			context.Background(), nil, newCurrentWorkflowRequest(), newMutatedExecution(), nil, nil,
+			nil, nil, nil, nil, shardCondition)
+		failure, ok := err.(*nosqlplugin.WorkflowOperationConditionFailure)
+		require.True(t, ok, "unexpected error %v", err)
+		require.NotNil(t, failure.CurrentWorkflowConditionFailInfo)
+		assert.Contains(t, *failure.CurrentWorkflowConditionFailInfo, "Actual Current RunID: other-run")
+	})
+
+	t.Run("update current workflow without condition", func(t *testing.T) {
+		request := newCurrentWorkflowRequest()
+		request.Condition = nil
+		err := newTestDB(&fakeClient{}).UpdateWorkflowExecutionWithTasks(
+			context.Background(), nil, request, newMutatedExecution(), nil, nil,
+			nil, nil, nil, nil, shardCondition)
+		assert.Error(t, err)
+	})
+}
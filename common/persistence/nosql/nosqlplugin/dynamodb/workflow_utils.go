@@ -0,0 +1,845 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/checksum"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/dynamodb/cadence"
+)
+
+const (
+	// workflowRequestTTLInSeconds is the TTL of workflow requests, which is used for deduplication
+	workflowRequestTTLInSeconds = 10800
+)
+
+type (
+	// workflowExecutionData is the data attribute of the workflow execution item
+	workflowExecutionData struct {
+		ExecutionInfo    *persistence.InternalWorkflowExecutionInfo
+		VersionHistories *persistence.DataBlob
+		Checksum         *checksum.Checksum
+		LastWriteVersion int64
+	}
+
+	// writeItemKind is the kind of an item in a workflow transaction, it's used to figure out
+	// the reason when the transaction is canceled
+	writeItemKind int
+
+	// workflowTransaction is a list of writes which are executed in a transaction,
+	// and the metadata of the writes to convert the condition failures
+	workflowTransaction struct {
+		items []*dynamodb.TransactWriteItem
+		kinds []writeItemKind
+		// requests are the workflow requests of the items with kind writeItemKindWorkflowRequest
+		requests map[int]*nosqlplugin.WorkflowRequestRow
+		// tasks are the task items, they have no condition and can be moved to other transactions
+		// if the transaction has too many items
+		tasks []*dynamodb.TransactWriteItem
+	}
+)
+
+const (
+	writeItemKindShard writeItemKind = iota
+	writeItemKindWorkflowRequest
+	writeItemKindCurrentWorkflow
+	writeItemKindMutatedExecution
+	writeItemKindInsertedExecution
+	writeItemKindResetExecution
+)
+
+func newWorkflowTransaction(db *ddb, shardCondition *nosqlplugin.ShardCondition) *workflowTransaction {
+	t := &workflowTransaction{
+		requests: make(map[int]*nosqlplugin.WorkflowRequestRow),
+	}
+	t.add(writeItemKindShard, db.shardRangeCondition(shardCondition))
+	return t
+}
+
+func (t *workflowTransaction) add(kind writeItemKind, item *dynamodb.TransactWriteItem) {
+	t.items = append(t.items, item)
+	t.kinds = append(t.kinds, kind)
+}
+
+func (t *workflowTransaction) addTask(table string, item interface{}) error {
+	av, err := marshalItem(item)
+	if err != nil {
+		return err
+	}
+	t.tasks = append(t.tasks, &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName: aws.String(table),
+			Item:      av,
+		},
+	})
+	return nil
+}
+
+// execute writes all the items.
+// A transaction can contain at most 100 items. When there are too many tasks, the tasks that don't fit into the main
+// transaction are written after it succeeds, in separate transactions, so that no task is written for a mutation which
+// fails. They don't check the shard range ID as they belong to a mutation which is already written. If they fail to be
+// written, an error is returned so that the shard reloads the mutable state, and the tasks missing because of the error
+// or of a crash are regenerated from the mutable state by refreshing the tasks of the workflow.
+func (t *workflowTransaction) execute(ctx context.Context, db *ddb) (*transactionConditionFailure, error) {
+	tasks := t.tasks
+	capacity := maxTransactionItems - len(t.items)
+	if capacity > len(tasks) {
+		capacity = len(tasks)
+	}
+	err := db.transactWrite(ctx, append(t.items, tasks[:capacity]...))
+	if failure, ok := err.(*transactionConditionFailure); ok {
+		return failure, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for tasks = tasks[capacity:]; len(tasks) > 0; {
+		end := maxTransactionItems
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		if err := db.transactWrite(ctx, tasks[:end]); err != nil {
+			return nil, fmt.Errorf("workflow mutation is written but %v of its tasks are not: %v", len(tasks), err)
+		}
+		tasks = tasks[end:]
+	}
+	return nil, nil
+}
+
+// shardRangeCondition checks the range ID of the shard is not changed
+func (db *ddb) shardRangeCondition(shardCondition *nosqlplugin.ShardCondition) *dynamodb.TransactWriteItem {
+	return &dynamodb.TransactWriteItem{
+		ConditionCheck: &dynamodb.ConditionCheck{
+			TableName:           aws.String(db.tableName(cadence.ShardTableName)),
+			Key:                 itemKey(shardKey(shardCondition.ShardID), shardSortKey),
+			ConditionExpression: aws.String("range_id = :range_id"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":range_id": numberValue(shardCondition.RangeID),
+			},
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		},
+	}
+}
+
+func (db *ddb) addWorkflowRequests(
+	t *workflowTransaction,
+	requests *nosqlplugin.WorkflowRequestsWriteRequest,
+) error {
+	if requests == nil {
+		return nil
+	}
+	var condition *string
+	switch requests.WriteMode {
+	case nosqlplugin.WorkflowRequestWriteModeInsert:
+		condition = aws.String("attribute_not_exists(pk)")
+	case nosqlplugin.WorkflowRequestWriteModeUpsert:
+		// the latest write always wins
+	default:
+		return fmt.Errorf("unknown workflow request write mode %v", requests.WriteMode)
+	}
+	for _, row := range requests.Rows {
+		item, err := newItem(
+			workflowRequestKey(row.ShardID, row.DomainID, row.WorkflowID),
+			joinKey(strconv.Itoa(int(row.RequestType)), row.RequestID),
+			nil,
+			ttlFromNow(workflowRequestTTLInSeconds),
+		)
+		if err != nil {
+			return err
+		}
+		av, err := marshalItem(&cadence.WorkflowRequestItem{
+			Item:    item,
+			RunID:   row.RunID,
+			Version: row.Version,
+		})
+		if err != nil {
+			return err
+		}
+		t.requests[len(t.items)] = row
+		t.add(writeItemKindWorkflowRequest, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:                           aws.String(db.tableName(cadence.WorkflowRequestTableName)),
+				Item:                                av,
+				ConditionExpression:                 condition,
+				ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+			},
+		})
+	}
+	return nil
+}
+
+func (db *ddb) addCurrentWorkflow(
+	t *workflowTransaction,
+	shardID int,
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+) error {
+	var condition *string
+	values := map[string]*dynamodb.AttributeValue{}
+	switch request.WriteMode {
+	case nosqlplugin.CurrentWorkflowWriteModeNoop:
+		return nil
+	case nosqlplugin.CurrentWorkflowWriteModeInsert:
+		condition = aws.String("attribute_not_exists(pk)")
+	case nosqlplugin.CurrentWorkflowWriteModeUpdate:
+		if request.Condition == nil || request.Condition.GetCurrentRunID() == "" {
+			return fmt.Errorf("CurrentWorkflowWriteModeUpdate require Condition.CurrentRunID")
+		}
+		values[":current_run_id"] = stringValue(*request.Condition.CurrentRunID)
+		if request.Condition.LastWriteVersion != nil && request.Condition.State != nil {
+			condition = aws.String("current_run_id = :current_run_id AND last_write_version = :last_write_version AND workflow_state = :workflow_state")
+			values[":last_write_version"] = numberValue(*request.Condition.LastWriteVersion)
+			values[":workflow_state"] = numberValue(int64(*request.Condition.State))
+		} else {
+			condition = aws.String("current_run_id = :current_run_id")
+		}
+	default:
+		return fmt.Errorf("unknown mode %v", request.WriteMode)
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+
+	row := request.Row
+	row.ShardID = shardID
+	item, err := newItem(shardKey(shardID), currentWorkflowSortKey(row.DomainID, row.WorkflowID), &row, 0)
+	if err != nil {
+		return err
+	}
+	av, err := marshalItem(&cadence.CurrentWorkflowItem{
+		Item:             item,
+		CurrentRunID:     row.RunID,
+		LastWriteVersion: row.LastWriteVersion,
+		WorkflowState:    row.State,
+	})
+	if err != nil {
+		return err
+	}
+	t.add(writeItemKindCurrentWorkflow, &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName:                           aws.String(db.tableName(cadence.CurrentWorkflowTableName)),
+			Item:                                av,
+			ConditionExpression:                 condition,
+			ExpressionAttributeValues:           values,
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		},
+	})
+	return nil
+}
+
+// addCreatedExecution writes a new workflow execution, used for insertedExecution and resetExecution
+func (db *ddb) addCreatedExecution(
+	t *workflowTransaction,
+	shardID int,
+	kind writeItemKind,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	item, err := newWorkflowExecutionItem(shardID, execution)
+	if err != nil {
+		return err
+	}
+	av, err := marshalItem(item)
+	if err != nil {
+		return err
+	}
+	put := &dynamodb.Put{
+		TableName:                           aws.String(db.tableName(cadence.WorkflowExecutionTableName)),
+		Item:                                av,
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+	switch kind {
+	case writeItemKindInsertedExecution:
+		if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeCreate {
+			return fmt.Errorf("MapsWriteMode %v is not supported for inserted execution", execution.MapsWriteMode)
+		}
+		put.ConditionExpression = aws.String("attribute_not_exists(pk)")
+	case writeItemKindResetExecution:
+		if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeReset {
+			return fmt.Errorf("MapsWriteMode %v is not supported for reset execution", execution.MapsWriteMode)
+		}
+		if execution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeClear {
+			return fmt.Errorf("EventBufferWriteMode %v is not supported for reset execution", execution.EventBufferWriteMode)
+		}
+		if execution.PreviousNextEventIDCondition == nil {
+			return fmt.Errorf("PreviousNextEventIDCondition is required for reset execution")
+		}
+		put.ConditionExpression = aws.String("next_event_id = :previous_next_event_id")
+		put.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":previous_next_event_id": numberValue(*execution.PreviousNextEventIDCondition),
+		}
+	default:
+		return fmt.Errorf("unknown kind %v of created execution", kind)
+	}
+	t.add(kind, &dynamodb.TransactWriteItem{Put: put})
+	return nil
+}
+
+// addMutatedExecution updates the execution info and the changed entries of the maps of an existing workflow execution
+func (db *ddb) addMutatedExecution(
+	t *workflowTransaction,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeUpdate {
+		return fmt.Errorf("MapsWriteMode %v is not supported for mutated execution", execution.MapsWriteMode)
+	}
+	if execution.PreviousNextEventIDCondition == nil {
+		return fmt.Errorf("PreviousNextEventIDCondition is required for mutated execution")
+	}
+
+	data, err := newWorkflowExecutionData(execution)
+	if err != nil {
+		return err
+	}
+	b := newUpdateBuilder()
+	b.set(cadence.DataAttribute, &dynamodb.AttributeValue{B: data})
+	b.set(cadence.DataEncodingAttribute, stringValue(dataEncodingJSON))
+	b.set(cadence.NextEventIDAttribute, numberValue(execution.NextEventID))
+
+	if err := setMapEntries(b, cadence.ActivityInfosAttribute, execution.ActivityInfos); err != nil {
+		return err
+	}
+	if err := setMapEntries(b, cadence.TimerInfosAttribute, execution.TimerInfos); err != nil {
+		return err
+	}
+	if err := setMapEntries(b, cadence.ChildExecutionInfosAttribute, execution.ChildWorkflowInfos); err != nil {
+		return err
+	}
+	if err := setMapEntries(b, cadence.RequestCancelInfosAttribute, execution.RequestCancelInfos); err != nil {
+		return err
+	}
+	if err := setMapEntries(b, cadence.SignalInfosAttribute, execution.SignalInfos); err != nil {
+		return err
+	}
+	for _, id := range execution.SignalRequestedIDs {
+		b.setMapEntry(cadence.SignalRequestedIDsAttribute, id, &dynamodb.AttributeValue{BOOL: aws.Bool(true)})
+	}
+
+	removeMapEntries(b, cadence.ActivityInfosAttribute, execution.ActivityInfoKeysToDelete)
+	removeMapEntries(b, cadence.TimerInfosAttribute, execution.TimerInfoKeysToDelete)
+	removeMapEntries(b, cadence.ChildExecutionInfosAttribute, execution.ChildWorkflowInfoKeysToDelete)
+	removeMapEntries(b, cadence.RequestCancelInfosAttribute, execution.RequestCancelInfoKeysToDelete)
+	removeMapEntries(b, cadence.SignalInfosAttribute, execution.SignalInfoKeysToDelete)
+	removeMapEntries(b, cadence.SignalRequestedIDsAttribute, execution.SignalRequestedIDsKeysToDelete)
+
+	switch execution.EventBufferWriteMode {
+	case nosqlplugin.EventBufferWriteModeNone:
+	case nosqlplugin.EventBufferWriteModeAppend:
+		if execution.NewBufferedEventBatch == nil {
+			return fmt.Errorf("NewBufferedEventBatch is required for EventBufferWriteModeAppend")
+		}
+		newEvents, err := marshalItem(&struct {
+			Events []cadence.EventBlob `dynamodbav:"events"`
+		}{
+			Events: []cadence.EventBlob{toEventBlob(execution.NewBufferedEventBatch)},
+		})
+		if err != nil {
+			return err
+		}
+		b.appendList(cadence.BufferedEventsAttribute, newEvents["events"])
+	case nosqlplugin.EventBufferWriteModeClear:
+		b.set(cadence.BufferedEventsAttribute, &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}})
+	default:
+		return fmt.Errorf("unknown EventBufferWriteMode %v", execution.EventBufferWriteMode)
+	}
+
+	t.add(writeItemKindMutatedExecution, &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			TableName:                           aws.String(db.tableName(cadence.WorkflowExecutionTableName)),
+			Key:                                 itemKey(shardKey(shardID), workflowExecutionSortKey(execution.DomainID, execution.WorkflowID, execution.RunID)),
+			UpdateExpression:                    b.expression(),
+			ConditionExpression:                 aws.String("next_event_id = " + b.value(numberValue(*execution.PreviousNextEventIDCondition))),
+			ExpressionAttributeNames:            b.names,
+			ExpressionAttributeValues:           b.values,
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		},
+	})
+	return nil
+}
+
+func (db *ddb) addTasks(
+	t *workflowTransaction,
+	shardID int,
+	transferTasks []*nosqlplugin.TransferTask,
+	crossClusterTasks []*nosqlplugin.CrossClusterTask,
+	replicationTasks []*nosqlplugin.ReplicationTask,
+	timerTasks []*nosqlplugin.TimerTask,
+) error {
+	for _, task := range transferTasks {
+		item, err := newItem(shardKey(shardID), encodeInt64(task.TaskID), task, 0)
+		if err != nil {
+			return err
+		}
+		if err := t.addTask(db.tableName(cadence.TransferTaskTableName), item); err != nil {
+			return err
+		}
+	}
+	for _, task := range crossClusterTasks {
+		item, err := newItem(crossClusterTaskKey(shardID, task.TargetCluster), encodeInt64(task.TaskID), task, 0)
+		if err != nil {
+			return err
+		}
+		if err := t.addTask(db.tableName(cadence.CrossClusterTaskTableName), item); err != nil {
+			return err
+		}
+	}
+	for _, task := range replicationTasks {
+		item, err := newItem(shardKey(shardID), encodeInt64(task.TaskID), task, 0)
+		if err != nil {
+			return err
+		}
+		if err := t.addTask(db.tableName(cadence.ReplicationTaskTableName), item); err != nil {
+			return err
+		}
+	}
+	for _, task := range timerTasks {
+		item, err := newItem(shardKey(shardID), timerTaskSortKey(task.VisibilityTimestamp.UnixNano(), task.TaskID), task, 0)
+		if err != nil {
+			return err
+		}
+		if err := t.addTask(db.tableName(cadence.TimerTaskTableName), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newWorkflowExecutionData(execution *nosqlplugin.WorkflowExecutionRequest) ([]byte, error) {
+	info := execution.InternalWorkflowExecutionInfo
+	item, err := newItem("", "", &workflowExecutionData{
+		ExecutionInfo:    &info,
+		VersionHistories: execution.VersionHistories,
+		Checksum:         execution.Checksums,
+		LastWriteVersion: execution.LastWriteVersion,
+	}, 0)
+	return item.Data, err
+}
+
+func newWorkflowExecutionItem(shardID int, execution *nosqlplugin.WorkflowExecutionRequest) (*cadence.WorkflowExecutionItem, error) {
+	data, err := newWorkflowExecutionData(execution)
+	if err != nil {
+		return nil, err
+	}
+	item := &cadence.WorkflowExecutionItem{
+		Item: cadence.Item{
+			PartitionKey: shardKey(shardID),
+			SortKey:      workflowExecutionSortKey(execution.DomainID, execution.WorkflowID, execution.RunID),
+			Data:         data,
+			DataEncoding: dataEncodingJSON,
+		},
+		NextEventID:        execution.NextEventID,
+		SignalRequestedIDs: make(map[string]bool, len(execution.SignalRequestedIDs)),
+		BufferedEvents:     []cadence.EventBlob{},
+	}
+	if item.ActivityInfos, err = encodeMap(execution.ActivityInfos); err != nil {
+		return nil, err
+	}
+	if item.TimerInfos, err = encodeMap(execution.TimerInfos); err != nil {
+		return nil, err
+	}
+	if item.ChildExecutionInfos, err = encodeMap(execution.ChildWorkflowInfos); err != nil {
+		return nil, err
+	}
+	if item.RequestCancelInfos, err = encodeMap(execution.RequestCancelInfos); err != nil {
+		return nil, err
+	}
+	if item.SignalInfos, err = encodeMap(execution.SignalInfos); err != nil {
+		return nil, err
+	}
+	for _, id := range execution.SignalRequestedIDs {
+		item.SignalRequestedIDs[id] = true
+	}
+	return item, nil
+}
+
+func toWorkflowExecution(item *cadence.WorkflowExecutionItem) (*nosqlplugin.WorkflowExecution, error) {
+	data := &workflowExecutionData{}
+	if err := decodeData(&item.Item, data); err != nil {
+		return nil, err
+	}
+	state := &nosqlplugin.WorkflowExecution{
+		ExecutionInfo:    data.ExecutionInfo,
+		VersionHistories: data.VersionHistories,
+	}
+	if data.Checksum != nil {
+		state.Checksum = *data.Checksum
+	}
+
+	var err error
+	if state.ActivityInfos, err = decodeInt64Map[persistence.InternalActivityInfo](item.ActivityInfos); err != nil {
+		return nil, err
+	}
+	if state.TimerInfos, err = decodeStringMap[persistence.TimerInfo](item.TimerInfos); err != nil {
+		return nil, err
+	}
+	if state.ChildExecutionInfos, err = decodeInt64Map[persistence.InternalChildExecutionInfo](item.ChildExecutionInfos); err != nil {
+		return nil, err
+	}
+	if state.RequestCancelInfos, err = decodeInt64Map[persistence.RequestCancelInfo](item.RequestCancelInfos); err != nil {
+		return nil, err
+	}
+	if state.SignalInfos, err = decodeInt64Map[persistence.SignalInfo](item.SignalInfos); err != nil {
+		return nil, err
+	}
+	state.SignalRequestedIDs = make(map[string]struct{}, len(item.SignalRequestedIDs))
+	for id := range item.SignalRequestedIDs {
+		state.SignalRequestedIDs[id] = struct{}{}
+	}
+	state.BufferedEvents = make([]*persistence.DataBlob, 0, len(item.BufferedEvents))
+	for _, blob := range item.BufferedEvents {
+		state.BufferedEvents = append(state.BufferedEvents, persistence.NewDataBlob(blob.Data, common.EncodingType(blob.Encoding)))
+	}
+	return state, nil
+}
+
+func toEventBlob(blob *persistence.DataBlob) cadence.EventBlob {
+	return cadence.EventBlob{
+		Data:     blob.Data,
+		Encoding: string(blob.GetEncoding()),
+	}
+}
+
+// encodeMap encodes the values of a map into JSON, the keys are converted to strings
+func encodeMap[K comparable, V any](m map[K]V) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(m))
+	for k, v := range m {
+		item, err := newItem("", "", v, 0)
+		if err != nil {
+			return nil, err
+		}
+		result[fmt.Sprint(k)] = item.Data
+	}
+	return result, nil
+}
+
+func decodeInt64Map[V any](m map[string][]byte) (map[int64]*V, error) {
+	result := make(map[int64]*V, len(m))
+	for k, data := range m {
+		key, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v := new(V)
+		if err := decodeData(&cadence.Item{Data: data}, v); err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+func decodeStringMap[V any](m map[string][]byte) (map[string]*V, error) {
+	result := make(map[string]*V, len(m))
+	for k, data := range m {
+		v := new(V)
+		if err := decodeData(&cadence.Item{Data: data}, v); err != nil {
+			return nil, err
+		}
+		result[k] = v
+	}
+	return result, nil
+}
+
+func setMapEntries[K comparable, V any](b *updateBuilder, attribute string, m map[K]V) error {
+	encoded, err := encodeMap(m)
+	if err != nil {
+		return err
+	}
+	for k, data := range encoded {
+		b.setMapEntry(attribute, k, &dynamodb.AttributeValue{B: data})
+	}
+	return nil
+}
+
+func removeMapEntries[K comparable](b *updateBuilder, attribute string, keys []K) {
+	for _, k := range keys {
+		b.removeMapEntry(attribute, fmt.Sprint(k))
+	}
+}
+
+// currentWorkflowSortKey is the sort key of current_workflow table.
+// Domain ID has fixed length so the key is unique for any workflow ID.
+func currentWorkflowSortKey(domainID, workflowID string) string {
+	return joinKey(domainID, workflowID)
+}
+
+// workflowExecutionSortKey is the sort key of workflow_execution table.
+// Domain ID and run ID have fixed length so the key is unique for any workflow ID.
+func workflowExecutionSortKey(domainID, workflowID, runID string) string {
+	return joinKey(domainID, workflowID, runID)
+}
+
+func workflowRequestKey(shardID int, domainID, workflowID string) string {
+	return joinKey(shardKey(shardID), domainID, workflowID)
+}
+
+func crossClusterTaskKey(shardID int, targetCluster string) string {
+	return joinKey(shardKey(shardID), targetCluster)
+}
+
+func replicationDLQTaskKey(shardID int, sourceCluster string) string {
+	return joinKey(shardKey(shardID), sourceCluster)
+}
+
+// timerTaskSortKey is the sort key of timer_task table, timer tasks are sorted by visibility timestamp and then task ID
+func timerTaskSortKey(visibilityTimestamp int64, taskID int64) string {
+	return joinKey(encodeInt64(visibilityTimestamp), encodeInt64(taskID))
+}
+
+// convertCreateWorkflowConditionFailure converts the condition failure of InsertWorkflowExecutionWithTasks
+func (db *ddb) convertCreateWorkflowConditionFailure(
+	ctx context.Context,
+	t *workflowTransaction,
+	failure *transactionConditionFailure,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if err := db.checkShardAndRequestConditionFailure(ctx, t, failure, shardCondition); err != nil {
+		return err
+	}
+
+	if index, ok := t.indexOf(writeItemKindCurrentWorkflow); ok {
+		if previous, failed := failure.failed(index); failed {
+			current, err := toCurrentWorkflowItem(previous)
+			if err != nil {
+				return err
+			}
+			if currentWorkflowRequest.WriteMode == nosqlplugin.CurrentWorkflowWriteModeInsert {
+				// CreateWorkflowExecution failed because there is already a current execution record for this workflow
+				if current == nil {
+					msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v", currentWorkflowRequest.Row.WorkflowID)
+					return &nosqlplugin.WorkflowOperationConditionFailure{
+						CurrentWorkflowConditionFailInfo: &msg,
+					}
+				}
+				row := &nosqlplugin.CurrentWorkflowRow{}
+				if err := decodeData(&current.Item, row); err != nil {
+					return err
+				}
+				msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v", currentWorkflowRequest.Row.WorkflowID, current.CurrentRunID)
+				return &nosqlplugin.WorkflowOperationConditionFailure{
+					WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+						OtherInfo:        msg,
+						CreateRequestID:  row.CreateRequestID,
+						RunID:            current.CurrentRunID,
+						State:            row.State,
+						CloseStatus:      row.CloseStatus,
+						LastWriteVersion: current.LastWriteVersion,
+					},
+				}
+			}
+			return newCurrentWorkflowConditionFailure(currentWorkflowRequest, current, "Workflow execution creation condition failed")
+		}
+	}
+
+	if index, ok := t.indexOf(writeItemKindInsertedExecution); ok {
+		if previous, failed := failure.failed(index); failed {
+			actualLastWriteVersion := int64(common.EmptyVersion)
+			if len(previous) > 0 {
+				var item cadence.WorkflowExecutionItem
+				if err := unmarshalItem(previous, &item); err != nil {
+					return err
+				}
+				data := &workflowExecutionData{}
+				if err := decodeData(&item.Item, data); err != nil {
+					return err
+				}
+				actualLastWriteVersion = data.LastWriteVersion
+			}
+			msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v", execution.WorkflowID, execution.RunID)
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+					OtherInfo:        msg,
+					CreateRequestID:  execution.CreateRequestID,
+					RunID:            execution.RunID,
+					State:            execution.State,
+					CloseStatus:      execution.CloseStatus,
+					LastWriteVersion: actualLastWriteVersion,
+				},
+			}
+		}
+	}
+
+	msg := fmt.Sprintf("Failed to operate on workflow execution.  Request RangeID: %v, failed items: (%v)",
+		shardCondition.RangeID, failure.Error())
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		UnknownConditionFailureDetails: &msg,
+	}
+}
+
+// convertUpdateWorkflowConditionFailure converts the condition failure of UpdateWorkflowExecutionWithTasks
+func (db *ddb) convertUpdateWorkflowConditionFailure(
+	ctx context.Context,
+	t *workflowTransaction,
+	failure *transactionConditionFailure,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	previousNextEventIDCondition int64,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if err := db.checkShardAndRequestConditionFailure(ctx, t, failure, shardCondition); err != nil {
+		return err
+	}
+
+	if index, ok := t.indexOf(writeItemKindCurrentWorkflow); ok {
+		if previous, failed := failure.failed(index); failed {
+			current, err := toCurrentWorkflowItem(previous)
+			if err != nil {
+				return err
+			}
+			return newCurrentWorkflowConditionFailure(currentWorkflowRequest, current, "Failed to update mutable state")
+		}
+	}
+
+	for _, kind := range []writeItemKind{writeItemKindMutatedExecution, writeItemKindResetExecution} {
+		index, ok := t.indexOf(kind)
+		if !ok {
+			continue
+		}
+		if previous, failed := failure.failed(index); failed {
+			actualNextEventID := int64(0)
+			if len(previous) > 0 {
+				var item cadence.WorkflowExecutionItem
+				if err := unmarshalItem(previous, &item); err != nil {
+					return err
+				}
+				actualNextEventID = item.NextEventID
+			}
+			msg := fmt.Sprintf("Failed to update mutable state. previousNextEventIDCondition: %v, actualNextEventID: %v, Request Current RunID: %v",
+				previousNextEventIDCondition, actualNextEventID, currentWorkflowRequest.Row.RunID)
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				UnknownConditionFailureDetails: &msg,
+			}
+		}
+	}
+
+	msg := fmt.Sprintf("Failed to update mutable state. ShardID: %v, RangeID: %v, previousNextEventIDCondition: %v, requestConditionalRunID: %v, failed items: (%v)",
+		shardCondition.ShardID, shardCondition.RangeID, previousNextEventIDCondition, currentWorkflowRequest.Condition.GetCurrentRunID(), failure.Error())
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		UnknownConditionFailureDetails: &msg,
+	}
+}
+
+// checkShardAndRequestConditionFailure returns the condition failure if the shard range ID doesn't match
+// or there is a duplicate request, which have the highest priority
+func (db *ddb) checkShardAndRequestConditionFailure(
+	ctx context.Context,
+	t *workflowTransaction,
+	failure *transactionConditionFailure,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if previous, failed := failure.failed(0); failed {
+		var shard cadence.ShardItem
+		if err := unmarshalItem(previous, &shard); err != nil {
+			return err
+		}
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			ShardRangeIDNotMatch: common.Int64Ptr(shard.RangeID),
+		}
+	}
+
+	for index, row := range t.requests {
+		previous, failed := failure.failed(index)
+		if !failed {
+			continue
+		}
+		var request cadence.WorkflowRequestItem
+		if err := unmarshalItem(previous, &request); err != nil {
+			return err
+		}
+		if request.RunID == "" {
+			return fmt.Errorf("corrupted data detected. DomainID: %v, WorkflowId: %v, RequestID: %v, RequestType: %v", row.DomainID, row.WorkflowID, row.RequestID, row.RequestType)
+		}
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			DuplicateRequest: &nosqlplugin.DuplicateRequest{
+				RequestType: row.RequestType,
+				RunID:       request.RunID,
+			},
+		}
+	}
+	return nil
+}
+
+func (t *workflowTransaction) indexOf(kind writeItemKind) (int, bool) {
+	for i, k := range t.kinds {
+		if k == kind {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// toCurrentWorkflowItem returns nil if the current workflow item doesn't exist
+func toCurrentWorkflowItem(av map[string]*dynamodb.AttributeValue) (*cadence.CurrentWorkflowItem, error) {
+	if len(av) == 0 {
+		return nil, nil
+	}
+	var item cadence.CurrentWorkflowItem
+	if err := unmarshalItem(av, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func newCurrentWorkflowConditionFailure(
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+	current *cadence.CurrentWorkflowItem,
+	operation string,
+) error {
+	actualCurrRunID := ""
+	actualLastWriteVersion := int64(common.EmptyVersion)
+	actualState := 0
+	if current != nil {
+		actualCurrRunID = current.CurrentRunID
+		actualLastWriteVersion = current.LastWriteVersion
+		actualState = current.WorkflowState
+	}
+
+	var msg string
+	switch {
+	case request.Condition.GetCurrentRunID() != actualCurrRunID:
+		msg = fmt.Sprintf("%v by mismatch runID. WorkflowId: %v, Expected Current RunID: %v, Actual Current RunID: %v",
+			operation, request.Row.WorkflowID, request.Condition.GetCurrentRunID(), actualCurrRunID)
+	case request.Condition != nil && request.Condition.LastWriteVersion != nil && *request.Condition.LastWriteVersion != actualLastWriteVersion:
+		msg = fmt.Sprintf("%v. WorkflowId: %v, Expected Version: %v, Actual Version: %v",
+			operation, request.Row.WorkflowID, *request.Condition.LastWriteVersion, actualLastWriteVersion)
+	case request.Condition != nil && request.Condition.State != nil && *request.Condition.State != actualState:
+		msg = fmt.Sprintf("%v. WorkflowId: %v, Expected State: %v, Actual State: %v",
+			operation, request.Row.WorkflowID, *request.Condition.State, actualState)
+	default:
+		msg = fmt.Sprintf("%v. WorkflowId: %v, Current RunID: %v", operation, request.Row.WorkflowID, actualCurrRunID)
+	}
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		CurrentWorkflowConditionFailInfo: &msg,
+	}
+}
@@ -57,7 +57,7 @@ func (db *mdb) SetupTestDatabase(schemaBaseDir string, replicas int) error {
 	}
 	for _, cmd := range commands {
 		result := db.dbConn.RunCommand(context.Background(), cmd)
-		if result.Err() != nil {
+		if err := result.Err(); err != nil {
 			return err
 		}
 	}
@@ -22,8 +22,12 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/log"
@@ -47,3 +51,41 @@ func (db *mdb) Close() {
 func (db *mdb) PluginName() string {
 	return PluginName
 }
+
+func (db *mdb) collection(name string) *mongo.Collection {
+	return db.dbConn.Collection(name)
+}
+
+// withTransaction runs fn in a multi-document transaction, which requires MongoDB to be deployed as a replica set.
+// The transaction is retried by the driver on transient errors, so fn must be idempotent.
+func (db *mdb) withTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) error) error {
+	session, err := db.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	transactionOptions := options.Transaction().
+		SetReadConcern(readconcern.Snapshot()).
+		SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	}, transactionOptions)
+	return err
+}
+
+// conditionFailedError is returned by the function of a transaction when a write condition doesn't meet,
+// which aborts the transaction. Operation is the name of the write that failed.
+type conditionFailedError struct {
+	operation string
+}
+
+func (e *conditionFailedError) Error() string {
+	return fmt.Sprintf("condition of %v failed", e.operation)
+}
+
+// isConditionFailed returns whether the error is a conditionFailedError of the operation
+func isConditionFailed(err error, operation string) bool {
+	failure, ok := err.(*conditionFailedError)
+	return ok && failure.operation == operation
+}
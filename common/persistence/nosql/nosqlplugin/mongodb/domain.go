@@ -22,8 +22,26 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/schema/mongodb/cadence"
+)
+
+const (
+	// domainMetadataID is the _id of the only document in domain_metadata collection
+	domainMetadataID    = "metadata"
+	domainComponentName = "domain"
+
+	domainOperation         = "domain"
+	domainMetadataOperation = "domain_metadata"
 )
 
 // Insert a new record to domain, return error if failed or already exists
@@ -32,7 +50,49 @@ func (db *mdb) InsertDomain(
 	ctx context.Context,
 	row *nosqlplugin.DomainRow,
 ) error {
-	panic("TODO")
+	metadataNotificationVersion, err := db.SelectDomainMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	newRow := *row
+	newRow.FailoverNotificationVersion = persistence.InitialFailoverNotificationVersion
+	newRow.PreviousFailoverVersion = common.InitialPreviousFailoverVersion
+	newRow.NotificationVersion = metadataNotificationVersion
+	doc, err := newDomainDocument(&newRow)
+	if err != nil {
+		return err
+	}
+
+	err = db.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		_, err := db.collection(cadence.DomainCollectionName).InsertOne(sessCtx, doc)
+		if mongo.IsDuplicateKeyError(err) {
+			return &conditionFailedError{operation: domainOperation}
+		}
+		if err != nil {
+			return err
+		}
+		return db.updateDomainMetadata(sessCtx, metadataNotificationVersion)
+	})
+	if isConditionFailed(err, domainOperation) {
+		// either the name or the ID is taken
+		count, err := db.collection(cadence.DomainCollectionName).CountDocuments(ctx, bson.D{{"name", row.Info.Name}})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			db.logger.Warn("Domain already exists", tag.WorkflowDomainName(row.Info.Name))
+			return &types.DomainAlreadyExistsError{
+				Message: fmt.Sprintf("Domain %v already exists", row.Info.Name),
+			}
+		}
+		return fmt.Errorf("CreateDomain operation failed because of uuid collision")
+	}
+	if isConditionFailed(err, domainMetadataOperation) {
+		db.logger.Warn("Create domain operation failed because of condition update failure on domain metadata record")
+		return nosqlplugin.NewConditionFailure(domainComponentName)
+	}
+	return err
 }
 
 // Update domain
@@ -40,7 +100,49 @@ func (db *mdb) UpdateDomain(
 	ctx context.Context,
 	row *nosqlplugin.DomainRow,
 ) error {
-	panic("TODO")
+	doc, err := newDomainDocument(row)
+	if err != nil {
+		return err
+	}
+
+	err = db.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		if _, err := db.collection(cadence.DomainCollectionName).ReplaceOne(sessCtx, bson.D{{"_id", doc.ID}}, doc); err != nil {
+			return err
+		}
+		return db.updateDomainMetadata(sessCtx, row.NotificationVersion)
+	})
+	if isConditionFailed(err, domainMetadataOperation) {
+		return nosqlplugin.NewConditionFailure(domainComponentName)
+	}
+	return err
+}
+
+// updateDomainMetadata increases the notification version by one, if the current version matches
+func (db *mdb) updateDomainMetadata(ctx context.Context, notificationVersion int64) error {
+	collection := db.collection(cadence.DomainMetadataCollectionName)
+	if notificationVersion == 0 {
+		// the metadata document doesn't exist before the first domain is created
+		_, err := collection.InsertOne(ctx, cadence.DomainMetadataCollectionEntry{
+			ID:                  domainMetadataID,
+			NotificationVersion: 1,
+		})
+		if mongo.IsDuplicateKeyError(err) {
+			return &conditionFailedError{operation: domainMetadataOperation}
+		}
+		return err
+	}
+
+	result, err := collection.UpdateOne(ctx,
+		bson.D{{"_id", domainMetadataID}, {"notificationversion", notificationVersion}},
+		bson.D{{"$set", bson.D{{"notificationversion", notificationVersion + 1}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return &conditionFailedError{operation: domainMetadataOperation}
+	}
+	return nil
 }
 
 // Get one domain data, either by domainID or domainName
@@ -49,7 +151,26 @@ func (db *mdb) SelectDomain(
 	domainID *string,
 	domainName *string,
 ) (*nosqlplugin.DomainRow, error) {
-	panic("TODO")
+	var filter bson.D
+	if domainID != nil && domainName != nil {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name specified in request")
+	} else if domainID != nil {
+		filter = bson.D{{"_id", *domainID}}
+	} else if domainName != nil {
+		filter = bson.D{{"name", *domainName}}
+	} else {
+		return nil, fmt.Errorf("GetDomain operation failed.  Both ID and Name are empty")
+	}
+
+	var doc cadence.DomainCollectionEntry
+	if err := db.collection(cadence.DomainCollectionName).FindOne(ctx, filter).Decode(&doc); err != nil {
+		return nil, err
+	}
+	row := &nosqlplugin.DomainRow{}
+	if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+		return nil, err
+	}
+	return row, nil
 }
 
 // Get all domain data
@@ -58,7 +179,26 @@ func (db *mdb) SelectAllDomains(
 	pageSize int,
 	pageToken []byte,
 ) ([]*nosqlplugin.DomainRow, []byte, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.DomainCollectionEntry](ctx,
+		db.collection(cadence.DomainCollectionName),
+		bson.D{},
+		[]sortKey{{field: "_id"}},
+		pageSize,
+		pageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]*nosqlplugin.DomainRow, 0, len(docs))
+	for _, doc := range docs {
+		row := &nosqlplugin.DomainRow{}
+		if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nextPageToken, nil
 }
 
 // Delete a domain, either by domainID or domainName
@@ -67,11 +207,42 @@ func (db *mdb) DeleteDomain(
 	domainID *string,
 	domainName *string,
 ) error {
-	panic("TODO")
+	var filter bson.D
+	if domainID != nil {
+		filter = bson.D{{"_id", *domainID}}
+	} else if domainName != nil {
+		filter = bson.D{{"name", *domainName}}
+	} else {
+		return fmt.Errorf("must provide either domainID or domainName")
+	}
+	_, err := db.collection(cadence.DomainCollectionName).DeleteOne(ctx, filter)
+	return err
 }
 
 func (db *mdb) SelectDomainMetadata(
 	ctx context.Context,
 ) (int64, error) {
-	panic("TODO")
+	var doc cadence.DomainMetadataCollectionEntry
+	err := db.collection(cadence.DomainMetadataCollectionName).FindOne(ctx, bson.D{{"_id", domainMetadataID}}).Decode(&doc)
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			// the metadata document doesn't exist before the first domain is created
+			return 0, nil
+		}
+		return -1, err
+	}
+	return doc.NotificationVersion, nil
+}
+
+func newDomainDocument(row *nosqlplugin.DomainRow) (*cadence.DomainCollectionEntry, error) {
+	data, encoding, err := encodeData(row)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.DomainCollectionEntry{
+		ID:           row.Info.ID,
+		Name:         row.Info.Name,
+		Data:         data,
+		DataEncoding: encoding,
+	}, nil
 }
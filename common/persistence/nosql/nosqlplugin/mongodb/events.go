@@ -22,31 +22,186 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
 )
 
 // InsertIntoHistoryTreeAndNode inserts one or two rows: tree row and node row(at least one of them)
 func (db *mdb) InsertIntoHistoryTreeAndNode(ctx context.Context, treeRow *nosqlplugin.HistoryTreeRow, nodeRow *nosqlplugin.HistoryNodeRow) error {
-	panic("TODO")
+	if treeRow == nil && nodeRow == nil {
+		return fmt.Errorf("require at least a tree row or a node row to insert")
+	}
+
+	insert := func(ctx context.Context) error {
+		if treeRow != nil {
+			data, encoding, err := encodeData(treeRow)
+			if err != nil {
+				return err
+			}
+			_, err = db.collection(cadence.HistoryTreeCollectionName).ReplaceOne(ctx,
+				bson.D{{"treeid", treeRow.TreeID}, {"branchid", treeRow.BranchID}},
+				cadence.HistoryTreeCollectionEntry{
+					TreeID:       treeRow.TreeID,
+					BranchID:     treeRow.BranchID,
+					Data:         data,
+					DataEncoding: encoding,
+				},
+				options.Replace().SetUpsert(true),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if nodeRow != nil {
+			txnID := common.Int64Default(nodeRow.TxnID)
+			_, err := db.collection(cadence.HistoryNodeCollectionName).ReplaceOne(ctx,
+				bson.D{{"treeid", nodeRow.TreeID}, {"branchid", nodeRow.BranchID}, {"nodeid", nodeRow.NodeID}, {"txnid", txnID}},
+				cadence.HistoryNodeCollectionEntry{
+					TreeID:       nodeRow.TreeID,
+					BranchID:     nodeRow.BranchID,
+					NodeID:       nodeRow.NodeID,
+					TxnID:        txnID,
+					Data:         nodeRow.Data,
+					DataEncoding: nodeRow.DataEncoding,
+				},
+				options.Replace().SetUpsert(true),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if treeRow == nil || nodeRow == nil {
+		return insert(ctx)
+	}
+	return db.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		return insert(sessCtx)
+	})
 }
 
 // SelectFromHistoryNode read nodes based on a filter
 func (db *mdb) SelectFromHistoryNode(ctx context.Context, filter *nosqlplugin.HistoryNodeFilter) ([]*nosqlplugin.HistoryNodeRow, []byte, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.HistoryNodeCollectionEntry](ctx,
+		db.collection(cadence.HistoryNodeCollectionName),
+		bson.D{
+			{"treeid", filter.TreeID},
+			{"branchid", filter.BranchID},
+			{"nodeid", bson.D{{"$gte", filter.MinNodeID}, {"$lt", filter.MaxNodeID}}},
+		},
+		// a node can be written by multiple transactions, the one with the largest txn ID comes first
+		[]sortKey{{field: "nodeid"}, {field: "txnid", descending: true}},
+		filter.PageSize,
+		filter.NextPageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]*nosqlplugin.HistoryNodeRow, 0, len(docs))
+	for _, doc := range docs {
+		rows = append(rows, &nosqlplugin.HistoryNodeRow{
+			TreeID:       doc.TreeID,
+			BranchID:     doc.BranchID,
+			NodeID:       doc.NodeID,
+			TxnID:        common.Int64Ptr(doc.TxnID),
+			Data:         doc.Data,
+			DataEncoding: doc.DataEncoding,
+		})
+	}
+	return rows, nextPageToken, nil
 }
 
 // DeleteFromHistoryTreeAndNode delete a branch record, and a list of ranges of nodes.
 func (db *mdb) DeleteFromHistoryTreeAndNode(ctx context.Context, treeFilter *nosqlplugin.HistoryTreeFilter, nodeFilters []*nosqlplugin.HistoryNodeFilter) error {
-	panic("TODO")
+	// delete the nodes first, so that the branch can still be found to retry if the deletion fails in the middle
+	for _, nodeFilter := range nodeFilters {
+		_, err := db.collection(cadence.HistoryNodeCollectionName).DeleteMany(ctx, bson.D{
+			{"treeid", nodeFilter.TreeID},
+			{"branchid", nodeFilter.BranchID},
+			{"nodeid", bson.D{{"$gte", nodeFilter.MinNodeID}}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := db.collection(cadence.HistoryTreeCollectionName).DeleteOne(ctx,
+		bson.D{{"treeid", treeFilter.TreeID}, {"branchid", common.StringDefault(treeFilter.BranchID)}},
+	)
+	return err
 }
 
 // SelectAllHistoryTrees will return all tree branches with pagination
 func (db *mdb) SelectAllHistoryTrees(ctx context.Context, nextPageToken []byte, pageSize int) ([]*nosqlplugin.HistoryTreeRow, []byte, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.HistoryTreeCollectionEntry](ctx,
+		db.collection(cadence.HistoryTreeCollectionName),
+		bson.D{},
+		[]sortKey{{field: "treeid"}, {field: "branchid"}},
+		pageSize,
+		nextPageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := decodeHistoryTrees(docs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rows, nextPageToken, nil
 }
 
 // SelectFromHistoryTree read branch records for a tree
 func (db *mdb) SelectFromHistoryTree(ctx context.Context, filter *nosqlplugin.HistoryTreeFilter) ([]*nosqlplugin.HistoryTreeRow, error) {
-	panic("TODO")
+	docs, _, err := findPage[cadence.HistoryTreeCollectionEntry](ctx,
+		db.collection(cadence.HistoryTreeCollectionName),
+		bson.D{{"treeid", filter.TreeID}},
+		[]sortKey{{field: "branchid"}},
+		0,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := decodeHistoryTrees(docs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		sortBranchAncestors(row)
+	}
+	return rows, nil
+}
+
+func decodeHistoryTrees(docs []*cadence.HistoryTreeCollectionEntry) ([]*nosqlplugin.HistoryTreeRow, error) {
+	rows := make([]*nosqlplugin.HistoryTreeRow, 0, len(docs))
+	for _, doc := range docs {
+		row := &nosqlplugin.HistoryTreeRow{}
+		if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// sortBranchAncestors sorts the ancestors by EndNodeID and sets the BeginNodeID
+func sortBranchAncestors(row *nosqlplugin.HistoryTreeRow) {
+	ancestors := row.Ancestors
+	if len(ancestors) == 0 {
+		return
+	}
+	sort.Slice(ancestors, func(i, j int) bool { return ancestors[i].EndNodeID < ancestors[j].EndNodeID })
+	ancestors[0].BeginNodeID = int64(1)
+	for i := 1; i < len(ancestors); i++ {
+		ancestors[i].BeginNodeID = ancestors[i-1].EndNodeID
+	}
 }
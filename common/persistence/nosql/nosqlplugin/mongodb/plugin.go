@@ -58,7 +58,11 @@ func (p *plugin) CreateAdminDB(cfg *config.NoSQL, logger log.Logger, dc *persist
 }
 
 func (p *plugin) doCreateDB(cfg *config.NoSQL, logger log.Logger) (*mdb, error) {
-	uri := fmt.Sprintf("mongodb://%v:%v@%v:%v/", cfg.User, cfg.Password, cfg.Hosts, cfg.Port)
+	credentials := ""
+	if cfg.User != "" {
+		credentials = fmt.Sprintf("%v:%v@", cfg.User, cfg.Password)
+	}
+	uri := fmt.Sprintf("mongodb://%v%v:%v/", credentials, cfg.Hosts, cfg.Port)
 	// TODO CreateDB/CreateAdminDB don't pass in context.Context so we are using background for now
 	// It's okay because this is being called during server startup or CLI.
 	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
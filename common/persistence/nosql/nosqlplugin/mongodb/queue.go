@@ -22,10 +22,18 @@ package mongodb
 
 import (
 	"context"
-	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
+)
+
+const (
+	queueComponentName = "queue"
 )
 
 // Insert message into queue, return error if failed or already exists
@@ -34,7 +42,20 @@ func (db *mdb) InsertIntoQueue(
 	ctx context.Context,
 	row *nosqlplugin.QueueMessageRow,
 ) error {
-	panic("TODO")
+	data, encoding, err := encodeData(row)
+	if err != nil {
+		return err
+	}
+	_, err = db.collection(cadence.QueueMessageCollectionName).InsertOne(ctx, cadence.QueueMessageCollectionEntry{
+		QueueType:    int(row.QueueType),
+		MessageID:    row.ID,
+		Data:         data,
+		DataEncoding: encoding,
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return nosqlplugin.NewConditionFailure(queueComponentName)
+	}
+	return err
 }
 
 // Get the ID of last message inserted into the queue
@@ -42,7 +63,15 @@ func (db *mdb) SelectLastEnqueuedMessageID(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (int64, error) {
-	panic("TODO")
+	var doc cadence.QueueMessageCollectionEntry
+	err := db.collection(cadence.QueueMessageCollectionName).FindOne(ctx,
+		bson.D{{"queuetype", int(queueType)}},
+		options.FindOne().SetSort(bson.D{{"messageid", -1}}),
+	).Decode(&doc)
+	if err != nil {
+		return 0, err
+	}
+	return doc.MessageID, nil
 }
 
 // Read queue messages starting from the exclusiveBeginMessageID
@@ -52,7 +81,17 @@ func (db *mdb) SelectMessagesFrom(
 	exclusiveBeginMessageID int64,
 	maxRows int,
 ) ([]*nosqlplugin.QueueMessageRow, error) {
-	panic("TODO")
+	docs, _, err := findPage[cadence.QueueMessageCollectionEntry](ctx,
+		db.collection(cadence.QueueMessageCollectionName),
+		bson.D{{"queuetype", int(queueType)}, {"messageid", bson.D{{"$gt", exclusiveBeginMessageID}}}},
+		[]sortKey{{field: "messageid"}},
+		maxRows,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return decodeQueueMessages(docs)
 }
 
 // Read queue message starting from exclusiveBeginMessageID int64, inclusiveEndMessageID int64
@@ -60,7 +99,30 @@ func (db *mdb) SelectMessagesBetween(
 	ctx context.Context,
 	request nosqlplugin.SelectMessagesBetweenRequest,
 ) (*nosqlplugin.SelectMessagesBetweenResponse, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.QueueMessageCollectionEntry](ctx,
+		db.collection(cadence.QueueMessageCollectionName),
+		bson.D{
+			{"queuetype", int(request.QueueType)},
+			{"messageid", bson.D{{"$gt", request.ExclusiveBeginMessageID}, {"$lte", request.InclusiveEndMessageID}}},
+		},
+		[]sortKey{{field: "messageid"}},
+		request.PageSize,
+		request.NextPageToken,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := decodeQueueMessages(docs)
+	if err != nil {
+		return nil, err
+	}
+	response := &nosqlplugin.SelectMessagesBetweenResponse{
+		NextPageToken: nextPageToken,
+	}
+	for _, row := range rows {
+		response.Rows = append(response.Rows, *row)
+	}
+	return response, nil
 }
 
 // Delete all messages before exclusiveBeginMessageID
@@ -69,7 +131,10 @@ func (db *mdb) DeleteMessagesBefore(
 	queueType persistence.QueueType,
 	exclusiveBeginMessageID int64,
 ) error {
-	panic("TODO")
+	_, err := db.collection(cadence.QueueMessageCollectionName).DeleteMany(ctx,
+		bson.D{{"queuetype", int(queueType)}, {"messageid", bson.D{{"$lt", exclusiveBeginMessageID}}}},
+	)
+	return err
 }
 
 // Delete all messages in a range between exclusiveBeginMessageID and inclusiveEndMessageID
@@ -79,7 +144,13 @@ func (db *mdb) DeleteMessagesInRange(
 	exclusiveBeginMessageID int64,
 	inclusiveEndMessageID int64,
 ) error {
-	panic("TODO")
+	_, err := db.collection(cadence.QueueMessageCollectionName).DeleteMany(ctx,
+		bson.D{
+			{"queuetype", int(queueType)},
+			{"messageid", bson.D{{"$gt", exclusiveBeginMessageID}, {"$lte", inclusiveEndMessageID}}},
+		},
+	)
+	return err
 }
 
 // Delete one message
@@ -88,7 +159,10 @@ func (db *mdb) DeleteMessage(
 	queueType persistence.QueueType,
 	messageID int64,
 ) error {
-	panic("TODO")
+	_, err := db.collection(cadence.QueueMessageCollectionName).DeleteOne(ctx,
+		bson.D{{"queuetype", int(queueType)}, {"messageid", messageID}},
+	)
+	return err
 }
 
 // Insert an empty metadata row, starting from a version
@@ -97,8 +171,20 @@ func (db *mdb) InsertQueueMetadata(
 	queueType persistence.QueueType,
 	version int64,
 ) error {
-	fmt.Println("not implemented, ignore the eror for testing")
-	return nil
+	doc, err := newQueueMetadataDocument(nosqlplugin.QueueMetadataRow{
+		QueueType:        queueType,
+		ClusterAckLevels: map[string]int64{},
+		Version:          version,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.collection(cadence.QueueMetadataCollectionName).InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		// it's ok if the document is not inserted, which means that the record exists already.
+		return nil
+	}
+	return err
 }
 
 // **Conditionally** update a queue metadata row, if current version is matched(meaning current == row.Version - 1),
@@ -108,7 +194,21 @@ func (db *mdb) UpdateQueueMetadataCas(
 	ctx context.Context,
 	row nosqlplugin.QueueMetadataRow,
 ) error {
-	panic("TODO")
+	doc, err := newQueueMetadataDocument(row)
+	if err != nil {
+		return err
+	}
+	result, err := db.collection(cadence.QueueMetadataCollectionName).ReplaceOne(ctx,
+		bson.D{{"_id", int(row.QueueType)}, {"version", row.Version - 1}},
+		doc,
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return nosqlplugin.NewConditionFailure(queueComponentName)
+	}
+	return nil
 }
 
 // Read a QueueMetadata
@@ -116,13 +216,52 @@ func (db *mdb) SelectQueueMetadata(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (*nosqlplugin.QueueMetadataRow, error) {
-	fmt.Println("not implemented, ignore the eror for testing")
-	return nil, nil
+	var doc cadence.QueueMetadataCollectionEntry
+	err := db.collection(cadence.QueueMetadataCollectionName).FindOne(ctx, bson.D{{"_id", int(queueType)}}).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	row := &nosqlplugin.QueueMetadataRow{}
+	if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+		return nil, err
+	}
+	// if record exist but ackLevels is empty, we initialize the map
+	if row.ClusterAckLevels == nil {
+		row.ClusterAckLevels = make(map[string]int64)
+	}
+	row.QueueType = queueType
+	row.Version = doc.Version
+	return row, nil
 }
 
 func (db *mdb) GetQueueSize(
 	ctx context.Context,
 	queueType persistence.QueueType,
 ) (int64, error) {
-	panic("TODO")
+	return db.collection(cadence.QueueMessageCollectionName).CountDocuments(ctx, bson.D{{"queuetype", int(queueType)}})
+}
+
+func newQueueMetadataDocument(row nosqlplugin.QueueMetadataRow) (*cadence.QueueMetadataCollectionEntry, error) {
+	data, encoding, err := encodeData(&row)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.QueueMetadataCollectionEntry{
+		QueueType:    int(row.QueueType),
+		Version:      row.Version,
+		Data:         data,
+		DataEncoding: encoding,
+	}, nil
+}
+
+func decodeQueueMessages(docs []*cadence.QueueMessageCollectionEntry) ([]*nosqlplugin.QueueMessageRow, error) {
+	rows := make([]*nosqlplugin.QueueMessageRow, 0, len(docs))
+	for _, doc := range docs {
+		row := &nosqlplugin.QueueMessageRow{}
+		if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
 }
@@ -22,31 +22,124 @@ package mongodb
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
 )
 
 // InsertShard creates a new shard, return error is there is any.
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *mdb) InsertShard(ctx context.Context, row *nosqlplugin.ShardRow) error {
-	log.Println("not implemented...ignore the error for testing...")
-	return nil
+	doc, err := newShardDocument(row)
+	if err != nil {
+		return err
+	}
+	_, err = db.collection(cadence.ShardCollectionName).InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return db.newShardConditionFailure(ctx, row.ShardID, "shard already exists")
+	}
+	return err
 }
 
 // SelectShard gets a shard
 func (db *mdb) SelectShard(ctx context.Context, shardID int, currentClusterName string) (int64, *nosqlplugin.ShardRow, error) {
-	panic("TODO")
+	var doc cadence.ShardCollectionEntry
+	err := db.collection(cadence.ShardCollectionName).FindOne(ctx, bson.D{{"_id", shardID}}).Decode(&doc)
+	if err != nil {
+		return 0, nil, err
+	}
+	row := &nosqlplugin.ShardRow{}
+	if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+		return 0, nil, err
+	}
+
+	if row.ClusterTransferAckLevel == nil {
+		row.ClusterTransferAckLevel = map[string]int64{
+			currentClusterName: row.TransferAckLevel,
+		}
+	}
+	if row.ClusterTimerAckLevel == nil {
+		row.ClusterTimerAckLevel = map[string]time.Time{
+			currentClusterName: row.TimerAckLevel,
+		}
+	}
+	if row.ClusterReplicationLevel == nil {
+		row.ClusterReplicationLevel = make(map[string]int64)
+	}
+	if row.ReplicationDLQAckLevel == nil {
+		row.ReplicationDLQAckLevel = make(map[string]int64)
+	}
+	return doc.RangeID, row, nil
 }
 
 // UpdateRangeID updates the rangeID, return error is there is any
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *mdb) UpdateRangeID(ctx context.Context, shardID int, rangeID int64, previousRangeID int64) error {
-	panic("TODO")
+	result, err := db.collection(cadence.ShardCollectionName).UpdateOne(ctx,
+		bson.D{{"_id", shardID}, {"rangeid", previousRangeID}},
+		bson.D{{"$set", bson.D{{"rangeid", rangeID}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return db.newShardConditionFailure(ctx, shardID, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return nil
 }
 
 // UpdateShard updates a shard, return error is there is any.
 // Return ShardOperationConditionFailure if the condition doesn't meet
 func (db *mdb) UpdateShard(ctx context.Context, row *nosqlplugin.ShardRow, previousRangeID int64) error {
-	panic("TODO")
+	doc, err := newShardDocument(row)
+	if err != nil {
+		return err
+	}
+	result, err := db.collection(cadence.ShardCollectionName).ReplaceOne(ctx,
+		bson.D{{"_id", row.ShardID}, {"rangeid", previousRangeID}},
+		doc,
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return db.newShardConditionFailure(ctx, row.ShardID, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return nil
+}
+
+func newShardDocument(row *nosqlplugin.ShardRow) (*cadence.ShardCollectionEntry, error) {
+	data, encoding, err := encodeData(row)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.ShardCollectionEntry{
+		ShardID:      row.ShardID,
+		RangeID:      row.RangeID,
+		Data:         data,
+		DataEncoding: encoding,
+	}, nil
+}
+
+// newShardConditionFailure reads the current range_id of the shard to build the condition failure error
+func (db *mdb) newShardConditionFailure(ctx context.Context, shardID int, details string) error {
+	var doc cadence.ShardCollectionEntry
+	err := db.collection(cadence.ShardCollectionName).FindOne(ctx, bson.D{{"_id", shardID}}).Decode(&doc)
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			return &nosqlplugin.ShardOperationConditionFailure{
+				Details: fmt.Sprintf("shard %v not found: %v", shardID, details),
+			}
+		}
+		return err
+	}
+	return &nosqlplugin.ShardOperationConditionFailure{
+		RangeID: doc.RangeID,
+		Details: fmt.Sprintf("shard_id=%v,range_id=%v: %v", shardID, doc.RangeID, details),
+	}
 }
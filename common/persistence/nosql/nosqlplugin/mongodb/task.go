@@ -22,20 +22,46 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
+)
+
+const (
+	initialRangeID = 1 // Id of the first range of a new task list
+
+	taskListRangeOperation = "tasklist_range"
 )
 
 // SelectTaskList returns a single tasklist row.
 // Return IsNotFoundError if the row doesn't exist
 func (db *mdb) SelectTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter) (*nosqlplugin.TaskListRow, error) {
-	panic("TODO")
+	var doc cadence.TaskListCollectionEntry
+	if err := db.collection(cadence.TaskListCollectionName).FindOne(ctx, taskListDocumentFilter(filter)).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return toTaskListRow(&doc)
 }
 
 // InsertTaskList insert a single tasklist row
 // Return IsConditionFailedError if the row already exists, and also the existing row
 func (db *mdb) InsertTaskList(ctx context.Context, row *nosqlplugin.TaskListRow) error {
-	panic("TODO")
+	newRow := *row
+	newRow.RangeID = initialRangeID
+	newRow.AckLevel = 0
+	doc, err := newTaskListDocument(&newRow, 0)
+	if err != nil {
+		return err
+	}
+	_, err = db.collection(cadence.TaskListCollectionName).InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return db.newTaskListConditionFailure(ctx, taskListFilterOf(row), "tasklist already exists")
+	}
+	return err
 }
 
 // UpdateTaskList updates a single tasklist row
@@ -45,7 +71,7 @@ func (db *mdb) UpdateTaskList(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
-	panic("TODO")
+	return db.updateTaskList(ctx, 0, row, previousRangeID)
 }
 
 // UpdateTaskList updates a single tasklist row, and set an TTL on the record
@@ -58,19 +84,72 @@ func (db *mdb) UpdateTaskListWithTTL(
 	row *nosqlplugin.TaskListRow,
 	previousRangeID int64,
 ) error {
-	panic("TODO")
+	return db.updateTaskList(ctx, ttlSeconds, row, previousRangeID)
+}
+
+func (db *mdb) updateTaskList(
+	ctx context.Context,
+	ttlSeconds int64,
+	row *nosqlplugin.TaskListRow,
+	previousRangeID int64,
+) error {
+	doc, err := newTaskListDocument(row, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	filter := taskListFilterOf(row)
+	result, err := db.collection(cadence.TaskListCollectionName).ReplaceOne(ctx,
+		append(taskListDocumentFilter(filter), bson.E{Key: "rangeid", Value: previousRangeID}),
+		doc,
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return db.newTaskListConditionFailure(ctx, filter, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return nil
 }
 
 // ListTaskList returns all tasklists.
 // Noop if TTL is already implemented in other methods
 func (db *mdb) ListTaskList(ctx context.Context, pageSize int, nextPageToken []byte) (*nosqlplugin.ListTaskListResult, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.TaskListCollectionEntry](ctx,
+		db.collection(cadence.TaskListCollectionName),
+		bson.D{},
+		[]sortKey{{field: "domainid"}, {field: "tasklistname"}, {field: "tasklisttype"}},
+		pageSize,
+		nextPageToken,
+	)
+	if err != nil {
+		return nil, err
+	}
+	result := &nosqlplugin.ListTaskListResult{
+		NextPageToken: nextPageToken,
+	}
+	for _, doc := range docs {
+		row, err := toTaskListRow(doc)
+		if err != nil {
+			return nil, err
+		}
+		result.TaskLists = append(result.TaskLists, row)
+	}
+	return result, nil
 }
 
 // DeleteTaskList deletes a single tasklist row
 // Return TaskOperationConditionFailure if the condition doesn't meet
 func (db *mdb) DeleteTaskList(ctx context.Context, filter *nosqlplugin.TaskListFilter, previousRangeID int64) error {
-	panic("TODO")
+	result, err := db.collection(cadence.TaskListCollectionName).DeleteOne(ctx,
+		append(taskListDocumentFilter(filter), bson.E{Key: "rangeid", Value: previousRangeID}),
+	)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return db.newTaskListConditionFailure(ctx, filter, fmt.Sprintf("previous range_id %v not match", previousRangeID))
+	}
+	return nil
 }
 
 // InsertTasks inserts a batch of tasks
@@ -80,16 +159,85 @@ func (db *mdb) InsertTasks(
 	tasksToInsert []*nosqlplugin.TaskRowForInsert,
 	tasklistCondition *nosqlplugin.TaskListRow,
 ) error {
-	panic("TODO")
+	filter := taskListFilterOf(tasklistCondition)
+	docs := make([]interface{}, 0, len(tasksToInsert))
+	for _, task := range tasksToInsert {
+		row := task.TaskRow
+		row.DomainID = filter.DomainID
+		row.TaskListName = filter.TaskListName
+		row.TaskListType = filter.TaskListType
+		data, encoding, err := encodeData(&row)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, &cadence.TaskCollectionEntry{
+			DomainID:     filter.DomainID,
+			TaskListName: filter.TaskListName,
+			TaskListType: filter.TaskListType,
+			TaskID:       task.TaskID,
+			Data:         data,
+			DataEncoding: encoding,
+			ExpireTime:   expireTime(int64(task.TTLSeconds)),
+		})
+	}
+
+	err := db.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// writing the range_id of the tasklist makes the transaction conflict with any concurrent update of the tasklist
+		result, err := db.collection(cadence.TaskListCollectionName).UpdateOne(sessCtx,
+			append(taskListDocumentFilter(filter), bson.E{Key: "rangeid", Value: tasklistCondition.RangeID}),
+			bson.D{{"$set", bson.D{{"rangeid", tasklistCondition.RangeID}}}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return &conditionFailedError{operation: taskListRangeOperation}
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		_, err = db.collection(cadence.TaskCollectionName).InsertMany(sessCtx, docs)
+		return err
+	})
+	if isConditionFailed(err, taskListRangeOperation) {
+		return db.newTaskListConditionFailure(ctx, filter, fmt.Sprintf("range_id %v not match", tasklistCondition.RangeID))
+	}
+	return err
 }
 
 // SelectTasks return tasks that associated to a tasklist
 func (db *mdb) SelectTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) ([]*nosqlplugin.TaskRow, error) {
-	panic("TODO")
+	docs, _, err := findPage[cadence.TaskCollectionEntry](ctx,
+		db.collection(cadence.TaskCollectionName),
+		append(taskListDocumentFilter(&filter.TaskListFilter),
+			bson.E{Key: "taskid", Value: bson.D{{"$gt", filter.MinTaskID}, {"$lte", filter.MaxTaskID}}},
+		),
+		[]sortKey{{field: "taskid"}},
+		filter.BatchSize,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*nosqlplugin.TaskRow, 0, len(docs))
+	for _, doc := range docs {
+		row := &nosqlplugin.TaskRow{}
+		if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
 }
 
+// SelectTasks return tasks that associated to a tasklist
 func (db *mdb) GetTasksCount(ctx context.Context, filter *nosqlplugin.TasksFilter) (int64, error) {
-	panic("TODO")
+	return db.collection(cadence.TaskCollectionName).CountDocuments(ctx,
+		append(taskListDocumentFilter(&filter.TaskListFilter),
+			bson.E{Key: "taskid", Value: bson.D{{"$gt", filter.MinTaskID}}},
+		),
+	)
 }
 
 // DeleteTask delete a batch tasks that taskIDs less than the row
@@ -97,5 +245,72 @@ func (db *mdb) GetTasksCount(ctx context.Context, filter *nosqlplugin.TasksFilte
 // NOTE: This API ignores the `BatchSize` request parameter i.e. either all tasks leq the task_id will be deleted or an error will
 // be returned to the caller, because rowsDeleted is not supported by Cassandra
 func (db *mdb) RangeDeleteTasks(ctx context.Context, filter *nosqlplugin.TasksFilter) (rowsDeleted int, err error) {
-	panic("TODO")
+	result, err := db.collection(cadence.TaskCollectionName).DeleteMany(ctx,
+		append(taskListDocumentFilter(&filter.TaskListFilter),
+			bson.E{Key: "taskid", Value: bson.D{{"$gt", filter.MinTaskID}, {"$lte", filter.MaxTaskID}}},
+		),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
+
+// taskListDocumentFilter returns the filter of the tasklist document, or the tasks of the tasklist
+func taskListDocumentFilter(filter *nosqlplugin.TaskListFilter) bson.D {
+	return bson.D{
+		{"domainid", filter.DomainID},
+		{"tasklistname", filter.TaskListName},
+		{"tasklisttype", filter.TaskListType},
+	}
+}
+
+func taskListFilterOf(row *nosqlplugin.TaskListRow) *nosqlplugin.TaskListFilter {
+	return &nosqlplugin.TaskListFilter{
+		DomainID:     row.DomainID,
+		TaskListName: row.TaskListName,
+		TaskListType: row.TaskListType,
+	}
+}
+
+func newTaskListDocument(row *nosqlplugin.TaskListRow, ttlSeconds int64) (*cadence.TaskListCollectionEntry, error) {
+	data, encoding, err := encodeData(row)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.TaskListCollectionEntry{
+		DomainID:     row.DomainID,
+		TaskListName: row.TaskListName,
+		TaskListType: row.TaskListType,
+		RangeID:      row.RangeID,
+		Data:         data,
+		DataEncoding: encoding,
+		ExpireTime:   expireTime(ttlSeconds),
+	}, nil
+}
+
+func toTaskListRow(doc *cadence.TaskListCollectionEntry) (*nosqlplugin.TaskListRow, error) {
+	row := &nosqlplugin.TaskListRow{}
+	if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+		return nil, err
+	}
+	row.RangeID = doc.RangeID
+	return row, nil
+}
+
+// newTaskListConditionFailure reads the current range_id of the tasklist to build the condition failure error
+func (db *mdb) newTaskListConditionFailure(ctx context.Context, filter *nosqlplugin.TaskListFilter, details string) error {
+	var doc cadence.TaskListCollectionEntry
+	if err := db.collection(cadence.TaskListCollectionName).FindOne(ctx, taskListDocumentFilter(filter)).Decode(&doc); err != nil {
+		if db.IsNotFoundError(err) {
+			return &nosqlplugin.TaskOperationConditionFailure{
+				Details: fmt.Sprintf("tasklist %v not found: %v", filter.TaskListName, details),
+			}
+		}
+		return err
+	}
+	return &nosqlplugin.TaskOperationConditionFailure{
+		RangeID: doc.RangeID,
+		Details: fmt.Sprintf("range_id=%v: %v", doc.RangeID, details),
+	}
 }
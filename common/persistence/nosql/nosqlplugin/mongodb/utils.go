@@ -0,0 +1,172 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/uber/cadence/common"
+)
+
+const (
+	// dataEncodingJSON is the encoding of the data fields
+	dataEncodingJSON = string(common.EncodingTypeJSON)
+)
+
+// encodeData encodes a row into the data field of a document
+func encodeData(row interface{}) ([]byte, string, error) {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, dataEncodingJSON, nil
+}
+
+// decodeData decodes the data field of a document into a row
+func decodeData(data []byte, encoding string, row interface{}) error {
+	if encoding != dataEncodingJSON {
+		return fmt.Errorf("unsupported data encoding %v", encoding)
+	}
+	return json.Unmarshal(data, row)
+}
+
+// expireTime returns the value of the TTL index field, nil means the document never expires
+func expireTime(ttlSeconds int64) *time.Time {
+	if ttlSeconds <= 0 {
+		return nil
+	}
+	t := time.Now().Add(time.Duration(ttlSeconds) * time.Second)
+	return &t
+}
+
+// encodeMapKey encodes a map key so that it can be used in a field path, which can't contain "." or start with "$"
+func encodeMapKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeMapKey(key string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(key)
+	return string(decoded), err
+}
+
+// sortKey is a field of the sort order of a paginated query
+type sortKey struct {
+	field      string
+	descending bool
+}
+
+// findPage reads one page of documents sorted by the sort keys, which must be unique within the filter.
+// The page token is the sort key values of the last document of the previous page.
+func findPage[T any](
+	ctx context.Context,
+	collection *mongo.Collection,
+	filter bson.D,
+	keys []sortKey,
+	pageSize int,
+	pageToken []byte,
+) ([]*T, []byte, error) {
+	if len(pageToken) > 0 {
+		var last bson.D
+		if err := bson.Unmarshal(pageToken, &last); err != nil {
+			return nil, nil, fmt.Errorf("invalid page token: %v", err)
+		}
+		if len(last) != len(keys) {
+			return nil, nil, fmt.Errorf("invalid page token: expect %v keys but got %v", len(keys), len(last))
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: afterFilter(keys, last)})
+	}
+
+	sort := bson.D{}
+	for _, key := range keys {
+		order := 1
+		if key.descending {
+			order = -1
+		}
+		sort = append(sort, bson.E{Key: key.field, Value: order})
+	}
+	findOptions := options.Find().SetSort(sort)
+	if pageSize > 0 {
+		findOptions.SetLimit(int64(pageSize))
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	var documents []bson.Raw
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]*T, 0, len(documents))
+	for _, document := range documents {
+		result := new(T)
+		if err := bson.Unmarshal(document, result); err != nil {
+			return nil, nil, err
+		}
+		results = append(results, result)
+	}
+
+	var nextPageToken []byte
+	if pageSize > 0 && len(documents) == pageSize {
+		last := bson.D{}
+		for _, key := range keys {
+			value, err := documents[len(documents)-1].LookupErr(key.field)
+			if err != nil {
+				return nil, nil, err
+			}
+			last = append(last, bson.E{Key: key.field, Value: value})
+		}
+		if nextPageToken, err = bson.Marshal(last); err != nil {
+			return nil, nil, err
+		}
+	}
+	return results, nextPageToken, nil
+}
+
+// afterFilter returns the conditions of the documents after the last document in the sort order:
+// (k1 after v1) OR (k1 = v1 AND k2 after v2) OR ...
+func afterFilter(keys []sortKey, last bson.D) bson.A {
+	conditions := bson.A{}
+	for i, key := range keys {
+		condition := bson.D{}
+		for j := 0; j < i; j++ {
+			condition = append(condition, bson.E{Key: keys[j].field, Value: last[j].Value})
+		}
+		operator := "$gt"
+		if key.descending {
+			operator = "$lt"
+		}
+		condition = append(condition, bson.E{Key: key.field, Value: bson.D{{operator, last[i].Value}}})
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
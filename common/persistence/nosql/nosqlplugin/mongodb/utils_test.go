@@ -0,0 +1,96 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mongodb
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/uber/cadence/common/persistence"
+)
+
+func TestEncodeMapKey(t *testing.T) {
+	for _, key := range []string{"", "timer-1", "a.b.c", "$signal", "中文"} {
+		encoded := encodeMapKey(key)
+		assert.False(t, strings.Contains(encoded, "."))
+		assert.False(t, strings.HasPrefix(encoded, "$"))
+		decoded, err := decodeMapKey(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, key, decoded)
+	}
+}
+
+func TestFieldKey(t *testing.T) {
+	assert.Equal(t, "-5", fieldKey(int64(-5)))
+	assert.Equal(t, "123", fieldKey(int64(123)))
+	assert.Equal(t, encodeMapKey("a.b"), fieldKey("a.b"))
+}
+
+func TestEncodeMap(t *testing.T) {
+	encoded, err := encodeMap(map[string]*persistence.TimerInfo{
+		"timer.1": {TimerID: "timer.1", StartedID: 5},
+	})
+	require.NoError(t, err)
+	decoded, err := decodeStringMap[persistence.TimerInfo](encoded)
+	require.NoError(t, err)
+	require.Contains(t, decoded, "timer.1")
+	assert.Equal(t, int64(5), decoded["timer.1"].StartedID)
+
+	encodedInt64, err := encodeMap(map[int64]*persistence.SignalInfo{
+		7: {InitiatedID: 7, SignalRequestID: "request"},
+	})
+	require.NoError(t, err)
+	decodedInt64, err := decodeInt64Map[persistence.SignalInfo](encodedInt64)
+	require.NoError(t, err)
+	require.Contains(t, decodedInt64, int64(7))
+	assert.Equal(t, "request", decodedInt64[7].SignalRequestID)
+}
+
+func TestDecodeData_UnsupportedEncoding(t *testing.T) {
+	var row struct{}
+	assert.Error(t, decodeData([]byte("{}"), "thriftrw", &row))
+}
+
+func TestExpireTime(t *testing.T) {
+	assert.Nil(t, expireTime(0))
+	assert.Nil(t, expireTime(-1))
+	expire := expireTime(60)
+	require.NotNil(t, expire)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *expire, 5*time.Second)
+}
+
+func TestAfterFilter(t *testing.T) {
+	keys := []sortKey{{field: "starttime", descending: true}, {field: "runid"}}
+	last := bson.D{{Key: "starttime", Value: int64(100)}, {Key: "runid", Value: "run"}}
+
+	assert.Equal(t, bson.A{
+		bson.D{{Key: "starttime", Value: bson.D{{Key: "$lt", Value: int64(100)}}}},
+		bson.D{
+			{Key: "starttime", Value: int64(100)},
+			{Key: "runid", Value: bson.D{{Key: "$gt", Value: "run"}}},
+		},
+	}, afterFilter(keys, last))
+}
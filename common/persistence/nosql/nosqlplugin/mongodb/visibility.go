@@ -22,8 +22,15 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
 )
 
 func (db *mdb) InsertVisibility(
@@ -31,7 +38,21 @@ func (db *mdb) InsertVisibility(
 	ttlSeconds int64,
 	row *nosqlplugin.VisibilityRowForInsert,
 ) error {
-	panic("TODO")
+	doc, err := newVisibilityDocument(row.DomainID, &row.VisibilityRow, false, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	// the started record must not override the closed record if the close event is recorded first,
+	// in which case the upsert fails on the unique index
+	_, err = db.collection(cadence.VisibilityCollectionName).ReplaceOne(ctx,
+		bson.D{{"domainid", doc.DomainID}, {"runid", doc.RunID}, {"closed", false}},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
 }
 
 func (db *mdb) UpdateVisibility(
@@ -39,26 +60,141 @@ func (db *mdb) UpdateVisibility(
 	ttlSeconds int64,
 	row *nosqlplugin.VisibilityRowForUpdate,
 ) error {
-	panic("TODO")
+	if row.UpdateCloseToOpen {
+		return fmt.Errorf("updating visibility record from closed to open is not supported")
+	}
+	doc, err := newVisibilityDocument(row.DomainID, &row.VisibilityRow, true, ttlSeconds)
+	if err != nil {
+		return err
+	}
+	_, err = db.collection(cadence.VisibilityCollectionName).ReplaceOne(ctx,
+		bson.D{{"domainid", doc.DomainID}, {"runid", doc.RunID}},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	return err
 }
 
 func (db *mdb) SelectVisibility(
 	ctx context.Context,
 	filter *nosqlplugin.VisibilityFilter,
 ) (*nosqlplugin.SelectVisibilityResponse, error) {
-	panic("TODO")
+	request := &filter.ListRequest
+
+	var closed bool
+	switch filter.FilterType {
+	case nosqlplugin.AllClosed, nosqlplugin.ClosedByWorkflowType, nosqlplugin.ClosedByWorkflowID, nosqlplugin.ClosedByClosedStatus:
+		closed = true
+	}
+	documentFilter := bson.D{{"domainid", request.DomainUUID}, {"closed", closed}}
+	switch filter.FilterType {
+	case nosqlplugin.AllOpen, nosqlplugin.AllClosed:
+	case nosqlplugin.OpenByWorkflowType, nosqlplugin.ClosedByWorkflowType:
+		documentFilter = append(documentFilter, bson.E{Key: "workflowtypename", Value: filter.WorkflowType})
+	case nosqlplugin.OpenByWorkflowID, nosqlplugin.ClosedByWorkflowID:
+		documentFilter = append(documentFilter, bson.E{Key: "workflowid", Value: filter.WorkflowID})
+	case nosqlplugin.ClosedByClosedStatus:
+		documentFilter = append(documentFilter, bson.E{Key: "closestatus", Value: int(filter.CloseStatus)})
+	default:
+		return nil, fmt.Errorf("not supported filter type %v", filter.FilterType)
+	}
+
+	timeField := "starttime"
+	if closed && filter.SortType == nosqlplugin.SortByClosedTime {
+		timeField = "closetime"
+	} else if filter.SortType != nosqlplugin.SortByStartTime && closed {
+		return nil, fmt.Errorf("not supported sorting type %v", filter.SortType)
+	}
+	documentFilter = append(documentFilter, bson.E{Key: timeField, Value: bson.D{
+		{"$gte", request.EarliestTime.UnixNano()},
+		{"$lte", request.LatestTime.UnixNano()},
+	}})
+
+	docs, nextPageToken, err := findPage[cadence.VisibilityCollectionEntry](ctx,
+		db.collection(cadence.VisibilityCollectionName),
+		documentFilter,
+		[]sortKey{{field: timeField, descending: true}, {field: "runid", descending: true}},
+		request.PageSize,
+		request.NextPageToken,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &nosqlplugin.SelectVisibilityResponse{
+		NextPageToken: nextPageToken,
+	}
+	for _, doc := range docs {
+		row, err := toVisibilityRow(doc)
+		if err != nil {
+			return nil, err
+		}
+		response.Executions = append(response.Executions, row)
+	}
+	return response, nil
 }
 
 func (db *mdb) DeleteVisibility(
 	ctx context.Context,
 	domainID, workflowID, runID string,
 ) error {
-	panic("TODO")
+	_, err := db.collection(cadence.VisibilityCollectionName).DeleteOne(ctx, bson.D{{"domainid", domainID}, {"runid", runID}})
+	return err
 }
 
 func (db *mdb) SelectOneClosedWorkflow(
 	ctx context.Context,
 	domainID, workflowID, runID string,
 ) (*nosqlplugin.VisibilityRow, error) {
-	panic("TODO")
+	var doc cadence.VisibilityCollectionEntry
+	err := db.collection(cadence.VisibilityCollectionName).FindOne(ctx,
+		bson.D{{"domainid", domainID}, {"runid", runID}, {"workflowid", workflowID}, {"closed", true}},
+	).Decode(&doc)
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			// Special case: return nil,nil if not found(since we will deprecate it, it's not worth refactor to be consistent)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toVisibilityRow(&doc)
+}
+
+func newVisibilityDocument(domainID string, row *nosqlplugin.VisibilityRow, closed bool, ttlSeconds int64) (*cadence.VisibilityCollectionEntry, error) {
+	data := *row
+	data.DomainID = domainID
+	// search attributes are not supported by database visibility
+	data.SearchAttributes = nil
+	encoded, encoding, err := encodeData(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &cadence.VisibilityCollectionEntry{
+		DomainID:         domainID,
+		RunID:            row.RunID,
+		WorkflowID:       row.WorkflowID,
+		WorkflowTypeName: row.TypeName,
+		Closed:           closed,
+		StartTime:        row.StartTime.UnixNano(),
+		Data:             encoded,
+		DataEncoding:     encoding,
+		ExpireTime:       expireTime(ttlSeconds),
+	}
+	if closed {
+		doc.CloseTime = row.CloseTime.UnixNano()
+		if row.Status != nil {
+			status := int(*row.Status)
+			doc.CloseStatus = &status
+		}
+	}
+	return doc, nil
+}
+
+func toVisibilityRow(doc *cadence.VisibilityCollectionEntry) (*nosqlplugin.VisibilityRow, error) {
+	row := &persistence.InternalVisibilityWorkflowExecutionInfo{}
+	if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+		return nil, err
+	}
+	return row, nil
 }
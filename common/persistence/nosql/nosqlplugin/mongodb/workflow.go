@@ -23,10 +23,15 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
 )
 
 var _ nosqlplugin.WorkflowCRUD = (*mdb)(nil)
@@ -42,7 +47,26 @@ func (db *mdb) InsertWorkflowExecutionWithTasks(
 	timerTasks []*nosqlplugin.TimerTask,
 	shardCondition *nosqlplugin.ShardCondition,
 ) error {
-	panic("TODO")
+	shardID := shardCondition.ShardID
+	t := newWorkflowTransaction(shardCondition)
+	if err := db.addWorkflowRequests(t, requests); err != nil {
+		return err
+	}
+	if err := db.addCurrentWorkflow(t, shardID, currentWorkflowRequest); err != nil {
+		return err
+	}
+	if err := db.addInsertedExecution(t, shardID, execution); err != nil {
+		return err
+	}
+	if err := db.addTasks(t, shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks); err != nil {
+		return err
+	}
+
+	err := t.execute(ctx, db)
+	if failure, ok := err.(*conditionFailedError); ok {
+		return db.convertCreateWorkflowConditionFailure(ctx, failure, requests, currentWorkflowRequest, execution, shardCondition)
+	}
+	return err
 }
 
 func (db *mdb) UpdateWorkflowExecutionWithTasks(
@@ -58,105 +82,331 @@ func (db *mdb) UpdateWorkflowExecutionWithTasks(
 	timerTasks []*nosqlplugin.TimerTask,
 	shardCondition *nosqlplugin.ShardCondition,
 ) error {
-	panic("TODO")
+	shardID := shardCondition.ShardID
+	var conditionExecution *nosqlplugin.WorkflowExecutionRequest
+	if mutatedExecution != nil {
+		conditionExecution = mutatedExecution
+	} else if resetExecution != nil {
+		conditionExecution = resetExecution
+	} else {
+		return fmt.Errorf("at least one of mutatedExecution and resetExecution should be provided")
+	}
+	if conditionExecution.PreviousNextEventIDCondition == nil {
+		return fmt.Errorf("PreviousNextEventIDCondition is required for updating workflow execution")
+	}
+
+	t := newWorkflowTransaction(shardCondition)
+	if err := db.addWorkflowRequests(t, requests); err != nil {
+		return err
+	}
+	if err := db.addCurrentWorkflow(t, shardID, currentWorkflowRequest); err != nil {
+		return err
+	}
+	if mutatedExecution != nil {
+		if err := db.addMutatedExecution(t, shardID, mutatedExecution); err != nil {
+			return err
+		}
+	}
+	if insertedExecution != nil {
+		if err := db.addInsertedExecution(t, shardID, insertedExecution); err != nil {
+			return err
+		}
+	}
+	if resetExecution != nil {
+		if err := db.addResetExecution(t, shardID, resetExecution); err != nil {
+			return err
+		}
+	}
+	if err := db.addTasks(t, shardID, transferTasks, crossClusterTasks, replicationTasks, timerTasks); err != nil {
+		return err
+	}
+
+	err := t.execute(ctx, db)
+	if failure, ok := err.(*conditionFailedError); ok {
+		return db.convertUpdateWorkflowConditionFailure(ctx, failure, requests, currentWorkflowRequest, conditionExecution,
+			*conditionExecution.PreviousNextEventIDCondition, shardCondition)
+	}
+	return err
 }
 
 func (db *mdb) SelectCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID string) (*nosqlplugin.CurrentWorkflowRow, error) {
-	panic("TODO")
+	var doc cadence.CurrentWorkflowCollectionEntry
+	err := db.collection(cadence.CurrentWorkflowCollectionName).FindOne(ctx, currentWorkflowFilter(shardID, domainID, workflowID)).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	row := &nosqlplugin.CurrentWorkflowRow{}
+	if err := decodeData(doc.Data, doc.DataEncoding, row); err != nil {
+		return nil, err
+	}
+	row.ShardID = shardID
+	row.RunID = doc.CurrentRunID
+	row.LastWriteVersion = doc.LastWriteVersion
+	return row, nil
 }
 
 func (db *mdb) SelectWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) (*nosqlplugin.WorkflowExecution, error) {
-	panic("TODO")
+	var doc cadence.WorkflowExecutionCollectionEntry
+	err := db.collection(cadence.WorkflowExecutionCollectionName).FindOne(ctx, workflowExecutionFilter(shardID, domainID, workflowID, runID)).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return toWorkflowExecution(&doc)
 }
 
+// DeleteCurrentWorkflow deletes the current workflow record if the current run ID matches,
+// the deletion is skipped otherwise
 func (db *mdb) DeleteCurrentWorkflow(ctx context.Context, shardID int, domainID, workflowID, currentRunIDCondition string) error {
-	panic("TODO")
+	_, err := db.collection(cadence.CurrentWorkflowCollectionName).DeleteOne(ctx,
+		append(currentWorkflowFilter(shardID, domainID, workflowID), bson.E{Key: "currentrunid", Value: currentRunIDCondition}),
+	)
+	return err
 }
 
 func (db *mdb) DeleteWorkflowExecution(ctx context.Context, shardID int, domainID, workflowID, runID string) error {
-	panic("TODO")
+	_, err := db.collection(cadence.WorkflowExecutionCollectionName).DeleteOne(ctx, workflowExecutionFilter(shardID, domainID, workflowID, runID))
+	return err
 }
 
 func (db *mdb) SelectAllCurrentWorkflows(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.CurrentWorkflowExecution, []byte, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.CurrentWorkflowCollectionEntry](ctx,
+		db.collection(cadence.CurrentWorkflowCollectionName),
+		bson.D{{"shardid", shardID}},
+		[]sortKey{{field: "domainid"}, {field: "workflowid"}},
+		pageSize,
+		pageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	executions := make([]*persistence.CurrentWorkflowExecution, 0, len(docs))
+	for _, doc := range docs {
+		executions = append(executions, &persistence.CurrentWorkflowExecution{
+			DomainID:     doc.DomainID,
+			WorkflowID:   doc.WorkflowID,
+			RunID:        doc.CurrentRunID,
+			State:        doc.WorkflowState,
+			CurrentRunID: doc.CurrentRunID,
+		})
+	}
+	return executions, nextPageToken, nil
 }
 
 func (db *mdb) SelectAllWorkflowExecutions(ctx context.Context, shardID int, pageToken []byte, pageSize int) ([]*persistence.InternalListConcreteExecutionsEntity, []byte, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.WorkflowExecutionCollectionEntry](ctx,
+		db.collection(cadence.WorkflowExecutionCollectionName),
+		bson.D{{"shardid", shardID}},
+		[]sortKey{{field: "domainid"}, {field: "workflowid"}, {field: "runid"}},
+		pageSize,
+		pageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	executions := make([]*persistence.InternalListConcreteExecutionsEntity, 0, len(docs))
+	for _, doc := range docs {
+		data := &workflowExecutionData{}
+		if err := decodeData(doc.Data, doc.DataEncoding, data); err != nil {
+			return nil, nil, err
+		}
+		executions = append(executions, &persistence.InternalListConcreteExecutionsEntity{
+			ExecutionInfo:    data.ExecutionInfo,
+			VersionHistories: data.VersionHistories,
+		})
+	}
+	return executions, nextPageToken, nil
 }
 
 func (db *mdb) IsWorkflowExecutionExists(ctx context.Context, shardID int, domainID, workflowID, runID string) (bool, error) {
-	panic("TODO")
+	count, err := db.collection(cadence.WorkflowExecutionCollectionName).CountDocuments(ctx,
+		workflowExecutionFilter(shardID, domainID, workflowID, runID),
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
 func (db *mdb) SelectTransferTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.TransferTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.TransferTask](ctx, db, cadence.TransferTaskCollectionName, shardTaskFilter(shardID, ""), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *mdb) DeleteTransferTask(ctx context.Context, shardID int, taskID int64) error {
-	panic("TODO")
+	return db.deleteTask(ctx, cadence.TransferTaskCollectionName, shardTaskFilter(shardID, ""), taskID)
 }
 
 func (db *mdb) RangeDeleteTransferTasks(ctx context.Context, shardID int, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasks(ctx, cadence.TransferTaskCollectionName, shardTaskFilter(shardID, ""), exclusiveBeginTaskID, inclusiveEndTaskID)
 }
 
 func (db *mdb) SelectTimerTasksOrderByVisibilityTime(ctx context.Context, shardID, pageSize int, pageToken []byte, inclusiveMinTime, exclusiveMaxTime time.Time) ([]*nosqlplugin.TimerTask, []byte, error) {
-	panic("TODO")
+	docs, nextPageToken, err := findPage[cadence.TimerTaskCollectionEntry](ctx,
+		db.collection(cadence.TimerTaskCollectionName),
+		timerTaskRangeFilter(shardID, inclusiveMinTime, exclusiveMaxTime),
+		[]sortKey{{field: "visibilitytimestamp"}, {field: "taskid"}},
+		pageSize,
+		pageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks := make([]*nosqlplugin.TimerTask, 0, len(docs))
+	for _, doc := range docs {
+		task := &nosqlplugin.TimerTask{}
+		if err := decodeData(doc.Data, doc.DataEncoding, task); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
 }
 
 func (db *mdb) DeleteTimerTask(ctx context.Context, shardID int, taskID int64, visibilityTimestamp time.Time) error {
-	panic("TODO")
+	_, err := db.collection(cadence.TimerTaskCollectionName).DeleteOne(ctx,
+		bson.D{{"shardid", shardID}, {"visibilitytimestamp", visibilityTimestamp.UnixNano()}, {"taskid", taskID}},
+	)
+	return err
 }
 
 func (db *mdb) RangeDeleteTimerTasks(ctx context.Context, shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) error {
-	panic("TODO")
+	_, err := db.collection(cadence.TimerTaskCollectionName).DeleteMany(ctx, timerTaskRangeFilter(shardID, inclusiveMinTime, exclusiveMaxTime))
+	return err
 }
 
 func (db *mdb) SelectReplicationTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.ReplicationTask](ctx, db, cadence.ReplicationTaskCollectionName, shardTaskFilter(shardID, ""), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *mdb) DeleteReplicationTask(ctx context.Context, shardID int, taskID int64) error {
-	panic("TODO")
+	return db.deleteTask(ctx, cadence.ReplicationTaskCollectionName, shardTaskFilter(shardID, ""), taskID)
 }
 
 func (db *mdb) RangeDeleteReplicationTasks(ctx context.Context, shardID int, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	_, err := db.collection(cadence.ReplicationTaskCollectionName).DeleteMany(ctx,
+		append(shardTaskFilter(shardID, ""), bson.E{Key: "taskid", Value: bson.D{{"$lte", inclusiveEndTaskID}}}),
+	)
+	return err
 }
 
 func (db *mdb) InsertReplicationTask(ctx context.Context, tasks []*nosqlplugin.ReplicationTask, condition nosqlplugin.ShardCondition) error {
-	panic("TODO")
+	if len(tasks) == 0 {
+		return nil
+	}
+	t := newWorkflowTransaction(&condition)
+	if err := db.addTasks(t, condition.ShardID, nil, nil, tasks, nil); err != nil {
+		return err
+	}
+	err := t.execute(ctx, db)
+	if isConditionFailed(err, shardRangeOperation) {
+		var shard cadence.ShardCollectionEntry
+		err := db.collection(cadence.ShardCollectionName).FindOne(ctx, bson.D{{"_id", condition.ShardID}}).Decode(&shard)
+		if err != nil && !db.IsNotFoundError(err) {
+			return err
+		}
+		return &nosqlplugin.ShardOperationConditionFailure{
+			RangeID: shard.RangeID,
+		}
+	}
+	return err
 }
 
 func (db *mdb) SelectCrossClusterTasksOrderByTaskID(ctx context.Context, shardID, pageSize int, pageToken []byte, targetCluster string, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.CrossClusterTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.CrossClusterTask](ctx, db, cadence.CrossClusterTaskCollectionName, shardTaskFilter(shardID, targetCluster), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *mdb) DeleteCrossClusterTask(ctx context.Context, shardID int, targetCluster string, taskID int64) error {
-	panic("TODO")
+	return db.deleteTask(ctx, cadence.CrossClusterTaskCollectionName, shardTaskFilter(shardID, targetCluster), taskID)
 }
 
 func (db *mdb) RangeDeleteCrossClusterTasks(ctx context.Context, shardID int, targetCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasks(ctx, cadence.CrossClusterTaskCollectionName, shardTaskFilter(shardID, targetCluster), exclusiveBeginTaskID, inclusiveEndTaskID)
 }
 
 func (db *mdb) InsertReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, task nosqlplugin.ReplicationTask) error {
-	panic("TODO")
+	doc, err := newShardTaskDocument(shardID, sourceCluster, task.TaskID, &task)
+	if err != nil {
+		return err
+	}
+	_, err = db.collection(cadence.ReplicationDLQTaskCollectionName).InsertOne(ctx, doc)
+	return err
 }
 
 func (db *mdb) SelectReplicationDLQTasksOrderByTaskID(ctx context.Context, shardID int, sourceCluster string, pageSize int, pageToken []byte, exclusiveMinTaskID, inclusiveMaxTaskID int64) ([]*nosqlplugin.ReplicationTask, []byte, error) {
-	panic("TODO")
+	return selectTasksByTaskID[nosqlplugin.ReplicationTask](ctx, db, cadence.ReplicationDLQTaskCollectionName, shardTaskFilter(shardID, sourceCluster), pageSize, pageToken, exclusiveMinTaskID, inclusiveMaxTaskID)
 }
 
 func (db *mdb) SelectReplicationDLQTasksCount(ctx context.Context, shardID int, sourceCluster string) (int64, error) {
-	panic("TODO")
+	return db.collection(cadence.ReplicationDLQTaskCollectionName).CountDocuments(ctx, shardTaskFilter(shardID, sourceCluster))
 }
 
 func (db *mdb) DeleteReplicationDLQTask(ctx context.Context, shardID int, sourceCluster string, taskID int64) error {
-	panic("TODO")
+	return db.deleteTask(ctx, cadence.ReplicationDLQTaskCollectionName, shardTaskFilter(shardID, sourceCluster), taskID)
 }
 
 func (db *mdb) RangeDeleteReplicationDLQTasks(ctx context.Context, shardID int, sourceCluster string, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
-	panic("TODO")
+	return db.rangeDeleteTasks(ctx, cadence.ReplicationDLQTaskCollectionName, shardTaskFilter(shardID, sourceCluster), exclusiveBeginTaskID, inclusiveEndTaskID)
+}
+
+// selectTasksByTaskID reads a page of tasks in (exclusiveMinTaskID, inclusiveMaxTaskID]
+func selectTasksByTaskID[T any](
+	ctx context.Context,
+	db *mdb,
+	collection string,
+	filter bson.D,
+	pageSize int,
+	pageToken []byte,
+	exclusiveMinTaskID, inclusiveMaxTaskID int64,
+) ([]*T, []byte, error) {
+	docs, nextPageToken, err := findPage[cadence.ShardTaskCollectionEntry](ctx,
+		db.collection(collection),
+		append(filter, taskIDRangeFilter(exclusiveMinTaskID, inclusiveMaxTaskID)),
+		[]sortKey{{field: "taskid"}},
+		pageSize,
+		pageToken,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks := make([]*T, 0, len(docs))
+	for _, doc := range docs {
+		task := new(T)
+		if err := decodeData(doc.Data, doc.DataEncoding, task); err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextPageToken, nil
+}
+
+func (db *mdb) deleteTask(ctx context.Context, collection string, filter bson.D, taskID int64) error {
+	_, err := db.collection(collection).DeleteOne(ctx, append(filter, bson.E{Key: "taskid", Value: taskID}))
+	return err
+}
+
+func (db *mdb) rangeDeleteTasks(ctx context.Context, collection string, filter bson.D, exclusiveBeginTaskID, inclusiveEndTaskID int64) error {
+	_, err := db.collection(collection).DeleteMany(ctx, append(filter, taskIDRangeFilter(exclusiveBeginTaskID, inclusiveEndTaskID)))
+	return err
+}
+
+// shardTaskFilter returns the filter of the tasks of a shard, cluster is empty for transfer and replication tasks
+func shardTaskFilter(shardID int, cluster string) bson.D {
+	filter := bson.D{{"shardid", shardID}}
+	if cluster != "" {
+		filter = append(filter, bson.E{Key: "cluster", Value: cluster})
+	}
+	return filter
+}
+
+// taskIDRangeFilter returns the filter of tasks in (exclusiveMinTaskID, inclusiveMaxTaskID]
+func taskIDRangeFilter(exclusiveMinTaskID, inclusiveMaxTaskID int64) bson.E {
+	return bson.E{Key: "taskid", Value: bson.D{{"$gt", exclusiveMinTaskID}, {"$lte", inclusiveMaxTaskID}}}
+}
+
+// timerTaskRangeFilter returns the filter of timer tasks in [inclusiveMinTime, exclusiveMaxTime)
+func timerTaskRangeFilter(shardID int, inclusiveMinTime, exclusiveMaxTime time.Time) bson.D {
+	return bson.D{
+		{"shardid", shardID},
+		{"visibilitytimestamp", bson.D{{"$gte", inclusiveMinTime.UnixNano()}, {"$lt", exclusiveMaxTime.UnixNano()}}},
+	}
 }
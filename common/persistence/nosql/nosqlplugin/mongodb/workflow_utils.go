@@ -0,0 +1,844 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/checksum"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/nosql/nosqlplugin"
+	"github.com/uber/cadence/schema/mongodb/cadence"
+)
+
+const (
+	// workflowRequestTTLInSeconds is the TTL of workflow requests, which is used for deduplication
+	workflowRequestTTLInSeconds = 10800
+)
+
+// below are the operations of a workflow transaction, which are used to figure out the reason
+// when the transaction is aborted by a condition failure
+const (
+	shardRangeOperation        = "shard_range"
+	workflowRequestOperation   = "workflow_request"
+	currentWorkflowOperation   = "current_workflow"
+	mutatedExecutionOperation  = "mutated_execution"
+	insertedExecutionOperation = "inserted_execution"
+	resetExecutionOperation    = "reset_execution"
+)
+
+type (
+	// workflowExecutionData is the data field of the workflow execution document
+	workflowExecutionData struct {
+		ExecutionInfo    *persistence.InternalWorkflowExecutionInfo
+		VersionHistories *persistence.DataBlob
+		Checksum         *checksum.Checksum
+		LastWriteVersion int64
+	}
+
+	// workflowTransaction is a list of writes which are executed in a transaction after checking the shard range ID
+	workflowTransaction struct {
+		shardCondition *nosqlplugin.ShardCondition
+		writes         []func(ctx mongo.SessionContext) error
+	}
+)
+
+func newWorkflowTransaction(shardCondition *nosqlplugin.ShardCondition) *workflowTransaction {
+	return &workflowTransaction{
+		shardCondition: shardCondition,
+	}
+}
+
+func (t *workflowTransaction) add(write func(ctx mongo.SessionContext) error) {
+	t.writes = append(t.writes, write)
+}
+
+// execute runs all the writes in a transaction.
+// Return conditionFailedError if the condition of any write doesn't meet, none of the writes are applied in that case
+func (t *workflowTransaction) execute(ctx context.Context, db *mdb) error {
+	return db.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		// writing the range_id of the shard makes the transaction conflict with any concurrent update of the shard
+		result, err := db.collection(cadence.ShardCollectionName).UpdateOne(sessCtx,
+			bson.D{{"_id", t.shardCondition.ShardID}, {"rangeid", t.shardCondition.RangeID}},
+			bson.D{{"$set", bson.D{{"rangeid", t.shardCondition.RangeID}}}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return &conditionFailedError{operation: shardRangeOperation}
+		}
+		for _, write := range t.writes {
+			if err := write(sessCtx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *mdb) addWorkflowRequests(
+	t *workflowTransaction,
+	requests *nosqlplugin.WorkflowRequestsWriteRequest,
+) error {
+	if requests == nil {
+		return nil
+	}
+	switch requests.WriteMode {
+	case nosqlplugin.WorkflowRequestWriteModeInsert, nosqlplugin.WorkflowRequestWriteModeUpsert:
+	default:
+		return fmt.Errorf("unknown workflow request write mode %v", requests.WriteMode)
+	}
+
+	for _, row := range requests.Rows {
+		doc := &cadence.WorkflowRequestCollectionEntry{
+			ShardID:     row.ShardID,
+			DomainID:    row.DomainID,
+			WorkflowID:  row.WorkflowID,
+			RequestType: int(row.RequestType),
+			RequestID:   row.RequestID,
+			RunID:       row.RunID,
+			Version:     row.Version,
+			ExpireTime:  *expireTime(workflowRequestTTLInSeconds),
+		}
+		if requests.WriteMode == nosqlplugin.WorkflowRequestWriteModeInsert {
+			t.add(func(ctx mongo.SessionContext) error {
+				_, err := db.collection(cadence.WorkflowRequestCollectionName).InsertOne(ctx, doc)
+				if mongo.IsDuplicateKeyError(err) {
+					return &conditionFailedError{operation: workflowRequestOperation}
+				}
+				return err
+			})
+		} else {
+			// the latest write always wins
+			t.add(func(ctx mongo.SessionContext) error {
+				_, err := db.collection(cadence.WorkflowRequestCollectionName).ReplaceOne(ctx,
+					workflowRequestFilter(doc.ShardID, doc.DomainID, doc.WorkflowID, doc.RequestType, doc.RequestID),
+					doc,
+					options.Replace().SetUpsert(true),
+				)
+				return err
+			})
+		}
+	}
+	return nil
+}
+
+func (db *mdb) addCurrentWorkflow(
+	t *workflowTransaction,
+	shardID int,
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+) error {
+	row := request.Row
+	row.ShardID = shardID
+	data, encoding, err := encodeData(&row)
+	if err != nil {
+		return err
+	}
+	doc := &cadence.CurrentWorkflowCollectionEntry{
+		ShardID:          shardID,
+		DomainID:         row.DomainID,
+		WorkflowID:       row.WorkflowID,
+		CurrentRunID:     row.RunID,
+		LastWriteVersion: row.LastWriteVersion,
+		WorkflowState:    row.State,
+		Data:             data,
+		DataEncoding:     encoding,
+	}
+
+	switch request.WriteMode {
+	case nosqlplugin.CurrentWorkflowWriteModeNoop:
+	case nosqlplugin.CurrentWorkflowWriteModeInsert:
+		t.add(func(ctx mongo.SessionContext) error {
+			_, err := db.collection(cadence.CurrentWorkflowCollectionName).InsertOne(ctx, doc)
+			if mongo.IsDuplicateKeyError(err) {
+				return &conditionFailedError{operation: currentWorkflowOperation}
+			}
+			return err
+		})
+	case nosqlplugin.CurrentWorkflowWriteModeUpdate:
+		if request.Condition == nil || request.Condition.GetCurrentRunID() == "" {
+			return fmt.Errorf("CurrentWorkflowWriteModeUpdate require Condition.CurrentRunID")
+		}
+		filter := append(currentWorkflowFilter(shardID, row.DomainID, row.WorkflowID),
+			bson.E{Key: "currentrunid", Value: *request.Condition.CurrentRunID},
+		)
+		if request.Condition.LastWriteVersion != nil && request.Condition.State != nil {
+			filter = append(filter,
+				bson.E{Key: "lastwriteversion", Value: *request.Condition.LastWriteVersion},
+				bson.E{Key: "workflowstate", Value: *request.Condition.State},
+			)
+		}
+		t.add(func(ctx mongo.SessionContext) error {
+			result, err := db.collection(cadence.CurrentWorkflowCollectionName).ReplaceOne(ctx, filter, doc)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return &conditionFailedError{operation: currentWorkflowOperation}
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown mode %v", request.WriteMode)
+	}
+	return nil
+}
+
+// addInsertedExecution inserts a new workflow execution
+func (db *mdb) addInsertedExecution(
+	t *workflowTransaction,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeCreate {
+		return fmt.Errorf("MapsWriteMode %v is not supported for inserted execution", execution.MapsWriteMode)
+	}
+	doc, err := newWorkflowExecutionDocument(shardID, execution)
+	if err != nil {
+		return err
+	}
+	t.add(func(ctx mongo.SessionContext) error {
+		_, err := db.collection(cadence.WorkflowExecutionCollectionName).InsertOne(ctx, doc)
+		if mongo.IsDuplicateKeyError(err) {
+			return &conditionFailedError{operation: insertedExecutionOperation}
+		}
+		return err
+	})
+	return nil
+}
+
+// addResetExecution replaces the whole workflow execution
+func (db *mdb) addResetExecution(
+	t *workflowTransaction,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeReset {
+		return fmt.Errorf("MapsWriteMode %v is not supported for reset execution", execution.MapsWriteMode)
+	}
+	if execution.EventBufferWriteMode != nosqlplugin.EventBufferWriteModeClear {
+		return fmt.Errorf("EventBufferWriteMode %v is not supported for reset execution", execution.EventBufferWriteMode)
+	}
+	if execution.PreviousNextEventIDCondition == nil {
+		return fmt.Errorf("PreviousNextEventIDCondition is required for reset execution")
+	}
+	doc, err := newWorkflowExecutionDocument(shardID, execution)
+	if err != nil {
+		return err
+	}
+	filter := append(workflowExecutionFilter(shardID, execution.DomainID, execution.WorkflowID, execution.RunID),
+		bson.E{Key: "nexteventid", Value: *execution.PreviousNextEventIDCondition},
+	)
+	t.add(func(ctx mongo.SessionContext) error {
+		result, err := db.collection(cadence.WorkflowExecutionCollectionName).ReplaceOne(ctx, filter, doc)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return &conditionFailedError{operation: resetExecutionOperation}
+		}
+		return nil
+	})
+	return nil
+}
+
+// addMutatedExecution updates the execution info and the changed entries of the maps of an existing workflow execution
+func (db *mdb) addMutatedExecution(
+	t *workflowTransaction,
+	shardID int,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+) error {
+	if execution.MapsWriteMode != nosqlplugin.WorkflowExecutionMapsWriteModeUpdate {
+		return fmt.Errorf("MapsWriteMode %v is not supported for mutated execution", execution.MapsWriteMode)
+	}
+	if execution.PreviousNextEventIDCondition == nil {
+		return fmt.Errorf("PreviousNextEventIDCondition is required for mutated execution")
+	}
+
+	data, err := newWorkflowExecutionData(execution)
+	if err != nil {
+		return err
+	}
+	sets := bson.D{
+		{"data", data},
+		{"dataencoding", dataEncodingJSON},
+		{"nexteventid", execution.NextEventID},
+	}
+	var unsets bson.D
+	var push bson.D
+
+	if sets, err = setMapEntries(sets, "activityinfos", execution.ActivityInfos); err != nil {
+		return err
+	}
+	if sets, err = setMapEntries(sets, "timerinfos", execution.TimerInfos); err != nil {
+		return err
+	}
+	if sets, err = setMapEntries(sets, "childexecutioninfos", execution.ChildWorkflowInfos); err != nil {
+		return err
+	}
+	if sets, err = setMapEntries(sets, "requestcancelinfos", execution.RequestCancelInfos); err != nil {
+		return err
+	}
+	if sets, err = setMapEntries(sets, "signalinfos", execution.SignalInfos); err != nil {
+		return err
+	}
+	for _, id := range execution.SignalRequestedIDs {
+		sets = append(sets, bson.E{Key: "signalrequestedids." + encodeMapKey(id), Value: true})
+	}
+
+	unsets = unsetMapEntries(unsets, "activityinfos", execution.ActivityInfoKeysToDelete)
+	unsets = unsetMapEntries(unsets, "timerinfos", execution.TimerInfoKeysToDelete)
+	unsets = unsetMapEntries(unsets, "childexecutioninfos", execution.ChildWorkflowInfoKeysToDelete)
+	unsets = unsetMapEntries(unsets, "requestcancelinfos", execution.RequestCancelInfoKeysToDelete)
+	unsets = unsetMapEntries(unsets, "signalinfos", execution.SignalInfoKeysToDelete)
+	unsets = unsetMapEntries(unsets, "signalrequestedids", execution.SignalRequestedIDsKeysToDelete)
+
+	switch execution.EventBufferWriteMode {
+	case nosqlplugin.EventBufferWriteModeNone:
+	case nosqlplugin.EventBufferWriteModeAppend:
+		if execution.NewBufferedEventBatch == nil {
+			return fmt.Errorf("NewBufferedEventBatch is required for EventBufferWriteModeAppend")
+		}
+		push = bson.D{{"bufferedevents", toEventBlob(execution.NewBufferedEventBatch)}}
+	case nosqlplugin.EventBufferWriteModeClear:
+		sets = append(sets, bson.E{Key: "bufferedevents", Value: bson.A{}})
+	default:
+		return fmt.Errorf("unknown EventBufferWriteMode %v", execution.EventBufferWriteMode)
+	}
+
+	update := bson.D{{"$set", sets}}
+	if len(unsets) > 0 {
+		update = append(update, bson.E{Key: "$unset", Value: unsets})
+	}
+	if len(push) > 0 {
+		update = append(update, bson.E{Key: "$push", Value: push})
+	}
+	filter := append(workflowExecutionFilter(shardID, execution.DomainID, execution.WorkflowID, execution.RunID),
+		bson.E{Key: "nexteventid", Value: *execution.PreviousNextEventIDCondition},
+	)
+	t.add(func(ctx mongo.SessionContext) error {
+		result, err := db.collection(cadence.WorkflowExecutionCollectionName).UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return &conditionFailedError{operation: mutatedExecutionOperation}
+		}
+		return nil
+	})
+	return nil
+}
+
+func (db *mdb) addTasks(
+	t *workflowTransaction,
+	shardID int,
+	transferTasks []*nosqlplugin.TransferTask,
+	crossClusterTasks []*nosqlplugin.CrossClusterTask,
+	replicationTasks []*nosqlplugin.ReplicationTask,
+	timerTasks []*nosqlplugin.TimerTask,
+) error {
+	var transferDocs, crossClusterDocs, replicationDocs, timerDocs []interface{}
+	for _, task := range transferTasks {
+		doc, err := newShardTaskDocument(shardID, "", task.TaskID, task)
+		if err != nil {
+			return err
+		}
+		transferDocs = append(transferDocs, doc)
+	}
+	for _, task := range crossClusterTasks {
+		doc, err := newShardTaskDocument(shardID, task.TargetCluster, task.TaskID, task)
+		if err != nil {
+			return err
+		}
+		crossClusterDocs = append(crossClusterDocs, doc)
+	}
+	for _, task := range replicationTasks {
+		doc, err := newShardTaskDocument(shardID, "", task.TaskID, task)
+		if err != nil {
+			return err
+		}
+		replicationDocs = append(replicationDocs, doc)
+	}
+	for _, task := range timerTasks {
+		data, encoding, err := encodeData(task)
+		if err != nil {
+			return err
+		}
+		timerDocs = append(timerDocs, &cadence.TimerTaskCollectionEntry{
+			ShardID:             shardID,
+			VisibilityTimestamp: task.VisibilityTimestamp.UnixNano(),
+			TaskID:              task.TaskID,
+			Data:                data,
+			DataEncoding:        encoding,
+		})
+	}
+
+	for collection, docs := range map[string][]interface{}{
+		cadence.TransferTaskCollectionName:     transferDocs,
+		cadence.CrossClusterTaskCollectionName: crossClusterDocs,
+		cadence.ReplicationTaskCollectionName:  replicationDocs,
+		cadence.TimerTaskCollectionName:        timerDocs,
+	} {
+		if len(docs) == 0 {
+			continue
+		}
+		collection, docs := collection, docs
+		t.add(func(ctx mongo.SessionContext) error {
+			_, err := db.collection(collection).InsertMany(ctx, docs)
+			return err
+		})
+	}
+	return nil
+}
+
+func newShardTaskDocument(shardID int, cluster string, taskID int64, task interface{}) (*cadence.ShardTaskCollectionEntry, error) {
+	data, encoding, err := encodeData(task)
+	if err != nil {
+		return nil, err
+	}
+	return &cadence.ShardTaskCollectionEntry{
+		ShardID:      shardID,
+		Cluster:      cluster,
+		TaskID:       taskID,
+		Data:         data,
+		DataEncoding: encoding,
+	}, nil
+}
+
+func newWorkflowExecutionData(execution *nosqlplugin.WorkflowExecutionRequest) ([]byte, error) {
+	info := execution.InternalWorkflowExecutionInfo
+	data, _, err := encodeData(&workflowExecutionData{
+		ExecutionInfo:    &info,
+		VersionHistories: execution.VersionHistories,
+		Checksum:         execution.Checksums,
+		LastWriteVersion: execution.LastWriteVersion,
+	})
+	return data, err
+}
+
+func newWorkflowExecutionDocument(shardID int, execution *nosqlplugin.WorkflowExecutionRequest) (*cadence.WorkflowExecutionCollectionEntry, error) {
+	data, err := newWorkflowExecutionData(execution)
+	if err != nil {
+		return nil, err
+	}
+	doc := &cadence.WorkflowExecutionCollectionEntry{
+		ShardID:            shardID,
+		DomainID:           execution.DomainID,
+		WorkflowID:         execution.WorkflowID,
+		RunID:              execution.RunID,
+		NextEventID:        execution.NextEventID,
+		Data:               data,
+		DataEncoding:       dataEncodingJSON,
+		SignalRequestedIDs: make(map[string]bool, len(execution.SignalRequestedIDs)),
+		BufferedEvents:     []cadence.EventBlob{},
+	}
+	if doc.ActivityInfos, err = encodeMap(execution.ActivityInfos); err != nil {
+		return nil, err
+	}
+	if doc.TimerInfos, err = encodeMap(execution.TimerInfos); err != nil {
+		return nil, err
+	}
+	if doc.ChildExecutionInfos, err = encodeMap(execution.ChildWorkflowInfos); err != nil {
+		return nil, err
+	}
+	if doc.RequestCancelInfos, err = encodeMap(execution.RequestCancelInfos); err != nil {
+		return nil, err
+	}
+	if doc.SignalInfos, err = encodeMap(execution.SignalInfos); err != nil {
+		return nil, err
+	}
+	for _, id := range execution.SignalRequestedIDs {
+		doc.SignalRequestedIDs[encodeMapKey(id)] = true
+	}
+	return doc, nil
+}
+
+func toWorkflowExecution(doc *cadence.WorkflowExecutionCollectionEntry) (*nosqlplugin.WorkflowExecution, error) {
+	data := &workflowExecutionData{}
+	if err := decodeData(doc.Data, doc.DataEncoding, data); err != nil {
+		return nil, err
+	}
+	state := &nosqlplugin.WorkflowExecution{
+		ExecutionInfo:    data.ExecutionInfo,
+		VersionHistories: data.VersionHistories,
+	}
+	if data.Checksum != nil {
+		state.Checksum = *data.Checksum
+	}
+
+	var err error
+	if state.ActivityInfos, err = decodeInt64Map[persistence.InternalActivityInfo](doc.ActivityInfos); err != nil {
+		return nil, err
+	}
+	if state.TimerInfos, err = decodeStringMap[persistence.TimerInfo](doc.TimerInfos); err != nil {
+		return nil, err
+	}
+	if state.ChildExecutionInfos, err = decodeInt64Map[persistence.InternalChildExecutionInfo](doc.ChildExecutionInfos); err != nil {
+		return nil, err
+	}
+	if state.RequestCancelInfos, err = decodeInt64Map[persistence.RequestCancelInfo](doc.RequestCancelInfos); err != nil {
+		return nil, err
+	}
+	if state.SignalInfos, err = decodeInt64Map[persistence.SignalInfo](doc.SignalInfos); err != nil {
+		return nil, err
+	}
+	state.SignalRequestedIDs = make(map[string]struct{}, len(doc.SignalRequestedIDs))
+	for key := range doc.SignalRequestedIDs {
+		id, err := decodeMapKey(key)
+		if err != nil {
+			return nil, err
+		}
+		state.SignalRequestedIDs[id] = struct{}{}
+	}
+	state.BufferedEvents = make([]*persistence.DataBlob, 0, len(doc.BufferedEvents))
+	for _, blob := range doc.BufferedEvents {
+		state.BufferedEvents = append(state.BufferedEvents, persistence.NewDataBlob(blob.Data, common.EncodingType(blob.Encoding)))
+	}
+	return state, nil
+}
+
+func toEventBlob(blob *persistence.DataBlob) cadence.EventBlob {
+	return cadence.EventBlob{
+		Data:     blob.Data,
+		Encoding: string(blob.GetEncoding()),
+	}
+}
+
+// fieldKey converts a map key to a field name, int64 keys are decimal strings and string keys are encoded
+func fieldKey[K comparable](key K) string {
+	if s, ok := any(key).(string); ok {
+		return encodeMapKey(s)
+	}
+	return fmt.Sprint(key)
+}
+
+// encodeMap encodes the values of a map into JSON, the keys are converted to field names
+func encodeMap[K comparable, V any](m map[K]V) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(m))
+	for k, v := range m {
+		data, _, err := encodeData(v)
+		if err != nil {
+			return nil, err
+		}
+		result[fieldKey(k)] = data
+	}
+	return result, nil
+}
+
+func decodeInt64Map[V any](m map[string][]byte) (map[int64]*V, error) {
+	result := make(map[int64]*V, len(m))
+	for k, data := range m {
+		key, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v := new(V)
+		if err := decodeData(data, dataEncodingJSON, v); err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+func decodeStringMap[V any](m map[string][]byte) (map[string]*V, error) {
+	result := make(map[string]*V, len(m))
+	for k, data := range m {
+		key, err := decodeMapKey(k)
+		if err != nil {
+			return nil, err
+		}
+		v := new(V)
+		if err := decodeData(data, dataEncodingJSON, v); err != nil {
+			return nil, err
+		}
+		result[key] = v
+	}
+	return result, nil
+}
+
+func setMapEntries[K comparable, V any](sets bson.D, field string, m map[K]V) (bson.D, error) {
+	encoded, err := encodeMap(m)
+	if err != nil {
+		return nil, err
+	}
+	for k, data := range encoded {
+		sets = append(sets, bson.E{Key: field + "." + k, Value: data})
+	}
+	return sets, nil
+}
+
+func unsetMapEntries[K comparable](unsets bson.D, field string, keys []K) bson.D {
+	for _, k := range keys {
+		unsets = append(unsets, bson.E{Key: field + "." + fieldKey(k), Value: ""})
+	}
+	return unsets
+}
+
+func currentWorkflowFilter(shardID int, domainID, workflowID string) bson.D {
+	return bson.D{{"shardid", shardID}, {"domainid", domainID}, {"workflowid", workflowID}}
+}
+
+func workflowExecutionFilter(shardID int, domainID, workflowID, runID string) bson.D {
+	return bson.D{{"shardid", shardID}, {"domainid", domainID}, {"workflowid", workflowID}, {"runid", runID}}
+}
+
+func workflowRequestFilter(shardID int, domainID, workflowID string, requestType int, requestID string) bson.D {
+	return bson.D{
+		{"shardid", shardID},
+		{"domainid", domainID},
+		{"workflowid", workflowID},
+		{"requesttype", requestType},
+		{"requestid", requestID},
+	}
+}
+
+// convertCreateWorkflowConditionFailure converts the condition failure of InsertWorkflowExecutionWithTasks
+func (db *mdb) convertCreateWorkflowConditionFailure(
+	ctx context.Context,
+	failure *conditionFailedError,
+	requests *nosqlplugin.WorkflowRequestsWriteRequest,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if err := db.checkShardAndRequestConditionFailure(ctx, failure, requests, shardCondition); err != nil {
+		return err
+	}
+
+	switch failure.operation {
+	case currentWorkflowOperation:
+		current, err := db.selectCurrentWorkflowDocument(ctx, shardCondition.ShardID, currentWorkflowRequest.Row.DomainID, currentWorkflowRequest.Row.WorkflowID)
+		if err != nil {
+			return err
+		}
+		if currentWorkflowRequest.WriteMode == nosqlplugin.CurrentWorkflowWriteModeInsert {
+			// CreateWorkflowExecution failed because there is already a current execution record for this workflow
+			if current == nil {
+				msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v", currentWorkflowRequest.Row.WorkflowID)
+				return &nosqlplugin.WorkflowOperationConditionFailure{
+					CurrentWorkflowConditionFailInfo: &msg,
+				}
+			}
+			row := &nosqlplugin.CurrentWorkflowRow{}
+			if err := decodeData(current.Data, current.DataEncoding, row); err != nil {
+				return err
+			}
+			msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v", currentWorkflowRequest.Row.WorkflowID, current.CurrentRunID)
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+					OtherInfo:        msg,
+					CreateRequestID:  row.CreateRequestID,
+					RunID:            current.CurrentRunID,
+					State:            row.State,
+					CloseStatus:      row.CloseStatus,
+					LastWriteVersion: current.LastWriteVersion,
+				},
+			}
+		}
+		return newCurrentWorkflowConditionFailure(currentWorkflowRequest, current, "Workflow execution creation condition failed")
+	case insertedExecutionOperation:
+		actualLastWriteVersion := int64(common.EmptyVersion)
+		existing, err := db.selectWorkflowExecutionDocument(ctx, shardCondition.ShardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			data := &workflowExecutionData{}
+			if err := decodeData(existing.Data, existing.DataEncoding, data); err != nil {
+				return err
+			}
+			actualLastWriteVersion = data.LastWriteVersion
+		}
+		msg := fmt.Sprintf("Workflow execution already running. WorkflowId: %v, RunId: %v", execution.WorkflowID, execution.RunID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			WorkflowExecutionAlreadyExists: &nosqlplugin.WorkflowExecutionAlreadyExists{
+				OtherInfo:        msg,
+				CreateRequestID:  execution.CreateRequestID,
+				RunID:            execution.RunID,
+				State:            execution.State,
+				CloseStatus:      execution.CloseStatus,
+				LastWriteVersion: actualLastWriteVersion,
+			},
+		}
+	}
+
+	msg := fmt.Sprintf("Failed to operate on workflow execution.  Request RangeID: %v, failed operation: (%v)",
+		shardCondition.RangeID, failure.Error())
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		UnknownConditionFailureDetails: &msg,
+	}
+}
+
+// convertUpdateWorkflowConditionFailure converts the condition failure of UpdateWorkflowExecutionWithTasks
+func (db *mdb) convertUpdateWorkflowConditionFailure(
+	ctx context.Context,
+	failure *conditionFailedError,
+	requests *nosqlplugin.WorkflowRequestsWriteRequest,
+	currentWorkflowRequest *nosqlplugin.CurrentWorkflowWriteRequest,
+	execution *nosqlplugin.WorkflowExecutionRequest,
+	previousNextEventIDCondition int64,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	if err := db.checkShardAndRequestConditionFailure(ctx, failure, requests, shardCondition); err != nil {
+		return err
+	}
+
+	switch failure.operation {
+	case currentWorkflowOperation:
+		current, err := db.selectCurrentWorkflowDocument(ctx, shardCondition.ShardID, currentWorkflowRequest.Row.DomainID, currentWorkflowRequest.Row.WorkflowID)
+		if err != nil {
+			return err
+		}
+		return newCurrentWorkflowConditionFailure(currentWorkflowRequest, current, "Failed to update mutable state")
+	case mutatedExecutionOperation, resetExecutionOperation:
+		actualNextEventID := int64(0)
+		existing, err := db.selectWorkflowExecutionDocument(ctx, shardCondition.ShardID, execution.DomainID, execution.WorkflowID, execution.RunID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			actualNextEventID = existing.NextEventID
+		}
+		msg := fmt.Sprintf("Failed to update mutable state. previousNextEventIDCondition: %v, actualNextEventID: %v, Request Current RunID: %v",
+			previousNextEventIDCondition, actualNextEventID, currentWorkflowRequest.Row.RunID)
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			UnknownConditionFailureDetails: &msg,
+		}
+	}
+
+	msg := fmt.Sprintf("Failed to update mutable state. ShardID: %v, RangeID: %v, previousNextEventIDCondition: %v, requestConditionalRunID: %v, failed operation: (%v)",
+		shardCondition.ShardID, shardCondition.RangeID, previousNextEventIDCondition, currentWorkflowRequest.Condition.GetCurrentRunID(), failure.Error())
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		UnknownConditionFailureDetails: &msg,
+	}
+}
+
+// checkShardAndRequestConditionFailure returns the condition failure if the shard range ID doesn't match
+// or there is a duplicate request, which have the highest priority
+func (db *mdb) checkShardAndRequestConditionFailure(
+	ctx context.Context,
+	failure *conditionFailedError,
+	requests *nosqlplugin.WorkflowRequestsWriteRequest,
+	shardCondition *nosqlplugin.ShardCondition,
+) error {
+	switch failure.operation {
+	case shardRangeOperation:
+		var shard cadence.ShardCollectionEntry
+		err := db.collection(cadence.ShardCollectionName).FindOne(ctx, bson.D{{"_id", shardCondition.ShardID}}).Decode(&shard)
+		if err != nil && !db.IsNotFoundError(err) {
+			return err
+		}
+		return &nosqlplugin.WorkflowOperationConditionFailure{
+			ShardRangeIDNotMatch: common.Int64Ptr(shard.RangeID),
+		}
+	case workflowRequestOperation:
+		for _, row := range requests.Rows {
+			var request cadence.WorkflowRequestCollectionEntry
+			err := db.collection(cadence.WorkflowRequestCollectionName).FindOne(ctx,
+				workflowRequestFilter(row.ShardID, row.DomainID, row.WorkflowID, int(row.RequestType), row.RequestID),
+			).Decode(&request)
+			if db.IsNotFoundError(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if request.RunID == "" {
+				return fmt.Errorf("corrupted data detected. DomainID: %v, WorkflowId: %v, RequestID: %v, RequestType: %v", row.DomainID, row.WorkflowID, row.RequestID, row.RequestType)
+			}
+			return &nosqlplugin.WorkflowOperationConditionFailure{
+				DuplicateRequest: &nosqlplugin.DuplicateRequest{
+					RequestType: row.RequestType,
+					RunID:       request.RunID,
+				},
+			}
+		}
+	}
+	return nil
+}
+
+// selectCurrentWorkflowDocument returns nil if the current workflow document doesn't exist
+func (db *mdb) selectCurrentWorkflowDocument(ctx context.Context, shardID int, domainID, workflowID string) (*cadence.CurrentWorkflowCollectionEntry, error) {
+	var doc cadence.CurrentWorkflowCollectionEntry
+	err := db.collection(cadence.CurrentWorkflowCollectionName).FindOne(ctx, currentWorkflowFilter(shardID, domainID, workflowID)).Decode(&doc)
+	if db.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// selectWorkflowExecutionDocument returns nil if the workflow execution document doesn't exist
+func (db *mdb) selectWorkflowExecutionDocument(ctx context.Context, shardID int, domainID, workflowID, runID string) (*cadence.WorkflowExecutionCollectionEntry, error) {
+	var doc cadence.WorkflowExecutionCollectionEntry
+	err := db.collection(cadence.WorkflowExecutionCollectionName).FindOne(ctx, workflowExecutionFilter(shardID, domainID, workflowID, runID)).Decode(&doc)
+	if db.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func newCurrentWorkflowConditionFailure(
+	request *nosqlplugin.CurrentWorkflowWriteRequest,
+	current *cadence.CurrentWorkflowCollectionEntry,
+	operation string,
+) error {
+	actualCurrRunID := ""
+	actualLastWriteVersion := int64(common.EmptyVersion)
+	actualState := 0
+	if current != nil {
+		actualCurrRunID = current.CurrentRunID
+		actualLastWriteVersion = current.LastWriteVersion
+		actualState = current.WorkflowState
+	}
+
+	var msg string
+	switch {
+	case request.Condition.GetCurrentRunID() != actualCurrRunID:
+		msg = fmt.Sprintf("%v by mismatch runID. WorkflowId: %v, Expected Current RunID: %v, Actual Current RunID: %v",
+			operation, request.Row.WorkflowID, request.Condition.GetCurrentRunID(), actualCurrRunID)
+	case request.Condition != nil && request.Condition.LastWriteVersion != nil && *request.Condition.LastWriteVersion != actualLastWriteVersion:
+		msg = fmt.Sprintf("%v. WorkflowId: %v, Expected Version: %v, Actual Version: %v",
+			operation, request.Row.WorkflowID, *request.Condition.LastWriteVersion, actualLastWriteVersion)
+	case request.Condition != nil && request.Condition.State != nil && *request.Condition.State != actualState:
+		msg = fmt.Sprintf("%v. WorkflowId: %v, Expected State: %v, Actual State: %v",
+			operation, request.Row.WorkflowID, *request.Condition.State, actualState)
+	default:
+		msg = fmt.Sprintf("%v. WorkflowId: %v, Current RunID: %v", operation, request.Row.WorkflowID, actualCurrRunID)
+	}
+	return &nosqlplugin.WorkflowOperationConditionFailure{
+		CurrentWorkflowConditionFailInfo: &msg,
+	}
+}
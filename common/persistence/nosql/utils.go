@@ -21,6 +21,7 @@
 package nosql
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/uber/cadence/common/persistence"
@@ -38,6 +39,12 @@ func (e *ShardingError) Error() string {
 }
 
 func convertCommonErrors(errChecker nosqlplugin.ClientErrorChecker, operation string, err error) error {
+	// plugins which enforce their own size limits return the typed error, so that the callers can handle it
+	var sizeLimitErr *persistence.TransactionSizeLimitError
+	if errors.As(err, &sizeLimitErr) {
+		return sizeLimitErr
+	}
+
 	if errChecker.IsNotFoundError(err) {
 		return &types.EntityNotExistsError{
 			Message: fmt.Sprintf("%v failed. Error: %v ", operation, err),
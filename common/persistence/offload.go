@@ -0,0 +1,436 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/blobstore"
+	"github.com/uber/cadence/common/encryption"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/types"
+)
+
+// When a payload of the events of a history node is offloaded, the events with all their payloads are stored
+// in a blob of the blobstore whose key is derived from the node, and the node keeps the events without the large
+// payloads. The encoding of the node has offloadedEncodingSuffix, so the payload bytes are never inspected to tell
+// whether they are offloaded, and the key of the blob is never read from them.
+const offloadedEncodingSuffix = "+offloaded"
+
+const (
+	offloadedPayloadKeyPrefix = "history_payload"
+	// the blobs of offloaded events have these tags with the encoding of the events,
+	// and with the ID of the key which encrypts them if they are encrypted
+	offloadedPayloadEncodingTag      = "encoding"
+	offloadedPayloadEncryptionKeyTag = "encryption_key_id"
+	// number of history nodes read per page when looking for offloaded payloads
+	offloadedPayloadScanPageSize = 100
+)
+
+// isOffloadedBlob returns true if some payloads of the events of the history node are offloaded to the blobstore
+func isOffloadedBlob(blob *DataBlob) bool {
+	return blob != nil && strings.HasSuffix(string(DecryptedEncoding(blob.Encoding)), offloadedEncodingSuffix)
+}
+
+// offloadedPayloadsKey returns the key of the blob of the events of a history node, the transaction ID is part
+// of the key so that nodes overriding the node don't override its blob
+func offloadedPayloadsKey(treeID string, branchID string, nodeID int64, transactionID int64) string {
+	return fmt.Sprintf("%v_%v_%v_%v_%v", offloadedPayloadKeyPrefix, treeID, branchID, nodeID, transactionID)
+}
+
+// historyEventPayloads returns the payload fields of the event which can be offloaded,
+// memos, search attributes, headers and control fields are always kept in the event
+func historyEventPayloads(event *types.HistoryEvent) []*[]byte {
+	switch {
+	case event.WorkflowExecutionStartedEventAttributes != nil:
+		attr := event.WorkflowExecutionStartedEventAttributes
+		return []*[]byte{&attr.Input, &attr.ContinuedFailureDetails, &attr.LastCompletionResult}
+	case event.WorkflowExecutionCompletedEventAttributes != nil:
+		return []*[]byte{&event.WorkflowExecutionCompletedEventAttributes.Result}
+	case event.WorkflowExecutionFailedEventAttributes != nil:
+		return []*[]byte{&event.WorkflowExecutionFailedEventAttributes.Details}
+	case event.DecisionTaskCompletedEventAttributes != nil:
+		return []*[]byte{&event.DecisionTaskCompletedEventAttributes.ExecutionContext}
+	case event.DecisionTaskFailedEventAttributes != nil:
+		return []*[]byte{&event.DecisionTaskFailedEventAttributes.Details}
+	case event.ActivityTaskScheduledEventAttributes != nil:
+		return []*[]byte{&event.ActivityTaskScheduledEventAttributes.Input}
+	case event.ActivityTaskStartedEventAttributes != nil:
+		return []*[]byte{&event.ActivityTaskStartedEventAttributes.LastFailureDetails}
+	case event.ActivityTaskCompletedEventAttributes != nil:
+		return []*[]byte{&event.ActivityTaskCompletedEventAttributes.Result}
+	case event.ActivityTaskFailedEventAttributes != nil:
+		return []*[]byte{&event.ActivityTaskFailedEventAttributes.Details}
+	case event.ActivityTaskTimedOutEventAttributes != nil:
+		attr := event.ActivityTaskTimedOutEventAttributes
+		return []*[]byte{&attr.Details, &attr.LastFailureDetails}
+	case event.ActivityTaskCanceledEventAttributes != nil:
+		return []*[]byte{&event.ActivityTaskCanceledEventAttributes.Details}
+	case event.MarkerRecordedEventAttributes != nil:
+		return []*[]byte{&event.MarkerRecordedEventAttributes.Details}
+	case event.WorkflowExecutionSignaledEventAttributes != nil:
+		return []*[]byte{&event.WorkflowExecutionSignaledEventAttributes.Input}
+	case event.WorkflowExecutionTerminatedEventAttributes != nil:
+		return []*[]byte{&event.WorkflowExecutionTerminatedEventAttributes.Details}
+	case event.WorkflowExecutionCanceledEventAttributes != nil:
+		return []*[]byte{&event.WorkflowExecutionCanceledEventAttributes.Details}
+	case event.WorkflowExecutionContinuedAsNewEventAttributes != nil:
+		attr := event.WorkflowExecutionContinuedAsNewEventAttributes
+		return []*[]byte{&attr.Input, &attr.FailureDetails, &attr.LastCompletionResult}
+	case event.StartChildWorkflowExecutionInitiatedEventAttributes != nil:
+		return []*[]byte{&event.StartChildWorkflowExecutionInitiatedEventAttributes.Input}
+	case event.ChildWorkflowExecutionCompletedEventAttributes != nil:
+		return []*[]byte{&event.ChildWorkflowExecutionCompletedEventAttributes.Result}
+	case event.ChildWorkflowExecutionFailedEventAttributes != nil:
+		return []*[]byte{&event.ChildWorkflowExecutionFailedEventAttributes.Details}
+	case event.ChildWorkflowExecutionCanceledEventAttributes != nil:
+		return []*[]byte{&event.ChildWorkflowExecutionCanceledEventAttributes.Details}
+	case event.SignalExternalWorkflowExecutionInitiatedEventAttributes != nil:
+		return []*[]byte{&event.SignalExternalWorkflowExecutionInitiatedEventAttributes.Input}
+	}
+	return nil
+}
+
+// offloadPayloads stores the events in the blobstore if some of their payloads are larger than the offload threshold
+// of the domain. It returns the blob of the events without these payloads, with offloadedEncodingSuffix,
+// and the key of the blob in the blobstore. The returned blob is nil if no payload is offloaded.
+// The events of the request are not modified.
+func (m *historyV2ManagerImpl) offloadPayloads(
+	ctx context.Context,
+	request *AppendHistoryNodesRequest,
+	treeID string,
+	branchID string,
+	nodeID int64,
+	blob *DataBlob,
+) (*DataBlob, string, error) {
+
+	if m.blobstoreClient == nil || m.payloadOffloadThreshold == nil {
+		return nil, "", nil
+	}
+	threshold := m.payloadOffloadThreshold(request.DomainName)
+	if threshold <= 0 || !hasPayloadLargerThan(request.Events, threshold) {
+		return nil, "", nil
+	}
+
+	key := offloadedPayloadsKey(treeID, branchID, nodeID, request.TransactionID)
+	tags := map[string]string{
+		"domain":                    request.DomainName,
+		"tree_id":                   treeID,
+		"branch_id":                 branchID,
+		offloadedPayloadEncodingTag: string(blob.Encoding),
+	}
+	body, err := encryptOffloadedPayload(m.keyProvider, blob.Data, request.DomainName, tags)
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = m.blobstoreClient.Put(ctx, &blobstore.PutRequest{
+		Key:  key,
+		Blob: blobstore.Blob{Tags: tags, Body: body},
+	})
+	if err != nil {
+		return nil, "", &types.InternalServiceError{
+			Message: fmt.Sprintf("failed to offload payloads to blobstore: %v", err),
+		}
+	}
+
+	// the events are shared with the caller, so payloads are removed from a copy of them
+	events, err := m.historySerializer.DeserializeBatchEvents(blob)
+	if err != nil {
+		return nil, key, err
+	}
+	for _, event := range events {
+		for _, payload := range historyEventPayloads(event) {
+			if len(*payload) > threshold {
+				*payload = nil
+			}
+		}
+	}
+	offloadedBlob, err := m.historySerializer.SerializeBatchEvents(events, blob.Encoding)
+	if err != nil {
+		return nil, key, err
+	}
+	return offloadedBlob, key, nil
+}
+
+// resolveOffloadedPayloads returns the blobs of the history nodes with the blobs of the events whose payloads are
+// offloaded replaced by the events from the blobstore. The blobs of the events are decrypted and decompressed,
+// the other blobs are returned as they are. The nodes are the nodes of the blobs in the branch.
+func (m *historyV2ManagerImpl) resolveOffloadedPayloads(
+	ctx context.Context,
+	treeID string,
+	branchID string,
+	blobs []*DataBlob,
+	nodes []InternalHistoryNode,
+) ([]*DataBlob, error) {
+
+	var resolved []*DataBlob
+	for i, blob := range blobs {
+		if !isOffloadedBlob(blob) {
+			continue
+		}
+		if len(nodes) != len(blobs) {
+			return nil, &types.InternalServiceError{
+				Message: "history store doesn't return the nodes of the history events",
+			}
+		}
+		if resolved == nil {
+			resolved = append([]*DataBlob{}, blobs...)
+		}
+		key := offloadedPayloadsKey(treeID, branchID, nodes[i].NodeID, nodes[i].TransactionID)
+		offloaded, err := m.getOffloadedPayload(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if offloaded.Tags["tree_id"] != treeID || offloaded.Tags["branch_id"] != branchID {
+			return nil, &types.InternalServiceError{
+				Message: fmt.Sprintf("offloaded payload %v doesn't belong to history branch %v", key, branchID),
+			}
+		}
+		data, err := decryptOffloadedPayload(m.keyProvider, offloaded)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = NewDataBlob(data, common.EncodingType(offloaded.Tags[offloadedPayloadEncodingTag]))
+	}
+	if resolved == nil {
+		return blobs, nil
+	}
+	return resolved, nil
+}
+
+// reencryptOffloadedPayloads re-encrypts the events offloaded by a history node
+// if they are not encrypted with the active key of the domain
+func (m *historyV2ManagerImpl) reencryptOffloadedPayloads(
+	ctx context.Context,
+	key string,
+	domainName string,
+) error {
+
+	blob, err := m.getOffloadedPayload(ctx, key)
+	if err != nil {
+		return err
+	}
+	if blob.Tags[offloadedPayloadEncryptionKeyTag] == activeKeyID(m.keyProvider, domainName) {
+		return nil
+	}
+	payload, err := decryptOffloadedPayload(m.keyProvider, blob)
+	if err != nil {
+		return err
+	}
+	tags := make(map[string]string, len(blob.Tags))
+	for k, v := range blob.Tags {
+		if k != offloadedPayloadEncryptionKeyTag {
+			tags[k] = v
+		}
+	}
+	body, err := encryptOffloadedPayload(m.keyProvider, payload, domainName, tags)
+	if err != nil {
+		return err
+	}
+	_, err = m.blobstoreClient.Put(ctx, &blobstore.PutRequest{
+		Key:  key,
+		Blob: blobstore.Blob{Tags: tags, Body: body},
+	})
+	if err != nil {
+		return &types.InternalServiceError{
+			Message: fmt.Sprintf("failed to re-encrypt offloaded payloads %v: %v", key, err),
+		}
+	}
+	return nil
+}
+
+// getOffloadedPayloadKeysToDelete returns the keys of the payloads offloaded by the history nodes
+// which are deleted with the branch. The nodes are selected the same way as the history stores do.
+// NOTE: payloads of nodes overridden by nodes with a larger transaction ID are not returned
+func (m *historyV2ManagerImpl) getOffloadedPayloadKeysToDelete(
+	ctx context.Context,
+	branch types.HistoryBranch,
+	shardID int,
+) ([]string, error) {
+
+	treeResp, err := m.persistence.GetHistoryTree(ctx, &InternalGetHistoryTreeRequest{
+		TreeID:  branch.TreeID,
+		ShardID: common.IntPtr(shardID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the max node ID referred by the valid branches of each branch range
+	validBRsMaxEndNode := map[string]int64{}
+	for _, b := range treeResp.Branches {
+		for _, br := range b.Ancestors {
+			if curr, ok := validBRsMaxEndNode[br.BranchID]; !ok || curr < br.EndNodeID {
+				validBRsMaxEndNode[br.BranchID] = br.EndNodeID
+			}
+		}
+	}
+
+	beginNodeID := common.FirstEventID
+	if len(branch.Ancestors) > 0 {
+		beginNodeID = branch.Ancestors[len(branch.Ancestors)-1].EndNodeID
+	}
+	brsToDelete := append(append([]*types.HistoryBranchRange{}, branch.Ancestors...), &types.HistoryBranchRange{
+		BranchID:    branch.BranchID,
+		BeginNodeID: beginNodeID,
+	})
+
+	var keys []string
+	for i := len(brsToDelete) - 1; i >= 0; i-- {
+		br := brsToDelete[i]
+		minNodeID, referred := validBRsMaxEndNode[br.BranchID]
+		if !referred {
+			minNodeID = br.BeginNodeID
+		}
+		rangeKeys, err := m.getOffloadedPayloadKeysOfRange(ctx, branch.TreeID, br.BranchID, minNodeID, shardID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, rangeKeys...)
+		if referred {
+			break
+		}
+	}
+	return keys, nil
+}
+
+func (m *historyV2ManagerImpl) getOffloadedPayloadKeysOfRange(
+	ctx context.Context,
+	treeID string,
+	branchID string,
+	minNodeID int64,
+	shardID int,
+) ([]string, error) {
+
+	var keys []string
+	req := &InternalReadHistoryBranchRequest{
+		TreeID:            treeID,
+		BranchID:          branchID,
+		MinNodeID:         minNodeID,
+		MaxNodeID:         common.EndEventID,
+		PageSize:          offloadedPayloadScanPageSize,
+		LastNodeID:        defaultLastNodeID,
+		LastTransactionID: defaultLastTransactionID,
+		ShardID:           shardID,
+	}
+	for {
+		resp, err := m.persistence.ReadHistoryBranch(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for i, blob := range resp.History {
+			if !isOffloadedBlob(blob) {
+				continue
+			}
+			if len(resp.Nodes) != len(resp.History) {
+				return nil, &types.InternalServiceError{
+					Message: "history store doesn't return the nodes of the history events",
+				}
+			}
+			keys = append(keys, offloadedPayloadsKey(treeID, branchID, resp.Nodes[i].NodeID, resp.Nodes[i].TransactionID))
+		}
+		if len(resp.NextPageToken) == 0 {
+			return keys, nil
+		}
+		req.NextPageToken = resp.NextPageToken
+		req.LastNodeID = resp.LastNodeID
+		req.LastTransactionID = resp.LastTransactionID
+	}
+}
+
+// deleteOffloadedPayloads deletes offloaded payloads on a best effort basis,
+// the history nodes referencing them are already deleted or were never written
+func (m *historyV2ManagerImpl) deleteOffloadedPayloads(
+	ctx context.Context,
+	keys []string,
+) {
+
+	for _, key := range keys {
+		if _, err := m.blobstoreClient.Delete(ctx, &blobstore.DeleteRequest{Key: key}); err != nil {
+			m.logger.Warn("failed to delete offloaded payload", tag.BlobstoreKey(key), tag.Error(err))
+		}
+	}
+}
+
+func (m *historyV2ManagerImpl) getOffloadedPayload(
+	ctx context.Context,
+	key string,
+) (*blobstore.Blob, error) {
+
+	if m.blobstoreClient == nil {
+		return nil, &types.InternalServiceError{
+			Message: "history event payload is offloaded but no blobstore is configured",
+		}
+	}
+	resp, err := m.blobstoreClient.Get(ctx, &blobstore.GetRequest{Key: key})
+	if err != nil {
+		return nil, &types.InternalServiceError{
+			Message: fmt.Sprintf("failed to get offloaded payload %v from blobstore: %v", key, err),
+		}
+	}
+	return &resp.Blob, nil
+}
+
+// encryptOffloadedPayload encrypts a payload with the active key of the domain before it's offloaded,
+// and records the ID of the key in the tags of its blob
+func encryptOffloadedPayload(
+	keyProvider encryption.KeyProvider,
+	payload []byte,
+	domainName string,
+	tags map[string]string,
+) ([]byte, error) {
+
+	keyID := activeKeyID(keyProvider, domainName)
+	if len(keyID) == 0 {
+		return payload, nil
+	}
+	body, err := encryptPayload(keyProvider, keyID, payload)
+	if err != nil {
+		return nil, err
+	}
+	tags[offloadedPayloadEncryptionKeyTag] = keyID
+	return body, nil
+}
+
+// decryptOffloadedPayload returns the payload of a blob, which is decrypted if its tags record an encryption key
+func decryptOffloadedPayload(
+	keyProvider encryption.KeyProvider,
+	blob *blobstore.Blob,
+) ([]byte, error) {
+
+	if len(blob.Tags[offloadedPayloadEncryptionKeyTag]) == 0 {
+		return blob.Body, nil
+	}
+	return decryptPayload(keyProvider, blob.Body)
+}
+
+func hasPayloadLargerThan(events []*types.HistoryEvent, size int) bool {
+	for _, event := range events {
+		for _, payload := range historyEventPayloads(event) {
+			if len(*payload) > size {
+				return true
+			}
+		}
+	}
+	return false
+}
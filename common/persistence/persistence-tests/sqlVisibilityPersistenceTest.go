@@ -0,0 +1,321 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package persistencetests
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pborman/uuid"
+
+	"github.com/uber/cadence/common/definition"
+	p "github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	// SQLVisibilityPersistenceSuite tests visibility persistence against SQL based visibility,
+	// which additionally supports uninitialized records, search attributes and queries
+	SQLVisibilityPersistenceSuite struct {
+		DBVisibilityPersistenceSuite
+	}
+)
+
+// TestUninitializedVisibility test
+func (s *SQLVisibilityPersistenceSuite) TestUninitializedVisibility() {
+	ctx, cancel := context.WithTimeout(context.Background(), testContextTimeout)
+	defer cancel()
+
+	testDomainUUID := uuid.New()
+	workflowExecution := types.WorkflowExecution{
+		WorkflowID: "visibility-uninitialized-workflow-test",
+		RunID:      uuid.New(),
+	}
+	uninitializedReq := &p.RecordWorkflowExecutionUninitializedRequest{
+		DomainUUID:       testDomainUUID,
+		Execution:        workflowExecution,
+		WorkflowTypeName: "visibility-workflow",
+		UpdateTimestamp:  time.Now().UnixNano(),
+		ShardID:          1234,
+	}
+	s.NoError(s.VisibilityMgr.RecordWorkflowExecutionUninitialized(ctx, uninitializedReq))
+
+	// uninitialized executions are not listed as open
+	startTime := time.Now().Add(time.Second * -5).UnixNano()
+	openResp, err := s.VisibilityMgr.ListOpenWorkflowExecutions(ctx, &p.ListWorkflowExecutionsRequest{
+		DomainUUID:   testDomainUUID,
+		PageSize:     10,
+		EarliestTime: 0,
+		LatestTime:   time.Now().UnixNano(),
+	})
+	s.NoError(err)
+	s.Empty(openResp.Executions)
+
+	resp, err := s.VisibilityMgr.ListWorkflowExecutions(ctx, &p.ListWorkflowExecutionsByQueryRequest{
+		DomainUUID: testDomainUUID,
+		PageSize:   10,
+		Query:      "StartTime = missing",
+	})
+	s.NoError(err)
+	s.Len(resp.Executions, 1)
+	s.Equal(workflowExecution.RunID, resp.Executions[0].Execution.RunID)
+	s.Equal("visibility-workflow", resp.Executions[0].Type.Name)
+	s.assertCount(ctx, testDomainUUID, "StartTime = missing", 1)
+
+	s.NoError(s.VisibilityMgr.DeleteUninitializedWorkflowExecution(ctx, &p.VisibilityDeleteWorkflowExecutionRequest{
+		DomainID: testDomainUUID,
+		RunID:    workflowExecution.RunID,
+	}))
+	s.assertCount(ctx, testDomainUUID, "", 0)
+
+	// the started record replaces the uninitialized one
+	s.NoError(s.VisibilityMgr.RecordWorkflowExecutionUninitialized(ctx, uninitializedReq))
+	startReq := &p.RecordWorkflowExecutionStartedRequest{
+		DomainUUID:       testDomainUUID,
+		Execution:        workflowExecution,
+		WorkflowTypeName: "visibility-workflow",
+		StartTimestamp:   startTime,
+		ShardID:          1234,
+	}
+	s.NoError(s.VisibilityMgr.RecordWorkflowExecutionStarted(ctx, startReq))
+	openResp, err = s.VisibilityMgr.ListOpenWorkflowExecutions(ctx, &p.ListWorkflowExecutionsRequest{
+		DomainUUID:   testDomainUUID,
+		PageSize:     10,
+		EarliestTime: startTime,
+		LatestTime:   startTime,
+	})
+	s.NoError(err)
+	s.Len(openResp.Executions, 1)
+	s.assertOpenExecutionEquals(startReq, openResp.Executions[0])
+	s.assertCount(ctx, testDomainUUID, "StartTime = missing", 0)
+
+	// initialized executions are not deleted
+	s.NoError(s.VisibilityMgr.DeleteUninitializedWorkflowExecution(ctx, &p.VisibilityDeleteWorkflowExecutionRequest{
+		DomainID: testDomainUUID,
+		RunID:    workflowExecution.RunID,
+	}))
+	s.assertCount(ctx, testDomainUUID, "", 1)
+
+	// and are not overwritten by late uninitialized records
+	s.NoError(s.VisibilityMgr.RecordWorkflowExecutionUninitialized(ctx, uninitializedReq))
+	s.assertCount(ctx, testDomainUUID, "StartTime != missing", 1)
+}
+
+// TestUpsertWorkflowExecution test
+func (s *SQLVisibilityPersistenceSuite) TestUpsertWorkflowExecution() {
+	ctx, cancel := context.WithTimeout(context.Background(), testContextTimeout)
+	defer cancel()
+
+	testDomainUUID := uuid.New()
+	workflowExecution := types.WorkflowExecution{
+		WorkflowID: "visibility-upsert-workflow-test",
+		RunID:      uuid.New(),
+	}
+	startTime := time.Now().Add(time.Second * -5).UnixNano()
+	s.NoError(s.VisibilityMgr.RecordWorkflowExecutionStarted(ctx, &p.RecordWorkflowExecutionStartedRequest{
+		DomainUUID:       testDomainUUID,
+		Execution:        workflowExecution,
+		WorkflowTypeName: "visibility-workflow",
+		StartTimestamp:   startTime,
+		SearchAttributes: map[string][]byte{
+			definition.CustomKeywordField: []byte(`"before"`),
+		},
+		ShardID: 1234,
+	}))
+	s.assertCount(ctx, testDomainUUID, "CustomKeywordField = 'before'", 1)
+
+	upsertReq := &p.UpsertWorkflowExecutionRequest{
+		DomainUUID:       testDomainUUID,
+		Execution:        workflowExecution,
+		WorkflowTypeName: "visibility-workflow",
+		StartTimestamp:   startTime,
+		UpdateTimestamp:  time.Now().UnixNano(),
+		SearchAttributes: map[string][]byte{
+			definition.CustomKeywordField: []byte(`"after"`),
+			definition.CustomIntField:     []byte(`5`),
+		},
+		ShardID: 1234,
+	}
+	s.NoError(s.VisibilityMgr.UpsertWorkflowExecution(ctx, upsertReq))
+	s.assertCount(ctx, testDomainUUID, "CustomKeywordField = 'before'", 0)
+	s.assertCount(ctx, testDomainUUID, "CustomKeywordField = 'after' and CustomIntField >= 5", 1)
+
+	resp, err := s.VisibilityMgr.ListWorkflowExecutions(ctx, &p.ListWorkflowExecutionsByQueryRequest{
+		DomainUUID: testDomainUUID,
+		PageSize:   10,
+		Query:      "CustomIntField = 5",
+	})
+	s.NoError(err)
+	s.Len(resp.Executions, 1)
+	s.Equal(workflowExecution.RunID, resp.Executions[0].Execution.RunID)
+	s.Equal([]byte(`"after"`), resp.Executions[0].SearchAttributes.IndexedFields[definition.CustomKeywordField])
+	s.Equal([]byte(`5`), resp.Executions[0].SearchAttributes.IndexedFields[definition.CustomIntField])
+
+	// upserts of closed executions are ignored
+	s.NoError(s.VisibilityMgr.RecordWorkflowExecutionClosed(ctx, &p.RecordWorkflowExecutionClosedRequest{
+		DomainUUID:       testDomainUUID,
+		Execution:        workflowExecution,
+		WorkflowTypeName: "visibility-workflow",
+		StartTimestamp:   startTime,
+		CloseTimestamp:   time.Now().UnixNano(),
+		Status:           types.WorkflowExecutionCloseStatusCompleted,
+		HistoryLength:    5,
+		SearchAttributes: upsertReq.SearchAttributes,
+		ShardID:          1234,
+	}))
+	upsertReq.SearchAttributes = map[string][]byte{definition.CustomKeywordField: []byte(`"late"`)}
+	s.NoError(s.VisibilityMgr.UpsertWorkflowExecution(ctx, upsertReq))
+	s.assertCount(ctx, testDomainUUID, "CustomKeywordField = 'late'", 0)
+	s.assertCount(ctx, testDomainUUID, "CustomKeywordField = 'after' and CloseStatus = 'COMPLETED'", 1)
+
+	// upserts create the row if the started record is missing
+	workflowExecution.RunID = uuid.New()
+	upsertReq.Execution = workflowExecution
+	s.NoError(s.VisibilityMgr.UpsertWorkflowExecution(ctx, upsertReq))
+	s.assertCount(ctx, testDomainUUID, "CustomKeywordField = 'late' and CloseTime = missing", 1)
+}
+
+// TestListWorkflowExecutionsByQuery test
+func (s *SQLVisibilityPersistenceSuite) TestListWorkflowExecutionsByQuery() {
+	ctx, cancel := context.WithTimeout(context.Background(), testContextTimeout)
+	defer cancel()
+
+	testDomainUUID := uuid.New()
+	startTime := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	runIDs := make([]string, 4)
+	for i := range runIDs {
+		runIDs[i] = uuid.New()
+		startReq := &p.RecordWorkflowExecutionStartedRequest{
+			DomainUUID:       testDomainUUID,
+			Execution:        types.WorkflowExecution{WorkflowID: fmt.Sprintf("visibility-query-workflow-%d", i), RunID: runIDs[i]},
+			WorkflowTypeName: fmt.Sprintf("visibility-workflow-%d", i%2),
+			StartTimestamp:   startTime.Add(time.Duration(i) * time.Minute).UnixNano(),
+			SearchAttributes: map[string][]byte{
+				definition.CustomKeywordField: []byte(fmt.Sprintf(`"keyword-%d"`, i)),
+				definition.CustomIntField:     []byte(fmt.Sprintf("%d", i)),
+				definition.CustomBoolField:    []byte(fmt.Sprintf("%t", i%2 == 0)),
+				definition.BinaryChecksums:    []byte(fmt.Sprintf(`["checksum-%d", "checksum-%d"]`, i, i+1)),
+			},
+			ShardID: 1234,
+		}
+		s.NoError(s.VisibilityMgr.RecordWorkflowExecutionStarted(ctx, startReq))
+		if i < 2 {
+			s.NoError(s.VisibilityMgr.RecordWorkflowExecutionClosed(ctx, &p.RecordWorkflowExecutionClosedRequest{
+				DomainUUID:       testDomainUUID,
+				Execution:        startReq.Execution,
+				WorkflowTypeName: startReq.WorkflowTypeName,
+				StartTimestamp:   startReq.StartTimestamp,
+				CloseTimestamp:   time.Now().UnixNano(),
+				Status:           types.WorkflowExecutionCloseStatus(i),
+				HistoryLength:    int64(10 + i),
+				SearchAttributes: startReq.SearchAttributes,
+				ShardID:          1234,
+			}))
+		}
+	}
+
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{runIDs[3], runIDs[2], runIDs[1], runIDs[0]}},
+		{"order by StartTime asc", []string{runIDs[0], runIDs[1], runIDs[2], runIDs[3]}},
+		{"WorkflowType = 'visibility-workflow-1'", []string{runIDs[3], runIDs[1]}},
+		{"WorkflowID = 'visibility-query-workflow-2'", []string{runIDs[2]}},
+		{"CloseTime = missing", []string{runIDs[3], runIDs[2]}},
+		{"CloseTime != missing order by StartTime", []string{runIDs[0], runIDs[1]}},
+		{"CloseStatus = 'FAILED'", []string{runIDs[1]}},
+		{"CloseStatus = 0 or HistoryLength > 10", []string{runIDs[1], runIDs[0]}},
+		{fmt.Sprintf("StartTime >= %d", startTime.Add(2*time.Minute).UnixNano()), []string{runIDs[3], runIDs[2]}},
+		{"CustomKeywordField in ('keyword-0', 'keyword-3')", []string{runIDs[3], runIDs[0]}},
+		{"CustomKeywordField not in ('keyword-0', 'keyword-3')", []string{runIDs[2], runIDs[1]}},
+		{"`Attr.CustomKeywordField` = 'keyword-2'", []string{runIDs[2]}},
+		{"CustomKeywordField != 'keyword-2' and CustomIntField < 2", []string{runIDs[1], runIDs[0]}},
+		{"CustomIntField between 1 and 2", []string{runIDs[2], runIDs[1]}},
+		{"CustomBoolField = true", []string{runIDs[2], runIDs[0]}},
+		{"BinaryChecksums = 'checksum-2'", []string{runIDs[2], runIDs[1]}},
+		{"CustomStringField = missing and CustomIntField > 2", []string{runIDs[3]}},
+		{"(CustomIntField = 1 or CustomIntField = 3) and CloseTime = missing", []string{runIDs[3]}},
+		{"order by CustomIntField desc", []string{runIDs[3], runIDs[2], runIDs[1], runIDs[0]}},
+	}
+	for _, tc := range testCases {
+		resp, err := s.VisibilityMgr.ListWorkflowExecutions(ctx, &p.ListWorkflowExecutionsByQueryRequest{
+			DomainUUID: testDomainUUID,
+			PageSize:   10,
+			Query:      tc.query,
+		})
+		s.NoError(err, tc.query)
+		var actual []string
+		for _, execution := range resp.Executions {
+			actual = append(actual, execution.Execution.RunID)
+		}
+		s.Equal(tc.expected, actual, tc.query)
+		s.Nil(resp.NextPageToken, tc.query)
+		s.assertCount(ctx, testDomainUUID, tc.query, int64(len(tc.expected)))
+	}
+
+	// pagination
+	var token []byte
+	var actual []string
+	for {
+		resp, err := s.VisibilityMgr.ScanWorkflowExecutions(ctx, &p.ListWorkflowExecutionsByQueryRequest{
+			DomainUUID:    testDomainUUID,
+			PageSize:      3,
+			NextPageToken: token,
+			Query:         "order by StartTime",
+		})
+		s.NoError(err)
+		for _, execution := range resp.Executions {
+			actual = append(actual, execution.Execution.RunID)
+		}
+		if token = resp.NextPageToken; len(token) == 0 {
+			break
+		}
+	}
+	s.Equal(runIDs, actual)
+
+	for _, query := range []string{
+		"CustomKeywordField = ",
+		"CustomIntField > missing",
+		"StartTime = 'yesterday'",
+		"WorkflowID = 1",
+		"CustomKeywordField is null",
+	} {
+		_, err := s.VisibilityMgr.ListWorkflowExecutions(ctx, &p.ListWorkflowExecutionsByQueryRequest{
+			DomainUUID: testDomainUUID,
+			PageSize:   10,
+			Query:      query,
+		})
+		s.IsType(&types.BadRequestError{}, err, query)
+	}
+}
+
+func (s *SQLVisibilityPersistenceSuite) assertCount(ctx context.Context, domainID string, query string, expected int64) {
+	resp, err := s.VisibilityMgr.CountWorkflowExecutions(ctx, &p.CountWorkflowExecutionsRequest{
+		DomainUUID: domainID,
+		Query:      query,
+	})
+	s.NoError(err, query)
+	s.Equal(expected, resp.Count, query)
+}
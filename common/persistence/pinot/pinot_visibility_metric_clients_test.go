@@ -117,7 +117,7 @@ func TestMetricClientRecordWorkflowExecutionStarted(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -201,7 +201,7 @@ func TestMetricClientRecordWorkflowExecutionClosed(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -275,7 +275,7 @@ func TestMetricClientRecordWorkflowExecutionUninitialized(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -349,7 +349,7 @@ func TestMetricClientUpsertWorkflowExecution(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -421,7 +421,7 @@ func TestMetricClientListOpenWorkflowExecutions(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -493,7 +493,7 @@ func TestMetricClientListClosedWorkflowExecutions(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -566,7 +566,7 @@ func TestMetricClientListOpenWorkflowExecutionsByType(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -639,7 +639,7 @@ func TestMetricClientListClosedWorkflowExecutionsByType(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -711,7 +711,7 @@ func TestMetricClientListOpenWorkflowExecutionsByWorkflowID(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -783,7 +783,7 @@ func TestMetricClientListClosedWorkflowExecutionsByWorkflowID(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -859,7 +859,7 @@ func TestMetricClientListClosedWorkflowExecutionsByStatus(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -933,7 +933,7 @@ func TestMetricClientGetClosedWorkflowExecution(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1003,7 +1003,7 @@ func TestMetricClientListWorkflowExecutions(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1069,7 +1069,7 @@ func TestMetricClientScanWorkflowExecutions(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1135,7 +1135,7 @@ func TestMetricClientCountWorkflowExecutions(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1209,7 +1209,7 @@ func TestMetricClientDeleteWorkflowExecution(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1279,7 +1279,7 @@ func TestMetricClientDeleteUninitializedWorkflowExecution(t *testing.T) {
 				ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 			}, mockProducer, testlogger.New(t))
 			visibilityStore := mgr.(*pinotVisibilityStore)
-			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+			pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 			visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 			metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1309,7 +1309,7 @@ func TestMetricClientClose(t *testing.T) {
 		ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 	}, mockProducer, testlogger.New(t))
 	visibilityStore := mgr.(*pinotVisibilityStore)
-	pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+	pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 	visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 	metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
@@ -1333,7 +1333,7 @@ func TestMetricClientGetName(t *testing.T) {
 		ESIndexMaxResultWindow: dynamicconfig.GetIntPropertyFn(3),
 	}, mockProducer, testlogger.New(t))
 	visibilityStore := mgr.(*pinotVisibilityStore)
-	pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger)
+	pinotVisibilityManager := p.NewVisibilityManagerImpl(visibilityStore, logger, nil)
 	visibilityMgr := NewPinotVisibilityMetricsClient(pinotVisibilityManager, mockMetricClient, logger)
 	metricsClient := visibilityMgr.(*pinotVisibilityMetricsClient)
 
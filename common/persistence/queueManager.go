@@ -49,6 +49,10 @@ func (q *queueManager) EnqueueMessage(ctx context.Context, messagePayload []byte
 	return q.persistence.EnqueueMessage(ctx, messagePayload)
 }
 
+func (q *queueManager) EnqueueMessages(ctx context.Context, messagePayloads [][]byte) error {
+	return q.persistence.EnqueueMessages(ctx, messagePayloads)
+}
+
 func (q *queueManager) ReadMessages(ctx context.Context, lastMessageID int64, maxCount int) (QueueMessageList, error) {
 	resp, err := q.persistence.ReadMessages(ctx, lastMessageID, maxCount)
 	if err != nil {
@@ -69,6 +73,10 @@ func (q *queueManager) UpdateAckLevel(ctx context.Context, messageID int64, clus
 	return q.persistence.UpdateAckLevel(ctx, messageID, clusterName)
 }
 
+func (q *queueManager) DeleteAckLevel(ctx context.Context, clusterName string) error {
+	return q.persistence.DeleteAckLevel(ctx, clusterName)
+}
+
 func (q *queueManager) GetAckLevels(ctx context.Context) (map[string]int64, error) {
 	return q.persistence.GetAckLevels(ctx)
 }
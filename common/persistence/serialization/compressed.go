@@ -0,0 +1,259 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package serialization
+
+import (
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/persistence"
+)
+
+type (
+	// compressedEncoder compresses the blobs of an encoder with the compression of a compressed encoding type
+	compressedEncoder struct {
+		encoder  encoder
+		encoding common.EncodingType
+	}
+
+	// compressedDecoder decompresses the blobs of a compressed encoding type before they are decoded by a decoder
+	compressedDecoder struct {
+		decoder  decoder
+		encoding common.EncodingType
+	}
+)
+
+func newCompressedEncoder(encoder encoder, encoding common.EncodingType) encoder {
+	return &compressedEncoder{
+		encoder:  encoder,
+		encoding: encoding,
+	}
+}
+
+func newCompressedDecoder(decoder decoder, encoding common.EncodingType) decoder {
+	return &compressedDecoder{
+		decoder:  decoder,
+		encoding: encoding,
+	}
+}
+
+func (e *compressedEncoder) shardInfoToBlob(info *ShardInfo) ([]byte, error) {
+	return e.compress(e.encoder.shardInfoToBlob(info))
+}
+
+func (e *compressedEncoder) domainInfoToBlob(info *DomainInfo) ([]byte, error) {
+	return e.compress(e.encoder.domainInfoToBlob(info))
+}
+
+func (e *compressedEncoder) historyTreeInfoToBlob(info *HistoryTreeInfo) ([]byte, error) {
+	return e.compress(e.encoder.historyTreeInfoToBlob(info))
+}
+
+func (e *compressedEncoder) workflowExecutionInfoToBlob(info *WorkflowExecutionInfo) ([]byte, error) {
+	return e.compress(e.encoder.workflowExecutionInfoToBlob(info))
+}
+
+func (e *compressedEncoder) activityInfoToBlob(info *ActivityInfo) ([]byte, error) {
+	return e.compress(e.encoder.activityInfoToBlob(info))
+}
+
+func (e *compressedEncoder) childExecutionInfoToBlob(info *ChildExecutionInfo) ([]byte, error) {
+	return e.compress(e.encoder.childExecutionInfoToBlob(info))
+}
+
+func (e *compressedEncoder) signalInfoToBlob(info *SignalInfo) ([]byte, error) {
+	return e.compress(e.encoder.signalInfoToBlob(info))
+}
+
+func (e *compressedEncoder) requestCancelInfoToBlob(info *RequestCancelInfo) ([]byte, error) {
+	return e.compress(e.encoder.requestCancelInfoToBlob(info))
+}
+
+func (e *compressedEncoder) timerInfoToBlob(info *TimerInfo) ([]byte, error) {
+	return e.compress(e.encoder.timerInfoToBlob(info))
+}
+
+func (e *compressedEncoder) taskInfoToBlob(info *TaskInfo) ([]byte, error) {
+	return e.compress(e.encoder.taskInfoToBlob(info))
+}
+
+func (e *compressedEncoder) taskListInfoToBlob(info *TaskListInfo) ([]byte, error) {
+	return e.compress(e.encoder.taskListInfoToBlob(info))
+}
+
+func (e *compressedEncoder) transferTaskInfoToBlob(info *TransferTaskInfo) ([]byte, error) {
+	return e.compress(e.encoder.transferTaskInfoToBlob(info))
+}
+
+func (e *compressedEncoder) crossClusterTaskInfoToBlob(info *CrossClusterTaskInfo) ([]byte, error) {
+	return e.compress(e.encoder.crossClusterTaskInfoToBlob(info))
+}
+
+func (e *compressedEncoder) timerTaskInfoToBlob(info *TimerTaskInfo) ([]byte, error) {
+	return e.compress(e.encoder.timerTaskInfoToBlob(info))
+}
+
+func (e *compressedEncoder) replicationTaskInfoToBlob(info *ReplicationTaskInfo) ([]byte, error) {
+	return e.compress(e.encoder.replicationTaskInfoToBlob(info))
+}
+
+func (e *compressedEncoder) encodingType() common.EncodingType {
+	return e.encoding
+}
+
+func (e *compressedEncoder) compress(data []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	blob, err := persistence.CompressDataBlob(persistence.NewDataBlob(data, e.encoder.encodingType()), e.encoding)
+	if err != nil {
+		return nil, err
+	}
+	return blob.Data, nil
+}
+
+func (d *compressedDecoder) shardInfoFromBlob(data []byte) (*ShardInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.shardInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) domainInfoFromBlob(data []byte) (*DomainInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.domainInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) historyTreeInfoFromBlob(data []byte) (*HistoryTreeInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.historyTreeInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) workflowExecutionInfoFromBlob(data []byte) (*WorkflowExecutionInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.workflowExecutionInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) activityInfoFromBlob(data []byte) (*ActivityInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.activityInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) childExecutionInfoFromBlob(data []byte) (*ChildExecutionInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.childExecutionInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) signalInfoFromBlob(data []byte) (*SignalInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.signalInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) requestCancelInfoFromBlob(data []byte) (*RequestCancelInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.requestCancelInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) timerInfoFromBlob(data []byte) (*TimerInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.timerInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) taskInfoFromBlob(data []byte) (*TaskInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.taskInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) taskListInfoFromBlob(data []byte) (*TaskListInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.taskListInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) transferTaskInfoFromBlob(data []byte) (*TransferTaskInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.transferTaskInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) crossClusterTaskInfoFromBlob(data []byte) (*CrossClusterTaskInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.crossClusterTaskInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) timerTaskInfoFromBlob(data []byte) (*TimerTaskInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.timerTaskInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) replicationTaskInfoFromBlob(data []byte) (*ReplicationTaskInfo, error) {
+	data, err := d.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	return d.decoder.replicationTaskInfoFromBlob(data)
+}
+
+func (d *compressedDecoder) decompress(data []byte) ([]byte, error) {
+	blob, err := persistence.DecompressDataBlob(persistence.NewDataBlob(data, d.encoding))
+	if err != nil {
+		return nil, err
+	}
+	return blob.Data, nil
+}
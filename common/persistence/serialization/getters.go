@@ -1428,6 +1428,22 @@ func (t *TaskListInfo) GetLastUpdated() time.Time {
 	return time.Unix(0, 0)
 }
 
+// GetAdaptiveReadPartitions internal sql blob getter
+func (t *TaskListInfo) GetAdaptiveReadPartitions() (o int32) {
+	if t != nil {
+		return t.AdaptiveReadPartitions
+	}
+	return
+}
+
+// GetAdaptiveWritePartitions internal sql blob getter
+func (t *TaskListInfo) GetAdaptiveWritePartitions() (o int32) {
+	if t != nil {
+		return t.AdaptiveWritePartitions
+	}
+	return
+}
+
 // GetDomainID internal sql blob getter
 func (t *TransferTaskInfo) GetDomainID() (o []byte) {
 	if t != nil {
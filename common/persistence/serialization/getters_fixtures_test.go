@@ -124,10 +124,12 @@ var expectedNil = map[string]map[string]any{
 		"GetWorkflowID":              "",
 	},
 	"*serialization.TaskListInfo": {
-		"GetAckLevel":        int64(0),
-		"GetExpiryTimestamp": zeroUnix,
-		"GetKind":            int16(0),
-		"GetLastUpdated":     zeroUnix,
+		"GetAckLevel":                int64(0),
+		"GetAdaptiveReadPartitions":  int32(0),
+		"GetAdaptiveWritePartitions": int32(0),
+		"GetExpiryTimestamp":         zeroUnix,
+		"GetKind":                    int16(0),
+		"GetLastUpdated":             zeroUnix,
 	},
 	"*serialization.TaskInfo": {
 		"GetCreatedTimestamp": zeroUnix,
@@ -349,10 +351,12 @@ var expectedEmpty = map[string]map[string]any{
 		"GetWorkflowID":              "",
 	},
 	"*serialization.TaskListInfo": {
-		"GetAckLevel":        int64(0),
-		"GetExpiryTimestamp": time.Time{},
-		"GetKind":            int16(0),
-		"GetLastUpdated":     time.Time{},
+		"GetAckLevel":                int64(0),
+		"GetAdaptiveReadPartitions":  int32(0),
+		"GetAdaptiveWritePartitions": int32(0),
+		"GetExpiryTimestamp":         time.Time{},
+		"GetKind":                    int16(0),
+		"GetLastUpdated":             time.Time{},
 	},
 	"*serialization.TaskInfo": {
 		"GetCreatedTimestamp": time.Time{},
@@ -576,10 +580,12 @@ var expectedNonEmpty = map[string]map[string]any{
 		"GetWorkflowID":              "workflowID",
 	},
 	"*serialization.TaskListInfo": {
-		"GetAckLevel":        int64(2),
-		"GetExpiryTimestamp": taskListInfoExpireTime,
-		"GetKind":            int16(1),
-		"GetLastUpdated":     taskListInfoLastUpdateTime,
+		"GetAckLevel":                int64(2),
+		"GetAdaptiveReadPartitions":  int32(3),
+		"GetAdaptiveWritePartitions": int32(4),
+		"GetExpiryTimestamp":         taskListInfoExpireTime,
+		"GetKind":                    int16(1),
+		"GetLastUpdated":             taskListInfoLastUpdateTime,
 	},
 	"*serialization.TaskInfo": {
 		"GetCreatedTimestamp": taskInfoCreateTime,
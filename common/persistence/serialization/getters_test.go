@@ -174,10 +174,12 @@ func TestGettersForInfos(t *testing.T) {
 			CreationTimestamp:       replicationCreationTimestamp,
 		},
 		&TaskListInfo{
-			Kind:            1,
-			AckLevel:        2,
-			ExpiryTimestamp: taskListInfoExpireTime,
-			LastUpdated:     taskListInfoLastUpdateTime,
+			Kind:                    1,
+			AckLevel:                2,
+			ExpiryTimestamp:         taskListInfoExpireTime,
+			LastUpdated:             taskListInfoLastUpdateTime,
+			AdaptiveReadPartitions:  3,
+			AdaptiveWritePartitions: 4,
 		},
 		&TaskInfo{
 			WorkflowID:       "workflowID",
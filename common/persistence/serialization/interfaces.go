@@ -263,10 +263,12 @@ type (
 
 	// TaskListInfo blob in a serialization agnostic format
 	TaskListInfo struct {
-		Kind            int16
-		AckLevel        int64
-		ExpiryTimestamp time.Time
-		LastUpdated     time.Time
+		Kind                    int16
+		AckLevel                int64
+		ExpiryTimestamp         time.Time
+		LastUpdated             time.Time
+		AdaptiveReadPartitions  int32
+		AdaptiveWritePartitions int32
 	}
 
 	// TransferTaskInfo blob in a serialization agnostic format
@@ -36,14 +36,15 @@ type (
 	}
 )
 
-// NewParser constructs a new parser using encoder as specified by encodingType and using decoders specified by decodingTypes
+// NewParser constructs a new parser using encoder as specified by encodingType and using decoders specified by decodingTypes,
+// the blobs of encodingType are always decoded so that the blobs written by the parser can be read back
 func NewParser(encodingType common.EncodingType, decodingTypes ...common.EncodingType) (Parser, error) {
 	encoder, err := getEncoder(encodingType)
 	if err != nil {
 		return nil, err
 	}
 	decoders := make(map[common.EncodingType]decoder)
-	for _, dt := range decodingTypes {
+	for _, dt := range append(decodingTypes, encodingType) {
 		decoder, err := getDecoder(dt)
 		if err != nil {
 			return nil, err
@@ -353,6 +354,8 @@ func getDecoder(encoding common.EncodingType) (decoder, error) {
 	switch encoding {
 	case common.EncodingTypeThriftRW:
 		return newThriftDecoder(), nil
+	case common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy:
+		return newCompressedDecoder(newThriftDecoder(), encoding), nil
 	default:
 		return nil, unsupportedEncodingError(encoding)
 	}
@@ -362,6 +365,8 @@ func getEncoder(encoding common.EncodingType) (encoder, error) {
 	switch encoding {
 	case common.EncodingTypeThriftRW:
 		return newThriftEncoder(), nil
+	case common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy:
+		return newCompressedEncoder(newThriftEncoder(), encoding), nil
 	default:
 		return nil, unsupportedEncodingError(encoding)
 	}
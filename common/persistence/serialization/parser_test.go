@@ -213,10 +213,12 @@ func TestParserRoundTrip(t *testing.T) {
 			PartitionConfig:  map[string]string{"test_partition_key": "test_partition_value"},
 		},
 		&TaskListInfo{
-			Kind:            1,
-			AckLevel:        2,
-			ExpiryTimestamp: now,
-			LastUpdated:     now,
+			Kind:                    1,
+			AckLevel:                2,
+			ExpiryTimestamp:         now,
+			LastUpdated:             now,
+			AdaptiveReadPartitions:  3,
+			AdaptiveWritePartitions: 4,
 		},
 		&TransferTaskInfo{
 			DomainID:                MustParseUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
@@ -263,6 +265,16 @@ func TestParserRoundTrip(t *testing.T) {
 			blob := parse(t, thriftParser, testCase)
 			result := unparse(t, thriftParser, blob, testCase)
 			assert.Equal(t, testCase, result)
+
+			// compressed blobs round trip too and uncompressed blobs are still read by parsers writing compressed blobs
+			for _, encoding := range []common.EncodingType{common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy} {
+				compressedParser, err := NewParser(encoding, common.EncodingTypeThriftRW)
+				require.NoError(t, err)
+				compressedBlob := parse(t, compressedParser, testCase)
+				assert.Equal(t, encoding, compressedBlob.Encoding)
+				assert.Equal(t, testCase, unparse(t, compressedParser, compressedBlob, testCase))
+				assert.Equal(t, testCase, unparse(t, compressedParser, blob, testCase))
+			}
 		})
 	}
 }
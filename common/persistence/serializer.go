@@ -31,6 +31,7 @@ import (
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/checksum"
 	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/common/types/mapper/thrift"
 )
@@ -107,6 +108,7 @@ type (
 
 	serializerImpl struct {
 		thriftrwEncoder codec.BinaryEncoder
+		keyProvider     encryption.KeyProvider
 	}
 )
 
@@ -117,6 +119,15 @@ func NewPayloadSerializer() PayloadSerializer {
 	}
 }
 
+// NewPayloadSerializerWithKeyProvider returns a PayloadSerializer which deserializes
+// blobs encrypted by EncryptDataBlob with the keys of the key provider
+func NewPayloadSerializerWithKeyProvider(keyProvider encryption.KeyProvider) PayloadSerializer {
+	return &serializerImpl{
+		thriftrwEncoder: codec.NewThriftRWEncoder(),
+		keyProvider:     keyProvider,
+	}
+}
+
 func (t *serializerImpl) SerializeBatchEvents(events []*types.HistoryEvent, encodingType common.EncodingType) (*DataBlob, error) {
 	return t.serialize(events, encodingType)
 }
@@ -338,7 +349,7 @@ func (t *serializerImpl) serialize(input interface{}, encodingType common.Encodi
 	var err error
 
 	switch encodingType {
-	case common.EncodingTypeThriftRW:
+	case common.EncodingTypeThriftRW, common.EncodingTypeThriftRWZstd, common.EncodingTypeThriftRWSnappy:
 		data, err = t.thriftrwEncode(input)
 	case common.EncodingTypeJSON, common.EncodingTypeUnknown, common.EncodingTypeEmpty: // For backward-compatibility
 		encodingType = common.EncodingTypeJSON
@@ -350,7 +361,7 @@ func (t *serializerImpl) serialize(input interface{}, encodingType common.Encodi
 	if err != nil {
 		return nil, NewCadenceSerializationError(err.Error())
 	}
-	return NewDataBlob(data, encodingType), nil
+	return CompressDataBlob(NewDataBlob(data, UncompressedEncoding(encodingType)), encodingType)
 }
 
 func (t *serializerImpl) thriftrwEncode(input interface{}) ([]byte, error) {
@@ -390,7 +401,17 @@ func (t *serializerImpl) deserialize(data *DataBlob, target interface{}) error {
 	if len(data.Data) == 0 {
 		return NewCadenceDeserializationError("DeserializeEvent empty data")
 	}
-	var err error
+	data, err := DecryptDataBlob(t.keyProvider, data)
+	if err != nil {
+		return err
+	}
+	data, err = DecompressDataBlob(data)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return NewCadenceDeserializationError("DeserializeEvent empty data")
+	}
 
 	switch data.GetEncoding() {
 	case common.EncodingTypeThriftRW:
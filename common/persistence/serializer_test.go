@@ -45,11 +45,13 @@ type testDef struct {
 
 // key is encoding type, value is whether the encoding type is supported
 var encodingTypes = map[common.EncodingType]bool{
-	common.EncodingTypeEmpty:    true,
-	common.EncodingTypeUnknown:  true,
-	common.EncodingTypeJSON:     true,
-	common.EncodingTypeThriftRW: true,
-	common.EncodingTypeGob:      false,
+	common.EncodingTypeEmpty:          true,
+	common.EncodingTypeUnknown:        true,
+	common.EncodingTypeJSON:           true,
+	common.EncodingTypeThriftRW:       true,
+	common.EncodingTypeThriftRWZstd:   true,
+	common.EncodingTypeThriftRWSnappy: true,
+	common.EncodingTypeGob:            false,
 }
 
 type runnableTest struct {
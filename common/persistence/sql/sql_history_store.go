@@ -194,6 +194,7 @@ func (m *sqlHistoryStore) ReadHistoryBranch(
 	}
 
 	history := make([]*persistence.DataBlob, 0, int(request.PageSize))
+	nodes := make([]persistence.InternalHistoryNode, 0, int(request.PageSize))
 	eventBlob := &persistence.DataBlob{}
 
 	for _, row := range rows {
@@ -238,6 +239,7 @@ func (m *sqlHistoryStore) ReadHistoryBranch(
 			lastTxnID = *row.TxnID
 			lastNodeID = row.NodeID
 			history = append(history, eventBlob)
+			nodes = append(nodes, persistence.InternalHistoryNode{NodeID: row.NodeID, TransactionID: *row.TxnID})
 			eventBlob = &persistence.DataBlob{}
 		}
 	}
@@ -249,6 +251,7 @@ func (m *sqlHistoryStore) ReadHistoryBranch(
 
 	return &persistence.InternalReadHistoryBranchResponse{
 		History:           history,
+		Nodes:             nodes,
 		NextPageToken:     pagingToken,
 		LastNodeID:        lastNodeID,
 		LastTransactionID: lastTxnID,
@@ -555,3 +558,33 @@ func (m *sqlHistoryStore) GetHistoryTree(
 		Branches: branches,
 	}, nil
 }
+
+// UpdateHistoryNode overrides the events of an existing history node
+func (m *sqlHistoryStore) UpdateHistoryNode(
+	ctx context.Context,
+	request *persistence.InternalUpdateHistoryNodeRequest,
+) error {
+
+	nodeRow := &sqlplugin.HistoryNodeRow{
+		TreeID:       serialization.MustParseUUID(request.TreeID),
+		BranchID:     serialization.MustParseUUID(request.BranchID),
+		NodeID:       request.NodeID,
+		TxnID:        &request.TransactionID,
+		Data:         request.Events.Data,
+		DataEncoding: string(request.Events.Encoding),
+		ShardID:      request.ShardID,
+	}
+
+	result, err := m.db.UpdateHistoryNode(ctx, nodeRow)
+	if err != nil {
+		return convertCommonErrors(m.db, "UpdateHistoryNode", "", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != 1 {
+		return &persistence.ConditionFailedError{Msg: fmt.Sprintf("UpdateHistoryNode: expected 1 row to be affected, got %v", rowsAffected)}
+	}
+	return nil
+}
@@ -871,6 +871,7 @@ func TestReadHistoryBranch(t *testing.T) {
 			},
 			want: &persistence.InternalReadHistoryBranchResponse{
 				History:           []*persistence.DataBlob{{Data: []byte(`b`), Encoding: common.EncodingType("b")}},
+				Nodes:             []persistence.InternalHistoryNode{{NodeID: 202, TransactionID: 101}},
 				NextPageToken:     serializePageToken(202),
 				LastNodeID:        202,
 				LastTransactionID: 101,
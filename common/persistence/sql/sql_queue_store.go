@@ -78,6 +78,35 @@ func (q *sqlQueueStore) EnqueueMessage(
 	})
 }
 
+func (q *sqlQueueStore) EnqueueMessages(
+	ctx context.Context,
+	messagePayloads [][]byte,
+) error {
+	return q.txExecute(ctx, sqlplugin.DbDefaultShard, "EnqueueMessages", func(tx sqlplugin.Tx) error {
+		lastMessageID, err := tx.GetLastEnqueuedMessageIDForUpdate(ctx, q.queueType)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				lastMessageID = -1
+			} else {
+				return err
+			}
+		}
+
+		ackLevels, err := tx.GetAckLevels(ctx, q.queueType, true)
+		if err != nil {
+			return err
+		}
+
+		nextID := getNextID(ackLevels, lastMessageID)
+		for i, messagePayload := range messagePayloads {
+			if _, err := tx.InsertIntoQueue(ctx, newQueueRow(q.queueType, nextID+int64(i), messagePayload)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (q *sqlQueueStore) ReadMessages(
 	ctx context.Context,
 	lastMessageID int64,
@@ -142,6 +171,25 @@ func (q *sqlQueueStore) UpdateAckLevel(
 	})
 }
 
+func (q *sqlQueueStore) DeleteAckLevel(
+	ctx context.Context,
+	clusterName string,
+) error {
+	return q.txExecute(ctx, sqlplugin.DbDefaultShard, "DeleteAckLevel", func(tx sqlplugin.Tx) error {
+		clusterAckLevels, err := tx.GetAckLevels(ctx, q.queueType, true)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := clusterAckLevels[clusterName]; !ok {
+			return nil
+		}
+
+		delete(clusterAckLevels, clusterName)
+		return tx.UpdateAckLevels(ctx, q.queueType, clusterAckLevels)
+	})
+}
+
 func (q *sqlQueueStore) GetAckLevels(
 	ctx context.Context,
 ) (map[string]int64, error) {
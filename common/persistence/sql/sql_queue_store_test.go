@@ -147,6 +147,65 @@ func TestEnqueueMessage(t *testing.T) {
 	}
 }
 
+func TestEnqueueMessages(t *testing.T) {
+	testCases := []struct {
+		name      string
+		queueType persistence.QueueType
+		mockSetup func(*sqlplugin.MockDB, *sqlplugin.MockTx)
+		wantErr   bool
+	}{
+		{
+			name:      "Success case",
+			queueType: persistence.DomainReplicationQueueType,
+			mockSetup: func(mockDB *sqlplugin.MockDB, mockTx *sqlplugin.MockTx) {
+				mockDB.EXPECT().BeginTx(gomock.Any(), sqlplugin.DbDefaultShard).Return(mockTx, nil)
+				mockTx.EXPECT().GetLastEnqueuedMessageIDForUpdate(gomock.Any(), persistence.DomainReplicationQueueType).Return(int64(3), nil)
+				mockTx.EXPECT().GetAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, true).Return(map[string]int64{"a": 5}, nil)
+				gomock.InOrder(
+					mockTx.EXPECT().InsertIntoQueue(gomock.Any(), newQueueRow(persistence.DomainReplicationQueueType, 6, []byte("m1"))).Return(nil, nil),
+					mockTx.EXPECT().InsertIntoQueue(gomock.Any(), newQueueRow(persistence.DomainReplicationQueueType, 7, []byte("m2"))).Return(nil, nil),
+				)
+				mockTx.EXPECT().Commit().Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:      "Error case - failed to insert into queue",
+			queueType: persistence.DomainReplicationQueueType,
+			mockSetup: func(mockDB *sqlplugin.MockDB, mockTx *sqlplugin.MockTx) {
+				mockDB.EXPECT().BeginTx(gomock.Any(), sqlplugin.DbDefaultShard).Return(mockTx, nil)
+				mockTx.EXPECT().GetLastEnqueuedMessageIDForUpdate(gomock.Any(), persistence.DomainReplicationQueueType).Return(int64(0), sql.ErrNoRows)
+				mockTx.EXPECT().GetAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, true).Return(nil, nil)
+				err := errors.New("some error")
+				mockTx.EXPECT().InsertIntoQueue(gomock.Any(), gomock.Any()).Return(nil, err)
+				mockTx.EXPECT().Rollback().Return(nil)
+				mockDB.EXPECT().IsNotFoundError(err).Return(true)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := sqlplugin.NewMockDB(ctrl)
+			mockTx := sqlplugin.NewMockTx(ctrl)
+			store, err := newQueueStore(mockDB, nil, tc.queueType)
+			require.NoError(t, err, "Failed to create sql queue store")
+
+			tc.mockSetup(mockDB, mockTx)
+			err = store.EnqueueMessages(context.Background(), [][]byte{[]byte("m1"), []byte("m2")})
+			if tc.wantErr {
+				assert.Error(t, err, "Expected an error for test case")
+			} else {
+				assert.NoError(t, err, "Did not expect an error for test case")
+			}
+		})
+	}
+}
+
 func TestReadMessages(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -362,6 +421,74 @@ func TestUpdateAckLevel(t *testing.T) {
 	}
 }
 
+func TestDeleteAckLevel(t *testing.T) {
+	testCases := []struct {
+		name        string
+		queueType   persistence.QueueType
+		clusterName string
+		mockSetup   func(*sqlplugin.MockDB, *sqlplugin.MockTx)
+		wantErr     bool
+	}{
+		{
+			name:        "Success case",
+			queueType:   persistence.DomainReplicationQueueType,
+			clusterName: "b",
+			mockSetup: func(mockDB *sqlplugin.MockDB, mockTx *sqlplugin.MockTx) {
+				mockDB.EXPECT().BeginTx(gomock.Any(), sqlplugin.DbDefaultShard).Return(mockTx, nil)
+				mockTx.EXPECT().GetAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, true).Return(map[string]int64{"a": 1, "b": 2}, nil)
+				mockTx.EXPECT().UpdateAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, map[string]int64{"a": 1}).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Success case - no op",
+			queueType:   persistence.DomainReplicationQueueType,
+			clusterName: "b",
+			mockSetup: func(mockDB *sqlplugin.MockDB, mockTx *sqlplugin.MockTx) {
+				mockDB.EXPECT().BeginTx(gomock.Any(), sqlplugin.DbDefaultShard).Return(mockTx, nil)
+				mockTx.EXPECT().GetAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, true).Return(map[string]int64{"a": 1}, nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Error case - failed to update",
+			queueType:   persistence.DomainReplicationQueueType,
+			clusterName: "b",
+			mockSetup: func(mockDB *sqlplugin.MockDB, mockTx *sqlplugin.MockTx) {
+				mockDB.EXPECT().BeginTx(gomock.Any(), sqlplugin.DbDefaultShard).Return(mockTx, nil)
+				mockTx.EXPECT().GetAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, true).Return(map[string]int64{"b": 2}, nil)
+				err := errors.New("some error")
+				mockTx.EXPECT().UpdateAckLevels(gomock.Any(), persistence.DomainReplicationQueueType, map[string]int64{}).Return(err)
+				mockTx.EXPECT().Rollback().Return(nil)
+				mockDB.EXPECT().IsNotFoundError(err).Return(true)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := sqlplugin.NewMockDB(ctrl)
+			mockTx := sqlplugin.NewMockTx(ctrl)
+			store, err := newQueueStore(mockDB, nil, tc.queueType)
+			require.NoError(t, err, "Failed to create sql queue store")
+
+			tc.mockSetup(mockDB, mockTx)
+			err = store.DeleteAckLevel(context.Background(), tc.clusterName)
+			if tc.wantErr {
+				assert.Error(t, err, "Expected an error for test case")
+			} else {
+				assert.NoError(t, err, "Did not expect an error for test case")
+			}
+		})
+	}
+}
+
 func TestGetAckLevels(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -190,13 +190,14 @@ func (m *sqlTaskStore) LeaseTaskList(
 			return fmt.Errorf("%v rows affected instead of 1", rowsAffected)
 		}
 		resp = &persistence.LeaseTaskListResponse{TaskListInfo: &persistence.TaskListInfo{
-			DomainID:    request.DomainID,
-			Name:        request.TaskList,
-			TaskType:    request.TaskType,
-			RangeID:     rangeID + 1,
-			AckLevel:    ackLevel,
-			Kind:        request.TaskListKind,
-			LastUpdated: now,
+			DomainID:                request.DomainID,
+			Name:                    request.TaskList,
+			TaskType:                request.TaskType,
+			RangeID:                 rangeID + 1,
+			AckLevel:                ackLevel,
+			Kind:                    request.TaskListKind,
+			LastUpdated:             now,
+			AdaptivePartitionConfig: toTaskListPartitionConfig(tlInfo),
 		}}
 		return nil
 	})
@@ -218,6 +219,10 @@ func (m *sqlTaskStore) UpdateTaskList(
 	if request.TaskListInfo.Kind == persistence.TaskListKindSticky {
 		tlInfo.ExpiryTimestamp = stickyTaskListExpiry()
 	}
+	if config := request.TaskListInfo.AdaptivePartitionConfig; config != nil {
+		tlInfo.AdaptiveReadPartitions = int32(config.NumReadPartitions)
+		tlInfo.AdaptiveWritePartitions = int32(config.NumWritePartitions)
+	}
 
 	var resp *persistence.UpdateTaskListResponse
 	blob, err := m.parser.TaskListInfoToBlob(tlInfo)
@@ -340,6 +345,7 @@ func (m *sqlTaskStore) ListTaskList(
 		resp.Items[i].AckLevel = info.GetAckLevel()
 		resp.Items[i].Expiry = info.GetExpiryTimestamp()
 		resp.Items[i].LastUpdated = info.GetLastUpdated()
+		resp.Items[i].AdaptivePartitionConfig = toTaskListPartitionConfig(info)
 	}
 
 	return resp, nil
@@ -593,3 +599,13 @@ func lockTaskList(ctx context.Context, tx sqlplugin.Tx, shardID int, domainID se
 func stickyTaskListExpiry() time.Time {
 	return time.Now().Add(stickyTasksListsTTL)
 }
+
+func toTaskListPartitionConfig(info *serialization.TaskListInfo) *persistence.TaskListPartitionConfig {
+	if info.GetAdaptiveWritePartitions() == 0 {
+		return nil
+	}
+	return &persistence.TaskListPartitionConfig{
+		NumReadPartitions:  int(info.GetAdaptiveReadPartitions()),
+		NumWritePartitions: int(info.GetAdaptiveWritePartitions()),
+	}
+}
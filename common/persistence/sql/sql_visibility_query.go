@@ -0,0 +1,337 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xwb1989/sqlparser"
+
+	workflow "github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/definition"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+const (
+	// missingValue is used by visibility queries to match absent fields, e.g. CloseTime = missing
+	missingValue = "missing"
+)
+
+// visibilityColumns maps system search attributes to the columns of executions_visibility table
+var visibilityColumns = map[string]string{
+	definition.DomainID:      "domain_id",
+	definition.WorkflowID:    "workflow_id",
+	definition.RunID:         "run_id",
+	definition.WorkflowType:  "workflow_type_name",
+	definition.StartTime:     "start_time",
+	definition.ExecutionTime: "execution_time",
+	definition.CloseTime:     "close_time",
+	definition.CloseStatus:   "close_status",
+	definition.HistoryLength: "history_length",
+	definition.IsCron:        "is_cron",
+	definition.NumClusters:   "num_clusters",
+	definition.UpdateTime:    "update_time",
+}
+
+// parseVisibilityQuery converts an advanced visibility query, as validated by the frontend,
+// into the condition and ordering used to filter executions_visibility table
+func parseVisibilityQuery(query string) (sqlplugin.VisibilityCondition, []sqlplugin.VisibilityOrderBy, error) {
+	query = strings.TrimSpace(query)
+	if len(query) == 0 {
+		return nil, nil, nil
+	}
+
+	// the placeholder query is only parsed and never executed
+	var placeholderQuery string
+	if common.IsJustOrderByClause(query) {
+		placeholderQuery = fmt.Sprintf("SELECT * FROM dummy %s", query)
+	} else {
+		placeholderQuery = fmt.Sprintf("SELECT * FROM dummy WHERE %s", query)
+	}
+	stmt, err := sqlparser.Parse(placeholderQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, nil, errors.New("invalid select query")
+	}
+
+	var condition sqlplugin.VisibilityCondition
+	if sel.Where != nil {
+		condition, err = convertVisibilityExpr(sel.Where.Expr)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var orderBy []sqlplugin.VisibilityOrderBy
+	for _, order := range sel.OrderBy {
+		colName, ok := order.Expr.(*sqlparser.ColName)
+		if !ok {
+			return nil, nil, errors.New("invalid order by expression")
+		}
+		field, err := convertVisibilityField(colName)
+		if err != nil {
+			return nil, nil, err
+		}
+		orderBy = append(orderBy, sqlplugin.VisibilityOrderBy{
+			Field: field,
+			Desc:  order.Direction == sqlparser.DescScr,
+		})
+	}
+	return condition, orderBy, nil
+}
+
+func convertVisibilityExpr(expr sqlparser.Expr) (sqlplugin.VisibilityCondition, error) {
+	switch expr := expr.(type) {
+	case *sqlparser.AndExpr:
+		left, right, err := convertVisibilityExprs(expr.Left, expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlplugin.VisibilityAndCondition{Left: left, Right: right}, nil
+	case *sqlparser.OrExpr:
+		left, right, err := convertVisibilityExprs(expr.Left, expr.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlplugin.VisibilityOrCondition{Left: left, Right: right}, nil
+	case *sqlparser.ParenExpr:
+		return convertVisibilityExpr(expr.Expr)
+	case *sqlparser.ComparisonExpr:
+		return convertVisibilityComparison(expr)
+	case *sqlparser.RangeCond:
+		return convertVisibilityRange(expr)
+	default:
+		return nil, errors.New("invalid where clause")
+	}
+}
+
+func convertVisibilityExprs(left, right sqlparser.Expr) (sqlplugin.VisibilityCondition, sqlplugin.VisibilityCondition, error) {
+	l, err := convertVisibilityExpr(left)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := convertVisibilityExpr(right)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
+
+func convertVisibilityComparison(expr *sqlparser.ComparisonExpr) (sqlplugin.VisibilityCondition, error) {
+	colName, ok := expr.Left.(*sqlparser.ColName)
+	if !ok {
+		return nil, errors.New("invalid comparison expression")
+	}
+	field, err := convertVisibilityField(colName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.Operator {
+	case sqlparser.InStr, sqlparser.NotInStr:
+		tuple, ok := expr.Right.(sqlparser.ValTuple)
+		if !ok {
+			return nil, errors.New("invalid IN expression")
+		}
+		values := make([]interface{}, 0, len(tuple))
+		for _, val := range tuple {
+			value, err := convertVisibilityValue(field, val)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return &sqlplugin.VisibilityInCondition{
+			Field:   field,
+			Values:  values,
+			Negated: expr.Operator == sqlparser.NotInStr,
+		}, nil
+	case sqlparser.EqualStr, sqlparser.NotEqualStr, sqlparser.LessThanStr, sqlparser.LessEqualStr,
+		sqlparser.GreaterThanStr, sqlparser.GreaterEqualStr, sqlparser.LikeStr, sqlparser.NotLikeStr:
+	default:
+		return nil, fmt.Errorf("operator %q is not supported", expr.Operator)
+	}
+
+	value, err := convertVisibilityValue(field, expr.Right)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil && expr.Operator != sqlparser.EqualStr && expr.Operator != sqlparser.NotEqualStr {
+		return nil, fmt.Errorf("operator %q cannot be used with missing", expr.Operator)
+	}
+	if value == nil && (field.Column == "start_time" || field.Column == "execution_time") {
+		// uninitialized executions are the only rows recorded before the execution started
+		return &sqlplugin.VisibilityComparisonCondition{
+			Field:    sqlplugin.VisibilityField{Column: "is_uninitialized"},
+			Operator: sqlparser.EqualStr,
+			Value:    expr.Operator == sqlparser.EqualStr,
+		}, nil
+	}
+	return &sqlplugin.VisibilityComparisonCondition{
+		Field:    field,
+		Operator: expr.Operator,
+		Value:    value,
+	}, nil
+}
+
+func convertVisibilityRange(expr *sqlparser.RangeCond) (sqlplugin.VisibilityCondition, error) {
+	colName, ok := expr.Left.(*sqlparser.ColName)
+	if !ok {
+		return nil, errors.New("invalid range expression")
+	}
+	field, err := convertVisibilityField(colName)
+	if err != nil {
+		return nil, err
+	}
+	from, err := convertVisibilityValue(field, expr.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := convertVisibilityValue(field, expr.To)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlplugin.VisibilityRangeCondition{
+		Field:   field,
+		From:    from,
+		To:      to,
+		Negated: expr.Operator == sqlparser.NotBetweenStr,
+	}, nil
+}
+
+func convertVisibilityField(colName *sqlparser.ColName) (sqlplugin.VisibilityField, error) {
+	name := colName.Name.String()
+	// the frontend prefixes custom search attributes, which the parser may read as a qualifier
+	if colName.Qualifier.Name.String() == definition.Attr {
+		return sqlplugin.VisibilityField{SearchAttribute: name}, nil
+	}
+	if strings.HasPrefix(name, definition.Attr+".") {
+		return sqlplugin.VisibilityField{SearchAttribute: strings.TrimPrefix(name, definition.Attr+".")}, nil
+	}
+	if column, ok := visibilityColumns[name]; ok {
+		return sqlplugin.VisibilityField{Column: column}, nil
+	}
+	if definition.IsSystemIndexedKey(name) {
+		return sqlplugin.VisibilityField{}, fmt.Errorf("search attribute %s is not supported by SQL visibility", name)
+	}
+	return sqlplugin.VisibilityField{SearchAttribute: name}, nil
+}
+
+// convertVisibilityValue returns nil for missing, a time.Time for time columns and a
+// string, int64, float64 or bool otherwise
+func convertVisibilityValue(field sqlplugin.VisibilityField, expr sqlparser.Expr) (interface{}, error) {
+	var value interface{}
+	switch expr := expr.(type) {
+	case *sqlparser.ColName:
+		// unquoted strings are parsed as column names
+		if expr.Name.String() != missingValue {
+			return nil, fmt.Errorf("invalid value %s", expr.Name.String())
+		}
+		return nil, nil
+	case sqlparser.BoolVal:
+		value = bool(expr)
+	case *sqlparser.SQLVal:
+		var err error
+		switch expr.Type {
+		case sqlparser.StrVal:
+			value = string(expr.Val)
+		case sqlparser.IntVal:
+			value, err = strconv.ParseInt(string(expr.Val), 10, 64)
+		case sqlparser.FloatVal:
+			value, err = strconv.ParseFloat(string(expr.Val), 64)
+		default:
+			return nil, fmt.Errorf("invalid value %s", string(expr.Val))
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("invalid value")
+	}
+
+	switch field.Column {
+	case "":
+		return value, nil
+	case "start_time", "execution_time", "close_time", "update_time":
+		return convertVisibilityTime(value)
+	case "close_status":
+		return convertVisibilityCloseStatus(value)
+	case "history_length", "num_clusters":
+		if _, ok := value.(int64); !ok {
+			return nil, fmt.Errorf("invalid integer value %v", value)
+		}
+		return value, nil
+	case "is_cron":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		default:
+			return nil, fmt.Errorf("invalid bool value %v", value)
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("invalid string value %v", value)
+		}
+		return value, nil
+	}
+}
+
+// times are given either as unix nanoseconds or in RFC3339 format
+func convertVisibilityTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case int64:
+		return time.Unix(0, v), nil
+	case string:
+		if nanos, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(0, nanos), nil
+		}
+		return time.Parse(time.RFC3339Nano, v)
+	default:
+		return time.Time{}, fmt.Errorf("invalid time value %v", value)
+	}
+}
+
+// close status is given either as the stored integer or by name, e.g. 'COMPLETED'
+func convertVisibilityCloseStatus(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case string:
+		var status workflow.WorkflowExecutionCloseStatus
+		if err := status.UnmarshalText([]byte(strings.ToUpper(v))); err != nil {
+			return 0, err
+		}
+		return int64(status), nil
+	default:
+		return 0, fmt.Errorf("invalid close status value %v", value)
+	}
+}
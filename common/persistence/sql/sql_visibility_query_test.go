@@ -0,0 +1,150 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+func TestParseVisibilityQuery(t *testing.T) {
+	startTime := time.Unix(0, 1600000000000000000)
+	tests := []struct {
+		name          string
+		query         string
+		wantCondition sqlplugin.VisibilityCondition
+		wantOrderBy   []sqlplugin.VisibilityOrderBy
+		wantErr       bool
+	}{
+		{
+			name:  "empty query",
+			query: "  ",
+		},
+		{
+			name:  "system and custom search attributes",
+			query: "WorkflowID = 'wid' and `Attr.CustomIntField` > 5",
+			wantCondition: &sqlplugin.VisibilityAndCondition{
+				Left: &sqlplugin.VisibilityComparisonCondition{
+					Field:    sqlplugin.VisibilityField{Column: "workflow_id"},
+					Operator: "=",
+					Value:    "wid",
+				},
+				Right: &sqlplugin.VisibilityComparisonCondition{
+					Field:    sqlplugin.VisibilityField{SearchAttribute: "CustomIntField"},
+					Operator: ">",
+					Value:    int64(5),
+				},
+			},
+		},
+		{
+			name:  "times and close status",
+			query: "StartTime between 1600000000000000000 and '2020-09-13T12:26:40Z' or CloseStatus = 'failed'",
+			wantCondition: &sqlplugin.VisibilityOrCondition{
+				Left: &sqlplugin.VisibilityRangeCondition{
+					Field: sqlplugin.VisibilityField{Column: "start_time"},
+					From:  startTime,
+					To:    time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC),
+				},
+				Right: &sqlplugin.VisibilityComparisonCondition{
+					Field:    sqlplugin.VisibilityField{Column: "close_status"},
+					Operator: "=",
+					Value:    int64(1),
+				},
+			},
+		},
+		{
+			name:  "missing values",
+			query: "CloseTime = missing and StartTime != missing",
+			wantCondition: &sqlplugin.VisibilityAndCondition{
+				Left: &sqlplugin.VisibilityComparisonCondition{
+					Field:    sqlplugin.VisibilityField{Column: "close_time"},
+					Operator: "=",
+				},
+				Right: &sqlplugin.VisibilityComparisonCondition{
+					Field:    sqlplugin.VisibilityField{Column: "is_uninitialized"},
+					Operator: "=",
+					Value:    false,
+				},
+			},
+		},
+		{
+			name:  "in clause",
+			query: "CustomKeywordField not in ('a', 'b')",
+			wantCondition: &sqlplugin.VisibilityInCondition{
+				Field:   sqlplugin.VisibilityField{SearchAttribute: "CustomKeywordField"},
+				Values:  []interface{}{"a", "b"},
+				Negated: true,
+			},
+		},
+		{
+			name:  "order by only",
+			query: "order by CustomDatetimeField desc, WorkflowType",
+			wantOrderBy: []sqlplugin.VisibilityOrderBy{
+				{Field: sqlplugin.VisibilityField{SearchAttribute: "CustomDatetimeField"}, Desc: true},
+				{Field: sqlplugin.VisibilityField{Column: "workflow_type_name"}},
+			},
+		},
+		{
+			name:    "invalid syntax",
+			query:   "WorkflowID = ",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported system search attribute",
+			query:   "TaskList = 'tl'",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value type",
+			query:   "HistoryLength = 'long'",
+			wantErr: true,
+		},
+		{
+			name:    "missing with range operator",
+			query:   "CloseTime > missing",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported expression",
+			query:   "CustomKeywordField is null",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, orderBy, err := parseVisibilityQuery(tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCondition, condition)
+			assert.Equal(t, tt.wantOrderBy, orderBy)
+		})
+	}
+}
@@ -21,6 +21,7 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -31,6 +32,7 @@ import (
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
 	p "github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 	"github.com/uber/cadence/common/types"
@@ -46,6 +48,14 @@ type (
 		Time  time.Time
 		RunID string
 	}
+
+	visibilityQueryPageToken struct {
+		Offset int
+	}
+)
+
+const (
+	defaultVisibilityQueryPageSize = 1000
 )
 
 // NewSQLVisibilityStore creates an instance of ExecutionStore
@@ -66,7 +76,11 @@ func (s *sqlVisibilityStore) RecordWorkflowExecutionStarted(
 	ctx context.Context,
 	request *p.InternalRecordWorkflowExecutionStartedRequest,
 ) error {
-	_, err := s.db.InsertIntoVisibility(ctx, &sqlplugin.VisibilityRow{
+	searchAttributes, err := serializeVisibilitySearchAttributes(request.SearchAttributes)
+	if err != nil {
+		return err
+	}
+	row := &sqlplugin.VisibilityRow{
 		DomainID:         request.DomainUUID,
 		WorkflowID:       request.WorkflowID,
 		RunID:            request.RunID,
@@ -79,18 +93,51 @@ func (s *sqlVisibilityStore) RecordWorkflowExecutionStarted(
 		NumClusters:      request.NumClusters,
 		UpdateTime:       request.UpdateTimestamp,
 		ShardID:          request.ShardID,
-	})
-
-	if err != nil {
-		return convertCommonErrors(s.db, "RecordWorkflowExecutionStarted", "", err)
+		SearchAttributes: searchAttributes,
 	}
-	return nil
+	// the row of an uninitialized execution is replaced in the same transaction, so that a concurrent
+	// upsert can't be lost between the delete and the insert
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(request.DomainUUID, s.db.GetTotalNumDBShards())
+	return s.txExecute(ctx, dbShardID, "RecordWorkflowExecutionStarted", func(tx sqlplugin.Tx) error {
+		result, err := tx.InsertIntoVisibility(ctx, row)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rowsAffected error: %v", err)
+		}
+		if rowsAffected > 0 {
+			return nil
+		}
+
+		// a row already exists, it is replaced only if the execution was recorded as uninitialized
+		result, err = tx.DeleteUninitializedFromVisibility(ctx, &sqlplugin.VisibilityFilter{
+			DomainID: request.DomainUUID,
+			RunID:    &request.RunID,
+		})
+		if err != nil {
+			return err
+		}
+		if rowsAffected, err = result.RowsAffected(); err != nil {
+			return fmt.Errorf("rowsAffected error: %v", err)
+		}
+		if rowsAffected == 0 {
+			return nil
+		}
+		_, err = tx.InsertIntoVisibility(ctx, row)
+		return err
+	})
 }
 
 func (s *sqlVisibilityStore) RecordWorkflowExecutionClosed(
 	ctx context.Context,
 	request *p.InternalRecordWorkflowExecutionClosedRequest,
 ) error {
+	searchAttributes, err := serializeVisibilitySearchAttributes(request.SearchAttributes)
+	if err != nil {
+		return err
+	}
 	closeTime := request.CloseTimestamp
 	result, err := s.db.ReplaceIntoVisibility(ctx, &sqlplugin.VisibilityRow{
 		DomainID:         request.DomainUUID,
@@ -108,6 +155,7 @@ func (s *sqlVisibilityStore) RecordWorkflowExecutionClosed(
 		NumClusters:      request.NumClusters,
 		UpdateTime:       request.UpdateTimestamp,
 		ShardID:          request.ShardID,
+		SearchAttributes: searchAttributes,
 	})
 	if err != nil {
 		return convertCommonErrors(s.db, "RecordWorkflowExecutionClosed", "", err)
@@ -130,18 +178,67 @@ func (s *sqlVisibilityStore) RecordWorkflowExecutionUninitialized(
 	ctx context.Context,
 	request *p.InternalRecordWorkflowExecutionUninitializedRequest,
 ) error {
-	// temporary: not implemented, only implemented for ES
+	// the start time is not known yet, update time is recorded in its place to keep the
+	// row ordered, uninitialized rows are excluded from open workflow listings
+	_, err := s.db.InsertIntoVisibility(ctx, &sqlplugin.VisibilityRow{
+		DomainID:         request.DomainUUID,
+		WorkflowID:       request.WorkflowID,
+		RunID:            request.RunID,
+		StartTime:        request.UpdateTimestamp,
+		ExecutionTime:    request.UpdateTimestamp,
+		WorkflowTypeName: request.WorkflowTypeName,
+		UpdateTime:       request.UpdateTimestamp,
+		ShardID:          int16(request.ShardID),
+		IsUninitialized:  true,
+	})
+	if err != nil {
+		return convertCommonErrors(s.db, "RecordWorkflowExecutionUninitialized", "", err)
+	}
 	return nil
 }
 
 func (s *sqlVisibilityStore) UpsertWorkflowExecution(
-	_ context.Context,
+	ctx context.Context,
 	request *p.InternalUpsertWorkflowExecutionRequest,
 ) error {
-	if p.IsNopUpsertWorkflowRequest(request) {
+	searchAttributes, err := serializeVisibilitySearchAttributes(request.SearchAttributes)
+	if err != nil {
+		return err
+	}
+	row := &sqlplugin.VisibilityRow{
+		DomainID:         request.DomainUUID,
+		WorkflowID:       request.WorkflowID,
+		RunID:            request.RunID,
+		StartTime:        request.StartTimestamp,
+		ExecutionTime:    request.ExecutionTimestamp,
+		WorkflowTypeName: request.WorkflowTypeName,
+		Memo:             request.Memo.Data,
+		Encoding:         string(request.Memo.GetEncoding()),
+		IsCron:           request.IsCron,
+		NumClusters:      request.NumClusters,
+		UpdateTime:       request.UpdateTimestamp,
+		ShardID:          int16(request.ShardID),
+		SearchAttributes: searchAttributes,
+	}
+	result, err := s.db.UpdateVisibility(ctx, row)
+	if err != nil {
+		return convertCommonErrors(s.db, "UpsertWorkflowExecution", "", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return &types.InternalServiceError{
+			Message: fmt.Sprintf("UpsertWorkflowExecution rowsAffected error: %v", err),
+		}
+	}
+	if rowsAffected > 0 {
 		return nil
 	}
-	return p.ErrVisibilityOperationNotSupported
+	// either the row does not exist yet or the execution is already closed, in which case
+	// the insert is ignored
+	if _, err := s.db.InsertIntoVisibility(ctx, row); err != nil {
+		return convertCommonErrors(s.db, "UpsertWorkflowExecution", "", err)
+	}
+	return nil
 }
 
 func (s *sqlVisibilityStore) ListOpenWorkflowExecutions(
@@ -308,35 +405,57 @@ func (s *sqlVisibilityStore) DeleteUninitializedWorkflowExecution(
 	ctx context.Context,
 	request *p.VisibilityDeleteWorkflowExecutionRequest,
 ) error {
-	// temporary: not implemented, only implemented for ES
+	_, err := s.db.DeleteUninitializedFromVisibility(ctx, &sqlplugin.VisibilityFilter{
+		DomainID: request.DomainID,
+		RunID:    &request.RunID,
+	})
+	if err != nil {
+		return convertCommonErrors(s.db, "DeleteUninitializedWorkflowExecution", "", err)
+	}
 	return nil
 }
 
 func (s *sqlVisibilityStore) ListWorkflowExecutions(
-	_ context.Context,
-	_ *p.ListWorkflowExecutionsByQueryRequest,
+	ctx context.Context,
+	request *p.ListWorkflowExecutionsByQueryRequest,
 ) (*p.InternalListWorkflowExecutionsResponse, error) {
-	return nil, p.ErrVisibilityOperationNotSupported
+	return s.listWorkflowExecutionsByQuery(ctx, "ListWorkflowExecutions", request)
 }
 
 func (s *sqlVisibilityStore) ScanWorkflowExecutions(
-	_ context.Context,
-	_ *p.ListWorkflowExecutionsByQueryRequest,
+	ctx context.Context,
+	request *p.ListWorkflowExecutionsByQueryRequest,
 ) (*p.InternalListWorkflowExecutionsResponse, error) {
-	return nil, p.ErrVisibilityOperationNotSupported
+	return s.listWorkflowExecutionsByQuery(ctx, "ScanWorkflowExecutions", request)
 }
 
 func (s *sqlVisibilityStore) CountWorkflowExecutions(
-	_ context.Context,
-	_ *p.CountWorkflowExecutionsRequest,
+	ctx context.Context,
+	request *p.CountWorkflowExecutionsRequest,
 ) (*p.CountWorkflowExecutionsResponse, error) {
-	return nil, p.ErrVisibilityOperationNotSupported
+	condition, _, err := parseVisibilityQuery(request.Query)
+	if err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Error when parse query: %v", err)}
+	}
+	count, err := s.db.CountFromVisibilityByQuery(ctx, &sqlplugin.VisibilityQueryFilter{
+		DomainID:  request.DomainUUID,
+		Condition: condition,
+	})
+	if err != nil {
+		return nil, convertCommonErrors(s.db, "CountWorkflowExecutions", "", err)
+	}
+	return &p.CountWorkflowExecutionsResponse{Count: count}, nil
 }
 
 func (s *sqlVisibilityStore) rowToInfo(row *sqlplugin.VisibilityRow) *p.InternalVisibilityWorkflowExecutionInfo {
 	if row.ExecutionTime.UnixNano() == 0 {
 		row.ExecutionTime = row.StartTime
 	}
+	if row.IsUninitialized {
+		// uninitialized rows hold the update time in place of the unknown start time
+		row.StartTime = time.Time{}
+		row.ExecutionTime = time.Time{}
+	}
 	info := &p.InternalVisibilityWorkflowExecutionInfo{
 		WorkflowID:    row.WorkflowID,
 		RunID:         row.RunID,
@@ -355,9 +474,66 @@ func (s *sqlVisibilityStore) rowToInfo(row *sqlplugin.VisibilityRow) *p.Internal
 		info.CloseTime = *row.CloseTime
 		info.HistoryLength = *row.HistoryLength
 	}
+	if len(row.SearchAttributes) > 0 {
+		searchAttributes, err := deserializeVisibilitySearchAttributes(row.SearchAttributes)
+		if err != nil {
+			s.logger.Error("failed to deserialize visibility search attributes", tag.Error(err), tag.WorkflowRunID(row.RunID))
+		} else {
+			info.SearchAttributes = searchAttributes
+		}
+	}
 	return info
 }
 
+func (s *sqlVisibilityStore) listWorkflowExecutionsByQuery(
+	ctx context.Context,
+	opName string,
+	request *p.ListWorkflowExecutionsByQueryRequest,
+) (*p.InternalListWorkflowExecutionsResponse, error) {
+	condition, orderBy, err := parseVisibilityQuery(request.Query)
+	if err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Error when parse query: %v", err)}
+	}
+	token := &visibilityQueryPageToken{}
+	if len(request.NextPageToken) > 0 {
+		if err := json.Unmarshal(request.NextPageToken, token); err != nil {
+			return nil, &types.BadRequestError{Message: fmt.Sprintf("Invalid next page token: %v", err)}
+		}
+	}
+	pageSize := request.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultVisibilityQueryPageSize
+	}
+
+	rows, err := s.db.SelectFromVisibilityByQuery(ctx, &sqlplugin.VisibilityQueryFilter{
+		DomainID:  request.DomainUUID,
+		Condition: condition,
+		OrderBy:   orderBy,
+		Offset:    token.Offset,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		return nil, convertCommonErrors(s.db, opName, "", err)
+	}
+
+	infos := make([]*p.InternalVisibilityWorkflowExecutionInfo, len(rows))
+	for i, row := range rows {
+		row.DomainID = request.DomainUUID
+		infos[i] = s.rowToInfo(&row)
+	}
+	var nextPageToken []byte
+	if len(rows) == pageSize {
+		nextPageToken, err = json.Marshal(&visibilityQueryPageToken{Offset: token.Offset + len(rows)})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &p.InternalListWorkflowExecutionsResponse{
+		Executions:    infos,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
 func (s *sqlVisibilityStore) listWorkflowExecutions(opName string, pageToken []byte, earliestTime time.Time, latestTime time.Time, selectOp func(readLevel *visibilityPageToken) ([]sqlplugin.VisibilityRow, error)) (*p.InternalListWorkflowExecutionsResponse, error) {
 	var readLevel *visibilityPageToken
 	var err error
@@ -409,3 +585,30 @@ func (s *sqlVisibilityStore) serializePageToken(token *visibilityPageToken) ([]b
 	data, err := json.Marshal(token)
 	return data, err
 }
+
+// search attribute values are already json encoded, they are stored as a single json object
+func serializeVisibilitySearchAttributes(searchAttributes map[string][]byte) ([]byte, error) {
+	if len(searchAttributes) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]json.RawMessage, len(searchAttributes))
+	for key, value := range searchAttributes {
+		fields[key] = value
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, &types.BadRequestError{Message: fmt.Sprintf("Invalid search attributes: %v", err)}
+	}
+	return data, nil
+}
+
+func deserializeVisibilitySearchAttributes(data []byte) (map[string]interface{}, error) {
+	var searchAttributes map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep integers precise
+	decoder.UseNumber()
+	if err := decoder.Decode(&searchAttributes); err != nil {
+		return nil, err
+	}
+	return searchAttributes, nil
+}
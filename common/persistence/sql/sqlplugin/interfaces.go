@@ -542,6 +542,8 @@ type (
 		NumClusters      int16
 		UpdateTime       time.Time
 		ShardID          int16
+		SearchAttributes []byte
+		IsUninitialized  bool
 	}
 
 	// VisibilityFilter contains the column names within executions_visibility table that
@@ -631,6 +633,7 @@ type (
 
 		// eventsV2
 		InsertIntoHistoryNode(ctx context.Context, row *HistoryNodeRow) (sql.Result, error)
+		UpdateHistoryNode(ctx context.Context, row *HistoryNodeRow) (sql.Result, error)
 		SelectFromHistoryNode(ctx context.Context, filter *HistoryNodeFilter) ([]HistoryNodeRow, error)
 		DeleteFromHistoryNode(ctx context.Context, filter *HistoryNodeFilter) (sql.Result, error)
 		InsertIntoHistoryTree(ctx context.Context, row *HistoryTreeRow) (sql.Result, error)
@@ -789,6 +792,9 @@ type (
 		InsertIntoVisibility(ctx context.Context, row *VisibilityRow) (sql.Result, error)
 		// ReplaceIntoVisibility deletes old row (if it exist) and inserts new row into visibility table
 		ReplaceIntoVisibility(ctx context.Context, row *VisibilityRow) (sql.Result, error)
+		// UpdateVisibility updates the row of an open workflow execution in visibility table and
+		// marks it as initialized. Rows of closed workflow executions are left untouched
+		UpdateVisibility(ctx context.Context, row *VisibilityRow) (sql.Result, error)
 		// SelectFromVisibility returns one or more rows from visibility table
 		// Required filter params:
 		// - getClosedWorkflowExecution - retrieves single row - {domainID, runID, closed=true}
@@ -799,6 +805,18 @@ type (
 		//     - workflowID, workflowTypeName, closeStatus (along with closed=true)
 		SelectFromVisibility(ctx context.Context, filter *VisibilityFilter) ([]VisibilityRow, error)
 		DeleteFromVisibility(ctx context.Context, filter *VisibilityFilter) (sql.Result, error)
+		// DeleteUninitializedFromVisibility deletes a row from visibility table only if it
+		// is still marked as uninitialized
+		// Required filter params - {domainID, runID}
+		DeleteUninitializedFromVisibility(ctx context.Context, filter *VisibilityFilter) (sql.Result, error)
+		// SelectFromVisibilityByQuery returns one page of rows from visibility table matching an
+		// advanced visibility query
+		// Required filter params - {domainID, pageSize}
+		SelectFromVisibilityByQuery(ctx context.Context, filter *VisibilityQueryFilter) ([]VisibilityRow, error)
+		// CountFromVisibilityByQuery returns the number of rows in visibility table matching an
+		// advanced visibility query
+		// Required filter params - {domainID}
+		CountFromVisibilityByQuery(ctx context.Context, filter *VisibilityQueryFilter) (int64, error)
 
 		InsertIntoQueue(ctx context.Context, row *QueueRow) (sql.Result, error)
 		GetLastEnqueuedMessageIDForUpdate(ctx context.Context, queueType persistence.QueueType) (int64, error)
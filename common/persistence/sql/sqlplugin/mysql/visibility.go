@@ -25,18 +25,23 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 )
 
 const (
 	templateCreateWorkflowExecutionStarted = `INSERT IGNORE INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, num_clusters, update_time, shard_id) ` +
-		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, num_clusters, update_time, shard_id, search_attributes, is_uninitialized) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	templateCreateWorkflowExecutionClosed = `REPLACE INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, is_cron, num_clusters, update_time, shard_id) ` +
-		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, is_cron, num_clusters, update_time, shard_id, search_attributes) ` +
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	templateUpdateWorkflowExecution = `UPDATE executions_visibility SET ` +
+		`start_time = ?, execution_time = ?, workflow_type_name = ?, memo = ?, encoding = ?, is_cron = ?, num_clusters = ?, update_time = ?, shard_id = ?, search_attributes = ?, is_uninitialized = false ` +
+		`WHERE domain_id = ? AND run_id = ? AND close_status IS NULL`
 
 	// RunID condition is needed for correct pagination
 	templateConditions = ` AND domain_id = ?
@@ -46,8 +51,8 @@ const (
          ORDER BY start_time DESC, run_id
          LIMIT ?`
 
-	templateOpenFieldNames = `workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, update_time, shard_id`
-	templateOpenSelect     = `SELECT ` + templateOpenFieldNames + ` FROM executions_visibility WHERE close_status IS NULL `
+	templateOpenFieldNames = `workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, update_time, shard_id, search_attributes`
+	templateOpenSelect     = `SELECT ` + templateOpenFieldNames + ` FROM executions_visibility WHERE close_status IS NULL AND is_uninitialized = false `
 
 	templateClosedSelect = `SELECT ` + templateOpenFieldNames + `, close_time, close_status, history_length
 		 FROM executions_visibility WHERE close_status IS NOT NULL `
@@ -66,12 +71,19 @@ const (
 
 	templateGetClosedWorkflowExecutionsByStatus = templateClosedSelect + `AND close_status = ?` + templateConditions
 
-	templateGetClosedWorkflowExecution = `SELECT workflow_id, run_id, start_time, execution_time, memo, encoding, close_time, workflow_type_name, close_status, history_length, is_cron, update_time, shard_id
+	templateGetClosedWorkflowExecution = `SELECT workflow_id, run_id, start_time, execution_time, memo, encoding, close_time, workflow_type_name, close_status, history_length, is_cron, update_time, shard_id, search_attributes
 		 FROM executions_visibility
 		 WHERE domain_id = ? AND close_status IS NOT NULL
 		 AND run_id = ?`
 
+	templateGetWorkflowExecutionsByQuery = `SELECT ` + templateOpenFieldNames + `, close_time, close_status, history_length, is_uninitialized
+		 FROM executions_visibility WHERE %s ORDER BY %s LIMIT ? OFFSET ?`
+
+	templateCountWorkflowExecutionsByQuery = `SELECT COUNT(*) FROM executions_visibility WHERE %s`
+
 	templateDeleteWorkflowExecution = "DELETE FROM executions_visibility WHERE domain_id=? AND run_id=?"
+
+	templateDeleteUninitializedWorkflowExecution = "DELETE FROM executions_visibility WHERE domain_id=? AND run_id=? AND is_uninitialized = true"
 )
 
 var errCloseParams = errors.New("missing one of {closeStatus, closeTime, historyLength} params")
@@ -95,7 +107,9 @@ func (mdb *db) InsertIntoVisibility(ctx context.Context, row *sqlplugin.Visibili
 		row.IsCron,
 		row.NumClusters,
 		row.UpdateTime,
-		row.ShardID)
+		row.ShardID,
+		searchAttributesParam(row.SearchAttributes),
+		row.IsUninitialized)
 }
 
 // ReplaceIntoVisibility replaces an existing row if it exist or creates a new row in visibility table
@@ -122,18 +136,46 @@ func (mdb *db) ReplaceIntoVisibility(ctx context.Context, row *sqlplugin.Visibil
 			row.IsCron,
 			row.NumClusters,
 			row.UpdateTime,
-			row.ShardID)
+			row.ShardID,
+			searchAttributesParam(row.SearchAttributes))
 	default:
 		return nil, errCloseParams
 	}
 }
 
+// UpdateVisibility updates the row of an open workflow execution in visibility table
+func (mdb *db) UpdateVisibility(ctx context.Context, row *sqlplugin.VisibilityRow) (sql.Result, error) {
+	row.StartTime = mdb.converter.ToMySQLDateTime(row.StartTime)
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(row.DomainID, mdb.GetTotalNumDBShards())
+	return mdb.driver.ExecContext(ctx,
+		dbShardID,
+		templateUpdateWorkflowExecution,
+		row.StartTime,
+		row.ExecutionTime,
+		row.WorkflowTypeName,
+		row.Memo,
+		row.Encoding,
+		row.IsCron,
+		row.NumClusters,
+		row.UpdateTime,
+		row.ShardID,
+		searchAttributesParam(row.SearchAttributes),
+		row.DomainID,
+		row.RunID)
+}
+
 // DeleteFromVisibility deletes a row from visibility table if it exist
 func (mdb *db) DeleteFromVisibility(ctx context.Context, filter *sqlplugin.VisibilityFilter) (sql.Result, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
 	return mdb.driver.ExecContext(ctx, dbShardID, templateDeleteWorkflowExecution, filter.DomainID, filter.RunID)
 }
 
+// DeleteUninitializedFromVisibility deletes a row from visibility table if it exist and is uninitialized
+func (mdb *db) DeleteUninitializedFromVisibility(ctx context.Context, filter *sqlplugin.VisibilityFilter) (sql.Result, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
+	return mdb.driver.ExecContext(ctx, dbShardID, templateDeleteUninitializedWorkflowExecution, filter.DomainID, filter.RunID)
+}
+
 // SelectFromVisibility reads one or more rows from visibility table
 func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.VisibilityFilter) ([]sqlplugin.VisibilityRow, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
@@ -227,3 +269,77 @@ func (mdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 	}
 	return rows, err
 }
+
+// SelectFromVisibilityByQuery reads one page of rows matching an advanced visibility query from visibility table
+func (mdb *db) SelectFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) ([]sqlplugin.VisibilityRow, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
+	where, orderBy, args, err := sqlplugin.BuildVisibilityQuery(&visibilityQueryDialect{converter: mdb.converter}, filter)
+	if err != nil {
+		return nil, err
+	}
+	var rows []sqlplugin.VisibilityRow
+	err = mdb.driver.SelectContext(ctx,
+		dbShardID,
+		&rows,
+		fmt.Sprintf(templateGetWorkflowExecutionsByQuery, where, orderBy),
+		append(args, filter.PageSize, filter.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].StartTime = mdb.converter.FromMySQLDateTime(rows[i].StartTime)
+		rows[i].ExecutionTime = mdb.converter.FromMySQLDateTime(rows[i].ExecutionTime)
+		if rows[i].CloseTime != nil {
+			closeTime := mdb.converter.FromMySQLDateTime(*rows[i].CloseTime)
+			rows[i].CloseTime = &closeTime
+		}
+	}
+	return rows, nil
+}
+
+// CountFromVisibilityByQuery counts the rows matching an advanced visibility query in visibility table
+func (mdb *db) CountFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) (int64, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, mdb.GetTotalNumDBShards())
+	where, _, args, err := sqlplugin.BuildVisibilityQuery(&visibilityQueryDialect{converter: mdb.converter}, filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = mdb.driver.GetContext(ctx, dbShardID, &count, fmt.Sprintf(templateCountWorkflowExecutionsByQuery, where), args...)
+	return count, err
+}
+
+type visibilityQueryDialect struct {
+	converter DataConverter
+}
+
+func (d *visibilityQueryDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (d *visibilityQueryDialect) SearchAttribute(key string, kind sqlplugin.SearchAttributeKind) string {
+	extract := fmt.Sprintf(`JSON_EXTRACT(search_attributes, '$."%s"')`, key)
+	switch kind {
+	case sqlplugin.SearchAttributeKindString, sqlplugin.SearchAttributeKindBool:
+		return "JSON_UNQUOTE(" + extract + ")"
+	default:
+		// json numbers are compared numerically with sql numbers
+		return extract
+	}
+}
+
+func (d *visibilityQueryDialect) SearchAttributeContains(key string, placeholder string) string {
+	return fmt.Sprintf(`JSON_CONTAINS(search_attributes, JSON_QUOTE(%s), '$."%s"')`, placeholder, key)
+}
+
+func (d *visibilityQueryDialect) ConvertTime(t time.Time) time.Time {
+	return d.converter.ToMySQLDateTime(t)
+}
+
+// json columns do not accept binary strings, so search attributes are sent as text
+func searchAttributesParam(searchAttributes []byte) interface{} {
+	if searchAttributes == nil {
+		return nil
+	}
+	return string(searchAttributes)
+}
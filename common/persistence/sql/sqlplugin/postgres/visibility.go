@@ -26,19 +26,20 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
 )
 
 const (
 	templateCreateWorkflowExecutionStarted = `INSERT INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, num_clusters, update_time, shard_id) ` +
-		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, num_clusters, update_time, shard_id, search_attributes, is_uninitialized) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
          ON CONFLICT (domain_id, run_id) DO NOTHING`
 
 	templateCreateWorkflowExecutionClosed = `INSERT INTO executions_visibility (` +
-		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, is_cron, num_clusters, update_time, shard_id) ` +
-		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		`domain_id, workflow_id, run_id, start_time, execution_time, workflow_type_name, close_time, close_status, history_length, memo, encoding, is_cron, num_clusters, update_time, shard_id, search_attributes) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		ON CONFLICT (domain_id, run_id) DO UPDATE
 		  SET workflow_id = excluded.workflow_id,
 		      start_time = excluded.start_time,
@@ -52,7 +53,13 @@ const (
 				is_cron = excluded.is_cron,
 				num_clusters = excluded.num_clusters,
 				update_time = excluded.update_time,
-				shard_id = excluded.shard_id`
+				shard_id = excluded.shard_id,
+				search_attributes = excluded.search_attributes,
+				is_uninitialized = false`
+
+	templateUpdateWorkflowExecution = `UPDATE executions_visibility SET ` +
+		`start_time = $1, execution_time = $2, workflow_type_name = $3, memo = $4, encoding = $5, is_cron = $6, num_clusters = $7, update_time = $8, shard_id = $9, search_attributes = $10, is_uninitialized = false ` +
+		`WHERE domain_id = $11 AND run_id = $12 AND close_status IS NULL`
 
 	// RunID condition is needed for correct pagination
 	templateConditions1 = ` AND domain_id = $1
@@ -69,8 +76,8 @@ const (
          ORDER BY start_time DESC, run_id
          LIMIT $7`
 
-	templateOpenFieldNames = `workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, update_time, shard_id`
-	templateOpenSelect     = `SELECT ` + templateOpenFieldNames + ` FROM executions_visibility WHERE close_status IS NULL `
+	templateOpenFieldNames = `workflow_id, run_id, start_time, execution_time, workflow_type_name, memo, encoding, is_cron, update_time, shard_id, search_attributes`
+	templateOpenSelect     = `SELECT ` + templateOpenFieldNames + ` FROM executions_visibility WHERE close_status IS NULL AND is_uninitialized = false `
 
 	templateClosedSelect = `SELECT ` + templateOpenFieldNames + `, close_time, close_status, history_length
 		 FROM executions_visibility WHERE close_status IS NOT NULL `
@@ -89,12 +96,19 @@ const (
 
 	templateGetClosedWorkflowExecutionsByStatus = templateClosedSelect + `AND close_status = $1` + templateConditions2
 
-	templateGetClosedWorkflowExecution = `SELECT workflow_id, run_id, start_time, execution_time, memo, encoding, close_time, workflow_type_name, close_status, history_length, is_cron, update_time, shard_id
+	templateGetClosedWorkflowExecution = `SELECT workflow_id, run_id, start_time, execution_time, memo, encoding, close_time, workflow_type_name, close_status, history_length, is_cron, update_time, shard_id, search_attributes
 		 FROM executions_visibility
 		 WHERE domain_id = $1 AND close_status IS NOT NULL
 		 AND run_id = $2`
 
+	templateGetWorkflowExecutionsByQuery = `SELECT ` + templateOpenFieldNames + `, close_time, close_status, history_length, is_uninitialized
+		 FROM executions_visibility WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`
+
+	templateCountWorkflowExecutionsByQuery = `SELECT COUNT(*) FROM executions_visibility WHERE %s`
+
 	templateDeleteWorkflowExecution = "DELETE FROM executions_visibility WHERE domain_id=$1 AND run_id=$2"
+
+	templateDeleteUninitializedWorkflowExecution = "DELETE FROM executions_visibility WHERE domain_id=$1 AND run_id=$2 AND is_uninitialized = true"
 )
 
 var errCloseParams = errors.New("missing one of {closeStatus, closeTime, historyLength} params")
@@ -116,7 +130,9 @@ func (pdb *db) InsertIntoVisibility(ctx context.Context, row *sqlplugin.Visibili
 		row.IsCron,
 		row.NumClusters,
 		row.UpdateTime,
-		row.ShardID)
+		row.ShardID,
+		searchAttributesParam(row.SearchAttributes),
+		row.IsUninitialized)
 }
 
 // ReplaceIntoVisibility replaces an existing row if it exist or creates a new row in visibility table
@@ -141,18 +157,44 @@ func (pdb *db) ReplaceIntoVisibility(ctx context.Context, row *sqlplugin.Visibil
 			row.IsCron,
 			row.NumClusters,
 			row.UpdateTime,
-			row.ShardID)
+			row.ShardID,
+			searchAttributesParam(row.SearchAttributes))
 	default:
 		return nil, errCloseParams
 	}
 }
 
+// UpdateVisibility updates the row of an open workflow execution in visibility table
+func (pdb *db) UpdateVisibility(ctx context.Context, row *sqlplugin.VisibilityRow) (sql.Result, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(row.DomainID, pdb.GetTotalNumDBShards())
+	row.StartTime = pdb.converter.ToPostgresDateTime(row.StartTime)
+	return pdb.driver.ExecContext(ctx, dbShardID, templateUpdateWorkflowExecution,
+		row.StartTime,
+		row.ExecutionTime,
+		row.WorkflowTypeName,
+		row.Memo,
+		row.Encoding,
+		row.IsCron,
+		row.NumClusters,
+		row.UpdateTime,
+		row.ShardID,
+		searchAttributesParam(row.SearchAttributes),
+		row.DomainID,
+		row.RunID)
+}
+
 // DeleteFromVisibility deletes a row from visibility table if it exist
 func (pdb *db) DeleteFromVisibility(ctx context.Context, filter *sqlplugin.VisibilityFilter) (sql.Result, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
 	return pdb.driver.ExecContext(ctx, dbShardID, templateDeleteWorkflowExecution, filter.DomainID, filter.RunID)
 }
 
+// DeleteUninitializedFromVisibility deletes a row from visibility table if it exist and is uninitialized
+func (pdb *db) DeleteUninitializedFromVisibility(ctx context.Context, filter *sqlplugin.VisibilityFilter) (sql.Result, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
+	return pdb.driver.ExecContext(ctx, dbShardID, templateDeleteUninitializedWorkflowExecution, filter.DomainID, filter.RunID)
+}
+
 // SelectFromVisibility reads one or more rows from visibility table
 func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.VisibilityFilter) ([]sqlplugin.VisibilityRow, error) {
 	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
@@ -242,3 +284,79 @@ func (pdb *db) SelectFromVisibility(ctx context.Context, filter *sqlplugin.Visib
 	}
 	return rows, err
 }
+
+// SelectFromVisibilityByQuery reads one page of rows matching an advanced visibility query from visibility table
+func (pdb *db) SelectFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) ([]sqlplugin.VisibilityRow, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
+	where, orderBy, args, err := sqlplugin.BuildVisibilityQuery(&visibilityQueryDialect{converter: pdb.converter}, filter)
+	if err != nil {
+		return nil, err
+	}
+	var rows []sqlplugin.VisibilityRow
+	err = pdb.driver.SelectContext(ctx,
+		dbShardID,
+		&rows,
+		fmt.Sprintf(templateGetWorkflowExecutionsByQuery, where, orderBy, len(args)+1, len(args)+2),
+		append(args, filter.PageSize, filter.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].StartTime = pdb.converter.FromPostgresDateTime(rows[i].StartTime)
+		rows[i].ExecutionTime = pdb.converter.FromPostgresDateTime(rows[i].ExecutionTime)
+		if rows[i].CloseTime != nil {
+			closeTime := pdb.converter.FromPostgresDateTime(*rows[i].CloseTime)
+			rows[i].CloseTime = &closeTime
+		}
+		rows[i].RunID = strings.TrimSpace(rows[i].RunID)
+		rows[i].WorkflowID = strings.TrimSpace(rows[i].WorkflowID)
+	}
+	return rows, nil
+}
+
+// CountFromVisibilityByQuery counts the rows matching an advanced visibility query in visibility table
+func (pdb *db) CountFromVisibilityByQuery(ctx context.Context, filter *sqlplugin.VisibilityQueryFilter) (int64, error) {
+	dbShardID := sqlplugin.GetDBShardIDFromDomainID(filter.DomainID, pdb.GetTotalNumDBShards())
+	where, _, args, err := sqlplugin.BuildVisibilityQuery(&visibilityQueryDialect{converter: pdb.converter}, filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = pdb.driver.GetContext(ctx, dbShardID, &count, fmt.Sprintf(templateCountWorkflowExecutionsByQuery, where), args...)
+	return count, err
+}
+
+type visibilityQueryDialect struct {
+	converter DataConverter
+}
+
+func (d *visibilityQueryDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d *visibilityQueryDialect) SearchAttribute(key string, kind sqlplugin.SearchAttributeKind) string {
+	switch kind {
+	case sqlplugin.SearchAttributeKindString, sqlplugin.SearchAttributeKindBool:
+		return fmt.Sprintf("(search_attributes->>'%s')", key)
+	case sqlplugin.SearchAttributeKindNumber:
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(search_attributes->'%s') = 'number' THEN (search_attributes->>'%s')::numeric END)", key, key)
+	default:
+		return fmt.Sprintf("(search_attributes->'%s')", key)
+	}
+}
+
+func (d *visibilityQueryDialect) SearchAttributeContains(key string, placeholder string) string {
+	return fmt.Sprintf("(search_attributes->'%s' @> to_jsonb(CAST(%s AS TEXT)))", key, placeholder)
+}
+
+func (d *visibilityQueryDialect) ConvertTime(t time.Time) time.Time {
+	return d.converter.ToPostgresDateTime(t)
+}
+
+// jsonb columns do not accept bytea, so search attributes are sent as text
+func searchAttributesParam(searchAttributes []byte) interface{} {
+	if searchAttributes == nil {
+		return nil
+	}
+	return string(searchAttributes)
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlite
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/iancoleman/strcase"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3" // needed to register the sqlite3 driver
+
+	"github.com/uber/cadence/common/config"
+	pt "github.com/uber/cadence/common/persistence/persistence-tests"
+	"github.com/uber/cadence/common/persistence/sql"
+	"github.com/uber/cadence/common/persistence/sql/sqldriver"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin"
+)
+
+const (
+	// PluginName is the name of the plugin
+	PluginName = "sqlite"
+	// driverName is the name of the registered database/sql driver
+	driverName = "sqlite3"
+	// InMemoryDatabasePrefix is the prefix of the names of in-memory databases, e.g. ":memory:" or ":memory:visibility".
+	// Other database names are the path of the database file.
+	InMemoryDatabasePrefix   = ":memory:"
+	defaultBusyTimeoutMillis = "10000"
+)
+
+// dsnAttrOverrides are always set. Transactions are started with BEGIN IMMEDIATE, which takes the database
+// write lock, so that reads in a transaction have the same isolation as the locking reads of other plugins.
+var dsnAttrOverrides = map[string]string{
+	"_txlock": "immediate",
+}
+
+// dsnAttrDefaults are set if they are not in the connect attributes
+var dsnAttrDefaults = map[string]string{
+	"_busy_timeout": defaultBusyTimeoutMillis,
+}
+
+type plugin struct{}
+
+var _ sqlplugin.Plugin = (*plugin)(nil)
+
+// inMemoryDBs holds the in-memory databases of this process. An in-memory database only lives as long as its
+// connection, so it uses a single connection that is shared by all the callers, and kept until it's dropped.
+var inMemoryDBs = struct {
+	sync.Mutex
+	dbs map[string]*sqlx.DB
+}{dbs: make(map[string]*sqlx.DB)}
+
+func init() {
+	sql.RegisterPlugin(PluginName, &plugin{})
+}
+
+// CreateDB initialize the db object
+func (p *plugin) CreateDB(cfg *config.SQL) (sqlplugin.DB, error) {
+	conns, err := sqldriver.CreateDBConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return p.createSingleDBConn(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newDB(conns, nil, sqlplugin.DbShardUndefined, cfg.NumShards)
+}
+
+// CreateAdminDB initialize the adminDb object
+func (p *plugin) CreateAdminDB(cfg *config.SQL) (sqlplugin.AdminDB, error) {
+	conns, err := sqldriver.CreateDBConnections(cfg, func(cfg *config.SQL) (*sqlx.DB, error) {
+		return p.createSingleDBConn(cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newDB(conns, nil, sqlplugin.DbShardUndefined, cfg.NumShards)
+}
+
+func (p *plugin) createSingleDBConn(cfg *config.SQL) (*sqlx.DB, error) {
+	if isInMemory(cfg.DatabaseName) {
+		return getInMemoryDB(cfg.DatabaseName, cfg.ConnectAttributes)
+	}
+
+	db, err := openDB(cfg.DatabaseName, cfg.ConnectAttributes)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.MaxConnLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.MaxConnLifetime)
+	}
+	return db, nil
+}
+
+func openDB(name string, attrs map[string]string) (*sqlx.DB, error) {
+	db, err := sqlx.Connect(driverName, buildDSN(name, attrs))
+	if err != nil {
+		return nil, err
+	}
+	// Maps struct names in CamelCase to snake without need for db struct tags.
+	db.MapperFunc(strcase.ToSnake)
+	return db, nil
+}
+
+func isInMemory(name string) bool {
+	return strings.HasPrefix(name, InMemoryDatabasePrefix)
+}
+
+func getInMemoryDB(name string, attrs map[string]string) (*sqlx.DB, error) {
+	inMemoryDBs.Lock()
+	defer inMemoryDBs.Unlock()
+
+	if db, ok := inMemoryDBs.dbs[name]; ok {
+		return db, nil
+	}
+	db, err := openDB(name, attrs)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	inMemoryDBs.dbs[name] = db
+	return db, nil
+}
+
+func isInMemoryDB(xdb *sqlx.DB) bool {
+	inMemoryDBs.Lock()
+	defer inMemoryDBs.Unlock()
+
+	for _, db := range inMemoryDBs.dbs {
+		if db == xdb {
+			return true
+		}
+	}
+	return false
+}
+
+func releaseInMemoryDB(name string) error {
+	inMemoryDBs.Lock()
+	defer inMemoryDBs.Unlock()
+
+	db, ok := inMemoryDBs.dbs[name]
+	if !ok {
+		return nil
+	}
+	delete(inMemoryDBs.dbs, name)
+	return db.Close()
+}
+
+// createDatabase creates the database file, or the in-memory database
+func createDatabase(name string) error {
+	if isInMemory(name) {
+		_, err := getInMemoryDB(name, nil)
+		return err
+	}
+	db, err := openDB(name, nil)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func buildDSN(name string, connectAttrs map[string]string) string {
+	attrs := make(map[string]string, len(dsnAttrDefaults)+len(connectAttrs)+len(dsnAttrOverrides)+2)
+	for k, v := range dsnAttrDefaults {
+		attrs[k] = v
+	}
+	for k, v := range connectAttrs {
+		attrs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	for k, v := range dsnAttrOverrides {
+		attrs[k] = v
+	}
+	if isInMemory(name) {
+		attrs["mode"] = "memory"
+		attrs["cache"] = "shared"
+	} else if _, ok := attrs["_journal_mode"]; !ok {
+		attrs["_journal_mode"] = "WAL"
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, url.QueryEscape(k)+"="+url.QueryEscape(attrs[k]))
+	}
+	return fmt.Sprintf("file:%v?%v", (&url.URL{Path: name}).EscapedPath(), strings.Join(values, "&"))
+}
+
+const (
+	// testSchemaDir is relative to the directory of this file, so that the tests don't depend on the name of
+	// the directory of the repository
+	testSchemaDir = "../../../../../schema/sqlite"
+)
+
+// GetTestClusterOption return test options, the tests use an in-memory database so that they don't need a database server
+func GetTestClusterOption() (*pt.TestBaseOptions, error) {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil, fmt.Errorf("failed to get the path of the sqlite plugin")
+	}
+	return &pt.TestBaseOptions{
+		DBPluginName: PluginName,
+		DBName:       InMemoryDatabasePrefix + pt.GenerateRandomDBName(10),
+		SchemaDir:    filepath.Join(filepath.Dir(file), testSchemaDir),
+		StoreType:    config.StoreTypeSQL,
+	}, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDSN(t *testing.T) {
+	assert.Equal(t,
+		"file:cadence.db?_busy_timeout=10000&_journal_mode=WAL&_txlock=immediate",
+		buildDSN("cadence.db", nil))
+	assert.Equal(t,
+		"file:/tmp/cadence.db?_busy_timeout=100&_journal_mode=DELETE&_txlock=immediate",
+		buildDSN("/tmp/cadence.db", map[string]string{"_busy_timeout": "100", "_journal_mode": "DELETE", "_txlock": "deferred"}))
+	assert.Equal(t,
+		"file::memory:visibility?_busy_timeout=10000&_txlock=immediate&cache=shared&mode=memory",
+		buildDSN(":memory:visibility", nil))
+}
+
+func TestInMemoryDB(t *testing.T) {
+	name := InMemoryDatabasePrefix + t.Name()
+	assert.True(t, isInMemory(name))
+	assert.False(t, isInMemory("cadence.db"))
+
+	assert.NoError(t, createDatabase(name))
+	db1, err := getInMemoryDB(name, nil)
+	assert.NoError(t, err)
+	db2, err := getInMemoryDB(name, nil)
+	assert.NoError(t, err)
+	assert.True(t, db1 == db2)
+	assert.True(t, isInMemoryDB(db1))
+
+	assert.NoError(t, releaseInMemoryDB(name))
+	assert.False(t, isInMemoryDB(db1))
+	assert.NoError(t, releaseInMemoryDB(name))
+}
@@ -0,0 +1,347 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package sqlplugin
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// VisibilityQueryFilter contains a parsed advanced visibility query which is translated
+	// into a WHERE and an ORDER BY clause on executions_visibility table by each plugin
+	VisibilityQueryFilter struct {
+		DomainID string
+		// Condition is the parsed WHERE clause of the query, nil matches all rows of the domain
+		Condition VisibilityCondition
+		// OrderBy defaults to start_time DESC. Rows are always ordered by run_id last so that
+		// pages are stable
+		OrderBy  []VisibilityOrderBy
+		Offset   int
+		PageSize int
+	}
+
+	// VisibilityCondition is a node of the WHERE clause of an advanced visibility query
+	VisibilityCondition interface {
+		isVisibilityCondition()
+	}
+
+	// VisibilityAndCondition matches rows matched by both Left and Right
+	VisibilityAndCondition struct {
+		Left  VisibilityCondition
+		Right VisibilityCondition
+	}
+
+	// VisibilityOrCondition matches rows matched by either Left or Right
+	VisibilityOrCondition struct {
+		Left  VisibilityCondition
+		Right VisibilityCondition
+	}
+
+	// VisibilityComparisonCondition compares Field with Value. Operator is one of
+	// =, !=, <, <=, >, >=, like and not like. A nil Value can only be used with = and !=,
+	// and matches rows where the field is missing or present respectively
+	VisibilityComparisonCondition struct {
+		Field    VisibilityField
+		Operator string
+		Value    interface{}
+	}
+
+	// VisibilityInCondition matches rows where Field is (or is not) one of Values
+	VisibilityInCondition struct {
+		Field   VisibilityField
+		Values  []interface{}
+		Negated bool
+	}
+
+	// VisibilityRangeCondition matches rows where Field is (or is not) between From and To inclusively
+	VisibilityRangeCondition struct {
+		Field   VisibilityField
+		From    interface{}
+		To      interface{}
+		Negated bool
+	}
+
+	// VisibilityField references either a column of executions_visibility table or a
+	// custom search attribute stored in its search_attributes column
+	VisibilityField struct {
+		Column          string
+		SearchAttribute string
+	}
+
+	// VisibilityOrderBy is an ORDER BY term of an advanced visibility query
+	VisibilityOrderBy struct {
+		Field VisibilityField
+		Desc  bool
+	}
+
+	// VisibilityQueryDialect renders the database specific parts of an advanced visibility query
+	VisibilityQueryDialect interface {
+		// Placeholder returns the bind variable of the n-th argument, starting from 1
+		Placeholder(n int) string
+		// SearchAttribute returns an expression extracting the value of a custom search attribute
+		SearchAttribute(key string, kind SearchAttributeKind) string
+		// SearchAttributeContains returns a predicate matching rows where the custom search attribute
+		// is equal to, or is an array containing, the string bound to placeholder
+		SearchAttributeContains(key string, placeholder string) string
+		// ConvertTime converts a time argument into the representation stored in the database
+		ConvertTime(t time.Time) time.Time
+	}
+
+	// SearchAttributeKind is the type a custom search attribute is extracted as
+	SearchAttributeKind int
+
+	visibilityQueryBuilder struct {
+		dialect VisibilityQueryDialect
+		args    []interface{}
+	}
+)
+
+// SearchAttributeKind values
+const (
+	// SearchAttributeKindAny extracts the value in a form suitable for ordering
+	SearchAttributeKindAny SearchAttributeKind = iota
+	// SearchAttributeKindString extracts the value as text
+	SearchAttributeKindString
+	// SearchAttributeKindNumber extracts the value as a number, non numeric values are NULL
+	SearchAttributeKindNumber
+	// SearchAttributeKindBool extracts the value as the text 'true' or 'false'
+	SearchAttributeKindBool
+)
+
+var (
+	searchAttributeKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	comparisonOperators = map[string]bool{
+		"=":        true,
+		"!=":       true,
+		"<":        true,
+		"<=":       true,
+		">":        true,
+		">=":       true,
+		"like":     true,
+		"not like": true,
+	}
+)
+
+func (VisibilityAndCondition) isVisibilityCondition()        {}
+func (VisibilityOrCondition) isVisibilityCondition()         {}
+func (VisibilityComparisonCondition) isVisibilityCondition() {}
+func (VisibilityInCondition) isVisibilityCondition()         {}
+func (VisibilityRangeCondition) isVisibilityCondition()      {}
+
+// BuildVisibilityQuery renders filter into a WHERE clause and an ORDER BY clause, both without
+// their leading keywords, along with the arguments bound by the WHERE clause
+func BuildVisibilityQuery(
+	dialect VisibilityQueryDialect,
+	filter *VisibilityQueryFilter,
+) (where string, orderBy string, args []interface{}, err error) {
+	b := &visibilityQueryBuilder{dialect: dialect}
+	where = "domain_id = " + b.bind(filter.DomainID)
+	if filter.Condition != nil {
+		condition, err := b.condition(filter.Condition)
+		if err != nil {
+			return "", "", nil, err
+		}
+		where += " AND " + condition
+	}
+	orderBy, err = b.orderBy(filter.OrderBy)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return where, orderBy, b.args, nil
+}
+
+func (b *visibilityQueryBuilder) bind(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		value = b.dialect.ConvertTime(t)
+	}
+	b.args = append(b.args, value)
+	return b.dialect.Placeholder(len(b.args))
+}
+
+func (b *visibilityQueryBuilder) condition(condition VisibilityCondition) (string, error) {
+	switch c := condition.(type) {
+	case *VisibilityAndCondition:
+		return b.binary(c.Left, "AND", c.Right)
+	case *VisibilityOrCondition:
+		return b.binary(c.Left, "OR", c.Right)
+	case *VisibilityComparisonCondition:
+		return b.comparison(c)
+	case *VisibilityInCondition:
+		return b.in(c)
+	case *VisibilityRangeCondition:
+		return b.rangeCondition(c)
+	default:
+		return "", fmt.Errorf("unknown visibility condition %T", condition)
+	}
+}
+
+func (b *visibilityQueryBuilder) binary(left VisibilityCondition, operator string, right VisibilityCondition) (string, error) {
+	l, err := b.condition(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := b.condition(right)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s %s %s)", l, operator, r), nil
+}
+
+func (b *visibilityQueryBuilder) comparison(c *VisibilityComparisonCondition) (string, error) {
+	operator := strings.ToLower(c.Operator)
+	if !comparisonOperators[operator] {
+		return "", fmt.Errorf("unsupported operator %q", c.Operator)
+	}
+	if c.Value == nil {
+		field, err := b.field(c.Field, SearchAttributeKindAny)
+		if err != nil {
+			return "", err
+		}
+		switch operator {
+		case "=":
+			return field + " IS NULL", nil
+		case "!=":
+			return field + " IS NOT NULL", nil
+		default:
+			return "", fmt.Errorf("operator %q cannot be used to check for missing values", c.Operator)
+		}
+	}
+
+	if c.Field.SearchAttribute == "" {
+		return fmt.Sprintf("%s %s %s", c.Field.Column, strings.ToUpper(operator), b.bind(c.Value)), nil
+	}
+	if err := validateSearchAttributeKey(c.Field.SearchAttribute); err != nil {
+		return "", err
+	}
+	switch v := c.Value.(type) {
+	case string:
+		if operator == "=" || operator == "!=" {
+			// keyword search attributes may hold an array of values, any of which can match
+			contains := b.dialect.SearchAttributeContains(c.Field.SearchAttribute, b.bind(v))
+			if operator == "!=" {
+				return fmt.Sprintf("NOT COALESCE(%s, FALSE)", contains), nil
+			}
+			return contains, nil
+		}
+		return b.searchAttributeComparison(c.Field.SearchAttribute, SearchAttributeKindString, operator, v), nil
+	case bool:
+		if operator != "=" && operator != "!=" {
+			return "", fmt.Errorf("operator %q cannot be used with bool values", c.Operator)
+		}
+		return b.searchAttributeComparison(c.Field.SearchAttribute, SearchAttributeKindBool, operator, strconv.FormatBool(v)), nil
+	case int64, float64:
+		if operator == "like" || operator == "not like" {
+			return "", fmt.Errorf("operator %q cannot be used with numeric values", c.Operator)
+		}
+		return b.searchAttributeComparison(c.Field.SearchAttribute, SearchAttributeKindNumber, operator, v), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v for search attribute %s", c.Value, c.Field.SearchAttribute)
+	}
+}
+
+func (b *visibilityQueryBuilder) searchAttributeComparison(key string, kind SearchAttributeKind, operator string, value interface{}) string {
+	return fmt.Sprintf("%s %s %s", b.dialect.SearchAttribute(key, kind), strings.ToUpper(operator), b.bind(value))
+}
+
+func (b *visibilityQueryBuilder) in(c *VisibilityInCondition) (string, error) {
+	if len(c.Values) == 0 {
+		return "", fmt.Errorf("empty IN clause")
+	}
+	var terms []string
+	for _, value := range c.Values {
+		if value == nil {
+			return "", fmt.Errorf("missing value cannot be used in IN clause")
+		}
+		term, err := b.comparison(&VisibilityComparisonCondition{Field: c.Field, Operator: "=", Value: value})
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, term)
+	}
+	result := "(" + strings.Join(terms, " OR ") + ")"
+	if c.Negated {
+		result = "NOT COALESCE(" + result + ", FALSE)"
+	}
+	return result, nil
+}
+
+func (b *visibilityQueryBuilder) rangeCondition(c *VisibilityRangeCondition) (string, error) {
+	if c.From == nil || c.To == nil {
+		return "", fmt.Errorf("missing value cannot be used in BETWEEN clause")
+	}
+	kind := SearchAttributeKindNumber
+	if _, ok := c.From.(string); ok {
+		kind = SearchAttributeKindString
+	}
+	field, err := b.field(c.Field, kind)
+	if err != nil {
+		return "", err
+	}
+	operator := "BETWEEN"
+	if c.Negated {
+		operator = "NOT BETWEEN"
+	}
+	return fmt.Sprintf("%s %s %s AND %s", field, operator, b.bind(c.From), b.bind(c.To)), nil
+}
+
+func (b *visibilityQueryBuilder) orderBy(orderBy []VisibilityOrderBy) (string, error) {
+	if len(orderBy) == 0 {
+		return "start_time DESC, run_id", nil
+	}
+	terms := make([]string, 0, len(orderBy)+1)
+	for _, o := range orderBy {
+		field, err := b.field(o.Field, SearchAttributeKindAny)
+		if err != nil {
+			return "", err
+		}
+		if o.Desc {
+			field += " DESC"
+		}
+		terms = append(terms, field)
+	}
+	terms = append(terms, "run_id")
+	return strings.Join(terms, ", "), nil
+}
+
+func (b *visibilityQueryBuilder) field(field VisibilityField, kind SearchAttributeKind) (string, error) {
+	if field.SearchAttribute == "" {
+		return field.Column, nil
+	}
+	if err := validateSearchAttributeKey(field.SearchAttribute); err != nil {
+		return "", err
+	}
+	return b.dialect.SearchAttribute(field.SearchAttribute, kind), nil
+}
+
+// search attribute keys are rendered into the query, so only plain identifiers are allowed
+func validateSearchAttributeKey(key string) error {
+	if !searchAttributeKeyRegex.MatchString(key) {
+		return fmt.Errorf("invalid search attribute key %q", key)
+	}
+	return nil
+}
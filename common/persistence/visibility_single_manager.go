@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/encryption"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/types"
@@ -36,6 +37,7 @@ type (
 		serializer  PayloadSerializer
 		persistence VisibilityStore
 		logger      log.Logger
+		keyProvider encryption.KeyProvider
 	}
 )
 
@@ -45,11 +47,12 @@ const VisibilityEncoding = common.EncodingTypeThriftRW
 var _ VisibilityManager = (*visibilityManagerImpl)(nil)
 
 // NewVisibilityManagerImpl returns new VisibilityManager via a VisibilityStore
-func NewVisibilityManagerImpl(persistence VisibilityStore, logger log.Logger) VisibilityManager {
+func NewVisibilityManagerImpl(persistence VisibilityStore, logger log.Logger, keyProvider encryption.KeyProvider) VisibilityManager {
 	return &visibilityManagerImpl{
-		serializer:  NewPayloadSerializer(),
+		serializer:  NewPayloadSerializerWithKeyProvider(keyProvider),
 		persistence: persistence,
 		logger:      logger,
+		keyProvider: keyProvider,
 	}
 }
 
@@ -77,7 +80,7 @@ func (v *visibilityManagerImpl) RecordWorkflowExecutionStarted(
 		TaskList:           request.TaskList,
 		IsCron:             request.IsCron,
 		NumClusters:        request.NumClusters,
-		Memo:               v.serializeMemo(request.Memo, request.DomainUUID, request.Execution.GetWorkflowID(), request.Execution.GetRunID()),
+		Memo:               v.serializeMemo(request.Memo, request.DomainUUID, request.Domain, request.Execution.GetWorkflowID(), request.Execution.GetRunID()),
 		UpdateTimestamp:    time.Unix(0, request.UpdateTimestamp),
 		SearchAttributes:   request.SearchAttributes,
 		ShardID:            request.ShardID,
@@ -97,7 +100,7 @@ func (v *visibilityManagerImpl) RecordWorkflowExecutionClosed(
 		StartTimestamp:     time.Unix(0, request.StartTimestamp),
 		ExecutionTimestamp: time.Unix(0, request.ExecutionTimestamp),
 		TaskID:             request.TaskID,
-		Memo:               v.serializeMemo(request.Memo, request.DomainUUID, request.Execution.GetWorkflowID(), request.Execution.GetRunID()),
+		Memo:               v.serializeMemo(request.Memo, request.DomainUUID, request.Domain, request.Execution.GetWorkflowID(), request.Execution.GetRunID()),
 		TaskList:           request.TaskList,
 		SearchAttributes:   request.SearchAttributes,
 		CloseTimestamp:     time.Unix(0, request.CloseTimestamp),
@@ -139,7 +142,7 @@ func (v *visibilityManagerImpl) UpsertWorkflowExecution(
 		StartTimestamp:     time.Unix(0, request.StartTimestamp),
 		ExecutionTimestamp: time.Unix(0, request.ExecutionTimestamp),
 		TaskID:             request.TaskID,
-		Memo:               v.serializeMemo(request.Memo, request.DomainUUID, request.Execution.GetWorkflowID(), request.Execution.GetRunID()),
+		Memo:               v.serializeMemo(request.Memo, request.DomainUUID, request.Domain, request.Execution.GetWorkflowID(), request.Execution.GetRunID()),
 		TaskList:           request.TaskList,
 		IsCron:             request.IsCron,
 		NumClusters:        request.NumClusters,
@@ -427,8 +430,11 @@ func (v *visibilityManagerImpl) toInternalListWorkflowExecutionsRequest(req *Lis
 	}
 }
 
-func (v *visibilityManagerImpl) serializeMemo(visibilityMemo *types.Memo, domainID, wID, rID string) *DataBlob {
+func (v *visibilityManagerImpl) serializeMemo(visibilityMemo *types.Memo, domainID, domainName, wID, rID string) *DataBlob {
 	memo, err := v.serializer.SerializeVisibilityMemo(visibilityMemo, VisibilityEncoding)
+	if err == nil {
+		memo, err = EncryptDataBlob(v.keyProvider, memo, domainName)
+	}
 	if err != nil {
 		v.logger.WithTags(
 			tag.WorkflowDomainID(domainID),
@@ -48,7 +48,7 @@ func TestNewVisibilityManagerImpl(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
 			assert.NotPanics(t, func() {
-				NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+				NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 			})
 		})
 	}
@@ -67,7 +67,7 @@ func TestClose(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
 			mockVisibilityStore.EXPECT().Close().Return().Times(1)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 			assert.NotPanics(t, func() {
 				visibilityManager.Close()
 			})
@@ -88,7 +88,7 @@ func TestGetName(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
 			mockVisibilityStore.EXPECT().GetName().Return(testTableName).Times(1)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			assert.NotPanics(t, func() {
 				visibilityManager.GetName()
@@ -151,7 +151,7 @@ func TestRecordWorkflowExecutionStarted(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -215,7 +215,7 @@ func TestRecordWorkflowExecutionClosed(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -269,7 +269,7 @@ func TestRecordWorkflowExecutionUninitialized(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -333,7 +333,7 @@ func TestUpsertWorkflowExecution(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -377,7 +377,7 @@ func TestDeleteWorkflowExecution(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -421,7 +421,7 @@ func TestDeleteUninitializedWorkflowExecution(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -465,7 +465,7 @@ func TestListOpenWorkflowExecutions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -509,7 +509,7 @@ func TestListClosedWorkflowExecutions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -553,7 +553,7 @@ func TestListOpenWorkflowExecutionsByType(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -597,7 +597,7 @@ func TestTestListClosedWorkflowExecutionsByType(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -641,7 +641,7 @@ func TestListOpenWorkflowExecutionsByWorkflowID(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -685,7 +685,7 @@ func TestListClosedWorkflowExecutionsByWorkflowID(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -729,7 +729,7 @@ func TestListClosedWorkflowExecutionsByStatus(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -780,7 +780,7 @@ func TestGetClosedWorkflowExecution(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -839,7 +839,7 @@ func TestListWorkflowExecutions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -883,7 +883,7 @@ func TestScanWorkflowExecutions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -927,7 +927,7 @@ func TestCountWorkflowExecutions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 
 			test.visibilityStoreAffordance(mockVisibilityStore)
 
@@ -963,7 +963,7 @@ func TestGetSearchAttributes(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 			visibilityManagerImpl := visibilityManager.(*visibilityManagerImpl)
 
 			actualOutput, actualErr := visibilityManagerImpl.getSearchAttributes(*test.input)
@@ -1013,7 +1013,7 @@ func TestConvertVisibilityWorkflowExecutionInfo(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			mockVisibilityStore := NewMockVisibilityStore(ctrl)
-			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+			visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 			visibilityManagerImpl := visibilityManager.(*visibilityManagerImpl)
 
 			actualOutput := visibilityManagerImpl.convertVisibilityWorkflowExecutionInfo(test.input)
@@ -1025,7 +1025,7 @@ func TestConvertVisibilityWorkflowExecutionInfo(t *testing.T) {
 func TestToInternalListWorkflowExecutionsRequest(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	mockVisibilityStore := NewMockVisibilityStore(ctrl)
-	visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+	visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 	visibilityManagerImpl := visibilityManager.(*visibilityManagerImpl)
 
 	assert.Nil(t, visibilityManagerImpl.toInternalListWorkflowExecutionsRequest(nil))
@@ -1036,10 +1036,10 @@ func TestSerializeMemo(t *testing.T) {
 	mockVisibilityStore := NewMockVisibilityStore(ctrl)
 	mockPayloadSerializer := NewMockPayloadSerializer(ctrl)
 	mockPayloadSerializer.EXPECT().SerializeVisibilityMemo(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error")).Times(1)
-	visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop())
+	visibilityManager := NewVisibilityManagerImpl(mockVisibilityStore, log.NewNoop(), nil)
 	visibilityManagerImpl := visibilityManager.(*visibilityManagerImpl)
 	visibilityManagerImpl.serializer = mockPayloadSerializer
 	assert.NotPanics(t, func() {
-		visibilityManagerImpl.serializeMemo(nil, "testDomain", "testWorkflowID", "testRunID")
+		visibilityManagerImpl.serializeMemo(nil, "testDomainID", "testDomain", "testWorkflowID", "testRunID")
 	})
 }
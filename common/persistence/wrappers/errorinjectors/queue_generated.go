@@ -58,6 +58,21 @@ func (c *injectorQueueManager) Close() {
 	return
 }
 
+func (c *injectorQueueManager) DeleteAckLevel(ctx context.Context, clusterName string) (err error) {
+	fakeErr := generateFakeError(c.errorRate)
+	var forwardCall bool
+	if forwardCall = shouldForwardCallToPersistence(fakeErr); forwardCall {
+		err = c.wrapped.DeleteAckLevel(ctx, clusterName)
+	}
+
+	if fakeErr != nil {
+		logErr(c.logger, "QueueManager.DeleteAckLevel", fakeErr, forwardCall, err)
+		err = fakeErr
+		return
+	}
+	return
+}
+
 func (c *injectorQueueManager) DeleteMessageFromDLQ(ctx context.Context, messageID int64) (err error) {
 	fakeErr := generateFakeError(c.errorRate)
 	var forwardCall bool
@@ -118,6 +133,21 @@ func (c *injectorQueueManager) EnqueueMessageToDLQ(ctx context.Context, messageP
 	return
 }
 
+func (c *injectorQueueManager) EnqueueMessages(ctx context.Context, messagePayloads [][]byte) (err error) {
+	fakeErr := generateFakeError(c.errorRate)
+	var forwardCall bool
+	if forwardCall = shouldForwardCallToPersistence(fakeErr); forwardCall {
+		err = c.wrapped.EnqueueMessages(ctx, messagePayloads)
+	}
+
+	if fakeErr != nil {
+		logErr(c.logger, "QueueManager.EnqueueMessages", fakeErr, forwardCall, err)
+		err = fakeErr
+		return
+	}
+	return
+}
+
 func (c *injectorQueueManager) GetAckLevels(ctx context.Context) (m1 map[string]int64, err error) {
 	fakeErr := generateFakeError(c.errorRate)
 	var forwardCall bool
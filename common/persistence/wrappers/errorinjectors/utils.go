@@ -176,6 +176,8 @@ func historyManagerTags(op string) *tag.Tag {
 		return &tag.StoreOperationReadRawHistoryBranch
 	case "HistoryManager.GetAllHistoryTreeBranches":
 		return &tag.StoreOperationGetAllHistoryTreeBranches
+	case "HistoryManager.ReencryptHistoryBranch":
+		return &tag.StoreOperationReencryptHistoryBranch
 	}
 	return nil
 }
@@ -326,6 +328,8 @@ func queueManagerTags(op string) *tag.Tag {
 	switch op {
 	case "QueueManager.EnqueueMessage":
 		return &tag.StoreOperationEnqueueMessage
+	case "QueueManager.EnqueueMessages":
+		return &tag.StoreOperationEnqueueMessages
 	case "QueueManager.EnqueueMessageToDLQ":
 		return &tag.StoreOperationEnqueueMessageToDLQ
 	case "QueueManager.DeleteMessageFromDLQ":
@@ -334,6 +338,8 @@ func queueManagerTags(op string) *tag.Tag {
 		return &tag.StoreOperationRangeDeleteMessagesFromDLQ
 	case "QueueManager.UpdateAckLevel":
 		return &tag.StoreOperationUpdateAckLevel
+	case "QueueManager.DeleteAckLevel":
+		return &tag.StoreOperationDeleteAckLevel
 	case "QueueManager.GetAckLevels":
 		return &tag.StoreOperationGetAckLevels
 	case "QueueManager.UpdateDLQAckLevel":
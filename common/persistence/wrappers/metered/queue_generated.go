@@ -63,6 +63,17 @@ func (c *meteredQueueManager) Close() {
 	return
 }
 
+func (c *meteredQueueManager) DeleteAckLevel(ctx context.Context, clusterName string) (err error) {
+	op := func() error {
+		err = c.wrapped.DeleteAckLevel(ctx, clusterName)
+		c.emptyMetric("QueueManager.DeleteAckLevel", clusterName, err, err)
+		return err
+	}
+
+	err = c.call(metrics.PersistenceDeleteAckLevelScope, op, getCustomMetricTags(clusterName)...)
+	return
+}
+
 func (c *meteredQueueManager) DeleteMessageFromDLQ(ctx context.Context, messageID int64) (err error) {
 	op := func() error {
 		err = c.wrapped.DeleteMessageFromDLQ(ctx, messageID)
@@ -107,6 +118,17 @@ func (c *meteredQueueManager) EnqueueMessageToDLQ(ctx context.Context, messagePa
 	return
 }
 
+func (c *meteredQueueManager) EnqueueMessages(ctx context.Context, messagePayloads [][]byte) (err error) {
+	op := func() error {
+		err = c.wrapped.EnqueueMessages(ctx, messagePayloads)
+		c.emptyMetric("QueueManager.EnqueueMessages", messagePayloads, err, err)
+		return err
+	}
+
+	err = c.call(metrics.PersistenceEnqueueMessagesScope, op, getCustomMetricTags(messagePayloads)...)
+	return
+}
+
 func (c *meteredQueueManager) GetAckLevels(ctx context.Context) (m1 map[string]int64, err error) {
 	op := func() error {
 		m1, err = c.wrapped.GetAckLevels(ctx)
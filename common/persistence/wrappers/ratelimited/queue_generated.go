@@ -55,6 +55,14 @@ func (c *ratelimitedQueueManager) Close() {
 	return
 }
 
+func (c *ratelimitedQueueManager) DeleteAckLevel(ctx context.Context, clusterName string) (err error) {
+	if ok := c.rateLimiter.Allow(); !ok {
+		err = ErrPersistenceLimitExceeded
+		return
+	}
+	return c.wrapped.DeleteAckLevel(ctx, clusterName)
+}
+
 func (c *ratelimitedQueueManager) DeleteMessageFromDLQ(ctx context.Context, messageID int64) (err error) {
 	if ok := c.rateLimiter.Allow(); !ok {
 		err = ErrPersistenceLimitExceeded
@@ -87,6 +95,14 @@ func (c *ratelimitedQueueManager) EnqueueMessageToDLQ(ctx context.Context, messa
 	return c.wrapped.EnqueueMessageToDLQ(ctx, messagePayload)
 }
 
+func (c *ratelimitedQueueManager) EnqueueMessages(ctx context.Context, messagePayloads [][]byte) (err error) {
+	if ok := c.rateLimiter.Allow(); !ok {
+		err = ErrPersistenceLimitExceeded
+		return
+	}
+	return c.wrapped.EnqueueMessages(ctx, messagePayloads)
+}
+
 func (c *ratelimitedQueueManager) GetAckLevels(ctx context.Context) (m1 map[string]int64, err error) {
 	if ok := c.rateLimiter.Allow(); !ok {
 		err = ErrPersistenceLimitExceeded
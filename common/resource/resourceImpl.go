@@ -259,12 +259,14 @@ func New(
 		MetricsClient:    params.MetricsClient,
 		ClusterMetadata:  params.ClusterMetadata,
 		DomainCache:      domainCache,
+		KeyProvider:      params.PersistenceConfig.KeyProvider,
 	}
 	visibilityArchiverBootstrapContainer := &archiver.VisibilityBootstrapContainer{
 		Logger:          logger,
 		MetricsClient:   params.MetricsClient,
 		ClusterMetadata: params.ClusterMetadata,
 		DomainCache:     domainCache,
+		KeyProvider:     params.PersistenceConfig.KeyProvider,
 	}
 	if err := params.ArchiverProvider.RegisterBootstrapContainer(
 		serviceName,
@@ -159,6 +159,8 @@ func NewTest(
 	persistenceBean.EXPECT().GetHistoryManager().Return(historyMgr).AnyTimes()
 	persistenceBean.EXPECT().GetShardManager().Return(shardMgr).AnyTimes()
 	persistenceBean.EXPECT().GetExecutionManager(gomock.Any()).Return(executionMgr, nil).AnyTimes()
+	persistenceBean.EXPECT().GetMapQQueueManager(gomock.Any()).Return(persistence.NewMockQueueManager(controller), nil).AnyTimes()
+	persistenceBean.EXPECT().GetAuditQueueManager().Return(persistence.NewMockQueueManager(controller)).AnyTimes()
 
 	isolationGroupMock := isolationgroup.NewMockState(controller)
 	isolationGroupMock.EXPECT().Stop().AnyTimes()
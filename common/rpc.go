@@ -58,8 +58,33 @@ const (
 
 	// ClientIsolationGroupHeaderName refers to the name of the header that contains the isolation group which the client request is from
 	ClientIsolationGroupHeaderName = "cadence-client-isolation-group"
+
+	// The task priority headers carry optional scheduling hints which are ignored by servers and domains without the feature,
+	// so they are passed as headers rather than IDL fields. Changes to the semantics of an API, like new operations or
+	// fields that must be honored, are made in the IDL instead.
+	// ClientTaskPriorityHeaderName refers to the name of the header that contains the priority of the tasks of the workflow started by the request
+	ClientTaskPriorityHeaderName = "cadence-client-task-priority"
+	// ClientTaskFairnessKeyHeaderName refers to the name of the header that contains the fairness key of the tasks of the workflow started by the request
+	ClientTaskFairnessKeyHeaderName = "cadence-client-task-fairness-key"
+	// ClientTaskFairnessWeightHeaderName refers to the name of the header that contains the weight of the fairness key
+	ClientTaskFairnessWeightHeaderName = "cadence-client-task-fairness-weight"
+	// TaskListBacklogByPriorityHeaderName refers to the name of the DescribeTaskList response header that contains
+	// the json encoded number of buffered tasks of the task list by priority
+	TaskListBacklogByPriorityHeaderName = "cadence-task-list-backlog-by-priority"
+	// TaskListPartitionsHeaderName refers to the name of the DescribeTaskList response header of a root partition that
+	// contains the json encoded numbers of read and write partitions of the task list
+	TaskListPartitionsHeaderName = "cadence-task-list-partitions"
+	// TaskListPurgeCompletedHeaderName refers to the name of the DescribeTaskList response header that contains the
+	// creation time in unix seconds of the purge filter of the task list once the purge of its backlog is completed
+	TaskListPurgeCompletedHeaderName = "cadence-task-list-purge-completed"
 )
 
+// TaskListPartitions is the value of the TaskListPartitionsHeaderName header
+type TaskListPartitions struct {
+	ReadPartitions  int `json:"readPartitions"`
+	WritePartitions int `json:"writePartitions"`
+}
+
 type (
 	// RPCFactory Creates a dispatcher that knows how to transport requests.
 	RPCFactory interface {
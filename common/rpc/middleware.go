@@ -24,6 +24,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"strconv"
 
 	"go.uber.org/cadence/worker"
 	"go.uber.org/yarpc"
@@ -248,16 +249,33 @@ func (m *ForwardPartitionConfigMiddleware) Call(ctx context.Context, request *tr
 }
 
 // ClientPartitionConfigMiddleware stores the partition config and isolation group of the request into the context
-// It reads a header from client request and uses it as the isolation group
+// It reads a header from client request and uses it as the isolation group,
+// the task priority headers of the client request are also stored in the partition config
 type ClientPartitionConfigMiddleware struct{}
 
 func (m *ClientPartitionConfigMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	partitionConfig := make(map[string]string)
 	zone, _ := req.Headers.Get(common.ClientIsolationGroupHeaderName)
 	if zone != "" {
-		ctx = partition.ContextWithConfig(ctx, map[string]string{
-			partition.IsolationGroupKey: zone,
-		})
+		partitionConfig[partition.IsolationGroupKey] = zone
 		ctx = partition.ContextWithIsolationGroup(ctx, zone)
 	}
+	if priority, _ := req.Headers.Get(common.ClientTaskPriorityHeaderName); priority != "" {
+		// invalid priorities are ignored
+		if _, err := strconv.Atoi(priority); err == nil {
+			partitionConfig[partition.TaskPriorityKey] = priority
+		}
+	}
+	if fairnessKey, _ := req.Headers.Get(common.ClientTaskFairnessKeyHeaderName); fairnessKey != "" {
+		partitionConfig[partition.TaskFairnessKeyKey] = fairnessKey
+	}
+	if weight, _ := req.Headers.Get(common.ClientTaskFairnessWeightHeaderName); weight != "" {
+		if _, err := strconv.ParseFloat(weight, 64); err == nil {
+			partitionConfig[partition.TaskFairnessWeightKey] = weight
+		}
+	}
+	if len(partitionConfig) > 0 {
+		ctx = partition.ContextWithConfig(ctx, partitionConfig)
+	}
 	return h.Handle(ctx, req, resw)
 }
@@ -316,6 +316,22 @@ func TestClientPartitionConfigMiddleware(t *testing.T) {
 		assert.Equal(t, "dca1", partition.IsolationGroupFromContext(h.ctx))
 	})
 
+	t.Run("it sets the task priority", func(t *testing.T) {
+		m := &ClientPartitionConfigMiddleware{}
+		h := &fakeHandler{}
+		headers := transport.NewHeaders().
+			With(common.ClientTaskPriorityHeaderName, "-1").
+			With(common.ClientTaskFairnessKeyHeaderName, "tenant").
+			With(common.ClientTaskFairnessWeightHeaderName, "invalid")
+		err := m.Handle(context.Background(), &transport.Request{Headers: headers}, nil, h)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			partition.TaskPriorityKey:    "-1",
+			partition.TaskFairnessKeyKey: "tenant",
+		}, partition.ConfigFromContext(h.ctx))
+		assert.Equal(t, "", partition.IsolationGroupFromContext(h.ctx))
+	})
+
 	t.Run("noop when header is empty", func(t *testing.T) {
 		m := &ClientPartitionConfigMiddleware{}
 		h := &fakeHandler{}
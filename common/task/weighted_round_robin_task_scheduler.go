@@ -39,6 +39,7 @@ type weightedRoundRobinTaskSchedulerImpl struct {
 	status       int32
 	weights      atomic.Value // store the currently used weights
 	taskChs      map[int]chan PriorityTask
+	isolatedChs  map[string]chan PriorityTask // isolation key -> dedicated task channel
 	shutdownCh   chan struct{}
 	notifyCh     chan struct{}
 	dispatcherWG sync.WaitGroup
@@ -77,6 +78,7 @@ func NewWeightedRoundRobinTaskScheduler(
 	scheduler := &weightedRoundRobinTaskSchedulerImpl{
 		status:       common.DaemonStatusInitialized,
 		taskChs:      make(map[int]chan PriorityTask),
+		isolatedChs:  make(map[string]chan PriorityTask),
 		shutdownCh:   make(chan struct{}),
 		notifyCh:     make(chan struct{}, 1),
 		logger:       logger,
@@ -126,6 +128,9 @@ func (w *weightedRoundRobinTaskSchedulerImpl) Stop() {
 	for _, taskCh := range w.taskChs {
 		drainAndNackPriorityTask(taskCh)
 	}
+	for _, taskCh := range w.isolatedChs {
+		drainAndNackPriorityTask(taskCh)
+	}
 	w.RUnlock()
 
 	if success := common.AwaitWaitGroup(&w.dispatcherWG, time.Minute); !success {
@@ -144,7 +149,7 @@ func (w *weightedRoundRobinTaskSchedulerImpl) Submit(task PriorityTask) error {
 		return ErrTaskSchedulerClosed
 	}
 
-	taskCh, err := w.getOrCreateTaskChan(task.Priority())
+	taskCh, err := w.getOrCreateTaskChanForTask(task)
 	if err != nil {
 		return err
 	}
@@ -168,7 +173,7 @@ func (w *weightedRoundRobinTaskSchedulerImpl) TrySubmit(
 		return false, ErrTaskSchedulerClosed
 	}
 
-	taskCh, err := w.getOrCreateTaskChan(task.Priority())
+	taskCh, err := w.getOrCreateTaskChanForTask(task)
 	if err != nil {
 		return false, err
 	}
@@ -194,6 +199,7 @@ func (w *weightedRoundRobinTaskSchedulerImpl) dispatcher() {
 
 	outstandingTasks := false
 	taskChs := make(map[int]chan PriorityTask)
+	isolatedChs := make(map[string]chan PriorityTask)
 
 	for {
 		if !outstandingTasks {
@@ -210,7 +216,7 @@ func (w *weightedRoundRobinTaskSchedulerImpl) dispatcher() {
 		}
 
 		outstandingTasks = false
-		w.updateTaskChs(taskChs)
+		w.updateTaskChs(taskChs, isolatedChs)
 		weights := w.getWeights()
 		for priority, taskCh := range taskChs {
 			count, ok := weights[priority]
@@ -218,26 +224,71 @@ func (w *weightedRoundRobinTaskSchedulerImpl) dispatcher() {
 				w.logger.Error("weights not found for task priority", tag.Dynamic("priority", priority), tag.Dynamic("weights", weights))
 				continue
 			}
-		Submit_Loop:
-			for i := 0; i < count; i++ {
-				select {
-				case task := <-taskCh:
-					// dispatched at least one task in this round
-					outstandingTasks = true
-
-					if err := w.processor.Submit(task); err != nil {
-						w.logger.Error("fail to submit task to processor", tag.Error(err))
-						task.Nack()
-					}
-				case <-w.shutdownCh:
-					return
-				default:
-					// if no task, don't block. Skip to next priority
-					break Submit_Loop
-				}
+			dispatched, shutdown := w.dispatchTasks(taskCh, count)
+			if shutdown {
+				return
+			}
+			outstandingTasks = outstandingTasks || dispatched
+		}
+		for key, taskCh := range isolatedChs {
+			dispatched, shutdown := w.dispatchTasks(taskCh, w.getIsolationWeight(key))
+			if shutdown {
+				return
+			}
+			outstandingTasks = outstandingTasks || dispatched
+		}
+	}
+}
+
+// dispatchTasks dispatches at most count tasks from the task channel,
+// and returns if any task is dispatched and if the scheduler is shutdown
+func (w *weightedRoundRobinTaskSchedulerImpl) dispatchTasks(taskCh chan PriorityTask, count int) (bool, bool) {
+	dispatched := false
+	for i := 0; i < count; i++ {
+		select {
+		case task := <-taskCh:
+			// dispatched at least one task in this round
+			dispatched = true
+
+			if err := w.processor.Submit(task); err != nil {
+				w.logger.Error("fail to submit task to processor", tag.Error(err))
+				task.Nack()
 			}
+		case <-w.shutdownCh:
+			return dispatched, true
+		default:
+			// if no task, don't block. Skip to next channel
+			return dispatched, false
+		}
+	}
+	return dispatched, false
+}
+
+func (w *weightedRoundRobinTaskSchedulerImpl) getOrCreateTaskChanForTask(task PriorityTask) (chan PriorityTask, error) {
+	if w.options.IsolationKeyFn != nil {
+		if key := w.options.IsolationKeyFn(task); key != "" {
+			return w.getOrCreateIsolatedTaskChan(key), nil
 		}
 	}
+	return w.getOrCreateTaskChan(task.Priority())
+}
+
+func (w *weightedRoundRobinTaskSchedulerImpl) getOrCreateIsolatedTaskChan(key string) chan PriorityTask {
+	w.RLock()
+	if taskCh, ok := w.isolatedChs[key]; ok {
+		w.RUnlock()
+		return taskCh
+	}
+	w.RUnlock()
+
+	w.Lock()
+	defer w.Unlock()
+	if taskCh, ok := w.isolatedChs[key]; ok {
+		return taskCh
+	}
+	taskCh := make(chan PriorityTask, w.options.QueueSize)
+	w.isolatedChs[key] = taskCh
+	return taskCh
 }
 
 func (w *weightedRoundRobinTaskSchedulerImpl) getOrCreateTaskChan(priority int) (chan PriorityTask, error) {
@@ -262,7 +313,10 @@ func (w *weightedRoundRobinTaskSchedulerImpl) getOrCreateTaskChan(priority int)
 	return taskCh, nil
 }
 
-func (w *weightedRoundRobinTaskSchedulerImpl) updateTaskChs(taskChs map[int]chan PriorityTask) {
+func (w *weightedRoundRobinTaskSchedulerImpl) updateTaskChs(
+	taskChs map[int]chan PriorityTask,
+	isolatedChs map[string]chan PriorityTask,
+) {
 	w.RLock()
 	defer w.RUnlock()
 
@@ -271,6 +325,11 @@ func (w *weightedRoundRobinTaskSchedulerImpl) updateTaskChs(taskChs map[int]chan
 			taskChs[priority] = taskCh
 		}
 	}
+	for key, taskCh := range w.isolatedChs {
+		if _, ok := isolatedChs[key]; !ok {
+			isolatedChs[key] = taskCh
+		}
+	}
 }
 
 func (w *weightedRoundRobinTaskSchedulerImpl) notifyDispatcher() {
@@ -286,6 +345,13 @@ func (w *weightedRoundRobinTaskSchedulerImpl) getWeights() map[int]int {
 	return w.weights.Load().(map[int]int)
 }
 
+func (w *weightedRoundRobinTaskSchedulerImpl) getIsolationWeight(key string) int {
+	if w.options.IsolationWeight == nil {
+		return 1
+	}
+	return common.MaxInt(w.options.IsolationWeight(key), 1)
+}
+
 func (w *weightedRoundRobinTaskSchedulerImpl) updateWeights() {
 	ticker := time.NewTicker(defaultUpdateWeightsInterval)
 	for {
@@ -34,6 +34,13 @@ type WeightedRoundRobinTaskSchedulerOptions struct {
 	WorkerCount     dynamicconfig.IntPropertyFn
 	DispatcherCount int
 	RetryPolicy     backoff.RetryPolicy
+	// IsolationKeyFn returns the key of the dedicated channel a task should be scheduled from,
+	// or an empty string if the task should be scheduled from the channel for its priority.
+	// Optional, tasks are only scheduled by priority if not specified.
+	IsolationKeyFn func(PriorityTask) string
+	// IsolationWeight returns the weight of the dedicated channel for an isolation key,
+	// weight for a dedicated channel is at least 1
+	IsolationWeight func(string) int
 }
 
 func (o *WeightedRoundRobinTaskSchedulerOptions) String() string {
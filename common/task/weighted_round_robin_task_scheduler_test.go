@@ -280,6 +280,94 @@ func (s *weightedRoundRobinTaskSchedulerSuite) TestWRR() {
 	s.scheduler.Stop()
 }
 
+func (s *weightedRoundRobinTaskSchedulerSuite) TestSubmit_IsolatedTask() {
+	isolatedTask := NewMockPriorityTask(s.controller)
+	scheduler := s.newTestWeightedRoundRobinTaskScheduler(
+		&WeightedRoundRobinTaskSchedulerOptions{
+			Weights:         testSchedulerWeights,
+			QueueSize:       s.queueSize,
+			WorkerCount:     dynamicconfig.GetIntPropertyFn(1),
+			DispatcherCount: 3,
+			RetryPolicy:     backoff.NewExponentialRetryPolicy(time.Millisecond),
+			IsolationKeyFn: func(task PriorityTask) string {
+				if task == isolatedTask {
+					return "isolated"
+				}
+				return ""
+			},
+		},
+	)
+
+	// isolated task is scheduled from its dedicated channel regardless of its priority
+	err := scheduler.Submit(isolatedTask)
+	s.NoError(err)
+	submitted, err := scheduler.TrySubmit(isolatedTask)
+	s.NoError(err)
+	s.True(submitted)
+	s.Len(scheduler.isolatedChs["isolated"], 2)
+
+	mockTask := NewMockPriorityTask(s.controller)
+	mockTask.EXPECT().Priority().Return(1)
+	err = scheduler.Submit(mockTask)
+	s.NoError(err)
+	s.Len(scheduler.taskChs[1], 1)
+}
+
+func (s *weightedRoundRobinTaskSchedulerSuite) TestWRR_IsolatedTask() {
+	numTasks := 1000
+	var taskWG sync.WaitGroup
+
+	isolationKeys := []string{"", "isolated-1", "isolated-2"}
+	isolatedTasks := make(map[PriorityTask]string)
+	scheduler := s.newTestWeightedRoundRobinTaskScheduler(
+		&WeightedRoundRobinTaskSchedulerOptions{
+			Weights:         testSchedulerWeights,
+			QueueSize:       s.queueSize,
+			WorkerCount:     dynamicconfig.GetIntPropertyFn(1),
+			DispatcherCount: 3,
+			RetryPolicy:     backoff.NewExponentialRetryPolicy(time.Millisecond),
+			IsolationKeyFn: func(task PriorityTask) string {
+				return isolatedTasks[task]
+			},
+			IsolationWeight: func(key string) int {
+				if key == "isolated-1" {
+					return 2
+				}
+				// weight is at least 1
+				return 0
+			},
+		},
+	)
+
+	s.mockProcessor.EXPECT().Start()
+	s.mockProcessor.EXPECT().Stop()
+
+	tasks := []PriorityTask{}
+	mockFn := func(_ Task) error {
+		taskWG.Done()
+		return nil
+	}
+	for i := 0; i != numTasks; i++ {
+		mockTask := NewMockPriorityTask(s.controller)
+		if key := isolationKeys[rand.Intn(len(isolationKeys))]; key != "" {
+			isolatedTasks[mockTask] = key
+		} else {
+			mockTask.EXPECT().Priority().Return(rand.Intn(len(testSchedulerWeights()))).Times(1)
+		}
+		tasks = append(tasks, mockTask)
+		taskWG.Add(1)
+		s.mockProcessor.EXPECT().Submit(newMockPriorityTaskMatcher(mockTask)).DoAndReturn(mockFn)
+	}
+
+	scheduler.processor = s.mockProcessor
+	scheduler.Start()
+	for _, task := range tasks {
+		s.NoError(scheduler.Submit(task))
+	}
+	taskWG.Wait()
+	scheduler.Stop()
+}
+
 func (s *weightedRoundRobinTaskSchedulerSuite) TestSchedulerContract() {
 	testSchedulerContract(s.Assertions, s.controller, s.scheduler)
 }
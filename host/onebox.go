@@ -106,6 +106,7 @@ type (
 		historyV2Mgr                  persistence.HistoryManager
 		executionMgrFactory           persistence.ExecutionManagerFactory
 		domainReplicationQueue        domain.ReplicationQueue
+		mapQQueueManager              func(string) (persistence.QueueManager, error)
 		shutdownCh                    chan struct{}
 		shutdownWG                    sync.WaitGroup
 		clusterNo                     int // cluster number
@@ -198,6 +199,7 @@ type (
 		HistoryV2Mgr                  persistence.HistoryManager
 		ExecutionMgrFactory           persistence.ExecutionManagerFactory
 		DomainReplicationQueue        domain.ReplicationQueue
+		MapQQueueManager              func(string) (persistence.QueueManager, error)
 		Logger                        log.Logger
 		ClusterNo                     int
 		ArchiverMetadata              carchiver.ArchivalMetadata
@@ -234,6 +236,7 @@ func NewCadence(params *CadenceParams) Cadence {
 		historyV2Mgr:                  params.HistoryV2Mgr,
 		executionMgrFactory:           params.ExecutionMgrFactory,
 		domainReplicationQueue:        params.DomainReplicationQueue,
+		mapQQueueManager:              params.MapQQueueManager,
 		shutdownCh:                    make(chan struct{}),
 		clusterNo:                     params.ClusterNo,
 		esConfig:                      params.ESConfig,
@@ -811,6 +814,7 @@ func (c *cadenceImpl) startWorker(hosts map[string][]membership.HostInfo, startW
 			asyncWFDomainCache,
 			queueProvider,
 			c.frontendClient,
+			c.mapQQueueManager,
 			asyncworkflow.WithTimeSource(params.TimeSource),
 			asyncworkflow.WithRefreshInterval(time.Second),
 		)
@@ -54,6 +54,7 @@ import (
 	"github.com/uber/cadence/common/persistence/sql"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin/mysql"
 	"github.com/uber/cadence/common/persistence/sql/sqlplugin/postgres"
+	"github.com/uber/cadence/common/persistence/sql/sqlplugin/sqlite"
 	pnt "github.com/uber/cadence/common/pinot"
 	"github.com/uber/cadence/testflags"
 
@@ -162,6 +163,7 @@ func NewCluster(t *testing.T, options *TestClusterConfig, logger log.Logger, par
 		HistoryV2Mgr:                  testBase.HistoryV2Mgr,
 		ExecutionMgrFactory:           testBase.ExecutionMgrFactory,
 		DomainReplicationQueue:        domainReplicationQueue,
+		MapQQueueManager:              testBase.ExecutionMgrFactory.NewMapQQueueManager,
 		Logger:                        logger,
 		ClusterNo:                     options.ClusterNo,
 		ESConfig:                      options.ESConfig,
@@ -233,6 +235,7 @@ func NewPinotTestCluster(t *testing.T, options *TestClusterConfig, logger log.Lo
 		HistoryV2Mgr:                  testBase.HistoryV2Mgr,
 		ExecutionMgrFactory:           testBase.ExecutionMgrFactory,
 		DomainReplicationQueue:        domainReplicationQueue,
+		MapQQueueManager:              testBase.ExecutionMgrFactory.NewMapQQueueManager,
 		Logger:                        logger,
 		ClusterNo:                     options.ClusterNo,
 		ESConfig:                      options.ESConfig,
@@ -315,6 +318,12 @@ func NewPersistenceTestCluster(t *testing.T, clusterConfig *TestClusterConfig) t
 		case postgres.PluginName:
 			testflags.RequirePostgres(t)
 			ops, err = postgres.GetTestClusterOption()
+		case sqlite.PluginName:
+			// sqlite runs in-process, so the database is named by the test cluster option instead of the test config
+			ops, err = sqlite.GetTestClusterOption()
+			if err == nil {
+				clusterConfig.Persistence.DBName = ops.DBName
+			}
 		default:
 			t.Fatal("not supported plugin " + TestFlags.SQLPluginName)
 		}
@@ -0,0 +1,198 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cadence
+
+// below are the names of all DynamoDB tables.
+// The actual table name is prefixed by the keyspace in the NoSQL config, see TableName.
+const (
+	ShardTableName              = "shard"
+	CurrentWorkflowTableName    = "current_workflow"
+	WorkflowExecutionTableName  = "workflow_execution"
+	WorkflowRequestTableName    = "workflow_request"
+	TransferTaskTableName       = "transfer_task"
+	TimerTaskTableName          = "timer_task"
+	ReplicationTaskTableName    = "replication_task"
+	CrossClusterTaskTableName   = "cross_cluster_task"
+	ReplicationDLQTaskTableName = "replication_dlq_task"
+	HistoryTreeTableName        = "history_tree"
+	HistoryNodeTableName        = "history_node"
+	QueueMessageTableName       = "queue_message"
+	QueueMetadataTableName      = "queue_metadata"
+	DomainTableName             = "domain"
+	TaskListTableName           = "task_list"
+	TaskTableName               = "task"
+	VisibilityTableName         = "visibility"
+	ClusterConfigTableName      = "cluster_config"
+)
+
+// below are the names of the attributes that are used in key schema, indexes or conditions.
+const (
+	PartitionKeyAttribute        = "pk"
+	SortKeyAttribute             = "sk"
+	DataAttribute                = "data"
+	DataEncodingAttribute        = "data_encoding"
+	TTLAttribute                 = "ttl"
+	RangeIDAttribute             = "range_id"
+	NextEventIDAttribute         = "next_event_id"
+	CurrentRunIDAttribute        = "current_run_id"
+	LastWriteVersionAttribute    = "last_write_version"
+	WorkflowStateAttribute       = "workflow_state"
+	VersionAttribute             = "version"
+	NotificationVersionAttribute = "notification_version"
+	DomainIDAttribute            = "domain_id"
+	DomainWorkflowTypeAttribute  = "domain_workflow_type"
+	DomainWorkflowIDAttribute    = "domain_workflow_id"
+	DomainCloseStatusAttribute   = "domain_close_status"
+	ActivityInfosAttribute       = "activity_infos"
+	TimerInfosAttribute          = "timer_infos"
+	ChildExecutionInfosAttribute = "child_execution_infos"
+	RequestCancelInfosAttribute  = "request_cancel_infos"
+	SignalInfosAttribute         = "signal_infos"
+	SignalRequestedIDsAttribute  = "signal_requested_ids"
+	BufferedEventsAttribute      = "buffered_events"
+	StartTimeKeyAttribute        = "start_time_key"
+	CloseTimeKeyAttribute        = "close_time_key"
+)
+
+// below are the names of global secondary indexes of visibility table.
+// The name is <partition key attribute>-<sort key attribute>
+const (
+	VisibilityDomainStartTimeIndex       = DomainIDAttribute + "-" + StartTimeKeyAttribute
+	VisibilityDomainCloseTimeIndex       = DomainIDAttribute + "-" + CloseTimeKeyAttribute
+	VisibilityWorkflowTypeStartTimeIndex = DomainWorkflowTypeAttribute + "-" + StartTimeKeyAttribute
+	VisibilityWorkflowTypeCloseTimeIndex = DomainWorkflowTypeAttribute + "-" + CloseTimeKeyAttribute
+	VisibilityWorkflowIDStartTimeIndex   = DomainWorkflowIDAttribute + "-" + StartTimeKeyAttribute
+	VisibilityWorkflowIDCloseTimeIndex   = DomainWorkflowIDAttribute + "-" + CloseTimeKeyAttribute
+	VisibilityCloseStatusStartTimeIndex  = DomainCloseStatusAttribute + "-" + StartTimeKeyAttribute
+	VisibilityCloseStatusCloseTimeIndex  = DomainCloseStatusAttribute + "-" + CloseTimeKeyAttribute
+)
+
+// NOTE1: Every table uses the same key schema: a string partition key (pk) and a string sort key (sk).
+// Numbers and timestamps that are part of a sort key are encoded as fixed width strings so that the
+// lexicographical order is the same as the numerical order.
+
+// NOTE2: Columns that are not used in key schema, indexes or conditions are stored in the data attribute,
+// encoded with the encoding in data_encoding attribute. This allows adding new fields without schema changes.
+
+// IMPORTANT: making change to the items below is changing the DynamoDB table schema.
+// Please make sure it's backward compatible(e.g., don't delete the field, or change the annotation value).
+
+type (
+	// Item is the common part of all items
+	Item struct {
+		PartitionKey string `dynamodbav:"pk"`
+		SortKey      string `dynamodbav:"sk"`
+		Data         []byte `dynamodbav:"data,omitempty"`
+		DataEncoding string `dynamodbav:"data_encoding,omitempty"`
+		// TTL is the expiration time in unix seconds, zero means no expiration
+		TTL int64 `dynamodbav:"ttl,omitempty"`
+	}
+
+	// ShardItem is the schema of shard table
+	ShardItem struct {
+		Item
+		RangeID int64 `dynamodbav:"range_id"`
+	}
+
+	// CurrentWorkflowItem is the schema of current_workflow table
+	CurrentWorkflowItem struct {
+		Item
+		CurrentRunID     string `dynamodbav:"current_run_id"`
+		LastWriteVersion int64  `dynamodbav:"last_write_version"`
+		WorkflowState    int    `dynamodbav:"workflow_state"`
+	}
+
+	// WorkflowExecutionItem is the schema of workflow_execution table.
+	// The data attribute is the execution info, and the maps are stored as separate attributes so that
+	// a single entry can be updated without rewriting the whole item. Keys of the int64 maps are decimal strings.
+	WorkflowExecutionItem struct {
+		Item
+		NextEventID         int64             `dynamodbav:"next_event_id"`
+		ActivityInfos       map[string][]byte `dynamodbav:"activity_infos"`
+		TimerInfos          map[string][]byte `dynamodbav:"timer_infos"`
+		ChildExecutionInfos map[string][]byte `dynamodbav:"child_execution_infos"`
+		RequestCancelInfos  map[string][]byte `dynamodbav:"request_cancel_infos"`
+		SignalInfos         map[string][]byte `dynamodbav:"signal_infos"`
+		SignalRequestedIDs  map[string]bool   `dynamodbav:"signal_requested_ids"`
+		BufferedEvents      []EventBlob       `dynamodbav:"buffered_events"`
+	}
+
+	// EventBlob is an encoded batch of buffered events
+	EventBlob struct {
+		Data     []byte `dynamodbav:"data"`
+		Encoding string `dynamodbav:"encoding"`
+	}
+
+	// WorkflowRequestItem is the schema of workflow_request table
+	WorkflowRequestItem struct {
+		Item
+		RunID   string `dynamodbav:"current_run_id"`
+		Version int64  `dynamodbav:"version"`
+	}
+
+	// HistoryNodeItem is the schema of history_node table.
+	// The data attribute is the event batch blob, instead of an encoded row
+	HistoryNodeItem struct {
+		Item
+		NodeID int64 `dynamodbav:"node_id"`
+		TxnID  int64 `dynamodbav:"txn_id"`
+	}
+
+	// QueueMetadataItem is the schema of queue_metadata table
+	QueueMetadataItem struct {
+		Item
+		Version int64 `dynamodbav:"version"`
+	}
+
+	// DomainMetadataItem is the schema of the domain metadata record in domain table
+	DomainMetadataItem struct {
+		Item
+		NotificationVersion int64 `dynamodbav:"notification_version"`
+	}
+
+	// TaskListItem is the schema of task_list table
+	TaskListItem struct {
+		Item
+		RangeID int64 `dynamodbav:"range_id"`
+	}
+
+	// VisibilityItem is the schema of visibility table.
+	// DomainCloseStatus and CloseTimeKey are only set for closed workflows, so that the indexes on them are sparse.
+	VisibilityItem struct {
+		Item
+		DomainID           string `dynamodbav:"domain_id"`
+		DomainWorkflowType string `dynamodbav:"domain_workflow_type"`
+		DomainWorkflowID   string `dynamodbav:"domain_workflow_id"`
+		DomainCloseStatus  string `dynamodbav:"domain_close_status,omitempty"`
+		StartTimeKey       string `dynamodbav:"start_time_key"`
+		CloseTimeKey       string `dynamodbav:"close_time_key,omitempty"`
+	}
+)
+
+// TableName returns the actual table name for a keyspace
+func TableName(keyspace, name string) string {
+	if keyspace == "" {
+		return name
+	}
+	return keyspace + "_" + name
+}
@@ -20,9 +20,29 @@
 
 package cadence
 
+import "time"
+
 // below are the names of all mongoDB collections
 const (
-	ClusterConfigCollectionName = "cluster_config"
+	ClusterConfigCollectionName      = "cluster_config"
+	ShardCollectionName              = "shard"
+	CurrentWorkflowCollectionName    = "current_workflow"
+	WorkflowExecutionCollectionName  = "workflow_execution"
+	WorkflowRequestCollectionName    = "workflow_request"
+	TransferTaskCollectionName       = "transfer_task"
+	TimerTaskCollectionName          = "timer_task"
+	ReplicationTaskCollectionName    = "replication_task"
+	CrossClusterTaskCollectionName   = "cross_cluster_task"
+	ReplicationDLQTaskCollectionName = "replication_dlq_task"
+	HistoryTreeCollectionName        = "history_tree"
+	HistoryNodeCollectionName        = "history_node"
+	QueueMessageCollectionName       = "queue_message"
+	QueueMetadataCollectionName      = "queue_metadata"
+	DomainCollectionName             = "domain"
+	DomainMetadataCollectionName     = "domain_metadata"
+	TaskListCollectionName           = "task_list"
+	TaskCollectionName               = "task"
+	VisibilityCollectionName         = "visibility"
 )
 
 // NOTE1: MongoDB collection is schemaless -- there is no schema file for collection. We use Go lang structs to define the collection fields.
@@ -39,3 +59,172 @@ type ClusterConfigCollectionEntry struct {
 	DataEncoding         string `json:"dataencoding"`
 	UnixTimestampSeconds int64  `json:"unixtimestampseconds"`
 }
+
+// NOTE3: Below collections use bson annotations. Fields that are not used in filters, sorting or indexes are
+// stored in the data field, encoded with the encoding in dataencoding field.
+// This allows adding new fields to the rows without changing the collection schema.
+// ExpireTime is the field of the TTL index of the collection, documents without it never expire.
+
+// ShardCollectionEntry is the schema of shard collection
+type ShardCollectionEntry struct {
+	ShardID      int    `bson:"_id"`
+	RangeID      int64  `bson:"rangeid"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// DomainCollectionEntry is the schema of domain collection, Name has a unique index
+type DomainCollectionEntry struct {
+	ID           string `bson:"_id"`
+	Name         string `bson:"name"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// DomainMetadataCollectionEntry is the schema of domain_metadata collection, there is only one document
+type DomainMetadataCollectionEntry struct {
+	ID                  string `bson:"_id"`
+	NotificationVersion int64  `bson:"notificationversion"`
+}
+
+// QueueMessageCollectionEntry is the schema of queue_message collection
+type QueueMessageCollectionEntry struct {
+	QueueType    int    `bson:"queuetype"`
+	MessageID    int64  `bson:"messageid"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// QueueMetadataCollectionEntry is the schema of queue_metadata collection
+type QueueMetadataCollectionEntry struct {
+	QueueType    int    `bson:"_id"`
+	Version      int64  `bson:"version"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// HistoryTreeCollectionEntry is the schema of history_tree collection
+type HistoryTreeCollectionEntry struct {
+	TreeID       string `bson:"treeid"`
+	BranchID     string `bson:"branchid"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// HistoryNodeCollectionEntry is the schema of history_node collection, Data is the blob of the event batch
+type HistoryNodeCollectionEntry struct {
+	TreeID       string `bson:"treeid"`
+	BranchID     string `bson:"branchid"`
+	NodeID       int64  `bson:"nodeid"`
+	TxnID        int64  `bson:"txnid"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// TaskListCollectionEntry is the schema of task_list collection
+type TaskListCollectionEntry struct {
+	DomainID     string     `bson:"domainid"`
+	TaskListName string     `bson:"tasklistname"`
+	TaskListType int        `bson:"tasklisttype"`
+	RangeID      int64      `bson:"rangeid"`
+	Data         []byte     `bson:"data"`
+	DataEncoding string     `bson:"dataencoding"`
+	ExpireTime   *time.Time `bson:"expiretime,omitempty"`
+}
+
+// TaskCollectionEntry is the schema of task collection
+type TaskCollectionEntry struct {
+	DomainID     string     `bson:"domainid"`
+	TaskListName string     `bson:"tasklistname"`
+	TaskListType int        `bson:"tasklisttype"`
+	TaskID       int64      `bson:"taskid"`
+	Data         []byte     `bson:"data"`
+	DataEncoding string     `bson:"dataencoding"`
+	ExpireTime   *time.Time `bson:"expiretime,omitempty"`
+}
+
+// VisibilityCollectionEntry is the schema of visibility collection.
+// CloseTime and CloseStatus are only set for closed workflows.
+type VisibilityCollectionEntry struct {
+	DomainID         string     `bson:"domainid"`
+	RunID            string     `bson:"runid"`
+	WorkflowID       string     `bson:"workflowid"`
+	WorkflowTypeName string     `bson:"workflowtypename"`
+	Closed           bool       `bson:"closed"`
+	StartTime        int64      `bson:"starttime"`
+	CloseTime        int64      `bson:"closetime,omitempty"`
+	CloseStatus      *int       `bson:"closestatus,omitempty"`
+	Data             []byte     `bson:"data"`
+	DataEncoding     string     `bson:"dataencoding"`
+	ExpireTime       *time.Time `bson:"expiretime,omitempty"`
+}
+
+// CurrentWorkflowCollectionEntry is the schema of current_workflow collection
+type CurrentWorkflowCollectionEntry struct {
+	ShardID          int    `bson:"shardid"`
+	DomainID         string `bson:"domainid"`
+	WorkflowID       string `bson:"workflowid"`
+	CurrentRunID     string `bson:"currentrunid"`
+	LastWriteVersion int64  `bson:"lastwriteversion"`
+	WorkflowState    int    `bson:"workflowstate"`
+	Data             []byte `bson:"data"`
+	DataEncoding     string `bson:"dataencoding"`
+}
+
+// WorkflowExecutionCollectionEntry is the schema of workflow_execution collection.
+// Data is the execution info, and the maps are stored as separate fields so that a single entry can be updated
+// without rewriting the whole document. The map keys are encoded so that they can be used in field paths.
+type WorkflowExecutionCollectionEntry struct {
+	ShardID             int               `bson:"shardid"`
+	DomainID            string            `bson:"domainid"`
+	WorkflowID          string            `bson:"workflowid"`
+	RunID               string            `bson:"runid"`
+	NextEventID         int64             `bson:"nexteventid"`
+	Data                []byte            `bson:"data"`
+	DataEncoding        string            `bson:"dataencoding"`
+	ActivityInfos       map[string][]byte `bson:"activityinfos"`
+	TimerInfos          map[string][]byte `bson:"timerinfos"`
+	ChildExecutionInfos map[string][]byte `bson:"childexecutioninfos"`
+	RequestCancelInfos  map[string][]byte `bson:"requestcancelinfos"`
+	SignalInfos         map[string][]byte `bson:"signalinfos"`
+	SignalRequestedIDs  map[string]bool   `bson:"signalrequestedids"`
+	BufferedEvents      []EventBlob       `bson:"bufferedevents"`
+}
+
+// EventBlob is an encoded batch of buffered events
+type EventBlob struct {
+	Data     []byte `bson:"data"`
+	Encoding string `bson:"encoding"`
+}
+
+// WorkflowRequestCollectionEntry is the schema of workflow_request collection
+type WorkflowRequestCollectionEntry struct {
+	ShardID     int       `bson:"shardid"`
+	DomainID    string    `bson:"domainid"`
+	WorkflowID  string    `bson:"workflowid"`
+	RequestType int       `bson:"requesttype"`
+	RequestID   string    `bson:"requestid"`
+	RunID       string    `bson:"runid"`
+	Version     int64     `bson:"version"`
+	ExpireTime  time.Time `bson:"expiretime"`
+}
+
+// ShardTaskCollectionEntry is the schema of transfer_task, replication_task, cross_cluster_task and
+// replication_dlq_task collections.
+// Cluster is the target cluster of cross cluster tasks, or the source cluster of replication DLQ tasks.
+type ShardTaskCollectionEntry struct {
+	ShardID      int    `bson:"shardid"`
+	Cluster      string `bson:"cluster,omitempty"`
+	TaskID       int64  `bson:"taskid"`
+	Data         []byte `bson:"data"`
+	DataEncoding string `bson:"dataencoding"`
+}
+
+// TimerTaskCollectionEntry is the schema of timer_task collection, VisibilityTimestamp is in unix nanoseconds
+type TimerTaskCollectionEntry struct {
+	ShardID             int    `bson:"shardid"`
+	VisibilityTimestamp int64  `bson:"visibilitytimestamp"`
+	TaskID              int64  `bson:"taskid"`
+	Data                []byte `bson:"data"`
+	DataEncoding        string `bson:"dataencoding"`
+}
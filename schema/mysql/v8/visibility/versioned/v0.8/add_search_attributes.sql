@@ -0,0 +1,2 @@
+ALTER TABLE executions_visibility ADD search_attributes JSON NULL;
+ALTER TABLE executions_visibility ADD is_uninitialized BOOLEAN DEFAULT false NOT NULL;
@@ -15,6 +15,8 @@ CREATE TABLE executions_visibility (
   num_clusters         INTEGER NULL,
   update_time          TIMESTAMP NULL,
   shard_id             INTEGER NULL,
+  search_attributes    JSONB NULL,
+  is_uninitialized     BOOLEAN DEFAULT false NOT NULL,
 
   PRIMARY KEY  (domain_id, run_id)
 );
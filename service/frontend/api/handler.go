@@ -141,6 +141,7 @@ func NewWorkflowHandler(
 			resource.GetAsyncWorkflowQueueProvider(),
 			resource.GetLogger(),
 			resource.GetMetricsClient(),
+			resource.GetPersistenceBean().GetMapQQueueManager,
 		),
 		thriftrwEncoder: codec.NewThriftRWEncoder(),
 	}
@@ -655,7 +656,12 @@ func (wh *WorkflowHandler) getIsolationGroup(ctx context.Context, domainName str
 }
 
 func (wh *WorkflowHandler) getPartitionConfig(ctx context.Context, domainName string) map[string]string {
-	return partition.ConfigFromContext(ctx)
+	partitionConfig := partition.ConfigFromContext(ctx)
+	if !wh.config.EnableClientTaskPriority(domainName) {
+		// task priority headers are ignored unless they are enabled for the domain
+		return partition.WithoutTaskPriority(partitionConfig)
+	}
+	return partition.WithTaskPriorityInRange(partitionConfig, wh.config.MinClientTaskPriority(domainName), wh.config.MaxClientTaskPriority(domainName))
 }
 
 func (wh *WorkflowHandler) isIsolationGroupHealthy(ctx context.Context, domainName, isolationGroup string) bool {
@@ -3459,14 +3465,24 @@ func (wh *WorkflowHandler) DescribeTaskList(
 		return nil, validate.ErrTaskListTypeNotSet
 	}
 
+	var responseHeaders map[string]string
 	response, err := wh.GetMatchingClient().DescribeTaskList(ctx, &types.MatchingDescribeTaskListRequest{
 		DomainUUID:  domainID,
 		DescRequest: request,
-	})
+	}, yarpc.ResponseHeaders(&responseHeaders))
 	if err != nil {
 		return nil, err
 	}
 
+	// the backlog by priority, the partition counts and the purge state have no field in the IDL, so they are passed on as response headers
+	for _, header := range []string{common.TaskListBacklogByPriorityHeaderName, common.TaskListPartitionsHeaderName, common.TaskListPurgeCompletedHeaderName} {
+		if value, ok := responseHeaders[header]; ok {
+			if call := yarpc.CallFromContext(ctx); call != nil {
+				_ = call.WriteResponseHeader(header, value)
+			}
+		}
+	}
+
 	return response, nil
 }
 
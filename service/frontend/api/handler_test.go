@@ -285,6 +285,27 @@ func (s *workflowHandlerSuite) TestPollForDecisionTask_IsolationGroupDrained() {
 	s.Equal(&types.PollForDecisionTaskResponse{}, resp)
 }
 
+func (s *workflowHandlerSuite) TestGetPartitionConfig_TaskPriority() {
+	ctx := partition.ContextWithConfig(context.Background(), map[string]string{
+		partition.IsolationGroupKey:  "dca1",
+		partition.TaskPriorityKey:    "100",
+		partition.TaskFairnessKeyKey: "tenant",
+	})
+
+	config := s.newConfig(dc.NewInMemoryClient())
+	wh := s.getWorkflowHandler(config)
+	// task priority headers are ignored by default
+	s.Equal(map[string]string{partition.IsolationGroupKey: "dca1"}, wh.getPartitionConfig(ctx, s.testDomain))
+
+	config.EnableClientTaskPriority = dc.GetBoolPropertyFnFilteredByDomain(true)
+	config.MaxClientTaskPriority = dc.GetIntPropertyFilteredByDomain(5)
+	s.Equal(map[string]string{
+		partition.IsolationGroupKey:  "dca1",
+		partition.TaskPriorityKey:    "5",
+		partition.TaskFairnessKeyKey: "tenant",
+	}, wh.getPartitionConfig(ctx, s.testDomain))
+}
+
 func (s *workflowHandlerSuite) TestPollForActivityTask_IsolationGroupDrained() {
 	config := s.newConfig(dc.NewInMemoryClient())
 	config.EnableTasklistIsolation = dc.GetBoolPropertyFnFilteredByDomain(true)
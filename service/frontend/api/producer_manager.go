@@ -34,6 +34,7 @@ import (
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/messaging"
 	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
 )
 
 type (
@@ -48,6 +49,7 @@ type (
 		provider      queue.Provider
 		logger        log.Logger
 		metricsClient metrics.Client
+		queueManager  func(string) (persistence.QueueManager, error)
 
 		producerCache cache.Cache
 	}
@@ -58,12 +60,14 @@ func NewProducerManager(
 	provider queue.Provider,
 	logger log.Logger,
 	metricsClient metrics.Client,
+	queueManager func(string) (persistence.QueueManager, error),
 ) ProducerManager {
 	return &producerManagerImpl{
 		domainCache:   domainCache,
 		provider:      provider,
 		logger:        logger,
 		metricsClient: metricsClient,
+		queueManager:  queueManager,
 		producerCache: cache.New(&cache.Options{
 			TTL:             time.Minute * 5,
 			InitialCapacity: 5,
@@ -104,7 +108,7 @@ func (q *producerManagerImpl) GetProducerByDomain(
 		return val.(messaging.Producer), nil
 	}
 
-	producer, err := queue.CreateProducer(&provider.Params{Logger: q.logger, MetricsClient: q.metricsClient})
+	producer, err := queue.CreateProducer(&provider.Params{Logger: q.logger, MetricsClient: q.metricsClient, MapQQueueManager: q.queueManager})
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +218,7 @@ func TestGetProducerByDomain(t *testing.T) {
 				mockProvider,
 				nil,
 				nil,
+				nil,
 			)
 			producerManager.(*producerManagerImpl).producerCache = mockProducerCache
 
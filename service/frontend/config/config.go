@@ -70,6 +70,11 @@ type Config struct {
 	// isolation configuration
 	EnableTasklistIsolation dynamicconfig.BoolPropertyFnWithDomainFilter
 
+	// task priority headers of clients, the priority is clamped to [MinClientTaskPriority, MaxClientTaskPriority]
+	EnableClientTaskPriority dynamicconfig.BoolPropertyFnWithDomainFilter
+	MinClientTaskPriority    dynamicconfig.IntPropertyFnWithDomainFilter
+	MaxClientTaskPriority    dynamicconfig.IntPropertyFnWithDomainFilter
+
 	// id length limits
 	MaxIDLengthWarnLimit  dynamicconfig.IntPropertyFn
 	DomainNameMaxLength   dynamicconfig.IntPropertyFnWithDomainFilter
@@ -188,6 +193,9 @@ func NewConfig(dc *dynamicconfig.Collection, numHistoryShards int, isAdvancedVis
 		EmitSignalNameMetricsTag:                    dc.GetBoolPropertyFilteredByDomain(dynamicconfig.FrontendEmitSignalNameMetricsTag),
 		Lockdown:                                    dc.GetBoolPropertyFilteredByDomain(dynamicconfig.Lockdown),
 		EnableTasklistIsolation:                     dc.GetBoolPropertyFilteredByDomain(dynamicconfig.EnableTasklistIsolation),
+		EnableClientTaskPriority:                    dc.GetBoolPropertyFilteredByDomain(dynamicconfig.FrontendEnableClientTaskPriority),
+		MinClientTaskPriority:                       dc.GetIntPropertyFilteredByDomain(dynamicconfig.FrontendMinClientTaskPriority),
+		MaxClientTaskPriority:                       dc.GetIntPropertyFilteredByDomain(dynamicconfig.FrontendMaxClientTaskPriority),
 		DomainConfig: domain.Config{
 			MaxBadBinaryCount:      dc.GetIntPropertyFilteredByDomain(dynamicconfig.FrontendMaxBadBinaries),
 			MinRetentionDays:       dc.GetIntProperty(dynamicconfig.MinRetentionDays),
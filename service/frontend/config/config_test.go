@@ -104,6 +104,9 @@ func TestNewConfig(t *testing.T) {
 		"EmitSignalNameMetricsTag":                    {dynamicconfig.FrontendEmitSignalNameMetricsTag, true},
 		"Lockdown":                                    {dynamicconfig.Lockdown, false},
 		"EnableTasklistIsolation":                     {dynamicconfig.EnableTasklistIsolation, true},
+		"EnableClientTaskPriority":                    {dynamicconfig.FrontendEnableClientTaskPriority, true},
+		"MinClientTaskPriority":                       {dynamicconfig.FrontendMinClientTaskPriority, 45},
+		"MaxClientTaskPriority":                       {dynamicconfig.FrontendMaxClientTaskPriority, 46},
 		"GlobalRatelimiterKeyMode":                    {dynamicconfig.FrontendGlobalRatelimiterMode, "disabled"},
 		"GlobalRatelimiterUpdateInterval":             {dynamicconfig.GlobalRatelimiterUpdateInterval, 3 * time.Second},
 	}
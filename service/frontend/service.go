@@ -29,6 +29,8 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/authorization"
 	"github.com/uber/cadence/common/client"
 	"github.com/uber/cadence/common/domain"
 	"github.com/uber/cadence/common/dynamicconfig"
@@ -41,6 +43,7 @@ import (
 	"github.com/uber/cadence/service/frontend/api"
 	"github.com/uber/cadence/service/frontend/config"
 	"github.com/uber/cadence/service/frontend/wrappers/accesscontrolled"
+	"github.com/uber/cadence/service/frontend/wrappers/audited"
 	"github.com/uber/cadence/service/frontend/wrappers/clusterredirection"
 	"github.com/uber/cadence/service/frontend/wrappers/grpc"
 	"github.com/uber/cadence/service/frontend/wrappers/metered"
@@ -59,6 +62,9 @@ type Service struct {
 	config                 *config.Config
 	params                 *resource.Params
 	ratelimiterCollections globalRatelimiterCollections
+	auditSink              audit.Sink
+	// authorizer is created by the service if it's not in the params, and stopped with it
+	authorizer authorization.Authorizer
 }
 
 // NewService builds a new cadence-frontend service
@@ -158,7 +164,27 @@ func (s *Service) Start() {
 	if s.params.ClusterRedirectionPolicy != nil {
 		handler = clusterredirection.NewAPIHandler(handler, s, s.config, *s.params.ClusterRedirectionPolicy)
 	}
-	handler = accesscontrolled.NewAPIHandler(handler, s, s.params.Authorizer, s.params.AuthorizationConfig)
+	authorizer := s.params.Authorizer
+	if authorizer == nil {
+		authorizer, err = authorization.NewAuthorizer(s.params.AuthorizationConfig, logger, s.GetDomainCache())
+		if err != nil {
+			logger.Fatal("constructing authorizer", tag.Error(err))
+		}
+		s.authorizer = authorizer
+	}
+	handler = accesscontrolled.NewAPIHandler(handler, s, authorizer, s.params.AuthorizationConfig)
+	auditSink, err := audit.NewSink(s.params.AuditConfig, s.GetMessagingClient(), s.GetPersistenceBean().GetAuditQueueManager())
+	if err != nil {
+		logger.Fatal("constructing audit sink", tag.Error(err))
+	}
+	if auditSink != nil {
+		s.auditSink = audit.NewAsyncSink(auditSink, s.params.AuditConfig, logger)
+		principalResolver, err := authorization.NewPrincipalResolver(s.params.AuthorizationConfig, logger)
+		if err != nil {
+			logger.Fatal("constructing audit principal resolver", tag.Error(err))
+		}
+		handler = audited.NewAPIHandler(handler, s.auditSink, principalResolver, s.params.AuditConfig.APIs, logger, s.GetTimeSource())
+	}
 
 	// Register the latest (most decorated) handler
 	thriftHandler := thrift.NewAPIHandler(handler)
@@ -168,7 +194,7 @@ func (s *Service) Start() {
 	grpcHandler.Register(s.GetDispatcher())
 
 	s.adminHandler = admin.NewHandler(s, s.params, s.config, dh)
-	s.adminHandler = accesscontrolled.NewAdminHandler(s.adminHandler, s, s.params.Authorizer, s.params.AuthorizationConfig)
+	s.adminHandler = accesscontrolled.NewAdminHandler(s.adminHandler, s, authorizer, s.params.AuthorizationConfig)
 
 	adminThriftHandler := thrift.NewAdminHandler(s.adminHandler)
 	adminThriftHandler.Register(s.GetDispatcher())
@@ -322,6 +348,15 @@ func (s *Service) Stop() {
 	s.GetLogger().Info("ShutdownHandler: Draining traffic")
 	time.Sleep(requestDrainTime)
 
+	if s.auditSink != nil {
+		if err := s.auditSink.Close(); err != nil {
+			s.GetLogger().Error("failed to close audit sink", tag.Error(err))
+		}
+	}
+	if s.authorizer != nil {
+		authorization.StopAuthorizer(s.authorizer)
+	}
+
 	close(s.stopC)
 	s.Resource.Stop()
 	s.params.Logger.Info("frontend stopped")
@@ -0,0 +1,707 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audited
+
+// Code generated by gowrap. DO NOT EDIT.
+// template: ../../templates/audited.tmpl
+// gowrap: http://github.com/hexdigest/gowrap
+
+import (
+	"context"
+
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/frontend/api"
+)
+
+// apiHandler frontend handler wrapper for auditing the API calls
+type apiHandler struct {
+	handler           api.Handler
+	sink              audit.Sink
+	principalResolver authorization.PrincipalResolver
+	apis              map[string]struct{}
+	logger            log.Logger
+	timeSource        clock.TimeSource
+}
+
+// NewAPIHandler creates frontend handler which records the calls of the audited APIs to the sink,
+// the APIs which change a workflow or a domain are audited if apis is empty
+func NewAPIHandler(handler api.Handler, sink audit.Sink, principalResolver authorization.PrincipalResolver, apis []string, logger log.Logger, timeSource clock.TimeSource) api.Handler {
+	if len(apis) == 0 {
+		apis = audit.DefaultAPIs
+	}
+	auditedAPIs := make(map[string]struct{}, len(apis))
+	for _, name := range apis {
+		auditedAPIs[name] = struct{}{}
+	}
+	return &apiHandler{
+		handler:           handler,
+		sink:              sink,
+		principalResolver: principalResolver,
+		apis:              auditedAPIs,
+		logger:            logger,
+		timeSource:        timeSource,
+	}
+}
+
+func (a *apiHandler) CountWorkflowExecutions(ctx context.Context, cp1 *types.CountWorkflowExecutionsRequest) (cp2 *types.CountWorkflowExecutionsResponse, err error) {
+	if !a.isAudited("CountWorkflowExecutions") {
+		return a.handler.CountWorkflowExecutions(ctx, cp1)
+	}
+	entry := &audit.Entry{
+		API:    "CountWorkflowExecutions",
+		Domain: cp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.CountWorkflowExecutions(ctx, cp1)
+}
+
+func (a *apiHandler) DeprecateDomain(ctx context.Context, dp1 *types.DeprecateDomainRequest) (err error) {
+	if !a.isAudited("DeprecateDomain") {
+		return a.handler.DeprecateDomain(ctx, dp1)
+	}
+	entry := &audit.Entry{
+		API:    "DeprecateDomain",
+		Domain: dp1.GetName(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.DeprecateDomain(ctx, dp1)
+}
+
+func (a *apiHandler) DescribeDomain(ctx context.Context, dp1 *types.DescribeDomainRequest) (dp2 *types.DescribeDomainResponse, err error) {
+	if !a.isAudited("DescribeDomain") {
+		return a.handler.DescribeDomain(ctx, dp1)
+	}
+	entry := &audit.Entry{
+		API:    "DescribeDomain",
+		Domain: dp1.GetName(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.DescribeDomain(ctx, dp1)
+}
+
+func (a *apiHandler) DescribeTaskList(ctx context.Context, dp1 *types.DescribeTaskListRequest) (dp2 *types.DescribeTaskListResponse, err error) {
+	if !a.isAudited("DescribeTaskList") {
+		return a.handler.DescribeTaskList(ctx, dp1)
+	}
+	entry := &audit.Entry{
+		API:    "DescribeTaskList",
+		Domain: dp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.DescribeTaskList(ctx, dp1)
+}
+
+func (a *apiHandler) DescribeWorkflowExecution(ctx context.Context, dp1 *types.DescribeWorkflowExecutionRequest) (dp2 *types.DescribeWorkflowExecutionResponse, err error) {
+	if !a.isAudited("DescribeWorkflowExecution") {
+		return a.handler.DescribeWorkflowExecution(ctx, dp1)
+	}
+	entry := &audit.Entry{
+		API:        "DescribeWorkflowExecution",
+		Domain:     dp1.GetDomain(),
+		WorkflowID: dp1.GetExecution().GetWorkflowID(),
+		RunID:      dp1.GetExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.DescribeWorkflowExecution(ctx, dp1)
+}
+
+func (a *apiHandler) GetClusterInfo(ctx context.Context) (cp1 *types.ClusterInfo, err error) {
+	if !a.isAudited("GetClusterInfo") {
+		return a.handler.GetClusterInfo(ctx)
+	}
+	entry := &audit.Entry{
+		API: "GetClusterInfo",
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.GetClusterInfo(ctx)
+}
+
+func (a *apiHandler) GetSearchAttributes(ctx context.Context) (gp1 *types.GetSearchAttributesResponse, err error) {
+	if !a.isAudited("GetSearchAttributes") {
+		return a.handler.GetSearchAttributes(ctx)
+	}
+	entry := &audit.Entry{
+		API: "GetSearchAttributes",
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.GetSearchAttributes(ctx)
+}
+
+func (a *apiHandler) GetTaskListsByDomain(ctx context.Context, gp1 *types.GetTaskListsByDomainRequest) (gp2 *types.GetTaskListsByDomainResponse, err error) {
+	if !a.isAudited("GetTaskListsByDomain") {
+		return a.handler.GetTaskListsByDomain(ctx, gp1)
+	}
+	entry := &audit.Entry{
+		API:    "GetTaskListsByDomain",
+		Domain: gp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.GetTaskListsByDomain(ctx, gp1)
+}
+
+func (a *apiHandler) GetWorkflowExecutionHistory(ctx context.Context, gp1 *types.GetWorkflowExecutionHistoryRequest) (gp2 *types.GetWorkflowExecutionHistoryResponse, err error) {
+	if !a.isAudited("GetWorkflowExecutionHistory") {
+		return a.handler.GetWorkflowExecutionHistory(ctx, gp1)
+	}
+	entry := &audit.Entry{
+		API:        "GetWorkflowExecutionHistory",
+		Domain:     gp1.GetDomain(),
+		WorkflowID: gp1.GetExecution().GetWorkflowID(),
+		RunID:      gp1.GetExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.GetWorkflowExecutionHistory(ctx, gp1)
+}
+
+func (a *apiHandler) Health(ctx context.Context) (hp1 *types.HealthStatus, err error) {
+	return a.handler.Health(ctx)
+}
+
+func (a *apiHandler) ListArchivedWorkflowExecutions(ctx context.Context, lp1 *types.ListArchivedWorkflowExecutionsRequest) (lp2 *types.ListArchivedWorkflowExecutionsResponse, err error) {
+	if !a.isAudited("ListArchivedWorkflowExecutions") {
+		return a.handler.ListArchivedWorkflowExecutions(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API:    "ListArchivedWorkflowExecutions",
+		Domain: lp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ListArchivedWorkflowExecutions(ctx, lp1)
+}
+
+func (a *apiHandler) ListClosedWorkflowExecutions(ctx context.Context, lp1 *types.ListClosedWorkflowExecutionsRequest) (lp2 *types.ListClosedWorkflowExecutionsResponse, err error) {
+	if !a.isAudited("ListClosedWorkflowExecutions") {
+		return a.handler.ListClosedWorkflowExecutions(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API:    "ListClosedWorkflowExecutions",
+		Domain: lp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ListClosedWorkflowExecutions(ctx, lp1)
+}
+
+func (a *apiHandler) ListDomains(ctx context.Context, lp1 *types.ListDomainsRequest) (lp2 *types.ListDomainsResponse, err error) {
+	if !a.isAudited("ListDomains") {
+		return a.handler.ListDomains(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API: "ListDomains",
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ListDomains(ctx, lp1)
+}
+
+func (a *apiHandler) ListOpenWorkflowExecutions(ctx context.Context, lp1 *types.ListOpenWorkflowExecutionsRequest) (lp2 *types.ListOpenWorkflowExecutionsResponse, err error) {
+	if !a.isAudited("ListOpenWorkflowExecutions") {
+		return a.handler.ListOpenWorkflowExecutions(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API:    "ListOpenWorkflowExecutions",
+		Domain: lp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ListOpenWorkflowExecutions(ctx, lp1)
+}
+
+func (a *apiHandler) ListTaskListPartitions(ctx context.Context, lp1 *types.ListTaskListPartitionsRequest) (lp2 *types.ListTaskListPartitionsResponse, err error) {
+	if !a.isAudited("ListTaskListPartitions") {
+		return a.handler.ListTaskListPartitions(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API:    "ListTaskListPartitions",
+		Domain: lp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ListTaskListPartitions(ctx, lp1)
+}
+
+func (a *apiHandler) ListWorkflowExecutions(ctx context.Context, lp1 *types.ListWorkflowExecutionsRequest) (lp2 *types.ListWorkflowExecutionsResponse, err error) {
+	if !a.isAudited("ListWorkflowExecutions") {
+		return a.handler.ListWorkflowExecutions(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API:    "ListWorkflowExecutions",
+		Domain: lp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ListWorkflowExecutions(ctx, lp1)
+}
+
+func (a *apiHandler) PollForActivityTask(ctx context.Context, pp1 *types.PollForActivityTaskRequest) (pp2 *types.PollForActivityTaskResponse, err error) {
+	if !a.isAudited("PollForActivityTask") {
+		return a.handler.PollForActivityTask(ctx, pp1)
+	}
+	entry := &audit.Entry{
+		API:      "PollForActivityTask",
+		Domain:   pp1.GetDomain(),
+		Identity: pp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.PollForActivityTask(ctx, pp1)
+}
+
+func (a *apiHandler) PollForDecisionTask(ctx context.Context, pp1 *types.PollForDecisionTaskRequest) (pp2 *types.PollForDecisionTaskResponse, err error) {
+	if !a.isAudited("PollForDecisionTask") {
+		return a.handler.PollForDecisionTask(ctx, pp1)
+	}
+	entry := &audit.Entry{
+		API:      "PollForDecisionTask",
+		Domain:   pp1.GetDomain(),
+		Identity: pp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.PollForDecisionTask(ctx, pp1)
+}
+
+func (a *apiHandler) QueryWorkflow(ctx context.Context, qp1 *types.QueryWorkflowRequest) (qp2 *types.QueryWorkflowResponse, err error) {
+	if !a.isAudited("QueryWorkflow") {
+		return a.handler.QueryWorkflow(ctx, qp1)
+	}
+	entry := &audit.Entry{
+		API:        "QueryWorkflow",
+		Domain:     qp1.GetDomain(),
+		WorkflowID: qp1.GetExecution().GetWorkflowID(),
+		RunID:      qp1.GetExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.QueryWorkflow(ctx, qp1)
+}
+
+func (a *apiHandler) RecordActivityTaskHeartbeat(ctx context.Context, rp1 *types.RecordActivityTaskHeartbeatRequest) (rp2 *types.RecordActivityTaskHeartbeatResponse, err error) {
+	if !a.isAudited("RecordActivityTaskHeartbeat") {
+		return a.handler.RecordActivityTaskHeartbeat(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API: "RecordActivityTaskHeartbeat",
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RecordActivityTaskHeartbeat(ctx, rp1)
+}
+
+func (a *apiHandler) RecordActivityTaskHeartbeatByID(ctx context.Context, rp1 *types.RecordActivityTaskHeartbeatByIDRequest) (rp2 *types.RecordActivityTaskHeartbeatResponse, err error) {
+	if !a.isAudited("RecordActivityTaskHeartbeatByID") {
+		return a.handler.RecordActivityTaskHeartbeatByID(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RecordActivityTaskHeartbeatByID",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RecordActivityTaskHeartbeatByID(ctx, rp1)
+}
+
+func (a *apiHandler) RefreshWorkflowTasks(ctx context.Context, rp1 *types.RefreshWorkflowTasksRequest) (err error) {
+	if !a.isAudited("RefreshWorkflowTasks") {
+		return a.handler.RefreshWorkflowTasks(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RefreshWorkflowTasks",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetExecution().GetWorkflowID(),
+		RunID:      rp1.GetExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RefreshWorkflowTasks(ctx, rp1)
+}
+
+func (a *apiHandler) RegisterDomain(ctx context.Context, rp1 *types.RegisterDomainRequest) (err error) {
+	if !a.isAudited("RegisterDomain") {
+		return a.handler.RegisterDomain(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:    "RegisterDomain",
+		Domain: rp1.GetName(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RegisterDomain(ctx, rp1)
+}
+
+func (a *apiHandler) RequestCancelWorkflowExecution(ctx context.Context, rp1 *types.RequestCancelWorkflowExecutionRequest) (err error) {
+	if !a.isAudited("RequestCancelWorkflowExecution") {
+		return a.handler.RequestCancelWorkflowExecution(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RequestCancelWorkflowExecution",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowExecution().GetWorkflowID(),
+		RunID:      rp1.GetWorkflowExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RequestCancelWorkflowExecution(ctx, rp1)
+}
+
+func (a *apiHandler) ResetStickyTaskList(ctx context.Context, rp1 *types.ResetStickyTaskListRequest) (rp2 *types.ResetStickyTaskListResponse, err error) {
+	if !a.isAudited("ResetStickyTaskList") {
+		return a.handler.ResetStickyTaskList(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "ResetStickyTaskList",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetExecution().GetWorkflowID(),
+		RunID:      rp1.GetExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ResetStickyTaskList(ctx, rp1)
+}
+
+func (a *apiHandler) ResetWorkflowExecution(ctx context.Context, rp1 *types.ResetWorkflowExecutionRequest) (rp2 *types.ResetWorkflowExecutionResponse, err error) {
+	if !a.isAudited("ResetWorkflowExecution") {
+		return a.handler.ResetWorkflowExecution(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "ResetWorkflowExecution",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowExecution().GetWorkflowID(),
+		RunID:      rp1.GetWorkflowExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ResetWorkflowExecution(ctx, rp1)
+}
+
+func (a *apiHandler) RespondActivityTaskCanceled(ctx context.Context, rp1 *types.RespondActivityTaskCanceledRequest) (err error) {
+	if !a.isAudited("RespondActivityTaskCanceled") {
+		return a.handler.RespondActivityTaskCanceled(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:      "RespondActivityTaskCanceled",
+		Identity: rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondActivityTaskCanceled(ctx, rp1)
+}
+
+func (a *apiHandler) RespondActivityTaskCanceledByID(ctx context.Context, rp1 *types.RespondActivityTaskCanceledByIDRequest) (err error) {
+	if !a.isAudited("RespondActivityTaskCanceledByID") {
+		return a.handler.RespondActivityTaskCanceledByID(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RespondActivityTaskCanceledByID",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowID(),
+		Identity:   rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondActivityTaskCanceledByID(ctx, rp1)
+}
+
+func (a *apiHandler) RespondActivityTaskCompleted(ctx context.Context, rp1 *types.RespondActivityTaskCompletedRequest) (err error) {
+	if !a.isAudited("RespondActivityTaskCompleted") {
+		return a.handler.RespondActivityTaskCompleted(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:      "RespondActivityTaskCompleted",
+		Identity: rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondActivityTaskCompleted(ctx, rp1)
+}
+
+func (a *apiHandler) RespondActivityTaskCompletedByID(ctx context.Context, rp1 *types.RespondActivityTaskCompletedByIDRequest) (err error) {
+	if !a.isAudited("RespondActivityTaskCompletedByID") {
+		return a.handler.RespondActivityTaskCompletedByID(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RespondActivityTaskCompletedByID",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowID(),
+		Identity:   rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondActivityTaskCompletedByID(ctx, rp1)
+}
+
+func (a *apiHandler) RespondActivityTaskFailed(ctx context.Context, rp1 *types.RespondActivityTaskFailedRequest) (err error) {
+	if !a.isAudited("RespondActivityTaskFailed") {
+		return a.handler.RespondActivityTaskFailed(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:      "RespondActivityTaskFailed",
+		Identity: rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondActivityTaskFailed(ctx, rp1)
+}
+
+func (a *apiHandler) RespondActivityTaskFailedByID(ctx context.Context, rp1 *types.RespondActivityTaskFailedByIDRequest) (err error) {
+	if !a.isAudited("RespondActivityTaskFailedByID") {
+		return a.handler.RespondActivityTaskFailedByID(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RespondActivityTaskFailedByID",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowID(),
+		Identity:   rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondActivityTaskFailedByID(ctx, rp1)
+}
+
+func (a *apiHandler) RespondDecisionTaskCompleted(ctx context.Context, rp1 *types.RespondDecisionTaskCompletedRequest) (rp2 *types.RespondDecisionTaskCompletedResponse, err error) {
+	if !a.isAudited("RespondDecisionTaskCompleted") {
+		return a.handler.RespondDecisionTaskCompleted(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:      "RespondDecisionTaskCompleted",
+		Identity: rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondDecisionTaskCompleted(ctx, rp1)
+}
+
+func (a *apiHandler) RespondDecisionTaskFailed(ctx context.Context, rp1 *types.RespondDecisionTaskFailedRequest) (err error) {
+	if !a.isAudited("RespondDecisionTaskFailed") {
+		return a.handler.RespondDecisionTaskFailed(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:      "RespondDecisionTaskFailed",
+		Identity: rp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondDecisionTaskFailed(ctx, rp1)
+}
+
+func (a *apiHandler) RespondQueryTaskCompleted(ctx context.Context, rp1 *types.RespondQueryTaskCompletedRequest) (err error) {
+	if !a.isAudited("RespondQueryTaskCompleted") {
+		return a.handler.RespondQueryTaskCompleted(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API: "RespondQueryTaskCompleted",
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RespondQueryTaskCompleted(ctx, rp1)
+}
+
+func (a *apiHandler) RestartWorkflowExecution(ctx context.Context, rp1 *types.RestartWorkflowExecutionRequest) (rp2 *types.RestartWorkflowExecutionResponse, err error) {
+	if !a.isAudited("RestartWorkflowExecution") {
+		return a.handler.RestartWorkflowExecution(ctx, rp1)
+	}
+	entry := &audit.Entry{
+		API:        "RestartWorkflowExecution",
+		Domain:     rp1.GetDomain(),
+		WorkflowID: rp1.GetWorkflowExecution().GetWorkflowID(),
+		RunID:      rp1.GetWorkflowExecution().GetRunID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.RestartWorkflowExecution(ctx, rp1)
+}
+
+func (a *apiHandler) ScanWorkflowExecutions(ctx context.Context, lp1 *types.ListWorkflowExecutionsRequest) (lp2 *types.ListWorkflowExecutionsResponse, err error) {
+	if !a.isAudited("ScanWorkflowExecutions") {
+		return a.handler.ScanWorkflowExecutions(ctx, lp1)
+	}
+	entry := &audit.Entry{
+		API:    "ScanWorkflowExecutions",
+		Domain: lp1.GetDomain(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.ScanWorkflowExecutions(ctx, lp1)
+}
+
+func (a *apiHandler) SignalWithStartWorkflowExecution(ctx context.Context, sp1 *types.SignalWithStartWorkflowExecutionRequest) (sp2 *types.StartWorkflowExecutionResponse, err error) {
+	if !a.isAudited("SignalWithStartWorkflowExecution") {
+		return a.handler.SignalWithStartWorkflowExecution(ctx, sp1)
+	}
+	entry := &audit.Entry{
+		API:        "SignalWithStartWorkflowExecution",
+		Domain:     sp1.GetDomain(),
+		WorkflowID: sp1.GetWorkflowID(),
+		Identity:   sp1.GetIdentity(),
+	}
+	defer func() {
+		entry.RunID = sp2.GetRunID()
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.SignalWithStartWorkflowExecution(ctx, sp1)
+}
+
+func (a *apiHandler) SignalWithStartWorkflowExecutionAsync(ctx context.Context, sp1 *types.SignalWithStartWorkflowExecutionAsyncRequest) (sp2 *types.SignalWithStartWorkflowExecutionAsyncResponse, err error) {
+	if !a.isAudited("SignalWithStartWorkflowExecutionAsync") {
+		return a.handler.SignalWithStartWorkflowExecutionAsync(ctx, sp1)
+	}
+	entry := &audit.Entry{
+		API:        "SignalWithStartWorkflowExecutionAsync",
+		Domain:     sp1.GetDomain(),
+		WorkflowID: sp1.GetWorkflowID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.SignalWithStartWorkflowExecutionAsync(ctx, sp1)
+}
+
+func (a *apiHandler) SignalWorkflowExecution(ctx context.Context, sp1 *types.SignalWorkflowExecutionRequest) (err error) {
+	if !a.isAudited("SignalWorkflowExecution") {
+		return a.handler.SignalWorkflowExecution(ctx, sp1)
+	}
+	entry := &audit.Entry{
+		API:        "SignalWorkflowExecution",
+		Domain:     sp1.GetDomain(),
+		WorkflowID: sp1.GetWorkflowExecution().GetWorkflowID(),
+		RunID:      sp1.GetWorkflowExecution().GetRunID(),
+		Identity:   sp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.SignalWorkflowExecution(ctx, sp1)
+}
+
+func (a *apiHandler) StartWorkflowExecution(ctx context.Context, sp1 *types.StartWorkflowExecutionRequest) (sp2 *types.StartWorkflowExecutionResponse, err error) {
+	if !a.isAudited("StartWorkflowExecution") {
+		return a.handler.StartWorkflowExecution(ctx, sp1)
+	}
+	entry := &audit.Entry{
+		API:        "StartWorkflowExecution",
+		Domain:     sp1.GetDomain(),
+		WorkflowID: sp1.GetWorkflowID(),
+	}
+	defer func() {
+		entry.RunID = sp2.GetRunID()
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.StartWorkflowExecution(ctx, sp1)
+}
+
+func (a *apiHandler) StartWorkflowExecutionAsync(ctx context.Context, sp1 *types.StartWorkflowExecutionAsyncRequest) (sp2 *types.StartWorkflowExecutionAsyncResponse, err error) {
+	if !a.isAudited("StartWorkflowExecutionAsync") {
+		return a.handler.StartWorkflowExecutionAsync(ctx, sp1)
+	}
+	entry := &audit.Entry{
+		API:        "StartWorkflowExecutionAsync",
+		Domain:     sp1.GetDomain(),
+		WorkflowID: sp1.GetWorkflowID(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.StartWorkflowExecutionAsync(ctx, sp1)
+}
+
+func (a *apiHandler) TerminateWorkflowExecution(ctx context.Context, tp1 *types.TerminateWorkflowExecutionRequest) (err error) {
+	if !a.isAudited("TerminateWorkflowExecution") {
+		return a.handler.TerminateWorkflowExecution(ctx, tp1)
+	}
+	entry := &audit.Entry{
+		API:        "TerminateWorkflowExecution",
+		Domain:     tp1.GetDomain(),
+		WorkflowID: tp1.GetWorkflowExecution().GetWorkflowID(),
+		RunID:      tp1.GetWorkflowExecution().GetRunID(),
+		Identity:   tp1.GetIdentity(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.TerminateWorkflowExecution(ctx, tp1)
+}
+
+func (a *apiHandler) UpdateDomain(ctx context.Context, up1 *types.UpdateDomainRequest) (up2 *types.UpdateDomainResponse, err error) {
+	if !a.isAudited("UpdateDomain") {
+		return a.handler.UpdateDomain(ctx, up1)
+	}
+	entry := &audit.Entry{
+		API:    "UpdateDomain",
+		Domain: up1.GetName(),
+	}
+	defer func() {
+		a.record(ctx, entry, err)
+	}()
+	return a.handler.UpdateDomain(ctx, up1)
+}
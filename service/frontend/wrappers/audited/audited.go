@@ -0,0 +1,66 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audited
+
+import (
+	"context"
+
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/types"
+)
+
+func (a *apiHandler) isAudited(api string) bool {
+	_, ok := a.apis[api]
+	return ok
+}
+
+// record completes the entry with the caller and the outcome of the call, and writes it to the sink.
+// Failing to write the entry doesn't fail the call. The sink is expected to be an async sink, which only
+// makes the call wait when its buffer is full and bounds the wait, so the write doesn't depend on the context of the call.
+func (a *apiHandler) record(ctx context.Context, entry *audit.Entry, err error) {
+	entry.Timestamp = a.timeSource.Now()
+	if principal, ok := a.principalResolver.GetPrincipal(ctx); ok {
+		entry.Actor = principal.Actor
+		entry.Groups = principal.Groups
+		entry.PrincipalSource = principal.Source
+	}
+	switch err.(type) {
+	case nil:
+		entry.Outcome = audit.OutcomeSuccess
+	case *types.AccessDeniedError:
+		entry.Outcome = audit.OutcomeAccessDenied
+		entry.Error = err.Error()
+	default:
+		entry.Outcome = audit.OutcomeError
+		entry.Error = err.Error()
+	}
+
+	if err := a.sink.Write(context.Background(), entry); err != nil {
+		a.logger.Error("Failed to write audit entry",
+			tag.WorkflowHandlerName(entry.API),
+			tag.WorkflowDomainName(entry.Domain),
+			tag.WorkflowID(entry.WorkflowID),
+			tag.Error(err))
+	}
+}
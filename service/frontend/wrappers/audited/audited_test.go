@@ -0,0 +1,247 @@
+// The MIT License (MIT)
+
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package audited
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/frontend/api"
+)
+
+type fakePrincipalResolver struct {
+	principal *authorization.Principal
+}
+
+func (r fakePrincipalResolver) GetPrincipal(context.Context) (authorization.Principal, bool) {
+	if r.principal == nil {
+		return authorization.Principal{}, false
+	}
+	return *r.principal, true
+}
+
+func TestAPIHandler(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	principal := &authorization.Principal{Actor: "worker", Groups: []string{"ops"}, Source: authorization.PrincipalSourceMTLS}
+	execution := &types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"}
+
+	testCases := []struct {
+		name      string
+		principal *authorization.Principal
+		apis      []string
+		call      func(api.Handler) error
+		mockSetup func(*api.MockHandler)
+		entry     *audit.Entry
+	}{
+		{
+			name:      "successful terminate",
+			principal: principal,
+			call: func(h api.Handler) error {
+				return h.TerminateWorkflowExecution(context.Background(), &types.TerminateWorkflowExecutionRequest{
+					Domain:            "test-domain",
+					WorkflowExecution: execution,
+					Identity:          "self-reported",
+				})
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			entry: &audit.Entry{
+				Timestamp:       now,
+				Actor:           "worker",
+				Groups:          []string{"ops"},
+				PrincipalSource: authorization.PrincipalSourceMTLS,
+				Identity:        "self-reported",
+				API:             "TerminateWorkflowExecution",
+				Domain:          "test-domain",
+				WorkflowID:      "wid",
+				RunID:           "rid",
+				Outcome:         audit.OutcomeSuccess,
+			},
+		},
+		{
+			name: "denied signal of an unauthenticated caller",
+			call: func(h api.Handler) error {
+				return h.SignalWorkflowExecution(context.Background(), &types.SignalWorkflowExecutionRequest{
+					Domain:            "test-domain",
+					WorkflowExecution: execution,
+				})
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any()).Return(&types.AccessDeniedError{Message: "denied"})
+			},
+			entry: &audit.Entry{
+				Timestamp:  now,
+				API:        "SignalWorkflowExecution",
+				Domain:     "test-domain",
+				WorkflowID: "wid",
+				RunID:      "rid",
+				Outcome:    audit.OutcomeAccessDenied,
+				Error:      "denied",
+			},
+		},
+		{
+			name:      "failed start records the workflow ID",
+			principal: principal,
+			call: func(h api.Handler) error {
+				_, err := h.StartWorkflowExecution(context.Background(), &types.StartWorkflowExecutionRequest{
+					Domain:     "test-domain",
+					WorkflowID: "wid",
+				})
+				return err
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).Return(nil, errors.New("some random error"))
+			},
+			entry: &audit.Entry{
+				Timestamp:       now,
+				Actor:           "worker",
+				Groups:          []string{"ops"},
+				PrincipalSource: authorization.PrincipalSourceMTLS,
+				API:             "StartWorkflowExecution",
+				Domain:          "test-domain",
+				WorkflowID:      "wid",
+				Outcome:         audit.OutcomeError,
+				Error:           "some random error",
+			},
+		},
+		{
+			name:      "start records the run ID of the response",
+			principal: principal,
+			call: func(h api.Handler) error {
+				_, err := h.StartWorkflowExecution(context.Background(), &types.StartWorkflowExecutionRequest{
+					Domain:     "test-domain",
+					WorkflowID: "wid",
+				})
+				return err
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).Return(&types.StartWorkflowExecutionResponse{RunID: "rid"}, nil)
+			},
+			entry: &audit.Entry{
+				Timestamp:       now,
+				Actor:           "worker",
+				Groups:          []string{"ops"},
+				PrincipalSource: authorization.PrincipalSourceMTLS,
+				API:             "StartWorkflowExecution",
+				Domain:          "test-domain",
+				WorkflowID:      "wid",
+				RunID:           "rid",
+				Outcome:         audit.OutcomeSuccess,
+			},
+		},
+		{
+			name: "read APIs are not audited by default",
+			call: func(h api.Handler) error {
+				_, err := h.DescribeWorkflowExecution(context.Background(), &types.DescribeWorkflowExecutionRequest{
+					Domain:    "test-domain",
+					Execution: execution,
+				})
+				return err
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(&types.DescribeWorkflowExecutionResponse{}, nil)
+			},
+		},
+		{
+			name: "configured APIs are audited",
+			apis: []string{"DescribeWorkflowExecution"},
+			call: func(h api.Handler) error {
+				_, err := h.DescribeWorkflowExecution(context.Background(), &types.DescribeWorkflowExecutionRequest{
+					Domain:    "test-domain",
+					Execution: execution,
+				})
+				return err
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(&types.DescribeWorkflowExecutionResponse{}, nil)
+			},
+			entry: &audit.Entry{
+				Timestamp:  now,
+				API:        "DescribeWorkflowExecution",
+				Domain:     "test-domain",
+				WorkflowID: "wid",
+				RunID:      "rid",
+				Outcome:    audit.OutcomeSuccess,
+			},
+		},
+		{
+			name: "domain APIs record the domain name",
+			call: func(h api.Handler) error {
+				return h.DeprecateDomain(context.Background(), &types.DeprecateDomainRequest{Name: "test-domain"})
+			},
+			mockSetup: func(h *api.MockHandler) {
+				h.EXPECT().DeprecateDomain(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			entry: &audit.Entry{
+				Timestamp: now,
+				API:       "DeprecateDomain",
+				Domain:    "test-domain",
+				Outcome:   audit.OutcomeSuccess,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			mockHandler := api.NewMockHandler(controller)
+			mockSink := audit.NewMockSink(controller)
+			tc.mockSetup(mockHandler)
+			if tc.entry != nil {
+				mockSink.EXPECT().Write(gomock.Any(), tc.entry).Return(nil)
+			}
+
+			handler := NewAPIHandler(mockHandler, mockSink, fakePrincipalResolver{tc.principal}, tc.apis, testlogger.New(t), clock.NewMockedTimeSourceAt(now))
+			err := tc.call(handler)
+			if tc.entry != nil && tc.entry.Outcome != audit.OutcomeSuccess {
+				assert.Error(t, err, "the error of the call is returned")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_SinkFailureDoesNotFailCall(t *testing.T) {
+	controller := gomock.NewController(t)
+	mockHandler := api.NewMockHandler(controller)
+	mockSink := audit.NewMockSink(controller)
+	mockHandler.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), gomock.Any()).Return(nil)
+	mockSink.EXPECT().Write(gomock.Any(), gomock.Any()).Return(errors.New("sink is down"))
+
+	handler := NewAPIHandler(mockHandler, mockSink, fakePrincipalResolver{}, nil, testlogger.New(t), clock.NewRealTimeSource())
+	err := handler.RequestCancelWorkflowExecution(context.Background(), &types.RequestCancelWorkflowExecutionRequest{Domain: "test-domain"})
+	require.NoError(t, err)
+}
@@ -33,6 +33,7 @@ import (
 type Config struct {
 	NumberOfShards                   int
 	IsAdvancedVisConfigExist         bool
+	IsPayloadOffloadEnabled          bool
 	RPS                              dynamicconfig.IntPropertyFn
 	MaxIDLengthWarnLimit             dynamicconfig.IntPropertyFn
 	DomainNameMaxLength              dynamicconfig.IntPropertyFnWithDomainFilter
@@ -97,6 +98,7 @@ type Config struct {
 	TaskSchedulerShardQueueSize             dynamicconfig.IntPropertyFn
 	TaskSchedulerDispatcherCount            dynamicconfig.IntPropertyFn
 	TaskSchedulerRoundRobinWeights          dynamicconfig.MapPropertyFn
+	TaskSchedulerIsolatedDomainWeight       dynamicconfig.IntPropertyFnWithDomainFilter
 	TaskCriticalRetryCount                  dynamicconfig.IntPropertyFn
 	ActiveTaskRedispatchInterval            dynamicconfig.DurationPropertyFn
 	StandbyTaskRedispatchInterval           dynamicconfig.DurationPropertyFn
@@ -120,6 +122,8 @@ type Config struct {
 	QueueProcessorEnablePersistQueueStates             dynamicconfig.BoolPropertyFn
 	QueueProcessorEnableLoadQueueStates                dynamicconfig.BoolPropertyFn
 	QueueProcessorEnableGracefulSyncShutdown           dynamicconfig.BoolPropertyFn
+	QueueProcessorIsolatedDomains                      dynamicconfig.MapPropertyFn
+	QueueProcessorIsolatedDomainRPS                    dynamicconfig.IntPropertyFnWithDomainFilter
 
 	// TimerQueueProcessor settings
 	TimerTaskBatchSize                                dynamicconfig.IntPropertyFn
@@ -237,6 +241,8 @@ type Config struct {
 	// Size limit related settings
 	BlobSizeLimitError               dynamicconfig.IntPropertyFnWithDomainFilter
 	BlobSizeLimitWarn                dynamicconfig.IntPropertyFnWithDomainFilter
+	OffloadedBlobSizeLimitError      dynamicconfig.IntPropertyFnWithDomainFilter
+	PayloadOffloadThreshold          dynamicconfig.IntPropertyFnWithDomainFilter
 	HistorySizeLimitError            dynamicconfig.IntPropertyFnWithDomainFilter
 	HistorySizeLimitWarn             dynamicconfig.IntPropertyFnWithDomainFilter
 	HistoryCountLimitError           dynamicconfig.IntPropertyFnWithDomainFilter
@@ -411,6 +417,7 @@ func New(dc *dynamicconfig.Collection, numberOfShards int, maxMessageSize int, s
 		TaskSchedulerShardQueueSize:             dc.GetIntProperty(dynamicconfig.TaskSchedulerShardQueueSize),
 		TaskSchedulerDispatcherCount:            dc.GetIntProperty(dynamicconfig.TaskSchedulerDispatcherCount),
 		TaskSchedulerRoundRobinWeights:          dc.GetMapProperty(dynamicconfig.TaskSchedulerRoundRobinWeights),
+		TaskSchedulerIsolatedDomainWeight:       dc.GetIntPropertyFilteredByDomain(dynamicconfig.TaskSchedulerIsolatedDomainWeight),
 		TaskCriticalRetryCount:                  dc.GetIntProperty(dynamicconfig.TaskCriticalRetryCount),
 		ActiveTaskRedispatchInterval:            dc.GetDurationProperty(dynamicconfig.ActiveTaskRedispatchInterval),
 		StandbyTaskRedispatchInterval:           dc.GetDurationProperty(dynamicconfig.StandbyTaskRedispatchInterval),
@@ -433,6 +440,8 @@ func New(dc *dynamicconfig.Collection, numberOfShards int, maxMessageSize int, s
 		QueueProcessorEnablePersistQueueStates:             dc.GetBoolProperty(dynamicconfig.QueueProcessorEnablePersistQueueStates),
 		QueueProcessorEnableLoadQueueStates:                dc.GetBoolProperty(dynamicconfig.QueueProcessorEnableLoadQueueStates),
 		QueueProcessorEnableGracefulSyncShutdown:           dc.GetBoolProperty(dynamicconfig.QueueProcessorEnableGracefulSyncShutdown),
+		QueueProcessorIsolatedDomains:                      dc.GetMapProperty(dynamicconfig.QueueProcessorIsolatedDomains),
+		QueueProcessorIsolatedDomainRPS:                    dc.GetIntPropertyFilteredByDomain(dynamicconfig.QueueProcessorIsolatedDomainRPS),
 
 		TimerTaskBatchSize:                                dc.GetIntProperty(dynamicconfig.TimerTaskBatchSize),
 		TimerTaskDeleteBatchSize:                          dc.GetIntProperty(dynamicconfig.TimerTaskDeleteBatchSize),
@@ -526,6 +535,8 @@ func New(dc *dynamicconfig.Collection, numberOfShards int, maxMessageSize int, s
 
 		BlobSizeLimitError:               dc.GetIntPropertyFilteredByDomain(dynamicconfig.BlobSizeLimitError),
 		BlobSizeLimitWarn:                dc.GetIntPropertyFilteredByDomain(dynamicconfig.BlobSizeLimitWarn),
+		OffloadedBlobSizeLimitError:      dc.GetIntPropertyFilteredByDomain(dynamicconfig.OffloadedBlobSizeLimitError),
+		PayloadOffloadThreshold:          dc.GetIntPropertyFilteredByDomain(dynamicconfig.PayloadOffloadThreshold),
 		HistorySizeLimitError:            dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistorySizeLimitError),
 		HistorySizeLimitWarn:             dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistorySizeLimitWarn),
 		HistoryCountLimitError:           dc.GetIntPropertyFilteredByDomain(dynamicconfig.HistoryCountLimitError),
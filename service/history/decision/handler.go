@@ -99,6 +99,16 @@ func NewHandler(
 	}
 }
 
+// blobSizeLimitError returns the per event blob size limit, large payloads don't count towards
+// the history size when they are offloaded to the blobstore, so a larger limit applies to them.
+// Events are still returned in RPC responses with their payloads, so the limit can't exceed MaxResponseSize.
+func (handler *handlerImpl) blobSizeLimitError(domainName string) int {
+	if handler.config.IsPayloadOffloadEnabled && handler.config.PayloadOffloadThreshold(domainName) > 0 {
+		return common.MinInt(handler.config.OffloadedBlobSizeLimitError(domainName), handler.config.MaxResponseSize)
+	}
+	return handler.config.BlobSizeLimitError(domainName)
+}
+
 func (handler *handlerImpl) HandleDecisionTaskScheduled(
 	ctx context.Context,
 	req *types.ScheduleDecisionTaskRequest,
@@ -426,7 +436,7 @@ Update_History_Loop:
 		} else {
 			workflowSizeChecker := newWorkflowSizeChecker(
 				handler.config.BlobSizeLimitWarn(domainName),
-				handler.config.BlobSizeLimitError(domainName),
+				handler.blobSizeLimitError(domainName),
 				handler.config.HistorySizeLimitWarn(domainName),
 				handler.config.HistorySizeLimitError(domainName),
 				handler.config.HistoryCountLimitWarn(domainName),
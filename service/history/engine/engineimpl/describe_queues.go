@@ -24,6 +24,7 @@ package engineimpl
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/service/history/queue"
@@ -57,6 +58,7 @@ func (e *historyEngineImpl) describeQueue(
 	for _, state := range resp.GetStateActionResult.States {
 		serializedStates = append(serializedStates, e.serializeQueueState(state))
 	}
+	serializedStates = append(serializedStates, e.describeIsolatedDomains()...)
 	return &types.DescribeQueueResponse{
 		ProcessingQueueStates: serializedStates,
 	}, nil
@@ -67,3 +69,32 @@ func (e *historyEngineImpl) serializeQueueState(
 ) string {
 	return fmt.Sprintf("%v", state)
 }
+
+// describeIsolatedDomains returns the virtual queue assignment of isolated domains,
+// the processing queues for a domain are the ones at the same level
+func (e *historyEngineImpl) describeIsolatedDomains() []string {
+	isolatedDomainLevels, err := queue.GetIsolatedDomainQueueLevels(e.config.QueueProcessorIsolatedDomains())
+	if err != nil {
+		return []string{fmt.Sprintf("invalid isolated domains config: %v", err)}
+	}
+
+	domainNames := make([]string, 0, len(isolatedDomainLevels))
+	for domainName := range isolatedDomainLevels {
+		domainNames = append(domainNames, domainName)
+	}
+	sort.Strings(domainNames)
+
+	descriptions := make([]string, 0, len(domainNames))
+	for _, domainName := range domainNames {
+		domainID, _ := e.shard.GetDomainCache().GetDomainID(domainName)
+		descriptions = append(descriptions, fmt.Sprintf(
+			"&{isolatedDomain: %v, domainID: %v, level: %v, schedulerWeight: %v, processRPS: %v}",
+			domainName,
+			domainID,
+			isolatedDomainLevels[domainName],
+			e.config.TaskSchedulerIsolatedDomainWeight(domainName),
+			e.config.QueueProcessorIsolatedDomainRPS(domainName),
+		))
+	}
+	return descriptions
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"fmt"
+
+	"github.com/uber/cadence/common/cache"
+)
+
+const (
+	// isolatedDomainQueueLevelBase is the processing queue level right below the first level
+	// for isolated domains. It's way above the max level of queue split, so that queues of
+	// isolated domains won't be mixed up with queues created by other split policies
+	isolatedDomainQueueLevelBase = 1000
+)
+
+// GetIsolatedDomainQueueLevels converts the value of history.queueProcessorIsolatedDomains
+// to a map from domain name to the level of the processing queue dedicated to the domain
+func GetIsolatedDomainQueueLevels(
+	isolatedDomains map[string]interface{},
+) (map[string]int, error) {
+	levels := make(map[string]int, len(isolatedDomains))
+	for domainName, value := range isolatedDomains {
+		var virtualQueueID int
+		switch value := value.(type) {
+		case float64:
+			virtualQueueID = int(value)
+		case int:
+			virtualQueueID = value
+		case int32:
+			virtualQueueID = int(value)
+		case int64:
+			virtualQueueID = int(value)
+		default:
+			return nil, fmt.Errorf("unknown virtual queue ID %v with type %T for domain %v", value, value, domainName)
+		}
+		if virtualQueueID <= 0 {
+			return nil, fmt.Errorf("virtual queue ID for domain %v must be positive, got %v", domainName, virtualQueueID)
+		}
+		levels[domainName] = isolatedDomainQueueLevelBase + virtualQueueID
+	}
+	return levels, nil
+}
+
+func isIsolatedDomainQueueLevel(level int) bool {
+	return level > isolatedDomainQueueLevelBase
+}
+
+// getIsolatedDomainIDQueueLevels is the same as GetIsolatedDomainQueueLevels
+// except that the keys of the result are domainIDs, domains can't be found are skipped
+func getIsolatedDomainIDQueueLevels(
+	isolatedDomains map[string]interface{},
+	domainCache cache.DomainCache,
+) (map[string]int, error) {
+	levels, err := GetIsolatedDomainQueueLevels(isolatedDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	domainIDLevels := make(map[string]int, len(levels))
+	for domainName, level := range levels {
+		domainID, err := domainCache.GetDomainID(domainName)
+		if err != nil {
+			// the domain may not exist or have been deleted, there's no task to isolate for it
+			continue
+		}
+		domainIDLevels[domainID] = level
+	}
+	return domainIDLevels, nil
+}
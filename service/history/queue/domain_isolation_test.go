@@ -0,0 +1,51 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIsolatedDomainQueueLevels(t *testing.T) {
+	levels, err := GetIsolatedDomainQueueLevels(map[string]interface{}{
+		"testDomain1": 1,
+		"testDomain2": float64(2),
+		"testDomain3": int64(1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"testDomain1": isolatedDomainQueueLevelBase + 1,
+		"testDomain2": isolatedDomainQueueLevelBase + 2,
+		"testDomain3": isolatedDomainQueueLevelBase + 1,
+	}, levels)
+	for _, level := range levels {
+		require.True(t, isIsolatedDomainQueueLevel(level))
+	}
+	require.False(t, isIsolatedDomainQueueLevel(defaultProcessingQueueLevel))
+
+	_, err = GetIsolatedDomainQueueLevels(map[string]interface{}{"testDomain1": 0})
+	require.Error(t, err)
+
+	_, err = GetIsolatedDomainQueueLevels(map[string]interface{}{"testDomain1": "1"})
+	require.Error(t, err)
+}
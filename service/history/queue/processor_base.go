@@ -180,12 +180,21 @@ func (p *processorBase) updateAckLevel() (bool, task.Key, error) {
 }
 
 func (p *processorBase) initializeSplitPolicy(lookAheadFunc lookAheadFunc) ProcessingQueueSplitPolicy {
+	// note the order of policies matters, check the comment for aggregated split policy
+	var policies []ProcessingQueueSplitPolicy
+
+	// domain isolation is explicitly configured, so it's applied even if queue split is disabled
+	if domainIsolationPolicy := p.initializeDomainIsolationSplitPolicy(); domainIsolationPolicy != nil {
+		policies = append(policies, domainIsolationPolicy)
+	}
+
 	if !p.options.EnableSplit() {
-		return nil
+		if len(policies) == 0 {
+			return nil
+		}
+		return NewAggregatedSplitPolicy(policies...)
 	}
 
-	// note the order of policies matters, check the comment for aggregated split policy
-	var policies []ProcessingQueueSplitPolicy
 	maxNewQueueLevel := p.options.SplitMaxLevel()
 
 	pendingTaskThresholds, err := common.ConvertDynamicConfigMapPropertyToIntMap(p.options.PendingTaskSplitThreshold())
@@ -234,6 +243,40 @@ func (p *processorBase) initializeSplitPolicy(lookAheadFunc lookAheadFunc) Proce
 	return NewAggregatedSplitPolicy(policies...)
 }
 
+func (p *processorBase) initializeDomainIsolationSplitPolicy() ProcessingQueueSplitPolicy {
+	if p.options.IsolatedDomains == nil {
+		// domain isolation is not supported, e.g. failover processor
+		return nil
+	}
+
+	isolatedDomainLevels, err := getIsolatedDomainIDQueueLevels(p.options.IsolatedDomains(), p.shard.GetDomainCache())
+	if err != nil {
+		p.logger.Error("Failed to convert isolated domains", tag.Error(err))
+		return nil
+	}
+
+	if len(isolatedDomainLevels) == 0 && !p.hasIsolatedDomainQueues() {
+		return nil
+	}
+
+	// the policy is still needed after all domains are removed from the config,
+	// so that they can be moved back from their dedicated queues
+	return NewDomainIsolationSplitPolicy(
+		isolatedDomainLevels,
+		p.logger,
+		p.metricsScope,
+	)
+}
+
+func (p *processorBase) hasIsolatedDomainQueues() bool {
+	for _, queueCollection := range p.processingQueueCollections {
+		if isIsolatedDomainQueueLevel(queueCollection.Level()) && len(queueCollection.Queues()) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *processorBase) splitProcessingQueueCollection(splitPolicy ProcessingQueueSplitPolicy, upsertPollTimeFn func(int, time.Time)) {
 	defer p.emitProcessingQueueMetrics()
 
@@ -45,6 +45,7 @@ type queueProcessorOptions struct {
 	EnableStuckTaskSplitByDomainID       dynamicconfig.BoolPropertyFnWithDomainIDFilter
 	StuckTaskSplitThreshold              dynamicconfig.MapPropertyFn
 	SplitLookAheadDurationByDomainID     dynamicconfig.DurationPropertyFnWithDomainIDFilter
+	IsolatedDomains                      dynamicconfig.MapPropertyFn
 	PollBackoffInterval                  dynamicconfig.DurationPropertyFn
 	PollBackoffIntervalJitterCoefficient dynamicconfig.FloatPropertyFn
 	EnablePersistQueueStates             dynamicconfig.BoolPropertyFn
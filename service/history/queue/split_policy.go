@@ -37,6 +37,7 @@ const (
 	policyTypeStuckTask
 	policyTypeSelectedDomain
 	policyTypeRandom
+	policyTypeDomainIsolation
 )
 
 type (
@@ -79,6 +80,13 @@ type (
 		metricsScope metrics.Scope
 	}
 
+	domainIsolationSplitPolicy struct {
+		isolatedDomainLevels map[string]int // domainID -> queue level
+
+		logger       log.Logger
+		metricsScope metrics.Scope
+	}
+
 	aggregatedSplitPolicy struct {
 		policies []ProcessingQueueSplitPolicy
 	}
@@ -158,6 +166,21 @@ func NewRandomSplitPolicy(
 	}
 }
 
+// NewDomainIsolationSplitPolicy creates a new processing queue split policy
+// that moves isolated domains to the queue level dedicated to them, and moves
+// domains that are no longer isolated back to the default queue level
+func NewDomainIsolationSplitPolicy(
+	isolatedDomainLevels map[string]int,
+	logger log.Logger,
+	metricsScope metrics.Scope,
+) ProcessingQueueSplitPolicy {
+	return &domainIsolationSplitPolicy{
+		isolatedDomainLevels: isolatedDomainLevels,
+		logger:               logger,
+		metricsScope:         metricsScope,
+	}
+}
+
 // NewAggregatedSplitPolicy creates a new processing queue split policy
 // that which combines other policies. Policies are evaluated in the order
 // they passed in, and if one policy returns an non-empty result, that result
@@ -171,8 +194,8 @@ func NewAggregatedSplitPolicy(policies ...ProcessingQueueSplitPolicy) Processing
 func (p *pendingTaskSplitPolicy) Evaluate(queue ProcessingQueue) []ProcessingQueueState {
 	queueImpl := queue.(*processingQueueImpl)
 
-	if queueImpl.state.level == p.maxNewQueueLevel {
-		// already reaches max level, skip splitting
+	if queueImpl.state.level >= p.maxNewQueueLevel {
+		// already reaches max level or is dedicated to isolated domains, skip splitting
 		return nil
 	}
 
@@ -220,8 +243,8 @@ func (p *pendingTaskSplitPolicy) Evaluate(queue ProcessingQueue) []ProcessingQue
 func (p *stuckTaskSplitPolicy) Evaluate(queue ProcessingQueue) []ProcessingQueueState {
 	queueImpl := queue.(*processingQueueImpl)
 
-	if queueImpl.state.level == p.maxNewQueueLevel {
-		// already reaches max level, skip splitting
+	if queueImpl.state.level >= p.maxNewQueueLevel {
+		// already reaches max level or is dedicated to isolated domains, skip splitting
 		return nil
 	}
 
@@ -304,11 +327,79 @@ func (p *selectedDomainSplitPolicy) Evaluate(queue ProcessingQueue) []Processing
 	}
 }
 
+func (p *domainIsolationSplitPolicy) Evaluate(queue ProcessingQueue) []ProcessingQueueState {
+	currentQueueState := queue.State()
+	currentLevel := currentQueueState.Level()
+	currentDomainFilter := currentQueueState.DomainFilter()
+
+	// queue level -> domainIDs to be moved to that level
+	domainsToMove := make(map[int]map[string]struct{})
+	if isIsolatedDomainQueueLevel(currentLevel) {
+		// queues for isolated domains are always created with an explicit list of domainIDs
+		for domainID := range currentDomainFilter.DomainIDs {
+			newLevel, ok := p.isolatedDomainLevels[domainID]
+			if !ok {
+				newLevel = defaultProcessingQueueLevel
+			}
+			if newLevel != currentLevel {
+				addDomainToLevel(domainsToMove, newLevel, domainID)
+			}
+		}
+	} else {
+		for domainID, newLevel := range p.isolatedDomainLevels {
+			if currentDomainFilter.Filter(domainID) {
+				addDomainToLevel(domainsToMove, newLevel, domainID)
+			}
+		}
+	}
+
+	if len(domainsToMove) == 0 {
+		// no split needed
+		return nil
+	}
+
+	domainIDs := make(map[string]struct{})
+	for _, domainIDsForLevel := range domainsToMove {
+		for domainID := range domainIDsForLevel {
+			domainIDs[domainID] = struct{}{}
+		}
+	}
+
+	p.logger.Info("Split processing queue",
+		tag.QueueLevel(currentLevel),
+		tag.WorkflowDomainIDs(domainIDs),
+		tag.QueueSplitPolicyType(policyTypeDomainIsolation),
+	)
+	p.metricsScope.IncCounter(metrics.ProcessingQueueDomainIsolationSplitCounter)
+
+	var newQueueStates []ProcessingQueueState
+	remainingDomainFilter := currentDomainFilter.Exclude(domainIDs)
+	if remainingDomainFilter.ReverseMatch || len(remainingDomainFilter.DomainIDs) != 0 {
+		newQueueStates = append(newQueueStates, newProcessingQueueState(
+			currentLevel,
+			currentQueueState.AckLevel(),
+			currentQueueState.ReadLevel(),
+			currentQueueState.MaxLevel(),
+			remainingDomainFilter,
+		))
+	}
+	for newLevel, domainIDsForLevel := range domainsToMove {
+		newQueueStates = append(newQueueStates, newProcessingQueueState(
+			newLevel,
+			currentQueueState.AckLevel(),
+			currentQueueState.ReadLevel(),
+			currentQueueState.MaxLevel(),
+			NewDomainFilter(domainIDsForLevel, false),
+		))
+	}
+	return newQueueStates
+}
+
 func (p *randomSplitPolicy) Evaluate(queue ProcessingQueue) []ProcessingQueueState {
 	queueImpl := queue.(*processingQueueImpl)
 
-	if queueImpl.state.level == p.maxNewQueueLevel {
-		// already reaches max level, skip splitting
+	if queueImpl.state.level >= p.maxNewQueueLevel {
+		// already reaches max level or is dedicated to isolated domains, skip splitting
 		return nil
 	}
 
@@ -422,6 +513,17 @@ func splitQueueHelper(
 	return newQueueStates
 }
 
+func addDomainToLevel(
+	domainsByLevel map[int]map[string]struct{},
+	level int,
+	domainID string,
+) {
+	if _, ok := domainsByLevel[level]; !ok {
+		domainsByLevel[level] = make(map[string]struct{})
+	}
+	domainsByLevel[level][domainID] = struct{}{}
+}
+
 func shouldSplit(probability float64) bool {
 	if probability <= 0 {
 		return false
@@ -555,6 +555,145 @@ func (s *splitPolicySuite) TestSelectedDomainSplitPolicy() {
 	}
 }
 
+func (s *splitPolicySuite) TestDomainIsolationSplitPolicy() {
+	isolatedLevel1 := isolatedDomainQueueLevelBase + 1
+	isolatedLevel2 := isolatedDomainQueueLevelBase + 2
+
+	testCases := []struct {
+		currentState         ProcessingQueueState
+		isolatedDomainLevels map[string]int
+		expectedNewStates    []ProcessingQueueState
+	}{
+		{
+			// isolated domain is not in the queue
+			currentState: newProcessingQueueState(
+				0,
+				testKey{ID: 0},
+				testKey{ID: 5},
+				testKey{ID: 10},
+				NewDomainFilter(map[string]struct{}{"testDomain1": {}}, false),
+			),
+			isolatedDomainLevels: map[string]int{"testDomain2": isolatedLevel1},
+			expectedNewStates:    nil,
+		},
+		{
+			// isolate domains from the root queue
+			currentState: newProcessingQueueState(
+				0,
+				testKey{ID: 0},
+				testKey{ID: 5},
+				testKey{ID: 10},
+				NewDomainFilter(map[string]struct{}{"testDomain1": {}}, true),
+			),
+			isolatedDomainLevels: map[string]int{
+				"testDomain1": isolatedLevel1,
+				"testDomain2": isolatedLevel1,
+				"testDomain3": isolatedLevel2,
+			},
+			expectedNewStates: []ProcessingQueueState{
+				newProcessingQueueState(
+					0,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain1": {}, "testDomain2": {}, "testDomain3": {}}, true),
+				),
+				newProcessingQueueState(
+					isolatedLevel1,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain2": {}}, false),
+				),
+				newProcessingQueueState(
+					isolatedLevel2,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain3": {}}, false),
+				),
+			},
+		},
+		{
+			// domain is still isolated at the same level
+			currentState: newProcessingQueueState(
+				isolatedLevel1,
+				testKey{ID: 0},
+				testKey{ID: 5},
+				testKey{ID: 10},
+				NewDomainFilter(map[string]struct{}{"testDomain1": {}}, false),
+			),
+			isolatedDomainLevels: map[string]int{"testDomain1": isolatedLevel1},
+			expectedNewStates:    nil,
+		},
+		{
+			// move domains which are no longer isolated back to the default level,
+			// and domains assigned to another virtual queue to the new level
+			currentState: newProcessingQueueState(
+				isolatedLevel1,
+				testKey{ID: 0},
+				testKey{ID: 5},
+				testKey{ID: 10},
+				NewDomainFilter(map[string]struct{}{"testDomain1": {}, "testDomain2": {}, "testDomain3": {}}, false),
+			),
+			isolatedDomainLevels: map[string]int{
+				"testDomain2": isolatedLevel1,
+				"testDomain3": isolatedLevel2,
+			},
+			expectedNewStates: []ProcessingQueueState{
+				newProcessingQueueState(
+					defaultProcessingQueueLevel,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain1": {}}, false),
+				),
+				newProcessingQueueState(
+					isolatedLevel1,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain2": {}}, false),
+				),
+				newProcessingQueueState(
+					isolatedLevel2,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain3": {}}, false),
+				),
+			},
+		},
+		{
+			// all domains are moved out, no empty queue is left at the isolated level
+			currentState: newProcessingQueueState(
+				isolatedLevel1,
+				testKey{ID: 0},
+				testKey{ID: 5},
+				testKey{ID: 10},
+				NewDomainFilter(map[string]struct{}{"testDomain1": {}}, false),
+			),
+			isolatedDomainLevels: map[string]int{},
+			expectedNewStates: []ProcessingQueueState{
+				newProcessingQueueState(
+					defaultProcessingQueueLevel,
+					testKey{ID: 0},
+					testKey{ID: 5},
+					testKey{ID: 10},
+					NewDomainFilter(map[string]struct{}{"testDomain1": {}}, false),
+				),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		queue := NewProcessingQueue(tc.currentState, nil, nil)
+		splitPolicy := NewDomainIsolationSplitPolicy(tc.isolatedDomainLevels, s.logger, s.metricsScope)
+
+		s.assertQueueStatesEqual(tc.expectedNewStates, splitPolicy.Evaluate(queue))
+	}
+}
+
 func (s *splitPolicySuite) TestRandomSplitPolicy() {
 	maxNewQueueLevel := 3
 	lookAheadFunc := func(key task.Key, _ string) task.Key {
@@ -492,20 +492,22 @@ func (t *transferQueueProcessorBase) splitQueue() {
 		}
 	}
 
+	var splitPolicy ProcessingQueueSplitPolicy
 	if t.lastSplitTime.IsZero() || t.estimatedTasksPerMinute == 0 {
-		// skip the split as we can't estimate the look ahead taskID
-		return
+		// we can't estimate the look ahead taskID, only domain isolation
+		// which doesn't need to look ahead can be applied
+		splitPolicy = t.initializeDomainIsolationSplitPolicy()
+	} else {
+		splitPolicy = t.initializeSplitPolicy(
+			func(key task.Key, domainID string) task.Key {
+				totalLookAhead := t.estimatedTasksPerMinute * int64(t.options.SplitLookAheadDurationByDomainID(domainID).Minutes())
+				// ensure the above calculation doesn't overflow and cap the maximun look ahead interval
+				totalLookAhead = common.MaxInt64(common.MinInt64(totalLookAhead, 2<<t.shard.GetConfig().RangeSizeBits), 0)
+				return newTransferTaskKey(key.(transferTaskKey).taskID + totalLookAhead)
+			},
+		)
 	}
 
-	splitPolicy := t.initializeSplitPolicy(
-		func(key task.Key, domainID string) task.Key {
-			totalLookAhead := t.estimatedTasksPerMinute * int64(t.options.SplitLookAheadDurationByDomainID(domainID).Minutes())
-			// ensure the above calculation doesn't overflow and cap the maximun look ahead interval
-			totalLookAhead = common.MaxInt64(common.MinInt64(totalLookAhead, 2<<t.shard.GetConfig().RangeSizeBits), 0)
-			return newTransferTaskKey(key.(transferTaskKey).taskID + totalLookAhead)
-		},
-	)
-
 	t.splitProcessingQueueCollection(splitPolicy, func(level int, _ time.Time) {
 		t.readyForProcess(level)
 	})
@@ -603,6 +605,7 @@ func newTransferQueueProcessorOptions(
 		options.EnableStuckTaskSplitByDomainID = config.QueueProcessorEnableStuckTaskSplitByDomainID
 		options.StuckTaskSplitThreshold = config.QueueProcessorStuckTaskSplitThreshold
 		options.SplitLookAheadDurationByDomainID = config.QueueProcessorSplitLookAheadDurationByDomainID
+		options.IsolatedDomains = config.QueueProcessorIsolatedDomains
 
 		options.EnablePersistQueueStates = config.QueueProcessorEnablePersistQueueStates
 		options.EnableLoadQueueStates = config.QueueProcessorEnableLoadQueueStates
@@ -72,6 +72,7 @@ func NewService(
 		params.HostName)
 
 	params.PersistenceConfig.HistoryMaxConns = serviceConfig.HistoryMgrNumConns()
+	serviceConfig.IsPayloadOffloadEnabled = params.PersistenceConfig.BlobstoreClient != nil
 
 	serviceResource, err := resource.New(
 		params,
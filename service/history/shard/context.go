@@ -583,6 +583,7 @@ func (s *contextImpl) CreateWorkflowExecution(
 	if err != nil {
 		return nil, err
 	}
+	request.Encoding = s.getDefaultEncoding(domainEntry.GetInfo().Name)
 
 	s.Lock()
 	defer s.Unlock()
@@ -921,9 +922,14 @@ func (s *contextImpl) AppendHistoryV2Events(
 	request.TransactionID = transactionID
 
 	size := 0
+	persistedSize := 0
 	defer func() {
-		s.GetMetricsClient().Scope(metrics.SessionSizeStatsScope, metrics.DomainTag(domainName)).
-			RecordTimer(metrics.HistorySize, time.Duration(size))
+		scope := s.GetMetricsClient().Scope(metrics.SessionSizeStatsScope, metrics.DomainTag(domainName))
+		scope.RecordTimer(metrics.HistorySize, time.Duration(size))
+		if persistence.IsCompressedEncoding(request.Encoding) && size > 0 {
+			scope.RecordTimer(metrics.HistoryPersistedSize, time.Duration(persistedSize))
+			scope.RecordHistogramValue(metrics.HistoryCompressionRatio, float64(persistedSize)/float64(size))
+		}
 		if size >= historySizeLogThreshold {
 			s.throttledLogger.Warn("history size threshold breached",
 				tag.WorkflowID(execution.GetWorkflowID()),
@@ -935,6 +941,7 @@ func (s *contextImpl) AppendHistoryV2Events(
 	resp, err0 := s.GetHistoryManager().AppendHistoryNodes(ctx, request)
 	if resp != nil {
 		size = len(resp.DataBlob.Data)
+		persistedSize = resp.PersistedSize
 	}
 	return resp, err0
 }
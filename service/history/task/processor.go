@@ -30,6 +30,7 @@ import (
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/quotas"
 	"github.com/uber/cadence/common/task"
 	"github.com/uber/cadence/service/history/config"
 	"github.com/uber/cadence/service/history/shard"
@@ -45,8 +46,11 @@ type processorImpl struct {
 	status        int32
 	options       *task.SchedulerOptions
 	shardOptions  *task.SchedulerOptions
+	config        *config.Config
 	logger        log.Logger
 	metricsClient metrics.Client
+
+	isolatedDomainRateLimiters *quotas.Collection
 }
 
 var (
@@ -70,6 +74,7 @@ func NewProcessor(
 	if err != nil {
 		return nil, err
 	}
+	setIsolationOptions(options, config)
 	hostScheduler, err := createTaskScheduler(options, logger, metricsClient)
 	if err != nil {
 		return nil, err
@@ -88,6 +93,7 @@ func NewProcessor(
 		if err != nil {
 			return nil, err
 		}
+		setIsolationOptions(shardOptions, config)
 		logger.Debug("Shard level task scheduler is enabled", tag.Dynamic("scheduler_options", shardOptions.String()))
 	}
 
@@ -98,8 +104,12 @@ func NewProcessor(
 		status:           common.DaemonStatusInitialized,
 		options:          options,
 		shardOptions:     shardOptions,
+		config:           config,
 		logger:           logger,
 		metricsClient:    metricsClient,
+		isolatedDomainRateLimiters: quotas.NewCollection(quotas.NewSimpleDynamicRateLimiterFactory(
+			config.QueueProcessorIsolatedDomainRPS,
+		)),
 	}, nil
 }
 
@@ -178,6 +188,11 @@ func (p *processorImpl) TrySubmit(task Task) (bool, error) {
 		return false, err
 	}
 
+	if p.isThrottledByDomainIsolation(task) {
+		// the task will be redispatched later by the queue processor
+		return false, nil
+	}
+
 	submitted, err := p.hostScheduler.TrySubmit(task)
 	if err != nil {
 		return false, err
@@ -241,10 +256,65 @@ func (p *processorImpl) getOrCreateShardTaskScheduler(shard shard.Context) (task
 	return scheduler, nil
 }
 
+func (p *processorImpl) isThrottledByDomainIsolation(task Task) bool {
+	domainName, ok := getIsolatedDomainName(task, p.config)
+	if !ok || p.config.QueueProcessorIsolatedDomainRPS(domainName) <= 0 {
+		return false
+	}
+
+	if p.isolatedDomainRateLimiters.For(domainName).Allow() {
+		return false
+	}
+	p.metricsClient.Scope(metrics.TaskSchedulerScope, metrics.DomainTag(domainName)).IncCounter(metrics.IsolatedDomainTaskThrottledCounter)
+	return true
+}
+
 func (p *processorImpl) isRunning() bool {
 	return atomic.LoadInt32(&p.status) == common.DaemonStatusStarted
 }
 
+// setIsolationOptions schedules tasks of isolated domains from dedicated
+// channels of the weighted round robin scheduler
+func setIsolationOptions(
+	options *task.SchedulerOptions,
+	config *config.Config,
+) {
+	if options.WRRSchedulerOptions == nil {
+		return
+	}
+
+	options.WRRSchedulerOptions.IsolationKeyFn = func(priorityTask task.PriorityTask) string {
+		queueTask, ok := priorityTask.(Task)
+		if !ok {
+			return ""
+		}
+		domainName, _ := getIsolatedDomainName(queueTask, config)
+		return domainName
+	}
+	options.WRRSchedulerOptions.IsolationWeight = config.TaskSchedulerIsolatedDomainWeight
+}
+
+// getIsolatedDomainName returns the domain name of the task
+// if the domain is isolated by history.queueProcessorIsolatedDomains
+func getIsolatedDomainName(
+	task Task,
+	config *config.Config,
+) (string, bool) {
+	isolatedDomains := config.QueueProcessorIsolatedDomains()
+	if len(isolatedDomains) == 0 {
+		return "", false
+	}
+
+	domainName, err := task.GetShard().GetDomainCache().GetDomainName(task.GetDomainID())
+	if err != nil {
+		return "", false
+	}
+	if _, ok := isolatedDomains[domainName]; !ok {
+		return "", false
+	}
+	return domainName, true
+}
+
 func createTaskScheduler(
 	options *task.SchedulerOptions,
 	logger log.Logger,
@@ -36,6 +36,7 @@ import (
 	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/task"
 	"github.com/uber/cadence/service/history/config"
+	"github.com/uber/cadence/service/history/constants"
 	"github.com/uber/cadence/service/history/shard"
 )
 
@@ -209,6 +210,39 @@ func (s *queueTaskProcessorSuite) TestTrySubmit_Fail() {
 	s.False(submitted)
 }
 
+func (s *queueTaskProcessorSuite) TestTrySubmit_IsolatedDomainThrottled() {
+	config := config.NewForTest()
+	config.QueueProcessorIsolatedDomains = dynamicconfig.GetMapPropertyFn(map[string]interface{}{constants.TestDomainName: 1})
+	config.QueueProcessorIsolatedDomainRPS = dynamicconfig.GetIntPropertyFilteredByDomain(1)
+	processor, err := NewProcessor(
+		s.mockPriorityAssigner,
+		config,
+		s.logger,
+		s.metricsClient,
+	)
+	s.NoError(err)
+	processorImpl := processor.(*processorImpl)
+
+	mockTask := NewMockTask(s.controller)
+	mockTask.EXPECT().GetShard().Return(s.mockShard).AnyTimes()
+	mockTask.EXPECT().GetDomainID().Return(constants.TestDomainID).AnyTimes()
+	s.mockShard.Resource.DomainCache.EXPECT().GetDomainName(constants.TestDomainID).Return(constants.TestDomainName, nil).AnyTimes()
+	s.mockPriorityAssigner.EXPECT().Assign(NewMockTaskMatcher(mockTask)).Return(nil).Times(2)
+
+	mockScheduler := task.NewMockScheduler(s.controller)
+	mockScheduler.EXPECT().TrySubmit(NewMockTaskMatcher(mockTask)).Return(true, nil).Times(1)
+	processorImpl.hostScheduler = mockScheduler
+
+	submitted, err := processorImpl.TrySubmit(mockTask)
+	s.NoError(err)
+	s.True(submitted)
+
+	// rate limit of the isolated domain is exceeded, task is not submitted to the scheduler
+	submitted, err = processorImpl.TrySubmit(mockTask)
+	s.NoError(err)
+	s.False(submitted)
+}
+
 func (s *queueTaskProcessorSuite) TestNewSchedulerOptions_UnknownSchedulerType() {
 	options, err := task.NewSchedulerOptions(0, 100, dynamicconfig.GetIntPropertyFn(10), 1, nil)
 	s.Error(err)
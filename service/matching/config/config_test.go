@@ -42,41 +42,52 @@ type configTestCase struct {
 func TestNewConfig(t *testing.T) {
 	hostname := "hostname"
 	fields := map[string]configTestCase{
-		"PersistenceMaxQPS":               {dynamicconfig.MatchingPersistenceMaxQPS, 1},
-		"PersistenceGlobalMaxQPS":         {dynamicconfig.MatchingPersistenceGlobalMaxQPS, 2},
-		"EnableSyncMatch":                 {dynamicconfig.MatchingEnableSyncMatch, true},
-		"UserRPS":                         {dynamicconfig.MatchingUserRPS, 3},
-		"WorkerRPS":                       {dynamicconfig.MatchingWorkerRPS, 4},
-		"DomainUserRPS":                   {dynamicconfig.MatchingDomainUserRPS, 5},
-		"DomainWorkerRPS":                 {dynamicconfig.MatchingDomainWorkerRPS, 6},
-		"RangeSize":                       {nil, int64(100000)},
-		"GetTasksBatchSize":               {dynamicconfig.MatchingGetTasksBatchSize, 7},
-		"UpdateAckInterval":               {dynamicconfig.MatchingUpdateAckInterval, time.Duration(8)},
-		"IdleTasklistCheckInterval":       {dynamicconfig.MatchingIdleTasklistCheckInterval, time.Duration(9)},
-		"MaxTasklistIdleTime":             {dynamicconfig.MaxTasklistIdleTime, time.Duration(10)},
-		"LongPollExpirationInterval":      {dynamicconfig.MatchingLongPollExpirationInterval, time.Duration(11)},
-		"MinTaskThrottlingBurstSize":      {dynamicconfig.MatchingMinTaskThrottlingBurstSize, 12},
-		"MaxTaskDeleteBatchSize":          {dynamicconfig.MatchingMaxTaskDeleteBatchSize, 13},
-		"OutstandingTaskAppendsThreshold": {dynamicconfig.MatchingOutstandingTaskAppendsThreshold, 14},
-		"MaxTaskBatchSize":                {dynamicconfig.MatchingMaxTaskBatchSize, 15},
-		"ThrottledLogRPS":                 {dynamicconfig.MatchingThrottledLogRPS, 16},
-		"NumTasklistWritePartitions":      {dynamicconfig.MatchingNumTasklistWritePartitions, 17},
-		"NumTasklistReadPartitions":       {dynamicconfig.MatchingNumTasklistReadPartitions, 18},
-		"ForwarderMaxOutstandingPolls":    {dynamicconfig.MatchingForwarderMaxOutstandingPolls, 19},
-		"ForwarderMaxOutstandingTasks":    {dynamicconfig.MatchingForwarderMaxOutstandingTasks, 20},
-		"ForwarderMaxRatePerSecond":       {dynamicconfig.MatchingForwarderMaxRatePerSecond, 21},
-		"ForwarderMaxChildrenPerNode":     {dynamicconfig.MatchingForwarderMaxChildrenPerNode, 22},
-		"ShutdownDrainDuration":           {dynamicconfig.MatchingShutdownDrainDuration, time.Duration(23)},
-		"EnableDebugMode":                 {dynamicconfig.EnableDebugMode, false},
-		"EnableTaskInfoLogByDomainID":     {dynamicconfig.MatchingEnableTaskInfoLogByDomainID, true},
-		"ActivityTaskSyncMatchWaitTime":   {dynamicconfig.MatchingActivityTaskSyncMatchWaitTime, time.Duration(24)},
-		"EnableTasklistIsolation":         {dynamicconfig.EnableTasklistIsolation, false},
-		"AllIsolationGroups":              {dynamicconfig.AllIsolationGroups, []interface{}{"a", "b", "c"}},
-		"AsyncTaskDispatchTimeout":        {dynamicconfig.AsyncTaskDispatchTimeout, time.Duration(25)},
-		"HostName":                        {nil, hostname},
-		"TaskDispatchRPS":                 {nil, 100000.0},
-		"TaskDispatchRPSTTL":              {nil, time.Minute},
-		"MaxTimeBetweenTaskDeletes":       {nil, time.Second},
+		"PersistenceMaxQPS":                   {dynamicconfig.MatchingPersistenceMaxQPS, 1},
+		"PersistenceGlobalMaxQPS":             {dynamicconfig.MatchingPersistenceGlobalMaxQPS, 2},
+		"EnableSyncMatch":                     {dynamicconfig.MatchingEnableSyncMatch, true},
+		"UserRPS":                             {dynamicconfig.MatchingUserRPS, 3},
+		"WorkerRPS":                           {dynamicconfig.MatchingWorkerRPS, 4},
+		"DomainUserRPS":                       {dynamicconfig.MatchingDomainUserRPS, 5},
+		"DomainWorkerRPS":                     {dynamicconfig.MatchingDomainWorkerRPS, 6},
+		"RangeSize":                           {nil, int64(100000)},
+		"GetTasksBatchSize":                   {dynamicconfig.MatchingGetTasksBatchSize, 7},
+		"UpdateAckInterval":                   {dynamicconfig.MatchingUpdateAckInterval, time.Duration(8)},
+		"IdleTasklistCheckInterval":           {dynamicconfig.MatchingIdleTasklistCheckInterval, time.Duration(9)},
+		"MaxTasklistIdleTime":                 {dynamicconfig.MaxTasklistIdleTime, time.Duration(10)},
+		"LongPollExpirationInterval":          {dynamicconfig.MatchingLongPollExpirationInterval, time.Duration(11)},
+		"MinTaskThrottlingBurstSize":          {dynamicconfig.MatchingMinTaskThrottlingBurstSize, 12},
+		"MaxTaskDeleteBatchSize":              {dynamicconfig.MatchingMaxTaskDeleteBatchSize, 13},
+		"OutstandingTaskAppendsThreshold":     {dynamicconfig.MatchingOutstandingTaskAppendsThreshold, 14},
+		"MaxTaskBatchSize":                    {dynamicconfig.MatchingMaxTaskBatchSize, 15},
+		"ThrottledLogRPS":                     {dynamicconfig.MatchingThrottledLogRPS, 16},
+		"NumTasklistWritePartitions":          {dynamicconfig.MatchingNumTasklistWritePartitions, 17},
+		"NumTasklistReadPartitions":           {dynamicconfig.MatchingNumTasklistReadPartitions, 18},
+		"ForwarderMaxOutstandingPolls":        {dynamicconfig.MatchingForwarderMaxOutstandingPolls, 19},
+		"ForwarderMaxOutstandingTasks":        {dynamicconfig.MatchingForwarderMaxOutstandingTasks, 20},
+		"ForwarderMaxRatePerSecond":           {dynamicconfig.MatchingForwarderMaxRatePerSecond, 21},
+		"ForwarderMaxChildrenPerNode":         {dynamicconfig.MatchingForwarderMaxChildrenPerNode, 22},
+		"ShutdownDrainDuration":               {dynamicconfig.MatchingShutdownDrainDuration, time.Duration(23)},
+		"EnableDebugMode":                     {dynamicconfig.EnableDebugMode, false},
+		"EnableTaskInfoLogByDomainID":         {dynamicconfig.MatchingEnableTaskInfoLogByDomainID, true},
+		"ActivityTaskSyncMatchWaitTime":       {dynamicconfig.MatchingActivityTaskSyncMatchWaitTime, time.Duration(24)},
+		"EnableTasklistIsolation":             {dynamicconfig.EnableTasklistIsolation, false},
+		"AllIsolationGroups":                  {dynamicconfig.AllIsolationGroups, []interface{}{"a", "b", "c"}},
+		"AsyncTaskDispatchTimeout":            {dynamicconfig.AsyncTaskDispatchTimeout, time.Duration(25)},
+		"EnableTaskPriority":                  {dynamicconfig.MatchingEnableTaskPriority, true},
+		"EnableAdaptiveScaler":                {dynamicconfig.MatchingEnableAdaptiveScaler, true},
+		"AdaptiveScalerUpdateInterval":        {dynamicconfig.MatchingAdaptiveScalerUpdateInterval, time.Duration(26)},
+		"AdaptiveScalerMaxPartitions":         {dynamicconfig.MatchingAdaptiveScalerMaxPartitions, 27},
+		"PartitionUpscaleRPS":                 {dynamicconfig.MatchingPartitionUpscaleRPS, 28},
+		"PartitionDownscaleRPS":               {dynamicconfig.MatchingPartitionDownscaleRPS, 29},
+		"PartitionUpscaleSustainedDuration":   {dynamicconfig.MatchingPartitionUpscaleSustainedDuration, time.Duration(30)},
+		"PartitionDownscaleSustainedDuration": {dynamicconfig.MatchingPartitionDownscaleSustainedDuration, time.Duration(31)},
+		"TaskListPaused":                      {dynamicconfig.MatchingTaskListPaused, true},
+		"TaskListPurgeCreatedBefore":          {dynamicconfig.MatchingTaskListPurgeCreatedBefore, 32},
+		"TaskListPurgeWorkflowType":           {dynamicconfig.MatchingTaskListPurgeWorkflowType, "workflowType"},
+		"HostName":                            {nil, hostname},
+		"TaskDispatchRPS":                     {nil, 100000.0},
+		"TaskDispatchRPSTTL":                  {nil, time.Minute},
+		"MaxTimeBetweenTaskDeletes":           {nil, time.Second},
 	}
 	client := dynamicconfig.NewInMemoryClient()
 	for fieldName, expected := range fields {
@@ -145,6 +156,8 @@ func getValue(f *reflect.Value) interface{} {
 			return fn()
 		case dynamicconfig.StringPropertyFn:
 			return fn()
+		case dynamicconfig.StringPropertyFnWithTaskListInfoFilters:
+			return fn("domain", "tasklist", int(types.TaskListTypeDecision))
 		default:
 			panic("Unable to handle type: " + f.Type().Name())
 		}
@@ -25,13 +25,16 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/pborman/uuid"
+	"go.uber.org/yarpc"
 
 	"github.com/uber/cadence/client/history"
 	"github.com/uber/cadence/client/matching"
@@ -245,6 +248,7 @@ func (e *matchingEngineImpl) getTaskListManager(taskList *tasklist.Identifier, t
 		e.clusterMetadata,
 		e.partitioner,
 		e.matchingClient,
+		e.historyService,
 		e.removeTaskListManager,
 		taskList,
 		taskListKind,
@@ -904,7 +908,55 @@ func (e *matchingEngineImpl) DescribeTaskList(
 		return nil, err
 	}
 
-	return tlMgr.DescribeTaskList(request.DescRequest.GetIncludeTaskListStatus()), nil
+	response := tlMgr.DescribeTaskList(request.DescRequest.GetIncludeTaskListStatus())
+	if request.DescRequest.GetIncludeTaskListStatus() {
+		writeBacklogByPriorityHeader(hCtx, tlMgr.BacklogByPriority())
+	}
+	if taskListID.IsRoot() && tlMgr.GetTaskListKind() == types.TaskListKindNormal {
+		readPartitions, writePartitions := tlMgr.Partitions()
+		writePartitionsHeader(hCtx, readPartitions, writePartitions)
+	}
+	writePurgeCompletedHeader(hCtx, tlMgr.CompletedPurge())
+	return response, nil
+}
+
+// writePurgeCompletedHeader passes the creation time of the completed purge of a task list as a response header since the IDL has no field for it
+func writePurgeCompletedHeader(ctx context.Context, createdBefore time.Time) {
+	if createdBefore.IsZero() {
+		return
+	}
+	// the call is nil when the engine isn't called through RPC
+	if call := yarpc.CallFromContext(ctx); call != nil {
+		_ = call.WriteResponseHeader(common.TaskListPurgeCompletedHeaderName, strconv.FormatInt(createdBefore.Unix(), 10))
+	}
+}
+
+// writePartitionsHeader passes the partition counts of a task list as a response header since the IDL has no field for them
+func writePartitionsHeader(ctx context.Context, readPartitions, writePartitions int) {
+	encoded, err := json.Marshal(common.TaskListPartitions{ReadPartitions: readPartitions, WritePartitions: writePartitions})
+	if err != nil {
+		return
+	}
+	// the call is nil when the engine isn't called through RPC
+	if call := yarpc.CallFromContext(ctx); call != nil {
+		_ = call.WriteResponseHeader(common.TaskListPartitionsHeaderName, string(encoded))
+	}
+}
+
+// writeBacklogByPriorityHeader passes the backlog of a task list by priority as a response header since the IDL has no field for it
+func writeBacklogByPriorityHeader(ctx context.Context, backlogByPriority map[int]int) {
+	if len(backlogByPriority) == 0 {
+		return
+	}
+
+	encoded, err := json.Marshal(backlogByPriority)
+	if err != nil {
+		return
+	}
+	// the call is nil when the engine isn't called through RPC
+	if call := yarpc.CallFromContext(ctx); call != nil {
+		_ = call.WriteResponseHeader(common.TaskListBacklogByPriorityHeaderName, string(encoded))
+	}
 }
 
 func (e *matchingEngineImpl) ListTaskListPartitions(
@@ -990,6 +1042,18 @@ func (e *matchingEngineImpl) getAllPartitions(
 	rootPartition := taskListID.GetRoot()
 	partitionKeys := []string{rootPartition}
 	n := e.config.NumTasklistWritePartitions(request.GetDomain(), rootPartition, taskListType)
+	if e.config.EnableAdaptiveScaler(request.GetDomain(), rootPartition, taskListType) {
+		// the partitions are scaled by the root partition, clients use the write partitions listed here
+		rootID, err := tasklist.NewIdentifier(domainID, rootPartition, taskListType)
+		if err != nil {
+			return nil, err
+		}
+		tlMgr, err := e.getTaskListManager(rootID, types.TaskListKindNormal.Ptr())
+		if err != nil {
+			return nil, err
+		}
+		_, n = tlMgr.Partitions()
+	}
 	for i := 1; i < n; i++ {
 		partitionKeys = append(partitionKeys, fmt.Sprintf("%v%v/%v", common.ReservedTaskListPrefix, rootPartition, i))
 	}
@@ -1185,6 +1249,8 @@ func (e *matchingEngineImpl) emitTaskIsolationMetrics(
 	partitionConfig map[string]string,
 	pollerIsolationGroup string,
 ) {
+	// the task priority keys are not emitted to avoid high cardinality of the fairness keys
+	partitionConfig = partition.WithoutTaskPriority(partitionConfig)
 	if len(partitionConfig) > 0 {
 		scope.Tagged(metrics.PartitionConfigTags(partitionConfig)...).Tagged(metrics.PollerIsolationGroupTag(pollerIsolationGroup)).IncCounter(metrics.IsolationTaskMatchPerTaskListCounter)
 	}
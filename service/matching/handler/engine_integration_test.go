@@ -215,6 +215,7 @@ func (s *matchingEngineSuite) TestOnlyUnloadMatchingInstance() {
 		s.matchingEngine.clusterMetadata,
 		s.matchingEngine.partitioner,
 		s.matchingEngine.matchingClient,
+		s.matchingEngine.historyService,
 		s.matchingEngine.removeTaskListManager,
 		taskListID, // same taskListID as above
 		&tlKind,
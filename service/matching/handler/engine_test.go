@@ -27,17 +27,22 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport/transporttest"
 
+	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/cache"
 	"github.com/uber/cadence/common/client"
 	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/membership"
 	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
 	"github.com/uber/cadence/service/matching/config"
 	"github.com/uber/cadence/service/matching/tasklist"
@@ -250,6 +255,7 @@ func TestListTaskListPartitions(t *testing.T) {
 				membershipResolver: mockResolver,
 				config: &config.Config{
 					NumTasklistWritePartitions: dynamicconfig.GetIntPropertyFilteredByTaskListInfo(3),
+					EnableAdaptiveScaler:       dynamicconfig.GetBoolPropertyFnFilteredByTaskListInfo(false),
 				},
 			}
 			resp, err := engine.ListTaskListPartitions(nil, tc.req)
@@ -263,6 +269,51 @@ func TestListTaskListPartitions(t *testing.T) {
 	}
 }
 
+func TestListTaskListPartitions_AdaptiveScaler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockDomainCache := cache.NewMockDomainCache(mockCtrl)
+	mockResolver := membership.NewMockResolver(mockCtrl)
+	mockDomainCache.EXPECT().GetDomainID("test-domain").Return("test-domain-id", nil).Times(2)
+	mockResolver.EXPECT().Lookup(gomock.Any(), gomock.Any()).Return(membership.NewHostInfo("addr"), nil).AnyTimes()
+
+	// the partitions are taken from the root partitions of the task list
+	activityTaskList, err := tasklist.NewIdentifier("test-domain-id", "test-tasklist", persistence.TaskListTypeActivity)
+	require.NoError(t, err)
+	decisionTaskList, err := tasklist.NewIdentifier("test-domain-id", "test-tasklist", persistence.TaskListTypeDecision)
+	require.NoError(t, err)
+	activityTlMgr := tasklist.NewMockManager(mockCtrl)
+	activityTlMgr.EXPECT().Partitions().Return(4, 2)
+	decisionTlMgr := tasklist.NewMockManager(mockCtrl)
+	decisionTlMgr.EXPECT().Partitions().Return(1, 1)
+
+	engine := &matchingEngineImpl{
+		domainCache:        mockDomainCache,
+		membershipResolver: mockResolver,
+		config: &config.Config{
+			NumTasklistWritePartitions: dynamicconfig.GetIntPropertyFilteredByTaskListInfo(3),
+			EnableAdaptiveScaler:       dynamicconfig.GetBoolPropertyFnFilteredByTaskListInfo(true),
+		},
+		taskLists: map[tasklist.Identifier]tasklist.Manager{
+			*activityTaskList: activityTlMgr,
+			*decisionTaskList: decisionTlMgr,
+		},
+	}
+	resp, err := engine.ListTaskListPartitions(nil, &types.MatchingListTaskListPartitionsRequest{
+		Domain:   "test-domain",
+		TaskList: &types.TaskList{Name: "test-tasklist"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &types.ListTaskListPartitionsResponse{
+		ActivityTaskListPartitions: []*types.TaskListPartitionMetadata{
+			{Key: "test-tasklist", OwnerHostName: "addr"},
+			{Key: "/__cadence_sys/test-tasklist/1", OwnerHostName: "addr"},
+		},
+		DecisionTaskListPartitions: []*types.TaskListPartitionMetadata{
+			{Key: "test-tasklist", OwnerHostName: "addr"},
+		},
+	}, resp)
+}
+
 func TestCancelOutstandingPoll(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -617,3 +668,51 @@ func TestWaitForQueryResult(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteBacklogByPriorityHeader(t *testing.T) {
+	ctx, call := encoding.NewInboundCall(context.Background())
+	writeBacklogByPriorityHeader(ctx, map[int]int{0: 3, 10: 1})
+
+	resw := &transporttest.FakeResponseWriter{}
+	require.NoError(t, call.WriteToResponse(resw))
+	backlog, ok := resw.Headers.Get(common.TaskListBacklogByPriorityHeaderName)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"0":3,"10":1}`, backlog)
+
+	// nothing is written without a call or backlog
+	writeBacklogByPriorityHeader(context.Background(), map[int]int{0: 3})
+	ctx, call = encoding.NewInboundCall(context.Background())
+	writeBacklogByPriorityHeader(ctx, nil)
+	resw = &transporttest.FakeResponseWriter{}
+	require.NoError(t, call.WriteToResponse(resw))
+	assert.Equal(t, 0, resw.Headers.Len())
+}
+
+func TestWritePartitionsHeader(t *testing.T) {
+	ctx, call := encoding.NewInboundCall(context.Background())
+	writePartitionsHeader(ctx, 3, 2)
+
+	resw := &transporttest.FakeResponseWriter{}
+	require.NoError(t, call.WriteToResponse(resw))
+	partitions, ok := resw.Headers.Get(common.TaskListPartitionsHeaderName)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"readPartitions":3,"writePartitions":2}`, partitions)
+}
+
+func TestWritePurgeCompletedHeader(t *testing.T) {
+	ctx, call := encoding.NewInboundCall(context.Background())
+	writePurgeCompletedHeader(ctx, time.Unix(1700000000, 0))
+
+	resw := &transporttest.FakeResponseWriter{}
+	require.NoError(t, call.WriteToResponse(resw))
+	createdBefore, ok := resw.Headers.Get(common.TaskListPurgeCompletedHeaderName)
+	assert.True(t, ok)
+	assert.Equal(t, "1700000000", createdBefore)
+
+	// nothing is written while the purge is not completed
+	ctx, call = encoding.NewInboundCall(context.Background())
+	writePurgeCompletedHeader(ctx, time.Time{})
+	resw = &transporttest.FakeResponseWriter{}
+	require.NoError(t, call.WriteToResponse(resw))
+	assert.Equal(t, 0, resw.Headers.Len())
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/uber/cadence/client/matching"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/matching/config"
+)
+
+const (
+	// drainCheckTimeout is the timeout of the calls checking the backlog of the partitions being drained
+	drainCheckTimeout = 5 * time.Second
+)
+
+type (
+	// adaptiveScaler scales the number of partitions of a task list by its add task rate. It runs in the
+	// root partition, and estimates the rate of the task list from the rate of the root partition since
+	// the tasks are spread evenly across the write partitions.
+	//
+	// Partitions are added to the read and write partitions at once. When partitions are removed, they are
+	// removed from the write partitions first and from the read partitions after their backlog is drained.
+	// The partitions being drained are kept loaded by describing them, their backlog is forwarded to the
+	// root partition where the pollers are.
+	//
+	// The partition counts are persisted in the task list info of the root partition, the next owner of the
+	// root partition starts from them.
+	adaptiveScaler struct {
+		taskListID            *Identifier
+		domainName            string
+		config                *config.TaskListConfig
+		taskManager           persistence.TaskManager
+		matchingClient        matching.Client
+		backlogCount          func() int64
+		partitionConfig       func() *persistence.TaskListPartitionConfig
+		updatePartitionConfig func(*persistence.TaskListPartitionConfig) error
+		timeSource            clock.TimeSource
+		logger                log.Logger
+		scope                 metrics.Scope
+
+		// addTaskCount is the number of tasks added to the root partition since the last update
+		addTaskCount int64
+		lastUpdate   time.Time
+
+		sync.RWMutex
+		enabled         bool
+		readPartitions  int
+		writePartitions int
+		// overloadSince and underloadSince are the times since the rate has been above the upscale threshold
+		// or below the downscale threshold
+		overloadSince  time.Time
+		underloadSince time.Time
+
+		stopC  chan struct{}
+		stopWG sync.WaitGroup
+	}
+)
+
+func newAdaptiveScaler(
+	taskListID *Identifier,
+	domainName string,
+	config *config.TaskListConfig,
+	taskManager persistence.TaskManager,
+	matchingClient matching.Client,
+	backlogCount func() int64,
+	partitionConfig func() *persistence.TaskListPartitionConfig,
+	updatePartitionConfig func(*persistence.TaskListPartitionConfig) error,
+	timeSource clock.TimeSource,
+	logger log.Logger,
+	scope metrics.Scope,
+) *adaptiveScaler {
+	return &adaptiveScaler{
+		taskListID:            taskListID,
+		domainName:            domainName,
+		config:                config,
+		taskManager:           taskManager,
+		matchingClient:        matchingClient,
+		backlogCount:          backlogCount,
+		partitionConfig:       partitionConfig,
+		updatePartitionConfig: updatePartitionConfig,
+		timeSource:            timeSource,
+		logger:                logger,
+		scope:                 scope,
+		lastUpdate:            timeSource.Now(),
+		stopC:                 make(chan struct{}),
+	}
+}
+
+func (s *adaptiveScaler) Start() {
+	s.stopWG.Add(1)
+	go func() {
+		defer s.stopWG.Done()
+		s.updateLoop()
+	}()
+}
+
+func (s *adaptiveScaler) Stop() {
+	close(s.stopC)
+	s.stopWG.Wait()
+}
+
+// RecordAddTask records a task added to the root partition by the load balancer
+func (s *adaptiveScaler) RecordAddTask() {
+	atomic.AddInt64(&s.addTaskCount, 1)
+}
+
+// Partitions returns the number of read and write partitions of the task list
+func (s *adaptiveScaler) Partitions() (int, int) {
+	s.RLock()
+	defer s.RUnlock()
+	if !s.enabled {
+		return s.config.NumReadPartitions(), s.config.NumWritePartitions()
+	}
+	return s.readPartitions, s.writePartitions
+}
+
+func (s *adaptiveScaler) updateLoop() {
+	ticker := s.timeSource.NewTicker(s.config.AdaptiveScalerUpdateInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			s.update()
+			ticker.Reset(s.config.AdaptiveScalerUpdateInterval())
+		case <-s.stopC:
+			return
+		}
+	}
+}
+
+func (s *adaptiveScaler) update() {
+	now := s.timeSource.Now()
+	elapsed := now.Sub(s.lastUpdate)
+	s.lastUpdate = now
+	addTaskCount := atomic.SwapInt64(&s.addTaskCount, 0)
+
+	if !s.config.EnableAdaptiveScaler() {
+		s.Lock()
+		s.enabled = false
+		s.Unlock()
+		return
+	}
+	if !s.isEnabled() {
+		// start from the persisted or configured partitions, and drain the partitions above them left by a previous owner
+		s.reset()
+		return
+	}
+
+	readPartitions, writePartitions := s.Partitions()
+	if elapsed > 0 {
+		qps := float64(addTaskCount) / elapsed.Seconds() * float64(writePartitions)
+		s.scope.UpdateGauge(metrics.EstimatedAddTaskQPSPerTaskListGauge, qps)
+		s.adjust(now, qps, readPartitions, writePartitions)
+	}
+	s.drain()
+
+	readPartitions, writePartitions = s.Partitions()
+	s.scope.UpdateGauge(metrics.ReadPartitionsPerTaskListGauge, float64(readPartitions))
+	s.scope.UpdateGauge(metrics.WritePartitionsPerTaskListGauge, float64(writePartitions))
+	s.persistPartitions(readPartitions, writePartitions)
+}
+
+func (s *adaptiveScaler) isEnabled() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.enabled
+}
+
+func (s *adaptiveScaler) reset() {
+	maxPartitions := s.config.AdaptiveScalerMaxPartitions()
+	writePartitions := common.MinInt(s.config.NumWritePartitions(), maxPartitions)
+	readPartitions := writePartitions
+	if persisted := s.partitionConfig(); persisted != nil {
+		writePartitions = common.MaxInt(1, common.MinInt(persisted.NumWritePartitions, maxPartitions))
+		readPartitions = common.MaxInt(persisted.NumReadPartitions, writePartitions)
+	}
+	// the partition counts may not have been persisted after the last change of the previous owner
+	for p := maxPartitions - 1; p >= readPartitions; p-- {
+		if s.hasTasks(p) {
+			readPartitions = p + 1
+			break
+		}
+	}
+	s.Lock()
+	s.enabled = true
+	s.readPartitions = readPartitions
+	s.writePartitions = writePartitions
+	s.overloadSince = time.Time{}
+	s.underloadSince = time.Time{}
+	s.Unlock()
+	s.logger.Info("Adaptive scaler of task list started",
+		tag.Dynamic("read-partitions", readPartitions),
+		tag.Dynamic("write-partitions", writePartitions))
+	s.persistPartitions(readPartitions, writePartitions)
+}
+
+// persistPartitions saves the partition counts in the task list info when they changed, a failed save is
+// retried on the next update
+func (s *adaptiveScaler) persistPartitions(readPartitions, writePartitions int) {
+	persisted := s.partitionConfig()
+	if persisted != nil && persisted.NumReadPartitions == readPartitions && persisted.NumWritePartitions == writePartitions {
+		return
+	}
+	err := s.updatePartitionConfig(&persistence.TaskListPartitionConfig{
+		NumReadPartitions:  readPartitions,
+		NumWritePartitions: writePartitions,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to persist partitions of task list", tag.Error(err))
+	}
+}
+
+func (s *adaptiveScaler) adjust(now time.Time, qps float64, readPartitions, writePartitions int) {
+	upscaleRPS := s.config.PartitionUpscaleRPS()
+	downscaleRPS := s.config.PartitionDownscaleRPS()
+	if upscaleRPS <= 0 || downscaleRPS <= 0 {
+		return
+	}
+	maxPartitions := s.config.AdaptiveScalerMaxPartitions()
+	upscaleTarget := s.targetPartitions(qps, upscaleRPS, maxPartitions)
+	downscaleTarget := s.targetPartitions(qps, downscaleRPS, maxPartitions)
+
+	switch {
+	case upscaleTarget > writePartitions:
+		s.underloadSince = time.Time{}
+		if s.overloadSince.IsZero() {
+			s.overloadSince = now
+		}
+		if now.Sub(s.overloadSince) >= s.config.PartitionUpscaleSustainedDuration() {
+			s.overloadSince = time.Time{}
+			s.setPartitions(common.MaxInt(readPartitions, upscaleTarget), upscaleTarget)
+			s.scope.IncCounter(metrics.PartitionUpscaleCounterPerTaskList)
+			s.logger.Info("Task list partitions scaled up",
+				tag.Dynamic("qps", qps),
+				tag.Dynamic("write-partitions", upscaleTarget))
+		}
+	case downscaleTarget < writePartitions && s.backlogCount() == 0:
+		s.overloadSince = time.Time{}
+		if s.underloadSince.IsZero() {
+			s.underloadSince = now
+		}
+		if now.Sub(s.underloadSince) >= s.config.PartitionDownscaleSustainedDuration() {
+			s.underloadSince = time.Time{}
+			s.setPartitions(readPartitions, downscaleTarget)
+			s.scope.IncCounter(metrics.PartitionDownscaleCounterPerTaskList)
+			s.logger.Info("Task list partitions scaled down",
+				tag.Dynamic("qps", qps),
+				tag.Dynamic("write-partitions", downscaleTarget))
+		}
+	default:
+		s.overloadSince = time.Time{}
+		s.underloadSince = time.Time{}
+	}
+}
+
+func (s *adaptiveScaler) targetPartitions(qps float64, rpsPerPartition int, maxPartitions int) int {
+	target := int(math.Ceil(qps / float64(rpsPerPartition)))
+	return common.MaxInt(1, common.MinInt(target, maxPartitions))
+}
+
+// drain removes the read partitions that are not written to anymore once their backlog is drained,
+// starting from the last partition so that the read partitions stay contiguous
+func (s *adaptiveScaler) drain() {
+	readPartitions, writePartitions := s.Partitions()
+	for p := readPartitions - 1; p >= writePartitions; p-- {
+		drained, err := s.isDrained(p)
+		if err != nil {
+			s.logger.Warn("Failed to check backlog of task list partition", tag.Dynamic("partition", p), tag.Error(err))
+			return
+		}
+		if !drained {
+			return
+		}
+		s.Lock()
+		// partitions may have been added back in the meantime
+		if s.readPartitions == p+1 && s.writePartitions <= p {
+			s.readPartitions = p
+		}
+		s.Unlock()
+	}
+}
+
+func (s *adaptiveScaler) isDrained(partition int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainCheckTimeout)
+	defer cancel()
+	taskListType := types.TaskListTypeDecision
+	if s.taskListID.GetType() == persistence.TaskListTypeActivity {
+		taskListType = types.TaskListTypeActivity
+	}
+	// describing the partition loads it, which dispatches its backlog
+	resp, err := s.matchingClient.DescribeTaskList(ctx, &types.MatchingDescribeTaskListRequest{
+		DomainUUID: s.taskListID.GetDomainID(),
+		DescRequest: &types.DescribeTaskListRequest{
+			Domain:                s.domainName,
+			TaskList:              &types.TaskList{Name: s.taskListID.mkName(partition), Kind: types.TaskListKindNormal.Ptr()},
+			TaskListType:          &taskListType,
+			IncludeTaskListStatus: true,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetTaskListStatus().GetBacklogCountHint() == 0, nil
+}
+
+// hasTasks checks in persistence if a partition may have tasks, it's used to find the partitions left by a previous
+// owner of the root partition without loading the partitions that don't exist
+func (s *adaptiveScaler) hasTasks(partition int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), drainCheckTimeout)
+	defer cancel()
+	resp, err := s.taskManager.GetTaskListSize(ctx, &persistence.GetTaskListSizeRequest{
+		DomainID:     s.taskListID.GetDomainID(),
+		DomainName:   s.domainName,
+		TaskListName: s.taskListID.mkName(partition),
+		TaskListType: s.taskListID.GetType(),
+		AckLevel:     0,
+	})
+	if err != nil {
+		// drain the partition to be safe, it's removed once it's found empty
+		s.logger.Warn("Failed to get size of task list partition", tag.Dynamic("partition", partition), tag.Error(err))
+		return true
+	}
+	return resp.Size > 0
+}
+
+func (s *adaptiveScaler) setPartitions(readPartitions, writePartitions int) {
+	s.Lock()
+	defer s.Unlock()
+	s.readPartitions = readPartitions
+	s.writePartitions = writePartitions
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/client/matching"
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/matching/config"
+)
+
+type adaptiveScalerTestEnv struct {
+	t              *testing.T
+	scaler         *adaptiveScaler
+	timeSource     clock.MockedTimeSource
+	matchingClient *matching.MockClient
+	taskManager    *persistence.MockTaskManager
+	backlog        int64
+	persisted      *persistence.TaskListPartitionConfig
+}
+
+func newAdaptiveScalerTestEnv(t *testing.T, enabled bool) *adaptiveScalerTestEnv {
+	controller := gomock.NewController(t)
+	env := &adaptiveScalerTestEnv{
+		t:              t,
+		timeSource:     clock.NewMockedTimeSource(),
+		matchingClient: matching.NewMockClient(controller),
+		taskManager:    persistence.NewMockTaskManager(controller),
+	}
+	taskListID, err := NewIdentifier("domain-id", "tl", persistence.TaskListTypeActivity)
+	assert.NoError(t, err)
+	cfg := &config.TaskListConfig{
+		NumReadPartitions:  func() int { return 1 },
+		NumWritePartitions: func() int { return 1 },
+		AdaptiveScalerConfig: config.AdaptiveScalerConfig{
+			EnableAdaptiveScaler:                func() bool { return enabled },
+			AdaptiveScalerUpdateInterval:        func() time.Duration { return 10 * time.Second },
+			AdaptiveScalerMaxPartitions:         func() int { return 4 },
+			PartitionUpscaleRPS:                 func() int { return 100 },
+			PartitionDownscaleRPS:               func() int { return 50 },
+			PartitionUpscaleSustainedDuration:   func() time.Duration { return 20 * time.Second },
+			PartitionDownscaleSustainedDuration: func() time.Duration { return 20 * time.Second },
+		},
+	}
+	env.scaler = newAdaptiveScaler(
+		taskListID,
+		"domain",
+		cfg,
+		env.taskManager,
+		env.matchingClient,
+		func() int64 { return env.backlog },
+		func() *persistence.TaskListPartitionConfig { return env.persisted },
+		func(partitionConfig *persistence.TaskListPartitionConfig) error {
+			env.persisted = partitionConfig
+			return nil
+		},
+		env.timeSource,
+		testlogger.New(t),
+		metrics.NewClient(tally.NoopScope, metrics.Matching).Scope(metrics.MatchingTaskListMgrScope),
+	)
+	return env
+}
+
+// tick records the given add task rate of the root partition for an update interval and runs an update
+func (env *adaptiveScalerTestEnv) tick(rootQPS int) {
+	for i := 0; i < rootQPS*10; i++ {
+		env.scaler.RecordAddTask()
+	}
+	env.timeSource.Advance(10 * time.Second)
+	env.scaler.update()
+}
+
+func (env *adaptiveScalerTestEnv) expectTaskListSize(partitions ...int64) {
+	for _, size := range partitions {
+		env.taskManager.EXPECT().GetTaskListSize(gomock.Any(), gomock.Any()).Return(&persistence.GetTaskListSizeResponse{Size: size}, nil)
+	}
+}
+
+func (env *adaptiveScalerTestEnv) expectBacklog(partition string, backlog int64) {
+	env.matchingClient.EXPECT().DescribeTaskList(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *types.MatchingDescribeTaskListRequest, _ ...interface{}) (*types.DescribeTaskListResponse, error) {
+			assert.Equal(env.t, partition, request.DescRequest.TaskList.GetName())
+			assert.True(env.t, request.DescRequest.IncludeTaskListStatus)
+			return &types.DescribeTaskListResponse{TaskListStatus: &types.TaskListStatus{BacklogCountHint: backlog}}, nil
+		})
+}
+
+func assertPartitions(t *testing.T, scaler *adaptiveScaler, read, write int) {
+	r, w := scaler.Partitions()
+	assert.Equal(t, read, r, "read partitions")
+	assert.Equal(t, write, w, "write partitions")
+}
+
+func TestAdaptiveScaler_Disabled(t *testing.T) {
+	env := newAdaptiveScalerTestEnv(t, false)
+	env.tick(1000)
+	env.tick(1000)
+	env.tick(1000)
+	assertPartitions(t, env.scaler, 1, 1)
+}
+
+func TestAdaptiveScaler_ScaleUpAndDown(t *testing.T) {
+	env := newAdaptiveScalerTestEnv(t, true)
+	// the partitions above the configured partitions are empty
+	env.expectTaskListSize(0, 0, 0)
+	env.tick(0)
+	assertPartitions(t, env.scaler, 1, 1)
+
+	// the rate has to stay above the threshold for the sustained duration
+	env.tick(250)
+	env.tick(250)
+	assertPartitions(t, env.scaler, 1, 1)
+	env.tick(250)
+	assertPartitions(t, env.scaler, 3, 3)
+	assert.Equal(t, &persistence.TaskListPartitionConfig{NumReadPartitions: 3, NumWritePartitions: 3}, env.persisted)
+
+	// a short drop of the rate doesn't scale down
+	env.tick(10)
+	env.tick(100)
+	assertPartitions(t, env.scaler, 3, 3)
+
+	// the write partitions are removed first, and the read partitions are removed once
+	// they are drained, starting from the last one
+	env.tick(10)
+	env.tick(10)
+	env.expectBacklog("/__cadence_sys/tl/2", 5)
+	env.tick(10)
+	assertPartitions(t, env.scaler, 3, 1)
+	env.expectBacklog("/__cadence_sys/tl/2", 0)
+	env.expectBacklog("/__cadence_sys/tl/1", 0)
+	env.tick(30)
+	assertPartitions(t, env.scaler, 1, 1)
+}
+
+func TestAdaptiveScaler_NoScaleDownWithBacklog(t *testing.T) {
+	env := newAdaptiveScalerTestEnv(t, true)
+	env.expectTaskListSize(0, 0, 0)
+	env.tick(0)
+	env.scaler.setPartitions(2, 2)
+
+	env.backlog = 10
+	for i := 0; i < 5; i++ {
+		env.tick(0)
+	}
+	assertPartitions(t, env.scaler, 2, 2)
+}
+
+func TestAdaptiveScaler_DrainPartitionsOfPreviousOwner(t *testing.T) {
+	env := newAdaptiveScalerTestEnv(t, true)
+	// partition 3 is empty and partition 2 has tasks left
+	env.expectTaskListSize(0, 10)
+	env.tick(0)
+	assertPartitions(t, env.scaler, 3, 1)
+
+	env.expectBacklog("/__cadence_sys/tl/2", 0)
+	env.expectBacklog("/__cadence_sys/tl/1", 0)
+	env.tick(0)
+	assertPartitions(t, env.scaler, 1, 1)
+}
+
+func TestAdaptiveScaler_StartFromPersistedPartitions(t *testing.T) {
+	env := newAdaptiveScalerTestEnv(t, true)
+	env.persisted = &persistence.TaskListPartitionConfig{NumReadPartitions: 3, NumWritePartitions: 2}
+	// only the partitions above the persisted read partitions are checked for tasks
+	env.expectTaskListSize(0)
+	env.tick(0)
+	assertPartitions(t, env.scaler, 3, 2)
+
+	env.expectBacklog("/__cadence_sys/tl/2", 0)
+	env.tick(150)
+	assertPartitions(t, env.scaler, 2, 2)
+	assert.Equal(t, &persistence.TaskListPartitionConfig{NumReadPartitions: 2, NumWritePartitions: 2}, env.persisted)
+}
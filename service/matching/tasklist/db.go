@@ -42,6 +42,9 @@ type (
 		backlogCount int64
 		store        persistence.TaskManager
 		logger       log.Logger
+		// ackLevel and partitionConfig are the last values persisted in the task list info
+		ackLevel        int64
+		partitionConfig *persistence.TaskListPartitionConfig
 	}
 	taskListState struct {
 		rangeID  int64
@@ -100,25 +103,51 @@ func (db *taskListDB) RenewLease() (taskListState, error) {
 		return taskListState{}, err
 	}
 	db.rangeID = resp.TaskListInfo.RangeID
+	db.ackLevel = resp.TaskListInfo.AckLevel
+	db.partitionConfig = resp.TaskListInfo.AdaptivePartitionConfig
 	return taskListState{rangeID: db.rangeID, ackLevel: resp.TaskListInfo.AckLevel}, nil
 }
 
+// PartitionConfig returns the persisted partition counts of the adaptive scaler, nil if the scaler never ran
+func (db *taskListDB) PartitionConfig() *persistence.TaskListPartitionConfig {
+	db.Lock()
+	defer db.Unlock()
+	return db.partitionConfig
+}
+
 // UpdateState updates the taskList state with the given value
 func (db *taskListDB) UpdateState(ackLevel int64) error {
 	db.Lock()
 	defer db.Unlock()
+	return db.updateStateLocked(ackLevel, db.partitionConfig)
+}
+
+// UpdatePartitionConfig persists the partition counts of the adaptive scaler along with the last persisted ack level
+func (db *taskListDB) UpdatePartitionConfig(partitionConfig *persistence.TaskListPartitionConfig) error {
+	db.Lock()
+	defer db.Unlock()
+	return db.updateStateLocked(db.ackLevel, partitionConfig)
+}
+
+func (db *taskListDB) updateStateLocked(ackLevel int64, partitionConfig *persistence.TaskListPartitionConfig) error {
 	_, err := db.store.UpdateTaskList(context.Background(), &persistence.UpdateTaskListRequest{
 		TaskListInfo: &persistence.TaskListInfo{
-			DomainID: db.domainID,
-			Name:     db.taskListName,
-			TaskType: db.taskType,
-			AckLevel: ackLevel,
-			RangeID:  db.rangeID,
-			Kind:     db.taskListKind,
+			DomainID:                db.domainID,
+			Name:                    db.taskListName,
+			TaskType:                db.taskType,
+			AckLevel:                ackLevel,
+			RangeID:                 db.rangeID,
+			Kind:                    db.taskListKind,
+			AdaptivePartitionConfig: partitionConfig,
 		},
 		DomainName: db.domainName,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	db.ackLevel = ackLevel
+	db.partitionConfig = partitionConfig
+	return nil
 }
 
 // CreateTasks creates a batch of given tasks for this task list
@@ -0,0 +1,55 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common/clock"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/persistence"
+)
+
+func TestTaskListDB_PartitionConfig(t *testing.T) {
+	logger := testlogger.New(t)
+	store := NewTestTaskManager(t, logger, clock.NewRealTimeSource())
+	db := newTaskListDB(store, "domain-id", "domain", "tl", persistence.TaskListTypeDecision, persistence.TaskListKindNormal, logger)
+
+	_, err := db.RenewLease()
+	assert.NoError(t, err)
+	assert.Nil(t, db.PartitionConfig())
+
+	partitionConfig := &persistence.TaskListPartitionConfig{NumReadPartitions: 3, NumWritePartitions: 2}
+	assert.NoError(t, db.UpdatePartitionConfig(partitionConfig))
+	// updating the ack level keeps the partition counts
+	assert.NoError(t, db.UpdateState(10))
+
+	// the next owner of the task list reads them
+	nextDB := newTaskListDB(store, "domain-id", "domain", "tl", persistence.TaskListTypeDecision, persistence.TaskListKindNormal, logger)
+	state, err := nextDB.RenewLease()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), state.ackLevel)
+	assert.Equal(t, partitionConfig, nextDB.PartitionConfig())
+}
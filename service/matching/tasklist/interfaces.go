@@ -53,6 +53,12 @@ type (
 		HasPollerAfter(accessTime time.Time) bool
 		// DescribeTaskList returns information about the target tasklist
 		DescribeTaskList(includeTaskListStatus bool) *types.DescribeTaskListResponse
+		// BacklogByPriority returns the number of tasks of the task list buffered in memory by priority
+		BacklogByPriority() map[int]int
+		// Partitions returns the number of read and write partitions of the task list
+		Partitions() (readPartitions int, writePartitions int)
+		// CompletedPurge returns the creation time of the purge filter of the task list if the purge of its backlog is completed
+		CompletedPurge() time.Time
 		String() string
 		GetTaskListKind() types.TaskListKind
 		TaskListID() *Identifier
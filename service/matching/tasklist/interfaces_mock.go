@@ -74,6 +74,20 @@ func (mr *MockManagerMockRecorder) AddTask(ctx, params interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTask", reflect.TypeOf((*MockManager)(nil).AddTask), ctx, params)
 }
 
+// BacklogByPriority mocks base method.
+func (m *MockManager) BacklogByPriority() map[int]int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BacklogByPriority")
+	ret0, _ := ret[0].(map[int]int)
+	return ret0
+}
+
+// BacklogByPriority indicates an expected call of BacklogByPriority.
+func (mr *MockManagerMockRecorder) BacklogByPriority() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BacklogByPriority", reflect.TypeOf((*MockManager)(nil).BacklogByPriority))
+}
+
 // CancelPoller mocks base method.
 func (m *MockManager) CancelPoller(pollerID string) {
 	m.ctrl.T.Helper()
@@ -86,6 +100,20 @@ func (mr *MockManagerMockRecorder) CancelPoller(pollerID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPoller", reflect.TypeOf((*MockManager)(nil).CancelPoller), pollerID)
 }
 
+// CompletedPurge mocks base method.
+func (m *MockManager) CompletedPurge() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletedPurge")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// CompletedPurge indicates an expected call of CompletedPurge.
+func (mr *MockManagerMockRecorder) CompletedPurge() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletedPurge", reflect.TypeOf((*MockManager)(nil).CompletedPurge))
+}
+
 // DescribeTaskList mocks base method.
 func (m *MockManager) DescribeTaskList(includeTaskListStatus bool) *types.DescribeTaskListResponse {
 	m.ctrl.T.Helper()
@@ -186,6 +214,21 @@ func (mr *MockManagerMockRecorder) HasPollerAfter(accessTime interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPollerAfter", reflect.TypeOf((*MockManager)(nil).HasPollerAfter), accessTime)
 }
 
+// Partitions mocks base method.
+func (m *MockManager) Partitions() (int, int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Partitions")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	return ret0, ret1
+}
+
+// Partitions indicates an expected call of Partitions.
+func (mr *MockManagerMockRecorder) Partitions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Partitions", reflect.TypeOf((*MockManager)(nil).Partitions))
+}
+
 // Start mocks base method.
 func (m *MockManager) Start() error {
 	m.ctrl.T.Helper()
@@ -32,6 +32,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/uber/cadence/client/history"
 	"github.com/uber/cadence/client/matching"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/backoff"
@@ -93,10 +94,12 @@ type (
 		db              *taskListDB
 		taskWriter      *taskWriter
 		taskReader      *taskReader // reads tasks from db and async matches it with poller
+		taskPurger      *taskPurger // deletes the backlog tasks selected by the purge filter of the task list
 		liveness        *liveness.Liveness
 		taskGC          *taskGC
 		taskAckManager  messaging.AckManager // tracks ackLevel for delivered messages
 		matcher         *TaskMatcher         // for matching a task producer with a poller
+		adaptiveScaler  *adaptiveScaler      // scales the partitions of the task list, only set for root partitions
 		clusterMetadata cluster.Metadata
 		domainCache     cache.DomainCache
 		partitioner     partition.Partitioner
@@ -135,6 +138,7 @@ func NewManager(
 	clusterMetadata cluster.Metadata,
 	partitioner partition.Partitioner,
 	matchingClient matching.Client,
+	historyService history.Client,
 	closeCallback func(Manager),
 	taskList *Identifier,
 	taskListKind *types.TaskListKind,
@@ -198,7 +202,23 @@ func NewManager(
 	}
 	tlMgr.matcher = newTaskMatcher(taskListConfig, fwdr, tlMgr.scope, isolationGroups, tlMgr.logger, taskList, *taskListKind)
 	tlMgr.taskWriter = newTaskWriter(tlMgr)
+	tlMgr.taskPurger = newTaskPurger(taskListConfig, db, historyService, tlMgr.logger, scope)
 	tlMgr.taskReader = newTaskReader(tlMgr, isolationGroups)
+	if taskList.IsRoot() && *taskListKind == types.TaskListKindNormal {
+		tlMgr.adaptiveScaler = newAdaptiveScaler(
+			taskList,
+			domainName,
+			taskListConfig,
+			taskManager,
+			matchingClient,
+			tlMgr.taskAckManager.GetBacklogCount,
+			db.PartitionConfig,
+			db.UpdatePartitionConfig,
+			timeSource,
+			tlMgr.logger,
+			scope,
+		)
+	}
 	tlMgr.startWG.Add(1)
 	return tlMgr, nil
 }
@@ -214,6 +234,9 @@ func (c *taskListManagerImpl) Start() error {
 		return err
 	}
 	c.taskReader.Start()
+	if c.adaptiveScaler != nil {
+		c.adaptiveScaler.Start()
+	}
 
 	return nil
 }
@@ -227,6 +250,9 @@ func (c *taskListManagerImpl) Stop() {
 	c.liveness.Stop()
 	c.taskWriter.Stop()
 	c.taskReader.Stop()
+	if c.adaptiveScaler != nil {
+		c.adaptiveScaler.Stop()
+	}
 	c.logger.Info("Task list manager state changed", tag.LifeCycleStopped)
 }
 
@@ -258,6 +284,9 @@ func (c *taskListManagerImpl) AddTask(ctx context.Context, params AddTaskParams)
 	if params.ForwardedFrom == "" {
 		// request sent by history service
 		c.liveness.MarkAlive()
+		if c.adaptiveScaler != nil {
+			c.adaptiveScaler.RecordAddTask()
+		}
 	}
 	var syncMatch bool
 	e := event.E{
@@ -293,12 +322,14 @@ func (c *taskListManagerImpl) AddTask(ctx context.Context, params AddTaskParams)
 		if err != nil {
 			return false, err
 		}
-		// active task, try sync match first
-		syncMatch, err = c.trySyncMatch(ctx, params, isolationGroup)
-		if syncMatch {
-			e.EventName = "SyncMatched so not persisted"
-			event.Log(e)
-			return &persistence.CreateTasksResponse{}, err
+		// active task, try sync match first unless dispatching is paused
+		if !c.config.Paused() {
+			syncMatch, err = c.trySyncMatch(ctx, params, isolationGroup)
+			if syncMatch {
+				e.EventName = "SyncMatched so not persisted"
+				event.Log(e)
+				return &persistence.CreateTasksResponse{}, err
+			}
 		}
 		if params.ActivityTaskDispatchInfo != nil {
 			return false, errRemoteSyncMatchFailed
@@ -481,6 +512,26 @@ func (c *taskListManagerImpl) DescribeTaskList(includeTaskListStatus bool) *type
 	return response
 }
 
+// BacklogByPriority returns the number of tasks of the task list buffered in memory by priority.
+// Tasks are only ordered by priority within the buffer, so the rest of the backlog is not broken down.
+func (c *taskListManagerImpl) BacklogByPriority() map[int]int {
+	return c.taskReader.bufferedTasksByPriority()
+}
+
+// CompletedPurge returns the creation time of the purge filter of the task list if the purge of its backlog is completed
+func (c *taskListManagerImpl) CompletedPurge() time.Time {
+	return c.taskPurger.completedPurge()
+}
+
+// Partitions returns the number of read and write partitions of the task list, they are
+// scaled by the traffic of the task list when the adaptive scaler is enabled
+func (c *taskListManagerImpl) Partitions() (int, int) {
+	if c.adaptiveScaler != nil {
+		return c.adaptiveScaler.Partitions()
+	}
+	return c.config.NumReadPartitions(), c.config.NumWritePartitions()
+}
+
 func (c *taskListManagerImpl) String() string {
 	buf := new(bytes.Buffer)
 	if c.taskListID.GetType() == persistence.TaskListTypeActivity {
@@ -494,6 +545,7 @@ func (c *taskListManagerImpl) String() string {
 	fmt.Fprintf(buf, "TaskIDBlock=%+v\n", rangeIDToTaskIDBlock(rangeID, c.config.RangeSize))
 	fmt.Fprintf(buf, "AckLevel=%v\n", c.taskAckManager.GetAckLevel())
 	fmt.Fprintf(buf, "MaxReadLevel=%v\n", c.taskAckManager.GetReadLevel())
+	fmt.Fprintf(buf, "BufferedTasksByPriority=%v\n", c.taskReader.bufferedTasksByPriority())
 
 	return buf.String()
 }
@@ -588,11 +640,9 @@ func (c *taskListManagerImpl) shouldReload() bool {
 }
 
 func (c *taskListManagerImpl) getIsolationGroupForTask(ctx context.Context, taskInfo *persistence.TaskInfo) (string, error) {
-	if c.enableIsolation && len(taskInfo.PartitionConfig) > 0 && c.taskListKind != types.TaskListKindSticky {
-		partitionConfig := make(map[string]string)
-		for k, v := range taskInfo.PartitionConfig {
-			partitionConfig[k] = v
-		}
+	// the task priority keys are not used for partitioning
+	partitionConfig := partition.WithoutTaskPriority(taskInfo.PartitionConfig)
+	if c.enableIsolation && len(partitionConfig) > 0 && c.taskListKind != types.TaskListKindSticky {
 		partitionConfig[partition.WorkflowIDKey] = taskInfo.WorkflowID
 		pollerIsolationGroups := c.config.AllIsolationGroups
 		// Not all poller information are available at the time of task list manager creation,
@@ -674,6 +724,7 @@ func rangeIDToTaskIDBlock(rangeID, rangeSize int64) taskIDBlock {
 
 func newTaskListConfig(id *Identifier, cfg *config.Config, domainName string) *config.TaskListConfig {
 	taskListName := id.GetName()
+	rootTaskListName := id.GetRoot()
 	taskType := id.GetType()
 	return &config.TaskListConfig{
 		RangeSize:          cfg.RangeSize,
@@ -721,6 +772,46 @@ func newTaskListConfig(id *Identifier, cfg *config.Config, domainName string) *c
 		AsyncTaskDispatchTimeout: func() time.Duration {
 			return cfg.AsyncTaskDispatchTimeout(domainName, taskListName, taskType)
 		},
+		EnableTaskPriority: func() bool {
+			return cfg.EnableTaskPriority(domainName, taskListName, taskType)
+		},
+		// operator controls apply to all the partitions of a task list
+		Paused: func() bool {
+			return cfg.TaskListPaused(domainName, rootTaskListName, taskType)
+		},
+		PurgeCreatedBefore: func() time.Time {
+			createdBefore := cfg.TaskListPurgeCreatedBefore(domainName, rootTaskListName, taskType)
+			if createdBefore <= 0 {
+				return time.Time{}
+			}
+			return time.Unix(int64(createdBefore), 0)
+		},
+		PurgeWorkflowType: func() string {
+			return cfg.TaskListPurgeWorkflowType(domainName, rootTaskListName, taskType)
+		},
+		AdaptiveScalerConfig: config.AdaptiveScalerConfig{
+			EnableAdaptiveScaler: func() bool {
+				return cfg.EnableAdaptiveScaler(domainName, taskListName, taskType)
+			},
+			AdaptiveScalerUpdateInterval: func() time.Duration {
+				return cfg.AdaptiveScalerUpdateInterval(domainName, taskListName, taskType)
+			},
+			AdaptiveScalerMaxPartitions: func() int {
+				return common.MaxInt(1, cfg.AdaptiveScalerMaxPartitions(domainName, taskListName, taskType))
+			},
+			PartitionUpscaleRPS: func() int {
+				return cfg.PartitionUpscaleRPS(domainName, taskListName, taskType)
+			},
+			PartitionDownscaleRPS: func() int {
+				return cfg.PartitionDownscaleRPS(domainName, taskListName, taskType)
+			},
+			PartitionUpscaleSustainedDuration: func() time.Duration {
+				return cfg.PartitionUpscaleSustainedDuration(domainName, taskListName, taskType)
+			},
+			PartitionDownscaleSustainedDuration: func() time.Duration {
+				return cfg.PartitionDownscaleSustainedDuration(domainName, taskListName, taskType)
+			},
+		},
 		ForwarderConfig: config.ForwarderConfig{
 			ForwarderMaxOutstandingPolls: func() int {
 				return cfg.ForwarderMaxOutstandingPolls(domainName, taskListName, taskType)
@@ -94,7 +94,7 @@ func TestTaskListString(t *testing.T) {
 	logger := testlogger.New(t)
 	tlm := createTestTaskListManager(t, logger, controller)
 	got := tlm.String()
-	want := "Activity task list tl\nRangeID=0\nTaskIDBlock={start:-99999 end:0}\nAckLevel=-1\nMaxReadLevel=-1\n"
+	want := "Activity task list tl\nRangeID=0\nTaskIDBlock={start:-99999 end:0}\nAckLevel=-1\nMaxReadLevel=-1\nBufferedTasksByPriority=map[]\n"
 	assert.Equal(t, want, got)
 }
 
@@ -179,7 +179,7 @@ func createTestTaskListManagerWithConfig(t *testing.T, logger log.Logger, contro
 		panic(err)
 	}
 	tlKind := types.TaskListKindNormal
-	tlMgr, err := NewManager(mockDomainCache, logger, metrics.NewClient(tally.NoopScope, metrics.Matching), tm, cluster.GetTestClusterMetadata(true), mockPartitioner, nil, func(Manager) {}, tlID, &tlKind, cfg, clock.NewRealTimeSource(), time.Now())
+	tlMgr, err := NewManager(mockDomainCache, logger, metrics.NewClient(tally.NoopScope, metrics.Matching), tm, cluster.GetTestClusterMetadata(true), mockPartitioner, nil, nil, func(Manager) {}, tlID, &tlKind, cfg, clock.NewRealTimeSource(), time.Now())
 	if err != nil {
 		logger.Fatal("error when createTestTaskListManager", tag.Error(err))
 	}
@@ -368,6 +368,54 @@ func TestAddTaskStandby(t *testing.T) {
 	require.False(t, syncMatch)
 }
 
+func TestAddTaskWhilePaused(t *testing.T) {
+	controller := gomock.NewController(t)
+	logger := testlogger.New(t)
+
+	var paused int32 = 1
+	cfg := defaultTestConfig()
+	cfg.LongPollExpirationInterval = dynamicconfig.GetDurationPropertyFnFilteredByTaskListInfo(5 * time.Second)
+	cfg.TaskListPaused = func(string, string, int) bool { return atomic.LoadInt32(&paused) == 1 }
+	tlm := createTestTaskListManagerWithConfig(t, logger, controller, cfg)
+	require.NoError(t, tlm.Start())
+	defer tlm.Stop()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// the waiting poller doesn't get the task while the task list is paused
+		ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+		defer cancel()
+		task, err := tlm.GetTask(ctx, nil)
+		if !assert.Error(t, err) {
+			task.Finish(nil)
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	syncMatch, err := tlm.AddTask(context.Background(), AddTaskParams{
+		TaskInfo: &persistence.TaskInfo{
+			DomainID:               "domain",
+			WorkflowID:             "workflow1",
+			RunID:                  "run1",
+			ScheduleID:             2,
+			ScheduleToStartTimeout: 100,
+			CreatedTime:            time.Now(),
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, syncMatch)
+	wg.Wait()
+
+	// the task is dispatched from the backlog once the task list is resumed
+	atomic.StoreInt32(&paused, 0)
+	task, err := tlm.GetTask(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "workflow1", task.Event.WorkflowID)
+	task.Finish(nil)
+}
+
 func TestGetPollerIsolationGroup(t *testing.T) {
 	controller := gomock.NewController(t)
 	logger := testlogger.New(t)
@@ -574,6 +622,7 @@ func TestTaskListManagerGetTaskBatch(t *testing.T) {
 		cluster.GetTestClusterMetadata(true),
 		mockPartitioner,
 		nil,
+		nil,
 		func(Manager) {},
 		taskListID,
 		types.TaskListKindNormal.Ptr(),
@@ -644,6 +693,7 @@ func TestTaskListManagerGetTaskBatch(t *testing.T) {
 		cluster.GetTestClusterMetadata(true),
 		mockPartitioner,
 		nil,
+		nil,
 		func(Manager) {},
 		taskListID,
 		types.TaskListKindNormal.Ptr(),
@@ -748,6 +798,7 @@ func TestTaskExpiryAndCompletion(t *testing.T) {
 				cluster.GetTestClusterMetadata(true),
 				mockPartitioner,
 				nil,
+				nil,
 				func(Manager) {},
 				taskListID,
 				types.TaskListKindNormal.Ptr(),
@@ -0,0 +1,146 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/uber/cadence/common/partition"
+	"github.com/uber/cadence/common/persistence"
+)
+
+type (
+	// taskPriorityQueue is the in-memory queue of the buffered tasks of a task list.
+	// Tasks with higher priority are dispatched first, tasks with the same priority are dispatched
+	// weighted-fair across their fairness keys, and tasks with the same fairness key are dispatched in order.
+	taskPriorityQueue struct {
+		sync.Mutex
+		levels map[int]*fairTaskQueue
+		// priorities are the priorities of the levels in descending order
+		priorities []int
+		size       int
+	}
+
+	// fairTaskQueue dispatches the tasks of the same priority with stride scheduling across fairness keys,
+	// the key with the smallest pass is dispatched next and its pass is increased by the inverse of its weight
+	fairTaskQueue struct {
+		keys map[string]*fairnessKeyQueue
+		// virtualTime is the pass of the last dispatched key, new keys start from it so that they don't
+		// take over the queue because of a pass smaller than the pass of existing keys
+		virtualTime float64
+	}
+
+	fairnessKeyQueue struct {
+		tasks  []*persistence.TaskInfo
+		pass   float64
+		weight float64
+	}
+)
+
+func newTaskPriorityQueue() *taskPriorityQueue {
+	return &taskPriorityQueue{
+		levels: make(map[int]*fairTaskQueue),
+	}
+}
+
+// Push adds a task to the queue
+func (q *taskPriorityQueue) Push(task *persistence.TaskInfo) {
+	q.Lock()
+	defer q.Unlock()
+
+	priority := partition.TaskPriority(task.PartitionConfig)
+	level, ok := q.levels[priority]
+	if !ok {
+		level = &fairTaskQueue{keys: make(map[string]*fairnessKeyQueue)}
+		q.levels[priority] = level
+		i := sort.Search(len(q.priorities), func(i int) bool { return q.priorities[i] < priority })
+		q.priorities = append(q.priorities, 0)
+		copy(q.priorities[i+1:], q.priorities[i:])
+		q.priorities[i] = priority
+	}
+	key := partition.TaskFairnessKey(task.PartitionConfig)
+	keyQueue, ok := level.keys[key]
+	if !ok {
+		keyQueue = &fairnessKeyQueue{pass: level.virtualTime}
+		level.keys[key] = keyQueue
+	}
+	keyQueue.weight = partition.TaskFairnessWeight(task.PartitionConfig)
+	keyQueue.tasks = append(keyQueue.tasks, task)
+	q.size++
+}
+
+// Pop removes and returns the next task to dispatch, it returns nil if the queue is empty
+func (q *taskPriorityQueue) Pop() *persistence.TaskInfo {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.size == 0 {
+		return nil
+	}
+	priority := q.priorities[0]
+	level := q.levels[priority]
+	var nextKey string
+	var next *fairnessKeyQueue
+	for key, keyQueue := range level.keys {
+		// ties are broken by the key to make the order deterministic
+		if next == nil || keyQueue.pass < next.pass || (keyQueue.pass == next.pass && key < nextKey) {
+			nextKey, next = key, keyQueue
+		}
+	}
+
+	task := next.tasks[0]
+	next.tasks[0] = nil
+	next.tasks = next.tasks[1:]
+	level.virtualTime = next.pass
+	next.pass += 1 / next.weight
+	if len(next.tasks) == 0 {
+		delete(level.keys, nextKey)
+	}
+	if len(level.keys) == 0 {
+		delete(q.levels, priority)
+		q.priorities = q.priorities[1:]
+	}
+	q.size--
+	return task
+}
+
+// Len returns the number of tasks in the queue
+func (q *taskPriorityQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+	return q.size
+}
+
+// CountByPriority returns the number of tasks in the queue by priority
+func (q *taskPriorityQueue) CountByPriority() map[int]int {
+	q.Lock()
+	defer q.Unlock()
+	result := make(map[int]int, len(q.levels))
+	for priority, level := range q.levels {
+		for _, keyQueue := range level.keys {
+			result[priority] += len(keyQueue.tasks)
+		}
+	}
+	return result
+}
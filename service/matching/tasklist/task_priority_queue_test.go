@@ -0,0 +1,92 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/common/partition"
+	"github.com/uber/cadence/common/persistence"
+)
+
+func TestTaskPriorityQueue_Priority(t *testing.T) {
+	q := newTaskPriorityQueue()
+	assert.Nil(t, q.Pop())
+
+	q.Push(newPriorityTask(1, 0, "", 0))
+	q.Push(newPriorityTask(2, 5, "", 0))
+	q.Push(newPriorityTask(3, -1, "", 0))
+	q.Push(newPriorityTask(4, 5, "", 0))
+	q.Push(newPriorityTask(5, 0, "", 0))
+	assert.Equal(t, 5, q.Len())
+	assert.Equal(t, map[int]int{-1: 1, 0: 2, 5: 2}, q.CountByPriority())
+
+	assert.Equal(t, []int64{2, 4, 1, 5, 3}, popTaskIDs(q))
+	assert.Equal(t, 0, q.Len())
+	assert.Nil(t, q.Pop())
+}
+
+func TestTaskPriorityQueue_Fairness(t *testing.T) {
+	q := newTaskPriorityQueue()
+	for i := int64(1); i <= 4; i++ {
+		q.Push(newPriorityTask(i, 0, "a", 2))
+	}
+	for i := int64(5); i <= 6; i++ {
+		q.Push(newPriorityTask(i, 0, "b", 1))
+	}
+	// key a is dispatched twice as often as key b, the tasks of the same key are dispatched in order
+	assert.Equal(t, []int64{1, 5, 2, 3, 6, 4}, popTaskIDs(q))
+
+	// a new key starts from the pass of the last dispatched key instead of taking over the queue
+	q.Push(newPriorityTask(1, 0, "a", 1))
+	q.Push(newPriorityTask(2, 0, "a", 1))
+	assert.Equal(t, int64(1), q.Pop().TaskID)
+	q.Push(newPriorityTask(3, 0, "a", 1))
+	q.Push(newPriorityTask(4, 0, "c", 1))
+	q.Push(newPriorityTask(5, 0, "c", 1))
+	assert.Equal(t, []int64{4, 2, 5, 3}, popTaskIDs(q))
+}
+
+func newPriorityTask(taskID int64, priority int, fairnessKey string, weight float64) *persistence.TaskInfo {
+	config := map[string]string{
+		partition.TaskPriorityKey: strconv.Itoa(priority),
+	}
+	if fairnessKey != "" {
+		config[partition.TaskFairnessKeyKey] = fairnessKey
+	}
+	if weight > 0 {
+		config[partition.TaskFairnessWeightKey] = strconv.FormatFloat(weight, 'f', -1, 64)
+	}
+	return &persistence.TaskInfo{TaskID: taskID, PartitionConfig: config}
+}
+
+func popTaskIDs(q *taskPriorityQueue) []int64 {
+	var result []int64
+	for task := q.Pop(); task != nil; task = q.Pop() {
+		result = append(result, task.TaskID)
+	}
+	return result
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber/cadence/client/history"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/matching/config"
+)
+
+const (
+	workflowTypeLookupTimeout = 5 * time.Second
+)
+
+type (
+	// purgeFilter selects the backlog tasks of a task list to purge,
+	// a task is selected when it matches all the fields that are set
+	purgeFilter struct {
+		createdBefore time.Time
+		workflowType  string
+	}
+
+	// taskPurger deletes the backlog tasks selected by the purge filter of a task list.
+	// The tasks which are already loaded in memory are purged by the task reader before
+	// they are dispatched, the tasks which are only in persistence are purged by purgeBacklog
+	taskPurger struct {
+		config         *config.TaskListConfig
+		db             *taskListDB
+		historyService history.Client
+		logger         log.Logger
+		scope          metrics.Scope
+
+		// filter used by the last purgeBacklog call and the task ID up to which it purged the backlog,
+		// the backlog is only purged again from the beginning when the filter changes
+		lastFilter      purgeFilter
+		lastPurgedLevel int64
+		// max read level of the task list when the last filter was first used, the purge of
+		// the filter is completed once all the tasks up to it are purged or dispatched
+		purgeTarget int64
+
+		sync.Mutex
+		completedFilter purgeFilter
+	}
+)
+
+func newTaskPurger(
+	config *config.TaskListConfig,
+	db *taskListDB,
+	historyService history.Client,
+	logger log.Logger,
+	scope metrics.Scope,
+) *taskPurger {
+	return &taskPurger{
+		config:         config,
+		db:             db,
+		historyService: historyService,
+		logger:         logger,
+		scope:          scope,
+	}
+}
+
+func (f purgeFilter) isEmpty() bool {
+	return f.createdBefore.IsZero() && f.workflowType == ""
+}
+
+func (p *taskPurger) filter() purgeFilter {
+	return purgeFilter{
+		createdBefore: p.config.PurgeCreatedBefore(),
+		workflowType:  p.config.PurgeWorkflowType(),
+	}
+}
+
+// shouldPurge returns whether the task is selected by the filter. workflowTypes caches the workflow
+// type of the runs looked up from history and can be nil when only a single task is checked
+func (p *taskPurger) shouldPurge(ctx context.Context, filter purgeFilter, task *persistence.TaskInfo, workflowTypes map[string]string) bool {
+	if filter.isEmpty() {
+		return false
+	}
+	if !filter.createdBefore.IsZero() && !task.CreatedTime.Before(filter.createdBefore) {
+		return false
+	}
+	if filter.workflowType != "" {
+		workflowType, err := p.getWorkflowType(ctx, task, workflowTypes)
+		if err != nil {
+			// keep the task, it is dispatched as usual if the workflow cannot be found
+			p.logger.Warn("Failed to get workflow type of task to purge",
+				tag.WorkflowID(task.WorkflowID),
+				tag.WorkflowRunID(task.RunID),
+				tag.TaskID(task.TaskID),
+				tag.Error(err))
+			return false
+		}
+		if workflowType != filter.workflowType {
+			return false
+		}
+	}
+	return true
+}
+
+// purgeBacklog deletes the tasks selected by the purge filter from the tasks in persistence
+// with an ID in (readLevel, maxReadLevel], the tasks up to readLevel are loaded in memory.
+// It returns the number of purged tasks
+func (p *taskPurger) purgeBacklog(ctx context.Context, readLevel int64, maxReadLevel int64) int {
+	filter := p.filter()
+	if filter != p.lastFilter {
+		p.lastFilter = filter
+		p.lastPurgedLevel = 0
+		p.purgeTarget = maxReadLevel
+	}
+	if filter.isEmpty() {
+		return 0
+	}
+
+	level := readLevel
+	if p.lastPurgedLevel > level {
+		level = p.lastPurgedLevel
+	}
+	purged := 0
+	workflowTypes := make(map[string]string)
+	for level < maxReadLevel && ctx.Err() == nil {
+		resp, err := p.db.GetTasks(level, maxReadLevel, p.config.GetTasksBatchSize())
+		if err != nil {
+			p.logger.Error("Persistent store operation failure",
+				tag.StoreOperationGetTasks,
+				tag.Error(err))
+			break
+		}
+		if len(resp.Tasks) == 0 {
+			level = maxReadLevel
+			break
+		}
+		for _, task := range resp.Tasks {
+			if p.shouldPurge(ctx, filter, task, workflowTypes) {
+				if err := p.db.CompleteTask(task.TaskID); err != nil {
+					// the error is logged by the db, retry from this task in the next call
+					p.lastPurgedLevel = level
+					return purged
+				}
+				p.scope.IncCounter(metrics.PurgedTasksPerTaskListCounter)
+				purged++
+			}
+			level = task.TaskID
+		}
+	}
+	p.lastPurgedLevel = level
+	if purged > 0 {
+		p.logger.Info("Purged backlog tasks of task list",
+			tag.Counter(purged),
+			tag.TaskID(level))
+	}
+	return purged
+}
+
+// checkCompleted marks the purge of the last filter as completed once the backlog which existed when
+// the filter was first used is purged. Only filters with a creation time are completed, since the
+// tasks created after it are never selected. The backlog is purged when purgeBacklog has scanned
+// persistence up to the purge target and the tasks loaded in memory, which are checked by the task
+// reader before they are dispatched, are acked up to the target or none of them is outstanding
+func (p *taskPurger) checkCompleted(ackLevel int64, outstandingTasks int64) {
+	filter := p.lastFilter
+	if filter.createdBefore.IsZero() || p.lastPurgedLevel < p.purgeTarget {
+		return
+	}
+	if ackLevel < p.purgeTarget && outstandingTasks > 0 {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	if p.completedFilter != filter {
+		p.completedFilter = filter
+		p.logger.Info("Completed purge of task list backlog", tag.TaskID(p.purgeTarget))
+	}
+}
+
+// completedPurge returns the creation time of the current purge filter if its purge is completed, or zero otherwise
+func (p *taskPurger) completedPurge() time.Time {
+	filter := p.filter()
+	p.Lock()
+	defer p.Unlock()
+	if filter.createdBefore.IsZero() || p.completedFilter != filter {
+		return time.Time{}
+	}
+	return filter.createdBefore
+}
+
+func (p *taskPurger) getWorkflowType(ctx context.Context, task *persistence.TaskInfo, workflowTypes map[string]string) (string, error) {
+	if workflowType, ok := workflowTypes[task.RunID]; ok {
+		return workflowType, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, workflowTypeLookupTimeout)
+	defer cancel()
+	resp, err := p.historyService.GetMutableState(ctx, &types.GetMutableStateRequest{
+		DomainUUID: task.DomainID,
+		Execution: &types.WorkflowExecution{
+			WorkflowID: task.WorkflowID,
+			RunID:      task.RunID,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	workflowType := resp.WorkflowType.GetName()
+	if workflowTypes != nil {
+		workflowTypes[task.RunID] = workflowType
+	}
+	return workflowType, nil
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+
+// Portions of the Software are attributed to Copyright (c) 2020 Temporal Technologies Inc.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tasklist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/client/history"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/matching/config"
+)
+
+type taskPurgerTestEnv struct {
+	purger         *taskPurger
+	taskManager    *persistence.MockTaskManager
+	historyService *history.MockClient
+	createdBefore  time.Time
+	workflowType   string
+}
+
+func newTaskPurgerTestEnv(t *testing.T) *taskPurgerTestEnv {
+	controller := gomock.NewController(t)
+	env := &taskPurgerTestEnv{
+		taskManager:    persistence.NewMockTaskManager(controller),
+		historyService: history.NewMockClient(controller),
+	}
+	cfg := &config.TaskListConfig{
+		GetTasksBatchSize:  func() int { return 2 },
+		PurgeCreatedBefore: func() time.Time { return env.createdBefore },
+		PurgeWorkflowType:  func() string { return env.workflowType },
+	}
+	logger := testlogger.New(t)
+	db := newTaskListDB(env.taskManager, "domain-id", "domain", "tl", persistence.TaskListTypeDecision, int(types.TaskListKindNormal), logger)
+	env.purger = newTaskPurger(
+		cfg,
+		db,
+		env.historyService,
+		logger,
+		metrics.NewClient(tally.NoopScope, metrics.Matching).Scope(metrics.MatchingTaskListMgrScope),
+	)
+	return env
+}
+
+func (env *taskPurgerTestEnv) expectWorkflowType(runID string, workflowType string) {
+	env.historyService.EXPECT().GetMutableState(gomock.Any(), &types.GetMutableStateRequest{
+		DomainUUID: "domain-id",
+		Execution:  &types.WorkflowExecution{WorkflowID: "wid", RunID: runID},
+	}).Return(&types.GetMutableStateResponse{WorkflowType: &types.WorkflowType{Name: workflowType}}, nil)
+}
+
+func (env *taskPurgerTestEnv) expectGetTasks(readLevel int64, maxReadLevel int64, tasks ...*persistence.TaskInfo) {
+	env.taskManager.EXPECT().GetTasks(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *persistence.GetTasksRequest) (*persistence.GetTasksResponse, error) {
+			if request.ReadLevel != readLevel || *request.MaxReadLevel != maxReadLevel {
+				return nil, errors.New("unexpected read range")
+			}
+			return &persistence.GetTasksResponse{Tasks: tasks}, nil
+		})
+}
+
+func (env *taskPurgerTestEnv) expectCompleteTask(taskID int64, err error) {
+	env.taskManager.EXPECT().CompleteTask(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *persistence.CompleteTaskRequest) error {
+			if request.TaskID != taskID {
+				return errors.New("unexpected task")
+			}
+			return err
+		})
+}
+
+func newPurgerTestTask(taskID int64, runID string, createdTime time.Time) *persistence.TaskInfo {
+	return &persistence.TaskInfo{
+		DomainID:    "domain-id",
+		WorkflowID:  "wid",
+		RunID:       runID,
+		TaskID:      taskID,
+		CreatedTime: createdTime,
+	}
+}
+
+func TestTaskPurger_ShouldPurge(t *testing.T) {
+	now := time.Now()
+	old := newPurgerTestTask(1, "rid1", now.Add(-time.Hour))
+	recent := newPurgerTestTask(2, "rid2", now)
+
+	tests := map[string]struct {
+		filter  purgeFilter
+		task    *persistence.TaskInfo
+		mockFn  func(env *taskPurgerTestEnv)
+		purgeIt bool
+	}{
+		"empty filter": {
+			filter:  purgeFilter{},
+			task:    old,
+			purgeIt: false,
+		},
+		"created before": {
+			filter:  purgeFilter{createdBefore: now.Add(-time.Minute)},
+			task:    old,
+			purgeIt: true,
+		},
+		"created after": {
+			filter:  purgeFilter{createdBefore: now.Add(-time.Minute)},
+			task:    recent,
+			purgeIt: false,
+		},
+		"workflow type matched": {
+			filter: purgeFilter{workflowType: "wt"},
+			task:   recent,
+			mockFn: func(env *taskPurgerTestEnv) {
+				env.expectWorkflowType("rid2", "wt")
+			},
+			purgeIt: true,
+		},
+		"workflow type not matched": {
+			filter: purgeFilter{workflowType: "wt"},
+			task:   recent,
+			mockFn: func(env *taskPurgerTestEnv) {
+				env.expectWorkflowType("rid2", "other")
+			},
+			purgeIt: false,
+		},
+		"workflow type lookup failed": {
+			filter: purgeFilter{workflowType: "wt"},
+			task:   recent,
+			mockFn: func(env *taskPurgerTestEnv) {
+				env.historyService.EXPECT().GetMutableState(gomock.Any(), gomock.Any()).Return(nil, &types.EntityNotExistsError{})
+			},
+			purgeIt: false,
+		},
+		"workflow type not looked up for recent task": {
+			filter:  purgeFilter{createdBefore: now.Add(-time.Minute), workflowType: "wt"},
+			task:    recent,
+			purgeIt: false,
+		},
+		"created before and workflow type matched": {
+			filter: purgeFilter{createdBefore: now.Add(-time.Minute), workflowType: "wt"},
+			task:   old,
+			mockFn: func(env *taskPurgerTestEnv) {
+				env.expectWorkflowType("rid1", "wt")
+			},
+			purgeIt: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			env := newTaskPurgerTestEnv(t)
+			if tc.mockFn != nil {
+				tc.mockFn(env)
+			}
+			assert.Equal(t, tc.purgeIt, env.purger.shouldPurge(context.Background(), tc.filter, tc.task, nil))
+		})
+	}
+}
+
+func TestTaskPurger_PurgeBacklog(t *testing.T) {
+	now := time.Now()
+	env := newTaskPurgerTestEnv(t)
+
+	// nothing is read while there is no filter
+	assert.Equal(t, 0, env.purger.purgeBacklog(context.Background(), 0, 10))
+
+	env.workflowType = "wt"
+	env.expectGetTasks(2, 10, newPurgerTestTask(3, "rid1", now), newPurgerTestTask(4, "rid2", now))
+	env.expectGetTasks(4, 10, newPurgerTestTask(5, "rid1", now))
+	env.expectGetTasks(5, 10)
+	// the workflow type of a run is only looked up once
+	env.expectWorkflowType("rid1", "wt")
+	env.expectWorkflowType("rid2", "other")
+	env.expectCompleteTask(3, nil)
+	env.expectCompleteTask(5, nil)
+	assert.Equal(t, 2, env.purger.purgeBacklog(context.Background(), 2, 10))
+
+	// the backlog which is already purged with the same filter is not read again
+	env.expectGetTasks(10, 12, newPurgerTestTask(11, "rid3", now))
+	env.expectGetTasks(11, 12)
+	env.expectWorkflowType("rid3", "other")
+	assert.Equal(t, 0, env.purger.purgeBacklog(context.Background(), 4, 12))
+
+	// the backlog is purged again from the read level when the filter changes
+	env.createdBefore = now.Add(time.Minute)
+	env.expectGetTasks(4, 12, newPurgerTestTask(11, "rid3", now))
+	env.expectGetTasks(11, 12)
+	env.expectWorkflowType("rid3", "other")
+	assert.Equal(t, 0, env.purger.purgeBacklog(context.Background(), 4, 12))
+}
+
+func TestTaskPurger_PurgeBacklogRetriesFailedTask(t *testing.T) {
+	now := time.Now()
+	env := newTaskPurgerTestEnv(t)
+	env.createdBefore = now
+
+	env.expectGetTasks(0, 10, newPurgerTestTask(1, "rid1", now.Add(-time.Minute)), newPurgerTestTask(2, "rid1", now.Add(-time.Minute)))
+	env.expectCompleteTask(1, nil)
+	env.expectCompleteTask(2, &persistence.TimeoutError{})
+	assert.Equal(t, 1, env.purger.purgeBacklog(context.Background(), 0, 10))
+
+	env.expectGetTasks(1, 10, newPurgerTestTask(2, "rid1", now.Add(-time.Minute)))
+	env.expectGetTasks(2, 10)
+	env.expectCompleteTask(2, nil)
+	assert.Equal(t, 1, env.purger.purgeBacklog(context.Background(), 0, 10))
+}
+
+func TestTaskPurger_CheckCompleted(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	env := newTaskPurgerTestEnv(t)
+
+	// a purge by workflow type only is never completed since new tasks are selected too
+	env.workflowType = "wt"
+	env.purger.purgeBacklog(context.Background(), 10, 10)
+	env.purger.checkCompleted(10, 0)
+	assert.True(t, env.purger.completedPurge().IsZero())
+
+	env.workflowType = ""
+	env.createdBefore = now
+	env.expectGetTasks(2, 10, newPurgerTestTask(3, "rid1", now.Add(-time.Minute)))
+	env.expectGetTasks(3, 10)
+	env.expectCompleteTask(3, nil)
+	env.purger.purgeBacklog(context.Background(), 2, 10)
+	// the tasks loaded in memory are not processed yet
+	env.purger.checkCompleted(2, 1)
+	assert.True(t, env.purger.completedPurge().IsZero())
+	env.purger.checkCompleted(10, 1)
+	assert.Equal(t, now, env.purger.completedPurge())
+
+	// the completion is reset when the filter changes
+	env.createdBefore = now.Add(time.Minute)
+	assert.True(t, env.purger.completedPurge().IsZero())
+	env.expectGetTasks(10, 12)
+	env.purger.purgeBacklog(context.Background(), 10, 12)
+	env.purger.checkCompleted(10, 0)
+	assert.Equal(t, now.Add(time.Minute), env.purger.completedPurge())
+}
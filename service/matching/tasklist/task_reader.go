@@ -48,6 +48,7 @@ var epochStartTime = time.Unix(0, 0)
 
 const (
 	defaultTaskBufferIsolationGroup = "" // a task buffer which is not using an isolation group
+	pausedDispatchCheckInterval     = time.Second
 )
 
 type (
@@ -57,7 +58,10 @@ type (
 		// that are enqueued for pollers to pickup. It's written to by
 		// - getTasksPump - the primary means of loading async matching tasks
 		// - task dispatch redirection - when a task is redirected from another isolation group
-		taskBuffers     map[string]chan *persistence.TaskInfo
+		taskBuffers map[string]chan *persistence.TaskInfo
+		// taskQueues: This is the in-memory queue of tasks taken from taskBuffers by the dispatchers,
+		// it orders the tasks by priority and fairness key when task priority is enabled
+		taskQueues      map[string]*taskPriorityQueue
 		notifyC         chan struct{} // Used as signal to notify pump of new tasks
 		tlMgr           *taskListManagerImpl
 		taskListID      *Identifier
@@ -65,6 +69,7 @@ type (
 		db              *taskListDB
 		taskWriter      *taskWriter
 		taskGC          *taskGC
+		taskPurger      *taskPurger
 		taskAckManager  messaging.AckManager
 		domainCache     cache.DomainCache
 		clusterMetadata cluster.Metadata
@@ -94,9 +99,12 @@ type (
 func newTaskReader(tlMgr *taskListManagerImpl, isolationGroups []string) *taskReader {
 	ctx, cancel := context.WithCancel(context.Background())
 	taskBuffers := make(map[string]chan *persistence.TaskInfo)
+	taskQueues := make(map[string]*taskPriorityQueue)
 	taskBuffers[defaultTaskBufferIsolationGroup] = make(chan *persistence.TaskInfo, tlMgr.config.GetTasksBatchSize()-1)
+	taskQueues[defaultTaskBufferIsolationGroup] = newTaskPriorityQueue()
 	for _, g := range isolationGroups {
 		taskBuffers[g] = make(chan *persistence.TaskInfo, tlMgr.config.GetTasksBatchSize()-1)
+		taskQueues[g] = newTaskPriorityQueue()
 	}
 	return &taskReader{
 		tlMgr:          tlMgr,
@@ -105,6 +113,7 @@ func newTaskReader(tlMgr *taskListManagerImpl, isolationGroups []string) *taskRe
 		db:             tlMgr.db,
 		taskWriter:     tlMgr.taskWriter,
 		taskGC:         tlMgr.taskGC,
+		taskPurger:     tlMgr.taskPurger,
 		taskAckManager: tlMgr.taskAckManager,
 		cancelCtx:      ctx,
 		cancelFunc:     cancel,
@@ -112,6 +121,7 @@ func newTaskReader(tlMgr *taskListManagerImpl, isolationGroups []string) *taskRe
 		// we always dequeue the head of the buffer and try to dispatch it to a poller
 		// so allocate one less than desired target buffer size
 		taskBuffers:              taskBuffers,
+		taskQueues:               taskQueues,
 		domainCache:              tlMgr.domainCache,
 		clusterMetadata:          tlMgr.clusterMetadata,
 		timeSource:               tlMgr.timeSource,
@@ -144,6 +154,11 @@ func (tr *taskReader) Start() {
 		defer tr.stopWg.Done()
 		tr.getTasksPump()
 	}()
+	tr.stopWg.Add(1)
+	go func() {
+		defer tr.stopWg.Done()
+		tr.purgeTasksPump()
+	}()
 }
 
 func (tr *taskReader) Stop() {
@@ -168,31 +183,72 @@ func (tr *taskReader) Signal() {
 }
 
 func (tr *taskReader) dispatchBufferedTasks(isolationGroup string) {
+	taskBuffer := tr.taskBuffers[isolationGroup]
+	taskQueue := tr.taskQueues[isolationGroup]
 dispatchLoop:
 	for {
-		select {
-		case taskInfo, ok := <-tr.taskBuffers[isolationGroup]:
-			if !ok { // Task list getTasks pump is shutdown
+		if taskQueue.Len() == 0 {
+			select {
+			case taskInfo, ok := <-taskBuffer:
+				if !ok { // Task list getTasks pump is shutdown
+					break dispatchLoop
+				}
+				taskQueue.Push(taskInfo)
+			case <-tr.cancelCtx.Done():
 				break dispatchLoop
 			}
-			event.Log(event.E{
-				TaskListName: tr.taskListID.GetName(),
-				TaskListType: tr.taskListID.GetType(),
-				TaskListKind: &tr.tlMgr.taskListKind,
-				TaskInfo:     *taskInfo,
-				EventName:    "Attempting to Dispatch Buffered Task",
-			})
-			breakDispatchLoop := tr.dispatchSingleTaskFromBufferWithRetries(isolationGroup, taskInfo)
-			if breakDispatchLoop {
-				// shutting down
-				break dispatchLoop
+		}
+		if tr.config.EnableTaskPriority() {
+			// take all the buffered tasks so that the next task is picked by priority and fairness key,
+			// the queue holds at most as many tasks as the buffer to bound the read ahead of the task list
+		drainLoop:
+			for taskQueue.Len() <= cap(taskBuffer) {
+				select {
+				case taskInfo, ok := <-taskBuffer:
+					if !ok {
+						break drainLoop
+					}
+					taskQueue.Push(taskInfo)
+				default:
+					break drainLoop
+				}
 			}
-		case <-tr.cancelCtx.Done():
+		}
+
+		taskInfo := taskQueue.Pop()
+		if !tr.waitWhilePaused() {
+			// shutting down
+			break dispatchLoop
+		}
+		if tr.purgeTask(taskInfo) {
+			continue dispatchLoop
+		}
+		event.Log(event.E{
+			TaskListName: tr.taskListID.GetName(),
+			TaskListType: tr.taskListID.GetType(),
+			TaskListKind: &tr.tlMgr.taskListKind,
+			TaskInfo:     *taskInfo,
+			EventName:    "Attempting to Dispatch Buffered Task",
+		})
+		breakDispatchLoop := tr.dispatchSingleTaskFromBufferWithRetries(isolationGroup, taskInfo)
+		if breakDispatchLoop {
+			// shutting down
 			break dispatchLoop
 		}
 	}
 }
 
+// bufferedTasksByPriority returns the number of tasks waiting in the in-memory queues by priority
+func (tr *taskReader) bufferedTasksByPriority() map[int]int {
+	result := make(map[int]int)
+	for _, taskQueue := range tr.taskQueues {
+		for priority, count := range taskQueue.CountByPriority() {
+			result[priority] += count
+		}
+	}
+	return result
+}
+
 func (tr *taskReader) getTasksPump() {
 	updateAckTimer := time.NewTimer(tr.config.UpdateAckInterval())
 	defer updateAckTimer.Stop()
@@ -244,6 +300,46 @@ getTasksPumpLoop:
 	}
 }
 
+// waitWhilePaused blocks while dispatching tasks of the task list is paused,
+// it returns false if the reader is stopped while waiting
+func (tr *taskReader) waitWhilePaused() bool {
+	for tr.config.Paused() {
+		select {
+		case <-tr.timeSource.After(pausedDispatchCheckInterval):
+		case <-tr.cancelCtx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// purgeTask completes a task loaded in memory without dispatching it if it is selected by the purge filter
+func (tr *taskReader) purgeTask(taskInfo *persistence.TaskInfo) bool {
+	if !tr.taskPurger.shouldPurge(tr.cancelCtx, tr.taskPurger.filter(), taskInfo, nil) {
+		return false
+	}
+	tr.completeTask(taskInfo, nil)
+	tr.scope.IncCounter(metrics.PurgedTasksPerTaskListCounter)
+	return true
+}
+
+// purgeTasksPump periodically purges the tasks selected by the purge filter from the
+// backlog in persistence which is not loaded in memory yet
+func (tr *taskReader) purgeTasksPump() {
+	purgeTimer := time.NewTimer(tr.config.UpdateAckInterval())
+	defer purgeTimer.Stop()
+	for {
+		select {
+		case <-tr.cancelCtx.Done():
+			return
+		case <-purgeTimer.C:
+			tr.taskPurger.purgeBacklog(tr.cancelCtx, tr.taskAckManager.GetReadLevel(), tr.taskWriter.GetMaxReadLevel())
+			tr.taskPurger.checkCompleted(tr.taskAckManager.GetAckLevel(), tr.taskAckManager.GetBacklogCount())
+			purgeTimer = time.NewTimer(tr.config.UpdateAckInterval())
+		}
+	}
+}
+
 func (tr *taskReader) getTaskBatchWithRange(readLevel int64, maxReadLevel int64) ([]*persistence.TaskInfo, error) {
 	var response *persistence.GetTasksResponse
 	op := func() (err error) {
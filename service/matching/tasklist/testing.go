@@ -52,6 +52,7 @@ type (
 		sync.Mutex
 		rangeID         int64
 		ackLevel        int64
+		partitionConfig *persistence.TaskListPartitionConfig
 		createTaskCount int
 		tasks           *treemap.Map
 	}
@@ -90,12 +91,13 @@ func (m *TestTaskManager) LeaseTaskList(
 
 	return &persistence.LeaseTaskListResponse{
 		TaskListInfo: &persistence.TaskListInfo{
-			AckLevel: tlm.ackLevel,
-			DomainID: request.DomainID,
-			Name:     request.TaskList,
-			TaskType: request.TaskType,
-			RangeID:  tlm.rangeID,
-			Kind:     request.TaskListKind,
+			AckLevel:                tlm.ackLevel,
+			DomainID:                request.DomainID,
+			Name:                    request.TaskList,
+			TaskType:                request.TaskType,
+			RangeID:                 tlm.rangeID,
+			Kind:                    request.TaskListKind,
+			AdaptivePartitionConfig: tlm.partitionConfig,
 		},
 	}, nil
 }
@@ -118,6 +120,7 @@ func (m *TestTaskManager) UpdateTaskList(
 		}
 	}
 	tlm.ackLevel = tli.AckLevel
+	tlm.partitionConfig = tli.AdaptivePartitionConfig
 	return &persistence.UpdateTaskListResponse{}, nil
 }
 
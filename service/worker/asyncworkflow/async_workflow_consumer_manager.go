@@ -36,7 +36,10 @@ import (
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/log"
 	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/membership"
 	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/service"
 	"github.com/uber/cadence/common/types"
 )
 
@@ -71,12 +74,21 @@ func WithEmitConsumerCountMetrifFn(fn func(int)) ConsumerManagerOptions {
 	}
 }
 
+// WithMembershipResolver splits the partitions of the queues which support it among the worker hosts via the membership ring.
+// Otherwise every worker host consumes all partitions.
+func WithMembershipResolver(resolver membership.Resolver) ConsumerManagerOptions {
+	return func(c *ConsumerManager) {
+		c.membershipResolver = resolver
+	}
+}
+
 func NewConsumerManager(
 	logger log.Logger,
 	metricsClient metrics.Client,
 	domainCache cache.DomainCache,
 	queueProvider queue.Provider,
 	frontendClient frontend.Client,
+	queueManager func(string) (persistence.QueueManager, error),
 	options ...ConsumerManagerOptions,
 ) *ConsumerManager {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -87,6 +99,7 @@ func NewConsumerManager(
 		domainCache:     domainCache,
 		queueProvider:   queueProvider,
 		frontendClient:  frontendClient,
+		queueManager:    queueManager,
 		refreshInterval: defaultRefreshInterval,
 		shutdownTimeout: defaultShutdownTimeout,
 		ctx:             ctx,
@@ -112,6 +125,7 @@ type ConsumerManager struct {
 	domainCache               cache.DomainCache
 	queueProvider             queue.Provider
 	frontendClient            frontend.Client
+	queueManager              func(string) (persistence.QueueManager, error)
 	refreshInterval           time.Duration
 	shutdownTimeout           time.Duration
 	ctx                       context.Context
@@ -119,6 +133,7 @@ type ConsumerManager struct {
 	wg                        sync.WaitGroup
 	activeConsumers           map[string]provider.Consumer
 	emitConsumerCountMetricFn func(int)
+	membershipResolver        membership.Resolver
 }
 
 func (c *ConsumerManager) Start() {
@@ -220,9 +235,11 @@ func (c *ConsumerManager) refreshConsumers() {
 
 		c.logger.Info("Starting consumer", tag.WorkflowDomainName(domain.GetInfo().Name), tag.AsyncWFQueueID(queue.ID()))
 		consumer, err := queue.CreateConsumer(&provider.Params{
-			Logger:         c.logger,
-			MetricsClient:  c.metricsClient,
-			FrontendClient: c.frontendClient,
+			Logger:           c.logger,
+			MetricsClient:    c.metricsClient,
+			FrontendClient:   c.frontendClient,
+			MapQQueueManager: c.queueManager,
+			OwnsPartition:    c.ownsPartition(),
 		})
 		if err != nil {
 			c.logger.Error("Failed to create consumer", tag.Error(err), tag.WorkflowDomainName(domain.GetInfo().Name), tag.AsyncWFQueueID(queue.ID()))
@@ -282,3 +299,22 @@ func (c *ConsumerManager) getQueue(cfg types.AsyncWorkflowConfiguration) (provid
 
 	return c.queueProvider.GetQueue(cfg.QueueType, cfg.QueueConfig)
 }
+
+// ownsPartition returns the function telling whether this worker host owns a queue partition in the membership ring
+func (c *ConsumerManager) ownsPartition() func(key string) (bool, error) {
+	if c.membershipResolver == nil {
+		return nil
+	}
+
+	return func(key string) (bool, error) {
+		owner, err := c.membershipResolver.Lookup(service.Worker, key)
+		if err != nil {
+			return false, err
+		}
+		self, err := c.membershipResolver.WhoAmI()
+		if err != nil {
+			return false, err
+		}
+		return owner.Identity() == self.Identity(), nil
+	}
+}
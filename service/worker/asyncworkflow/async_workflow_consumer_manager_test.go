@@ -39,8 +39,10 @@ import (
 	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/membership"
 	"github.com/uber/cadence/common/metrics"
 	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/service"
 	"github.com/uber/cadence/common/types"
 )
 
@@ -295,6 +297,7 @@ func TestConsumerManager(t *testing.T) {
 				mockDomainCache,
 				mockQueueProvider,
 				nil,
+				nil,
 				WithTimeSource(mockTimeSrc),
 			)
 
@@ -356,6 +359,7 @@ func TestConsumerManagerEnabledDisabled(t *testing.T) {
 		mockDomainCache,
 		mockQueueProvider,
 		nil,
+		nil,
 		WithTimeSource(mockTimeSrc),
 		WithEnabledPropertyFn(func(opts ...dynamicconfig.FilterOption) bool {
 			return atomic.LoadInt32(&consumerMgrEnabled) == 1
@@ -443,3 +447,38 @@ func cmpQueueIDs(activeConsumers map[string]provider.Consumer, want []string) st
 
 	return cmp.Diff(want, got)
 }
+
+func TestConsumerManagerOwnsPartition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	cm := NewConsumerManager(testlogger.New(t), metrics.NewNoopMetricsClient(), nil, nil, nil, nil)
+	if cm.ownsPartition() != nil {
+		t.Fatal("partitions should be owned by every host without a membership resolver")
+	}
+
+	resolver := membership.NewMockResolver(ctrl)
+	resolver.EXPECT().WhoAmI().Return(membership.NewHostInfo("host1"), nil).AnyTimes()
+	resolver.EXPECT().Lookup(service.Worker, "*/queue1/domain1").Return(membership.NewHostInfo("host1"), nil)
+	resolver.EXPECT().Lookup(service.Worker, "*/queue1/domain2").Return(membership.NewHostInfo("host2"), nil)
+	resolver.EXPECT().Lookup(service.Worker, "*/queue1/domain3").Return(membership.HostInfo{}, errors.New("ring not ready"))
+
+	ownsPartition := NewConsumerManager(testlogger.New(t), metrics.NewNoopMetricsClient(), nil, nil, nil, nil, WithMembershipResolver(resolver)).ownsPartition()
+	tests := []struct {
+		key       string
+		wantOwned bool
+		wantErr   bool
+	}{
+		{key: "*/queue1/domain1", wantOwned: true},
+		{key: "*/queue1/domain2", wantOwned: false},
+		{key: "*/queue1/domain3", wantErr: true},
+	}
+	for _, tc := range tests {
+		owned, err := ownsPartition(tc.key)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ownsPartition(%q) error: %v, wantErr: %v", tc.key, err, tc.wantErr)
+		}
+		if owned != tc.wantOwned {
+			t.Errorf("ownsPartition(%q) = %v, want: %v", tc.key, owned, tc.wantOwned)
+		}
+	}
+}
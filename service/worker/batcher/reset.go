@@ -0,0 +1,293 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package batcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uber/cadence/client/frontend"
+	"github.com/uber/cadence/common/types"
+)
+
+// Reset types supported by BatchTypeReset, they are the same as the ones of the reset commands of CLI
+const (
+	// ResetTypeFirstDecisionCompleted resets to the first DecisionTaskCompleted event
+	ResetTypeFirstDecisionCompleted = "FirstDecisionCompleted"
+	// ResetTypeLastDecisionCompleted resets to the last DecisionTaskCompleted event, offset by DecisionOffset
+	ResetTypeLastDecisionCompleted = "LastDecisionCompleted"
+	// ResetTypeLastContinuedAsNew resets to the last DecisionTaskCompleted event of the previous run
+	ResetTypeLastContinuedAsNew = "LastContinuedAsNew"
+	// ResetTypeBadBinary resets to the first decision completed by the binary of BadBinaryChecksum
+	ResetTypeBadBinary = "BadBinary"
+	// ResetTypeDecisionCompletedTime resets to the first DecisionTaskCompleted event after EarliestTime
+	ResetTypeDecisionCompletedTime = "DecisionCompletedTime"
+	// ResetTypeFirstDecisionScheduled resets to the first DecisionTaskScheduled event
+	ResetTypeFirstDecisionScheduled = "FirstDecisionScheduled"
+	// ResetTypeLastDecisionScheduled resets to the last DecisionTaskScheduled event, offset by DecisionOffset
+	ResetTypeLastDecisionScheduled = "LastDecisionScheduled"
+
+	resetHistoryPageSize = 1000
+)
+
+// AllResetTypes is the reset types we supported
+var AllResetTypes = []string{
+	ResetTypeFirstDecisionCompleted,
+	ResetTypeLastDecisionCompleted,
+	ResetTypeLastContinuedAsNew,
+	ResetTypeBadBinary,
+	ResetTypeDecisionCompletedTime,
+	ResetTypeFirstDecisionScheduled,
+	ResetTypeLastDecisionScheduled,
+}
+
+// errNoResetPoint is returned when the history has no event for the reset type.
+// Its message can be added to NonRetryableErrors to not retry such workflows.
+var errNoResetPoint = errors.New("no decision to reset to")
+
+func validateResetParams(params ResetParams) error {
+	switch params.ResetType {
+	case ResetTypeBadBinary:
+		if params.BadBinaryChecksum == "" {
+			return fmt.Errorf("must provide bad binary checksum for reset type %v", params.ResetType)
+		}
+	case ResetTypeDecisionCompletedTime:
+		if params.EarliestTime <= 0 {
+			return fmt.Errorf("must provide earliest time for reset type %v", params.ResetType)
+		}
+	case ResetTypeFirstDecisionCompleted,
+		ResetTypeLastDecisionCompleted,
+		ResetTypeLastContinuedAsNew,
+		ResetTypeFirstDecisionScheduled,
+		ResetTypeLastDecisionScheduled:
+	default:
+		return fmt.Errorf("not supported reset type: %v", params.ResetType)
+	}
+	if params.DecisionOffset > 0 {
+		return fmt.Errorf("only decision offset <= 0 is supported")
+	}
+	return nil
+}
+
+// getResetPoint returns the base run and the DecisionFinishEventID of the reset request for the workflow
+func getResetPoint(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+	params ResetParams,
+) (string, int64, error) {
+	var eventID int64
+	var err error
+	switch params.ResetType {
+	case ResetTypeFirstDecisionCompleted:
+		eventID, err = getFirstEventIDByType(ctx, client, domain, execution, types.EventTypeDecisionTaskCompleted)
+	case ResetTypeLastDecisionCompleted:
+		eventID, err = getLastEventIDByType(ctx, client, domain, execution, types.EventTypeDecisionTaskCompleted, params.DecisionOffset)
+	case ResetTypeLastContinuedAsNew:
+		// this reset type changes the base run to the previous one
+		return getLastContinuedAsNewResetPoint(ctx, client, domain, execution)
+	case ResetTypeBadBinary:
+		eventID, err = getBadBinaryEventID(ctx, client, domain, execution, params.BadBinaryChecksum)
+	case ResetTypeDecisionCompletedTime:
+		eventID, err = getEarliestEventIDByType(ctx, client, domain, execution, types.EventTypeDecisionTaskCompleted, params.EarliestTime)
+	case ResetTypeFirstDecisionScheduled:
+		eventID, err = getFirstEventIDByType(ctx, client, domain, execution, types.EventTypeDecisionTaskScheduled)
+		// DecisionFinishEventID is exclusive in reset API
+		eventID++
+	case ResetTypeLastDecisionScheduled:
+		eventID, err = getLastEventIDByType(ctx, client, domain, execution, types.EventTypeDecisionTaskScheduled, params.DecisionOffset)
+		// DecisionFinishEventID is exclusive in reset API
+		eventID++
+	default:
+		return "", 0, fmt.Errorf("not supported reset type: %v", params.ResetType)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return execution.GetRunID(), eventID, nil
+}
+
+func getFirstEventIDByType(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+	eventType types.EventType,
+) (int64, error) {
+	var eventID int64
+	err := scanHistory(ctx, client, domain, execution, func(e *types.HistoryEvent) bool {
+		if e.GetEventType() == eventType {
+			eventID = e.ID
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if eventID == 0 {
+		return 0, errNoResetPoint
+	}
+	return eventID, nil
+}
+
+func getLastEventIDByType(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+	eventType types.EventType,
+	decisionOffset int,
+) (int64, error) {
+	// remembers the last -decisionOffset+1 event IDs so that the first one is the offset event
+	size := -decisionOffset + 1
+	eventIDs := make([]int64, 0, size+1)
+	err := scanHistory(ctx, client, domain, execution, func(e *types.HistoryEvent) bool {
+		if e.GetEventType() == eventType {
+			eventIDs = append(eventIDs, e.ID)
+			if len(eventIDs) > size {
+				eventIDs = eventIDs[1:]
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(eventIDs) == 0 {
+		return 0, errNoResetPoint
+	}
+	return eventIDs[0], nil
+}
+
+func getEarliestEventIDByType(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+	eventType types.EventType,
+	earliestTime int64,
+) (int64, error) {
+	var eventID int64
+	err := scanHistory(ctx, client, domain, execution, func(e *types.HistoryEvent) bool {
+		if e.GetEventType() == eventType && e.GetTimestamp() >= earliestTime {
+			eventID = e.ID
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if eventID == 0 {
+		return 0, errNoResetPoint
+	}
+	return eventID, nil
+}
+
+func getBadBinaryEventID(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+	binaryChecksum string,
+) (int64, error) {
+	resp, err := client.DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain:    domain,
+		Execution: &execution,
+	})
+	if err != nil {
+		return 0, err
+	}
+	info := resp.GetWorkflowExecutionInfo()
+	if info == nil || info.AutoResetPoints == nil {
+		return 0, errNoResetPoint
+	}
+	for _, point := range info.AutoResetPoints.Points {
+		if point.GetBinaryChecksum() == binaryChecksum && point.GetResettable() {
+			return point.GetFirstDecisionCompletedID(), nil
+		}
+	}
+	return 0, errNoResetPoint
+}
+
+func getLastContinuedAsNewResetPoint(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+) (string, int64, error) {
+	resp, err := client.GetWorkflowExecutionHistory(ctx, &types.GetWorkflowExecutionHistoryRequest{
+		Domain:          domain,
+		Execution:       &execution,
+		MaximumPageSize: 1,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	events := resp.GetHistory().GetEvents()
+	if len(events) == 0 {
+		return "", 0, errNoResetPoint
+	}
+	baseRunID := events[0].GetWorkflowExecutionStartedEventAttributes().GetContinuedExecutionRunID()
+	if baseRunID == "" {
+		return "", 0, errNoResetPoint
+	}
+
+	baseExecution := types.WorkflowExecution{WorkflowID: execution.GetWorkflowID(), RunID: baseRunID}
+	eventID, err := getLastEventIDByType(ctx, client, domain, baseExecution, types.EventTypeDecisionTaskCompleted, 0)
+	if err != nil {
+		return "", 0, err
+	}
+	return baseRunID, eventID, nil
+}
+
+// scanHistory calls fn with the history events of the workflow in order until fn returns false
+func scanHistory(
+	ctx context.Context,
+	client frontend.Client,
+	domain string,
+	execution types.WorkflowExecution,
+	fn func(*types.HistoryEvent) bool,
+) error {
+	req := &types.GetWorkflowExecutionHistoryRequest{
+		Domain:          domain,
+		Execution:       &execution,
+		MaximumPageSize: resetHistoryPageSize,
+	}
+	for {
+		resp, err := client.GetWorkflowExecutionHistory(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, e := range resp.GetHistory().GetEvents() {
+			if !fn(e) {
+				return nil
+			}
+		}
+		if len(resp.NextPageToken) == 0 {
+			return nil
+		}
+		req.NextPageToken = resp.NextPageToken
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package batcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/cadence/client/frontend"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/types"
+)
+
+func TestGetResetPoint(t *testing.T) {
+	execution := types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"}
+	// history of two pages: decisions are scheduled at 2, 5, 8 and completed at 4, 7, 10
+	pages := [][]*types.HistoryEvent{
+		{
+			newEvent(1, types.EventTypeWorkflowExecutionStarted, 100),
+			newEvent(2, types.EventTypeDecisionTaskScheduled, 100),
+			newEvent(3, types.EventTypeDecisionTaskStarted, 100),
+			newEvent(4, types.EventTypeDecisionTaskCompleted, 100),
+			newEvent(5, types.EventTypeDecisionTaskScheduled, 200),
+			newEvent(6, types.EventTypeDecisionTaskStarted, 200),
+		},
+		{
+			newEvent(7, types.EventTypeDecisionTaskCompleted, 200),
+			newEvent(8, types.EventTypeDecisionTaskScheduled, 300),
+			newEvent(9, types.EventTypeDecisionTaskStarted, 300),
+			newEvent(10, types.EventTypeDecisionTaskCompleted, 300),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		params      ResetParams
+		wantEventID int64
+		wantErr     error
+	}{
+		{
+			name:        "first decision completed",
+			params:      ResetParams{ResetType: ResetTypeFirstDecisionCompleted},
+			wantEventID: 4,
+		},
+		{
+			name:        "last decision completed",
+			params:      ResetParams{ResetType: ResetTypeLastDecisionCompleted},
+			wantEventID: 10,
+		},
+		{
+			name:        "last decision completed with offset",
+			params:      ResetParams{ResetType: ResetTypeLastDecisionCompleted, DecisionOffset: -1},
+			wantEventID: 7,
+		},
+		{
+			name:        "last decision completed with offset beyond the first decision",
+			params:      ResetParams{ResetType: ResetTypeLastDecisionCompleted, DecisionOffset: -5},
+			wantEventID: 4,
+		},
+		{
+			name:        "decision completed time",
+			params:      ResetParams{ResetType: ResetTypeDecisionCompletedTime, EarliestTime: 150},
+			wantEventID: 7,
+		},
+		{
+			name:    "decision completed time after the last decision",
+			params:  ResetParams{ResetType: ResetTypeDecisionCompletedTime, EarliestTime: 400},
+			wantErr: errNoResetPoint,
+		},
+		{
+			name:        "first decision scheduled",
+			params:      ResetParams{ResetType: ResetTypeFirstDecisionScheduled},
+			wantEventID: 3,
+		},
+		{
+			name:        "last decision scheduled",
+			params:      ResetParams{ResetType: ResetTypeLastDecisionScheduled},
+			wantEventID: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := frontend.NewMockClient(gomock.NewController(t))
+			client.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, req *types.GetWorkflowExecutionHistoryRequest, _ ...interface{}) (*types.GetWorkflowExecutionHistoryResponse, error) {
+					assert.Equal(t, execution, *req.Execution)
+					if len(req.NextPageToken) == 0 {
+						return &types.GetWorkflowExecutionHistoryResponse{
+							History:       &types.History{Events: pages[0]},
+							NextPageToken: []byte("page2"),
+						}, nil
+					}
+					return &types.GetWorkflowExecutionHistoryResponse{History: &types.History{Events: pages[1]}}, nil
+				}).AnyTimes()
+
+			baseRunID, eventID, err := getResetPoint(context.Background(), client, "domain", execution, tt.params)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "rid", baseRunID)
+			assert.Equal(t, tt.wantEventID, eventID)
+		})
+	}
+}
+
+func TestGetResetPointLastContinuedAsNew(t *testing.T) {
+	client := frontend.NewMockClient(gomock.NewController(t))
+	execution := types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"}
+	started := newEvent(1, types.EventTypeWorkflowExecutionStarted, 100)
+	started.WorkflowExecutionStartedEventAttributes = &types.WorkflowExecutionStartedEventAttributes{
+		ContinuedExecutionRunID: "previous-rid",
+	}
+	client.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.GetWorkflowExecutionHistoryRequest, _ ...interface{}) (*types.GetWorkflowExecutionHistoryResponse, error) {
+			if req.Execution.RunID == "rid" {
+				return &types.GetWorkflowExecutionHistoryResponse{History: &types.History{Events: []*types.HistoryEvent{started}}}, nil
+			}
+			assert.Equal(t, "previous-rid", req.Execution.RunID)
+			return &types.GetWorkflowExecutionHistoryResponse{History: &types.History{Events: []*types.HistoryEvent{
+				newEvent(1, types.EventTypeWorkflowExecutionStarted, 10),
+				newEvent(4, types.EventTypeDecisionTaskCompleted, 10),
+				newEvent(6, types.EventTypeWorkflowExecutionContinuedAsNew, 10),
+			}}}, nil
+		}).Times(2)
+
+	baseRunID, eventID, err := getResetPoint(context.Background(), client, "domain", execution, ResetParams{ResetType: ResetTypeLastContinuedAsNew})
+	assert.NoError(t, err)
+	assert.Equal(t, "previous-rid", baseRunID)
+	assert.Equal(t, int64(4), eventID)
+}
+
+func TestGetResetPointBadBinary(t *testing.T) {
+	client := frontend.NewMockClient(gomock.NewController(t))
+	execution := types.WorkflowExecution{WorkflowID: "wid", RunID: "rid"}
+	client.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(&types.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &types.WorkflowExecutionInfo{
+			AutoResetPoints: &types.ResetPoints{Points: []*types.ResetPointInfo{
+				{BinaryChecksum: "good", FirstDecisionCompletedID: 4, Resettable: true},
+				{BinaryChecksum: "bad", FirstDecisionCompletedID: 10, Resettable: true},
+			}},
+		},
+	}, nil).Times(2)
+
+	_, eventID, err := getResetPoint(context.Background(), client, "domain", execution, ResetParams{ResetType: ResetTypeBadBinary, BadBinaryChecksum: "bad"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), eventID)
+
+	_, _, err = getResetPoint(context.Background(), client, "domain", execution, ResetParams{ResetType: ResetTypeBadBinary, BadBinaryChecksum: "unknown"})
+	assert.ErrorIs(t, err, errNoResetPoint)
+}
+
+func newEvent(id int64, eventType types.EventType, timestamp int64) *types.HistoryEvent {
+	return &types.HistoryEvent{
+		ID:        id,
+		EventType: eventType.Ptr(),
+		Timestamp: common.Int64Ptr(timestamp),
+	}
+}
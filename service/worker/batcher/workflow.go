@@ -23,6 +23,7 @@ package batcher
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -77,10 +78,29 @@ const (
 	BatchTypeSignal = "signal"
 	// BatchTypeReplicate is batch type for replicating workflows
 	BatchTypeReplicate = "replicate"
+	// BatchTypeReset is batch type for resetting workflows
+	BatchTypeReset = "reset"
+	// BatchTypeDelete is batch type for deleting closed workflows
+	BatchTypeDelete = "delete"
+	// BatchTypeTerminateIfOlderThan is batch type for terminating workflows started before a duration ago
+	BatchTypeTerminateIfOlderThan = "terminate-if-older-than"
 )
 
 // AllBatchTypes is the batch types we supported
-var AllBatchTypes = []string{BatchTypeTerminate, BatchTypeCancel, BatchTypeSignal, BatchTypeReplicate}
+//
+// There is no batch type to upsert memo or search attributes yet. They can only be changed by the workflow itself
+// through the UpsertWorkflowSearchAttributes decision, which is recorded in its history and replayed with it.
+// Writing them from the batcher needs a new history API appending such an event on behalf of an operator,
+// updating visibility directly would be overwritten by the next upsert or close of the workflow.
+var AllBatchTypes = []string{
+	BatchTypeTerminate,
+	BatchTypeCancel,
+	BatchTypeSignal,
+	BatchTypeReplicate,
+	BatchTypeReset,
+	BatchTypeDelete,
+	BatchTypeTerminateIfOlderThan,
+}
 
 type (
 	// TerminateParams is the parameters for terminating workflow
@@ -111,6 +131,31 @@ type (
 		TargetCluster string
 	}
 
+	// ResetParams is the parameters for resetting workflow
+	ResetParams struct {
+		// ResetType is one of AllResetTypes
+		ResetType string
+		// DecisionOffset moves the reset point of LastDecisionCompleted and LastDecisionScheduled
+		// back by the number of decisions. Only values <= 0 are supported.
+		DecisionOffset int
+		// BadBinaryChecksum is required for ResetTypeBadBinary
+		BadBinaryChecksum string
+		// EarliestTime in unix nanoseconds is required for ResetTypeDecisionCompletedTime
+		EarliestTime int64
+		// SkipSignalReapply skips reapplying the signals after the reset point
+		SkipSignalReapply bool
+	}
+
+	// TerminateIfOlderThanParams is the parameters for terminating workflow started before a duration ago
+	TerminateIfOlderThanParams struct {
+		// OlderThan is the minimum age of the workflows to terminate.
+		// It's evaluated when each workflow is processed, so workflows that were young when the job started
+		// are terminated if they are old enough by then.
+		OlderThan time.Duration
+		// this indicates whether to terminate children workflow. Default to true.
+		TerminateChildren *bool
+	}
+
 	// BatchParams is the parameters for batch operation workflow
 	BatchParams struct {
 		// Target domain to execute batch operation
@@ -119,7 +164,7 @@ type (
 		Query string
 		// Reason for the operation
 		Reason string
-		// Supporting: reset,terminate
+		// One of AllBatchTypes
 		BatchType string
 
 		// Below are all optional
@@ -131,6 +176,10 @@ type (
 		SignalParams SignalParams
 		// ReplicateParams is params only for BatchTypeReplicate
 		ReplicateParams ReplicateParams
+		// ResetParams is params only for BatchTypeReset
+		ResetParams ResetParams
+		// TerminateIfOlderThanParams is params only for BatchTypeTerminateIfOlderThan
+		TerminateIfOlderThanParams TerminateIfOlderThanParams
 		// RPS of processing. Default to DefaultRPS
 		// TODO we will implement smarter way than this static rate limiter: https://github.com/uber/cadence/issues/2138
 		RPS int
@@ -162,6 +211,8 @@ type (
 
 	taskDetail struct {
 		execution types.WorkflowExecution
+		// start time of the workflow in unix nanoseconds
+		startTime int64
 		attempts  int
 		// passing along the current heartbeat details to make heartbeat within a task so that it won't timeout
 		hbd HeartBeatDetails
@@ -224,9 +275,14 @@ func validateParams(params BatchParams) error {
 			return fmt.Errorf("must provide target cluster")
 		}
 		return nil
-	case BatchTypeCancel:
-		fallthrough
-	case BatchTypeTerminate:
+	case BatchTypeReset:
+		return validateResetParams(params.ResetParams)
+	case BatchTypeTerminateIfOlderThan:
+		if params.TerminateIfOlderThanParams.OlderThan <= 0 {
+			return fmt.Errorf("must provide a positive duration for older than")
+		}
+		return nil
+	case BatchTypeCancel, BatchTypeTerminate, BatchTypeDelete:
 		return nil
 	default:
 		return fmt.Errorf("not supported batch type: %v", params.BatchType)
@@ -258,6 +314,9 @@ func setDefaultParams(params BatchParams) BatchParams {
 	if params.TerminateParams.TerminateChildren == nil {
 		params.TerminateParams.TerminateChildren = common.BoolPtr(true)
 	}
+	if params.TerminateIfOlderThanParams.TerminateChildren == nil {
+		params.TerminateIfOlderThanParams.TerminateChildren = common.BoolPtr(true)
+	}
 	return params
 }
 
@@ -273,6 +332,9 @@ func BatchActivity(ctx context.Context, batchParams BatchParams) (HeartBeatDetai
 		}
 		adminClient = batcher.clientBean.GetRemoteAdminClient(batchParams.ReplicateParams.TargetCluster)
 	}
+	if batchParams.BatchType == BatchTypeDelete {
+		adminClient = batcher.clientBean.GetRemoteAdminClient(batcher.cfg.ClusterMetadata.GetCurrentClusterName())
+	}
 
 	domainResp, err := client.DescribeDomain(ctx, &types.DescribeDomainRequest{
 		Name: &batchParams.DomainName,
@@ -332,6 +394,7 @@ func BatchActivity(ctx context.Context, batchParams BatchParams) (HeartBeatDetai
 		for _, wf := range resp.Executions {
 			taskCh <- taskDetail{
 				execution: *wf.Execution,
+				startTime: wf.GetStartTime(),
 				attempts:  0,
 				hbd:       hbd,
 			}
@@ -391,7 +454,6 @@ func startTaskProcessor(
 				return
 			}
 			var err error
-			requestID := uuid.New().String()
 
 			switch batchParams.BatchType {
 			case BatchTypeTerminate:
@@ -419,7 +481,7 @@ func startTaskProcessor(
 								RunID:      runID,
 							},
 							Identity:  BatchWFTypeName,
-							RequestID: requestID,
+							RequestID: getRequestID(ctx, workflowID, runID),
 						})
 					})
 			case BatchTypeSignal:
@@ -432,7 +494,7 @@ func startTaskProcessor(
 								RunID:      runID,
 							},
 							Identity:   BatchWFTypeName,
-							RequestID:  requestID,
+							RequestID:  getRequestID(ctx, workflowID, runID),
 							SignalName: batchParams.SignalParams.SignalName,
 							Input:      []byte(batchParams.SignalParams.Input),
 						})
@@ -447,6 +509,52 @@ func startTaskProcessor(
 							RemoteCluster: batchParams.ReplicateParams.SourceCluster,
 						})
 					})
+			case BatchTypeReset:
+				err = processTask(ctx, limiter, task, batchParams, client, common.BoolPtr(false),
+					func(workflowID, runID string) error {
+						execution := types.WorkflowExecution{WorkflowID: workflowID, RunID: runID}
+						baseRunID, eventID, err := getResetPoint(ctx, client, batchParams.DomainName, execution, batchParams.ResetParams)
+						if err != nil {
+							return err
+						}
+						_, err = client.ResetWorkflowExecution(ctx, &types.ResetWorkflowExecutionRequest{
+							Domain: batchParams.DomainName,
+							WorkflowExecution: &types.WorkflowExecution{
+								WorkflowID: workflowID,
+								RunID:      baseRunID,
+							},
+							Reason:                batchParams.Reason,
+							DecisionFinishEventID: eventID,
+							RequestID:             getRequestID(ctx, workflowID, runID),
+							SkipSignalReapply:     batchParams.ResetParams.SkipSignalReapply,
+						})
+						return err
+					})
+			case BatchTypeDelete:
+				err = processTask(ctx, limiter, task, batchParams, client, common.BoolPtr(false),
+					func(workflowID, runID string) error {
+						return deleteClosedWorkflow(ctx, batchParams.DomainName, workflowID, runID, client, adminClient)
+					})
+			case BatchTypeTerminateIfOlderThan:
+				if !isOlderThan(task.startTime, batchParams.TerminateIfOlderThanParams.OlderThan, time.Now()) {
+					getActivityLogger(ctx).Info("Skipped workflow which is not old enough",
+						tag.WorkflowID(task.execution.GetWorkflowID()),
+						tag.WorkflowRunID(task.execution.GetRunID()))
+					break
+				}
+				err = processTask(ctx, limiter, task, batchParams, client,
+					batchParams.TerminateIfOlderThanParams.TerminateChildren,
+					func(workflowID, runID string) error {
+						return client.TerminateWorkflowExecution(ctx, &types.TerminateWorkflowExecutionRequest{
+							Domain: batchParams.DomainName,
+							WorkflowExecution: &types.WorkflowExecution{
+								WorkflowID: workflowID,
+								RunID:      runID,
+							},
+							Reason:   batchParams.Reason,
+							Identity: BatchWFTypeName,
+						})
+					})
 			}
 			if err != nil {
 				batcher.metricsClient.IncCounter(metrics.BatcherScope, metrics.BatcherProcessorFailures)
@@ -527,6 +635,42 @@ func processTask(
 	return nil
 }
 
+// deleteClosedWorkflow deletes the workflow if it's closed. Open workflows are skipped.
+func deleteClosedWorkflow(
+	ctx context.Context,
+	domain string,
+	workflowID string,
+	runID string,
+	client frontend.Client,
+	adminClient admin.Client,
+) error {
+	execution := &types.WorkflowExecution{
+		WorkflowID: workflowID,
+		RunID:      runID,
+	}
+	resp, err := client.DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain:    domain,
+		Execution: execution,
+	})
+	if err != nil {
+		return err
+	}
+	if info := resp.GetWorkflowExecutionInfo(); info == nil || info.CloseStatus == nil {
+		getActivityLogger(ctx).Info("Skipped deleting open workflow", tag.WorkflowID(workflowID), tag.WorkflowRunID(runID))
+		return nil
+	}
+	_, err = adminClient.DeleteWorkflow(ctx, &types.AdminDeleteWorkflowRequest{
+		Domain:    domain,
+		Execution: execution,
+	})
+	return err
+}
+
+// isOlderThan returns whether the workflow started before the duration ago. Workflows without start time are not.
+func isOlderThan(startTime int64, duration time.Duration, now time.Time) bool {
+	return startTime > 0 && now.Sub(time.Unix(0, startTime)) >= duration
+}
+
 func isDone(ctx context.Context) bool {
 	select {
 	case <-ctx.Done():
@@ -536,6 +680,18 @@ func isDone(ctx context.Context) bool {
 	}
 }
 
+// getRequestID returns the request ID of the operation of the batch job on a workflow. It is the same for every attempt,
+// so that the server dedups the operations which are retried after a timeout or a restart of the activity.
+func getRequestID(ctx context.Context, workflowID, runID string) string {
+	wfInfo := activity.GetInfo(ctx)
+	return newRequestID(wfInfo.WorkflowExecution.ID, wfInfo.WorkflowExecution.RunID, workflowID, runID)
+}
+
+func newRequestID(batchWorkflowID, batchRunID, workflowID, runID string) string {
+	name := strings.Join([]string{batchWorkflowID, batchRunID, workflowID, runID}, "/")
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
 func getActivityLogger(ctx context.Context) log.Logger {
 	batcher := ctx.Value(batcherContextKey).(*Batcher)
 	wfInfo := activity.GetInfo(ctx)
@@ -0,0 +1,148 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package batcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParams(t *testing.T) {
+	base := BatchParams{
+		DomainName: "domain",
+		Query:      "WorkflowType = 'wf'",
+		Reason:     "test",
+	}
+	tests := []struct {
+		name    string
+		modify  func(*BatchParams)
+		wantErr bool
+	}{
+		{
+			name:    "missing required parameters",
+			modify:  func(p *BatchParams) { p.BatchType = BatchTypeTerminate; p.Query = "" },
+			wantErr: true,
+		},
+		{
+			name:    "unknown batch type",
+			modify:  func(p *BatchParams) { p.BatchType = "unknown" },
+			wantErr: true,
+		},
+		{
+			name:   "terminate",
+			modify: func(p *BatchParams) { p.BatchType = BatchTypeTerminate },
+		},
+		{
+			name:   "delete",
+			modify: func(p *BatchParams) { p.BatchType = BatchTypeDelete },
+		},
+		{
+			name: "reset",
+			modify: func(p *BatchParams) {
+				p.BatchType = BatchTypeReset
+				p.ResetParams = ResetParams{ResetType: ResetTypeLastDecisionCompleted, DecisionOffset: -1}
+			},
+		},
+		{
+			name: "reset with unknown reset type",
+			modify: func(p *BatchParams) {
+				p.BatchType = BatchTypeReset
+				p.ResetParams = ResetParams{ResetType: "unknown"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "reset with positive decision offset",
+			modify: func(p *BatchParams) {
+				p.BatchType = BatchTypeReset
+				p.ResetParams = ResetParams{ResetType: ResetTypeLastDecisionCompleted, DecisionOffset: 1}
+			},
+			wantErr: true,
+		},
+		{
+			name: "reset to bad binary without checksum",
+			modify: func(p *BatchParams) {
+				p.BatchType = BatchTypeReset
+				p.ResetParams = ResetParams{ResetType: ResetTypeBadBinary}
+			},
+			wantErr: true,
+		},
+		{
+			name: "reset to decision completed time without earliest time",
+			modify: func(p *BatchParams) {
+				p.BatchType = BatchTypeReset
+				p.ResetParams = ResetParams{ResetType: ResetTypeDecisionCompletedTime}
+			},
+			wantErr: true,
+		},
+		{
+			name: "terminate if older than",
+			modify: func(p *BatchParams) {
+				p.BatchType = BatchTypeTerminateIfOlderThan
+				p.TerminateIfOlderThanParams.OlderThan = time.Hour
+			},
+		},
+		{
+			name:    "terminate if older than without duration",
+			modify:  func(p *BatchParams) { p.BatchType = BatchTypeTerminateIfOlderThan },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := base
+			tt.modify(&params)
+			err := validateParams(setDefaultParams(params))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSetDefaultParams(t *testing.T) {
+	params := setDefaultParams(BatchParams{})
+	assert.True(t, *params.TerminateParams.TerminateChildren)
+	assert.True(t, *params.TerminateIfOlderThanParams.TerminateChildren)
+	assert.Equal(t, DefaultRPS, params.RPS)
+	assert.Equal(t, DefaultConcurrency, params.Concurrency)
+}
+
+func TestIsOlderThan(t *testing.T) {
+	now := time.Now()
+	assert.True(t, isOlderThan(now.Add(-2*time.Hour).UnixNano(), time.Hour, now))
+	assert.True(t, isOlderThan(now.Add(-time.Hour).UnixNano(), time.Hour, now))
+	assert.False(t, isOlderThan(now.Add(-time.Minute).UnixNano(), time.Hour, now))
+	assert.False(t, isOlderThan(0, time.Hour, now))
+}
+
+func TestNewRequestID(t *testing.T) {
+	requestID := newRequestID("batch-wid", "batch-rid", "wid", "rid")
+	assert.Equal(t, requestID, newRequestID("batch-wid", "batch-rid", "wid", "rid"))
+	assert.NotEqual(t, requestID, newRequestID("batch-wid", "batch-rid2", "wid", "rid"))
+	assert.NotEqual(t, requestID, newRequestID("batch-wid", "batch-rid", "wid2", "rid"))
+	assert.NotEqual(t, requestID, newRequestID("batch-wid", "batch-rid", "wid", ""))
+}
@@ -0,0 +1,456 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package domaindeleter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/reconciliation/entity"
+	"github.com/uber/cadence/common/reconciliation/fetcher"
+	"github.com/uber/cadence/common/types"
+)
+
+const (
+	executionsPageSize = 100
+	visibilityPageSize = 1000
+	taskListsPageSize  = 100
+	// completeTasksLimit is the max number of tasks deleted from a task list at a time
+	completeTasksLimit = 1000
+)
+
+type (
+	deleteExecutionsHeartbeat struct {
+		// Shard is the next shard to process
+		Shard  int
+		Result deleteExecutionsActivityResult
+	}
+
+	deleteVisibilityHeartbeat struct {
+		NextPageToken []byte
+		Deleted       int
+	}
+)
+
+// ValidateDomainActivity verifies that the domain is deprecated and has no open workflows
+func ValidateDomainActivity(ctx context.Context, domainName string) (*DomainInfo, error) {
+	deleter := getDomainDeleter(ctx)
+	switch domainName {
+	case common.SystemLocalDomainName, common.SystemGlobalDomainName, common.BatcherLocalDomainName, common.ShadowerLocalDomainName:
+		return nil, cadence.NewCustomError(_nonRetriableReason, fmt.Sprintf("system domain %v cannot be deleted", domainName))
+	}
+	resp, err := deleter.resource.GetDomainManager().GetDomain(ctx, &persistence.GetDomainRequest{Name: domainName})
+	if err != nil {
+		var notExistsErr *types.EntityNotExistsError
+		if errors.As(err, &notExistsErr) {
+			return nil, cadence.NewCustomError(_nonRetriableReason, err.Error())
+		}
+		return nil, err
+	}
+	if resp.Info.Status != persistence.DomainStatusDeprecated {
+		return nil, cadence.NewCustomError(_nonRetriableReason, fmt.Sprintf("domain %v must be deprecated before it's deleted", domainName))
+	}
+
+	openResp, err := deleter.resource.GetVisibilityManager().ListOpenWorkflowExecutions(ctx, &persistence.ListWorkflowExecutionsRequest{
+		DomainUUID:   resp.Info.ID,
+		Domain:       domainName,
+		EarliestTime: 0,
+		LatestTime:   time.Now().UnixNano(),
+		PageSize:     1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(openResp.Executions) > 0 {
+		return nil, cadence.NewCustomError(_nonRetriableReason, fmt.Sprintf("domain %v has open workflows", domainName))
+	}
+
+	domain := &DomainInfo{
+		ID:               resp.Info.ID,
+		Name:             domainName,
+		IsGlobal:         resp.IsGlobalDomain,
+		NumHistoryShards: deleter.cfg.NumHistoryShards,
+	}
+	if resp.ReplicationConfig != nil {
+		for _, cluster := range resp.ReplicationConfig.Clusters {
+			domain.Clusters = append(domain.Clusters, cluster.ClusterName)
+		}
+	}
+	return domain, nil
+}
+
+// DeleteExecutionsActivity deletes the closed executions of the domain in a range of shards,
+// including their histories, current execution records and visibility records
+func DeleteExecutionsActivity(ctx context.Context, params deleteExecutionsActivityParams) (*deleteExecutionsActivityResult, error) {
+	deleter := getDomainDeleter(ctx)
+	logger := getActivityLogger(ctx).WithTags(tag.WorkflowDomainID(params.Domain.ID))
+	progress := deleteExecutionsHeartbeat{Shard: params.StartShard}
+	if activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &progress); err != nil {
+			logger.Warn("failed to load heartbeat details, starting from the first shard", tag.Error(err))
+			progress = deleteExecutionsHeartbeat{Shard: params.StartShard}
+		}
+	}
+
+	for ; progress.Shard < params.EndShard; progress.Shard++ {
+		if err := deleter.deleteExecutionsInShard(ctx, params.Domain, progress.Shard, &progress.Result); err != nil {
+			logger.Error("failed to delete executions", tag.ShardID(progress.Shard), tag.Error(err))
+			return nil, err
+		}
+		activity.RecordHeartbeat(ctx, progress)
+	}
+	return &progress.Result, nil
+}
+
+// DeleteVisibilityActivity deletes the visibility records of the domain left behind by the deleted executions,
+// e.g. the records of the executions deleted by retention before their visibility records
+func DeleteVisibilityActivity(ctx context.Context, domain DomainInfo) (int, error) {
+	deleter := getDomainDeleter(ctx)
+	visibilityManager := deleter.resource.GetVisibilityManager()
+	progress := deleteVisibilityHeartbeat{}
+	if activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &progress); err != nil {
+			progress = deleteVisibilityHeartbeat{}
+		}
+	}
+
+	request := &persistence.ListWorkflowExecutionsRequest{
+		DomainUUID:    domain.ID,
+		Domain:        domain.Name,
+		EarliestTime:  0,
+		LatestTime:    time.Now().UnixNano(),
+		PageSize:      visibilityPageSize,
+		NextPageToken: progress.NextPageToken,
+	}
+	for {
+		resp, err := visibilityManager.ListClosedWorkflowExecutions(ctx, request)
+		if err != nil {
+			return 0, err
+		}
+		for _, info := range resp.Executions {
+			if err := deleter.deleteVisibility(ctx, domain, info.GetExecution().GetWorkflowID(), info.GetExecution().GetRunID()); err != nil {
+				return 0, err
+			}
+			progress.Deleted++
+		}
+		progress.NextPageToken = resp.NextPageToken
+		activity.RecordHeartbeat(ctx, progress)
+		if len(resp.NextPageToken) == 0 {
+			return progress.Deleted, nil
+		}
+		request.NextPageToken = resp.NextPageToken
+	}
+}
+
+// DeleteTaskListsActivity deletes the task lists of the domain with their tasks.
+// The task lists are the ones used by the deleted executions, together with the ones listed from persistence
+// if the persistence supports listing task lists.
+func DeleteTaskListsActivity(ctx context.Context, params deleteTaskListsActivityParams) (int, error) {
+	deleter := getDomainDeleter(ctx)
+	logger := getActivityLogger(ctx).WithTags(tag.WorkflowDomainID(params.Domain.ID))
+	taskManager := deleter.resource.GetTaskManager()
+
+	taskLists := make(map[string]struct{})
+	for _, name := range params.TaskLists {
+		taskLists[name] = struct{}{}
+	}
+	request := &persistence.ListTaskListRequest{PageSize: taskListsPageSize}
+	for {
+		resp, err := taskManager.ListTaskList(ctx, request)
+		if err != nil {
+			var internalErr *types.InternalServiceError
+			if !errors.As(err, &internalErr) {
+				return 0, err
+			}
+			// listing task lists is not supported by NoSQL persistence
+			logger.Warn("unable to list task lists, only deleting the task lists used by the executions", tag.Error(err))
+			break
+		}
+		for _, info := range resp.Items {
+			if info.DomainID == params.Domain.ID {
+				taskLists[info.Name] = struct{}{}
+			}
+		}
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		request.PageToken = resp.NextPageToken
+	}
+
+	deleted := 0
+	for name := range taskLists {
+		for _, taskType := range []int{persistence.TaskListTypeDecision, persistence.TaskListTypeActivity} {
+			if err := deleter.deleteTaskList(ctx, params.Domain, name, taskType); err != nil {
+				logger.Error("failed to delete task list", tag.WorkflowTaskListName(name), tag.Error(err))
+				return 0, err
+			}
+		}
+		deleted++
+		activity.RecordHeartbeat(ctx, deleted)
+	}
+	return deleted, nil
+}
+
+// DeleteDomainMetadataActivity deletes the domain from the domain table
+func DeleteDomainMetadataActivity(ctx context.Context, domain DomainInfo) error {
+	deleter := getDomainDeleter(ctx)
+	err := deleter.resource.GetDomainManager().DeleteDomainByName(ctx, &persistence.DeleteDomainByNameRequest{Name: domain.Name})
+	if err != nil {
+		return err
+	}
+	getActivityLogger(ctx).Info("Deleted domain metadata", tag.WorkflowDomainName(domain.Name), tag.WorkflowDomainID(domain.ID))
+	return nil
+}
+
+// ReplicateDeletionActivity starts the deletion of a global domain on the other clusters of the domain
+// and returns the clusters the deletion is started on
+func ReplicateDeletionActivity(ctx context.Context, domain DomainInfo) ([]string, error) {
+	deleter := getDomainDeleter(ctx)
+	currentCluster := deleter.resource.GetClusterMetadata().GetCurrentClusterName()
+	input, err := json.Marshal(Params{DomainName: domain.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	var replicatedTo []string
+	for _, cluster := range domain.Clusters {
+		if cluster == currentCluster {
+			continue
+		}
+		client := deleter.resource.GetClientBean().GetRemoteFrontendClient(cluster)
+		_, err := client.StartWorkflowExecution(ctx, &types.StartWorkflowExecutionRequest{
+			Domain:                              common.SystemLocalDomainName,
+			WorkflowID:                          WorkflowID(domain.Name),
+			WorkflowType:                        &types.WorkflowType{Name: WorkflowTypeName},
+			TaskList:                            &types.TaskList{Name: TaskListName},
+			Input:                               input,
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(int32(WorkflowTimeout.Seconds())),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(int32(defaultDecisionTimeout.Seconds())),
+			Identity:                            WorkflowTypeName,
+			RequestID:                           uuid.New().String(),
+			WorkflowIDReusePolicy:               types.WorkflowIDReusePolicyAllowDuplicate.Ptr(),
+		})
+		var alreadyStartedErr *types.WorkflowExecutionAlreadyStartedError
+		if err != nil && !errors.As(err, &alreadyStartedErr) {
+			return nil, err
+		}
+		replicatedTo = append(replicatedTo, cluster)
+	}
+	return replicatedTo, nil
+}
+
+func (d *DomainDeleter) deleteExecutionsInShard(
+	ctx context.Context,
+	domain DomainInfo,
+	shardID int,
+	result *deleteExecutionsActivityResult,
+) error {
+	executionManager, err := d.resource.GetExecutionManager(shardID)
+	if err != nil {
+		return err
+	}
+	retryer := persistence.NewPersistenceRetryer(executionManager, d.resource.GetHistoryManager(), common.CreatePersistenceRetryPolicy())
+	iterator := fetcher.ConcreteExecutionIterator(ctx, retryer, executionsPageSize)
+	for iterator.HasNext() {
+		e, err := iterator.Next()
+		if err != nil {
+			return err
+		}
+		execution := e.(*entity.ConcreteExecution)
+		if execution.DomainID != domain.ID {
+			continue
+		}
+		if err := d.deleteExecution(ctx, retryer, domain, execution, result); err != nil {
+			return err
+		}
+		activity.RecordHeartbeat(ctx, deleteExecutionsHeartbeat{Shard: shardID, Result: *result})
+	}
+	return nil
+}
+
+func (d *DomainDeleter) deleteExecution(
+	ctx context.Context,
+	retryer persistence.Retryer,
+	domain DomainInfo,
+	execution *entity.ConcreteExecution,
+	result *deleteExecutionsActivityResult,
+) error {
+	resp, err := retryer.GetWorkflowExecution(ctx, &persistence.GetWorkflowExecutionRequest{
+		DomainID:   domain.ID,
+		Execution:  types.WorkflowExecution{WorkflowID: execution.WorkflowID, RunID: execution.RunID},
+		DomainName: domain.Name,
+	})
+	if err != nil {
+		var notExistsErr *types.EntityNotExistsError
+		if errors.As(err, &notExistsErr) {
+			return nil
+		}
+		return err
+	}
+	state := resp.State
+	if state.ExecutionInfo.State == persistence.WorkflowStateCreated || state.ExecutionInfo.State == persistence.WorkflowStateRunning {
+		d.logger.Warn("skipped deleting open workflow",
+			tag.WorkflowDomainID(domain.ID), tag.WorkflowID(execution.WorkflowID), tag.WorkflowRunID(execution.RunID))
+		result.SkippedOpenExecutions++
+		return nil
+	}
+
+	addTaskList(result, state.ExecutionInfo.TaskList)
+	for _, activityInfo := range state.ActivityInfos {
+		addTaskList(result, activityInfo.TaskList)
+	}
+
+	branchTokens := [][]byte{state.ExecutionInfo.BranchToken}
+	if state.VersionHistories != nil {
+		branchTokens = nil
+		for _, history := range state.VersionHistories.Histories {
+			branchTokens = append(branchTokens, history.BranchToken)
+		}
+	}
+	shardID := execution.ShardID
+	for _, branchToken := range branchTokens {
+		if len(branchToken) == 0 {
+			continue
+		}
+		if err := d.resource.GetHistoryManager().DeleteHistoryBranch(ctx, &persistence.DeleteHistoryBranchRequest{
+			BranchToken: branchToken,
+			ShardID:     &shardID,
+			DomainName:  domain.Name,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := retryer.DeleteCurrentWorkflowExecution(ctx, &persistence.DeleteCurrentWorkflowExecutionRequest{
+		DomainID:   domain.ID,
+		WorkflowID: execution.WorkflowID,
+		RunID:      execution.RunID,
+		DomainName: domain.Name,
+	}); err != nil {
+		return err
+	}
+	if err := retryer.DeleteWorkflowExecution(ctx, &persistence.DeleteWorkflowExecutionRequest{
+		DomainID:   domain.ID,
+		WorkflowID: execution.WorkflowID,
+		RunID:      execution.RunID,
+		DomainName: domain.Name,
+	}); err != nil {
+		return err
+	}
+	if err := d.deleteVisibility(ctx, domain, execution.WorkflowID, execution.RunID); err != nil {
+		return err
+	}
+	result.DeletedExecutions++
+	return nil
+}
+
+func (d *DomainDeleter) deleteVisibility(ctx context.Context, domain DomainInfo, workflowID, runID string) error {
+	key := persistence.VisibilityAdminDeletionKey("visibilityAdminDelete")
+	visCtx := context.WithValue(ctx, key, true)
+	err := d.resource.GetVisibilityManager().DeleteWorkflowExecution(visCtx, &persistence.VisibilityDeleteWorkflowExecutionRequest{
+		DomainID:   domain.ID,
+		Domain:     domain.Name,
+		RunID:      runID,
+		WorkflowID: workflowID,
+		TaskID:     math.MaxInt64,
+	})
+	var notExistsErr *types.EntityNotExistsError
+	if err != nil && !errors.As(err, &notExistsErr) {
+		return err
+	}
+	return nil
+}
+
+func (d *DomainDeleter) deleteTaskList(ctx context.Context, domain DomainInfo, name string, taskType int) error {
+	taskManager := d.resource.GetTaskManager()
+	// the lease returns the range ID required by the deletion, it creates the task list if it doesn't exist
+	resp, err := taskManager.LeaseTaskList(ctx, &persistence.LeaseTaskListRequest{
+		DomainID:   domain.ID,
+		DomainName: domain.Name,
+		TaskList:   name,
+		TaskType:   taskType,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		completeResp, err := taskManager.CompleteTasksLessThan(ctx, &persistence.CompleteTasksLessThanRequest{
+			DomainID:     domain.ID,
+			TaskListName: name,
+			TaskType:     taskType,
+			TaskID:       math.MaxInt64,
+			Limit:        completeTasksLimit,
+			DomainName:   domain.Name,
+		})
+		if err != nil {
+			return err
+		}
+		// the number of completed tasks is persistence.UnknownNumRowsAffected if the persistence doesn't support limit
+		if completeResp.TasksCompleted < completeTasksLimit {
+			break
+		}
+	}
+	return taskManager.DeleteTaskList(ctx, &persistence.DeleteTaskListRequest{
+		DomainID:     domain.ID,
+		DomainName:   domain.Name,
+		TaskListName: name,
+		TaskListType: taskType,
+		RangeID:      resp.TaskListInfo.RangeID,
+	})
+}
+
+func addTaskList(result *deleteExecutionsActivityResult, name string) {
+	if name == "" || len(result.TaskLists) >= maxTaskLists {
+		return
+	}
+	for _, taskList := range result.TaskLists {
+		if taskList == name {
+			return
+		}
+	}
+	result.TaskLists = append(result.TaskLists, name)
+}
+
+func getDomainDeleter(ctx context.Context) *DomainDeleter {
+	return ctx.Value(domainDeleterContextKey).(*DomainDeleter)
+}
+
+func getActivityLogger(ctx context.Context) log.Logger {
+	deleter := getDomainDeleter(ctx)
+	wfInfo := activity.GetInfo(ctx)
+	return deleter.logger.WithTags(
+		tag.WorkflowID(wfInfo.WorkflowExecution.ID),
+		tag.WorkflowRunID(wfInfo.WorkflowExecution.RunID),
+		tag.WorkflowDomainName(wfInfo.WorkflowDomain),
+	)
+}
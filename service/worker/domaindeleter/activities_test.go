@@ -0,0 +1,285 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package domaindeleter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/worker"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/resource"
+	"github.com/uber/cadence/common/types"
+)
+
+type activitiesTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	controller  *gomock.Controller
+	resource    *resource.Test
+	activityEnv *testsuite.TestActivityEnvironment
+
+	domain DomainInfo
+}
+
+func TestActivitiesTestSuite(t *testing.T) {
+	suite.Run(t, new(activitiesTestSuite))
+}
+
+func (s *activitiesTestSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	s.resource = resource.NewTest(s.T(), s.controller, metrics.Worker)
+	deleter := New(&BootstrapParams{
+		Config:   Config{NumHistoryShards: 4},
+		Resource: s.resource,
+	})
+
+	s.activityEnv = s.NewTestActivityEnvironment()
+	s.activityEnv.RegisterActivityWithOptions(ValidateDomainActivity, activity.RegisterOptions{Name: validateDomainActivityName})
+	s.activityEnv.RegisterActivityWithOptions(DeleteExecutionsActivity, activity.RegisterOptions{Name: deleteExecutionsActivityName})
+	s.activityEnv.RegisterActivityWithOptions(DeleteVisibilityActivity, activity.RegisterOptions{Name: deleteVisibilityActivityName})
+	s.activityEnv.RegisterActivityWithOptions(DeleteTaskListsActivity, activity.RegisterOptions{Name: deleteTaskListsActivityName})
+	s.activityEnv.RegisterActivityWithOptions(DeleteDomainMetadataActivity, activity.RegisterOptions{Name: deleteDomainMetadataActivityName})
+	s.activityEnv.RegisterActivityWithOptions(ReplicateDeletionActivity, activity.RegisterOptions{Name: replicateDeletionActivityName})
+	s.activityEnv.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), domainDeleterContextKey, deleter),
+	})
+
+	s.domain = DomainInfo{
+		ID:               "domain-id",
+		Name:             "domain",
+		IsGlobal:         true,
+		Clusters:         []string{"active", "standby"},
+		NumHistoryShards: 4,
+	}
+}
+
+func (s *activitiesTestSuite) TearDownTest() {
+	s.controller.Finish()
+	s.resource.Finish(s.T())
+}
+
+func (s *activitiesTestSuite) TestValidateDomain() {
+	s.expectGetDomain(persistence.DomainStatusDeprecated)
+	s.resource.VisibilityMgr.On("ListOpenWorkflowExecutions", mock.Anything, mock.Anything).
+		Return(&persistence.ListWorkflowExecutionsResponse{}, nil).Once()
+
+	value, err := s.activityEnv.ExecuteActivity(validateDomainActivityName, "domain")
+	s.NoError(err)
+	var domain DomainInfo
+	s.NoError(value.Get(&domain))
+	s.Equal(s.domain, domain)
+}
+
+func (s *activitiesTestSuite) TestValidateDomain_NotDeprecated() {
+	s.expectGetDomain(persistence.DomainStatusRegistered)
+
+	_, err := s.activityEnv.ExecuteActivity(validateDomainActivityName, "domain")
+	s.Error(err)
+	s.Contains(err.Error(), _nonRetriableReason)
+}
+
+func (s *activitiesTestSuite) TestValidateDomain_OpenWorkflows() {
+	s.expectGetDomain(persistence.DomainStatusDeprecated)
+	s.resource.VisibilityMgr.On("ListOpenWorkflowExecutions", mock.Anything, mock.Anything).
+		Return(&persistence.ListWorkflowExecutionsResponse{Executions: []*types.WorkflowExecutionInfo{{}}}, nil).Once()
+
+	_, err := s.activityEnv.ExecuteActivity(validateDomainActivityName, "domain")
+	s.Error(err)
+	s.Contains(err.Error(), _nonRetriableReason)
+}
+
+func (s *activitiesTestSuite) TestValidateDomain_SystemDomain() {
+	_, err := s.activityEnv.ExecuteActivity(validateDomainActivityName, common.SystemLocalDomainName)
+	s.Error(err)
+	s.Contains(err.Error(), _nonRetriableReason)
+}
+
+func (s *activitiesTestSuite) TestDeleteExecutions() {
+	closed := s.newExecution(s.domain.ID, "closed", persistence.WorkflowStateCompleted)
+	open := s.newExecution(s.domain.ID, "open", persistence.WorkflowStateRunning)
+	other := s.newExecution("other-domain-id", "other", persistence.WorkflowStateCompleted)
+	s.resource.ExecutionMgr.On("GetShardID").Return(0)
+	s.resource.ExecutionMgr.On("ListConcreteExecutions", mock.Anything, mock.Anything).
+		Return(&persistence.ListConcreteExecutionsResponse{Executions: []*persistence.ListConcreteExecutionsEntity{closed, open, other}}, nil).Once()
+	for _, e := range []*persistence.ListConcreteExecutionsEntity{closed, open} {
+		info := e.ExecutionInfo
+		s.resource.ExecutionMgr.On("GetWorkflowExecution", mock.Anything, &persistence.GetWorkflowExecutionRequest{
+			DomainID:   s.domain.ID,
+			Execution:  types.WorkflowExecution{WorkflowID: info.WorkflowID, RunID: info.RunID},
+			DomainName: s.domain.Name,
+		}).Return(&persistence.GetWorkflowExecutionResponse{State: &persistence.WorkflowMutableState{
+			ExecutionInfo: info,
+			ActivityInfos: map[int64]*persistence.ActivityInfo{5: {TaskList: "activity-tl"}},
+		}}, nil).Once()
+	}
+	shardID := 0
+	s.resource.HistoryMgr.On("DeleteHistoryBranch", mock.Anything, &persistence.DeleteHistoryBranchRequest{
+		BranchToken: closed.ExecutionInfo.BranchToken,
+		ShardID:     &shardID,
+		DomainName:  s.domain.Name,
+	}).Return(nil).Once()
+	s.resource.ExecutionMgr.On("DeleteCurrentWorkflowExecution", mock.Anything, &persistence.DeleteCurrentWorkflowExecutionRequest{
+		DomainID:   s.domain.ID,
+		WorkflowID: "closed",
+		RunID:      "closed-rid",
+		DomainName: s.domain.Name,
+	}).Return(nil).Once()
+	s.resource.ExecutionMgr.On("DeleteWorkflowExecution", mock.Anything, &persistence.DeleteWorkflowExecutionRequest{
+		DomainID:   s.domain.ID,
+		WorkflowID: "closed",
+		RunID:      "closed-rid",
+		DomainName: s.domain.Name,
+	}).Return(nil).Once()
+	s.resource.VisibilityMgr.On("DeleteWorkflowExecution", mock.Anything, mock.MatchedBy(func(req *persistence.VisibilityDeleteWorkflowExecutionRequest) bool {
+		return req.DomainID == s.domain.ID && req.WorkflowID == "closed" && req.RunID == "closed-rid"
+	})).Return(nil).Once()
+
+	value, err := s.activityEnv.ExecuteActivity(deleteExecutionsActivityName, deleteExecutionsActivityParams{
+		Domain:     s.domain,
+		StartShard: 0,
+		EndShard:   1,
+	})
+	s.NoError(err)
+	var result deleteExecutionsActivityResult
+	s.NoError(value.Get(&result))
+	s.Equal(deleteExecutionsActivityResult{
+		DeletedExecutions:     1,
+		SkippedOpenExecutions: 1,
+		TaskLists:             []string{"tl", "activity-tl"},
+	}, result)
+}
+
+func (s *activitiesTestSuite) TestDeleteVisibility() {
+	s.resource.VisibilityMgr.On("ListClosedWorkflowExecutions", mock.Anything, mock.MatchedBy(func(req *persistence.ListWorkflowExecutionsRequest) bool {
+		return req.NextPageToken == nil
+	})).Return(&persistence.ListWorkflowExecutionsResponse{
+		Executions:    []*types.WorkflowExecutionInfo{{Execution: &types.WorkflowExecution{WorkflowID: "wid1", RunID: "rid1"}}},
+		NextPageToken: []byte("token"),
+	}, nil).Once()
+	s.resource.VisibilityMgr.On("ListClosedWorkflowExecutions", mock.Anything, mock.MatchedBy(func(req *persistence.ListWorkflowExecutionsRequest) bool {
+		return string(req.NextPageToken) == "token"
+	})).Return(&persistence.ListWorkflowExecutionsResponse{
+		Executions: []*types.WorkflowExecutionInfo{{Execution: &types.WorkflowExecution{WorkflowID: "wid2", RunID: "rid2"}}},
+	}, nil).Once()
+	s.resource.VisibilityMgr.On("DeleteWorkflowExecution", mock.Anything, mock.Anything).Return(nil).Once()
+	// the record is already deleted
+	s.resource.VisibilityMgr.On("DeleteWorkflowExecution", mock.Anything, mock.Anything).Return(&types.EntityNotExistsError{}).Once()
+
+	value, err := s.activityEnv.ExecuteActivity(deleteVisibilityActivityName, s.domain)
+	s.NoError(err)
+	var deleted int
+	s.NoError(value.Get(&deleted))
+	s.Equal(2, deleted)
+}
+
+func (s *activitiesTestSuite) TestDeleteTaskLists() {
+	// listing task lists is not supported by NoSQL persistence
+	s.resource.TaskMgr.On("ListTaskList", mock.Anything, mock.Anything).
+		Return(nil, &types.InternalServiceError{Message: "unsupported operation"}).Once()
+	for _, taskType := range []int{persistence.TaskListTypeDecision, persistence.TaskListTypeActivity} {
+		taskType := taskType
+		s.resource.TaskMgr.On("LeaseTaskList", mock.Anything, mock.MatchedBy(func(req *persistence.LeaseTaskListRequest) bool {
+			return req.DomainID == s.domain.ID && req.TaskList == "tl" && req.TaskType == taskType
+		})).Return(&persistence.LeaseTaskListResponse{TaskListInfo: &persistence.TaskListInfo{RangeID: 10}}, nil).Once()
+		s.resource.TaskMgr.On("CompleteTasksLessThan", mock.Anything, mock.MatchedBy(func(req *persistence.CompleteTasksLessThanRequest) bool {
+			return req.TaskListName == "tl" && req.TaskType == taskType
+		})).Return(&persistence.CompleteTasksLessThanResponse{TasksCompleted: completeTasksLimit}, nil).Once()
+		s.resource.TaskMgr.On("CompleteTasksLessThan", mock.Anything, mock.MatchedBy(func(req *persistence.CompleteTasksLessThanRequest) bool {
+			return req.TaskListName == "tl" && req.TaskType == taskType
+		})).Return(&persistence.CompleteTasksLessThanResponse{TasksCompleted: 3}, nil).Once()
+		s.resource.TaskMgr.On("DeleteTaskList", mock.Anything, &persistence.DeleteTaskListRequest{
+			DomainID:     s.domain.ID,
+			DomainName:   s.domain.Name,
+			TaskListName: "tl",
+			TaskListType: taskType,
+			RangeID:      10,
+		}).Return(nil).Once()
+	}
+
+	value, err := s.activityEnv.ExecuteActivity(deleteTaskListsActivityName, deleteTaskListsActivityParams{
+		Domain:    s.domain,
+		TaskLists: []string{"tl"},
+	})
+	s.NoError(err)
+	var deleted int
+	s.NoError(value.Get(&deleted))
+	s.Equal(1, deleted)
+}
+
+func (s *activitiesTestSuite) TestDeleteDomainMetadata() {
+	s.resource.MetadataMgr.On("DeleteDomainByName", mock.Anything, &persistence.DeleteDomainByNameRequest{Name: "domain"}).Return(nil).Once()
+
+	_, err := s.activityEnv.ExecuteActivity(deleteDomainMetadataActivityName, s.domain)
+	s.NoError(err)
+}
+
+func (s *activitiesTestSuite) TestReplicateDeletion() {
+	s.resource.RemoteFrontendClient.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			s.Equal(common.SystemLocalDomainName, req.Domain)
+			s.Equal(WorkflowID("domain"), req.WorkflowID)
+			s.Equal(WorkflowTypeName, req.WorkflowType.GetName())
+			// the deletion is not replicated again by the other clusters
+			s.JSONEq(`{"DomainName":"domain","Replicate":false,"ShardsPerActivity":0,"Concurrency":0}`, string(req.Input))
+			return nil, &types.WorkflowExecutionAlreadyStartedError{}
+		})
+
+	value, err := s.activityEnv.ExecuteActivity(replicateDeletionActivityName, s.domain)
+	s.NoError(err)
+	var replicatedTo []string
+	s.NoError(value.Get(&replicatedTo))
+	s.Equal([]string{"standby"}, replicatedTo)
+}
+
+func (s *activitiesTestSuite) expectGetDomain(status int) {
+	s.resource.MetadataMgr.On("GetDomain", mock.Anything, &persistence.GetDomainRequest{Name: "domain"}).
+		Return(&persistence.GetDomainResponse{
+			Info: &persistence.DomainInfo{ID: s.domain.ID, Name: s.domain.Name, Status: status},
+			ReplicationConfig: &persistence.DomainReplicationConfig{
+				Clusters: []*persistence.ClusterReplicationConfig{{ClusterName: "active"}, {ClusterName: "standby"}},
+			},
+			IsGlobalDomain: true,
+		}, nil).Once()
+}
+
+func (s *activitiesTestSuite) newExecution(domainID, workflowID string, state int) *persistence.ListConcreteExecutionsEntity {
+	branchToken, err := persistence.NewHistoryBranchToken(workflowID + "-rid")
+	s.NoError(err)
+	return &persistence.ListConcreteExecutionsEntity{
+		ExecutionInfo: &persistence.WorkflowExecutionInfo{
+			DomainID:    domainID,
+			WorkflowID:  workflowID,
+			RunID:       workflowID + "-rid",
+			TaskList:    "tl",
+			State:       state,
+			BranchToken: branchToken,
+		},
+	}
+}
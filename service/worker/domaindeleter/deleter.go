@@ -0,0 +1,105 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package domaindeleter
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber-go/tally"
+	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/worker"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/resource"
+)
+
+type (
+	// Config defines the configuration for domain deleter
+	Config struct {
+		// NumHistoryShards is the number of history shards of the cluster
+		NumHistoryShards int
+	}
+
+	// BootstrapParams contains the set of params needed to bootstrap
+	// the domain deleter sub-system
+	BootstrapParams struct {
+		// Config contains the configuration for domain deleter
+		Config Config
+		// ServiceClient is an instance of cadence service client
+		ServiceClient workflowserviceclient.Interface
+		// Resource is the resource of the worker service, it provides the persistence managers and clients
+		Resource resource.Resource
+		// TallyScope is an instance of tally metrics scope
+		TallyScope tally.Scope
+	}
+
+	// DomainDeleter is the background sub-system that deletes deprecated domains and their data
+	// It is also the context object that get's passed around within the domain deletion activities
+	DomainDeleter struct {
+		cfg        Config
+		svcClient  workflowserviceclient.Interface
+		resource   resource.Resource
+		tallyScope tally.Scope
+		logger     log.Logger
+		worker     worker.Worker
+	}
+)
+
+// New returns a new instance of DomainDeleter
+func New(params *BootstrapParams) *DomainDeleter {
+	return &DomainDeleter{
+		cfg:        params.Config,
+		svcClient:  params.ServiceClient,
+		resource:   params.Resource,
+		tallyScope: params.TallyScope,
+		logger:     params.Resource.GetLogger().WithTags(tag.ComponentDomainDeleter),
+	}
+}
+
+// Start starts the worker of domain deletion workflows
+func (d *DomainDeleter) Start() error {
+	ctx := context.WithValue(context.Background(), domainDeleterContextKey, d)
+	workerOpts := worker.Options{
+		MetricsScope:              d.tallyScope,
+		BackgroundActivityContext: ctx,
+		Tracer:                    opentracing.GlobalTracer(),
+	}
+	deleterWorker := worker.New(d.svcClient, common.SystemLocalDomainName, TaskListName, workerOpts)
+	deleterWorker.RegisterWorkflowWithOptions(DeleteDomainWorkflow, workflow.RegisterOptions{Name: WorkflowTypeName})
+	deleterWorker.RegisterActivityWithOptions(ValidateDomainActivity, activity.RegisterOptions{Name: validateDomainActivityName})
+	deleterWorker.RegisterActivityWithOptions(DeleteExecutionsActivity, activity.RegisterOptions{Name: deleteExecutionsActivityName})
+	deleterWorker.RegisterActivityWithOptions(DeleteVisibilityActivity, activity.RegisterOptions{Name: deleteVisibilityActivityName})
+	deleterWorker.RegisterActivityWithOptions(DeleteTaskListsActivity, activity.RegisterOptions{Name: deleteTaskListsActivityName})
+	deleterWorker.RegisterActivityWithOptions(DeleteDomainMetadataActivity, activity.RegisterOptions{Name: deleteDomainMetadataActivityName})
+	deleterWorker.RegisterActivityWithOptions(ReplicateDeletionActivity, activity.RegisterOptions{Name: replicateDeletionActivityName})
+	d.worker = deleterWorker
+	return deleterWorker.Start()
+}
+
+// Stop stops the worker
+func (d *DomainDeleter) Stop() {
+	d.worker.Stop()
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package domaindeleter
+
+import (
+	"sort"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+type (
+	contextKey string
+)
+
+const (
+	domainDeleterContextKey contextKey = "domainDeleterContext"
+	// TaskListName is the tasklist of domain deletion workflows
+	TaskListName = "cadence-sys-domain-deleter-tasklist"
+	// WorkflowTypeName is the workflow type of domain deletion workflows
+	WorkflowTypeName = "cadence-sys-domain-deleter-workflow"
+	// WorkflowIDPrefix is the prefix of the workflow IDs of domain deletions
+	WorkflowIDPrefix = "cadence-domain-deleter"
+	// WorkflowTimeout is the execution timeout of domain deletion workflows
+	WorkflowTimeout = 30 * 24 * time.Hour
+	// QueryType for domain deletion workflow
+	QueryType = "progress"
+
+	validateDomainActivityName       = "cadence-sys-domain-deleter-validateDomain-activity"
+	deleteExecutionsActivityName     = "cadence-sys-domain-deleter-deleteExecutions-activity"
+	deleteVisibilityActivityName     = "cadence-sys-domain-deleter-deleteVisibility-activity"
+	deleteTaskListsActivityName      = "cadence-sys-domain-deleter-deleteTaskLists-activity"
+	deleteDomainMetadataActivityName = "cadence-sys-domain-deleter-deleteDomainMetadata-activity"
+	replicateDeletionActivityName    = "cadence-sys-domain-deleter-replicateDeletion-activity"
+
+	// StageValidating means the domain is being validated
+	StageValidating = "validating"
+	// StageDeletingExecutions means the executions and their histories are being deleted
+	StageDeletingExecutions = "deleting-executions"
+	// StageDeletingVisibility means the remaining visibility records are being deleted
+	StageDeletingVisibility = "deleting-visibility"
+	// StageDeletingTaskLists means the task lists are being deleted
+	StageDeletingTaskLists = "deleting-tasklists"
+	// StageDeletingMetadata means the domain metadata is being deleted
+	StageDeletingMetadata = "deleting-metadata"
+	// StageReplicating means the deletion is being started on the other clusters
+	StageReplicating = "replicating"
+	// StageCompleted means the domain is deleted
+	StageCompleted = "completed"
+
+	defaultDecisionTimeout   = 10 * time.Second
+	defaultShardsPerActivity = 32
+	defaultConcurrency       = 4
+	// maxTaskLists is the max number of task list names collected by an activity
+	maxTaskLists = 1000
+
+	_nonRetriableReason = "non-retriable-error"
+)
+
+type (
+	// Params is the input of the domain deletion workflow
+	Params struct {
+		DomainName string
+		// Replicate starts the deletion of a global domain on the other clusters of the domain
+		Replicate bool
+		// ShardsPerActivity is the number of history shards scanned by a single activity
+		ShardsPerActivity int
+		// Concurrency is the number of activities scanning the shards at the same time
+		Concurrency int
+	}
+
+	// DomainInfo is the domain to delete
+	DomainInfo struct {
+		ID               string
+		Name             string
+		IsGlobal         bool
+		Clusters         []string
+		NumHistoryShards int
+	}
+
+	// Result is the progress of the domain deletion, it's returned by QueryType and as the workflow result
+	Result struct {
+		Stage                    string
+		ProcessedShards          int
+		TotalShards              int
+		DeletedExecutions        int
+		DeletedVisibilityRecords int
+		DeletedTaskLists         int
+		ReplicatedTo             []string
+	}
+
+	deleteExecutionsActivityParams struct {
+		Domain     DomainInfo
+		StartShard int
+		// EndShard is exclusive
+		EndShard int
+	}
+
+	deleteExecutionsActivityResult struct {
+		DeletedExecutions     int
+		SkippedOpenExecutions int
+		// TaskLists are the task lists used by the deleted executions
+		TaskLists []string
+	}
+
+	deleteTaskListsActivityParams struct {
+		Domain    DomainInfo
+		TaskLists []string
+	}
+)
+
+var (
+	validateDomainActivityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          time.Second,
+			BackoffCoefficient:       2,
+			MaximumInterval:          time.Minute,
+			ExpirationInterval:       10 * time.Minute,
+			NonRetriableErrorReasons: []string{_nonRetriableReason},
+		},
+	}
+
+	// the deletion activities heartbeat their progress and resume from it when retried
+	deleteActivityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    24 * time.Hour,
+		HeartbeatTimeout:       5 * time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          10 * time.Second,
+			BackoffCoefficient:       2,
+			MaximumInterval:          5 * time.Minute,
+			ExpirationInterval:       7 * 24 * time.Hour,
+			NonRetriableErrorReasons: []string{_nonRetriableReason},
+		},
+	}
+)
+
+// WorkflowID returns the workflow ID of the deletion of the domain
+func WorkflowID(domainName string) string {
+	return WorkflowIDPrefix + ":" + domainName
+}
+
+// DeleteDomainWorkflow deletes a deprecated domain with all of its data:
+// the executions and their histories, the visibility records, the task lists and finally the domain metadata
+func DeleteDomainWorkflow(ctx workflow.Context, params Params) (*Result, error) {
+	if params.ShardsPerActivity <= 0 {
+		params.ShardsPerActivity = defaultShardsPerActivity
+	}
+	if params.Concurrency <= 0 {
+		params.Concurrency = defaultConcurrency
+	}
+	logger := workflow.GetLogger(ctx).With(zap.String("domain", params.DomainName))
+	result := &Result{Stage: StageValidating}
+	if err := workflow.SetQueryHandler(ctx, QueryType, func() (*Result, error) {
+		return result, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var domain DomainInfo
+	validateCtx := workflow.WithActivityOptions(ctx, validateDomainActivityOptions)
+	if err := workflow.ExecuteActivity(validateCtx, validateDomainActivityName, params.DomainName).Get(ctx, &domain); err != nil {
+		logger.Error("domain cannot be deleted", zap.Error(err))
+		return nil, err
+	}
+
+	deleteCtx := workflow.WithActivityOptions(ctx, deleteActivityOptions)
+	result.Stage = StageDeletingExecutions
+	result.TotalShards = domain.NumHistoryShards
+	taskLists, err := deleteExecutions(deleteCtx, params, domain, result)
+	if err != nil {
+		logger.Error("failed to delete executions", zap.Error(err))
+		return nil, err
+	}
+
+	result.Stage = StageDeletingVisibility
+	if err := workflow.ExecuteActivity(deleteCtx, deleteVisibilityActivityName, domain).Get(ctx, &result.DeletedVisibilityRecords); err != nil {
+		logger.Error("failed to delete visibility records", zap.Error(err))
+		return nil, err
+	}
+
+	result.Stage = StageDeletingTaskLists
+	if err := workflow.ExecuteActivity(deleteCtx, deleteTaskListsActivityName, deleteTaskListsActivityParams{
+		Domain:    domain,
+		TaskLists: taskLists,
+	}).Get(ctx, &result.DeletedTaskLists); err != nil {
+		logger.Error("failed to delete task lists", zap.Error(err))
+		return nil, err
+	}
+
+	result.Stage = StageDeletingMetadata
+	if err := workflow.ExecuteActivity(validateCtx, deleteDomainMetadataActivityName, domain).Get(ctx, nil); err != nil {
+		logger.Error("failed to delete domain metadata", zap.Error(err))
+		return nil, err
+	}
+
+	if params.Replicate && domain.IsGlobal {
+		result.Stage = StageReplicating
+		if err := workflow.ExecuteActivity(validateCtx, replicateDeletionActivityName, domain).Get(ctx, &result.ReplicatedTo); err != nil {
+			logger.Error("failed to replicate domain deletion", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	result.Stage = StageCompleted
+	logger.Info("domain is deleted",
+		zap.Int("deleted-executions", result.DeletedExecutions),
+		zap.Int("deleted-visibility-records", result.DeletedVisibilityRecords),
+		zap.Int("deleted-tasklists", result.DeletedTaskLists))
+	return result, nil
+}
+
+// deleteExecutions deletes the executions of the domain in all shards and returns the task lists used by them
+func deleteExecutions(ctx workflow.Context, params Params, domain DomainInfo, result *Result) ([]string, error) {
+	taskLists := make(map[string]struct{})
+	skipped := 0
+	for start := 0; start < domain.NumHistoryShards; {
+		var futures []workflow.Future
+		var shards []int
+		for i := 0; i < params.Concurrency && start < domain.NumHistoryShards; i++ {
+			end := start + params.ShardsPerActivity
+			if end > domain.NumHistoryShards {
+				end = domain.NumHistoryShards
+			}
+			futures = append(futures, workflow.ExecuteActivity(ctx, deleteExecutionsActivityName, deleteExecutionsActivityParams{
+				Domain:     domain,
+				StartShard: start,
+				EndShard:   end,
+			}))
+			shards = append(shards, end-start)
+			start = end
+		}
+		for i, future := range futures {
+			var activityResult deleteExecutionsActivityResult
+			if err := future.Get(ctx, &activityResult); err != nil {
+				return nil, err
+			}
+			result.ProcessedShards += shards[i]
+			result.DeletedExecutions += activityResult.DeletedExecutions
+			skipped += activityResult.SkippedOpenExecutions
+			for _, taskList := range activityResult.TaskLists {
+				taskLists[taskList] = struct{}{}
+			}
+		}
+	}
+	if skipped > 0 {
+		// the domain metadata is kept so that the deletion can be retried after the workflows are closed
+		return nil, cadence.NewCustomError(_nonRetriableReason,
+			"domain has open workflows, close them and delete the domain again", skipped)
+	}
+
+	names := make([]string, 0, len(taskLists))
+	for name := range taskLists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
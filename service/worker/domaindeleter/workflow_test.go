@@ -0,0 +1,147 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package domaindeleter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+)
+
+type deleteDomainWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	workflowEnv *testsuite.TestWorkflowEnvironment
+
+	domain DomainInfo
+}
+
+func TestDeleteDomainWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(deleteDomainWorkflowTestSuite))
+}
+
+func (s *deleteDomainWorkflowTestSuite) SetupTest() {
+	s.workflowEnv = s.NewTestWorkflowEnvironment()
+	s.workflowEnv.RegisterWorkflowWithOptions(DeleteDomainWorkflow, workflow.RegisterOptions{Name: WorkflowTypeName})
+	s.workflowEnv.RegisterActivityWithOptions(ValidateDomainActivity, activity.RegisterOptions{Name: validateDomainActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(DeleteExecutionsActivity, activity.RegisterOptions{Name: deleteExecutionsActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(DeleteVisibilityActivity, activity.RegisterOptions{Name: deleteVisibilityActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(DeleteTaskListsActivity, activity.RegisterOptions{Name: deleteTaskListsActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(DeleteDomainMetadataActivity, activity.RegisterOptions{Name: deleteDomainMetadataActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(ReplicateDeletionActivity, activity.RegisterOptions{Name: replicateDeletionActivityName})
+	s.domain = DomainInfo{
+		ID:               "domain-id",
+		Name:             "domain",
+		IsGlobal:         true,
+		Clusters:         []string{"active", "standby"},
+		NumHistoryShards: 40,
+	}
+}
+
+func (s *deleteDomainWorkflowTestSuite) TearDownTest() {
+	s.workflowEnv.AssertExpectations(s.T())
+}
+
+func (s *deleteDomainWorkflowTestSuite) TestDeleteDomain() {
+	s.workflowEnv.OnActivity(validateDomainActivityName, mock.Anything, "domain").Return(&s.domain, nil).Once()
+	var shardRanges [][2]int
+	s.workflowEnv.OnActivity(deleteExecutionsActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params deleteExecutionsActivityParams) (*deleteExecutionsActivityResult, error) {
+			shardRanges = append(shardRanges, [2]int{params.StartShard, params.EndShard})
+			return &deleteExecutionsActivityResult{
+				DeletedExecutions: params.EndShard - params.StartShard,
+				TaskLists:         []string{"tl-common", "tl-" + string(rune('a'+params.StartShard/16))},
+			}, nil
+		}).Times(3)
+	s.workflowEnv.OnActivity(deleteVisibilityActivityName, mock.Anything, s.domain).Return(5, nil).Once()
+	s.workflowEnv.OnActivity(deleteTaskListsActivityName, mock.Anything, deleteTaskListsActivityParams{
+		Domain:    s.domain,
+		TaskLists: []string{"tl-a", "tl-b", "tl-c", "tl-common"},
+	}).Return(4, nil).Once()
+	s.workflowEnv.OnActivity(deleteDomainMetadataActivityName, mock.Anything, s.domain).Return(nil).Once()
+	s.workflowEnv.OnActivity(replicateDeletionActivityName, mock.Anything, s.domain).Return([]string{"standby"}, nil).Once()
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, Params{
+		DomainName:        "domain",
+		Replicate:         true,
+		ShardsPerActivity: 16,
+		Concurrency:       2,
+	})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	var result Result
+	s.NoError(s.workflowEnv.GetWorkflowResult(&result))
+	s.Equal(Result{
+		Stage:                    StageCompleted,
+		ProcessedShards:          40,
+		TotalShards:              40,
+		DeletedExecutions:        40,
+		DeletedVisibilityRecords: 5,
+		DeletedTaskLists:         4,
+		ReplicatedTo:             []string{"standby"},
+	}, result)
+	s.ElementsMatch([][2]int{{0, 16}, {16, 32}, {32, 40}}, shardRanges)
+}
+
+func (s *deleteDomainWorkflowTestSuite) TestDeleteDomain_LocalOnly() {
+	s.workflowEnv.OnActivity(validateDomainActivityName, mock.Anything, "domain").Return(&s.domain, nil).Once()
+	s.workflowEnv.OnActivity(deleteExecutionsActivityName, mock.Anything, mock.Anything).
+		Return(&deleteExecutionsActivityResult{}, nil).Times(2)
+	s.workflowEnv.OnActivity(deleteVisibilityActivityName, mock.Anything, s.domain).Return(0, nil).Once()
+	s.workflowEnv.OnActivity(deleteTaskListsActivityName, mock.Anything, mock.Anything).Return(0, nil).Once()
+	s.workflowEnv.OnActivity(deleteDomainMetadataActivityName, mock.Anything, s.domain).Return(nil).Once()
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, Params{DomainName: "domain"})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+}
+
+func (s *deleteDomainWorkflowTestSuite) TestDeleteDomain_OpenExecutions() {
+	s.workflowEnv.OnActivity(validateDomainActivityName, mock.Anything, "domain").Return(&s.domain, nil).Once()
+	s.workflowEnv.OnActivity(deleteExecutionsActivityName, mock.Anything, mock.Anything).
+		Return(&deleteExecutionsActivityResult{DeletedExecutions: 1, SkippedOpenExecutions: 1}, nil).Times(2)
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, Params{DomainName: "domain", Replicate: true})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.Error(s.workflowEnv.GetWorkflowError())
+	value, err := s.workflowEnv.QueryWorkflow(QueryType)
+	s.NoError(err)
+	var result Result
+	s.NoError(value.Get(&result))
+	// the domain metadata is kept
+	s.Equal(StageDeletingExecutions, result.Stage)
+	s.Equal(2, result.DeletedExecutions)
+}
+
+func (s *deleteDomainWorkflowTestSuite) TestDeleteDomain_InvalidDomain() {
+	s.workflowEnv.OnActivity(validateDomainActivityName, mock.Anything, "domain").
+		Return(nil, cadence.NewCustomError(_nonRetriableReason, "domain domain must be deprecated before it's deleted")).Once()
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, Params{DomainName: "domain"})
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.Error(s.workflowEnv.GetWorkflowError())
+}
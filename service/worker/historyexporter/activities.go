@@ -0,0 +1,231 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package historyexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+
+	"github.com/uber/cadence/common/archiver"
+	"github.com/uber/cadence/common/blobstore"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/common/util"
+)
+
+const (
+	// FormatJSONL writes a Row per line as JSON
+	FormatJSONL = "jsonl"
+
+	// URISchemeFile writes the files to a directory of the worker hosts, e.g. file:///data/export
+	URISchemeFile = "file"
+	// URISchemeBlobstore writes the files to the blobstore of the cluster with the given key prefix, e.g. blobstore://export
+	URISchemeBlobstore = "blobstore"
+
+	historyPageSize = 1000
+
+	dirMode  = os.FileMode(0700)
+	fileMode = os.FileMode(0600)
+)
+
+// ValidateParams validates the destination, format and page size of a history export
+func ValidateParams(params Params) error {
+	if params.Domain == "" {
+		return errors.New("domain is not set")
+	}
+	if params.PageSize > MaxPageSize {
+		return fmt.Errorf("page size %v is larger than the max page size %v", params.PageSize, MaxPageSize)
+	}
+	switch params.Format {
+	case FormatJSONL:
+	default:
+		return fmt.Errorf("unsupported format %q, supported formats: %v", params.Format, FormatJSONL)
+	}
+	uri, err := archiver.NewURI(params.URI)
+	if err != nil {
+		return fmt.Errorf("invalid URI %q: %v", params.URI, err)
+	}
+	switch uri.Scheme() {
+	case URISchemeFile:
+		if uri.Path() == "" {
+			return fmt.Errorf("directory is not set in URI %q", params.URI)
+		}
+	case URISchemeBlobstore:
+		if blobKeyPrefix(uri) == "" {
+			return fmt.Errorf("key prefix is not set in URI %q", params.URI)
+		}
+	default:
+		return fmt.Errorf("unsupported URI scheme %q, supported schemes: %v, %v", uri.Scheme(), URISchemeFile, URISchemeBlobstore)
+	}
+	return nil
+}
+
+// FileName returns the name of the exported file with the given index
+func FileName(index int, format string) string {
+	return fmt.Sprintf("part-%06d.%v", index, format)
+}
+
+// ExportPageActivity exports a page of the workflows matching the visibility query to a single file
+// and returns the checkpoint of the next page
+func ExportPageActivity(ctx context.Context, params Params) (*Checkpoint, error) {
+	exporter := getHistoryExporter(ctx)
+	logger := getActivityLogger(ctx).WithTags(tag.WorkflowDomainName(params.Domain))
+	if err := ValidateParams(params); err != nil {
+		return nil, cadence.NewCustomError(_nonRetriableReason, err.Error())
+	}
+	uri, err := archiver.NewURI(params.URI)
+	if err != nil {
+		return nil, cadence.NewCustomError(_nonRetriableReason, err.Error())
+	}
+
+	client := exporter.resource.GetFrontendClient()
+	resp, err := client.ListWorkflowExecutions(ctx, &types.ListWorkflowExecutionsRequest{
+		Domain:        params.Domain,
+		PageSize:      int32(params.PageSize),
+		NextPageToken: params.Checkpoint.NextPageToken,
+		Query:         params.Query,
+	})
+	if err != nil {
+		var badRequestErr *types.BadRequestError
+		var notExistsErr *types.EntityNotExistsError
+		if errors.As(err, &badRequestErr) || errors.As(err, &notExistsErr) {
+			return nil, cadence.NewCustomError(_nonRetriableReason, err.Error())
+		}
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	rows := 0
+	for _, info := range resp.Executions {
+		events, err := exporter.getHistory(ctx, params.Domain, info.GetExecution())
+		if err != nil {
+			var notExistsErr *types.EntityNotExistsError
+			if errors.As(err, &notExistsErr) {
+				// the workflow is deleted by retention after it was listed
+				logger.Warn("skipped exporting deleted workflow",
+					tag.WorkflowID(info.GetExecution().GetWorkflowID()), tag.WorkflowRunID(info.GetExecution().GetRunID()))
+				continue
+			}
+			return nil, err
+		}
+		if err := encoder.Encode(newRow(params.Domain, info, events)); err != nil {
+			return nil, err
+		}
+		rows++
+		activity.RecordHeartbeat(ctx, rows)
+	}
+
+	checkpoint := params.Checkpoint
+	if rows > 0 {
+		name := FileName(checkpoint.NextFileIndex, params.Format)
+		if err := exporter.writeFile(ctx, uri, name, params.Format, buf.Bytes()); err != nil {
+			logger.Error("failed to write exported file", tag.Value(name), tag.Error(err))
+			return nil, err
+		}
+		checkpoint.NextFileIndex++
+		checkpoint.ExportedExecutions += rows
+	}
+	checkpoint.NextPageToken = resp.NextPageToken
+	return &checkpoint, nil
+}
+
+func (e *HistoryExporter) getHistory(ctx context.Context, domain string, execution *types.WorkflowExecution) ([]*types.HistoryEvent, error) {
+	client := e.resource.GetFrontendClient()
+	request := &types.GetWorkflowExecutionHistoryRequest{
+		Domain:          domain,
+		Execution:       execution,
+		MaximumPageSize: historyPageSize,
+	}
+	var events []*types.HistoryEvent
+	for {
+		resp, err := client.GetWorkflowExecutionHistory(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, resp.GetHistory().GetEvents()...)
+		if len(resp.NextPageToken) == 0 {
+			return events, nil
+		}
+		request.NextPageToken = resp.NextPageToken
+		activity.RecordHeartbeat(ctx)
+	}
+}
+
+// writeFile writes an exported file, an existing file with the same name is overwritten
+func (e *HistoryExporter) writeFile(ctx context.Context, uri archiver.URI, name string, format string, body []byte) error {
+	switch uri.Scheme() {
+	case URISchemeFile:
+		if err := util.MkdirAll(uri.Path(), dirMode); err != nil {
+			return err
+		}
+		return util.WriteFile(filepath.Join(uri.Path(), name), body, fileMode)
+	case URISchemeBlobstore:
+		client := e.resource.GetBlobstoreClient()
+		if client == nil {
+			return cadence.NewCustomError(_nonRetriableReason, "blobstore is not configured")
+		}
+		_, err := client.Put(ctx, &blobstore.PutRequest{
+			// the filestore blobstore doesn't create nested directories, so the prefix isn't a path
+			Key: blobKeyPrefix(uri) + "." + name,
+			Blob: blobstore.Blob{
+				Tags: map[string]string{
+					"schemaVersion": strconv.Itoa(SchemaVersion),
+					"format":        format,
+				},
+				Body: body,
+			},
+		})
+		return err
+	default:
+		return cadence.NewCustomError(_nonRetriableReason, fmt.Sprintf("unsupported URI scheme %q", uri.Scheme()))
+	}
+}
+
+func blobKeyPrefix(uri archiver.URI) string {
+	return strings.Trim(strings.ReplaceAll(uri.Hostname()+uri.Path(), "/", "."), ".")
+}
+
+func getHistoryExporter(ctx context.Context) *HistoryExporter {
+	return ctx.Value(historyExporterContextKey).(*HistoryExporter)
+}
+
+func getActivityLogger(ctx context.Context) log.Logger {
+	exporter := getHistoryExporter(ctx)
+	wfInfo := activity.GetInfo(ctx)
+	return exporter.logger.WithTags(
+		tag.WorkflowID(wfInfo.WorkflowExecution.ID),
+		tag.WorkflowRunID(wfInfo.WorkflowExecution.RunID),
+		tag.WorkflowDomainName(wfInfo.WorkflowDomain),
+	)
+}
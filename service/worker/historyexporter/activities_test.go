@@ -0,0 +1,275 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package historyexporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/worker"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/blobstore"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/resource"
+	"github.com/uber/cadence/common/types"
+)
+
+type activitiesTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	controller  *gomock.Controller
+	resource    *resource.Test
+	activityEnv *testsuite.TestActivityEnvironment
+
+	dir    string
+	params Params
+}
+
+func TestActivitiesTestSuite(t *testing.T) {
+	suite.Run(t, new(activitiesTestSuite))
+}
+
+func (s *activitiesTestSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	s.resource = resource.NewTest(s.T(), s.controller, metrics.Worker)
+	exporter := New(&BootstrapParams{Resource: s.resource})
+
+	s.activityEnv = s.NewTestActivityEnvironment()
+	s.activityEnv.RegisterActivityWithOptions(ExportPageActivity, activity.RegisterOptions{Name: exportPageActivityName})
+	s.activityEnv.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), historyExporterContextKey, exporter),
+	})
+
+	s.dir = s.T().TempDir()
+	s.params = Params{
+		Domain:   "domain",
+		Query:    "WorkflowType = 'wf'",
+		URI:      "file://" + s.dir,
+		Format:   FormatJSONL,
+		PageSize: 2,
+		Checkpoint: Checkpoint{
+			NextPageToken:      []byte("token"),
+			NextFileIndex:      3,
+			ExportedExecutions: 6,
+		},
+	}
+}
+
+func (s *activitiesTestSuite) TearDownTest() {
+	s.controller.Finish()
+	s.resource.Finish(s.T())
+}
+
+func (s *activitiesTestSuite) TestValidateParams() {
+	tests := map[string]struct {
+		update  func(*Params)
+		wantErr bool
+	}{
+		"valid file URI":        {update: func(*Params) {}},
+		"valid blobstore URI":   {update: func(p *Params) { p.URI = "blobstore://export/2023" }},
+		"missing domain":        {update: func(p *Params) { p.Domain = "" }, wantErr: true},
+		"page size too large":   {update: func(p *Params) { p.PageSize = MaxPageSize + 1 }, wantErr: true},
+		"unsupported format":    {update: func(p *Params) { p.Format = "parquet" }, wantErr: true},
+		"invalid URI":           {update: func(p *Params) { p.URI = "export" }, wantErr: true},
+		"unsupported scheme":    {update: func(p *Params) { p.URI = "s3://bucket/export" }, wantErr: true},
+		"missing directory":     {update: func(p *Params) { p.URI = "file://" }, wantErr: true},
+		"missing blob key base": {update: func(p *Params) { p.URI = "blobstore:///" }, wantErr: true},
+	}
+	for name, tc := range tests {
+		s.Run(name, func() {
+			params := s.params
+			tc.update(&params)
+			err := ValidateParams(params)
+			if tc.wantErr {
+				s.Error(err)
+			} else {
+				s.NoError(err)
+			}
+		})
+	}
+}
+
+func (s *activitiesTestSuite) TestExportPage_File() {
+	executions := s.expectListWorkflowExecutions([]byte("next-token"), "wid1", "wid2")
+	s.expectGetHistory(executions[0], 2)
+	s.expectGetHistory(executions[1], 1)
+
+	checkpoint := s.executeExportPage()
+	s.Equal(Checkpoint{NextPageToken: []byte("next-token"), NextFileIndex: 4, ExportedExecutions: 8}, checkpoint)
+
+	rows := s.readRows(filepath.Join(s.dir, "part-000003.jsonl"))
+	s.Len(rows, 2)
+	s.Equal(SchemaVersion, rows[0].SchemaVersion)
+	s.Equal("domain", rows[0].DomainName)
+	s.Equal("wid1", rows[0].WorkflowID)
+	s.Equal("wid1-run", rows[0].RunID)
+	s.Equal("wf", rows[0].WorkflowType)
+	s.Equal("COMPLETED", rows[0].CloseStatus)
+	s.Equal(map[string][]byte{"memo": []byte("value")}, rows[0].Memo)
+	s.Equal(json.RawMessage(`"value"`), rows[0].SearchAttributes["CustomKeywordField"])
+	s.Len(rows[0].Events, 2)
+	s.Equal(types.EventTypeWorkflowExecutionStarted, rows[0].Events[0].GetEventType())
+	s.Equal("wid2", rows[1].WorkflowID)
+	s.Len(rows[1].Events, 1)
+}
+
+func (s *activitiesTestSuite) TestExportPage_Blobstore() {
+	s.params.URI = "blobstore://export/2023"
+	executions := s.expectListWorkflowExecutions(nil, "wid1")
+	s.expectGetHistory(executions[0], 1)
+	s.resource.BlobstoreClient.On("Put", mock.Anything, mock.MatchedBy(func(req *blobstore.PutRequest) bool {
+		return req.Key == "export.2023.part-000003.jsonl" &&
+			req.Blob.Tags["schemaVersion"] == "1" &&
+			bytes.Count(req.Blob.Body, []byte("\n")) == 1
+	})).Return(&blobstore.PutResponse{}, nil).Once()
+
+	checkpoint := s.executeExportPage()
+	s.Equal(Checkpoint{NextFileIndex: 4, ExportedExecutions: 7}, checkpoint)
+	s.resource.BlobstoreClient.AssertExpectations(s.T())
+}
+
+func (s *activitiesTestSuite) TestExportPage_DeletedWorkflow() {
+	executions := s.expectListWorkflowExecutions(nil, "wid1", "wid2")
+	s.resource.FrontendClient.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).
+		Return(nil, &types.EntityNotExistsError{}).Times(1)
+	s.expectGetHistory(executions[1], 1)
+
+	checkpoint := s.executeExportPage()
+	s.Equal(Checkpoint{NextFileIndex: 4, ExportedExecutions: 7}, checkpoint)
+	rows := s.readRows(filepath.Join(s.dir, "part-000003.jsonl"))
+	s.Len(rows, 1)
+	s.Equal("wid2", rows[0].WorkflowID)
+}
+
+func (s *activitiesTestSuite) TestExportPage_EmptyPage() {
+	s.expectListWorkflowExecutions(nil)
+
+	checkpoint := s.executeExportPage()
+	s.Equal(Checkpoint{NextFileIndex: 3, ExportedExecutions: 6}, checkpoint)
+	files, err := os.ReadDir(s.dir)
+	s.NoError(err)
+	s.Empty(files)
+}
+
+func (s *activitiesTestSuite) TestExportPage_BadQuery() {
+	s.resource.FrontendClient.EXPECT().ListWorkflowExecutions(gomock.Any(), gomock.Any()).
+		Return(nil, &types.BadRequestError{Message: "invalid query"}).Times(1)
+
+	_, err := s.activityEnv.ExecuteActivity(exportPageActivityName, s.params)
+	s.Error(err)
+	s.Contains(err.Error(), _nonRetriableReason)
+}
+
+func (s *activitiesTestSuite) TestExportPage_InvalidParams() {
+	s.params.Format = "parquet"
+
+	_, err := s.activityEnv.ExecuteActivity(exportPageActivityName, s.params)
+	s.Error(err)
+	s.Contains(err.Error(), _nonRetriableReason)
+}
+
+func (s *activitiesTestSuite) executeExportPage() Checkpoint {
+	value, err := s.activityEnv.ExecuteActivity(exportPageActivityName, s.params)
+	s.Require().NoError(err)
+	var checkpoint Checkpoint
+	s.NoError(value.Get(&checkpoint))
+	return checkpoint
+}
+
+func (s *activitiesTestSuite) expectListWorkflowExecutions(nextPageToken []byte, workflowIDs ...string) []*types.WorkflowExecution {
+	var executions []*types.WorkflowExecution
+	var infos []*types.WorkflowExecutionInfo
+	for _, workflowID := range workflowIDs {
+		execution := &types.WorkflowExecution{WorkflowID: workflowID, RunID: workflowID + "-run"}
+		executions = append(executions, execution)
+		infos = append(infos, &types.WorkflowExecutionInfo{
+			Execution:        execution,
+			Type:             &types.WorkflowType{Name: "wf"},
+			StartTime:        common.Int64Ptr(1),
+			CloseTime:        common.Int64Ptr(2),
+			CloseStatus:      types.WorkflowExecutionCloseStatusCompleted.Ptr(),
+			HistoryLength:    2,
+			Memo:             &types.Memo{Fields: map[string][]byte{"memo": []byte("value")}},
+			SearchAttributes: &types.SearchAttributes{IndexedFields: map[string][]byte{"CustomKeywordField": []byte(`"value"`)}},
+			TaskList:         "tl",
+		})
+	}
+	s.resource.FrontendClient.EXPECT().ListWorkflowExecutions(gomock.Any(), &types.ListWorkflowExecutionsRequest{
+		Domain:        s.params.Domain,
+		PageSize:      int32(s.params.PageSize),
+		NextPageToken: s.params.Checkpoint.NextPageToken,
+		Query:         s.params.Query,
+	}).Return(&types.ListWorkflowExecutionsResponse{Executions: infos, NextPageToken: nextPageToken}, nil).Times(1)
+	return executions
+}
+
+// expectGetHistory expects the history of the execution to be read in pages of a single event
+func (s *activitiesTestSuite) expectGetHistory(execution *types.WorkflowExecution, numEvents int) {
+	for i := 0; i < numEvents; i++ {
+		var pageToken, nextPageToken []byte
+		if i > 0 {
+			pageToken = []byte{byte(i)}
+		}
+		if i < numEvents-1 {
+			nextPageToken = []byte{byte(i + 1)}
+		}
+		eventType := types.EventTypeWorkflowExecutionStarted
+		if i > 0 {
+			eventType = types.EventTypeDecisionTaskScheduled
+		}
+		s.resource.FrontendClient.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), &types.GetWorkflowExecutionHistoryRequest{
+			Domain:          s.params.Domain,
+			Execution:       execution,
+			MaximumPageSize: historyPageSize,
+			NextPageToken:   pageToken,
+		}).Return(&types.GetWorkflowExecutionHistoryResponse{
+			History:       &types.History{Events: []*types.HistoryEvent{{ID: int64(i + 1), EventType: eventType.Ptr()}}},
+			NextPageToken: nextPageToken,
+		}, nil).Times(1)
+	}
+}
+
+func (s *activitiesTestSuite) readRows(path string) []Row {
+	file, err := os.Open(path)
+	s.Require().NoError(err)
+	defer file.Close()
+	var rows []Row
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var row Row
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &row))
+		rows = append(rows, row)
+	}
+	s.NoError(scanner.Err())
+	return rows
+}
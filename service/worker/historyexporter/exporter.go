@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package historyexporter
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber-go/tally"
+	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/worker"
+	"go.uber.org/cadence/workflow"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/resource"
+)
+
+type (
+	// BootstrapParams contains the set of params needed to bootstrap
+	// the history exporter sub-system
+	BootstrapParams struct {
+		// ServiceClient is an instance of cadence service client
+		ServiceClient workflowserviceclient.Interface
+		// Resource is the resource of the worker service, it provides the frontend client and the blobstore client
+		Resource resource.Resource
+		// TallyScope is an instance of tally metrics scope
+		TallyScope tally.Scope
+	}
+
+	// HistoryExporter is the background sub-system that exports workflow histories to files
+	// It is also the context object that get's passed around within the history export activities
+	HistoryExporter struct {
+		svcClient  workflowserviceclient.Interface
+		resource   resource.Resource
+		tallyScope tally.Scope
+		logger     log.Logger
+		worker     worker.Worker
+	}
+)
+
+// New returns a new instance of HistoryExporter
+func New(params *BootstrapParams) *HistoryExporter {
+	return &HistoryExporter{
+		svcClient:  params.ServiceClient,
+		resource:   params.Resource,
+		tallyScope: params.TallyScope,
+		logger:     params.Resource.GetLogger().WithTags(tag.ComponentHistoryExporter),
+	}
+}
+
+// Start starts the worker of history export workflows
+func (e *HistoryExporter) Start() error {
+	ctx := context.WithValue(context.Background(), historyExporterContextKey, e)
+	workerOpts := worker.Options{
+		MetricsScope:              e.tallyScope,
+		BackgroundActivityContext: ctx,
+		Tracer:                    opentracing.GlobalTracer(),
+	}
+	exporterWorker := worker.New(e.svcClient, common.SystemLocalDomainName, TaskListName, workerOpts)
+	exporterWorker.RegisterWorkflowWithOptions(ExportHistoryWorkflow, workflow.RegisterOptions{Name: WorkflowTypeName})
+	exporterWorker.RegisterActivityWithOptions(ExportPageActivity, activity.RegisterOptions{Name: exportPageActivityName})
+	e.worker = exporterWorker
+	return exporterWorker.Start()
+}
+
+// Stop stops the worker
+func (e *HistoryExporter) Stop() {
+	e.worker.Stop()
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package historyexporter
+
+import (
+	"encoding/json"
+
+	"github.com/uber/cadence/common/types"
+)
+
+// SchemaVersion is the version of the Row schema. It's incremented when a field is removed
+// or its meaning changes, new fields may be added to the schema without changing its version.
+const SchemaVersion = 1
+
+// Row is a single exported workflow execution: its visibility record together with its full history.
+// In the JSONL format every line of the exported files is a Row, the field names are the JSON keys below.
+type Row struct {
+	// SchemaVersion is the SchemaVersion the row is written with
+	SchemaVersion int `json:"schemaVersion"`
+	// DomainName is the domain of the workflow
+	DomainName string `json:"domainName"`
+	// WorkflowID and RunID identify the workflow execution
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId"`
+	// WorkflowType is the name of the workflow type
+	WorkflowType string `json:"workflowType"`
+	// TaskList is the decision task list of the workflow
+	TaskList string `json:"taskList,omitempty"`
+	// StartTime, ExecutionTime and CloseTime are unix nanoseconds, CloseTime is omitted for open workflows
+	StartTime     int64 `json:"startTime"`
+	ExecutionTime int64 `json:"executionTime,omitempty"`
+	CloseTime     int64 `json:"closeTime,omitempty"`
+	// CloseStatus is one of COMPLETED, FAILED, CANCELED, TERMINATED, CONTINUED_AS_NEW and TIMED_OUT,
+	// it's omitted for open workflows
+	CloseStatus string `json:"closeStatus,omitempty"`
+	// IsCron is true for cron workflows
+	IsCron bool `json:"isCron,omitempty"`
+	// HistoryLength is the number of history events recorded in visibility
+	HistoryLength int64 `json:"historyLength,omitempty"`
+	// Memo is the memo of the workflow, the values are base64 encoded since their encoding is up to the client
+	Memo map[string][]byte `json:"memo,omitempty"`
+	// SearchAttributes are the custom search attributes of the workflow, the values are JSON
+	SearchAttributes map[string]json.RawMessage `json:"searchAttributes,omitempty"`
+	// Events is the full history of the workflow in the same JSON format as `cadence workflow show --output_filename`
+	Events []*types.HistoryEvent `json:"events"`
+}
+
+func newRow(domain string, info *types.WorkflowExecutionInfo, events []*types.HistoryEvent) *Row {
+	row := &Row{
+		SchemaVersion: SchemaVersion,
+		DomainName:    domain,
+		WorkflowID:    info.GetExecution().GetWorkflowID(),
+		RunID:         info.GetExecution().GetRunID(),
+		WorkflowType:  info.GetType().GetName(),
+		TaskList:      info.TaskList,
+		StartTime:     info.GetStartTime(),
+		ExecutionTime: info.GetExecutionTime(),
+		CloseTime:     info.GetCloseTime(),
+		IsCron:        info.IsCron,
+		HistoryLength: info.HistoryLength,
+		Memo:          info.Memo.GetFields(),
+		Events:        events,
+	}
+	if info.CloseStatus != nil {
+		row.CloseStatus = info.CloseStatus.String()
+	}
+	if fields := info.GetSearchAttributes().GetIndexedFields(); len(fields) > 0 {
+		row.SearchAttributes = make(map[string]json.RawMessage, len(fields))
+		for key, value := range fields {
+			row.SearchAttributes[key] = value
+		}
+	}
+	return row
+}
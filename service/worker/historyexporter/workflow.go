@@ -0,0 +1,152 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package historyexporter
+
+import (
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+)
+
+type (
+	contextKey string
+)
+
+const (
+	historyExporterContextKey contextKey = "historyExporterContext"
+	// TaskListName is the tasklist of history export workflows
+	TaskListName = "cadence-sys-history-exporter-tasklist"
+	// WorkflowTypeName is the workflow type of history export workflows
+	WorkflowTypeName = "cadence-sys-history-exporter-workflow"
+	// WorkflowIDPrefix is the prefix of the workflow IDs of history exports
+	WorkflowIDPrefix = "cadence-history-exporter"
+	// WorkflowTimeout is the execution timeout of a single run of history export workflows,
+	// the workflow continues as new after exporting pagesPerRun pages
+	WorkflowTimeout = 7 * 24 * time.Hour
+	// QueryType for history export workflow
+	QueryType = "progress"
+
+	exportPageActivityName = "cadence-sys-history-exporter-exportPage-activity"
+
+	// DefaultPageSize is the default number of executions exported to a single file
+	DefaultPageSize = 100
+	// MaxPageSize is the max number of executions exported to a single file
+	MaxPageSize = 1000
+
+	// pagesPerRun is the number of pages exported before the workflow continues as new to keep its history small
+	pagesPerRun = 100
+
+	_nonRetriableReason = "non-retriable-error"
+)
+
+type (
+	// Params is the input of the history export workflow
+	Params struct {
+		// Domain is the domain of the exported workflows
+		Domain string
+		// Query is the visibility query of the exported workflows, all workflows of the domain are exported if it's empty
+		Query string
+		// URI is the destination of the exported files, see ValidateParams for the supported schemes
+		URI string
+		// Format is the format of the exported files, see ValidateParams for the supported formats
+		Format string
+		// PageSize is the number of executions exported to a single file
+		PageSize int
+		// Checkpoint is where the export starts from, the export starts from the beginning if it's empty
+		Checkpoint Checkpoint
+	}
+
+	// Checkpoint is the position of the export, an export started with the checkpoint of a failed export
+	// continues from where the failed one stopped
+	Checkpoint struct {
+		// NextPageToken is the visibility page token of the next page to export
+		NextPageToken []byte
+		// NextFileIndex is the index of the next file to write
+		NextFileIndex int
+		// ExportedExecutions is the number of executions exported so far
+		ExportedExecutions int
+	}
+
+	// Result is the progress of the history export, it's returned by QueryType and as the workflow result
+	Result struct {
+		Checkpoint Checkpoint
+		Completed  bool
+	}
+)
+
+var (
+	// the page activity writes a single file named after the index of the page, so a retried activity overwrites
+	// the file written by the failed attempt instead of duplicating it
+	exportActivityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    time.Hour,
+		HeartbeatTimeout:       5 * time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          10 * time.Second,
+			BackoffCoefficient:       2,
+			MaximumInterval:          5 * time.Minute,
+			ExpirationInterval:       24 * time.Hour,
+			NonRetriableErrorReasons: []string{_nonRetriableReason},
+		},
+	}
+)
+
+// WorkflowID returns the workflow ID of the history export with the given ID
+func WorkflowID(exportID string) string {
+	return WorkflowIDPrefix + ":" + exportID
+}
+
+// ExportHistoryWorkflow exports the histories and visibility records of the workflows matching a visibility query,
+// one file per page of the query result. It continues as new with its checkpoint every pagesPerRun pages.
+func ExportHistoryWorkflow(ctx workflow.Context, params Params) (*Result, error) {
+	if params.PageSize <= 0 {
+		params.PageSize = DefaultPageSize
+	}
+	logger := workflow.GetLogger(ctx).With(zap.String("domain", params.Domain))
+	result := &Result{Checkpoint: params.Checkpoint}
+	if err := workflow.SetQueryHandler(ctx, QueryType, func() (*Result, error) {
+		return result, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	activityCtx := workflow.WithActivityOptions(ctx, exportActivityOptions)
+	for page := 0; page < pagesPerRun; page++ {
+		params.Checkpoint = result.Checkpoint
+		var checkpoint Checkpoint
+		if err := workflow.ExecuteActivity(activityCtx, exportPageActivityName, params).Get(ctx, &checkpoint); err != nil {
+			logger.Error("failed to export page", zap.Int("file-index", params.Checkpoint.NextFileIndex), zap.Error(err))
+			return nil, err
+		}
+		result.Checkpoint = checkpoint
+		if len(checkpoint.NextPageToken) == 0 {
+			result.Completed = true
+			logger.Info("history export is completed",
+				zap.Int("exported-executions", checkpoint.ExportedExecutions),
+				zap.Int("files", checkpoint.NextFileIndex))
+			return result, nil
+		}
+	}
+	params.Checkpoint = result.Checkpoint
+	return nil, workflow.NewContinueAsNewError(ctx, WorkflowTypeName, params)
+}
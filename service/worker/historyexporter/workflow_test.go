@@ -0,0 +1,143 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package historyexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+)
+
+type exportHistoryWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	workflowEnv *testsuite.TestWorkflowEnvironment
+
+	params Params
+}
+
+func TestExportHistoryWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(exportHistoryWorkflowTestSuite))
+}
+
+func (s *exportHistoryWorkflowTestSuite) SetupTest() {
+	s.workflowEnv = s.NewTestWorkflowEnvironment()
+	s.workflowEnv.RegisterWorkflowWithOptions(ExportHistoryWorkflow, workflow.RegisterOptions{Name: WorkflowTypeName})
+	s.workflowEnv.RegisterActivityWithOptions(ExportPageActivity, activity.RegisterOptions{Name: exportPageActivityName})
+	s.params = Params{
+		Domain: "domain",
+		Query:  "CloseTime > 0",
+		URI:    "file:///tmp/export",
+		Format: FormatJSONL,
+	}
+}
+
+func (s *exportHistoryWorkflowTestSuite) TearDownTest() {
+	s.workflowEnv.AssertExpectations(s.T())
+}
+
+func (s *exportHistoryWorkflowTestSuite) TestExportHistory() {
+	var checkpoints []Checkpoint
+	s.workflowEnv.OnActivity(exportPageActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params Params) (*Checkpoint, error) {
+			s.Equal(DefaultPageSize, params.PageSize)
+			checkpoints = append(checkpoints, params.Checkpoint)
+			checkpoint := params.Checkpoint
+			checkpoint.NextFileIndex++
+			checkpoint.ExportedExecutions += 10
+			if checkpoint.NextFileIndex < 3 {
+				checkpoint.NextPageToken = []byte{byte(checkpoint.NextFileIndex)}
+			} else {
+				checkpoint.NextPageToken = nil
+			}
+			return &checkpoint, nil
+		}).Times(3)
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, s.params)
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+	var result Result
+	s.NoError(s.workflowEnv.GetWorkflowResult(&result))
+	s.Equal(Result{
+		Checkpoint: Checkpoint{NextFileIndex: 3, ExportedExecutions: 30},
+		Completed:  true,
+	}, result)
+	s.Equal([]Checkpoint{
+		{},
+		{NextPageToken: []byte{1}, NextFileIndex: 1, ExportedExecutions: 10},
+		{NextPageToken: []byte{2}, NextFileIndex: 2, ExportedExecutions: 20},
+	}, checkpoints)
+}
+
+func (s *exportHistoryWorkflowTestSuite) TestExportHistory_ResumeFromCheckpoint() {
+	s.params.Checkpoint = Checkpoint{NextPageToken: []byte("token"), NextFileIndex: 5, ExportedExecutions: 50}
+	s.workflowEnv.OnActivity(exportPageActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params Params) (*Checkpoint, error) {
+			s.Equal(s.params.Checkpoint, params.Checkpoint)
+			return &Checkpoint{NextFileIndex: 6, ExportedExecutions: 55}, nil
+		}).Once()
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, s.params)
+	var result Result
+	s.NoError(s.workflowEnv.GetWorkflowResult(&result))
+	s.True(result.Completed)
+	s.Equal(Checkpoint{NextFileIndex: 6, ExportedExecutions: 55}, result.Checkpoint)
+}
+
+func (s *exportHistoryWorkflowTestSuite) TestExportHistory_ContinueAsNew() {
+	s.workflowEnv.OnActivity(exportPageActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params Params) (*Checkpoint, error) {
+			checkpoint := params.Checkpoint
+			checkpoint.NextFileIndex++
+			checkpoint.NextPageToken = []byte("token")
+			return &checkpoint, nil
+		}).Times(pagesPerRun)
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, s.params)
+	err := s.workflowEnv.GetWorkflowError()
+	var continueAsNewErr *workflow.ContinueAsNewError
+	s.True(errors.As(err, &continueAsNewErr))
+	s.Equal(WorkflowTypeName, continueAsNewErr.WorkflowType().Name)
+}
+
+func (s *exportHistoryWorkflowTestSuite) TestExportHistory_Failed() {
+	s.workflowEnv.OnActivity(exportPageActivityName, mock.Anything, mock.Anything).
+		Return(&Checkpoint{NextPageToken: []byte("token"), NextFileIndex: 1, ExportedExecutions: 10}, nil).Once()
+	s.workflowEnv.OnActivity(exportPageActivityName, mock.Anything, mock.Anything).
+		Return(nil, errors.New("some random error")).Once()
+
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, s.params)
+	s.Error(s.workflowEnv.GetWorkflowError())
+
+	// the checkpoint of the failed export is where a new export resumes from
+	value, err := s.workflowEnv.QueryWorkflow(QueryType)
+	s.NoError(err)
+	var result Result
+	s.NoError(value.Get(&result))
+	s.False(result.Completed)
+	s.Equal(Checkpoint{NextPageToken: []byte("token"), NextFileIndex: 1, ExportedExecutions: 10}, result.Checkpoint)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"context"
+
+	"go.uber.org/cadence/activity"
+	"golang.org/x/time/rate"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/metrics"
+	p "github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	// ReencryptorHeartbeatDetails is the heartbeat detail for HistoryReencryptorActivity
+	ReencryptorHeartbeatDetails struct {
+		NextPageToken    []byte
+		CurrentPage      int
+		ErrorCount       int
+		SuccCount        int
+		ReencryptedCount int
+	}
+
+	// Reencryptor is the type that holds the state for history re-encryption daemon
+	Reencryptor struct {
+		db               p.HistoryManager
+		hbd              ReencryptorHeartbeatDetails
+		rps              int
+		limiter          *rate.Limiter
+		numHistoryShards int
+		metrics          metrics.Client
+		logger           log.Logger
+		isInTest         bool
+		domainCache      cache.DomainCache
+	}
+
+	reencryptResult struct {
+		reencryptedCount int
+		err              error
+	}
+)
+
+const (
+	// number of history nodes re-encrypted per persistence call
+	reencryptPageSize = 100
+)
+
+// NewReencryptor returns an instance of history re-encryption daemon
+// The Reencryptor can be started by calling the Run() method on the
+// returned object. Calling the Run() method will result in one
+// complete iteration over all of the history branches in the system. For
+// each branch, the reencryptor rewrites the history nodes which are not
+// encrypted with the active key of the domain of the branch, so that
+// retired keys can be removed from the key provider
+func NewReencryptor(
+	db p.HistoryManager,
+	rps int,
+	numHistoryShards int,
+	hbd ReencryptorHeartbeatDetails,
+	metricsClient metrics.Client,
+	logger log.Logger,
+	domainCache cache.DomainCache,
+) *Reencryptor {
+
+	rateLimiter := rate.NewLimiter(rate.Limit(rps), rps)
+
+	return &Reencryptor{
+		db:               db,
+		hbd:              hbd,
+		rps:              rps,
+		limiter:          rateLimiter,
+		numHistoryShards: numHistoryShards,
+		metrics:          metricsClient,
+		logger:           logger,
+		domainCache:      domainCache,
+	}
+}
+
+// Run runs the reencryptor
+func (r *Reencryptor) Run(ctx context.Context) (ReencryptorHeartbeatDetails, error) {
+	taskCh := make(chan taskDetail, pageSize)
+	respCh := make(chan reencryptResult, pageSize)
+	concurrency := r.rps/rpsPerConcurrency + 1
+
+	for i := 0; i < concurrency; i++ {
+		go r.startTaskProcessor(ctx, taskCh, respCh)
+	}
+
+	for {
+		resp, err := r.db.GetAllHistoryTreeBranches(ctx, &p.GetAllHistoryTreeBranchesRequest{
+			PageSize:      pageSize,
+			NextPageToken: r.hbd.NextPageToken,
+		})
+		if err != nil {
+			return r.hbd, err
+		}
+		batchCount := len(resp.Branches)
+
+		errorsOnSplitting := 0
+		// send all tasks
+		for _, br := range resp.Branches {
+			domainID, wid, rid, err := p.SplitHistoryGarbageCleanupInfo(br.Info)
+			if err != nil {
+				batchCount--
+				errorsOnSplitting++
+				r.logger.Error("reencryptor: unable to parse the history branch info", tag.WorkflowTreeID(br.TreeID), tag.WorkflowBranchID(br.BranchID), tag.DetailInfo(br.Info))
+				r.metrics.IncCounter(metrics.HistoryReencryptorScope, metrics.HistoryReencryptorErrorCount)
+				continue
+			}
+
+			taskCh <- taskDetail{
+				domainID:   domainID,
+				workflowID: wid,
+				runID:      rid,
+				treeID:     br.TreeID,
+				branchID:   br.BranchID,
+			}
+		}
+
+		succCount := 0
+		errCount := 0
+		reencryptedCount := 0
+		if batchCount > 0 {
+			// wait for counters indicate this batch is done
+		Loop:
+			for {
+				select {
+				case result := <-respCh:
+					if result.err == nil {
+						r.metrics.IncCounter(metrics.HistoryReencryptorScope, metrics.HistoryReencryptorSuccessCount)
+						succCount++
+					} else {
+						r.metrics.IncCounter(metrics.HistoryReencryptorScope, metrics.HistoryReencryptorErrorCount)
+						errCount++
+					}
+					reencryptedCount += result.reencryptedCount
+					if succCount+errCount == batchCount {
+						break Loop
+					}
+				case <-ctx.Done():
+					return r.hbd, ctx.Err()
+				}
+			}
+		}
+
+		r.hbd.CurrentPage++
+		r.hbd.NextPageToken = resp.NextPageToken
+		r.hbd.SuccCount += succCount
+		r.hbd.ErrorCount += errCount + errorsOnSplitting
+		r.hbd.ReencryptedCount += reencryptedCount
+		if !r.isInTest {
+			activity.RecordHeartbeat(ctx, r.hbd)
+		}
+
+		if len(r.hbd.NextPageToken) == 0 {
+			break
+		}
+	}
+	return r.hbd, nil
+}
+
+func (r *Reencryptor) startTaskProcessor(
+	ctx context.Context,
+	taskCh chan taskDetail,
+	respCh chan reencryptResult,
+) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-taskCh:
+			if isDone(ctx) {
+				return
+			}
+
+			count, err := r.reencryptBranch(ctx, task)
+			if err != nil {
+				r.logger.Error("encounter error when re-encrypting history branch",
+					getTaskLoggingTags(err, task)...)
+			}
+			respCh <- reencryptResult{reencryptedCount: count, err: err}
+		}
+	}
+}
+
+func (r *Reencryptor) reencryptBranch(
+	ctx context.Context,
+	task taskDetail,
+) (int, error) {
+
+	domainName, err := r.domainCache.GetDomainName(task.domainID)
+	if err != nil {
+		return 0, err
+	}
+	branchToken, err := p.NewHistoryBranchTokenByBranchID(task.treeID, task.branchID)
+	if err != nil {
+		return 0, err
+	}
+	shardID := common.WorkflowIDToHistoryShard(task.workflowID, r.numHistoryShards)
+
+	reencryptedCount := 0
+	var nextPageToken []byte
+	for {
+		if !r.isInTest {
+			activity.RecordHeartbeat(ctx, r.hbd)
+		}
+		if err := r.limiter.Wait(ctx); err != nil {
+			return reencryptedCount, err
+		}
+
+		resp, err := r.db.ReencryptHistoryBranch(ctx, &p.ReencryptHistoryBranchRequest{
+			BranchToken:   branchToken,
+			PageSize:      reencryptPageSize,
+			NextPageToken: nextPageToken,
+			ShardID:       common.IntPtr(shardID),
+			DomainName:    domainName,
+		})
+		if err != nil {
+			if _, ok := err.(*types.EntityNotExistsError); ok {
+				// the branch is deleted after it's listed
+				return reencryptedCount, nil
+			}
+			return reencryptedCount, err
+		}
+		reencryptedCount += resp.ReencryptedCount
+		r.metrics.AddCounter(metrics.HistoryReencryptorScope, metrics.HistoryReencryptorNodeCount, int64(resp.ReencryptedCount))
+
+		nextPageToken = resp.NextPageToken
+		if len(nextPageToken) == 0 {
+			return reencryptedCount, nil
+		}
+	}
+}
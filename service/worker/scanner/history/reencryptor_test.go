@@ -0,0 +1,216 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package history
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/cache"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/mocks"
+	p "github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	ReencryptorTestSuite struct {
+		suite.Suite
+		logger    log.Logger
+		metric    metrics.Client
+		mockCache *cache.MockDomainCache
+	}
+)
+
+const testNumHistoryShards = 16
+
+func TestReencryptorTestSuite(t *testing.T) {
+	suite.Run(t, new(ReencryptorTestSuite))
+}
+
+func (s *ReencryptorTestSuite) SetupTest() {
+	s.logger = testlogger.New(s.T())
+	s.metric = metrics.NewClient(tally.NoopScope, metrics.Worker)
+	controller := gomock.NewController(s.T())
+	s.mockCache = cache.NewMockDomainCache(controller)
+}
+
+func (s *ReencryptorTestSuite) createTestReencryptor(rps int) (*mocks.HistoryV2Manager, *Reencryptor) {
+	db := &mocks.HistoryV2Manager{}
+	reencryptor := NewReencryptor(db, rps, testNumHistoryShards, ReencryptorHeartbeatDetails{}, s.metric, s.logger, s.mockCache)
+	reencryptor.isInTest = true
+	return db, reencryptor
+}
+
+func (s *ReencryptorTestSuite) expectReencryptBranch(db *mocks.HistoryV2Manager, treeID, branchID, workflowID, domainName string, pages []int) {
+	branchToken, err := p.NewHistoryBranchTokenByBranchID(treeID, branchID)
+	s.Nil(err)
+	var nextPageToken []byte
+	for i, count := range pages {
+		var respToken []byte
+		if i < len(pages)-1 {
+			respToken = []byte(fmt.Sprintf("%v-page%v", branchID, i+1))
+		}
+		db.On("ReencryptHistoryBranch", mock.Anything, &p.ReencryptHistoryBranchRequest{
+			BranchToken:   branchToken,
+			PageSize:      reencryptPageSize,
+			NextPageToken: nextPageToken,
+			ShardID:       common.IntPtr(common.WorkflowIDToHistoryShard(workflowID, testNumHistoryShards)),
+			DomainName:    domainName,
+		}).Return(&p.ReencryptHistoryBranchResponse{
+			ReencryptedCount: count,
+			NextPageToken:    respToken,
+		}, nil).Once()
+		nextPageToken = respToken
+	}
+}
+
+func (s *ReencryptorTestSuite) TestReencryptTwoPages() {
+	db, reencryptor := s.createTestReencryptor(100)
+	db.On("GetAllHistoryTreeBranches", mock.Anything, &p.GetAllHistoryTreeBranchesRequest{
+		PageSize: pageSize,
+	}).Return(&p.GetAllHistoryTreeBranchesResponse{
+		NextPageToken: []byte("page1"),
+		Branches: []p.HistoryBranchDetail{
+			{
+				TreeID:   "treeID1",
+				BranchID: "branchID1",
+				ForkTime: time.Now(),
+				Info:     p.BuildHistoryGarbageCleanupInfo("domainID1", "workflowID1", "runID1"),
+			},
+			{
+				TreeID:   "treeID2",
+				BranchID: "branchID2",
+				ForkTime: time.Now(),
+				Info:     p.BuildHistoryGarbageCleanupInfo("domainID2", "workflowID2", "runID2"),
+			},
+		},
+	}, nil).Once()
+
+	db.On("GetAllHistoryTreeBranches", mock.Anything, &p.GetAllHistoryTreeBranchesRequest{
+		PageSize:      pageSize,
+		NextPageToken: []byte("page1"),
+	}).Return(&p.GetAllHistoryTreeBranchesResponse{
+		Branches: []p.HistoryBranchDetail{
+			{
+				TreeID:   "treeID3",
+				BranchID: "branchID3",
+				ForkTime: time.Now(),
+				Info:     p.BuildHistoryGarbageCleanupInfo("domainID3", "workflowID3", "runID3"),
+			},
+		},
+	}, nil).Once()
+
+	s.mockCache.EXPECT().GetDomainName("domainID1").Return("domain1", nil).AnyTimes()
+	s.mockCache.EXPECT().GetDomainName("domainID2").Return("domain2", nil).AnyTimes()
+	s.mockCache.EXPECT().GetDomainName("domainID3").Return("domain3", nil).AnyTimes()
+	s.expectReencryptBranch(db, "treeID1", "branchID1", "workflowID1", "domain1", []int{100, 20})
+	s.expectReencryptBranch(db, "treeID2", "branchID2", "workflowID2", "domain2", []int{0})
+	s.expectReencryptBranch(db, "treeID3", "branchID3", "workflowID3", "domain3", []int{5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hbd, err := reencryptor.Run(ctx)
+	s.Nil(err)
+	s.Equal(3, hbd.SuccCount)
+	s.Equal(0, hbd.ErrorCount)
+	s.Equal(125, hbd.ReencryptedCount)
+	s.Equal(2, hbd.CurrentPage)
+	s.Equal(0, len(hbd.NextPageToken))
+	db.AssertExpectations(s.T())
+}
+
+func (s *ReencryptorTestSuite) TestErrorsAndDeletedBranches() {
+	db, reencryptor := s.createTestReencryptor(100)
+	db.On("GetAllHistoryTreeBranches", mock.Anything, &p.GetAllHistoryTreeBranchesRequest{
+		PageSize: pageSize,
+	}).Return(&p.GetAllHistoryTreeBranchesResponse{
+		Branches: []p.HistoryBranchDetail{
+			{
+				TreeID:   "treeID1",
+				BranchID: "branchID1",
+				ForkTime: time.Now(),
+				Info:     "error-info",
+			},
+			{
+				TreeID:   "treeID2",
+				BranchID: "branchID2",
+				ForkTime: time.Now(),
+				Info:     p.BuildHistoryGarbageCleanupInfo("domainID2", "workflowID2", "runID2"),
+			},
+			{
+				TreeID:   "treeID3",
+				BranchID: "branchID3",
+				ForkTime: time.Now(),
+				Info:     p.BuildHistoryGarbageCleanupInfo("domainID3", "workflowID3", "runID3"),
+			},
+			{
+				TreeID:   "treeID4",
+				BranchID: "branchID4",
+				ForkTime: time.Now(),
+				Info:     p.BuildHistoryGarbageCleanupInfo("domainID4", "workflowID4", "runID4"),
+			},
+		},
+	}, nil).Once()
+
+	s.mockCache.EXPECT().GetDomainName("domainID2").Return("", &types.EntityNotExistsError{}).AnyTimes()
+	s.mockCache.EXPECT().GetDomainName("domainID3").Return("domain3", nil).AnyTimes()
+	s.mockCache.EXPECT().GetDomainName("domainID4").Return("domain4", nil).AnyTimes()
+
+	branchToken3, err := p.NewHistoryBranchTokenByBranchID("treeID3", "branchID3")
+	s.Nil(err)
+	db.On("ReencryptHistoryBranch", mock.Anything, &p.ReencryptHistoryBranchRequest{
+		BranchToken: branchToken3,
+		PageSize:    reencryptPageSize,
+		ShardID:     common.IntPtr(common.WorkflowIDToHistoryShard("workflowID3", testNumHistoryShards)),
+		DomainName:  "domain3",
+	}).Return(nil, &types.EntityNotExistsError{}).Once()
+
+	branchToken4, err := p.NewHistoryBranchTokenByBranchID("treeID4", "branchID4")
+	s.Nil(err)
+	db.On("ReencryptHistoryBranch", mock.Anything, &p.ReencryptHistoryBranchRequest{
+		BranchToken: branchToken4,
+		PageSize:    reencryptPageSize,
+		ShardID:     common.IntPtr(common.WorkflowIDToHistoryShard("workflowID4", testNumHistoryShards)),
+		DomainName:  "domain4",
+	}).Return(nil, &types.InternalServiceError{}).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hbd, err := reencryptor.Run(ctx)
+	s.Nil(err)
+	s.Equal(1, hbd.SuccCount)
+	s.Equal(3, hbd.ErrorCount)
+	s.Equal(0, hbd.ReencryptedCount)
+	s.Equal(1, hbd.CurrentPage)
+	s.Equal(0, len(hbd.NextPageToken))
+	db.AssertExpectations(s.T())
+}
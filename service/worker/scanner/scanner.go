@@ -66,6 +66,13 @@ type (
 		ClusterMetadata cluster.Metadata
 		// HistoryScannerEnabled indicates if history scanner should be started as part of scanner
 		HistoryScannerEnabled dynamicconfig.BoolPropertyFn
+		// HistoryReencryptionEnabled indicates if history re-encryption scanner should be started as part of scanner
+		HistoryReencryptionEnabled dynamicconfig.BoolPropertyFn
+		// AuditPurgerEnabled indicates if the audit purger should be started as part of scanner,
+		// it's enabled when the frontend writes the audit entries to the persistence sink
+		AuditPurgerEnabled bool
+		// AuditRetention is how long the audit entries are kept by the audit purger
+		AuditRetention time.Duration
 		// ShardScanners is a list of shard scanner configs
 		ShardScanners              []*shardscanner.ScannerConfig
 		MaxWorkflowRetentionInDays dynamicconfig.IntPropertyFn
@@ -150,6 +157,20 @@ func (s *Scanner) Start() error {
 			historyScannerWFTypeName)
 		workerTaskListNames = append(workerTaskListNames, historyScannerTaskListName)
 	}
+	if s.context.cfg.HistoryReencryptionEnabled() {
+		ctx = s.startScanner(
+			ctx,
+			historyReencryptorWFStartOptions,
+			historyReencryptorWFTypeName)
+		workerTaskListNames = append(workerTaskListNames, historyReencryptorTaskListName)
+	}
+	if s.context.cfg.AuditPurgerEnabled {
+		ctx = s.startScanner(
+			ctx,
+			auditPurgerWFStartOptions,
+			auditPurgerWFTypeName)
+		workerTaskListNames = append(workerTaskListNames, auditPurgerTaskListName)
+	}
 
 	workerOpts := worker.Options{
 		Logger:                                 s.zapLogger,
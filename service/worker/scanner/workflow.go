@@ -29,6 +29,7 @@ import (
 	cclient "go.uber.org/cadence/client"
 	"go.uber.org/cadence/workflow"
 
+	"github.com/uber/cadence/common/audit"
 	"github.com/uber/cadence/common/log/tag"
 	"github.com/uber/cadence/service/worker/scanner/executions"
 	"github.com/uber/cadence/service/worker/scanner/history"
@@ -50,6 +51,16 @@ const (
 	historyScannerWFTypeName     = "cadence-sys-history-scanner-workflow"
 	historyScannerTaskListName   = "cadence-sys-history-scanner-tasklist-0"
 	historyScavengerActivityName = "cadence-sys-history-scanner-scvg-activity"
+
+	historyReencryptorWFID         = "cadence-sys-history-reencryptor"
+	historyReencryptorWFTypeName   = "cadence-sys-history-reencryptor-workflow"
+	historyReencryptorTaskListName = "cadence-sys-history-reencryptor-tasklist-0"
+	historyReencryptorActivityName = "cadence-sys-history-reencryptor-activity"
+
+	auditPurgerWFID         = "cadence-sys-audit-purger"
+	auditPurgerWFTypeName   = "cadence-sys-audit-purger-workflow"
+	auditPurgerTaskListName = "cadence-sys-audit-purger-tasklist-0"
+	auditPurgerActivityName = "cadence-sys-audit-purger-activity"
 )
 
 var (
@@ -81,6 +92,20 @@ var (
 		WorkflowIDReusePolicy:        cclient.WorkflowIDReusePolicyAllowDuplicate,
 		CronSchedule:                 "0 */12 * * *",
 	}
+	historyReencryptorWFStartOptions = cclient.StartWorkflowOptions{
+		ID:                           historyReencryptorWFID,
+		TaskList:                     historyReencryptorTaskListName,
+		ExecutionStartToCloseTimeout: infiniteDuration,
+		WorkflowIDReusePolicy:        cclient.WorkflowIDReusePolicyAllowDuplicate,
+		CronSchedule:                 "0 0 * * *",
+	}
+	auditPurgerWFStartOptions = cclient.StartWorkflowOptions{
+		ID:                           auditPurgerWFID,
+		TaskList:                     auditPurgerTaskListName,
+		ExecutionStartToCloseTimeout: infiniteDuration,
+		WorkflowIDReusePolicy:        cclient.WorkflowIDReusePolicyAllowDuplicate,
+		CronSchedule:                 "0 * * * *",
+	}
 )
 
 func init() {
@@ -90,6 +115,12 @@ func init() {
 	workflow.RegisterWithOptions(HistoryScannerWorkflow, workflow.RegisterOptions{Name: historyScannerWFTypeName})
 	activity.RegisterWithOptions(HistoryScavengerActivity, activity.RegisterOptions{Name: historyScavengerActivityName})
 
+	workflow.RegisterWithOptions(HistoryReencryptorWorkflow, workflow.RegisterOptions{Name: historyReencryptorWFTypeName})
+	activity.RegisterWithOptions(HistoryReencryptorActivity, activity.RegisterOptions{Name: historyReencryptorActivityName})
+
+	workflow.RegisterWithOptions(AuditPurgerWorkflow, workflow.RegisterOptions{Name: auditPurgerWFTypeName})
+	activity.RegisterWithOptions(AuditPurgerActivity, activity.RegisterOptions{Name: auditPurgerActivityName})
+
 	workflow.RegisterWithOptions(executions.ConcreteScannerWorkflow, workflow.RegisterOptions{Name: executions.ConcreteExecutionsScannerWFTypeName})
 	workflow.RegisterWithOptions(executions.CurrentScannerWorkflow, workflow.RegisterOptions{Name: executions.CurrentExecutionsScannerWFTypeName})
 	workflow.RegisterWithOptions(executions.ConcreteFixerWorkflow, workflow.RegisterOptions{Name: executions.ConcreteExecutionsFixerWFTypeName})
@@ -152,6 +183,78 @@ func HistoryScavengerActivity(
 	return scavenger.Run(activityCtx)
 }
 
+// HistoryReencryptorWorkflow is the workflow that runs the history re-encryption background daemon
+func HistoryReencryptorWorkflow(
+	ctx workflow.Context,
+) error {
+
+	future := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, activityOptions),
+		historyReencryptorActivityName,
+	)
+	return future.Get(ctx, nil)
+}
+
+// HistoryReencryptorActivity is the activity that runs history reencryptor
+func HistoryReencryptorActivity(
+	activityCtx context.Context,
+) (history.ReencryptorHeartbeatDetails, error) {
+
+	ctx, err := getScannerContext(activityCtx)
+	if err != nil {
+		return history.ReencryptorHeartbeatDetails{}, err
+	}
+
+	rps := ctx.cfg.ScannerPersistenceMaxQPS()
+	res := ctx.resource
+
+	hbd := history.ReencryptorHeartbeatDetails{}
+	if activity.HasHeartbeatDetails(activityCtx) {
+		if err := activity.GetHeartbeatDetails(activityCtx, &hbd); err != nil {
+			res.GetLogger().Error("Failed to recover from last heartbeat, start over from beginning", tag.Error(err))
+		}
+	}
+	reencryptor := history.NewReencryptor(
+		res.GetHistoryManager(),
+		rps,
+		ctx.cfg.Persistence.NumHistoryShards,
+		hbd,
+		res.GetMetricsClient(),
+		res.GetLogger(),
+		res.GetDomainCache(),
+	)
+	return reencryptor.Run(activityCtx)
+}
+
+// AuditPurgerWorkflow is the workflow that deletes the expired entries of the audit persistence sink
+func AuditPurgerWorkflow(
+	ctx workflow.Context,
+) error {
+
+	future := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, activityOptions),
+		auditPurgerActivityName,
+	)
+	return future.Get(ctx, nil)
+}
+
+// AuditPurgerActivity is the activity that deletes the audit entries older than the audit retention
+func AuditPurgerActivity(
+	activityCtx context.Context,
+) error {
+	ctx, err := getScannerContext(activityCtx)
+	if err != nil {
+		return err
+	}
+	res := ctx.resource
+	expiration := res.GetTimeSource().Now().Add(-ctx.cfg.AuditRetention)
+	if err := audit.PurgeExpiredEntries(activityCtx, res.GetPersistenceBean().GetAuditQueueManager(), expiration); err != nil {
+		res.GetLogger().Error("Failed to delete expired audit entries", tag.Error(err))
+		return err
+	}
+	return nil
+}
+
 // TaskListScavengerActivity is the activity that runs task list scavenger
 func TaskListScavengerActivity(
 	activityCtx context.Context,
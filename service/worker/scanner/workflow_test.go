@@ -22,6 +22,7 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	"go.uber.org/cadence/testsuite"
 	"go.uber.org/cadence/worker"
 
+	"github.com/uber/cadence/common/clock"
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/metrics"
 	p "github.com/uber/cadence/common/persistence"
@@ -80,3 +82,38 @@ func (s *scannerWorkflowTestSuite) TestScavengerActivity() {
 	_, err := env.ExecuteActivity(taskListScavengerActivityName)
 	s.NoError(err)
 }
+
+func (s *scannerWorkflowTestSuite) TestAuditPurgerWorkflow() {
+	env := s.NewTestWorkflowEnvironment()
+	env.OnActivity(auditPurgerActivityName, mock.Anything).Return(nil)
+	env.ExecuteWorkflow(auditPurgerWFTypeName)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *scannerWorkflowTestSuite) TestAuditPurgerActivity() {
+	env := s.NewTestActivityEnvironment()
+	controller := gomock.NewController(s.T())
+	mockResource := resource.NewTest(s.T(), controller, metrics.Worker)
+	defer mockResource.Finish(s.T())
+
+	now := time.Unix(1700000000, 0)
+	mockResource.TimeSource = clock.NewMockedTimeSourceAt(now)
+	queue := mockResource.GetPersistenceBean().GetAuditQueueManager().(*p.MockQueueManager)
+	entry, err := json.Marshal(map[string]interface{}{"enqueuedAt": now.Add(-2 * time.Hour)})
+	s.NoError(err)
+	queue.EXPECT().ReadMessages(gomock.Any(), int64(-1), gomock.Any()).Return(p.QueueMessageList{
+		{ID: 1, Payload: entry},
+	}, nil)
+	queue.EXPECT().DeleteMessagesBefore(gomock.Any(), int64(2)).Return(nil)
+
+	ctx := scannerContext{
+		resource: mockResource,
+		cfg:      Config{AuditPurgerEnabled: true, AuditRetention: time.Hour},
+	}
+	env.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: NewScannerContext(context.Background(), "default-test-workflow-type-name", ctx),
+	})
+	_, err = env.ExecuteActivity(auditPurgerActivityName)
+	s.NoError(err)
+}
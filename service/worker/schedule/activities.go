@@ -0,0 +1,155 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/activity"
+
+	"github.com/uber/cadence/client/frontend"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/log/tag"
+	"github.com/uber/cadence/common/types"
+)
+
+// StartWorkflowActivity starts the workflow of a run of the schedule according to the overlap policy
+func StartWorkflowActivity(ctx context.Context, params startWorkflowActivityParams) (*startWorkflowActivityResult, error) {
+	client := getFrontendClient(ctx)
+	if params.LastRun != nil && params.OverlapPolicy != OverlapPolicyAllowAll {
+		running, err := isRunning(ctx, client, params.Domain, *params.LastRun)
+		if err != nil {
+			return nil, err
+		}
+		if running {
+			switch params.OverlapPolicy {
+			case OverlapPolicySkip:
+				return &startWorkflowActivityResult{Outcome: RunOutcomeSkipped}, nil
+			case OverlapPolicyBuffer:
+				return &startWorkflowActivityResult{Outcome: runOutcomeBuffered}, nil
+			case OverlapPolicyCancelOther:
+				err := client.RequestCancelWorkflowExecution(ctx, &types.RequestCancelWorkflowExecutionRequest{
+					Domain:            params.Domain,
+					WorkflowExecution: params.LastRun,
+					Identity:          WorkflowTypeName,
+					RequestID:         uuid.New().String(),
+					Cause:             "canceled by the next run of the schedule",
+				})
+				var notExistsErr *types.EntityNotExistsError
+				if err != nil && !errors.As(err, &notExistsErr) {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	action := params.Action
+	workflowID := runWorkflowID(action, params.NominalTime)
+	resp, err := client.StartWorkflowExecution(ctx, &types.StartWorkflowExecutionRequest{
+		Domain:                              params.Domain,
+		WorkflowID:                          workflowID,
+		WorkflowType:                        &types.WorkflowType{Name: action.WorkflowType},
+		TaskList:                            &types.TaskList{Name: action.TaskList},
+		Input:                               action.Input,
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(int32(action.ExecutionStartToCloseTimeout.Seconds())),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(int32(action.TaskStartToCloseTimeout.Seconds())),
+		Identity:                            WorkflowTypeName,
+		RequestID:                           uuid.New().String(),
+		// each time of the schedule is run at most once, including retries of this activity
+		WorkflowIDReusePolicy: types.WorkflowIDReusePolicyRejectDuplicate.Ptr(),
+	})
+	if err != nil {
+		var alreadyStartedErr *types.WorkflowExecutionAlreadyStartedError
+		if errors.As(err, &alreadyStartedErr) {
+			return &startWorkflowActivityResult{Outcome: RunOutcomeStarted, WorkflowID: workflowID, RunID: alreadyStartedErr.RunID}, nil
+		}
+		var badRequestErr *types.BadRequestError
+		var notExistsErr *types.EntityNotExistsError
+		if errors.As(err, &badRequestErr) || errors.As(err, &notExistsErr) {
+			return nil, cadence.NewCustomError(_nonRetriableReason, err.Error())
+		}
+		return nil, err
+	}
+	getActivityLogger(ctx).Info("Started workflow of schedule",
+		tag.WorkflowDomainName(params.Domain), tag.WorkflowID(workflowID), tag.WorkflowRunID(resp.GetRunID()))
+	return &startWorkflowActivityResult{Outcome: RunOutcomeStarted, WorkflowID: workflowID, RunID: resp.GetRunID()}, nil
+}
+
+// WaitWorkflowActivity waits for the workflow to complete
+func WaitWorkflowActivity(ctx context.Context, params waitWorkflowActivityParams) error {
+	client := getFrontendClient(ctx)
+	req := &types.GetWorkflowExecutionHistoryRequest{
+		Domain:                 params.Domain,
+		Execution:              &params.Execution,
+		WaitForNewEvent:        true,
+		HistoryEventFilterType: types.HistoryEventFilterTypeCloseEvent.Ptr(),
+	}
+	for {
+		resp, err := client.GetWorkflowExecutionHistory(ctx, req)
+		if err != nil {
+			var notExistsErr *types.EntityNotExistsError
+			if errors.As(err, &notExistsErr) {
+				return nil
+			}
+			return err
+		}
+		if len(resp.GetHistory().GetEvents()) > 0 {
+			return nil
+		}
+		activity.RecordHeartbeat(ctx)
+		req.NextPageToken = resp.NextPageToken
+	}
+}
+
+func isRunning(ctx context.Context, client frontend.Client, domain string, execution types.WorkflowExecution) (bool, error) {
+	resp, err := client.DescribeWorkflowExecution(ctx, &types.DescribeWorkflowExecutionRequest{
+		Domain:    domain,
+		Execution: &execution,
+	})
+	if err != nil {
+		var notExistsErr *types.EntityNotExistsError
+		if errors.As(err, &notExistsErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	info := resp.GetWorkflowExecutionInfo()
+	return info != nil && info.CloseStatus == nil, nil
+}
+
+func getFrontendClient(ctx context.Context) frontend.Client {
+	scheduler := ctx.Value(schedulerContextKey).(*Scheduler)
+	return scheduler.clientBean.GetFrontendClient()
+}
+
+func getActivityLogger(ctx context.Context) log.Logger {
+	scheduler := ctx.Value(schedulerContextKey).(*Scheduler)
+	wfInfo := activity.GetInfo(ctx)
+	return scheduler.logger.WithTags(
+		tag.WorkflowID(wfInfo.WorkflowExecution.ID),
+		tag.WorkflowRunID(wfInfo.WorkflowExecution.RunID),
+		tag.WorkflowDomainName(wfInfo.WorkflowDomain),
+	)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/worker"
+
+	"github.com/uber/cadence/client"
+	"github.com/uber/cadence/client/frontend"
+	"github.com/uber/cadence/common/log/testlogger"
+	"github.com/uber/cadence/common/types"
+)
+
+type activitiesTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	controller  *gomock.Controller
+	frontend    *frontend.MockClient
+	activityEnv *testsuite.TestActivityEnvironment
+
+	lastRun *types.WorkflowExecution
+	params  startWorkflowActivityParams
+}
+
+func TestActivitiesTestSuite(t *testing.T) {
+	suite.Run(t, new(activitiesTestSuite))
+}
+
+func (s *activitiesTestSuite) SetupTest() {
+	s.controller = gomock.NewController(s.T())
+	s.frontend = frontend.NewMockClient(s.controller)
+	clientBean := client.NewMockBean(s.controller)
+	clientBean.EXPECT().GetFrontendClient().Return(s.frontend).AnyTimes()
+	scheduler := &Scheduler{
+		clientBean: clientBean,
+		logger:     testlogger.New(s.T()),
+	}
+
+	s.activityEnv = s.NewTestActivityEnvironment()
+	s.activityEnv.RegisterActivityWithOptions(StartWorkflowActivity, activity.RegisterOptions{Name: startWorkflowActivityName})
+	s.activityEnv.RegisterActivityWithOptions(WaitWorkflowActivity, activity.RegisterOptions{Name: waitWorkflowActivityName})
+	s.activityEnv.SetWorkerOptions(worker.Options{
+		BackgroundActivityContext: context.WithValue(context.Background(), schedulerContextKey, scheduler),
+	})
+
+	s.lastRun = &types.WorkflowExecution{WorkflowID: "wid-2024-01-01T00:00:00Z", RunID: "last-rid"}
+	s.params = startWorkflowActivityParams{
+		Domain: "domain",
+		Action: Action{
+			WorkflowID:                   "wid",
+			WorkflowType:                 "wt",
+			TaskList:                     "tl",
+			ExecutionStartToCloseTimeout: time.Hour,
+			TaskStartToCloseTimeout:      10 * time.Second,
+		},
+		NominalTime: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+		LastRun:     s.lastRun,
+	}
+}
+
+func (s *activitiesTestSuite) TearDownTest() {
+	s.controller.Finish()
+}
+
+func (s *activitiesTestSuite) TestStartWorkflow() {
+	s.params.OverlapPolicy = OverlapPolicySkip
+	s.expectDescribe(nil)
+	s.frontend.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			s.Equal("domain", req.Domain)
+			s.Equal("wid-2024-01-01T01:00:00Z", req.WorkflowID)
+			s.Equal("wt", req.WorkflowType.GetName())
+			s.Equal(int32(3600), req.GetExecutionStartToCloseTimeoutSeconds())
+			s.Equal(types.WorkflowIDReusePolicyRejectDuplicate, req.GetWorkflowIDReusePolicy())
+			return &types.StartWorkflowExecutionResponse{RunID: "rid"}, nil
+		})
+
+	result := s.executeStart()
+	s.Equal(startWorkflowActivityResult{Outcome: RunOutcomeStarted, WorkflowID: "wid-2024-01-01T01:00:00Z", RunID: "rid"}, result)
+}
+
+func (s *activitiesTestSuite) TestStartWorkflow_AlreadyStarted() {
+	// the activity is retried after the workflow is started
+	s.params.OverlapPolicy = OverlapPolicyAllowAll
+	s.frontend.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).
+		Return(nil, &types.WorkflowExecutionAlreadyStartedError{RunID: "rid"})
+
+	result := s.executeStart()
+	s.Equal(startWorkflowActivityResult{Outcome: RunOutcomeStarted, WorkflowID: "wid-2024-01-01T01:00:00Z", RunID: "rid"}, result)
+}
+
+func (s *activitiesTestSuite) TestStartWorkflow_BadRequest() {
+	s.params.OverlapPolicy = OverlapPolicyAllowAll
+	s.frontend.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).
+		Return(nil, &types.BadRequestError{Message: "bad request"})
+
+	_, err := s.activityEnv.ExecuteActivity(startWorkflowActivityName, s.params)
+	s.Error(err)
+	s.Contains(err.Error(), _nonRetriableReason)
+}
+
+func (s *activitiesTestSuite) TestStartWorkflow_Skip() {
+	s.params.OverlapPolicy = OverlapPolicySkip
+	s.expectRunning()
+
+	s.Equal(startWorkflowActivityResult{Outcome: RunOutcomeSkipped}, s.executeStart())
+}
+
+func (s *activitiesTestSuite) TestStartWorkflow_Buffer() {
+	s.params.OverlapPolicy = OverlapPolicyBuffer
+	s.expectRunning()
+
+	s.Equal(startWorkflowActivityResult{Outcome: runOutcomeBuffered}, s.executeStart())
+}
+
+func (s *activitiesTestSuite) TestStartWorkflow_CancelOther() {
+	s.params.OverlapPolicy = OverlapPolicyCancelOther
+	s.expectRunning()
+	cancel := s.frontend.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.RequestCancelWorkflowExecutionRequest, _ ...interface{}) error {
+			s.Equal(s.lastRun, req.WorkflowExecution)
+			return nil
+		})
+	s.frontend.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).
+		Return(&types.StartWorkflowExecutionResponse{RunID: "rid"}, nil).After(cancel)
+
+	s.Equal(RunOutcomeStarted, s.executeStart().Outcome)
+}
+
+func (s *activitiesTestSuite) TestWaitWorkflow() {
+	closeEvent := &types.HistoryEvent{ID: 10, EventType: types.EventTypeWorkflowExecutionCompleted.Ptr()}
+	gomock.InOrder(
+		s.frontend.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *types.GetWorkflowExecutionHistoryRequest, _ ...interface{}) (*types.GetWorkflowExecutionHistoryResponse, error) {
+				s.True(req.WaitForNewEvent)
+				s.Equal(types.HistoryEventFilterTypeCloseEvent, req.GetHistoryEventFilterType())
+				s.Nil(req.NextPageToken)
+				// long poll timed out
+				return &types.GetWorkflowExecutionHistoryResponse{History: &types.History{}, NextPageToken: []byte("token")}, nil
+			}),
+		s.frontend.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, req *types.GetWorkflowExecutionHistoryRequest, _ ...interface{}) (*types.GetWorkflowExecutionHistoryResponse, error) {
+				s.Equal([]byte("token"), req.NextPageToken)
+				return &types.GetWorkflowExecutionHistoryResponse{History: &types.History{Events: []*types.HistoryEvent{closeEvent}}}, nil
+			}),
+	)
+
+	_, err := s.activityEnv.ExecuteActivity(waitWorkflowActivityName, waitWorkflowActivityParams{Domain: "domain", Execution: *s.lastRun})
+	s.NoError(err)
+}
+
+func (s *activitiesTestSuite) TestWaitWorkflow_NotExists() {
+	s.frontend.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).Return(nil, &types.EntityNotExistsError{})
+
+	_, err := s.activityEnv.ExecuteActivity(waitWorkflowActivityName, waitWorkflowActivityParams{Domain: "domain", Execution: *s.lastRun})
+	s.NoError(err)
+}
+
+func (s *activitiesTestSuite) expectRunning() {
+	s.expectDescribe(&types.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &types.WorkflowExecutionInfo{Execution: s.lastRun},
+	})
+}
+
+func (s *activitiesTestSuite) expectDescribe(resp *types.DescribeWorkflowExecutionResponse) *gomock.Call {
+	if resp == nil {
+		closeStatus := types.WorkflowExecutionCloseStatusCompleted
+		resp = &types.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &types.WorkflowExecutionInfo{Execution: s.lastRun, CloseStatus: &closeStatus},
+		}
+	}
+	return s.frontend.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.DescribeWorkflowExecutionRequest, _ ...interface{}) (*types.DescribeWorkflowExecutionResponse, error) {
+			s.Equal(s.lastRun, req.Execution)
+			return resp, nil
+		})
+}
+
+func (s *activitiesTestSuite) executeStart() startWorkflowActivityResult {
+	value, err := s.activityEnv.ExecuteActivity(startWorkflowActivityName, s.params)
+	s.NoError(err)
+	var result startWorkflowActivityResult
+	s.NoError(value.Get(&result))
+	return result
+}
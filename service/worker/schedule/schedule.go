@@ -0,0 +1,187 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+
+	"github.com/uber/cadence/common/backoff"
+)
+
+const (
+	// OverlapPolicySkip skips a run if the last run started by the schedule is still running
+	OverlapPolicySkip = "skip"
+	// OverlapPolicyBuffer starts a run after the last run started by the schedule completes
+	OverlapPolicyBuffer = "buffer"
+	// OverlapPolicyCancelOther cancels the last run started by the schedule if it's still running and starts a new one
+	OverlapPolicyCancelOther = "cancel-other"
+	// OverlapPolicyAllowAll always starts a new run
+	OverlapPolicyAllowAll = "allow-all"
+
+	// DefaultCatchupWindow is the default value of Policies.CatchupWindow
+	DefaultCatchupWindow = time.Minute
+)
+
+// AllOverlapPolicies is the overlap policies we supported
+var AllOverlapPolicies = []string{OverlapPolicySkip, OverlapPolicyBuffer, OverlapPolicyCancelOther, OverlapPolicyAllowAll}
+
+type (
+	// Schedule defines when and what a schedule runs
+	Schedule struct {
+		Spec     Spec
+		Action   Action
+		Policies Policies
+	}
+
+	// Spec defines the times of the runs of a schedule
+	Spec struct {
+		// CronSchedule in the same format as the CronSchedule of workflows. Times are in UTC.
+		CronSchedule string
+		// StartTime and EndTime bound the times of the runs if set
+		StartTime time.Time
+		EndTime   time.Time
+	}
+
+	// Action is the workflow started by each run of a schedule
+	Action struct {
+		// WorkflowID of a run is the WorkflowID of the action followed by the time of the run,
+		// so each time of the schedule is run at most once.
+		WorkflowID                   string
+		WorkflowType                 string
+		TaskList                     string
+		Input                        []byte
+		ExecutionStartToCloseTimeout time.Duration
+		TaskStartToCloseTimeout      time.Duration
+	}
+
+	// Policies defines how a schedule handles overlapping and late runs
+	Policies struct {
+		// OverlapPolicy is one of AllOverlapPolicies. Default to OverlapPolicySkip.
+		OverlapPolicy string
+		// CatchupWindow is how late a run can be started, e.g. after the schedule was not able to run for a while.
+		// Runs later than that are skipped. Default to DefaultCatchupWindow.
+		CatchupWindow time.Duration
+	}
+)
+
+// Validate validates the schedule and sets the default values
+func (s *Schedule) Validate() error {
+	if _, err := s.Spec.parse(); err != nil {
+		return err
+	}
+	if !s.Spec.EndTime.IsZero() && s.Spec.EndTime.Before(s.Spec.StartTime) {
+		return errors.New("end time is before start time")
+	}
+	if s.Action.WorkflowID == "" || s.Action.WorkflowType == "" || s.Action.TaskList == "" {
+		return errors.New("must provide workflow ID, workflow type and task list of the action")
+	}
+	if s.Action.ExecutionStartToCloseTimeout <= 0 {
+		return errors.New("must provide a positive execution timeout of the action")
+	}
+	if s.Action.TaskStartToCloseTimeout <= 0 {
+		s.Action.TaskStartToCloseTimeout = defaultDecisionTimeout
+	}
+	if s.Policies.OverlapPolicy == "" {
+		s.Policies.OverlapPolicy = OverlapPolicySkip
+	}
+	if err := validateOverlapPolicy(s.Policies.OverlapPolicy); err != nil {
+		return err
+	}
+	if s.Policies.CatchupWindow <= 0 {
+		s.Policies.CatchupWindow = DefaultCatchupWindow
+	}
+	return nil
+}
+
+// Next returns the first time of the schedule after t. It returns zero time if there is none.
+func (s *Spec) Next(t time.Time) time.Time {
+	sched, err := s.parse()
+	if err != nil {
+		return time.Time{}
+	}
+	return s.next(sched, t)
+}
+
+// Upcoming returns at most n times of the schedule after t
+func (s *Spec) Upcoming(t time.Time, n int) []time.Time {
+	sched, err := s.parse()
+	if err != nil {
+		return nil
+	}
+	var times []time.Time
+	for next := s.next(sched, t); !next.IsZero() && len(times) < n; next = s.next(sched, next) {
+		times = append(times, next)
+	}
+	return times
+}
+
+func (s *Spec) parse() (cron.Schedule, error) {
+	if s.CronSchedule == "" {
+		return nil, errors.New("must provide cron schedule")
+	}
+	return backoff.ValidateSchedule(s.CronSchedule)
+}
+
+func (s *Spec) next(sched cron.Schedule, t time.Time) time.Time {
+	t = t.UTC()
+	if t.Before(s.StartTime) {
+		// cron schedules return times strictly after the given time
+		t = s.StartTime.UTC().Add(-time.Second)
+	}
+	next := sched.Next(t)
+	if !s.EndTime.IsZero() && next.After(s.EndTime) {
+		return time.Time{}
+	}
+	return next
+}
+
+// WorkflowID returns the ID of the workflow of a schedule
+func WorkflowID(domain, name string) string {
+	return fmt.Sprintf("%s:%s:%s", WorkflowIDPrefix, domain, name)
+}
+
+// ParseWorkflowID returns the domain and name of a schedule from the ID of its workflow
+func ParseWorkflowID(workflowID string) (domain string, name string, ok bool) {
+	parts := strings.SplitN(workflowID, ":", 3)
+	if len(parts) != 3 || parts[0] != WorkflowIDPrefix {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// runWorkflowID returns the ID of the workflow started by the run of the time
+func runWorkflowID(action Action, nominalTime time.Time) string {
+	return fmt.Sprintf("%s-%s", action.WorkflowID, nominalTime.UTC().Format(time.RFC3339))
+}
+
+func validateOverlapPolicy(policy string) error {
+	for _, p := range AllOverlapPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("not supported overlap policy: %v", policy)
+}
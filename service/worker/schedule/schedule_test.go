@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleValidate(t *testing.T) {
+	valid := func() Schedule {
+		return Schedule{
+			Spec: Spec{CronSchedule: "0 * * * *"},
+			Action: Action{
+				WorkflowID:                   "wid",
+				WorkflowType:                 "wt",
+				TaskList:                     "tl",
+				ExecutionStartToCloseTimeout: time.Hour,
+			},
+		}
+	}
+
+	s := valid()
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, OverlapPolicySkip, s.Policies.OverlapPolicy)
+	assert.Equal(t, DefaultCatchupWindow, s.Policies.CatchupWindow)
+	assert.Equal(t, defaultDecisionTimeout, s.Action.TaskStartToCloseTimeout)
+
+	tests := map[string]func(*Schedule){
+		"missing cron schedule":  func(s *Schedule) { s.Spec.CronSchedule = "" },
+		"invalid cron schedule":  func(s *Schedule) { s.Spec.CronSchedule = "* *" },
+		"end before start":       func(s *Schedule) { s.Spec.StartTime = time.Unix(100, 0); s.Spec.EndTime = time.Unix(10, 0) },
+		"missing workflow type":  func(s *Schedule) { s.Action.WorkflowType = "" },
+		"missing timeout":        func(s *Schedule) { s.Action.ExecutionStartToCloseTimeout = 0 },
+		"unknown overlap policy": func(s *Schedule) { s.Policies.OverlapPolicy = "unknown" },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := valid()
+			modify(&s)
+			assert.Error(t, s.Validate())
+		})
+	}
+}
+
+func TestSpecUpcoming(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+	spec := Spec{CronSchedule: "0 * * * *"}
+	assert.Equal(t, base.Add(30*time.Minute), spec.Next(base))
+	assert.Equal(t, []time.Time{
+		base.Add(30 * time.Minute),
+		base.Add(90 * time.Minute),
+	}, spec.Upcoming(base, 2))
+
+	// the times are bounded by the start and end time, both inclusive
+	spec.StartTime = base.Add(150 * time.Minute)
+	spec.EndTime = base.Add(270 * time.Minute)
+	assert.Equal(t, []time.Time{
+		base.Add(150 * time.Minute),
+		base.Add(210 * time.Minute),
+		base.Add(270 * time.Minute),
+	}, spec.Upcoming(base, 10))
+	assert.True(t, spec.Next(spec.EndTime).IsZero())
+}
+
+func TestWorkflowID(t *testing.T) {
+	domain, name, ok := ParseWorkflowID(WorkflowID("domain", "name:with:colons"))
+	assert.True(t, ok)
+	assert.Equal(t, "domain", domain)
+	assert.Equal(t, "name:with:colons", name)
+
+	_, _, ok = ParseWorkflowID("cadence-sys-other:domain:name")
+	assert.False(t, ok)
+
+	nominalTime := time.Date(2024, 1, 1, 1, 0, 0, 0, time.FixedZone("UTC+1", 3600))
+	assert.Equal(t, "wid-2024-01-01T00:00:00Z", runWorkflowID(Action{WorkflowID: "wid"}, nominalTime))
+}
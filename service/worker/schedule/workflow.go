@@ -0,0 +1,510 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/cadence/workflow"
+	"go.uber.org/zap"
+
+	"github.com/uber/cadence/common/types"
+)
+
+type (
+	contextKey string
+)
+
+const (
+	schedulerContextKey contextKey = "schedulerContext"
+	// TaskListName is the tasklist of schedule workflows
+	TaskListName = "cadence-sys-schedule-tasklist"
+	// WorkflowTypeName is the workflow type of schedule workflows
+	WorkflowTypeName = "cadence-sys-schedule-workflow"
+	// WorkflowIDPrefix is the prefix of the workflow IDs of schedules
+	WorkflowIDPrefix          = "cadence-schedule"
+	startWorkflowActivityName = "cadence-sys-schedule-startWorkflow-activity"
+	waitWorkflowActivityName  = "cadence-sys-schedule-waitWorkflow-activity"
+	// InfiniteDuration is a long duration(20 yrs) we used for infinite workflow running
+	InfiniteDuration = 20 * 365 * 24 * time.Hour
+
+	// QueryType for schedule workflow
+	QueryType = "describe"
+	// UpdateSignal signal name for replacing the schedule
+	UpdateSignal = "update"
+	// PauseSignal signal name for pause
+	PauseSignal = "pause"
+	// UnpauseSignal signal name for unpause
+	UnpauseSignal = "unpause"
+	// BackfillSignal signal name for backfill
+	BackfillSignal = "backfill"
+	// DeleteSignal signal name for delete
+	DeleteSignal = "delete"
+
+	// RunOutcomeStarted means the workflow of the run is started
+	RunOutcomeStarted = "started"
+	// RunOutcomeSkipped means the run is skipped because of the overlap policy
+	RunOutcomeSkipped = "skipped"
+	// RunOutcomeFailed means the workflow of the run failed to start
+	RunOutcomeFailed = "failed"
+
+	defaultDecisionTimeout = 10 * time.Second
+	maxRecentRuns          = 10
+	maxBufferedRuns        = 1000
+	maxUpcomingRuns        = 10
+	// the workflow continues as new after this many runs to keep its history small
+	maxRunsPerExecution = 500
+	// backfills are processed in chunks so that signals are handled in between
+	maxBackfillRunsPerIteration = 100
+
+	_nonRetriableReason = "non-retriable-error"
+)
+
+type (
+	// Params is the input of the schedule workflow
+	Params struct {
+		Domain   string
+		Name     string
+		Schedule Schedule
+		// State is carried over when the workflow continues as new
+		State State
+	}
+
+	// State is the runtime state of a schedule
+	State struct {
+		Paused bool
+		// Note is the reason of the last pause or unpause
+		Note string
+		// LastProcessedTime is the time up to which the runs of the schedule are processed
+		LastProcessedTime time.Time
+		// LastRun is the last workflow started by the schedule
+		LastRun *types.WorkflowExecution
+		// BufferedRuns are the times of runs waiting for the last run to complete
+		BufferedRuns []time.Time
+		// Backfills are the backfill requests being processed
+		Backfills []Backfill
+		// RecentRuns are the most recent runs of the schedule
+		RecentRuns  []RunResult
+		TotalRuns   int
+		SkippedRuns int
+		FailedRuns  int
+	}
+
+	// RunResult is the result of a run of the schedule
+	RunResult struct {
+		NominalTime time.Time
+		ActualTime  time.Time
+		Outcome     string
+		WorkflowID  string
+		RunID       string
+	}
+
+	// BackfillRequest is the payload of BackfillSignal, runs with times in [StartTime, EndTime] are started
+	BackfillRequest struct {
+		StartTime time.Time
+		EndTime   time.Time
+		// OverlapPolicy overrides the overlap policy of the schedule if set
+		OverlapPolicy string
+	}
+
+	// Backfill is a backfill request being processed
+	Backfill struct {
+		// LastProcessedTime is the time up to which the backfill is processed
+		LastProcessedTime time.Time
+		EndTime           time.Time
+		OverlapPolicy     string
+	}
+
+	// Description is the result of QueryType
+	Description struct {
+		Domain       string
+		Name         string
+		Schedule     Schedule
+		State        State
+		UpcomingRuns []time.Time
+	}
+
+	startWorkflowActivityParams struct {
+		Domain        string
+		Action        Action
+		NominalTime   time.Time
+		OverlapPolicy string
+		LastRun       *types.WorkflowExecution
+	}
+
+	startWorkflowActivityResult struct {
+		// Outcome is RunOutcomeStarted, RunOutcomeSkipped or runOutcomeBuffered
+		Outcome    string
+		WorkflowID string
+		RunID      string
+	}
+
+	waitWorkflowActivityParams struct {
+		Domain    string
+		Execution types.WorkflowExecution
+	}
+
+	// scheduleRunner runs the schedule within a run of the schedule workflow
+	scheduleRunner struct {
+		domain   string
+		name     string
+		schedule Schedule
+		state    State
+		logger   *zap.Logger
+
+		deleted bool
+		// runs is the number of runs processed by this execution of the workflow
+		runs int
+		// waitFuture is ready when the last run completes. It's only set when there are buffered runs.
+		waitFuture workflow.Future
+		waitCancel workflow.CancelFunc
+	}
+)
+
+// runOutcomeBuffered is returned by the start workflow activity if the run is buffered by the overlap policy
+const runOutcomeBuffered = "buffered"
+
+var (
+	startWorkflowActivityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:          time.Second,
+			BackoffCoefficient:       2,
+			MaximumInterval:          time.Minute,
+			ExpirationInterval:       10 * time.Minute,
+			NonRetriableErrorReasons: []string{_nonRetriableReason},
+		},
+	}
+
+	waitWorkflowActivityOptions = workflow.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute,
+		StartToCloseTimeout:    InfiniteDuration,
+		HeartbeatTimeout:       5 * time.Minute,
+		RetryPolicy: &cadence.RetryPolicy{
+			InitialInterval:    10 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    5 * time.Minute,
+			ExpirationInterval: InfiniteDuration,
+		},
+	}
+)
+
+// ScheduleWorkflow is the workflow that runs a schedule until it's deleted
+func ScheduleWorkflow(ctx workflow.Context, params Params) error {
+	if err := params.Schedule.Validate(); err != nil {
+		return cadence.NewCustomError(_nonRetriableReason, err.Error())
+	}
+	r := &scheduleRunner{
+		domain:   params.Domain,
+		name:     params.Name,
+		schedule: params.Schedule,
+		state:    params.State,
+		logger:   workflow.GetLogger(ctx),
+	}
+	return r.run(ctx)
+}
+
+func (r *scheduleRunner) run(ctx workflow.Context) error {
+	err := workflow.SetQueryHandler(ctx, QueryType, func() (*Description, error) {
+		return &Description{
+			Domain:       r.domain,
+			Name:         r.name,
+			Schedule:     r.schedule,
+			State:        r.state,
+			UpcomingRuns: r.schedule.Spec.Upcoming(r.state.LastProcessedTime, maxUpcomingRuns),
+		}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.state.LastProcessedTime.IsZero() {
+		r.state.LastProcessedTime = workflow.Now(ctx)
+	}
+	r.ensureWait(ctx)
+
+	for !r.deleted && r.runs < maxRunsPerExecution {
+		r.processBufferedRuns(ctx)
+		r.processScheduledRuns(ctx)
+		r.processBackfills(ctx)
+		if r.runs >= maxRunsPerExecution {
+			break
+		}
+
+		selector := workflow.NewSelector(ctx)
+		timerCtx, cancelTimer := workflow.WithCancel(ctx)
+		if len(r.state.Backfills) > 0 && !r.state.Paused {
+			// continue with the backfills after handling the pending signals
+			selector.AddDefault(func() {})
+		} else if !r.state.Paused {
+			if next := r.schedule.Spec.Next(r.state.LastProcessedTime); !next.IsZero() {
+				selector.AddFuture(workflow.NewTimer(timerCtx, maxDuration(next.Sub(workflow.Now(ctx)), time.Second)), func(workflow.Future) {})
+			}
+		}
+		if r.waitFuture != nil {
+			selector.AddFuture(r.waitFuture, func(f workflow.Future) {
+				if err := f.Get(ctx, nil); err != nil {
+					r.logger.Warn("Failed to wait for the last run of schedule", zap.Error(err))
+				}
+				r.waitFuture = nil
+			})
+		}
+		r.addSignalHandlers(ctx, selector)
+		selector.Select(ctx)
+		cancelTimer()
+	}
+
+	// signals received after the last decision are lost if the workflow continues as new without handling them
+	r.drainSignals(ctx)
+	if r.waitCancel != nil {
+		r.waitCancel()
+	}
+	if r.deleted {
+		r.logger.Info("Schedule is deleted", zap.String("domain", r.domain), zap.String("name", r.name))
+		return nil
+	}
+	return workflow.NewContinueAsNewError(ctx, WorkflowTypeName, Params{
+		Domain:   r.domain,
+		Name:     r.name,
+		Schedule: r.schedule,
+		State:    r.state,
+	})
+}
+
+// processScheduledRuns starts the runs with times up to now
+func (r *scheduleRunner) processScheduledRuns(ctx workflow.Context) {
+	if r.state.Paused {
+		return
+	}
+	now := workflow.Now(ctx)
+	for r.runs < maxRunsPerExecution {
+		next := r.schedule.Spec.Next(r.state.LastProcessedTime)
+		if next.IsZero() || next.After(now) {
+			r.state.LastProcessedTime = now
+			return
+		}
+		if now.Sub(next) > r.schedule.Policies.CatchupWindow {
+			r.logger.Warn("Skipped runs of schedule outside of catch-up window",
+				zap.Time("from", next), zap.Time("to", now.Add(-r.schedule.Policies.CatchupWindow)))
+			r.state.LastProcessedTime = now.Add(-r.schedule.Policies.CatchupWindow)
+			continue
+		}
+		r.state.LastProcessedTime = next
+		r.startOrBufferRun(ctx, next, r.schedule.Policies.OverlapPolicy)
+	}
+}
+
+// processBackfills starts the runs of the backfill requests in the order of the requests
+func (r *scheduleRunner) processBackfills(ctx workflow.Context) {
+	if r.state.Paused {
+		return
+	}
+	for processed := 0; len(r.state.Backfills) > 0 && processed < maxBackfillRunsPerIteration; processed++ {
+		if r.runs >= maxRunsPerExecution {
+			return
+		}
+		backfill := &r.state.Backfills[0]
+		next := r.schedule.Spec.Next(backfill.LastProcessedTime)
+		if next.IsZero() || next.After(backfill.EndTime) {
+			r.state.Backfills = r.state.Backfills[1:]
+			continue
+		}
+		backfill.LastProcessedTime = next
+		r.startOrBufferRun(ctx, next, backfill.OverlapPolicy)
+	}
+}
+
+// processBufferedRuns starts the first buffered run once the last run completes
+func (r *scheduleRunner) processBufferedRuns(ctx workflow.Context) {
+	if r.state.Paused || len(r.state.BufferedRuns) == 0 || r.waitFuture != nil || r.runs >= maxRunsPerExecution {
+		return
+	}
+	nominalTime := r.state.BufferedRuns[0]
+	r.state.BufferedRuns = r.state.BufferedRuns[1:]
+	if r.startRun(ctx, nominalTime, OverlapPolicyBuffer) {
+		// the last run is still running
+		r.state.BufferedRuns = append([]time.Time{nominalTime}, r.state.BufferedRuns...)
+	}
+	r.ensureWait(ctx)
+}
+
+func (r *scheduleRunner) startOrBufferRun(ctx workflow.Context, nominalTime time.Time, overlapPolicy string) {
+	// keep the order of buffered runs
+	if overlapPolicy != OverlapPolicyBuffer || len(r.state.BufferedRuns) == 0 {
+		if !r.startRun(ctx, nominalTime, overlapPolicy) {
+			return
+		}
+	}
+	if len(r.state.BufferedRuns) >= maxBufferedRuns {
+		r.recordRun(RunResult{NominalTime: nominalTime, ActualTime: workflow.Now(ctx), Outcome: RunOutcomeSkipped})
+		return
+	}
+	r.state.BufferedRuns = append(r.state.BufferedRuns, nominalTime)
+	r.ensureWait(ctx)
+}
+
+// startRun runs the action for the time with the overlap policy and returns whether the run needs to be buffered
+func (r *scheduleRunner) startRun(ctx workflow.Context, nominalTime time.Time, overlapPolicy string) bool {
+	r.runs++
+	params := &startWorkflowActivityParams{
+		Domain:        r.domain,
+		Action:        r.schedule.Action,
+		NominalTime:   nominalTime,
+		OverlapPolicy: overlapPolicy,
+		LastRun:       r.state.LastRun,
+	}
+	var result startWorkflowActivityResult
+	err := workflow.ExecuteActivity(workflow.WithActivityOptions(ctx, startWorkflowActivityOptions), startWorkflowActivityName, params).Get(ctx, &result)
+	run := RunResult{
+		NominalTime: nominalTime,
+		ActualTime:  workflow.Now(ctx),
+		Outcome:     result.Outcome,
+		WorkflowID:  result.WorkflowID,
+		RunID:       result.RunID,
+	}
+	if err != nil {
+		r.logger.Error("Failed to start workflow of schedule", zap.Time("nominal-time", nominalTime), zap.Error(err))
+		run.Outcome = RunOutcomeFailed
+	}
+	switch run.Outcome {
+	case runOutcomeBuffered:
+		return true
+	case RunOutcomeStarted:
+		r.state.LastRun = &types.WorkflowExecution{WorkflowID: result.WorkflowID, RunID: result.RunID}
+	}
+	r.recordRun(run)
+	return false
+}
+
+// ensureWait waits for the last run to complete if there are buffered runs
+func (r *scheduleRunner) ensureWait(ctx workflow.Context) {
+	if len(r.state.BufferedRuns) == 0 || r.state.LastRun == nil || r.waitFuture != nil {
+		return
+	}
+	waitCtx, cancel := workflow.WithCancel(workflow.WithActivityOptions(ctx, waitWorkflowActivityOptions))
+	r.waitCancel = cancel
+	r.waitFuture = workflow.ExecuteActivity(waitCtx, waitWorkflowActivityName, &waitWorkflowActivityParams{
+		Domain:    r.domain,
+		Execution: *r.state.LastRun,
+	})
+}
+
+func (r *scheduleRunner) recordRun(run RunResult) {
+	switch run.Outcome {
+	case RunOutcomeStarted:
+		r.state.TotalRuns++
+	case RunOutcomeSkipped:
+		r.state.SkippedRuns++
+	case RunOutcomeFailed:
+		r.state.FailedRuns++
+	}
+	r.state.RecentRuns = append(r.state.RecentRuns, run)
+	if len(r.state.RecentRuns) > maxRecentRuns {
+		r.state.RecentRuns = r.state.RecentRuns[len(r.state.RecentRuns)-maxRecentRuns:]
+	}
+}
+
+func (r *scheduleRunner) addSignalHandlers(ctx workflow.Context, selector workflow.Selector) {
+	selector.AddReceive(workflow.GetSignalChannel(ctx, UpdateSignal), func(c workflow.Channel, _ bool) {
+		var schedule Schedule
+		c.Receive(ctx, &schedule)
+		r.update(schedule)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, PauseSignal), func(c workflow.Channel, _ bool) {
+		var note string
+		c.Receive(ctx, &note)
+		r.pause(note)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, UnpauseSignal), func(c workflow.Channel, _ bool) {
+		var note string
+		c.Receive(ctx, &note)
+		r.unpause(ctx, note)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, BackfillSignal), func(c workflow.Channel, _ bool) {
+		var request BackfillRequest
+		c.Receive(ctx, &request)
+		r.backfill(request)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, DeleteSignal), func(c workflow.Channel, _ bool) {
+		c.Receive(ctx, nil)
+		r.deleted = true
+	})
+}
+
+func (r *scheduleRunner) drainSignals(ctx workflow.Context) {
+	for {
+		selector := workflow.NewSelector(ctx)
+		r.addSignalHandlers(ctx, selector)
+		pending := true
+		selector.AddDefault(func() { pending = false })
+		selector.Select(ctx)
+		if !pending {
+			return
+		}
+	}
+}
+
+func (r *scheduleRunner) update(schedule Schedule) {
+	if err := schedule.Validate(); err != nil {
+		r.logger.Error("Ignored invalid update of schedule", zap.Error(err))
+		return
+	}
+	r.schedule = schedule
+}
+
+func (r *scheduleRunner) pause(note string) {
+	r.state.Paused = true
+	r.state.Note = note
+}
+
+func (r *scheduleRunner) unpause(ctx workflow.Context, note string) {
+	if r.state.Paused {
+		// runs with times during the pause are skipped
+		r.state.LastProcessedTime = workflow.Now(ctx)
+	}
+	r.state.Paused = false
+	r.state.Note = note
+}
+
+func (r *scheduleRunner) backfill(request BackfillRequest) {
+	if request.OverlapPolicy == "" {
+		request.OverlapPolicy = r.schedule.Policies.OverlapPolicy
+	}
+	if err := validateOverlapPolicy(request.OverlapPolicy); err != nil || request.EndTime.Before(request.StartTime) {
+		r.logger.Error("Ignored invalid backfill of schedule", zap.Time("start", request.StartTime), zap.Time("end", request.EndTime))
+		return
+	}
+	r.state.Backfills = append(r.state.Backfills, Backfill{
+		// times of cron schedules are in seconds and strictly after the given time
+		LastProcessedTime: request.StartTime.Add(-time.Second),
+		EndTime:           request.EndTime,
+		OverlapPolicy:     request.OverlapPolicy,
+	})
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/cadence/activity"
+	"go.uber.org/cadence/testsuite"
+	"go.uber.org/cadence/workflow"
+)
+
+type scheduleWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	workflowEnv *testsuite.TestWorkflowEnvironment
+
+	startTime time.Time
+	// started are the nominal times of the started runs
+	started []time.Time
+}
+
+func TestScheduleWorkflowTestSuite(t *testing.T) {
+	suite.Run(t, new(scheduleWorkflowTestSuite))
+}
+
+func (s *scheduleWorkflowTestSuite) SetupTest() {
+	s.workflowEnv = s.NewTestWorkflowEnvironment()
+	s.workflowEnv.RegisterWorkflowWithOptions(ScheduleWorkflow, workflow.RegisterOptions{Name: WorkflowTypeName})
+	s.workflowEnv.RegisterActivityWithOptions(StartWorkflowActivity, activity.RegisterOptions{Name: startWorkflowActivityName})
+	s.workflowEnv.RegisterActivityWithOptions(WaitWorkflowActivity, activity.RegisterOptions{Name: waitWorkflowActivityName})
+	s.startTime = time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+	s.workflowEnv.SetStartTime(s.startTime)
+	s.started = nil
+}
+
+func (s *scheduleWorkflowTestSuite) TearDownTest() {
+	s.workflowEnv.AssertExpectations(s.T())
+}
+
+func (s *scheduleWorkflowTestSuite) TestScheduledRuns() {
+	s.onStartWorkflow()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		desc := s.describe()
+		s.Equal(2, desc.State.TotalRuns)
+		s.Len(desc.State.RecentRuns, 2)
+		s.Equal("wid-2024-01-01T02:00:00Z", desc.State.LastRun.WorkflowID)
+		s.Equal(s.at(3*time.Hour), desc.UpcomingRuns[0])
+		s.Len(desc.UpcomingRuns, maxUpcomingRuns)
+	}, 2*time.Hour)
+	s.deleteAfter(3*time.Hour + 15*time.Minute)
+	s.execute(s.newParams(OverlapPolicySkip))
+
+	s.Equal([]time.Time{s.at(time.Hour), s.at(2 * time.Hour), s.at(3 * time.Hour)}, s.started)
+}
+
+func (s *scheduleWorkflowTestSuite) TestPauseAndUnpause() {
+	s.onStartWorkflow()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(PauseSignal, "maintenance")
+	}, time.Hour)
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		desc := s.describe()
+		s.True(desc.State.Paused)
+		s.Equal("maintenance", desc.State.Note)
+		s.workflowEnv.SignalWorkflow(UnpauseSignal, "done")
+	}, 3*time.Hour)
+	s.deleteAfter(4*time.Hour + 15*time.Minute)
+	s.execute(s.newParams(OverlapPolicySkip))
+
+	// runs during the pause are skipped
+	s.Equal([]time.Time{s.at(time.Hour), s.at(4 * time.Hour)}, s.started)
+}
+
+func (s *scheduleWorkflowTestSuite) TestCatchupWindow() {
+	// the first run takes 90 minutes to start, the run of 02:00 is too late to start after that
+	s.workflowEnv.OnActivity(startWorkflowActivityName, mock.Anything, mock.Anything).
+		After(90 * time.Minute).Return(s.startWorkflow).Once()
+	s.onStartWorkflow()
+	s.deleteAfter(3*time.Hour + 45*time.Minute)
+	s.execute(s.newParams(OverlapPolicySkip))
+
+	s.Equal([]time.Time{s.at(time.Hour), s.at(3 * time.Hour), s.at(4 * time.Hour)}, s.started)
+}
+
+func (s *scheduleWorkflowTestSuite) TestBufferRuns() {
+	calls := 0
+	s.workflowEnv.OnActivity(startWorkflowActivityName, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, params startWorkflowActivityParams) (*startWorkflowActivityResult, error) {
+			calls++
+			s.Equal(OverlapPolicyBuffer, params.OverlapPolicy)
+			if calls == 2 {
+				// the run of 01:00 is still running at 02:00
+				s.Equal("wid-2024-01-01T01:00:00Z", params.LastRun.WorkflowID)
+				return &startWorkflowActivityResult{Outcome: runOutcomeBuffered}, nil
+			}
+			return s.startWorkflow(ctx, params)
+		})
+	s.workflowEnv.OnActivity(waitWorkflowActivityName, mock.Anything, mock.Anything).After(30 * time.Minute).Return(nil).Once()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.Equal([]time.Time{s.at(2 * time.Hour)}, s.describe().State.BufferedRuns)
+	}, time.Hour+45*time.Minute)
+	s.deleteAfter(2*time.Hour + 15*time.Minute)
+	s.execute(s.newParams(OverlapPolicyBuffer))
+
+	s.Equal(3, calls)
+	s.Equal([]time.Time{s.at(time.Hour), s.at(2 * time.Hour)}, s.started)
+}
+
+func (s *scheduleWorkflowTestSuite) TestBackfill() {
+	s.onStartWorkflow()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(BackfillSignal, BackfillRequest{
+			StartTime: s.at(-4 * time.Hour),
+			EndTime:   s.at(-2 * time.Hour),
+		})
+		// invalid backfills are ignored
+		s.workflowEnv.SignalWorkflow(BackfillSignal, BackfillRequest{
+			StartTime:     s.at(-4 * time.Hour),
+			EndTime:       s.at(-2 * time.Hour),
+			OverlapPolicy: "unknown",
+		})
+	}, time.Minute)
+	s.deleteAfter(10 * time.Minute)
+	s.execute(s.newParams(OverlapPolicySkip))
+
+	s.Equal([]time.Time{s.at(-4 * time.Hour), s.at(-3 * time.Hour), s.at(-2 * time.Hour)}, s.started)
+}
+
+func (s *scheduleWorkflowTestSuite) TestUpdate() {
+	s.onStartWorkflow()
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		// invalid updates are ignored
+		s.workflowEnv.SignalWorkflow(UpdateSignal, Schedule{})
+		schedule := s.newParams(OverlapPolicySkip).Schedule
+		schedule.Spec.CronSchedule = "*/30 * * * *"
+		s.workflowEnv.SignalWorkflow(UpdateSignal, schedule)
+	}, 10*time.Minute)
+	s.deleteAfter(time.Hour + 45*time.Minute)
+	s.execute(s.newParams(OverlapPolicySkip))
+
+	s.Equal([]time.Time{s.at(time.Hour), s.at(90 * time.Minute), s.at(2 * time.Hour)}, s.started)
+}
+
+func (s *scheduleWorkflowTestSuite) newParams(overlapPolicy string) Params {
+	return Params{
+		Domain: "domain",
+		Name:   "name",
+		Schedule: Schedule{
+			Spec: Spec{CronSchedule: "0 * * * *"},
+			Action: Action{
+				WorkflowID:                   "wid",
+				WorkflowType:                 "wt",
+				TaskList:                     "tl",
+				ExecutionStartToCloseTimeout: time.Hour,
+			},
+			Policies: Policies{OverlapPolicy: overlapPolicy},
+		},
+	}
+}
+
+func (s *scheduleWorkflowTestSuite) onStartWorkflow() {
+	s.workflowEnv.OnActivity(startWorkflowActivityName, mock.Anything, mock.Anything).Return(s.startWorkflow)
+}
+
+func (s *scheduleWorkflowTestSuite) startWorkflow(_ context.Context, params startWorkflowActivityParams) (*startWorkflowActivityResult, error) {
+	s.started = append(s.started, params.NominalTime)
+	return &startWorkflowActivityResult{
+		Outcome:    RunOutcomeStarted,
+		WorkflowID: runWorkflowID(params.Action, params.NominalTime),
+		RunID:      "rid",
+	}, nil
+}
+
+func (s *scheduleWorkflowTestSuite) deleteAfter(d time.Duration) {
+	s.workflowEnv.RegisterDelayedCallback(func() {
+		s.workflowEnv.SignalWorkflow(DeleteSignal, nil)
+	}, d)
+}
+
+func (s *scheduleWorkflowTestSuite) execute(params Params) {
+	s.workflowEnv.ExecuteWorkflow(WorkflowTypeName, params)
+	s.True(s.workflowEnv.IsWorkflowCompleted())
+	s.NoError(s.workflowEnv.GetWorkflowError())
+}
+
+func (s *scheduleWorkflowTestSuite) describe() *Description {
+	value, err := s.workflowEnv.QueryWorkflow(QueryType)
+	s.NoError(err)
+	var desc Description
+	s.NoError(value.Get(&desc))
+	return &desc
+}
+
+// at returns the time after d from the top of the hour the workflow starts, callbacks are delayed from the start time instead
+func (s *scheduleWorkflowTestSuite) at(d time.Duration) time.Time {
+	return s.startTime.Add(-30 * time.Minute).Add(d)
+}
@@ -28,7 +28,9 @@ import (
 	"sync/atomic"
 
 	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/audit"
 	"github.com/uber/cadence/common/cluster"
+	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/domain"
 	"github.com/uber/cadence/common/dynamicconfig"
 	"github.com/uber/cadence/common/log/tag"
@@ -39,8 +41,10 @@ import (
 	"github.com/uber/cadence/service/worker/archiver"
 	"github.com/uber/cadence/service/worker/asyncworkflow"
 	"github.com/uber/cadence/service/worker/batcher"
+	"github.com/uber/cadence/service/worker/domaindeleter"
 	"github.com/uber/cadence/service/worker/esanalyzer"
 	"github.com/uber/cadence/service/worker/failovermanager"
+	"github.com/uber/cadence/service/worker/historyexporter"
 	"github.com/uber/cadence/service/worker/indexer"
 	"github.com/uber/cadence/service/worker/parentclosepolicy"
 	"github.com/uber/cadence/service/worker/replicator"
@@ -49,6 +53,7 @@ import (
 	"github.com/uber/cadence/service/worker/scanner/shardscanner"
 	"github.com/uber/cadence/service/worker/scanner/tasklist"
 	"github.com/uber/cadence/service/worker/scanner/timers"
+	"github.com/uber/cadence/service/worker/schedule"
 )
 
 type (
@@ -80,6 +85,9 @@ type (
 		EnableParentClosePolicyWorker       dynamicconfig.BoolPropertyFn
 		NumParentClosePolicySystemWorkflows dynamicconfig.IntPropertyFn
 		EnableFailoverManager               dynamicconfig.BoolPropertyFn
+		EnableScheduler                     dynamicconfig.BoolPropertyFn
+		EnableDomainDeleter                 dynamicconfig.BoolPropertyFn
+		EnableHistoryExporter               dynamicconfig.BoolPropertyFn
 		DomainReplicationMaxRetryDuration   dynamicconfig.DurationPropertyFn
 		EnableESAnalyzer                    dynamicconfig.BoolPropertyFn
 		EnableAsyncWorkflowConsumption      dynamicconfig.BoolPropertyFn
@@ -136,10 +144,13 @@ func NewConfig(params *resource.Params) *Config {
 				EnableCleaning:           dc.GetBoolProperty(dynamicconfig.EnableCleaningOrphanTaskInTasklistScavenger),
 				MaxTasksPerJobFn:         dc.GetIntProperty(dynamicconfig.ScannerMaxTasksProcessedPerTasklistJob),
 			},
-			Persistence:            &params.PersistenceConfig,
-			ClusterMetadata:        params.ClusterMetadata,
-			TaskListScannerEnabled: dc.GetBoolProperty(dynamicconfig.TaskListScannerEnabled),
-			HistoryScannerEnabled:  dc.GetBoolProperty(dynamicconfig.HistoryScannerEnabled),
+			Persistence:                &params.PersistenceConfig,
+			ClusterMetadata:            params.ClusterMetadata,
+			TaskListScannerEnabled:     dc.GetBoolProperty(dynamicconfig.TaskListScannerEnabled),
+			HistoryScannerEnabled:      dc.GetBoolProperty(dynamicconfig.HistoryScannerEnabled),
+			HistoryReencryptionEnabled: dc.GetBoolProperty(dynamicconfig.HistoryReencryptionEnabled),
+			AuditPurgerEnabled:         params.AuditConfig.Sink == config.AuditSinkPersistence,
+			AuditRetention:             audit.Retention(params.AuditConfig),
 			ShardScanners: []*shardscanner.ScannerConfig{
 				executions.ConcreteExecutionConfig(dc),
 				executions.CurrentExecutionConfig(dc),
@@ -175,6 +186,9 @@ func NewConfig(params *resource.Params) *Config {
 		NumParentClosePolicySystemWorkflows: dc.GetIntProperty(dynamicconfig.NumParentClosePolicySystemWorkflows),
 		EnableESAnalyzer:                    dc.GetBoolProperty(dynamicconfig.EnableESAnalyzer),
 		EnableFailoverManager:               dc.GetBoolProperty(dynamicconfig.EnableFailoverManager),
+		EnableScheduler:                     dc.GetBoolProperty(dynamicconfig.EnableScheduler),
+		EnableDomainDeleter:                 dc.GetBoolProperty(dynamicconfig.EnableDomainDeleter),
+		EnableHistoryExporter:               dc.GetBoolProperty(dynamicconfig.EnableHistoryExporter),
 		ThrottledLogRPS:                     dc.GetIntProperty(dynamicconfig.WorkerThrottledLogRPS),
 		PersistenceGlobalMaxQPS:             dc.GetIntProperty(dynamicconfig.WorkerPersistenceGlobalMaxQPS),
 		PersistenceMaxQPS:                   dc.GetIntProperty(dynamicconfig.WorkerPersistenceMaxQPS),
@@ -237,6 +251,15 @@ func (s *Service) Start() {
 	if s.config.EnableFailoverManager() {
 		s.startFailoverManager()
 	}
+	if s.config.EnableScheduler() {
+		s.startScheduler()
+	}
+	if s.config.EnableDomainDeleter() {
+		s.startDomainDeleter()
+	}
+	if s.config.EnableHistoryExporter() {
+		s.startHistoryExporter()
+	}
 
 	cm := s.startAsyncWorkflowConsumerManager()
 	defer cm.Stop()
@@ -402,6 +425,45 @@ func (s *Service) startFailoverManager() {
 	}
 }
 
+func (s *Service) startScheduler() {
+	params := &schedule.BootstrapParams{
+		ServiceClient: s.params.PublicClient,
+		MetricsClient: s.GetMetricsClient(),
+		Logger:        s.GetLogger(),
+		TallyScope:    s.params.MetricScope,
+		ClientBean:    s.GetClientBean(),
+	}
+	if err := schedule.New(params).Start(); err != nil {
+		s.Stop()
+		s.GetLogger().Fatal("error starting scheduler", tag.Error(err))
+	}
+}
+
+func (s *Service) startDomainDeleter() {
+	params := &domaindeleter.BootstrapParams{
+		Config:        domaindeleter.Config{NumHistoryShards: s.params.PersistenceConfig.NumHistoryShards},
+		ServiceClient: s.params.PublicClient,
+		Resource:      s.Resource,
+		TallyScope:    s.params.MetricScope,
+	}
+	if err := domaindeleter.New(params).Start(); err != nil {
+		s.Stop()
+		s.GetLogger().Fatal("error starting domain deleter", tag.Error(err))
+	}
+}
+
+func (s *Service) startHistoryExporter() {
+	params := &historyexporter.BootstrapParams{
+		ServiceClient: s.params.PublicClient,
+		Resource:      s.Resource,
+		TallyScope:    s.params.MetricScope,
+	}
+	if err := historyexporter.New(params).Start(); err != nil {
+		s.Stop()
+		s.GetLogger().Fatal("error starting history exporter", tag.Error(err))
+	}
+}
+
 func (s *Service) startAsyncWorkflowConsumerManager() common.Daemon {
 	cm := asyncworkflow.NewConsumerManager(
 		s.GetLogger(),
@@ -409,7 +471,9 @@ func (s *Service) startAsyncWorkflowConsumerManager() common.Daemon {
 		s.GetDomainCache(),
 		s.Resource.GetAsyncWorkflowQueueProvider(),
 		s.GetFrontendClient(),
+		s.GetPersistenceBean().GetMapQQueueManager,
 		asyncworkflow.WithEnabledPropertyFn(s.config.EnableAsyncWorkflowConsumption),
+		asyncworkflow.WithMembershipResolver(s.GetMembershipResolver()),
 	)
 	cm.Start()
 	return cm
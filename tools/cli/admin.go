@@ -29,6 +29,7 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/uber/cadence/common/reconciliation/invariant"
+	"github.com/uber/cadence/service/worker/historyexporter"
 	"github.com/uber/cadence/service/worker/scanner/executions"
 )
 
@@ -144,6 +145,52 @@ func newAdminWorkflowCommands() []cli.Command {
 			},
 			Action: AdminMaintainCorruptWorkflow,
 		},
+		{
+			Name:  "export",
+			Usage: "Export the histories and visibility records of the workflows matching a visibility query to files",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagListQueryWithAlias,
+					Usage: "Visibility query of the exported workflows, all workflows of the domain are exported if it's not set",
+				},
+				cli.StringFlag{
+					Name: FlagURI,
+					Usage: "Destination of the exported files, file:///<directory> on the worker hosts " +
+						"or blobstore://<key prefix> in the blobstore of the cluster",
+				},
+				cli.StringFlag{
+					Name:  FlagFormat,
+					Value: historyexporter.FormatJSONL,
+					Usage: "Format of the exported files, only jsonl is supported",
+				},
+				cli.IntFlag{
+					Name:  FlagPageSizeWithAlias,
+					Value: historyexporter.DefaultPageSize,
+					Usage: "Number of workflows exported to a single file",
+				},
+				cli.StringFlag{
+					Name:  FlagJobIDWithAlias,
+					Usage: "ID of the export, a new ID is generated if it's not set",
+				},
+				cli.BoolFlag{
+					Name:  FlagResume,
+					Usage: "Resume the failed export with the given job ID from its checkpoint, the query and the URI must be the same",
+				},
+			},
+			Action: AdminExportHistory,
+		},
+		{
+			Name:    "describe_export",
+			Aliases: []string{"desc_export"},
+			Usage:   "Describe the progress of a history export",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagJobIDWithAlias,
+					Usage: "ID of the export",
+				},
+			},
+			Action: AdminDescribeHistoryExport,
+		},
 	}
 }
 
@@ -388,6 +435,27 @@ func newAdminDomainCommands() []cli.Command {
 				newDomainCLI(c, true).DescribeDomain(c)
 			},
 		},
+		{
+			Name:  "delete",
+			Usage: "Delete a deprecated domain with all of its workflows, visibility records and task lists",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  FlagLocalOnly,
+					Usage: "Only delete a global domain in the current cluster, by default the deletion is also started in the other clusters of the domain",
+				},
+				cli.BoolFlag{
+					Name:  FlagForce,
+					Usage: "Delete the domain without confirmation",
+				},
+			},
+			Action: AdminDeleteDomain,
+		},
+		{
+			Name:    "describe_deletion",
+			Aliases: []string{"desc_del"},
+			Usage:   "Describe the progress of the deletion of a domain",
+			Action:  AdminDescribeDomainDeletion,
+		},
 		{
 			Name:    "getdomainidorname",
 			Aliases: []string{"getdn"},
@@ -651,6 +719,102 @@ func newAdminTaskListCommands() []cli.Command {
 			},
 			Action: AdminListTaskList,
 		},
+		{
+			Name:  "pause",
+			Usage: "Pause dispatching tasks of a tasklist, new tasks are still added to the backlog",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagTaskListWithAlias,
+					Usage: "TaskList name",
+				},
+			},
+			Action: AdminPauseTaskList,
+		},
+		{
+			Name:  "resume",
+			Usage: "Resume dispatching tasks of a paused tasklist",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagTaskListWithAlias,
+					Usage: "TaskList name",
+				},
+			},
+			Action: AdminResumeTaskList,
+		},
+		{
+			Name:  "purge",
+			Usage: "Purge the existing backlog tasks of a tasklist by age and/or workflow type and wait until it is completed",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagTaskListWithAlias,
+					Usage: "TaskList name",
+				},
+				cli.StringFlag{
+					Name: FlagOlderThan,
+					Usage: "Purge tasks created before this long ago. " +
+						"Format is N<duration>, where duration can be second/s, minute/m, hour/h, day/d, week/w, month/M or year/y",
+				},
+				cli.StringFlag{
+					Name:  FlagWorkflowTypeWithAlias,
+					Usage: "Purge tasks of workflows of this type",
+				},
+				cli.BoolFlag{
+					Name:  FlagClear,
+					Usage: "Clear the purge of the tasklist, which is only needed when a previous purge command was interrupted",
+				},
+				cli.BoolFlag{
+					Name:  FlagYes,
+					Usage: "Optional flag to disable confirmation prompt",
+				},
+			},
+			Action: AdminPurgeTaskList,
+		},
+		{
+			Name:  "drain",
+			Usage: "Wait until the backlog of all partitions of a tasklist is empty",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagTaskListWithAlias,
+					Usage: "TaskList name",
+				},
+				cli.StringFlag{
+					Name:  FlagTaskListTypeWithAlias,
+					Value: "decision",
+					Usage: "Optional TaskList type [decision|activity]",
+				},
+			},
+			Action: AdminDrainTaskList,
+		},
+		{
+			Name: "update-partitions",
+			Usage: "Override the partition counts of a tasklist persisted by the adaptive scaler, written to the database. " +
+				"The matching host owning the tasklist loses its lease and reloads the tasklist with them. " +
+				"They are only used while matching.enableAdaptiveScaler is on",
+			Flags: append(getDBFlags(),
+				cli.StringFlag{
+					Name:  FlagTaskListWithAlias,
+					Usage: "TaskList name",
+				},
+				cli.StringFlag{
+					Name:  FlagTaskListTypeWithAlias,
+					Value: "decision",
+					Usage: "Optional TaskList type [decision|activity]",
+				},
+				cli.IntFlag{
+					Name:  FlagNumReadPartitions,
+					Usage: "Number of read partitions, defaults to the number of write partitions",
+				},
+				cli.IntFlag{
+					Name:  FlagNumWritePartitions,
+					Usage: "Number of write partitions",
+				},
+				cli.BoolFlag{
+					Name:  FlagYes,
+					Usage: "Optional flag to disable confirmation prompt",
+				},
+			),
+			Action: AdminUpdateTaskListPartitions,
+		},
 	}
 }
 
@@ -833,6 +997,25 @@ func newAdminAsyncQueueCommands() []cli.Command {
 			},
 			Action: AdminUpdateAsyncWFConfig,
 		},
+		{
+			Name:  "describe",
+			Usage: "describe the requests waiting to be processed in the async workflow queue of a domain, read from the database",
+			Flags: append(getDBFlags(),
+				cli.StringFlag{
+					Name:  FlagDomain,
+					Usage: `domain name, its queue config is used unless --` + FlagQueueType + ` is set`,
+				},
+				cli.StringFlag{
+					Name:  FlagQueueType,
+					Usage: `type of the queue, e.g. mapq, for the predefined queues`,
+				},
+				cli.StringFlag{
+					Name:  FlagJSON,
+					Usage: `queue config in json format, required with --` + FlagQueueType,
+				},
+			),
+			Action: AdminDescribeAsyncWFQueue,
+		},
 	}
 }
 
@@ -1243,6 +1426,77 @@ func newAdminConfigStoreCommands() []cli.Command {
 	}
 }
 
+func newAdminAuthorizeCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:  "dry-run",
+			Usage: "Explain whether the RBAC policy file allows a request, the domain is taken from the global domain option",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  FlagPolicyFile,
+					Usage: "Path of the RBAC policy file",
+				},
+				cli.StringFlag{
+					Name:  FlagActor,
+					Usage: "Actor making the request",
+				},
+				cli.StringSliceFlag{
+					Name:  FlagGroups,
+					Usage: "Groups of the actor, can be repeated",
+				},
+				cli.StringFlag{
+					Name:  FlagAPIName,
+					Usage: "API of the request, e.g. SignalWorkflowExecution",
+				},
+				cli.StringFlag{
+					Name:  FlagWorkflowTypeWithAlias,
+					Usage: "Workflow type of the request",
+				},
+				cli.StringFlag{
+					Name:  FlagTaskListWithAlias,
+					Usage: "Task list of the request",
+				},
+			},
+			Action: AdminAuthorizeDryRun,
+		},
+	}
+}
+
+func newAdminAuditCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "List the most recent audit entries of a workflow, read from the audit file if given, otherwise from the database",
+			Flags: append(getDBFlags(),
+				cli.StringFlag{
+					Name:  FlagWorkflowIDWithAlias,
+					Usage: "WorkflowID",
+				},
+				cli.StringFlag{
+					Name:  FlagRunIDWithAlias,
+					Usage: "Optional RunID, only the entries of the run are listed if set",
+				},
+				cli.StringFlag{
+					Name:  FlagAuditFile,
+					Usage: "Optional path of the file written by the file audit sink",
+				},
+				cli.IntFlag{
+					Name:  FlagMaxEntries,
+					Value: defaultMaxAuditEntries,
+					Usage: "Maximum number of entries to list",
+				},
+				cli.IntFlag{
+					Name:  FlagMaxScan,
+					Value: defaultMaxAuditScan,
+					Usage: "Maximum number of the most recent entries of all workflows to read from the database",
+				},
+			),
+			Action: AdminListAuditEntries,
+		},
+	}
+}
+
 func newAdminIsolationGroupCommands() []cli.Command {
 	return []cli.Command{
 		{
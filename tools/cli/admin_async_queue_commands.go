@@ -23,14 +23,42 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/urfave/cli"
 
+	"github.com/uber/cadence/common/asyncworkflow/queue"
+	_ "github.com/uber/cadence/common/asyncworkflow/queue/kafka" // needed to load kafka asyncworkflow queue
+	_ "github.com/uber/cadence/common/asyncworkflow/queue/mapq"  // needed to load mapq asyncworkflow queue
+	"github.com/uber/cadence/common/asyncworkflow/queue/provider"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
 )
 
+type (
+	// AsyncQueuePartitionRow is a row of the table of async workflow queue partitions
+	AsyncQueuePartitionRow struct {
+		Partition       string `header:"Partition"`
+		CommittedOffset int64  `header:"Committed Offset"`
+		PendingRequests int    `header:"Pending Requests"`
+	}
+
+	// AsyncQueueRequestRow is a row of the table of pending async workflow requests
+	AsyncQueueRequestRow struct {
+		Partition  string `header:"Partition"`
+		Offset     int64  `header:"Offset"`
+		Type       string `header:"Type"`
+		Domain     string `header:"Domain"`
+		WorkflowID string `header:"Workflow ID"`
+	}
+)
+
 func AdminGetAsyncWFConfig(c *cli.Context) {
 	adminClient := cFactory.ServerAdminClient(c)
 
@@ -84,3 +112,126 @@ func AdminUpdateAsyncWFConfig(c *cli.Context) {
 
 	fmt.Printf("Successfully updated async workflow queue config for domain %s\n", domainName)
 }
+
+// AdminDescribeAsyncWFQueue shows the requests waiting to be processed in the async workflow queue of a domain.
+// The queue is read from the database, so only the queues stored in Cadence persistence can be described.
+func AdminDescribeAsyncWFQueue(c *cli.Context) {
+	queueType := c.String(FlagQueueType)
+	var queueConfig *types.DataBlob
+	if queueType != "" {
+		queueConfig = &types.DataBlob{
+			EncodingType: types.EncodingTypeJSON.Ptr(),
+			Data:         []byte(getRequiredOption(c, FlagJSON)),
+		}
+	} else {
+		domainName := getRequiredOption(c, FlagDomain)
+		ctx, cancel := newContext(c)
+		defer cancel()
+		resp, err := cFactory.ServerAdminClient(c).GetDomainAsyncWorkflowConfiguraton(ctx, &types.GetDomainAsyncWorkflowConfiguratonRequest{
+			Domain: domainName,
+		})
+		if err != nil {
+			ErrorAndExit("Failed to get async wf queue config", err)
+			return
+		}
+		if resp == nil || resp.Configuration == nil {
+			ErrorAndExit(fmt.Sprintf("Async workflow queue config not found for domain %s", domainName), nil)
+			return
+		}
+		if resp.Configuration.PredefinedQueueName != "" {
+			ErrorAndExit(fmt.Sprintf("Domain %s uses the predefined queue %s, provide its type and config with --%s and --%s",
+				domainName, resp.Configuration.PredefinedQueueName, FlagQueueType, FlagJSON), nil)
+			return
+		}
+		queueType = resp.Configuration.QueueType
+		queueConfig = resp.Configuration.QueueConfig
+	}
+
+	asyncQueueProvider, err := queue.NewAsyncQueueProvider(nil)
+	if err != nil {
+		ErrorAndExit("Failed to create async workflow queue provider", err)
+		return
+	}
+	asyncQueue, err := asyncQueueProvider.GetQueue(queueType, queueConfig)
+	if err != nil {
+		ErrorAndExit("Failed to create async workflow queue", err)
+		return
+	}
+
+	persistenceFactory := initPersistenceFactory(c)
+	var mapQQueues []persistence.QueueManager
+	defer func() {
+		for _, mapQQueue := range mapQQueues {
+			mapQQueue.Close()
+		}
+	}()
+	newMapQQueue := func(queueName string) (persistence.QueueManager, error) {
+		mapQQueue, err := persistenceFactory.NewMapQQueueManager(queueName)
+		if err != nil {
+			return nil, err
+		}
+		mapQQueues = append(mapQQueues, mapQQueue)
+		return mapQQueue, nil
+	}
+
+	ctx, cancel := newContext(c)
+	defer cancel()
+	description, err := describeAsyncQueue(ctx, asyncQueue, newMapQQueue)
+	if err != nil {
+		ErrorAndExit("Failed to describe async workflow queue", err)
+		return
+	}
+	if err := renderAsyncQueueDescription(os.Stdout, description); err != nil {
+		ErrorAndExit("Failed to render async workflow queue description", err)
+		return
+	}
+}
+
+func describeAsyncQueue(
+	ctx context.Context,
+	asyncQueue provider.Queue,
+	newMapQQueue func(queueName string) (persistence.QueueManager, error),
+) (*provider.QueueDescription, error) {
+	describer, ok := asyncQueue.(provider.Describer)
+	if !ok {
+		return nil, fmt.Errorf("queue %s can't be described", asyncQueue.ID())
+	}
+	return describer.Describe(ctx, &provider.Params{
+		Logger:           log.NewNoop(),
+		MetricsClient:    metrics.NewNoopMetricsClient(),
+		MapQQueueManager: newMapQQueue,
+	})
+}
+
+func renderAsyncQueueDescription(w io.Writer, description *provider.QueueDescription) error {
+	partitions := []AsyncQueuePartitionRow{}
+	requests := []AsyncQueueRequestRow{}
+	for _, partition := range description.Partitions {
+		partitions = append(partitions, AsyncQueuePartitionRow{
+			Partition:       partition.Partition,
+			CommittedOffset: partition.CommittedOffset,
+			PendingRequests: partition.PendingRequestCount,
+		})
+		for _, request := range partition.PendingRequests {
+			requests = append(requests, AsyncQueueRequestRow{
+				Partition:  partition.Partition,
+				Offset:     request.Offset,
+				Type:       request.Type,
+				Domain:     request.Domain,
+				WorkflowID: request.WorkflowID,
+			})
+		}
+	}
+
+	if err := RenderTable(w, partitions, RenderOptions{Color: true, Border: true}); err != nil {
+		return err
+	}
+	if description.Truncated {
+		fmt.Fprintln(w, "The queue is too long to be read entirely, the pending request counts are lower bounds.")
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "First pending requests:")
+	return RenderTable(w, requests, RenderOptions{Color: true, Border: true})
+}
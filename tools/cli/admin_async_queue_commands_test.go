@@ -0,0 +1,159 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/uber/cadence/.gen/go/shared"
+	"github.com/uber/cadence/.gen/go/sqlblobs"
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/asyncworkflow/queue"
+	"github.com/uber/cadence/common/asyncworkflow/queue/provider"
+	"github.com/uber/cadence/common/codec"
+	"github.com/uber/cadence/common/config"
+	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/log"
+	"github.com/uber/cadence/common/metrics"
+	"github.com/uber/cadence/common/persistence"
+	"github.com/uber/cadence/common/persistence/client"
+	sqlite_db "github.com/uber/cadence/common/persistence/sql/sqlplugin/sqlite"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/schema/sqlite"
+	"github.com/uber/cadence/tools/sql"
+)
+
+const asyncQueueConfig = `{"name":"queue1","domains":[{"name":"d1","concurrency":2}]}`
+
+// TestAdminDescribeAsyncWFQueue publishes requests to a MAPQ queue stored in SQLite and describes it with the CLI
+func (s *cliAppSuite) TestAdminDescribeAsyncWFQueue() {
+	dbName := filepath.Join(s.T().TempDir(), "cadence.db")
+	s.NoError(sql.SetupSQLiteSchema(config.SQL{PluginName: sqlite_db.PluginName, DatabaseName: dbName}, "cadence/versioned", sqlite.Version))
+	s.publishAsyncRequests(dbName, map[string]string{"wid1": "d1", "wid2": "d2", "wid3": "d1"})
+
+	s.serverAdminClient.EXPECT().GetDomainAsyncWorkflowConfiguraton(gomock.Any(), gomock.Any()).Return(&types.GetDomainAsyncWorkflowConfiguratonResponse{
+		Configuration: &types.AsyncWorkflowConfiguration{
+			Enabled:   true,
+			QueueType: "mapq",
+			QueueConfig: &types.DataBlob{
+				EncodingType: types.EncodingTypeJSON.Ptr(),
+				Data:         []byte(asyncQueueConfig),
+			},
+		},
+	}, nil)
+	errorCode := s.RunErrorExitCode([]string{"", "admin", "async-wf-queue", "describe", "--domain", "d1", "--db_type", sqlite_db.PluginName, "--db_name", dbName})
+	s.Equal(0, errorCode)
+
+	errorCode = s.RunErrorExitCode([]string{"", "admin", "async-wf-queue", "describe", "--queue_type", "mapq", "--json", asyncQueueConfig, "--db_type", sqlite_db.PluginName, "--db_name", dbName})
+	s.Equal(0, errorCode)
+
+	s.serverAdminClient.EXPECT().GetDomainAsyncWorkflowConfiguraton(gomock.Any(), gomock.Any()).Return(&types.GetDomainAsyncWorkflowConfiguratonResponse{
+		Configuration: &types.AsyncWorkflowConfiguration{Enabled: true, PredefinedQueueName: "queue1"},
+	}, nil)
+	errorCode = s.RunErrorExitCode([]string{"", "admin", "async-wf-queue", "describe", "--domain", "d1", "--db_type", sqlite_db.PluginName, "--db_name", dbName})
+	s.Equal(1, errorCode)
+}
+
+func (s *cliAppSuite) TestDescribeAsyncQueue() {
+	dbName := filepath.Join(s.T().TempDir(), "cadence.db")
+	s.NoError(sql.SetupSQLiteSchema(config.SQL{PluginName: sqlite_db.PluginName, DatabaseName: dbName}, "cadence/versioned", sqlite.Version))
+	s.publishAsyncRequests(dbName, map[string]string{"wid1": "d1", "wid2": "d2", "wid3": "d1"})
+
+	asyncQueue, factory := s.newAsyncQueue(dbName)
+	defer factory.Close()
+	description, err := describeAsyncQueue(context.Background(), asyncQueue, factory.NewMapQQueueManager)
+	s.NoError(err)
+	s.Len(description.Partitions, 2)
+
+	var output bytes.Buffer
+	s.NoError(renderAsyncQueueDescription(&output, description))
+	s.Contains(output.String(), "PARTITION")
+	s.Contains(output.String(), "wid1")
+	s.Contains(output.String(), "wid2")
+	s.Contains(output.String(), "wid3")
+	s.NotContains(output.String(), "lower bounds")
+}
+
+func (s *cliAppSuite) newAsyncQueue(dbName string) (provider.Queue, client.Factory) {
+	asyncQueueProvider, err := queue.NewAsyncQueueProvider(nil)
+	s.Require().NoError(err)
+	asyncQueue, err := asyncQueueProvider.GetQueue("mapq", &types.DataBlob{
+		EncodingType: types.EncodingTypeJSON.Ptr(),
+		Data:         []byte(asyncQueueConfig),
+	})
+	s.Require().NoError(err)
+
+	cfg := &config.Persistence{
+		DefaultStore: "default",
+		DataStores: map[string]config.DataStore{
+			"default": {SQL: &config.SQL{PluginName: sqlite_db.PluginName, DatabaseName: dbName}},
+		},
+		TransactionSizeLimit: dynamicconfig.GetIntPropertyFn(common.DefaultTransactionSizeLimit),
+		ErrorInjectionRate:   dynamicconfig.GetFloatPropertyFn(0.0),
+	}
+	factory := client.NewFactory(
+		cfg,
+		func() float64 { return 0 },
+		"current-cluster",
+		metrics.NewNoopMetricsClient(),
+		log.NewNoop(),
+		&persistence.DynamicConfiguration{
+			EnableSQLAsyncTransaction: dynamicconfig.GetBoolPropertyFn(false),
+		},
+	)
+	return asyncQueue, factory
+}
+
+// publishAsyncRequests publishes a request to start each workflow ID in its domain
+func (s *cliAppSuite) publishAsyncRequests(dbName string, workflowDomains map[string]string) {
+	asyncQueue, factory := s.newAsyncQueue(dbName)
+	defer factory.Close()
+	producer, err := asyncQueue.CreateProducer(&provider.Params{
+		Logger:           log.NewNoop(),
+		MetricsClient:    metrics.NewNoopMetricsClient(),
+		MapQQueueManager: factory.NewMapQQueueManager,
+	})
+	s.Require().NoError(err)
+
+	encoder := codec.NewThriftRWEncoder()
+	requestType := sqlblobs.AsyncRequestTypeStartWorkflowExecutionAsyncRequest
+	for workflowID, domain := range workflowDomains {
+		payload, err := encoder.Encode(&shared.StartWorkflowExecutionAsyncRequest{
+			Request: &shared.StartWorkflowExecutionRequest{
+				Domain:     common.StringPtr(domain),
+				WorkflowId: common.StringPtr(workflowID),
+			},
+		})
+		s.Require().NoError(err)
+		s.Require().NoError(producer.Publish(context.Background(), &sqlblobs.AsyncRequestMessage{
+			PartitionKey: common.StringPtr(workflowID),
+			Type:         &requestType,
+			Encoding:     common.StringPtr(string(common.EncodingTypeThriftRW)),
+			Payload:      payload,
+		}))
+	}
+}
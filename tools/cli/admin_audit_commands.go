@@ -0,0 +1,222 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/audit"
+	"github.com/uber/cadence/common/persistence"
+)
+
+const (
+	defaultMaxAuditEntries = 20
+	defaultMaxAuditScan    = 100000
+	auditQueuePageSize     = 1000
+	maxAuditEntrySize      = 1024 * 1024
+)
+
+type (
+	// AuditEntryRow is a row of the table of audit entries
+	AuditEntryRow struct {
+		Time    time.Time `header:"Time"`
+		Actor   string    `header:"Actor"`
+		Source  string    `header:"Source"`
+		API     string    `header:"API"`
+		RunID   string    `header:"Run ID"`
+		Outcome string    `header:"Outcome"`
+		Error   string    `header:"Error"`
+	}
+
+	// recentAuditEntries keeps the last entries of a workflow, oldest first
+	recentAuditEntries struct {
+		domain     string
+		workflowID string
+		runID      string
+		maxEntries int
+		entries    []*audit.Entry
+	}
+)
+
+// AdminListAuditEntries shows the most recent audit entries of a workflow,
+// they are read from the audit file if given, otherwise from the persistence
+func AdminListAuditEntries(c *cli.Context) {
+	recent := &recentAuditEntries{
+		domain:     getRequiredGlobalOption(c, FlagDomain),
+		workflowID: getRequiredOption(c, FlagWorkflowID),
+		runID:      c.String(FlagRunID),
+		maxEntries: c.Int(FlagMaxEntries),
+	}
+	if recent.maxEntries <= 0 {
+		recent.maxEntries = defaultMaxAuditEntries
+	}
+
+	if path := c.String(FlagAuditFile); path != "" {
+		if err := readAuditFile(path, recent.add); err != nil {
+			ErrorAndExit("Failed to read audit file", err)
+		}
+	} else {
+		if err := readAuditQueue(c, recent.add); err != nil {
+			ErrorAndExit("Failed to read audit entries", err)
+		}
+	}
+
+	table := []AuditEntryRow{}
+	for _, entry := range recent.entries {
+		table = append(table, AuditEntryRow{
+			Time:    entry.Timestamp,
+			Actor:   entry.Actor,
+			Source:  entry.PrincipalSource,
+			API:     entry.API,
+			RunID:   entry.RunID,
+			Outcome: entry.Outcome,
+			Error:   entry.Error,
+		})
+	}
+	RenderTable(os.Stdout, table, RenderOptions{Color: true, Border: true})
+}
+
+func (r *recentAuditEntries) add(entry *audit.Entry) {
+	if entry.Domain != r.domain || entry.WorkflowID != r.workflowID {
+		return
+	}
+	if r.runID != "" && entry.RunID != r.runID {
+		return
+	}
+	r.entries = append(r.entries, entry)
+	if len(r.entries) > r.maxEntries {
+		r.entries = r.entries[1:]
+	}
+}
+
+// readAuditFile reads the entries appended to a file by the file sink, oldest first
+func readAuditFile(path string, add func(*audit.Entry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxAuditEntrySize)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		entry, err := audit.DecodeEntry(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("line %v: %v", line, err)
+		}
+		add(entry)
+	}
+	return scanner.Err()
+}
+
+// readAuditQueue reads the most recent entries enqueued by the persistence sink, oldest first.
+// At most max_scan entries are read, they are the entries of all workflows.
+func readAuditQueue(c *cli.Context, add func(*audit.Entry)) error {
+	queue, err := initPersistenceFactory(c).NewAuditQueueManager()
+	if err != nil {
+		return err
+	}
+	defer queue.Close()
+
+	maxScan := c.Int(FlagMaxScan)
+	if maxScan <= 0 {
+		maxScan = defaultMaxAuditScan
+	}
+	lastMessageID, err := findAuditQueueLastMessageID(func(messageID int64) (bool, error) {
+		messages, err := readAuditQueuePage(c, queue, messageID, 1)
+		return len(messages) > 0, err
+	})
+	if err != nil {
+		return err
+	}
+	// message IDs are assigned in order, so the scan starts max_scan messages before the last one
+	lastMessageID = common.MaxInt64(lastMessageID-int64(maxScan), -1)
+	for {
+		messages, err := readAuditQueuePage(c, queue, lastMessageID, auditQueuePageSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		for _, message := range messages {
+			entry, err := audit.DecodeEntry(message.Payload)
+			if err != nil {
+				return fmt.Errorf("message %v: %v", message.ID, err)
+			}
+			add(entry)
+			lastMessageID = message.ID
+		}
+	}
+}
+
+func readAuditQueuePage(c *cli.Context, queue persistence.QueueManager, lastMessageID int64, pageSize int) (persistence.QueueMessageList, error) {
+	ctx, cancel := newContext(c)
+	defer cancel()
+	return queue.ReadMessages(ctx, lastMessageID, pageSize)
+}
+
+// findAuditQueueLastMessageID returns the ID of the last message of the queue, or -1 if it's empty.
+// The queue can only be read forward, so the ID is searched with hasMessagesAfter, which returns
+// whether the queue has messages after an ID, in a number of reads logarithmic in the ID.
+func findAuditQueueLastMessageID(hasMessagesAfter func(int64) (bool, error)) (int64, error) {
+	// low has messages after it and high doesn't
+	low, high := int64(-1), int64(0)
+	for step := int64(1); ; step *= 2 {
+		ok, err := hasMessagesAfter(high)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		low, high = high, high+step
+	}
+	if low == -1 {
+		ok, err := hasMessagesAfter(-1)
+		if err != nil || !ok {
+			return -1, err
+		}
+	}
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		ok, err := hasMessagesAfter(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return high, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common/authorization"
+	"github.com/uber/cadence/common/types"
+)
+
+// AdminAuthorizeDryRun evaluates a request against an RBAC policy file and explains the decision
+func AdminAuthorizeDryRun(c *cli.Context) {
+	policy, err := authorization.LoadRBACPolicy(getRequiredOption(c, FlagPolicyFile))
+	if err != nil {
+		ErrorAndExit("Failed to load RBAC policy", err)
+	}
+	principal := authorization.Principal{
+		Actor:  c.String(FlagActor),
+		Groups: c.StringSlice(FlagGroups),
+	}
+	if principal.Actor == "" && len(principal.Groups) == 0 {
+		ErrorAndExit(fmt.Sprintf("Option %s or %s is required", FlagActor, FlagGroups), nil)
+	}
+	attributes := &authorization.Attributes{
+		Actor:      principal.Actor,
+		APIName:    getRequiredOption(c, FlagAPIName),
+		DomainName: c.GlobalString(FlagDomain),
+	}
+	if workflowType := c.String(FlagWorkflowType); workflowType != "" {
+		attributes.WorkflowType = &types.WorkflowType{Name: workflowType}
+	}
+	if taskList := c.String(FlagTaskList); taskList != "" {
+		attributes.TaskList = &types.TaskList{Name: taskList}
+	}
+
+	explanation := policy.Explain(principal, attributes)
+	decision := "deny"
+	if explanation.Decision == authorization.DecisionAllow {
+		decision = "allow"
+	}
+	fmt.Printf("Decision: %v\n", decision)
+	if explanation.Rule != "" {
+		fmt.Printf("Rule: %v\n", explanation.Rule)
+	}
+	fmt.Printf("Reason: %v\n", explanation.Reason)
+}
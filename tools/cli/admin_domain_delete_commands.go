@@ -0,0 +1,94 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pborman/uuid"
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/worker/domaindeleter"
+)
+
+// AdminDeleteDomain starts the domain deletion workflow which deletes a deprecated domain with all of its data
+func AdminDeleteDomain(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	if !c.Bool(FlagForce) {
+		prompt(fmt.Sprintf("You are trying to delete domain %v with all of its workflows, continue? Y/N", domain))
+	}
+	input, err := json.Marshal(domaindeleter.Params{
+		DomainName: domain,
+		Replicate:  !c.Bool(FlagLocalOnly),
+	})
+	if err != nil {
+		ErrorAndExit("Failed to serialize params for domain deletion workflow", err)
+	}
+
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	resp, err := client.StartWorkflowExecution(tcCtx, &types.StartWorkflowExecutionRequest{
+		Domain:     common.SystemLocalDomainName,
+		RequestID:  uuid.New(),
+		WorkflowID: domaindeleter.WorkflowID(domain),
+		// a failed deletion can be started again
+		WorkflowIDReusePolicy:               types.WorkflowIDReusePolicyAllowDuplicate.Ptr(),
+		WorkflowType:                        &types.WorkflowType{Name: domaindeleter.WorkflowTypeName},
+		TaskList:                            &types.TaskList{Name: domaindeleter.TaskListName},
+		Input:                               input,
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(int32(domaindeleter.WorkflowTimeout.Seconds())),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(defaultDecisionTimeoutInSeconds),
+		Identity:                            getCliIdentity(),
+	})
+	if err != nil {
+		if _, ok := err.(*types.WorkflowExecutionAlreadyStartedError); ok {
+			ErrorAndExit(fmt.Sprintf("Deletion of domain %v is already in progress", domain), nil)
+		}
+		ErrorAndExit("Failed to start domain deletion workflow", err)
+	}
+	fmt.Printf("Deletion of domain %v is started, wid: %v, rid: %v\n",
+		domain, domaindeleter.WorkflowID(domain), resp.GetRunID())
+}
+
+// AdminDescribeDomainDeletion describes the progress of the deletion of a domain
+func AdminDescribeDomainDeletion(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	resp, err := client.QueryWorkflow(tcCtx, &types.QueryWorkflowRequest{
+		Domain:    common.SystemLocalDomainName,
+		Execution: &types.WorkflowExecution{WorkflowID: domaindeleter.WorkflowID(domain)},
+		Query:     &types.WorkflowQuery{QueryType: domaindeleter.QueryType},
+	})
+	if err != nil {
+		ErrorAndExit(fmt.Sprintf("Failed to query deletion of domain %v", domain), err)
+	}
+	var result domaindeleter.Result
+	if err := json.Unmarshal(resp.GetQueryResult(), &result); err != nil {
+		ErrorAndExit("Unable to deserialize domain deletion progress", err)
+	}
+	prettyPrintJSONObject(result)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pborman/uuid"
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/worker/historyexporter"
+)
+
+// AdminExportHistory starts the history export workflow which exports the histories of the workflows
+// matching a visibility query to files
+func AdminExportHistory(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	params := historyexporter.Params{
+		Domain:   domain,
+		Query:    c.String(FlagListQuery),
+		URI:      getRequiredOption(c, FlagURI),
+		Format:   c.String(FlagFormat),
+		PageSize: c.Int(FlagPageSize),
+	}
+	if err := historyexporter.ValidateParams(params); err != nil {
+		ErrorAndExit("Invalid history export", err)
+	}
+	jobID := c.String(FlagJobID)
+	if c.Bool(FlagResume) {
+		if jobID == "" {
+			ErrorAndExit(fmt.Sprintf("Option %s is required to resume an export", FlagJobID), nil)
+		}
+		result := queryHistoryExport(c, jobID)
+		if result.Completed {
+			ErrorAndExit(fmt.Sprintf("History export %v is already completed", jobID), nil)
+		}
+		params.Checkpoint = result.Checkpoint
+	}
+	if jobID == "" {
+		jobID = uuid.New()
+	}
+	input, err := json.Marshal(params)
+	if err != nil {
+		ErrorAndExit("Failed to serialize params for history export workflow", err)
+	}
+
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	resp, err := client.StartWorkflowExecution(tcCtx, &types.StartWorkflowExecutionRequest{
+		Domain:     common.SystemLocalDomainName,
+		RequestID:  uuid.New(),
+		WorkflowID: historyexporter.WorkflowID(jobID),
+		// a failed export can be resumed with the same ID
+		WorkflowIDReusePolicy:               types.WorkflowIDReusePolicyAllowDuplicateFailedOnly.Ptr(),
+		WorkflowType:                        &types.WorkflowType{Name: historyexporter.WorkflowTypeName},
+		TaskList:                            &types.TaskList{Name: historyexporter.TaskListName},
+		Input:                               input,
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(int32(historyexporter.WorkflowTimeout.Seconds())),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(defaultDecisionTimeoutInSeconds),
+		Identity:                            getCliIdentity(),
+	})
+	if err != nil {
+		if _, ok := err.(*types.WorkflowExecutionAlreadyStartedError); ok {
+			ErrorAndExit(fmt.Sprintf("History export %v is already in progress", jobID), nil)
+		}
+		ErrorAndExit("Failed to start history export workflow", err)
+	}
+	fmt.Printf("History export is started, job ID: %v, wid: %v, rid: %v\n",
+		jobID, historyexporter.WorkflowID(jobID), resp.GetRunID())
+}
+
+// AdminDescribeHistoryExport describes the progress of a history export
+func AdminDescribeHistoryExport(c *cli.Context) {
+	jobID := getRequiredOption(c, FlagJobID)
+	prettyPrintJSONObject(queryHistoryExport(c, jobID))
+}
+
+func queryHistoryExport(c *cli.Context, jobID string) *historyexporter.Result {
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	resp, err := client.QueryWorkflow(tcCtx, &types.QueryWorkflowRequest{
+		Domain:    common.SystemLocalDomainName,
+		Execution: &types.WorkflowExecution{WorkflowID: historyexporter.WorkflowID(jobID)},
+		Query:     &types.WorkflowQuery{QueryType: historyexporter.QueryType},
+	})
+	if err != nil {
+		ErrorAndExit(fmt.Sprintf("Failed to query history export %v", jobID), err)
+	}
+	var result historyexporter.Result
+	if err := json.Unmarshal(resp.GetQueryResult(), &result); err != nil {
+		ErrorAndExit("Unable to deserialize history export progress", err)
+	}
+	return &result
+}
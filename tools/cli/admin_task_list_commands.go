@@ -21,15 +21,35 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli"
+	"go.uber.org/yarpc"
 
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/dynamicconfig"
+	"github.com/uber/cadence/common/persistence"
 	"github.com/uber/cadence/common/types"
 )
 
+const (
+	taskListDrainCheckInterval = 5 * time.Second
+
+	taskListDynamicConfigRequirement = "the task list commands require a dynamic config client which can be listed and updated through the admin API, like the config store client"
+
+	// the dynamic config values are cached by the frontend hosts, so an update is only visible after their next refresh
+	taskListDynamicConfigUpdateAttempts = 3
+	taskListDynamicConfigUpdateChecks   = 15
+	taskListDynamicConfigCheckInterval  = 2 * time.Second
+)
+
 type (
 	TaskListRow struct {
 		Name        string `header:"Task List Name"`
@@ -44,6 +64,14 @@ type (
 		StartID   int64   `header:"Lease Start TaskID"`
 		EndID     int64   `header:"Lease End TaskID"`
 	}
+	TaskListBacklogByPriorityRow struct {
+		Priority int `header:"Priority"`
+		Buffered int `header:"Buffered Tasks"`
+	}
+	TaskListPartitionsRow struct {
+		ReadPartitions  int `header:"Read Partitions"`
+		WritePartitions int `header:"Write Partitions"`
+	}
 )
 
 // AdminDescribeTaskList displays poller and status information of task list.
@@ -65,7 +93,8 @@ func AdminDescribeTaskList(c *cli.Context) {
 		IncludeTaskListStatus: true,
 	}
 
-	response, err := frontendClient.DescribeTaskList(ctx, request)
+	var responseHeaders map[string]string
+	response, err := frontendClient.DescribeTaskList(ctx, request, yarpc.ResponseHeaders(&responseHeaders))
 	if err != nil {
 		ErrorAndExit("Operation DescribeTaskList failed.", err)
 	}
@@ -77,6 +106,31 @@ func AdminDescribeTaskList(c *cli.Context) {
 	printTaskListStatus(taskListStatus)
 	fmt.Printf("\n")
 
+	// the backlog by priority is only returned when tasks of the task list are dispatched by priority
+	if encoded, ok := responseHeaders[common.TaskListBacklogByPriorityHeaderName]; ok {
+		var backlogByPriority map[int]int
+		if err := json.Unmarshal([]byte(encoded), &backlogByPriority); err != nil {
+			ErrorAndExit("Failed to decode the backlog by priority.", err)
+			return
+		}
+		printTaskListBacklogByPriority(backlogByPriority)
+		fmt.Printf("\n")
+	}
+
+	// the partition counts are only returned for the root partition of a task list
+	if encoded, ok := responseHeaders[common.TaskListPartitionsHeaderName]; ok {
+		var partitions common.TaskListPartitions
+		if err := json.Unmarshal([]byte(encoded), &partitions); err != nil {
+			ErrorAndExit("Failed to decode the partitions.", err)
+			return
+		}
+		RenderTable(os.Stdout, []TaskListPartitionsRow{{
+			ReadPartitions:  partitions.ReadPartitions,
+			WritePartitions: partitions.WritePartitions,
+		}}, RenderOptions{Color: true})
+		fmt.Printf("\n")
+	}
+
 	pollers := response.Pollers
 	if len(pollers) == 0 {
 		ErrorAndExit(colorMagenta("No poller for tasklist: "+taskList), nil)
@@ -111,6 +165,351 @@ func AdminListTaskList(c *cli.Context) {
 	RenderTable(os.Stdout, table, RenderOptions{Color: true, Border: true})
 }
 
+// AdminPauseTaskList pauses dispatching tasks of a task list, new tasks are still accepted into the backlog.
+func AdminPauseTaskList(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	taskList := getRequiredOption(c, FlagTaskList)
+
+	setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPaused, domain, taskList, true)
+	fmt.Printf("Task list %q of domain %q is paused\n", taskList, domain)
+}
+
+// AdminResumeTaskList resumes dispatching tasks of a paused task list.
+func AdminResumeTaskList(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	taskList := getRequiredOption(c, FlagTaskList)
+
+	setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPaused, domain, taskList, nil)
+	fmt.Printf("Task list %q of domain %q is resumed\n", taskList, domain)
+}
+
+// AdminPurgeTaskList purges the backlog tasks of a task list which are older than the given
+// duration and/or belong to the given workflow type, or clears the purge of a task list.
+// The purge only selects the tasks created before the request and is cleared once all the
+// partitions of the task list have purged their backlog.
+func AdminPurgeTaskList(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	taskList := getRequiredOption(c, FlagTaskList)
+
+	if c.Bool(FlagClear) {
+		setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeCreatedBefore, domain, taskList, nil)
+		setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeWorkflowType, domain, taskList, nil)
+		fmt.Printf("Purge of task list %q of domain %q is cleared\n", taskList, domain)
+		return
+	}
+
+	workflowType := c.String(FlagWorkflowType)
+	if !c.IsSet(FlagOlderThan) && workflowType == "" {
+		ErrorAndExit(fmt.Sprintf("At least one of %s and %s is required.", FlagOlderThan, FlagWorkflowType), nil)
+		return
+	}
+	// the creation time is always fixed at the time of the request, so that only the existing backlog is purged
+	createdBefore := time.Now()
+	if c.IsSet(FlagOlderThan) {
+		var err error
+		createdBefore, err = parseTimeRange(c.String(FlagOlderThan))
+		if err != nil {
+			ErrorAndExit("Failed to parse older than duration", err)
+			return
+		}
+	}
+	createdBefore = time.Unix(createdBefore.Unix(), 0)
+	if !c.Bool(FlagYes) {
+		prompt(fmt.Sprintf("You are trying to purge the backlog tasks of task list %q of domain %q, continue? Y/N", taskList, domain))
+	}
+
+	// set both values so that a previous purge of the task list is replaced
+	setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeCreatedBefore, domain, taskList, createdBefore.Unix())
+	if workflowType == "" {
+		setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeWorkflowType, domain, taskList, nil)
+	} else {
+		setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeWorkflowType, domain, taskList, workflowType)
+	}
+	fmt.Printf("Purging backlog tasks of task list %q of domain %q created before %s, "+
+		"run with --%s if the command is interrupted\n", taskList, domain, createdBefore.Format(time.RFC3339), FlagClear)
+
+	waitForTaskListPurge(c, domain, taskList, createdBefore)
+
+	setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeCreatedBefore, domain, taskList, nil)
+	setTaskListDynamicConfig(c, dynamicconfig.MatchingTaskListPurgeWorkflowType, domain, taskList, nil)
+	fmt.Printf("Backlog tasks of task list %q of domain %q are purged\n", taskList, domain)
+}
+
+// waitForTaskListPurge waits until all the partitions of both types of the task list report
+// that the purge of their backlog created before createdBefore is completed
+func waitForTaskListPurge(c *cli.Context, domain string, taskList string, createdBefore time.Time) {
+	frontendClient := cFactory.ServerFrontendClient(c)
+	completed := strconv.FormatInt(createdBefore.Unix(), 10)
+	for {
+		ctx, cancel := newContext(c)
+		partitions, err := frontendClient.ListTaskListPartitions(ctx, &types.ListTaskListPartitionsRequest{
+			Domain:   domain,
+			TaskList: &types.TaskList{Name: taskList},
+		})
+		cancel()
+		if err != nil {
+			ErrorAndExit("Operation ListTaskListPartitions failed.", err)
+			return
+		}
+
+		pending := 0
+		for taskListType, partitionMetadata := range map[types.TaskListType][]*types.TaskListPartitionMetadata{
+			types.TaskListTypeDecision: partitions.DecisionTaskListPartitions,
+			types.TaskListTypeActivity: partitions.ActivityTaskListPartitions,
+		} {
+			taskListType := taskListType
+			for _, partition := range partitionMetadata {
+				ctx, cancel := newContext(c)
+				var responseHeaders map[string]string
+				_, err := frontendClient.DescribeTaskList(ctx, &types.DescribeTaskListRequest{
+					Domain:       domain,
+					TaskList:     &types.TaskList{Name: partition.GetKey(), Kind: types.TaskListKindNormal.Ptr()},
+					TaskListType: &taskListType,
+				}, yarpc.ResponseHeaders(&responseHeaders))
+				cancel()
+				if err != nil {
+					ErrorAndExit("Operation DescribeTaskList failed.", err)
+					return
+				}
+				if responseHeaders[common.TaskListPurgeCompletedHeaderName] != completed {
+					pending++
+				}
+			}
+		}
+		if pending == 0 {
+			return
+		}
+		fmt.Printf("%s Partitions of task list %q being purged: %d\n", time.Now().Format(time.RFC3339), taskList, pending)
+		time.Sleep(taskListDrainCheckInterval)
+	}
+}
+
+// AdminDrainTaskList waits until the backlog of all the partitions of a task list reaches zero.
+func AdminDrainTaskList(c *cli.Context) {
+	frontendClient := cFactory.ServerFrontendClient(c)
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	taskList := getRequiredOption(c, FlagTaskList)
+	taskListType := types.TaskListTypeDecision
+	if strings.ToLower(c.String(FlagTaskListType)) == "activity" {
+		taskListType = types.TaskListTypeActivity
+	}
+
+	for {
+		ctx, cancel := newContext(c)
+		partitions, err := frontendClient.ListTaskListPartitions(ctx, &types.ListTaskListPartitionsRequest{
+			Domain:   domain,
+			TaskList: &types.TaskList{Name: taskList},
+		})
+		cancel()
+		if err != nil {
+			ErrorAndExit("Operation ListTaskListPartitions failed.", err)
+		}
+		partitionMetadata := partitions.DecisionTaskListPartitions
+		if taskListType == types.TaskListTypeActivity {
+			partitionMetadata = partitions.ActivityTaskListPartitions
+		}
+
+		var backlog int64
+		for _, partition := range partitionMetadata {
+			ctx, cancel := newContext(c)
+			response, err := frontendClient.DescribeTaskList(ctx, &types.DescribeTaskListRequest{
+				Domain:                domain,
+				TaskList:              &types.TaskList{Name: partition.GetKey(), Kind: types.TaskListKindNormal.Ptr()},
+				TaskListType:          &taskListType,
+				IncludeTaskListStatus: true,
+			})
+			cancel()
+			if err != nil {
+				ErrorAndExit("Operation DescribeTaskList failed.", err)
+			}
+			backlog += response.GetTaskListStatus().GetBacklogCountHint()
+		}
+		if backlog == 0 {
+			fmt.Printf("Task list %q of domain %q is drained\n", taskList, domain)
+			return
+		}
+		fmt.Printf("%s Backlog of task list %q: %d\n", time.Now().Format(time.RFC3339), taskList, backlog)
+		time.Sleep(taskListDrainCheckInterval)
+	}
+}
+
+// AdminUpdateTaskListPartitions overrides the partition counts of a task list persisted by the adaptive scaler.
+// The task list is leased to write them, so the matching host owning it fails its next write and reloads the
+// task list, whose adaptive scaler starts from the new counts.
+func AdminUpdateTaskListPartitions(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	taskList := getRequiredOption(c, FlagTaskList)
+	taskListType := persistence.TaskListTypeDecision
+	if strings.ToLower(c.String(FlagTaskListType)) == "activity" {
+		taskListType = persistence.TaskListTypeActivity
+	}
+	writePartitions := c.Int(FlagNumWritePartitions)
+	if writePartitions < 1 {
+		ErrorAndExit(fmt.Sprintf("%s must be at least 1.", FlagNumWritePartitions), nil)
+		return
+	}
+	readPartitions := writePartitions
+	if c.IsSet(FlagNumReadPartitions) {
+		readPartitions = c.Int(FlagNumReadPartitions)
+	}
+	if readPartitions < writePartitions {
+		ErrorAndExit(fmt.Sprintf("%s can't be less than %s, tasks of the write partitions wouldn't be read.", FlagNumReadPartitions, FlagNumWritePartitions), nil)
+		return
+	}
+	if !c.Bool(FlagYes) {
+		prompt(fmt.Sprintf("You are trying to set the partitions of task list %q of domain %q to %d read and %d write partitions, continue? Y/N",
+			taskList, domain, readPartitions, writePartitions))
+	}
+
+	ctx, cancel := newContext(c)
+	defer cancel()
+	domainID := getDomainID(ctx, domain, cFactory.ServerFrontendClient(c))
+
+	taskManager, err := initPersistenceFactory(c).NewTaskManager()
+	if err != nil {
+		ErrorAndExit("Failed to initialize task manager", err)
+		return
+	}
+	defer taskManager.Close()
+
+	lease, err := taskManager.LeaseTaskList(ctx, &persistence.LeaseTaskListRequest{
+		DomainID:     domainID,
+		DomainName:   domain,
+		TaskList:     taskList,
+		TaskType:     taskListType,
+		TaskListKind: persistence.TaskListKindNormal,
+	})
+	if err != nil {
+		ErrorAndExit("Failed to lease task list", err)
+		return
+	}
+	info := lease.TaskListInfo
+	info.AdaptivePartitionConfig = &persistence.TaskListPartitionConfig{
+		NumReadPartitions:  readPartitions,
+		NumWritePartitions: writePartitions,
+	}
+	_, err = taskManager.UpdateTaskList(ctx, &persistence.UpdateTaskListRequest{
+		TaskListInfo: info,
+		DomainName:   domain,
+	})
+	if err != nil {
+		ErrorAndExit("Failed to update task list", err)
+		return
+	}
+	fmt.Printf("Partitions of task list %q of domain %q are set to %d read and %d write partitions\n", taskList, domain, readPartitions, writePartitions)
+}
+
+// setTaskListDynamicConfig sets the value of a dynamic config key for a task list and keeps the
+// values of the key for other filters, a nil value removes the value of the task list.
+// The task type is not used as a filter so the value applies to both decision and activity task lists.
+func setTaskListDynamicConfig(c *cli.Context, key dynamicconfig.Key, domain string, taskList string, value interface{}) {
+	filters := []*cliFilter{
+		{Name: dynamicconfig.DomainName.String(), Value: domain},
+		{Name: dynamicconfig.TaskListName.String(), Value: taskList},
+	}
+	taskListValue, err := convertFromInputValue(&cliValue{Value: value, Filters: filters})
+	if err != nil {
+		ErrorAndExit("Unable to convert to DynamicConfigValue", err)
+		return
+	}
+
+	// the values of a key are replaced as a whole without a version check, so the value of the task list
+	// is read back after the update and the update is retried if another update of the key overwrote it
+	for attempt := 0; attempt < taskListDynamicConfigUpdateAttempts; attempt++ {
+		values, err := listTaskListDynamicConfig(c, key)
+		if err != nil {
+			ErrorAndExit(fmt.Sprintf("Failed to list dynamic config value(s), %s", taskListDynamicConfigRequirement), err)
+			return
+		}
+		newValues := make([]*types.DynamicConfigValue, 0, len(values)+1)
+		for _, dcValue := range values {
+			if !equalDynamicConfigFilters(dcValue.Filters, taskListValue.Filters) {
+				newValues = append(newValues, dcValue)
+			}
+		}
+		if value != nil {
+			newValues = append(newValues, taskListValue)
+		}
+
+		ctx, cancel := newContext(c)
+		err = cFactory.ServerAdminClient(c).UpdateDynamicConfig(ctx, &types.UpdateDynamicConfigRequest{
+			ConfigName:   key.String(),
+			ConfigValues: newValues,
+		})
+		cancel()
+		if err != nil {
+			ErrorAndExit(fmt.Sprintf("Failed to update dynamic config value, %s", taskListDynamicConfigRequirement), err)
+			return
+		}
+
+		for check := 0; check < taskListDynamicConfigUpdateChecks; check++ {
+			values, err := listTaskListDynamicConfig(c, key)
+			if err != nil {
+				ErrorAndExit("Failed to list dynamic config value(s)", err)
+				return
+			}
+			var current *types.DynamicConfigValue
+			for _, dcValue := range values {
+				if equalDynamicConfigFilters(dcValue.Filters, taskListValue.Filters) {
+					current = dcValue
+				}
+			}
+			if (value == nil && current == nil) || (value != nil && current != nil && bytes.Equal(current.Value.GetData(), taskListValue.Value.GetData())) {
+				return
+			}
+			time.Sleep(taskListDynamicConfigCheckInterval)
+		}
+	}
+	ErrorAndExit(fmt.Sprintf("Dynamic config %s of task list %q was concurrently updated, check its value and retry", key.String(), taskList), nil)
+}
+
+// listTaskListDynamicConfig returns all the values of the key, the task list commands fail on it before
+// updating anything when the dynamic config can't be listed and updated through the admin API
+func listTaskListDynamicConfig(c *cli.Context, key dynamicconfig.Key) ([]*types.DynamicConfigValue, error) {
+	ctx, cancel := newContext(c)
+	defer cancel()
+	response, err := cFactory.ServerAdminClient(c).ListDynamicConfig(ctx, &types.ListDynamicConfigRequest{
+		ConfigName: key.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, nil
+	}
+	var values []*types.DynamicConfigValue
+	for _, entry := range response.Entries {
+		if entry.Name == key.String() {
+			values = append(values, entry.Values...)
+		}
+	}
+	return values, nil
+}
+
+func equalDynamicConfigFilters(a []*types.DynamicConfigFilter, b []*types.DynamicConfigFilter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || !bytes.Equal(a[i].Value.GetData(), b[i].Value.GetData()) {
+			return false
+		}
+	}
+	return true
+}
+
+func printTaskListBacklogByPriority(backlogByPriority map[int]int) {
+	table := make([]TaskListBacklogByPriorityRow, 0, len(backlogByPriority))
+	for priority, buffered := range backlogByPriority {
+		table = append(table, TaskListBacklogByPriorityRow{Priority: priority, Buffered: buffered})
+	}
+	// tasks with higher priority are dispatched first
+	sort.Slice(table, func(i, j int) bool {
+		return table[i].Priority > table[j].Priority
+	})
+	RenderTable(os.Stdout, table, RenderOptions{Color: true})
+}
+
 func printTaskListStatus(taskListStatus *types.TaskListStatus) {
 	table := []TaskListStatusRow{{
 		ReadLevel: taskListStatus.GetReadLevel(),
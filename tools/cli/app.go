@@ -104,6 +104,12 @@ func NewCliApp() *cli.App {
 			Usage:       "Operate cadence tasklist",
 			Subcommands: newTaskListCommands(),
 		},
+		{
+			Name:        "schedule",
+			Aliases:     []string{"sch"},
+			Usage:       "Operate cadence schedule",
+			Subcommands: newScheduleCommands(),
+		},
 		{
 			Name:    "admin",
 			Aliases: []string{"adm"},
@@ -193,6 +199,17 @@ func NewCliApp() *cli.App {
 					Usage:       "Run admin operation on config store",
 					Subcommands: newAdminConfigStoreCommands(),
 				},
+				{
+					Name:        "authorize",
+					Aliases:     []string{"auth"},
+					Usage:       "Run admin operation on authorization policies",
+					Subcommands: newAdminAuthorizeCommands(),
+				},
+				{
+					Name:        "audit",
+					Usage:       "Run admin operation on the audit entries of the API calls",
+					Subcommands: newAdminAuditCommands(),
+				},
 			},
 		},
 		{
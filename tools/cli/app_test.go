@@ -21,6 +21,10 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -31,12 +35,18 @@ import (
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
 	"github.com/urfave/cli"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/api/encoding"
+	"go.uber.org/yarpc/api/transport"
 
 	"github.com/uber/cadence/client/admin"
 	"github.com/uber/cadence/client/frontend"
 	"github.com/uber/cadence/common"
 	"github.com/uber/cadence/common/config"
 	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/worker/domaindeleter"
+	"github.com/uber/cadence/service/worker/historyexporter"
+	"github.com/uber/cadence/service/worker/schedule"
 )
 
 type cliAppSuite struct {
@@ -73,7 +83,7 @@ func (m *clientFactoryMock) ElasticSearchClient(c *cli.Context) *elastic.Client
 }
 
 func (m *clientFactoryMock) ServerConfig(c *cli.Context) (*config.Config, error) {
-	panic("not implemented")
+	return nil, errors.New("not implemented")
 }
 
 var commands = []string{
@@ -505,6 +515,149 @@ var (
 	}
 )
 
+func (s *cliAppSuite) TestCreateSchedule() {
+	s.serverFrontendClient.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			s.Equal(common.SystemLocalDomainName, req.GetDomain())
+			s.Equal(schedule.WorkflowID(domainName, "sched"), req.GetWorkflowID())
+			s.Equal(schedule.WorkflowTypeName, req.WorkflowType.GetName())
+			var params schedule.Params
+			s.NoError(json.Unmarshal(req.Input, &params))
+			s.Equal("0 * * * *", params.Schedule.Spec.CronSchedule)
+			s.Equal(time.Minute, params.Schedule.Action.ExecutionStartToCloseTimeout)
+			s.Equal(schedule.OverlapPolicyBuffer, params.Schedule.Policies.OverlapPolicy)
+			s.Equal(time.Hour, params.Schedule.Policies.CatchupWindow)
+			return &types.StartWorkflowExecutionResponse{RunID: uuid.New()}, nil
+		})
+	err := s.app.Run([]string{"", "--do", domainName, "schedule", "create", "-n", "sched", "--cron", "0 * * * *",
+		"-w", "wid", "-wt", "testWorkflowType", "-tl", "testTaskList", "-et", "60", "--overlap_policy", "buffer", "--catchup_window", "1h"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestUpdateSchedule() {
+	desc := schedule.Description{
+		Schedule: schedule.Schedule{
+			Spec: schedule.Spec{CronSchedule: "0 * * * *"},
+			Action: schedule.Action{
+				WorkflowID:                   "wid",
+				WorkflowType:                 "testWorkflowType",
+				TaskList:                     "testTaskList",
+				ExecutionStartToCloseTimeout: time.Minute,
+			},
+		},
+	}
+	queryResult, err := json.Marshal(desc)
+	s.NoError(err)
+	s.serverFrontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).Return(&types.QueryWorkflowResponse{QueryResult: queryResult}, nil)
+	s.serverFrontendClient.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.SignalWorkflowExecutionRequest, _ ...interface{}) error {
+			s.Equal(schedule.UpdateSignal, req.GetSignalName())
+			var sched schedule.Schedule
+			s.NoError(json.Unmarshal(req.Input, &sched))
+			s.Equal("*/5 * * * *", sched.Spec.CronSchedule)
+			s.Equal("testWorkflowType", sched.Action.WorkflowType)
+			return nil
+		})
+	err = s.app.Run([]string{"", "--do", domainName, "schedule", "update", "-n", "sched", "--cron", "*/5 * * * *"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestBackfillSchedule() {
+	s.serverFrontendClient.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.SignalWorkflowExecutionRequest, _ ...interface{}) error {
+			s.Equal(schedule.WorkflowID(domainName, "sched"), req.WorkflowExecution.GetWorkflowID())
+			s.Equal(schedule.BackfillSignal, req.GetSignalName())
+			var request schedule.BackfillRequest
+			s.NoError(json.Unmarshal(req.Input, &request))
+			s.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), request.StartTime)
+			s.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), request.EndTime)
+			return nil
+		})
+	err := s.app.Run([]string{"", "--do", domainName, "schedule", "backfill", "-n", "sched",
+		"--start_time", "2024-01-01T00:00:00Z", "--end_time", "2024-01-02T00:00:00Z"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestListSchedules() {
+	s.serverFrontendClient.EXPECT().ListOpenWorkflowExecutions(gomock.Any(), gomock.Any()).Return(&types.ListOpenWorkflowExecutionsResponse{
+		Executions: []*types.WorkflowExecutionInfo{
+			{Execution: &types.WorkflowExecution{WorkflowID: schedule.WorkflowID(domainName, "sched")}},
+			{Execution: &types.WorkflowExecution{WorkflowID: schedule.WorkflowID("other-domain", "sched")}},
+		},
+	}, nil)
+	err := s.app.Run([]string{"", "--do", domainName, "schedule", "list"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestAdminDeleteDomain() {
+	s.serverFrontendClient.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			s.Equal(common.SystemLocalDomainName, req.GetDomain())
+			s.Equal(domaindeleter.WorkflowID(domainName), req.GetWorkflowID())
+			s.Equal(domaindeleter.WorkflowTypeName, req.WorkflowType.GetName())
+			var params domaindeleter.Params
+			s.NoError(json.Unmarshal(req.Input, &params))
+			s.Equal(domaindeleter.Params{DomainName: domainName, Replicate: false}, params)
+			return &types.StartWorkflowExecutionResponse{RunID: uuid.New()}, nil
+		})
+	err := s.app.Run([]string{"", "--do", domainName, "admin", "domain", "delete", "--local_only", "--force"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestAdminExportHistory() {
+	s.serverFrontendClient.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			s.Equal(common.SystemLocalDomainName, req.GetDomain())
+			s.Equal(historyexporter.WorkflowID("export-id"), req.GetWorkflowID())
+			s.Equal(historyexporter.WorkflowTypeName, req.WorkflowType.GetName())
+			var params historyexporter.Params
+			s.NoError(json.Unmarshal(req.Input, &params))
+			s.Equal(historyexporter.Params{
+				Domain:   domainName,
+				Query:    "CloseTime > 0",
+				URI:      "file:///tmp/export",
+				Format:   historyexporter.FormatJSONL,
+				PageSize: 10,
+			}, params)
+			return &types.StartWorkflowExecutionResponse{RunID: uuid.New()}, nil
+		})
+	err := s.app.Run([]string{"", "--do", domainName, "admin", "workflow", "export",
+		"--query", "CloseTime > 0", "--uri", "file:///tmp/export", "--pagesize", "10", "--job_id", "export-id"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestAdminExportHistory_Resume() {
+	checkpoint := historyexporter.Checkpoint{NextPageToken: []byte("token"), NextFileIndex: 3, ExportedExecutions: 300}
+	queryResult, err := json.Marshal(historyexporter.Result{Checkpoint: checkpoint})
+	s.NoError(err)
+	s.serverFrontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).
+		Return(&types.QueryWorkflowResponse{QueryResult: queryResult}, nil)
+	s.serverFrontendClient.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.StartWorkflowExecutionRequest, _ ...interface{}) (*types.StartWorkflowExecutionResponse, error) {
+			var params historyexporter.Params
+			s.NoError(json.Unmarshal(req.Input, &params))
+			s.Equal(checkpoint, params.Checkpoint)
+			return &types.StartWorkflowExecutionResponse{RunID: uuid.New()}, nil
+		})
+	err = s.app.Run([]string{"", "--do", domainName, "admin", "workflow", "export",
+		"--uri", "blobstore://export", "--job_id", "export-id", "--resume"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestAdminDescribeHistoryExport() {
+	queryResult, err := json.Marshal(historyexporter.Result{Completed: true})
+	s.NoError(err)
+	s.serverFrontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *types.QueryWorkflowRequest, _ ...interface{}) (*types.QueryWorkflowResponse, error) {
+			s.Equal(common.SystemLocalDomainName, req.GetDomain())
+			s.Equal(historyexporter.WorkflowID("export-id"), req.Execution.GetWorkflowID())
+			s.Equal(historyexporter.QueryType, req.Query.GetQueryType())
+			return &types.QueryWorkflowResponse{QueryResult: queryResult}, nil
+		})
+	err = s.app.Run([]string{"", "admin", "workflow", "describe_export", "--job_id", "export-id"})
+	s.Nil(err)
+}
+
 func (s *cliAppSuite) TestListWorkflow() {
 	resp := listClosedWorkflowExecutionsResponse
 	countWorkflowResp := &types.CountWorkflowExecutionsResponse{}
@@ -661,6 +814,156 @@ func (s *cliAppSuite) TestDescribeTaskList_Activity() {
 	s.Nil(err)
 }
 
+func (s *cliAppSuite) TestAdminDescribeTaskList_BacklogByPriority() {
+	s.serverFrontendClient.EXPECT().DescribeTaskList(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *types.DescribeTaskListRequest, opts ...yarpc.CallOption) (*types.DescribeTaskListResponse, error) {
+			s.True(request.IncludeTaskListStatus)
+			callOpts := make([]encoding.CallOption, 0, len(opts))
+			for _, opt := range opts {
+				callOpts = append(callOpts, encoding.CallOption(opt))
+			}
+			_, err := encoding.NewOutboundCall(callOpts...).ReadFromResponse(ctx, &transport.Response{
+				Headers: transport.NewHeaders().
+					With(common.TaskListBacklogByPriorityHeaderName, `{"0":3,"10":1}`).
+					With(common.TaskListPartitionsHeaderName, `{"readPartitions":3,"writePartitions":2}`),
+			})
+			s.NoError(err)
+			return &types.DescribeTaskListResponse{
+				Pollers:        describeTaskListResponse.Pollers,
+				TaskListStatus: &types.TaskListStatus{BacklogCountHint: 4},
+			}, nil
+		})
+	err := s.app.Run([]string{"", "--do", domainName, "admin", "tasklist", "describe", "-tl", "test-taskList"})
+	s.Nil(err)
+}
+
+func (s *cliAppSuite) TestAdminUpdateTaskListPartitions_Invalid() {
+	// the read partitions can't be less than the write partitions
+	errorCode := s.RunErrorExitCode([]string{"", "--do", domainName, "admin", "tasklist", "update-partitions", "-tl", "test-taskList",
+		"--num_read_partitions", "1", "--num_write_partitions", "2", "--yes"})
+	s.Equal(1, errorCode)
+}
+
+// expectDynamicConfigStore backs the dynamic config admin APIs by the returned map of values by config name
+func (s *cliAppSuite) expectDynamicConfigStore() map[string][]*types.DynamicConfigValue {
+	store := make(map[string][]*types.DynamicConfigValue)
+	s.serverAdminClient.EXPECT().ListDynamicConfig(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *types.ListDynamicConfigRequest, _ ...interface{}) (*types.ListDynamicConfigResponse, error) {
+			return &types.ListDynamicConfigResponse{Entries: []*types.DynamicConfigEntry{{Name: request.ConfigName, Values: store[request.ConfigName]}}}, nil
+		}).AnyTimes()
+	s.serverAdminClient.EXPECT().UpdateDynamicConfig(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, request *types.UpdateDynamicConfigRequest, _ ...interface{}) error {
+			store[request.ConfigName] = request.ConfigValues
+			return nil
+		}).AnyTimes()
+	return store
+}
+
+func (s *cliAppSuite) TestAdminPauseTaskList() {
+	store := s.expectDynamicConfigStore()
+	otherValue := &types.DynamicConfigValue{
+		Value: &types.DataBlob{EncodingType: types.EncodingTypeJSON.Ptr(), Data: []byte("true")},
+		Filters: []*types.DynamicConfigFilter{
+			{Name: "domainName", Value: &types.DataBlob{EncodingType: types.EncodingTypeJSON.Ptr(), Data: []byte(`"other-domain"`)}},
+			{Name: "taskListName", Value: &types.DataBlob{EncodingType: types.EncodingTypeJSON.Ptr(), Data: []byte(`"test-taskList"`)}},
+		},
+	}
+	store["matching.taskListPaused"] = []*types.DynamicConfigValue{otherValue}
+	err := s.app.Run([]string{"", "--do", domainName, "admin", "tasklist", "pause", "-tl", "test-taskList"})
+	s.Nil(err)
+	values := store["matching.taskListPaused"]
+	s.Len(values, 2)
+	s.Equal(otherValue, values[0])
+	s.Equal([]byte("true"), values[1].Value.Data)
+	s.Equal([]byte(`"`+domainName+`"`), values[1].Filters[0].Value.Data)
+}
+
+func (s *cliAppSuite) TestAdminResumeTaskList() {
+	store := s.expectDynamicConfigStore()
+	pausedValue, err := convertFromInputValue(&cliValue{
+		Value: true,
+		Filters: []*cliFilter{
+			{Name: "domainName", Value: domainName},
+			{Name: "taskListName", Value: "test-taskList"},
+		},
+	})
+	s.NoError(err)
+	store["matching.taskListPaused"] = []*types.DynamicConfigValue{pausedValue}
+	err = s.app.Run([]string{"", "--do", domainName, "admin", "tasklist", "resume", "-tl", "test-taskList"})
+	s.Nil(err)
+	s.Empty(store["matching.taskListPaused"])
+}
+
+func (s *cliAppSuite) TestAdminPauseTaskList_DynamicConfigNotWritable() {
+	// nothing is updated when the dynamic config client of the cluster can't be listed
+	s.serverAdminClient.EXPECT().ListDynamicConfig(gomock.Any(), gomock.Any()).Return(nil, &types.InternalServiceError{Message: "not supported for file based client"})
+	errorCode := s.RunErrorExitCode([]string{"", "--do", domainName, "admin", "tasklist", "pause", "-tl", "test-taskList"})
+	s.Equal(1, errorCode)
+}
+
+func (s *cliAppSuite) TestAdminPurgeTaskList() {
+	store := s.expectDynamicConfigStore()
+	s.serverFrontendClient.EXPECT().ListTaskListPartitions(gomock.Any(), gomock.Any()).Return(&types.ListTaskListPartitionsResponse{
+		DecisionTaskListPartitions: []*types.TaskListPartitionMetadata{{Key: "test-taskList"}},
+		ActivityTaskListPartitions: []*types.TaskListPartitionMetadata{{Key: "test-taskList"}},
+	}, nil)
+	var createdBefore []byte
+	s.serverFrontendClient.EXPECT().DescribeTaskList(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *types.DescribeTaskListRequest, opts ...yarpc.CallOption) (*types.DescribeTaskListResponse, error) {
+			// the purge is set while the partitions are checked
+			s.Len(store["matching.taskListPurgeCreatedBefore"], 1)
+			s.Len(store["matching.taskListPurgeWorkflowType"], 1)
+			s.Equal([]byte(`"test-workflow-type"`), store["matching.taskListPurgeWorkflowType"][0].Value.Data)
+			createdBefore = store["matching.taskListPurgeCreatedBefore"][0].Value.Data
+
+			callOpts := make([]encoding.CallOption, 0, len(opts))
+			for _, opt := range opts {
+				callOpts = append(callOpts, encoding.CallOption(opt))
+			}
+			_, err := encoding.NewOutboundCall(callOpts...).ReadFromResponse(ctx, &transport.Response{
+				Headers: transport.NewHeaders().With(common.TaskListPurgeCompletedHeaderName, string(createdBefore)),
+			})
+			s.NoError(err)
+			return &types.DescribeTaskListResponse{}, nil
+		}).Times(2)
+	err := s.app.Run([]string{"", "--do", domainName, "admin", "tasklist", "purge", "-tl", "test-taskList", "--workflow_type", "test-workflow-type", "--yes"})
+	s.Nil(err)
+	// the purge is fixed at the time of the request and cleared once completed
+	createdBeforeUnix, err := strconv.ParseInt(string(createdBefore), 10, 64)
+	s.NoError(err)
+	s.InDelta(time.Now().Unix(), createdBeforeUnix, 5)
+	s.Empty(store["matching.taskListPurgeCreatedBefore"])
+	s.Empty(store["matching.taskListPurgeWorkflowType"])
+}
+
+func (s *cliAppSuite) TestAdminPurgeTaskList_Clear() {
+	store := s.expectDynamicConfigStore()
+	purgeValue, err := convertFromInputValue(&cliValue{
+		Value: "test-workflow-type",
+		Filters: []*cliFilter{
+			{Name: "domainName", Value: domainName},
+			{Name: "taskListName", Value: "test-taskList"},
+		},
+	})
+	s.NoError(err)
+	store["matching.taskListPurgeWorkflowType"] = []*types.DynamicConfigValue{purgeValue}
+	err = s.app.Run([]string{"", "--do", domainName, "admin", "tasklist", "purge", "-tl", "test-taskList", "--clear"})
+	s.Nil(err)
+	s.Empty(store["matching.taskListPurgeCreatedBefore"])
+	s.Empty(store["matching.taskListPurgeWorkflowType"])
+}
+
+func (s *cliAppSuite) TestAdminDrainTaskList() {
+	s.serverFrontendClient.EXPECT().ListTaskListPartitions(gomock.Any(), gomock.Any()).Return(&types.ListTaskListPartitionsResponse{
+		DecisionTaskListPartitions: []*types.TaskListPartitionMetadata{{Key: "test-taskList"}, {Key: "/__cadence_sys/test-taskList/1"}},
+	}, nil)
+	s.serverFrontendClient.EXPECT().DescribeTaskList(gomock.Any(), gomock.Any()).Return(&types.DescribeTaskListResponse{
+		TaskListStatus: &types.TaskListStatus{BacklogCountHint: 0},
+	}, nil).Times(2)
+	err := s.app.Run([]string{"", "--do", domainName, "admin", "tasklist", "drain", "-tl", "test-taskList"})
+	s.Nil(err)
+}
+
 func (s *cliAppSuite) TestObserveWorkflow() {
 	history := getWorkflowExecutionHistoryResponse
 	s.serverFrontendClient.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any()).Return(history, nil).Times(2)
@@ -224,6 +224,15 @@ const (
 	FlagBatchTypeWithAlias                = FlagBatchType + ", bt"
 	FlagSignalName                        = "signal_name"
 	FlagSignalNameWithAlias               = FlagSignalName + ", sig"
+	FlagOlderThan                         = "older_than"
+	FlagClear                             = "clear"
+	FlagNumReadPartitions                 = "num_read_partitions"
+	FlagNumWritePartitions                = "num_write_partitions"
+	FlagOverlapPolicy                     = "overlap_policy"
+	FlagCatchupWindow                     = "catchup_window"
+	FlagLocalOnly                         = "local_only"
+	FlagStartTime                         = "start_time"
+	FlagEndTime                           = "end_time"
 	FlagTaskID                            = "task_id"
 	FlagTaskType                          = "task_type"
 	FlagTaskVisibilityTimestamp           = "task_timestamp"
@@ -303,6 +312,15 @@ const (
 	FlagIsolationGroupSetDrains           = "set-drains"
 	FlagIsolationGroupsRemoveAllDrains    = "remove-all-drains"
 	FlagSearchAttribute                   = "search_attr"
+	FlagURI                               = "uri"
+	FlagResume                            = "resume"
+	FlagPolicyFile                        = "policy_file"
+	FlagActor                             = "actor"
+	FlagGroups                            = "groups"
+	FlagAPIName                           = "api"
+	FlagAuditFile                         = "audit_file"
+	FlagMaxEntries                        = "max_entries"
+	FlagMaxScan                           = "max_scan"
 )
 
 var flagsForExecution = []cli.Flag{
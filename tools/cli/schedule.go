@@ -0,0 +1,177 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/service/worker/schedule"
+)
+
+func newScheduleCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:    "create",
+			Aliases: []string{"c"},
+			Usage:   "Create a schedule that starts a workflow at the times of a cron schedule",
+			Flags:   append(getFlagsForScheduleName(), getFlagsForSchedule()...),
+			Action:  CreateSchedule,
+		},
+		{
+			Name:    "update",
+			Aliases: []string{"u"},
+			Usage:   "Update a schedule, options not provided are unchanged",
+			Flags:   append(getFlagsForScheduleName(), getFlagsForSchedule()...),
+			Action:  UpdateSchedule,
+		},
+		{
+			Name:    "describe",
+			Aliases: []string{"desc"},
+			Usage:   "Describe a schedule, including its state, recent and upcoming runs",
+			Flags:   getFlagsForScheduleName(),
+			Action:  DescribeSchedule,
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "List the schedules of the domain",
+			Action:  ListSchedules,
+		},
+		{
+			Name:  "pause",
+			Usage: "Pause a schedule, runs with times during the pause are skipped",
+			Flags: append(getFlagsForScheduleName(), cli.StringFlag{
+				Name:  FlagReasonWithAlias,
+				Usage: "Reason of the pause",
+			}),
+			Action: PauseSchedule,
+		},
+		{
+			Name:  "unpause",
+			Usage: "Unpause a schedule",
+			Flags: append(getFlagsForScheduleName(), cli.StringFlag{
+				Name:  FlagReasonWithAlias,
+				Usage: "Reason of the unpause",
+			}),
+			Action: UnpauseSchedule,
+		},
+		{
+			Name:  "backfill",
+			Usage: "Start the runs of a schedule with times in a time range",
+			Flags: append(getFlagsForScheduleName(),
+				cli.StringFlag{
+					Name:  FlagStartTime,
+					Usage: "Start of the time range, supported formats are '2006-01-02T15:04:05+07:00', raw UnixNano and time range (N<duration>)",
+				},
+				cli.StringFlag{
+					Name:  FlagEndTime,
+					Usage: "End of the time range, supported formats are '2006-01-02T15:04:05+07:00', raw UnixNano and time range (N<duration>)",
+				},
+				cli.StringFlag{
+					Name:  FlagOverlapPolicy,
+					Usage: "Optional overlap policy of the runs, default to the one of the schedule. Valid values: " + strings.Join(schedule.AllOverlapPolicies, ","),
+				},
+			),
+			Action: BackfillSchedule,
+		},
+		{
+			Name:    "delete",
+			Aliases: []string{"del"},
+			Usage:   "Delete a schedule, workflows started by the schedule are not affected",
+			Flags:   getFlagsForScheduleName(),
+			Action:  DeleteSchedule,
+		},
+	}
+}
+
+func getFlagsForScheduleName() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  FlagNameWithAlias,
+			Usage: "Name of the schedule",
+		},
+	}
+}
+
+func getFlagsForSchedule() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name: FlagCronSchedule,
+			Usage: "Cron schedule of the runs, times are in UTC. Format:\n" +
+				"┌───────────── minute (0 - 59)\n" +
+				"│ ┌───────────── hour (0 - 23)\n" +
+				"│ │ ┌───────────── day of the month (1 - 31)\n" +
+				"│ │ │ ┌───────────── month (1 - 12)\n" +
+				"│ │ │ │ ┌───────────── day of the week (0 - 6) (Sunday to Saturday)\n" +
+				"│ │ │ │ │\n" +
+				"* * * * *",
+		},
+		cli.StringFlag{
+			Name:  FlagStartTime,
+			Usage: "Optional time before which there is no run, supported formats are '2006-01-02T15:04:05+07:00' and raw UnixNano",
+		},
+		cli.StringFlag{
+			Name:  FlagEndTime,
+			Usage: "Optional time after which there is no run, supported formats are '2006-01-02T15:04:05+07:00' and raw UnixNano",
+		},
+		cli.StringFlag{
+			Name:  FlagWorkflowIDWithAlias,
+			Usage: "WorkflowID of the started workflows, the time of the run is appended to it",
+		},
+		cli.StringFlag{
+			Name:  FlagWorkflowTypeWithAlias,
+			Usage: "WorkflowTypeName of the started workflows",
+		},
+		cli.StringFlag{
+			Name:  FlagTaskListWithAlias,
+			Usage: "TaskList of the started workflows",
+		},
+		cli.IntFlag{
+			Name:  FlagExecutionTimeoutWithAlias,
+			Usage: "Execution start to close timeout in seconds of the started workflows",
+		},
+		cli.IntFlag{
+			Name:  FlagDecisionTimeoutWithAlias,
+			Usage: "Decision task start to close timeout in seconds of the started workflows",
+		},
+		cli.StringFlag{
+			Name:  FlagInputWithAlias,
+			Usage: "Optional input of the started workflows in JSON format. For multiple JSON objects, concatenate them and use spaces as separators",
+		},
+		cli.StringFlag{
+			Name:  FlagInputFileWithAlias,
+			Usage: "Optional input of the started workflows from JSON file",
+		},
+		cli.StringFlag{
+			Name: FlagOverlapPolicy,
+			Usage: fmt.Sprintf("Optional policy when the workflow of the last run is still running. Valid values: %v. Default to %v",
+				strings.Join(schedule.AllOverlapPolicies, ","), schedule.OverlapPolicySkip),
+		},
+		cli.StringFlag{
+			Name: FlagCatchupWindow,
+			Usage: fmt.Sprintf("Optional duration like 30m or 12h, runs later than that are skipped when the schedule is not able to run for a while. Default to %v",
+				schedule.DefaultCatchupWindow),
+		},
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2017-2020 Uber Technologies Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/urfave/cli"
+
+	"github.com/uber/cadence/common"
+	"github.com/uber/cadence/common/types"
+	"github.com/uber/cadence/service/worker/schedule"
+)
+
+// Schedules are run by the schedule workflows in the system domain, one workflow per schedule.
+// The commands below manage the schedules through the workflow APIs of the workflows.
+
+// CreateSchedule creates a schedule
+func CreateSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	var sched schedule.Schedule
+	applyScheduleFlags(c, &sched)
+	if err := sched.Validate(); err != nil {
+		ErrorAndExit("Invalid schedule", err)
+	}
+	input, err := json.Marshal(schedule.Params{
+		Domain:   domain,
+		Name:     name,
+		Schedule: sched,
+	})
+	if err != nil {
+		ErrorAndExit("Failed to serialize schedule", err)
+	}
+
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	_, err = client.StartWorkflowExecution(tcCtx, &types.StartWorkflowExecutionRequest{
+		Domain:     common.SystemLocalDomainName,
+		RequestID:  uuid.New(),
+		WorkflowID: schedule.WorkflowID(domain, name),
+		// a deleted schedule can be created again
+		WorkflowIDReusePolicy:               types.WorkflowIDReusePolicyAllowDuplicate.Ptr(),
+		WorkflowType:                        &types.WorkflowType{Name: schedule.WorkflowTypeName},
+		TaskList:                            &types.TaskList{Name: schedule.TaskListName},
+		Input:                               input,
+		ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(int32(schedule.InfiniteDuration.Seconds())),
+		TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(defaultDecisionTimeoutInSeconds),
+		Identity:                            getCliIdentity(),
+	})
+	if err != nil {
+		if _, ok := err.(*types.WorkflowExecutionAlreadyStartedError); ok {
+			ErrorAndExit(fmt.Sprintf("Schedule %v already exists", name), nil)
+		}
+		ErrorAndExit("Failed to create schedule", err)
+	}
+	fmt.Printf("Schedule %v is created\n", name)
+}
+
+// UpdateSchedule updates a schedule with the options provided
+func UpdateSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	desc := describeSchedule(c, domain, name)
+	sched := desc.Schedule
+	applyScheduleFlags(c, &sched)
+	if err := sched.Validate(); err != nil {
+		ErrorAndExit("Invalid schedule", err)
+	}
+	if err := signalSchedule(c, domain, name, schedule.UpdateSignal, sched); err != nil {
+		ErrorAndExit("Failed to update schedule", err)
+	}
+	fmt.Printf("Schedule %v is updated\n", name)
+}
+
+// DescribeSchedule describes a schedule
+func DescribeSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	prettyPrintJSONObject(describeSchedule(c, domain, name))
+}
+
+// ListSchedules lists the schedules of a domain
+func ListSchedules(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	client := getCadenceClient(c)
+	request := &types.ListOpenWorkflowExecutionsRequest{
+		Domain:          common.SystemLocalDomainName,
+		MaximumPageSize: defaultPageSizeForList,
+		StartTimeFilter: &types.StartTimeFilter{
+			EarliestTime: common.Int64Ptr(0),
+			LatestTime:   common.Int64Ptr(time.Now().UnixNano()),
+		},
+		TypeFilter: &types.WorkflowTypeFilter{Name: schedule.WorkflowTypeName},
+	}
+	for {
+		tcCtx, cancel := newContext(c)
+		resp, err := client.ListOpenWorkflowExecutions(tcCtx, request)
+		cancel()
+		if err != nil {
+			ErrorAndExit("Failed to list schedules", err)
+		}
+		for _, info := range resp.GetExecutions() {
+			scheduleDomain, name, ok := schedule.ParseWorkflowID(info.GetExecution().GetWorkflowID())
+			if ok && scheduleDomain == domain {
+				fmt.Println(name)
+			}
+		}
+		if len(resp.NextPageToken) == 0 {
+			return
+		}
+		request.NextPageToken = resp.NextPageToken
+	}
+}
+
+// PauseSchedule pauses a schedule
+func PauseSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	if err := signalSchedule(c, domain, name, schedule.PauseSignal, c.String(FlagReason)); err != nil {
+		ErrorAndExit("Failed to pause schedule", err)
+	}
+	fmt.Printf("Schedule %v is paused\n", name)
+}
+
+// UnpauseSchedule unpauses a schedule
+func UnpauseSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	if err := signalSchedule(c, domain, name, schedule.UnpauseSignal, c.String(FlagReason)); err != nil {
+		ErrorAndExit("Failed to unpause schedule", err)
+	}
+	fmt.Printf("Schedule %v is unpaused\n", name)
+}
+
+// BackfillSchedule starts the runs of a schedule in a time range
+func BackfillSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	request := schedule.BackfillRequest{
+		StartTime:     time.Unix(0, parseTime(getRequiredOption(c, FlagStartTime), 0)).UTC(),
+		EndTime:       time.Unix(0, parseTime(getRequiredOption(c, FlagEndTime), 0)).UTC(),
+		OverlapPolicy: c.String(FlagOverlapPolicy),
+	}
+	if request.EndTime.Before(request.StartTime) {
+		ErrorAndExit("End time is before start time", nil)
+	}
+	if request.OverlapPolicy != "" && !isValidOverlapPolicy(request.OverlapPolicy) {
+		ErrorAndExit(fmt.Sprintf("Invalid overlap policy %v", request.OverlapPolicy), nil)
+	}
+	if err := signalSchedule(c, domain, name, schedule.BackfillSignal, request); err != nil {
+		ErrorAndExit("Failed to backfill schedule", err)
+	}
+	fmt.Printf("Backfill of schedule %v is requested\n", name)
+}
+
+// DeleteSchedule deletes a schedule
+func DeleteSchedule(c *cli.Context) {
+	domain := getRequiredGlobalOption(c, FlagDomain)
+	name := getRequiredOption(c, FlagName)
+	if err := signalSchedule(c, domain, name, schedule.DeleteSignal, nil); err != nil {
+		ErrorAndExit("Failed to delete schedule", err)
+	}
+	fmt.Printf("Schedule %v is deleted\n", name)
+}
+
+func applyScheduleFlags(c *cli.Context, sched *schedule.Schedule) {
+	if c.IsSet(FlagCronSchedule) {
+		sched.Spec.CronSchedule = c.String(FlagCronSchedule)
+	}
+	if c.IsSet(FlagStartTime) {
+		sched.Spec.StartTime = time.Unix(0, parseTime(c.String(FlagStartTime), 0)).UTC()
+	}
+	if c.IsSet(FlagEndTime) {
+		sched.Spec.EndTime = time.Unix(0, parseTime(c.String(FlagEndTime), 0)).UTC()
+	}
+	if c.IsSet(FlagWorkflowID) {
+		sched.Action.WorkflowID = c.String(FlagWorkflowID)
+	}
+	if c.IsSet(FlagWorkflowType) {
+		sched.Action.WorkflowType = c.String(FlagWorkflowType)
+	}
+	if c.IsSet(FlagTaskList) {
+		sched.Action.TaskList = c.String(FlagTaskList)
+	}
+	if c.IsSet(FlagExecutionTimeout) {
+		sched.Action.ExecutionStartToCloseTimeout = time.Duration(c.Int(FlagExecutionTimeout)) * time.Second
+	}
+	if c.IsSet(FlagDecisionTimeout) {
+		sched.Action.TaskStartToCloseTimeout = time.Duration(c.Int(FlagDecisionTimeout)) * time.Second
+	}
+	if c.IsSet(FlagInput) || c.IsSet(FlagInputFile) {
+		sched.Action.Input = []byte(processJSONInput(c))
+	}
+	if c.IsSet(FlagOverlapPolicy) {
+		sched.Policies.OverlapPolicy = c.String(FlagOverlapPolicy)
+	}
+	if c.IsSet(FlagCatchupWindow) {
+		window, err := time.ParseDuration(c.String(FlagCatchupWindow))
+		if err != nil {
+			ErrorAndExit(fmt.Sprintf("Option %s format is invalid.", FlagCatchupWindow), err)
+		}
+		sched.Policies.CatchupWindow = window
+	}
+}
+
+func describeSchedule(c *cli.Context, domain, name string) *schedule.Description {
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	resp, err := client.QueryWorkflow(tcCtx, &types.QueryWorkflowRequest{
+		Domain:    common.SystemLocalDomainName,
+		Execution: &types.WorkflowExecution{WorkflowID: schedule.WorkflowID(domain, name)},
+		Query:     &types.WorkflowQuery{QueryType: schedule.QueryType},
+	})
+	if err != nil {
+		ErrorAndExit(fmt.Sprintf("Failed to describe schedule %v", name), err)
+	}
+	var desc schedule.Description
+	if err := json.Unmarshal(resp.GetQueryResult(), &desc); err != nil {
+		ErrorAndExit("Unable to deserialize schedule", err)
+	}
+	return &desc
+}
+
+func signalSchedule(c *cli.Context, domain, name, signalName string, value interface{}) error {
+	var input []byte
+	if value != nil {
+		var err error
+		if input, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+	client := getCadenceClient(c)
+	tcCtx, cancel := newContext(c)
+	defer cancel()
+	return client.SignalWorkflowExecution(tcCtx, &types.SignalWorkflowExecutionRequest{
+		Domain:            common.SystemLocalDomainName,
+		WorkflowExecution: &types.WorkflowExecution{WorkflowID: schedule.WorkflowID(domain, name)},
+		SignalName:        signalName,
+		Input:             input,
+		Identity:          getCliIdentity(),
+		RequestID:         uuid.New(),
+	})
+}
+
+func isValidOverlapPolicy(policy string) bool {
+	for _, p := range schedule.AllOverlapPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
@@ -475,6 +475,32 @@ func newBatchCommands() []cli.Command {
 					Name:  FlagTargetClusterWithAlias,
 					Usage: "Required for batch replicate",
 				},
+				cli.StringFlag{
+					Name:  FlagResetType,
+					Usage: "Required for batch reset. Support one of these: " + strings.Join(batcher.AllResetTypes, ","),
+				},
+				cli.IntFlag{
+					Name:  FlagDecisionOffset,
+					Usage: "Optional for batch reset. Only negative number is supported, and only works with LastDecisionCompleted and LastDecisionScheduled",
+				},
+				cli.StringFlag{
+					Name:  FlagResetBadBinaryChecksum,
+					Usage: "Required for batch reset with resetType of BadBinary",
+				},
+				cli.StringFlag{
+					Name: FlagEarliestTimeWithAlias,
+					Usage: "Required for batch reset with resetType of DecisionCompletedTime. " +
+						"Supported formats are '2006-01-02T15:04:05+07:00', raw UnixNano and time range (N<duration>)",
+				},
+				cli.BoolFlag{
+					Name:  FlagSkipSignalReapply,
+					Usage: "Optional for batch reset. Whether or not skipping signals reapply after the reset point",
+				},
+				cli.StringFlag{
+					Name: FlagOlderThan,
+					Usage: "Required for batch terminate-if-older-than. Only workflows started before this long ago are terminated. " +
+						"Format is N<duration>, where duration can be second/s, minute/m, hour/h, day/d, week/w, month/M or year/y",
+				},
 				cli.IntFlag{
 					Name:  FlagRPS,
 					Value: batcher.DefaultRPS,
@@ -171,6 +171,24 @@ func StartBatchJob(c *cli.Context) {
 		sourceCluster = getRequiredOption(c, FlagSourceCluster)
 		targetCluster = getRequiredOption(c, FlagTargetCluster)
 	}
+	var resetParams batcher.ResetParams
+	if batchType == batcher.BatchTypeReset {
+		resetParams = batcher.ResetParams{
+			ResetType:         getRequiredOption(c, FlagResetType),
+			DecisionOffset:    c.Int(FlagDecisionOffset),
+			BadBinaryChecksum: c.String(FlagResetBadBinaryChecksum),
+			EarliestTime:      parseTime(c.String(FlagEarliestTime), 0),
+			SkipSignalReapply: c.Bool(FlagSkipSignalReapply),
+		}
+	}
+	var olderThan time.Duration
+	if batchType == batcher.BatchTypeTerminateIfOlderThan {
+		startedBefore, err := parseTimeRange(getRequiredOption(c, FlagOlderThan))
+		if err != nil {
+			ErrorAndExit("Failed to parse older than duration", err)
+		}
+		olderThan = time.Since(startedBefore).Round(time.Second)
+	}
 	rps := c.Int(FlagRPS)
 	pageSize := c.Int(FlagPageSize)
 	concurrency := c.Int(FlagConcurrency)
@@ -225,6 +243,10 @@ func StartBatchJob(c *cli.Context) {
 			SourceCluster: sourceCluster,
 			TargetCluster: targetCluster,
 		},
+		ResetParams: resetParams,
+		TerminateIfOlderThanParams: batcher.TerminateIfOlderThanParams{
+			OlderThan: olderThan,
+		},
 		RPS:                      rps,
 		Concurrency:              concurrency,
 		PageSize:                 pageSize,
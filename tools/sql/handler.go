@@ -22,6 +22,7 @@ package sql
 
 import (
 	"fmt"
+	"io/fs"
 	"log"
 	"net"
 
@@ -30,8 +31,10 @@ import (
 	"github.com/uber/cadence/common/config"
 	mysql_db "github.com/uber/cadence/common/persistence/sql/sqlplugin/mysql"
 	postgres_db "github.com/uber/cadence/common/persistence/sql/sqlplugin/postgres"
+	sqlite_db "github.com/uber/cadence/common/persistence/sql/sqlplugin/sqlite"
 	"github.com/uber/cadence/schema/mysql"
 	"github.com/uber/cadence/schema/postgres"
+	"github.com/uber/cadence/schema/sqlite"
 	cliflag "github.com/uber/cadence/tools/common/flag"
 	"github.com/uber/cadence/tools/common/schema"
 )
@@ -50,6 +53,8 @@ func VerifyCompatibleVersion(
 			expectedVersion = mysql.Version
 		case postgres_db.PluginName:
 			expectedVersion = postgres.Version
+		case sqlite_db.PluginName:
+			expectedVersion = sqlite.Version
 		}
 		err := CheckCompatibleVersion(*ds.SQL, expectedVersion)
 		if err != nil {
@@ -64,6 +69,8 @@ func VerifyCompatibleVersion(
 			expectedVersion = mysql.VisibilityVersion
 		case postgres_db.PluginName:
 			expectedVersion = postgres.VisibilityVersion
+		case sqlite_db.PluginName:
+			expectedVersion = sqlite.VisibilityVersion
 		}
 		err := CheckCompatibleVersion(*ds.SQL, expectedVersion)
 		if err != nil {
@@ -73,6 +80,57 @@ func VerifyCompatibleVersion(
 	return nil
 }
 
+// SetupSQLiteSchemas sets up the schemas of the SQLite default and visibility stores that have no tables yet,
+// so that the server can start against a new database file or an in-memory database without running the schema tool first.
+// Other stores are left unchanged.
+func SetupSQLiteSchemas(cfg config.Persistence) error {
+	if ds, ok := cfg.DataStores[cfg.DefaultStore]; ok && ds.SQL != nil && ds.SQL.PluginName == sqlite_db.PluginName {
+		if err := SetupSQLiteSchema(*ds.SQL, "cadence/versioned", sqlite.Version); err != nil {
+			return err
+		}
+	}
+	if ds, ok := cfg.DataStores[cfg.VisibilityStore]; ok && ds.SQL != nil && ds.SQL.PluginName == sqlite_db.PluginName {
+		if err := SetupSQLiteSchema(*ds.SQL, "visibility/versioned", sqlite.VisibilityVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupSQLiteSchema sets up the schema of a SQLite database that has no tables yet.
+// schemaDir is the versioned schema directory in the embedded SQLite schema.
+func SetupSQLiteSchema(cfg config.SQL, schemaDir string, targetVersion string) error {
+	connection, err := NewConnection(&cfg)
+	if err != nil {
+		return fmt.Errorf("unable to create SQL connection: %v", err.Error())
+	}
+	defer connection.Close()
+
+	tables, err := connection.ListTables()
+	if err != nil {
+		return err
+	}
+	if len(tables) > 0 {
+		return nil
+	}
+
+	schemaFS, err := fs.Sub(sqlite.SchemaFS, schemaDir)
+	if err != nil {
+		return err
+	}
+	if err := schema.SetupFromConfig(&schema.SetupConfig{InitialVersion: "0.0"}, connection); err != nil {
+		return fmt.Errorf("error setting up schema of %v: %v", cfg.DatabaseName, err)
+	}
+	if err := schema.UpdateFromConfig(&schema.UpdateConfig{
+		DBName:        cfg.DatabaseName,
+		TargetVersion: targetVersion,
+		SchemaFS:      schemaFS,
+	}, connection); err != nil {
+		return fmt.Errorf("error updating schema of %v: %v", cfg.DatabaseName, err)
+	}
+	return nil
+}
+
 // CheckCompatibleVersion check the version compatibility
 func CheckCompatibleVersion(
 	cfg config.SQL,
@@ -216,12 +274,15 @@ func parseConnectConfig(cli *cli.Context) (*config.SQL, error) {
 
 // ValidateConnectConfig validates params
 func ValidateConnectConfig(cfg *config.SQL) error {
-	host, _, err := net.SplitHostPort(cfg.ConnectAddr)
-	if err != nil {
-		return schema.NewConfigError("invalid host and port " + cfg.ConnectAddr)
-	}
-	if len(host) == 0 {
-		return schema.NewConfigError("missing sql endpoint argument " + flag(schema.CLIOptEndpoint))
+	// sqlite databases are local files, which don't have an endpoint
+	if cfg.PluginName != sqlite_db.PluginName {
+		host, _, err := net.SplitHostPort(cfg.ConnectAddr)
+		if err != nil {
+			return schema.NewConfigError("invalid host and port " + cfg.ConnectAddr)
+		}
+		if len(host) == 0 {
+			return schema.NewConfigError("missing sql endpoint argument " + flag(schema.CLIOptEndpoint))
+		}
 	}
 	if cfg.DatabaseName == "" {
 		return schema.NewConfigError("missing " + flag(schema.CLIOptDatabase) + " argument")
@@ -82,13 +82,13 @@ func BuildCLIOptions() *cli.App {
 		cli.StringFlag{
 			Name:   schema.CLIFlagDatabase,
 			Value:  "cadence",
-			Usage:  "name of the sql database",
+			Usage:  "name of the sql database, or the path of the database file for sqlite",
 			EnvVar: "SQL_DATABASE",
 		},
 		cli.StringFlag{
 			Name:   schema.CLIFlagPluginName,
 			Value:  "mysql",
-			Usage:  "name of the sql plugin",
+			Usage:  "name of the sql plugin: mysql, postgres or sqlite",
 			EnvVar: "SQL_PLUGIN",
 		},
 		cli.BoolFlag{